@@ -0,0 +1,85 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/migrations"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+const migrateUsage = "Usage: video_conferencing [-config dir] migrate up|down [steps]|status"
+
+// migrateCommand runs the migrate subcommand with args and returns the exit code of the process. down rolls back
+// a single migration unless the number of steps is given
+func migrateCommand(logger *utils.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	database, err := models.CreateDB(databaseConfig())
+	if err != nil {
+		logger.Error().Err(err).Msg("Error initializing database")
+		return 1
+	}
+	defer database.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrations.Up(logger, database)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Println(migrateUsage)
+				return 2
+			}
+		}
+		err = migrations.Down(logger, database, steps)
+	case "status":
+		err = printMigrationStatus(logger, database)
+	default:
+		fmt.Println(migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Str("command", args[0]).Msg("Migration failed")
+		return 1
+	}
+
+	return 0
+}
+
+// printMigrationStatus prints the schema version of the database and the migrations that are pending
+func printMigrationStatus(logger *utils.Logger, database *models.Database) error {
+	status, err := migrations.CurrentStatus(logger, database)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Version: %d\n", status.Version)
+	if status.Dirty {
+		fmt.Println("Dirty: the last migration failed part way and has to be fixed by hand")
+	}
+
+	fmt.Printf("Pending: %d\n", len(status.Pending))
+	for _, migration := range status.Pending {
+		fmt.Println("  " + migration)
+	}
+
+	return nil
+}
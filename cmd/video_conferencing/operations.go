@@ -0,0 +1,90 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+const operationsUsage = "Usage: video_conferencing [-config dir] operations register file..."
+
+// operationsCommand runs the operations subcommand with args and returns the exit code of the process. register
+// registers the operation document of every file, so that it can be executed while OPERATION_ALLOWLIST is enabled.
+// Either every file is registered or none is
+func operationsCommand(logger *utils.Logger, args []string) int {
+	if len(args) < 2 || args[0] != "register" {
+		fmt.Println(operationsUsage)
+		return 2
+	}
+
+	database, err := models.CreateDB(databaseConfig())
+	if err != nil {
+		logger.Error().Err(err).Msg("Error initializing database")
+		return 1
+	}
+	defer database.Close()
+
+	err = registerOperations(context.Background(), database, args[1:])
+	if errors.Is(err, services.ErrInvalidOperation) {
+		fmt.Println(err)
+		return 1
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not register operations")
+		return 1
+	}
+
+	return 0
+}
+
+// registerOperations registers the operation documents of files in a transaction and prints their hashes
+func registerOperations(ctx context.Context, database *models.Database, files []string) error {
+	tx, err := database.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	schema := generated.NewExecutableSchema(generated.Config{}).Schema()
+	operations := make([]*models.StoredOperation, len(files))
+	for index, file := range files {
+		document, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		operations[index], err = services.RegisterOperation(ctx, tx, schema, string(document), sql.NullInt64{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for index, operation := range operations {
+		fmt.Printf("%s %s\n", operation.Hash, files[index])
+	}
+
+	return nil
+}
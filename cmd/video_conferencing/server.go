@@ -11,24 +11,35 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/handlers"
 
 	"github.com/samyak-jain/agora_backend/internal/generated"
 	"github.com/samyak-jain/agora_backend/migrations"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
 	"github.com/samyak-jain/agora_backend/pkg/graph"
+	"github.com/samyak-jain/agora_backend/pkg/loaders"
 	"github.com/samyak-jain/agora_backend/pkg/middleware"
 	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+	"github.com/samyak-jain/agora_backend/pkg/rest"
+	"github.com/samyak-jain/agora_backend/pkg/rpc"
 	"github.com/samyak-jain/agora_backend/services"
 
 	"github.com/spf13/viper"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 
 	"github.com/rs/cors"
 	"github.com/rs/zerolog/hlog"
@@ -36,16 +47,26 @@ import (
 	"github.com/samyak-jain/agora_backend/utils"
 
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
 	"github.com/newrelic/go-agent/v3/integrations/nrgorilla"
 	newrelic "github.com/newrelic/go-agent/v3/newrelic"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"google.golang.org/grpc"
 )
 
 const defaultPort = "8080"
 
 func main() {
 	configDir := flag.String("config", ".", "Directory which contains the config.json")
+	flag.Parse()
 	if configDir != nil {
 		println("Config Path", *configDir)
 	} else {
@@ -64,9 +85,25 @@ func main() {
 		Filename:              "app-builder-logs",
 	})
 
+	if flag.Arg(0) == "migrate" {
+		os.Exit(migrateCommand(logger, flag.Args()[1:]))
+	}
+
+	if flag.Arg(0) == "operations" {
+		os.Exit(operationsCommand(logger, flag.Args()[1:]))
+	}
+
 	port := viper.GetString("PORT")
 
-	database, err := models.CreateDB(viper.GetString("DATABASE_URL"))
+	shutdownTracing, err := utils.SetupTracing(context.Background())
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error initializing tracing")
+		return
+	}
+
+	defer shutdownTracing(context.Background())
+
+	database, err := models.CreateDB(databaseConfig())
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Error initializing database")
 		return
@@ -74,32 +111,221 @@ func main() {
 
 	defer database.Close()
 
+	if !database.Postgres() {
+		logger.Warn().Str("driver", database.DriverName()).Msg("Only channels, recordings and signing in with OAuth are available, other features need Postgres")
+	}
+
+	// Migrations are applied before anything uses the database, so that new tables exist by the time it does
 	if viper.GetBool("RUN_MIGRATION") {
-		migrations.RunMigration(configDir)
+		err = migrations.Up(logger, database)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Error migrating database")
+			return
+		}
+	}
+
+	repos := repository.New(database)
+	defer repos.Close()
+
+	redisClient, err := utils.NewRedisClient()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error connecting to Redis")
+		return
+	}
+
+	// Subscriptions only reach the clients connected to other instances when messages go through Redis
+	var pubSub utils.PubSub = utils.NewPubSub()
+	if redisClient != nil {
+		defer redisClient.Close()
+
+		redisPubSub := utils.NewRedisPubSub(redisClient, logger)
+		defer redisPubSub.Close()
+		pubSub = redisPubSub
+	}
+
+	mailer, err := utils.NewMailer()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error configuring email")
+		return
+	}
+
+	smsSender, err := utils.NewSMSSender()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error configuring SMS")
+		return
+	}
+
+	// Every request to Agora goes through one client, so that an outage of Agora opens the same circuits for all of them
+	agoraClient := utils.NewAgoraClient()
+
+	recorder, err := utils.NewCloudRecorder()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error configuring cloud recording")
+		return
+	}
+
+	if _, ok := recorder.(*utils.MockCloudRecorder); ok {
+		logger.Warn().Msg("Recordings are simulated by the mock cloud recorder")
 	}
 
 	router := mux.NewRouter()
 
+	resolver := &graph.Resolver{
+		DB:       database,
+		Repos:    repos,
+		Logger:   logger,
+		PubSub:   pubSub,
+		Redis:    redisClient,
+		Mailer:   mailer,
+		SMS:      smsSender,
+		Recorder: recorder,
+		Agora:    agoraClient,
+		Tokens:   utils.CertificateTokenGenerator{},
+		IDs:      utils.RandomIDGenerator{},
+	}
+
 	config := generated.Config{
-		Resolvers: &graph.Resolver{
-			DB:     database,
-			Logger: logger,
-		},
+		Resolvers: resolver,
 	}
+	config.Directives.HasRole = resolver.HasRole
+	config.Directives.TwoFactor = resolver.TwoFactor
+	graph.SetComplexity(&config.Complexity)
 
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(config))
-	requestHandler := services.ServiceRouter{
+	srv := handler.New(generated.NewExecutableSchema(config))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				allowedOrigin := viper.GetString("ALLOWED_ORIGIN")
+				return allowedOrigin == "*" || r.Header.Get("Origin") == allowedOrigin
+			},
+		},
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: viper.GetInt64("GRAPHQL_MAX_UPLOAD_BYTES"),
+	})
+	srv.SetQueryCache(lru.New(1000))
+	srv.SetErrorPresenter(func(ctx context.Context, e error) *gqlerror.Error {
+		err := apierror.Presenter(ctx, e)
+		if requestID := middleware.GetRequestID(ctx); requestID != "" {
+			if err.Extensions == nil {
+				err.Extensions = map[string]interface{}{}
+			}
+			err.Extensions["requestId"] = requestID
+		}
+
+		return err
+	})
+	audit := middleware.Audit{
 		DB:     database,
 		Logger: logger,
 	}
+	var passphraseLimit *middleware.PassphraseRateLimit
+	if redisClient != nil {
+		passphraseLimit = &middleware.PassphraseRateLimit{
+			Limiter: &utils.RateLimiter{Client: redisClient},
+			Logger:  logger,
+			Fields:  []string{"Query.joinChannel", "Query.share"},
+		}
+	}
+
+	srv.Use(middleware.Tracing{})
+	srv.Use(audit)
+	if passphraseLimit != nil {
+		srv.Use(*passphraseLimit)
+	}
+	// Only registered operations are executed with the allowlist, which leaves no use for introspection and would be
+	// undermined by clients persisting queries of their own
+	if viper.GetBool("OPERATION_ALLOWLIST") {
+		if !database.Postgres() {
+			logger.Fatal().Msg("The operation allowlist needs a Postgres database")
+			return
+		}
+		srv.Use(middleware.OperationAllowlist{
+			DB:     database,
+			Logger: logger,
+		})
+	} else {
+		srv.Use(extension.Introspection{})
+		srv.Use(extension.AutomaticPersistedQuery{
+			Cache: lru.New(100),
+		})
+	}
+	// The other databases only have the tables of channels, recordings and signing in, so the fields that need any other
+	// table are refused rather than failing on a missing table
+	if !database.Postgres() {
+		srv.Use(middleware.SupportedFields{
+			Fields: graph.PortableFields,
+			Reason: "it needs a Postgres database",
+		})
+	}
+	srv.Use(extension.FixedComplexityLimit(viper.GetInt("GRAPHQL_COMPLEXITY_LIMIT")))
+	srv.Use(middleware.DepthLimit{Limit: viper.GetInt("GRAPHQL_DEPTH_LIMIT")})
+	requestHandler := services.ServiceRouter{
+		DB:       database,
+		Logger:   logger,
+		Redis:    redisClient,
+		Recorder: recorder,
+		Agora:    agoraClient,
+		Repos:    repos,
+		PubSub:   pubSub,
+	}
+
+	// Background jobs are stopped on shutdown once the requests in flight are done, and are waited for before the
+	// database is closed
+	jobsContext, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Every background job works with tables that only Postgres has
+	var jobs sync.WaitGroup
+	if database.Postgres() {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			requestHandler.Jobs(jobsContext, time.Duration(viper.GetInt("JOB_POLL_INTERVAL_SECONDS"))*time.Second)
+		}()
+	}
 
 	router.HandleFunc("/", playground.Handler("GraphQL playground", "/query"))
-	router.Handle("/query", srv)
+	router.Handle("/query", middleware.BodyLimitHandler(viper.GetInt64("GRAPHQL_MAX_BODY_BYTES"))(srv))
+	restAPI := rest.API{
+		Resolver:        resolver,
+		Logger:          logger,
+		Audit:           audit,
+		PassphraseLimit: passphraseLimit,
+		MaxBodyBytes:    viper.GetInt64("GRAPHQL_MAX_BODY_BYTES"),
+	}
+	restAPI.Routes(router)
+	router.HandleFunc("/healthz", http.HandlerFunc(requestHandler.Healthz)).Methods("GET")
+	router.HandleFunc("/readyz", http.HandlerFunc(requestHandler.Readyz)).Methods("GET")
 	router.HandleFunc("/oauth", http.HandlerFunc(requestHandler.OAuth))
-	router.HandleFunc("/pstn", http.HandlerFunc(requestHandler.PSTN))
+	// The routes of phone calls, data exports, short links, webhooks and Slack need tables that only Postgres has
+	if database.Postgres() {
+		router.HandleFunc("/pstn", http.HandlerFunc(requestHandler.PSTN))
+		router.HandleFunc("/exports/{id}", http.HandlerFunc(requestHandler.DownloadDataExport)).Methods("GET")
+		router.HandleFunc("/s/{slug}", http.HandlerFunc(requestHandler.ShortLinkRedirect)).Methods("GET")
+		router.HandleFunc("/webhooks/agora/recording", http.HandlerFunc(requestHandler.RecordingWebhook)).Methods("POST")
+		router.HandleFunc("/webhooks/agora/channel", http.HandlerFunc(requestHandler.ChannelWebhook)).Methods("POST")
+		router.HandleFunc("/webhooks/pstn/call", http.HandlerFunc(requestHandler.PSTNCallWebhook)).Methods("POST")
+		router.HandleFunc("/webhooks/sms/status", http.HandlerFunc(requestHandler.SMSStatusWebhook)).Methods("POST")
+		router.HandleFunc("/webhooks/stripe", http.HandlerFunc(requestHandler.StripeWebhook)).Methods("POST")
+		router.HandleFunc("/slack/commands", http.HandlerFunc(resolver.SlackCommand)).Methods("POST")
+		router.HandleFunc("/slack/interactions", http.HandlerFunc(resolver.SlackInteraction)).Methods("POST")
+	}
+
+	router.Use(func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(next, "http.server")
+	})
+
+	router.Use(middleware.RequestIDHandler(logger))
+	router.Use(middleware.ClientIPHandler)
 
 	router.Use(hlog.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
 		logger.Info().
+			Str("requestId", middleware.GetRequestID(r.Context())).
 			Str("method", r.Method).
 			Stringer("url", r.URL).
 			Int("status", status).
@@ -112,12 +338,23 @@ func main() {
 	router.Use(cors.New(cors.Options{
 		AllowedOrigins:   []string{viper.GetString("ALLOWED_ORIGIN")},
 		AllowCredentials: true,
-		AllowedHeaders:   []string{"authorization", "content-type"},
+		AllowedHeaders:   []string{"authorization", "content-type", "x-request-id", "x-api-key", "x-two-factor-code"},
+		ExposedHeaders:   []string{middleware.RequestIDHeader},
 		Debug:            false,
 	}).Handler)
 	router.Use(handlers.RecoveryHandler())
 
-	router.Use(middleware.AuthHandler(database, logger))
+	router.Use(middleware.AuthHandler(repos, logger))
+	router.Use(middleware.APIKeyHandler(repos, logger))
+	router.Use(middleware.TwoFactorHandler)
+	router.Use(loaders.Middleware(repos))
+
+	// Rate limits are shared by every instance through Redis and are not applied without it
+	if redisClient != nil {
+		router.Use(middleware.RateLimitHandler(&utils.RateLimiter{Client: redisClient}, logger))
+	} else {
+		logger.Info().Msg("Redis is not configured, requests are not rate limited")
+	}
 
 	if viper.GetBool("ENABLE_NEWRELIC_MONITORING") {
 		nrAgent, err := newrelic.NewApplication(
@@ -134,6 +371,108 @@ func main() {
 		router.Use(nrgorilla.Middleware(nrAgent))
 	}
 
-	logger.Debug().Str("PORT", port)
-	logger.Fatal().Err(http.ListenAndServe(":"+port, router))
+	// The server stops on SIGINT and SIGTERM, which is how deploys replace it
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// The gRPC API for internal services listens on a port of its own, which is not meant to be exposed publicly
+	var grpcServer *grpc.Server
+	if grpcPort := viper.GetString("GRPC_PORT"); grpcPort != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			logger.Fatal().Err(err).Str("port", grpcPort).Msg("Error listening for gRPC")
+			return
+		}
+
+		rpcServer := &rpc.Server{
+			Resolver: resolver,
+			Logger:   logger,
+			Audit:    audit,
+		}
+		grpcServer = rpcServer.GRPCServer()
+		go func() {
+			err := grpcServer.Serve(listener)
+			if err != nil {
+				logger.Error().Err(err).Msg("gRPC server stopped")
+				stopSignals()
+			}
+		}()
+		logger.Info().Str("port", grpcPort).Msg("Serving gRPC")
+	}
+
+	httpServer := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != http.ErrServerClosed {
+			logger.Error().Err(err).Msg("HTTP server stopped")
+			stopSignals()
+		}
+	}()
+	logger.Info().Str("port", port).Msg("Serving HTTP")
+
+	// A second signal stops the server right away instead of waiting for the shutdown
+	<-signals.Done()
+	stopSignals()
+	shutdown(logger, httpServer, grpcServer, stopJobs, &jobs)
+}
+
+// shutdown stops accepting requests and waits for the requests in flight, then stops the background jobs and waits for
+// them to finish, within SHUTDOWN_TIMEOUT_SECONDS altogether. Recordings being started when a deploy replaces the
+// server are finished rather than left half started, and the events they queue are flushed from the outbox
+func shutdown(logger *utils.Logger, httpServer *http.Server, grpcServer *grpc.Server, stopJobs context.CancelFunc, jobs *sync.WaitGroup) {
+	timeout := time.Duration(viper.GetInt("SHUTDOWN_TIMEOUT_SECONDS")) * time.Second
+	logger.Info().Dur("timeout", timeout).Msg("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Subscriptions are hijacked connections, which the HTTP server does not wait for
+	err := httpServer.Shutdown(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Requests were still in flight when the server shut down")
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logger.Error().Msg("gRPC calls were still in flight when the server shut down")
+			grpcServer.Stop()
+		}
+	}
+
+	stopJobs()
+	finished := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		logger.Info().Msg("Shut down")
+	case <-ctx.Done():
+		logger.Error().Msg("Background jobs were still running when the server shut down")
+	}
+}
+
+// databaseConfig reads the database and the size of its connection pool from the configuration
+func databaseConfig() models.DBConfig {
+	return models.DBConfig{
+		Driver:          viper.GetString("DATABASE_DRIVER"),
+		URL:             viper.GetString("DATABASE_URL"),
+		MaxOpenConns:    viper.GetInt("DATABASE_MAX_OPEN_CONNS"),
+		MaxIdleConns:    viper.GetInt("DATABASE_MAX_IDLE_CONNS"),
+		ConnMaxLifetime: time.Duration(viper.GetInt("DATABASE_CONN_MAX_LIFETIME_MINUTES")) * time.Minute,
+		QueryTimeout:    time.Duration(viper.GetInt("DATABASE_QUERY_TIMEOUT_SECONDS")) * time.Second,
+	}
 }
@@ -11,35 +11,182 @@
 package migrations
 
 import (
-	"flag"
-	"log"
+	"embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"github.com/samyak-jain/agora_backend/pkg/models"
 	"github.com/samyak-jain/agora_backend/utils"
 	"github.com/spf13/viper"
 )
 
-// RunMigration runs the schema migrations
-func RunMigration(config *string) {
-	if config == nil {
-		configDir := flag.String("config", "..", "Directory which contains the config.json")
-		utils.SetupConfig(configDir)
+// files holds the schema migrations of Postgres, MySQL and SQLite, so that the binary can migrate a database without the
+// migrations directory
+//
+//go:embed migrations/*.sql mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// Status describes the schema version of a database
+type Status struct {
+	// Version is the last migration that was applied, 0 when none was applied yet
+	Version uint
+	// Dirty is set when the last migration failed part way, which has to be fixed by hand
+	Dirty bool
+	// Pending lists the migrations that have not been applied yet, oldest first
+	Pending []string
+}
+
+// openSource opens the migrations embedded in the binary for the dialect of db, or those at MIGRATION_SOURCE when it
+// is set
+func openSource(db *models.Database) (source.Driver, error) {
+	if url := viper.GetString("MIGRATION_SOURCE"); url != "" {
+		return source.Open(url)
+	}
+
+	if db.MySQL() {
+		return httpfs.New(http.FS(files), "mysql")
+	}
+
+	if db.SQLite() {
+		return httpfs.New(http.FS(files), "sqlite")
+	}
+
+	return httpfs.New(http.FS(files), "migrations")
+}
+
+// migrateLogger writes the progress of migrations to the application log
+type migrateLogger struct {
+	logger *utils.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	l.logger.Info().Msg(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
+
+// open prepares the migrations of db and returns a function that releases them. Postgres and MySQL are migrated with
+// a connection of their own to DATABASE_URL, while SQLite is migrated through db so that in-memory databases are too
+func open(logger *utils.Logger, db *models.Database) (*migrate.Migrate, func(), error) {
+	src, err := openSource(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !db.SQLite() {
+		url := viper.GetString("DATABASE_URL")
+		if db.MySQL() {
+			// MySQL is configured with a DSN, which the migrations take as a URL of the mysql scheme
+			url = "mysql://" + url
+		}
+
+		m, err := migrate.NewWithSourceInstance("migrations", src, url)
+		if err != nil {
+			src.Close()
+			return nil, nil, err
+		}
+
+		m.Log = migrateLogger{logger}
+		return m, func() { m.Close() }, nil
+	}
+
+	driver, err := sqliteDriver(db.DB.DB)
+	if err != nil {
+		src.Close()
+		return nil, nil, err
+	}
+
+	m, err := migrate.NewWithInstance("migrations", src, models.DriverSQLite, driver)
+	if err != nil {
+		src.Close()
+		return nil, nil, err
 	}
 
-	println("Running Migrations...")
-	m, err := migrate.New(
-		"file://migrations/migrations",
-		viper.GetString("DATABASE_URL"))
+	// Closing m would close db along with the migrations, so only the migrations are closed
+	m.Log = migrateLogger{logger}
+	return m, func() { src.Close() }, nil
+}
+
+// Up applies every pending migration
+func Up(logger *utils.Logger, db *models.Database) error {
+	m, release, err := open(logger, db)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	defer release()
+
+	err = m.Up()
+	if err == migrate.ErrNoChange {
+		logger.Info().Msg("Database schema is up to date")
+		return nil
+	}
+
+	return err
+}
+
+// Down rolls back the last steps migrations that were applied
+func Down(logger *utils.Logger, db *models.Database, steps int) error {
+	if steps <= 0 {
+		return errors.New("Steps must be positive")
 	}
-	if err := m.Up(); err != nil {
-		log.Print(err)
+
+	m, release, err := open(logger, db)
+	if err != nil {
+		return err
 	}
-	// Uncomment the below lines for migrate down!
-	//if err := m.Down(); err != nil {
-	//	log.Fatal(err)
-	//}
+	defer release()
+
+	return m.Steps(-steps)
+}
+
+// CurrentStatus reports the schema version of the database along with the migrations that are pending
+func CurrentStatus(logger *utils.Logger, db *models.Database) (*Status, error) {
+	m, release, err := open(logger, db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	status := Status{}
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return nil, err
+	}
+
+	src, err := openSource(db)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	for err == nil {
+		if version > status.Version {
+			migration, identifier, err := src.ReadUp(version)
+			if err != nil {
+				return nil, err
+			}
+			migration.Close()
+			status.Pending = append(status.Pending, fmt.Sprintf("%d_%s", version, identifier))
+		}
+
+		version, err = src.Next(version)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return &status, nil
 }
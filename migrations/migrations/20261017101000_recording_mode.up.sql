@@ -0,0 +1 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS recording_mode TEXT NOT NULL DEFAULT 'mix';
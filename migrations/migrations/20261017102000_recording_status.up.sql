@@ -0,0 +1 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS recording_status TEXT;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS recordings (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    sid TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    track_type TEXT,
+    uid TEXT,
+    is_playable BOOLEAN NOT NULL DEFAULT TRUE,
+    slice_start_time BIGINT,
+    CONSTRAINT recordings_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_recording_file unique (sid, file_name)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS recordings_created_at_idx;
+ALTER TABLE channels DROP COLUMN IF EXISTS recording_retention_days;
@@ -0,0 +1,2 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS recording_retention_days INT;
+CREATE INDEX IF NOT EXISTS recordings_created_at_idx ON recordings (created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS channel_storage (
+    channel_id INT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    provider TEXT NOT NULL,
+    region INT NOT NULL DEFAULT 0,
+    bucket TEXT NOT NULL,
+    access_key TEXT NOT NULL,
+    secret_key TEXT NOT NULL,
+    CONSTRAINT channel_storage_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE channels DROP COLUMN IF EXISTS allow_viewers_to_publish;
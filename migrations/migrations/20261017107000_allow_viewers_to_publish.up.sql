@@ -0,0 +1 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS allow_viewers_to_publish BOOLEAN NOT NULL DEFAULT TRUE;
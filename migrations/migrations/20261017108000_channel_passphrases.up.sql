@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS channel_passphrases (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    passphrase TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    CONSTRAINT channel_passphrases_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_passphrase unique (passphrase)
+);
+
+INSERT INTO channel_passphrases (channel_id, passphrase, name, role)
+    SELECT id, host_passphrase, 'Host', 'HOST' FROM channels
+    ON CONFLICT DO NOTHING;
+
+INSERT INTO channel_passphrases (channel_id, passphrase, name, role)
+    SELECT id, viewer_passphrase, 'Viewer', 'VIEWER' FROM channels WHERE viewer_passphrase IS NOT NULL
+    ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS channels_viewer_passphrase_idx;
+DROP INDEX IF EXISTS channels_host_passphrase_idx;
@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX IF NOT EXISTS channels_host_passphrase_idx ON channels (host_passphrase);
+CREATE UNIQUE INDEX IF NOT EXISTS channels_viewer_passphrase_idx ON channels (viewer_passphrase);
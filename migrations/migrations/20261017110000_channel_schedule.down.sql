@@ -0,0 +1,2 @@
+ALTER TABLE channels DROP COLUMN IF EXISTS ends_at;
+ALTER TABLE channels DROP COLUMN IF EXISTS starts_at;
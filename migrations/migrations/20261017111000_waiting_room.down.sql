@@ -0,0 +1,2 @@
+DROP TABLE lobby;
+ALTER TABLE channels DROP COLUMN IF EXISTS waiting_room;
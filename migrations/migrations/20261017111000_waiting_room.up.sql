@@ -0,0 +1,12 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS waiting_room BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS lobby (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    lobby_id TEXT NOT NULL,
+    name TEXT,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    CONSTRAINT lobby_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_lobby_id unique (lobby_id)
+);
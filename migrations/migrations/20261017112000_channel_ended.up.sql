@@ -0,0 +1 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS ended_at TIMESTAMP WITH TIME ZONE;
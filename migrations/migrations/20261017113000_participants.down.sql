@@ -0,0 +1,2 @@
+DROP TABLE channel_bans;
+DROP TABLE participants;
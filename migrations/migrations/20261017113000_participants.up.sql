@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS participants (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid INT NOT NULL,
+    screen_share_uid INT,
+    name TEXT,
+    user_id INT,
+    CONSTRAINT participants_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT participants_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT unique_participant_uid unique (channel_id, uid)
+);
+
+CREATE TABLE IF NOT EXISTS channel_bans (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid INT NOT NULL,
+    user_id INT,
+    banned_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT channel_bans_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT channel_bans_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS channel_bans_channel_idx ON channel_bans (channel_id, banned_until);
@@ -0,0 +1,2 @@
+ALTER TABLE channels DROP COLUMN IF EXISTS max_participants;
+ALTER TABLE channels DROP COLUMN IF EXISTS locked;
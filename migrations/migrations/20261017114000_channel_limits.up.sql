@@ -0,0 +1,2 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS max_participants INT;
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS attendance (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid BIGINT NOT NULL,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    left_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT attendance_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_attendance_join unique (channel_id, uid, joined_at)
+);
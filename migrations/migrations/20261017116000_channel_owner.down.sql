@@ -0,0 +1,2 @@
+DROP TABLE host_transfers;
+ALTER TABLE channels DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,12 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS host_transfers (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    from_user_id INT,
+    to_user_id INT,
+    CONSTRAINT host_transfers_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT host_transfers_from_fkey FOREIGN KEY (from_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT host_transfers_to_fkey FOREIGN KEY (to_user_id) REFERENCES users (id) ON DELETE SET NULL
+);
@@ -0,0 +1 @@
+DROP TABLE pstn_numbers;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS pstn_numbers (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    country TEXT NOT NULL,
+    region TEXT,
+    number TEXT NOT NULL,
+    CONSTRAINT unique_pstn_number unique (country, number)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS live_streams (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    converter_id TEXT NOT NULL,
+    rtmp_url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    stopped_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT live_streams_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_converter_id unique (converter_id)
+);
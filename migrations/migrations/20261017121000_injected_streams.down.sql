@@ -0,0 +1 @@
+DROP TABLE injected_streams;
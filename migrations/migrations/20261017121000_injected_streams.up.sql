@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS injected_streams (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    player_id TEXT NOT NULL,
+    uid BIGINT NOT NULL,
+    stream_url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    stopped_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT injected_streams_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_player_id unique (player_id)
+);
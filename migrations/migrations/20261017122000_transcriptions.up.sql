@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS transcriptions (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    task_id TEXT NOT NULL,
+    builder_token TEXT NOT NULL,
+    language TEXT NOT NULL,
+    file_prefix TEXT NOT NULL,
+    status TEXT NOT NULL,
+    stopped_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT transcriptions_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_transcription_task unique (task_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE recording_transcript_segments;
+DROP TABLE recording_transcripts;
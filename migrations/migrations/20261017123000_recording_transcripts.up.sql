@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS recording_transcripts (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    sid TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    CONSTRAINT recording_transcripts_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_recording_transcript_sid unique (sid)
+);
+
+CREATE TABLE IF NOT EXISTS recording_transcript_segments (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transcript_id INT NOT NULL,
+    start_ms BIGINT NOT NULL,
+    end_ms BIGINT NOT NULL,
+    text TEXT NOT NULL,
+    CONSTRAINT recording_transcript_segments_fkey FOREIGN KEY (transcript_id) REFERENCES recording_transcripts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS recording_transcript_segments_idx ON recording_transcript_segments (transcript_id, start_ms);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS channel_messages (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid INT NOT NULL,
+    name TEXT,
+    user_id INT,
+    body TEXT NOT NULL,
+    CONSTRAINT channel_messages_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT channel_messages_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS channel_messages_channel_idx ON channel_messages (channel_id, id);
@@ -0,0 +1 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS whiteboard_room_uuid TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE participants ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'FULL';
+ALTER TABLE lobby ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'FULL';
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS polls (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    poll_id TEXT NOT NULL UNIQUE,
+    question TEXT NOT NULL,
+    options TEXT[] NOT NULL,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT polls_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS polls_channel_idx ON polls (channel_id);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    poll_id INT NOT NULL,
+    uid INT NOT NULL,
+    option INT NOT NULL,
+    CONSTRAINT poll_votes_poll_fkey FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT unique_poll_vote unique (poll_id, uid)
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS questions (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    question_id TEXT NOT NULL UNIQUE,
+    uid INT,
+    name TEXT,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    upvotes INT NOT NULL DEFAULT 0,
+    CONSTRAINT questions_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS questions_channel_idx ON questions (channel_id);
+
+CREATE TABLE IF NOT EXISTS question_votes (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    question_id INT NOT NULL,
+    uid INT NOT NULL,
+    CONSTRAINT question_votes_question_fkey FOREIGN KEY (question_id) REFERENCES questions (id) ON DELETE CASCADE,
+    CONSTRAINT unique_question_vote unique (question_id, uid)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE channels DROP COLUMN IF EXISTS locked_until;
+DROP TABLE passphrase_attempts;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS passphrase_attempts (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    ip TEXT NOT NULL,
+    channel_id INT,
+    succeeded BOOLEAN NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT passphrase_attempts_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS passphrase_attempts_ip_idx ON passphrase_attempts (ip, created_at);
+CREATE INDEX IF NOT EXISTS passphrase_attempts_channel_idx ON passphrase_attempts (channel_id);
+
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
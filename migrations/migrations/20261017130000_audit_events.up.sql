@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INT,
+    ip TEXT,
+    request_id TEXT,
+    operation TEXT NOT NULL,
+    channel_id INT,
+    arguments_hash TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error_code TEXT,
+    CONSTRAINT audit_events_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT audit_events_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_channel_idx ON audit_events (channel_id);
+CREATE INDEX IF NOT EXISTS audit_events_user_idx ON audit_events (user_id);
+CREATE INDEX IF NOT EXISTS audit_events_operation_idx ON audit_events (operation);
@@ -0,0 +1,5 @@
+DROP TABLE refresh_tokens;
+DROP INDEX IF EXISTS tokens_family_idx;
+DROP INDEX IF EXISTS tokens_token_idx;
+ALTER TABLE tokens DROP COLUMN IF EXISTS family_id;
+ALTER TABLE tokens DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,20 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family_id TEXT;
+
+CREATE INDEX IF NOT EXISTS tokens_token_idx ON tokens (token_id);
+CREATE INDEX IF NOT EXISTS tokens_family_idx ON tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INT NOT NULL,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT refresh_tokens_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_refresh_token_hash unique (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);
@@ -0,0 +1,4 @@
+ALTER TABLE tokens DROP COLUMN IF EXISTS location;
+ALTER TABLE tokens DROP COLUMN IF EXISTS ip;
+ALTER TABLE tokens DROP COLUMN IF EXISTS user_agent;
+ALTER TABLE tokens DROP COLUMN IF EXISTS last_used_at;
@@ -0,0 +1,4 @@
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS ip TEXT;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS location TEXT;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
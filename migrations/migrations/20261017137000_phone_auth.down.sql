@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS phone_codes;
+DROP INDEX IF EXISTS users_phone_number_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_number;
+DELETE FROM users WHERE email IS NULL;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
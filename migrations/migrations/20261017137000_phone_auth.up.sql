@@ -0,0 +1,15 @@
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_number_idx ON users (phone_number);
+
+CREATE TABLE IF NOT EXISTS phone_codes (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    phone_number TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS phone_codes_phone_number_idx ON phone_codes (phone_number);
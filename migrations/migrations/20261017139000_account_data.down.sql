@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS data_exports;
+ALTER TABLE recordings DROP COLUMN IF EXISTS delete_after;
@@ -0,0 +1,15 @@
+ALTER TABLE recordings ADD COLUMN IF NOT EXISTS delete_after TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    archive BYTEA,
+    CONSTRAINT data_exports_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_idx ON data_exports (user_id);
+CREATE INDEX IF NOT EXISTS data_exports_status_idx ON data_exports (status);
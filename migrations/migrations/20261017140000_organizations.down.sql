@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS channels_organization_idx;
+ALTER TABLE channels DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
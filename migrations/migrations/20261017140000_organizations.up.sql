@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    organization_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role TEXT NOT NULL,
+    CONSTRAINT organization_members_organization_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT organization_members_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_organization_member unique (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_idx ON organization_members (user_id);
+
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS organization_id INT REFERENCES organizations (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS channels_organization_idx ON channels (organization_id);
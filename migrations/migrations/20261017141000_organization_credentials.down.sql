@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS organization_storage;
+DROP TABLE IF EXISTS organization_credentials;
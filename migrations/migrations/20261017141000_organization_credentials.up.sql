@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS organization_credentials (
+    organization_id INT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    app_id TEXT NOT NULL,
+    app_certificate TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    customer_certificate TEXT NOT NULL,
+    CONSTRAINT organization_credentials_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS organization_storage (
+    organization_id INT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    provider TEXT NOT NULL,
+    region INT NOT NULL DEFAULT 0,
+    bucket TEXT NOT NULL,
+    access_key TEXT NOT NULL,
+    secret_key TEXT NOT NULL,
+    CONSTRAINT organization_storage_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS usage_records;
+DROP INDEX IF EXISTS attendance_unmetered_idx;
+ALTER TABLE attendance DROP COLUMN IF EXISTS metered_at;
+ALTER TABLE channels DROP COLUMN IF EXISTS recording_started_at;
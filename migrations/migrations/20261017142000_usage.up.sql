@@ -0,0 +1,36 @@
+ALTER TABLE channels ADD COLUMN IF NOT EXISTS recording_started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS metered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS usage_records (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    user_id INT,
+    organization_id INT,
+    channel_id INT,
+    metric TEXT NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    source TEXT NOT NULL,
+    CONSTRAINT usage_records_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT usage_records_organization_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT usage_records_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL,
+    CONSTRAINT unique_usage_source unique (metric, source)
+);
+
+CREATE INDEX IF NOT EXISTS usage_records_user_idx ON usage_records (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS usage_records_organization_idx ON usage_records (organization_id, occurred_at);
+CREATE INDEX IF NOT EXISTS attendance_unmetered_idx ON attendance (id) WHERE left_at IS NOT NULL AND metered_at IS NULL;
+
+-- Usage that happened before metering existed is recorded when it happened, so that it does not count against the
+-- quotas of the month metering started in
+INSERT INTO usage_records (occurred_at, user_id, organization_id, channel_id, metric, quantity, source)
+    SELECT created_at, owner_id, organization_id, id, 'channels', 1, 'channel:' || id FROM channels
+    ON CONFLICT (metric, source) DO NOTHING;
+
+INSERT INTO usage_records (occurred_at, user_id, organization_id, channel_id, metric, quantity, source)
+    SELECT attendance.left_at, channels.owner_id, channels.organization_id, channels.id, 'participant_minutes',
+    EXTRACT(EPOCH FROM attendance.left_at - attendance.joined_at) / 60, 'attendance:' || attendance.id
+    FROM attendance INNER JOIN channels ON channels.id = attendance.channel_id WHERE attendance.left_at IS NOT NULL
+    ON CONFLICT (metric, source) DO NOTHING;
+
+UPDATE attendance SET metered_at = CURRENT_TIMESTAMP WHERE left_at IS NOT NULL;
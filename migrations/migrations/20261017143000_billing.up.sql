@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS plans (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    name TEXT NOT NULL,
+    stripe_price_id TEXT NOT NULL,
+    channels_per_month INT,
+    recording_minutes_per_month INT,
+    participant_minutes_per_month INT,
+    pstn_calls_per_month INT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT unique_plan_price unique (stripe_price_id)
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INT,
+    organization_id INT,
+    plan_id INT NOT NULL,
+    stripe_customer_id TEXT NOT NULL,
+    stripe_subscription_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    current_period_end TIMESTAMP WITH TIME ZONE,
+    event_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT subscriptions_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT subscriptions_organization_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT subscriptions_plan_fkey FOREIGN KEY (plan_id) REFERENCES plans (id),
+    CONSTRAINT unique_stripe_subscription unique (stripe_subscription_id),
+    CONSTRAINT subscription_subject CHECK ((user_id IS NULL) <> (organization_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_user_idx ON subscriptions (user_id);
+CREATE INDEX IF NOT EXISTS subscriptions_organization_idx ON subscriptions (organization_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    event_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    channel_id INT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    user_id INT,
+    medium TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    sent BOOLEAN NOT NULL,
+    CONSTRAINT invitations_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT invitations_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS invitations_user_idx ON invitations (user_id, created_at);
+CREATE INDEX IF NOT EXISTS invitations_channel_idx ON invitations (channel_id, created_at);
@@ -0,0 +1,6 @@
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS sent BOOLEAN;
+UPDATE invitations SET sent = status NOT IN ('pending', 'failed', 'undelivered');
+ALTER TABLE invitations ALTER COLUMN sent SET NOT NULL;
+ALTER TABLE invitations DROP COLUMN IF EXISTS message_id;
+ALTER TABLE invitations DROP COLUMN IF EXISTS status;
+ALTER TABLE invitations DROP COLUMN IF EXISTS updated_at;
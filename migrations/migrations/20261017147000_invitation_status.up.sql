@@ -0,0 +1,8 @@
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS status TEXT;
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS message_id TEXT;
+
+UPDATE invitations SET status = CASE WHEN sent THEN 'sent' ELSE 'failed' END WHERE status IS NULL;
+
+ALTER TABLE invitations ALTER COLUMN status SET NOT NULL;
+ALTER TABLE invitations DROP COLUMN IF EXISTS sent;
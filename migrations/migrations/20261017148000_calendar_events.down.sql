@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS calendar_events;
+
+DROP INDEX IF EXISTS credentials_user_idx;
+ALTER TABLE credentials DROP CONSTRAINT IF EXISTS credentials_user_fkey;
+ALTER TABLE credentials DROP COLUMN IF EXISTS scope;
+ALTER TABLE credentials DROP COLUMN IF EXISTS provider;
+ALTER TABLE credentials DROP COLUMN IF EXISTS user_id;
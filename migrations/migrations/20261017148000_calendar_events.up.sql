@@ -0,0 +1,20 @@
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS user_id INT;
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS provider TEXT;
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS scope TEXT;
+ALTER TABLE credentials ADD CONSTRAINT credentials_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS credentials_user_idx ON credentials (user_id, provider);
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    user_id INT NOT NULL,
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    attendees TEXT[] NOT NULL DEFAULT '{}',
+    CONSTRAINT calendar_events_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT calendar_events_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_calendar_event unique (channel_id, user_id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS short_links (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    slug TEXT NOT NULL,
+    channel_id INT NOT NULL,
+    passphrase TEXT NOT NULL,
+    CONSTRAINT short_links_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_short_link_slug unique (slug)
+);
+
+CREATE INDEX IF NOT EXISTS short_links_passphrase_idx ON short_links (passphrase);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS quality_stats (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid BIGINT NOT NULL,
+    reported_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rtt INT,
+    uplink_packet_loss REAL,
+    downlink_packet_loss REAL,
+    width INT,
+    height INT,
+    frame_rate INT,
+    CONSTRAINT quality_stats_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS quality_stats_channel_uid ON quality_stats (channel_id, uid, reported_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS support_tickets (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT,
+    uid BIGINT,
+    user_id INT,
+    description TEXT NOT NULL,
+    log_key TEXT,
+    CONSTRAINT support_tickets_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL,
+    CONSTRAINT support_tickets_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS support_tickets_created_at ON support_tickets (created_at);
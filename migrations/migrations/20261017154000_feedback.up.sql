@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS feedback (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    channel_id INT NOT NULL,
+    uid BIGINT,
+    user_id INT,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT,
+    CONSTRAINT feedback_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT feedback_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT unique_feedback_uid unique (channel_id, uid)
+);
+
+CREATE INDEX IF NOT EXISTS feedback_created_at ON feedback (created_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS attendance_joined_at_idx;
+DROP INDEX IF EXISTS attendance_left_at_idx;
+DROP INDEX IF EXISTS pstn_calls_created_at_idx;
+DROP INDEX IF EXISTS usage_records_metric_idx;
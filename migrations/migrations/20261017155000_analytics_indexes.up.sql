@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS attendance_joined_at_idx ON attendance (joined_at);
+CREATE INDEX IF NOT EXISTS attendance_left_at_idx ON attendance (left_at);
+CREATE INDEX IF NOT EXISTS pstn_calls_created_at_idx ON pstn_calls (created_at);
+CREATE INDEX IF NOT EXISTS usage_records_metric_idx ON usage_records (metric, occurred_at);
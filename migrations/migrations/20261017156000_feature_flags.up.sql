@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    feature TEXT NOT NULL,
+    organization_id INT,
+    channel_id INT,
+    enabled BOOLEAN NOT NULL,
+    CONSTRAINT feature_flags_organization_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT feature_flags_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT feature_flags_scope CHECK (organization_id IS NULL OR channel_id IS NULL)
+);
+
+-- A flag is set once per scope: globally, for an organization or for a channel
+CREATE UNIQUE INDEX IF NOT EXISTS unique_feature_flag ON feature_flags (feature, COALESCE(organization_id, 0), COALESCE(channel_id, 0));
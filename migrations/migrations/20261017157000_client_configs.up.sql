@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS client_configs (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    version INT NOT NULL,
+    config JSONB NOT NULL,
+    created_by INT,
+    CONSTRAINT client_configs_user_fkey FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT unique_client_config_version unique (version)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS persisted_operations (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    hash TEXT NOT NULL,
+    name TEXT,
+    document TEXT NOT NULL,
+    created_by INT,
+    CONSTRAINT persisted_operations_user_fkey FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT unique_persisted_operation_hash unique (hash)
+);
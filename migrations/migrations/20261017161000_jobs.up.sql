@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id INT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    kind TEXT NOT NULL,
+    unique_key TEXT,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    CONSTRAINT unique_job_key unique (kind, unique_key)
+);
+
+CREATE INDEX IF NOT EXISTS jobs_due_idx ON jobs (run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS jobs_status_idx ON jobs (status, id);
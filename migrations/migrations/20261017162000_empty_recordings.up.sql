@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS empty_recordings (
+    sid TEXT PRIMARY KEY,
+    empty_since TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS totp_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_failures;
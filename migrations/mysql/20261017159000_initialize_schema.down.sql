@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS recordings;
+DROP TABLE IF EXISTS host_transfers;
+DROP TABLE IF EXISTS channel_passphrases;
+DROP TABLE IF EXISTS channels;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,110 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    identifier VARCHAR(255) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    user_name TEXT,
+    email VARCHAR(255),
+    roles TEXT NOT NULL DEFAULT ('{}'),
+    provider VARCHAR(32),
+    password_hash TEXT,
+    email_verified_at DATETIME,
+    phone_number VARCHAR(32),
+    totp_secret TEXT,
+    totp_enabled_at DATETIME,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT unique_email UNIQUE (email),
+    CONSTRAINT users_phone_number_idx UNIQUE (phone_number)
+);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    token_id VARCHAR(255),
+    user_id BIGINT,
+    expires_at DATETIME,
+    family_id VARCHAR(255),
+    last_used_at DATETIME,
+    user_agent TEXT,
+    ip VARCHAR(64),
+    location TEXT,
+    CONSTRAINT tokens_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX tokens_token_idx (token_id),
+    INDEX tokens_family_idx (family_id)
+);
+
+CREATE TABLE IF NOT EXISTS channels (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    title TEXT NOT NULL,
+    channel_name VARCHAR(255) NOT NULL,
+    channel_secret TEXT,
+    host_passphrase VARCHAR(255) NOT NULL,
+    viewer_passphrase VARCHAR(255),
+    recording_uid INT,
+    recording_sid VARCHAR(255),
+    recording_rid TEXT,
+    dtmf VARCHAR(32),
+    recording_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    recording_mode VARCHAR(32) NOT NULL DEFAULT 'mix',
+    recording_status VARCHAR(32),
+    recording_retention_days INT,
+    token_expiry_seconds INT,
+    allow_viewers_to_publish BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at DATETIME,
+    ends_at DATETIME,
+    waiting_room BOOLEAN NOT NULL DEFAULT FALSE,
+    ended_at DATETIME,
+    max_participants INT,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    owner_id BIGINT,
+    sip_uri TEXT,
+    whiteboard_room_uuid TEXT,
+    locked_until DATETIME,
+    organization_id BIGINT,
+    recording_started_at DATETIME,
+    metadata JSON NOT NULL DEFAULT (JSON_OBJECT()),
+    recording_quality VARCHAR(32),
+    area VARCHAR(32),
+    CONSTRAINT channels_owner_fkey FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT channels_host_passphrase_idx UNIQUE (host_passphrase),
+    CONSTRAINT channels_viewer_passphrase_idx UNIQUE (viewer_passphrase),
+    INDEX channels_organization_idx (organization_id)
+);
+
+CREATE TABLE IF NOT EXISTS channel_passphrases (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    channel_id BIGINT NOT NULL,
+    passphrase VARCHAR(255) NOT NULL,
+    name TEXT NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    CONSTRAINT channel_passphrases_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_passphrase UNIQUE (passphrase)
+);
+
+CREATE TABLE IF NOT EXISTS host_transfers (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    channel_id BIGINT NOT NULL,
+    from_user_id BIGINT,
+    to_user_id BIGINT,
+    CONSTRAINT host_transfers_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT host_transfers_from_fkey FOREIGN KEY (from_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT host_transfers_to_fkey FOREIGN KEY (to_user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS recordings (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    channel_id BIGINT NOT NULL,
+    sid VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    track_type VARCHAR(32),
+    uid VARCHAR(64),
+    is_playable BOOLEAN NOT NULL DEFAULT TRUE,
+    slice_start_time BIGINT,
+    delete_after DATETIME,
+    CONSTRAINT recordings_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_recording_file UNIQUE (sid, file_name),
+    INDEX recordings_created_at_idx (created_at)
+);
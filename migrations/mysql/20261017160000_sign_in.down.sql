@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS passphrase_attempts;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS credentials;
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,73 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    user_id BIGINT NOT NULL,
+    family_id VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    revoked_at DATETIME,
+    CONSTRAINT refresh_tokens_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_refresh_token_hash UNIQUE (token_hash),
+    INDEX refresh_tokens_family_idx (family_id)
+);
+
+CREATE TABLE IF NOT EXISTS credentials (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    code TEXT NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    token_type VARCHAR(32) NOT NULL,
+    expiry DATETIME,
+    user_id BIGINT,
+    provider VARCHAR(32),
+    scope TEXT,
+    CONSTRAINT credentials_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    INDEX credentials_user_idx (user_id, provider)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    key_hash VARCHAR(255) NOT NULL,
+    scopes TEXT NOT NULL DEFAULT ('{}'),
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    CONSTRAINT api_keys_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_api_key_hash UNIQUE (key_hash),
+    INDEX api_keys_user_idx (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS passphrase_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    ip VARCHAR(64) NOT NULL,
+    channel_id BIGINT,
+    succeeded BOOLEAN NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT passphrase_attempts_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    INDEX passphrase_attempts_ip_idx (ip, created_at),
+    INDEX passphrase_attempts_channel_idx (channel_id)
+);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    user_id BIGINT,
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    operation VARCHAR(255) NOT NULL,
+    channel_id BIGINT,
+    arguments_hash VARCHAR(255) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error_code VARCHAR(64),
+    CONSTRAINT audit_events_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT audit_events_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL,
+    INDEX audit_events_channel_idx (channel_id),
+    INDEX audit_events_user_idx (user_id),
+    INDEX audit_events_operation_idx (operation)
+);
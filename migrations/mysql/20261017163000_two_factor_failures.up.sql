@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN totp_failures INT NOT NULL DEFAULT 0, ADD COLUMN totp_locked_until DATETIME;
@@ -0,0 +1,26 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+//go:build !sqlite
+// +build !sqlite
+
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/golang-migrate/migrate/v4/database"
+)
+
+// sqliteDriver reports that SQLite databases cannot be migrated by binaries built without the sqlite tag
+func sqliteDriver(db *sql.DB) (database.Driver, error) {
+	return nil, errors.New("SQLite support is not built in, build with -tags sqlite")
+}
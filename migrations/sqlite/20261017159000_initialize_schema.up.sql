@@ -0,0 +1,113 @@
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    identifier TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_name TEXT,
+    email TEXT,
+    roles TEXT NOT NULL DEFAULT '{}',
+    provider TEXT,
+    password_hash TEXT,
+    email_verified_at TIMESTAMP,
+    phone_number TEXT,
+    totp_secret TEXT,
+    totp_enabled_at TIMESTAMP,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT unique_email UNIQUE (email)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_number_idx ON users (phone_number);
+
+CREATE TABLE IF NOT EXISTS tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    token_id TEXT,
+    user_id INTEGER,
+    expires_at TIMESTAMP,
+    family_id TEXT,
+    last_used_at TIMESTAMP,
+    user_agent TEXT,
+    ip TEXT,
+    location TEXT,
+    CONSTRAINT tokens_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS tokens_token_idx ON tokens (token_id);
+CREATE INDEX IF NOT EXISTS tokens_family_idx ON tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    title TEXT NOT NULL,
+    channel_name TEXT NOT NULL,
+    channel_secret TEXT,
+    host_passphrase TEXT NOT NULL,
+    viewer_passphrase TEXT,
+    recording_uid INTEGER,
+    recording_sid TEXT,
+    recording_rid TEXT,
+    dtmf TEXT,
+    recording_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    recording_mode TEXT NOT NULL DEFAULT 'mix',
+    recording_status TEXT,
+    recording_retention_days INTEGER,
+    token_expiry_seconds INTEGER,
+    allow_viewers_to_publish BOOLEAN NOT NULL DEFAULT TRUE,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    waiting_room BOOLEAN NOT NULL DEFAULT FALSE,
+    ended_at TIMESTAMP,
+    max_participants INTEGER,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    sip_uri TEXT,
+    whiteboard_room_uuid TEXT,
+    locked_until TIMESTAMP,
+    organization_id INTEGER,
+    recording_started_at TIMESTAMP,
+    metadata TEXT NOT NULL DEFAULT '{}',
+    recording_quality TEXT,
+    area TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channels_host_passphrase_idx ON channels (host_passphrase);
+CREATE UNIQUE INDEX IF NOT EXISTS channels_viewer_passphrase_idx ON channels (viewer_passphrase);
+CREATE INDEX IF NOT EXISTS channels_organization_idx ON channels (organization_id);
+
+CREATE TABLE IF NOT EXISTS channel_passphrases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    channel_id INTEGER NOT NULL,
+    passphrase TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    CONSTRAINT channel_passphrases_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_passphrase UNIQUE (passphrase)
+);
+
+CREATE TABLE IF NOT EXISTS host_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    channel_id INTEGER NOT NULL,
+    from_user_id INTEGER,
+    to_user_id INTEGER,
+    CONSTRAINT host_transfers_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT host_transfers_from_fkey FOREIGN KEY (from_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT host_transfers_to_fkey FOREIGN KEY (to_user_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS recordings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    channel_id INTEGER NOT NULL,
+    sid TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    track_type TEXT,
+    uid TEXT,
+    is_playable BOOLEAN NOT NULL DEFAULT TRUE,
+    slice_start_time BIGINT,
+    delete_after TIMESTAMP,
+    CONSTRAINT recordings_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE,
+    CONSTRAINT unique_recording_file UNIQUE (sid, file_name)
+);
+
+CREATE INDEX IF NOT EXISTS recordings_created_at_idx ON recordings (created_at);
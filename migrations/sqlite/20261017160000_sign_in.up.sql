@@ -0,0 +1,78 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CONSTRAINT refresh_tokens_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_refresh_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS credentials (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    token_type TEXT NOT NULL,
+    expiry TIMESTAMP,
+    user_id INTEGER,
+    provider TEXT,
+    scope TEXT,
+    CONSTRAINT credentials_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS credentials_user_idx ON credentials (user_id, provider);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CONSTRAINT api_keys_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT unique_api_key_hash UNIQUE (key_hash)
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_idx ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS passphrase_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ip TEXT NOT NULL,
+    channel_id INTEGER,
+    succeeded BOOLEAN NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT passphrase_attempts_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS passphrase_attempts_ip_idx ON passphrase_attempts (ip, created_at);
+CREATE INDEX IF NOT EXISTS passphrase_attempts_channel_idx ON passphrase_attempts (channel_id);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER,
+    ip TEXT,
+    request_id TEXT,
+    operation TEXT NOT NULL,
+    channel_id INTEGER,
+    arguments_hash TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error_code TEXT,
+    CONSTRAINT audit_events_user_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT audit_events_channel_fkey FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_channel_idx ON audit_events (channel_id);
+CREATE INDEX IF NOT EXISTS audit_events_user_idx ON audit_events (user_id);
+CREATE INDEX IF NOT EXISTS audit_events_operation_idx ON audit_events (operation);
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN totp_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_locked_until TIMESTAMP;
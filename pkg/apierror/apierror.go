@@ -0,0 +1,142 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// Package apierror attaches machine readable codes to the errors returned by the API, so that clients can
+// branch on the code in the extensions of a GraphQL error instead of matching its message
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Code identifies the kind of failure an error represents
+type Code string
+
+// Codes returned in the "code" extension of GraphQL errors
+const (
+	CodeInternal               Code = "INTERNAL_SERVER_ERROR"
+	CodeBadRequest             Code = "BAD_REQUEST"
+	CodeNotFound               Code = "NOT_FOUND"
+	CodeTokenExpired           Code = "TOKEN_EXPIRED"
+	CodeChannelNotFound        Code = "CHANNEL_NOT_FOUND"
+	CodeNotHost                Code = "NOT_HOST"
+	CodeMeetingNotStarted      Code = "MEETING_NOT_STARTED"
+	CodeMeetingEnded           Code = "MEETING_ENDED"
+	CodeChannelLocked          Code = "CHANNEL_LOCKED"
+	CodeChannelFull            Code = "CHANNEL_FULL"
+	CodeBanned                 Code = "BANNED"
+	CodePassphraseTaken        Code = "PASSPHRASE_TAKEN"
+	CodeRecordingAlreadyActive Code = "RECORDING_ALREADY_ACTIVE"
+	CodeRecordingNotActive     Code = "RECORDING_NOT_ACTIVE"
+	CodeUnavailable            Code = "UNAVAILABLE"
+	CodeRateLimited            Code = "RATE_LIMITED"
+	CodeForbidden              Code = "FORBIDDEN"
+	CodeInvalidCredentials     Code = "INVALID_CREDENTIALS"
+	CodeEmailNotVerified       Code = "EMAIL_NOT_VERIFIED"
+	CodeTwoFactorRequired      Code = "TWO_FACTOR_REQUIRED"
+	CodeQuotaExceeded          Code = "QUOTA_EXCEEDED"
+	CodeFeatureDisabled        Code = "FEATURE_DISABLED"
+	CodeServiceDegraded        Code = "SERVICE_DEGRADED"
+	CodeAgoraError             Code = "AGORA_ERROR"
+)
+
+// Error is an error with a code. Its message is returned to clients as is, along with its details in the extensions
+// of the GraphQL error
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates an error with a code
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WithDetails creates an error with a code and details for clients to branch on
+func WithDetails(code Code, message string, details map[string]interface{}) *Error {
+	return &Error{Code: code, Message: message, Details: details}
+}
+
+// CodeOf returns the code of the first error with a code in the chain of err, or an empty code if there is none
+func CodeOf(err error) Code {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+
+	return ""
+}
+
+// Presenter is a gqlgen error presenter that adds the code and details of an error to the extensions of the GraphQL
+// error
+func Presenter(ctx context.Context, e error) *gqlerror.Error {
+	err := graphql.DefaultErrorPresenter(ctx, e)
+
+	var apiErr *Error
+	if errors.As(e, &apiErr) {
+		if err.Extensions == nil {
+			err.Extensions = map[string]interface{}{}
+		}
+		for key, value := range apiErr.Details {
+			err.Extensions[key] = value
+		}
+		err.Extensions["code"] = apiErr.Code
+	}
+
+	return err
+}
+
+// statuses are the HTTP statuses of the codes for APIs other than GraphQL, which always answers with 200
+var statuses = map[Code]int{
+	CodeInternal:               http.StatusInternalServerError,
+	CodeBadRequest:             http.StatusBadRequest,
+	CodeNotFound:               http.StatusNotFound,
+	CodeTokenExpired:           http.StatusUnauthorized,
+	CodeChannelNotFound:        http.StatusNotFound,
+	CodeNotHost:                http.StatusForbidden,
+	CodeMeetingNotStarted:      http.StatusConflict,
+	CodeMeetingEnded:           http.StatusGone,
+	CodeChannelLocked:          http.StatusForbidden,
+	CodeChannelFull:            http.StatusConflict,
+	CodeBanned:                 http.StatusForbidden,
+	CodePassphraseTaken:        http.StatusConflict,
+	CodeRecordingAlreadyActive: http.StatusConflict,
+	CodeRecordingNotActive:     http.StatusConflict,
+	CodeUnavailable:            http.StatusServiceUnavailable,
+	CodeRateLimited:            http.StatusTooManyRequests,
+	CodeForbidden:              http.StatusForbidden,
+	CodeInvalidCredentials:     http.StatusUnauthorized,
+	CodeEmailNotVerified:       http.StatusForbidden,
+	CodeTwoFactorRequired:      http.StatusForbidden,
+	CodeQuotaExceeded:          http.StatusPaymentRequired,
+	CodeFeatureDisabled:        http.StatusForbidden,
+	CodeServiceDegraded:        http.StatusServiceUnavailable,
+	CodeAgoraError:             http.StatusBadGateway,
+}
+
+// HTTPStatus returns the HTTP status of an error. Errors without a code are messages for the client and answered
+// with 400
+func HTTPStatus(err error) int {
+	if status, ok := statuses[CodeOf(err)]; ok {
+		return status
+	}
+
+	return http.StatusBadRequest
+}
@@ -0,0 +1,97 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/spf13/viper"
+)
+
+// dataExport converts a data export into its GraphQL type. Only ready exports can be downloaded
+func dataExport(export models.UserDataExport) *models.DataExport {
+	result := &models.DataExport{
+		ID:        strconv.FormatInt(export.ID, 10),
+		Status:    models.DataExportStatus(export.Status),
+		CreatedAt: export.CreatedAt,
+	}
+
+	if export.CompletedAt.Valid {
+		result.CompletedAt = &export.CompletedAt.Time
+	}
+
+	if export.ExpiresAt.Valid {
+		result.ExpiresAt = &export.ExpiresAt.Time
+	}
+
+	if result.Status == models.DataExportStatusReady {
+		downloadURL := viper.GetString("BACKEND_URL") + "/exports/" + result.ID
+		result.DownloadURL = &downloadURL
+	}
+
+	return result
+}
+
+// exportMyData requests an export of everything stored about a user, which is built in the background.
+// A pending export is returned instead of requesting another one
+func (r *Resolver) exportMyData(ctx context.Context, user *models.UserAccount) (*models.DataExport, error) {
+	var export models.UserDataExport
+	err := r.DB.GetContext(ctx, &export, `WITH pending AS (
+			SELECT id, created_at, user_id, status, completed_at, expires_at FROM data_exports WHERE user_id = $1 AND status = $2
+		), inserted AS (
+			INSERT INTO data_exports (user_id, status) SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM pending)
+			RETURNING id, created_at, user_id, status, completed_at, expires_at
+		)
+		SELECT * FROM pending UNION ALL SELECT * FROM inserted LIMIT 1`, user.ID, models.DataExportStatusPending)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not request data export")
+		return nil, errInternalServer
+	}
+
+	return dataExport(export), nil
+}
+
+// dataExports lists the data exports of a user that have not expired, newest first
+func (r *Resolver) dataExports(ctx context.Context, user *models.UserAccount) ([]*models.DataExport, error) {
+	exports := []models.UserDataExport{}
+	err := r.DB.SelectContext(ctx, &exports, `SELECT id, created_at, user_id, status, completed_at, expires_at FROM data_exports
+		WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW()) ORDER BY id DESC`, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not get data exports")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.DataExport, len(exports))
+	for index, export := range exports {
+		result[index] = dataExport(export)
+	}
+
+	return result, nil
+}
+
+// deleteMyAccount erases the account of a user, which signs it out everywhere
+func (r *Resolver) deleteMyAccount(ctx context.Context, user *models.UserAccount) (string, error) {
+	deleted, err := services.DeleteAccount(ctx, r.DB, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not delete account")
+		return "", errInternalServer
+	}
+
+	if !deleted {
+		return "", errInvalidToken
+	}
+
+	r.log(ctx).Info().Int64("User ID", user.ID).Msg("Deleted account")
+	return "success", nil
+}
@@ -0,0 +1,223 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+const maxAdminChannelPage = 500
+
+// listAllChannels lists every channel, most recently created first
+func (r *Resolver) listAllChannels(ctx context.Context, before *string, limit int) ([]*models.AdminChannel, error) {
+	if limit <= 0 || limit > maxAdminChannelPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxAdminChannelPage))
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		id, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	channels, err := r.Repos.Channels.List(ctx, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not list channels")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.AdminChannel, len(channels))
+	for index := range channels {
+		result[index] = adminChannel(&channels[index])
+	}
+
+	return result, nil
+}
+
+// adminChannel describes a channel to admins
+func adminChannel(channel *repository.ListedChannel) *models.AdminChannel {
+	result := &models.AdminChannel{
+		ID:          strconv.FormatInt(channel.ID, 10),
+		Title:       channel.Title,
+		ChannelName: channel.ChannelName,
+		OwnerID:     nullableID(channel.OwnerID),
+		CreatedAt:   channel.CreatedAt.Time,
+		Recording:   channel.RecordingSID.Valid,
+		Locked:      channel.Locked,
+	}
+
+	if channel.EndedAt.Valid {
+		result.EndedAt = &channel.EndedAt.Time
+	}
+
+	return result
+}
+
+// forceStopRecording stops the recording running on a channel and clears it from the channel even when Agora fails
+// to stop it, which is how operators recover channels stuck with a recording that no longer exists
+func (r *Resolver) forceStopRecording(ctx context.Context, channelName string) error {
+	channelID, err := r.Repos.Channels.IDByName(ctx, channelName)
+	if err == sql.ErrNoRows {
+		return errChannelNotFound
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("channel", channelName).Msg("Could not fetch channel")
+		return errInternalServer
+	}
+
+	var sid string
+	err = r.DB.WithAdvisoryLock(ctx, models.LockRecording, channelID, func(tx *sqlx.Tx) error {
+		channels := r.Repos.WithTx(tx).Channels
+		current, err := channels.ByID(ctx, channelID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not fetch channel")
+			return errInternalServer
+		}
+
+		if !current.RecordingSID.Valid {
+			return errRecordingNotStarted
+		}
+		sid = current.RecordingSID.String
+
+		recorder, err := r.recorderFor(ctx, current)
+		if err != nil {
+			return err
+		}
+
+		err = r.Recorder.Stop(recorder)
+		if err != nil {
+			r.log(ctx).Warn().Err(err).Str("channel", current.ChannelName).Msg("Stop recording failed, clearing the recording anyway")
+		}
+
+		err = services.MeterRecording(ctx, tx, current.RecordingSID.String)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("sid", current.RecordingSID.String).Msg("Could not meter recording")
+		}
+
+		err = channels.ClearRecording(ctx, channelID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Clearing recording failed")
+			return errInternalServer
+		}
+
+		return nil
+	})
+	if apierror.CodeOf(err) != "" {
+		return err
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not lock channel to stop recording")
+		return errInternalServer
+	}
+
+	services.PublishRecordingUpdate(r.PubSub, channelID, services.RecordingStopped, sid)
+	middleware.SetAuditChannel(ctx, channelID)
+	return nil
+}
+
+// deleteUser deletes a user along with its login tokens. Channels and messages of the user are kept without it
+func (r *Resolver) deleteUser(ctx context.Context, userID string) error {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return apierror.New(apierror.CodeBadRequest, "Invalid user ID")
+	}
+
+	err = r.Repos.Users.Delete(ctx, id)
+	if err == sql.ErrNoRows {
+		return apierror.New(apierror.CodeNotFound, "User not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", id).Msg("Could not delete user")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// setUserRoles replaces the roles of a user
+func (r *Resolver) setUserRoles(ctx context.Context, userID string, roles []models.Role) ([]models.Role, error) {
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid user ID")
+	}
+
+	granted := pq.StringArray{}
+	for _, role := range roles {
+		granted = append(granted, role.String())
+	}
+
+	err = r.Repos.Users.SetRoles(ctx, id, granted)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "User not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", id).Msg("Could not update roles")
+		return nil, errInternalServer
+	}
+
+	return roles, nil
+}
+
+// usageStats counts the users, channels, participants and recordings of the deployment
+func (r *Resolver) usageStats(ctx context.Context) (*models.UsageStats, error) {
+	users, err := r.Repos.Users.Count(ctx)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not count users")
+		return nil, errInternalServer
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	channels, err := r.Repos.Channels.Stats(ctx, since)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not count channels")
+		return nil, errInternalServer
+	}
+
+	recordings, err := r.Repos.Recordings.Count(ctx)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not count recordings")
+		return nil, errInternalServer
+	}
+
+	var participants int
+	err = r.DB.GetContext(ctx, &participants, r.DB.Rebind("SELECT COUNT(*) FROM participants WHERE created_at > ?"), since.UTC())
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not count participants")
+		return nil, errInternalServer
+	}
+
+	return &models.UsageStats{
+		Users:                   users,
+		Channels:                channels.Channels,
+		ChannelsLast24Hours:     channels.CreatedSince,
+		ParticipantsLast24Hours: participants,
+		Recordings:              recordings,
+		ActiveRecordings:        channels.Recording,
+	}, nil
+}
@@ -0,0 +1,105 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *adminChannelResolver) Owner(ctx context.Context, obj *models.AdminChannel) (*models.User, error) {
+	return r.userByID(ctx, obj.OwnerID)
+}
+
+func (r *auditEventResolver) User(ctx context.Context, obj *models.AuditEvent) (*models.User, error) {
+	return r.userByID(ctx, obj.UserID)
+}
+
+func (r *auditEventResolver) ChannelDetails(ctx context.Context, obj *models.AuditEvent) (*models.AdminChannel, error) {
+	return r.adminChannelByName(ctx, obj.Channel)
+}
+
+func (r *mutationResolver) ForceStopRecording(ctx context.Context, channelName string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "ForceStopRecording").Str("channel", channelName).Msg("")
+
+	err := r.forceStopRecording(ctx, channelName)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) DeleteUser(ctx context.Context, userID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "DeleteUser").Str("userId", userID).Msg("")
+
+	err := r.deleteUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SetUserRoles(ctx context.Context, userID string, roles []models.Role) ([]models.Role, error) {
+	r.log(ctx).Info().Str("mutation", "SetUserRoles").Str("userId", userID).Interface("roles", roles).Msg("")
+
+	return r.setUserRoles(ctx, userID, roles)
+}
+
+func (r *queryResolver) AuditLog(ctx context.Context, channel *string, operation *string, before *string, limit *int) ([]*models.AuditEvent, error) {
+	r.log(ctx).Info().Str("query", "AuditLog").Interface("channel", channel).Interface("operation", operation).Msg("")
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.auditLog(ctx, channel, operation, before, pageSize)
+}
+
+func (r *queryResolver) ListAllChannels(ctx context.Context, before *string, limit *int) ([]*models.AdminChannel, error) {
+	r.log(ctx).Info().Str("query", "ListAllChannels").Msg("")
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.listAllChannels(ctx, before, pageSize)
+}
+
+func (r *queryResolver) UsageStats(ctx context.Context) (*models.UsageStats, error) {
+	r.log(ctx).Info().Str("query", "UsageStats").Msg("")
+
+	return r.usageStats(ctx)
+}
+
+func (r *queryResolver) MeetingAnalytics(ctx context.Context, since *time.Time, until *time.Time) (*models.MeetingAnalytics, error) {
+	r.log(ctx).Info().Str("query", "MeetingAnalytics").Interface("since", since).Interface("until", until).Msg("")
+
+	return r.meetingAnalytics(ctx, since, until)
+}
+
+// AdminChannel returns generated.AdminChannelResolver implementation.
+func (r *Resolver) AdminChannel() generated.AdminChannelResolver { return &adminChannelResolver{r} }
+
+// AuditEvent returns generated.AuditEventResolver implementation.
+func (r *Resolver) AuditEvent() generated.AuditEventResolver { return &auditEventResolver{r} }
+
+type adminChannelResolver struct{ *Resolver }
+type auditEventResolver struct{ *Resolver }
@@ -0,0 +1,196 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+const (
+	// analyticsDateLayout is the format of the days analytics are reported for
+	analyticsDateLayout = "2006-01-02"
+	// defaultAnalyticsRange is how far back analytics go when no range is given
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	maxAnalyticsRange     = 366 * 24 * time.Hour
+)
+
+// channelOccupancy lists when channels became occupied, with a delta of 1, and empty again, with a delta of -1, from
+// the attendance overlapping a time range. Participants that have not left are counted as leaving now. Channels
+// emptying are ordered before channels filling up at the same time, so back to back meetings do not overlap
+const channelOccupancy = `WITH events AS (
+		SELECT channel_id, joined_at AS at, 1 AS delta FROM attendance WHERE joined_at < $2 AND (left_at IS NULL OR left_at > $1)
+		UNION ALL
+		SELECT channel_id, COALESCE(left_at, NOW()) AS at, -1 AS delta FROM attendance WHERE joined_at < $2 AND (left_at IS NULL OR left_at > $1)
+	), occupancy AS (
+		SELECT channel_id, at, delta, SUM(delta) OVER (PARTITION BY channel_id ORDER BY at, delta ROWS UNBOUNDED PRECEDING) AS present FROM events
+	)
+	SELECT channel_id, at, delta FROM occupancy WHERE (delta = 1 AND present = 1) OR (delta = -1 AND present = 0) ORDER BY at, delta`
+
+// dailyTotals fetches a total per day in UTC from a query that selects a date and a total
+func (r *Resolver) dailyTotals(ctx context.Context, query string, args ...interface{}) (map[string]float64, error) {
+	rows := []struct {
+		Date  string  `db:"date"`
+		Total float64 `db:"total"`
+	}{}
+	err := r.DB.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]float64{}
+	for _, row := range rows {
+		totals[row.Date] = row.Total
+	}
+
+	return totals, nil
+}
+
+// meetingAnalytics reports the meetings, recording and PSTN usage of the deployment by day. A meeting lasts from the
+// first participant joining a channel to the last one leaving, is counted on the day it started and ongoing meetings
+// last until now
+func (r *Resolver) meetingAnalytics(ctx context.Context, since *time.Time, until *time.Time) (*models.MeetingAnalytics, error) {
+	end := time.Now().UTC()
+	if until != nil {
+		end = until.UTC()
+	}
+
+	start := end.Add(-defaultAnalyticsRange)
+	if since != nil {
+		start = since.UTC()
+	}
+
+	if !start.Before(end) {
+		return nil, apierror.New(apierror.CodeBadRequest, "since must be before until")
+	}
+
+	if end.Sub(start) > maxAnalyticsRange {
+		return nil, apierror.New(apierror.CodeBadRequest, "Analytics cover at most 366 days")
+	}
+
+	transitions := []struct {
+		ChannelID int64     `db:"channel_id"`
+		At        time.Time `db:"at"`
+		Delta     int       `db:"delta"`
+	}{}
+	err := r.DB.SelectContext(ctx, &transitions, channelOccupancy, start, end)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch channel occupancy")
+		return nil, errInternalServer
+	}
+
+	recordingMinutes, err := r.dailyTotals(ctx, `SELECT TO_CHAR(occurred_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date, SUM(quantity) AS total
+		FROM usage_records WHERE metric = $1 AND occurred_at >= $2 AND occurred_at < $3 GROUP BY date`, models.UsageRecordingMinutes, start, end)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch recording minutes")
+		return nil, errInternalServer
+	}
+
+	pstnCalls, err := r.dailyTotals(ctx, `SELECT TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date, COUNT(*) AS total
+		FROM pstn_calls WHERE created_at >= $1 AND created_at < $2 GROUP BY date`, start, end)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch PSTN calls")
+		return nil, errInternalServer
+	}
+
+	pstnMinutes, err := r.dailyTotals(ctx, `SELECT TO_CHAR(joined_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date,
+		SUM(EXTRACT(EPOCH FROM COALESCE(left_at, NOW()) - joined_at)) / 60 AS total
+		FROM attendance WHERE uid BETWEEN $1 AND $2 AND joined_at >= $3 AND joined_at < $4 GROUP BY date`, utils.MinPSTNUID, utils.MaxPSTNUID, start, end)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch PSTN minutes")
+		return nil, errInternalServer
+	}
+
+	result := &models.MeetingAnalytics{
+		Since: start,
+		Until: end,
+		Daily: []*models.DailyAnalytics{},
+	}
+
+	days := map[string]*models.DailyAnalytics{}
+	durations := map[string]float64{}
+	for day := start.Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		date := day.Format(analyticsDateLayout)
+		daily := &models.DailyAnalytics{
+			Date:             date,
+			RecordingMinutes: recordingMinutes[date],
+			PstnCalls:        int(pstnCalls[date]),
+			PstnMinutes:      pstnMinutes[date],
+		}
+		days[date] = daily
+		result.Daily = append(result.Daily, daily)
+	}
+
+	// Channels that are occupied when a day starts count towards its peak even when nothing changes during the day,
+	// so the number of active channels is carried over to each day passed on the way to a transition
+	firstDay := start.Truncate(24 * time.Hour)
+	active := 0
+	index := -1
+	advance := func(at time.Time) {
+		for index+1 < len(result.Daily) && !at.Before(firstDay.Add(time.Duration(index+1)*24*time.Hour)) {
+			index++
+			if result.Daily[index].PeakConcurrentChannels < active {
+				result.Daily[index].PeakConcurrentChannels = active
+			}
+		}
+	}
+
+	startedAt := map[int64]time.Time{}
+	for _, transition := range transitions {
+		advance(transition.At)
+		active += transition.Delta
+		if transition.Delta > 0 {
+			startedAt[transition.ChannelID] = transition.At
+		}
+
+		if index >= 0 && transition.At.Before(end) && result.Daily[index].PeakConcurrentChannels < active {
+			result.Daily[index].PeakConcurrentChannels = active
+		}
+
+		began, ok := startedAt[transition.ChannelID]
+		if transition.Delta > 0 || !ok || began.Before(start) || !began.Before(end) {
+			continue
+		}
+
+		date := began.UTC().Format(analyticsDateLayout)
+		days[date].Meetings++
+		durations[date] += transition.At.Sub(began).Minutes()
+		delete(startedAt, transition.ChannelID)
+	}
+	advance(end)
+
+	var totalDuration float64
+	for _, daily := range result.Daily {
+		if daily.Meetings > 0 {
+			average := durations[daily.Date] / float64(daily.Meetings)
+			daily.AverageDurationMinutes = &average
+		}
+
+		result.Meetings += daily.Meetings
+		totalDuration += durations[daily.Date]
+		result.RecordingMinutes += daily.RecordingMinutes
+		result.PstnCalls += daily.PstnCalls
+		result.PstnMinutes += daily.PstnMinutes
+		if daily.PeakConcurrentChannels > result.PeakConcurrentChannels {
+			result.PeakConcurrentChannels = daily.PeakConcurrentChannels
+		}
+	}
+
+	if result.Meetings > 0 {
+		average := totalDuration / float64(result.Meetings)
+		result.AverageDurationMinutes = &average
+	}
+
+	return result, nil
+}
@@ -0,0 +1,150 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+const apiKeyPrefix = "abk_"
+
+// apiKeyDisplayLength is how much of a key is kept in the clear so users can tell their keys apart
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// errMissingScope is returned when a request authenticated with an API key uses an operation outside its scopes
+var errMissingScope = apierror.New(apierror.CodeForbidden, "API key is not allowed to use this operation")
+
+// authorizedUser returns the user a request acts for, which is the signed in user or the owner of the API key the
+// request was authenticated with when the key has scope. It returns errInvalidToken for anonymous requests
+func (r *Resolver) authorizedUser(ctx context.Context, scope models.APIKeyScope) (*models.UserAccount, error) {
+	user, err := middleware.GetUserFromContext(ctx)
+	if err == nil {
+		return user, nil
+	}
+
+	apiKey, err := middleware.GetAPIKeyFromContext(ctx)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	if !apiKey.HasScope(scope) {
+		r.log(ctx).Debug().Int64("key", apiKey.ID).Str("scope", scope.String()).Msg("API key is missing scope")
+		return nil, errMissingScope
+	}
+
+	return apiKey.Owner, nil
+}
+
+// apiKey maps a stored API key onto the schema
+func apiKey(stored models.APICredential) *models.APIKey {
+	result := &models.APIKey{
+		ID:        strconv.FormatInt(stored.ID, 10),
+		Name:      stored.Name,
+		Prefix:    stored.Prefix,
+		Scopes:    []models.APIKeyScope{},
+		CreatedAt: stored.CreatedAt,
+	}
+
+	for _, scope := range stored.Scopes {
+		result.Scopes = append(result.Scopes, models.APIKeyScope(scope))
+	}
+
+	if stored.LastUsedAt.Valid {
+		result.LastUsedAt = &stored.LastUsedAt.Time
+	}
+
+	return result
+}
+
+// apiKeys lists the API keys of a user that have not been revoked
+func (r *Resolver) apiKeys(ctx context.Context, user *models.UserAccount) ([]*models.APIKey, error) {
+	stored, err := r.Repos.APIKeys.ListForUser(ctx, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not fetch API keys")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.APIKey, len(stored))
+	for index, key := range stored {
+		result[index] = apiKey(key)
+	}
+
+	return result, nil
+}
+
+// createAPIKey creates an API key for a user. The key itself is only returned here and cannot be recovered later
+func (r *Resolver) createAPIKey(ctx context.Context, user *models.UserAccount, name string, scopes []models.APIKeyScope) (*models.CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Name cannot be empty")
+	}
+
+	if len(scopes) == 0 {
+		return nil, apierror.New(apierror.CodeBadRequest, "API keys need at least one scope")
+	}
+
+	key, err := utils.GenerateSecret(apiKeyPrefix)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate API key")
+		return nil, errInternalServer
+	}
+
+	stored := models.APICredential{
+		UserID:  user.ID,
+		Name:    name,
+		Prefix:  key[:apiKeyDisplayLength],
+		KeyHash: utils.HashSecret(key),
+		Scopes:  pq.StringArray{},
+	}
+	for _, scope := range scopes {
+		stored.Scopes = append(stored.Scopes, scope.String())
+	}
+
+	err = r.Repos.APIKeys.Create(ctx, &stored)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not create API key")
+		return nil, errInternalServer
+	}
+
+	return &models.CreatedAPIKey{
+		APIKey: apiKey(stored),
+		Key:    key,
+	}, nil
+}
+
+// revokeAPIKey revokes an API key of a user, after which requests authenticated with it are anonymous
+func (r *Resolver) revokeAPIKey(ctx context.Context, user *models.UserAccount, id string) error {
+	keyID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return apierror.New(apierror.CodeBadRequest, "Invalid API key ID")
+	}
+
+	err = r.Repos.APIKeys.Revoke(ctx, keyID, user.ID)
+	if err == sql.ErrNoRows {
+		return apierror.New(apierror.CodeNotFound, "API key not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("key", keyID).Msg("Could not revoke API key")
+		return errInternalServer
+	}
+
+	return nil
+}
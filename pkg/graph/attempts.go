@@ -0,0 +1,145 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// errChannelLockedOut is returned when joining a channel that was locked because its passphrase was probably guessed
+var errChannelLockedOut = apierror.New(apierror.CodeChannelLocked, "Meeting is temporarily locked")
+
+// tooManyAttempts is returned when a client has to wait before looking up a passphrase again
+func tooManyAttempts(wait time.Duration) error {
+	return apierror.New(apierror.CodeRateLimited, "Too many failed attempts, try again in "+strconv.Itoa(int(math.Ceil(wait.Seconds())))+" seconds")
+}
+
+// backoff returns how long a client has to wait after failing a number of times. The wait starts at first once the
+// client failed after times and doubles with every further failure, up to maxWait
+func backoff(failures int, after int, first time.Duration, maxWait time.Duration) time.Duration {
+	if after <= 0 || failures < after {
+		return 0
+	}
+
+	wait := first
+	for doubled := after; doubled < failures && wait < maxWait; doubled++ {
+		wait *= 2
+	}
+
+	if wait > maxWait {
+		return maxWait
+	}
+
+	return wait
+}
+
+// passphraseBackoff returns how long a client has to wait after its last failed passphrase lookup. The wait starts at
+// a second once the client failed PASSPHRASE_BACKOFF_AFTER times and doubles with every further failure, up to
+// PASSPHRASE_FAILURE_WINDOW_MINUTES after which the failures are forgotten
+func passphraseBackoff(failures int) time.Duration {
+	maxWait := time.Duration(viper.GetInt("PASSPHRASE_FAILURE_WINDOW_MINUTES")) * time.Minute
+	return backoff(failures, viper.GetInt("PASSPHRASE_BACKOFF_AFTER"), time.Second, maxWait)
+}
+
+// checkPassphraseAttempts returns the number of recent failed passphrase lookups of a client and refuses the lookup
+// while the client is backing off. Lookups are refused when the attempts cannot be fetched, so that a failing
+// database does not turn off the backoff
+func (r *Resolver) checkPassphraseAttempts(ctx context.Context, ip string) (int, error) {
+	if ip == "" {
+		return 0, nil
+	}
+
+	since := time.Now().Add(-time.Duration(viper.GetInt("PASSPHRASE_FAILURE_WINDOW_MINUTES")) * time.Minute)
+	failures, last, err := r.Repos.Attempts.RecentFailures(ctx, ip, since)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("ip", ip).Msg("Could not fetch passphrase attempts")
+		return 0, errInternalServer
+	}
+
+	if !last.Valid {
+		return failures, nil
+	}
+
+	wait := time.Until(last.Time.Add(passphraseBackoff(failures)))
+	if wait > 0 {
+		r.log(ctx).Info().Str("ip", ip).Int("failures", failures).Dur("wait", wait).Msg("Passphrase lookup refused")
+		return failures, tooManyAttempts(wait)
+	}
+
+	return failures, nil
+}
+
+// recordPassphraseFailure records a lookup of a passphrase that does not exist
+func (r *Resolver) recordPassphraseFailure(ctx context.Context, ip string) {
+	if ip == "" {
+		return
+	}
+
+	err := r.Repos.Attempts.RecordFailure(ctx, ip)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("ip", ip).Msg("Could not record passphrase attempt")
+	}
+}
+
+// recordPassphraseSuccess records a successful lookup by a client that failed recently, which may have guessed the
+// passphrase. The channel is locked for PASSPHRASE_LOCKOUT_MINUTES when the client failed at least
+// PASSPHRASE_LOCKOUT_AFTER times, so hosts have time to rotate the passphrases
+func (r *Resolver) recordPassphraseSuccess(ctx context.Context, channelData *models.Channel, ip string, failures int) {
+	if ip == "" || failures == 0 {
+		return
+	}
+
+	lockAfter := viper.GetInt("PASSPHRASE_LOCKOUT_AFTER")
+	locked := lockAfter > 0 && failures >= lockAfter
+	if locked {
+		lockedUntil, err := r.Repos.Channels.LockFor(ctx, channelData.ID, viper.GetInt("PASSPHRASE_LOCKOUT_MINUTES"))
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not lock channel")
+			locked = false
+		} else {
+			channelData.LockedUntil = lockedUntil
+			r.log(ctx).Warn().Str("ip", ip).Int("failures", failures).Str("channel", channelData.ChannelName).Msg("Channel locked after failed passphrase attempts")
+		}
+	}
+
+	err := r.Repos.Attempts.RecordSuccess(ctx, ip, channelData.ID, failures, locked)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("ip", ip).Msg("Could not record passphrase attempt")
+	}
+}
+
+// passphraseAttempts lists the suspicious lookups of the passphrases of a channel, most recent first
+func (r *Resolver) passphraseAttempts(ctx context.Context, channelData *models.Channel) ([]*models.PassphraseAttempt, error) {
+	attempts, err := r.Repos.Attempts.ListForChannel(ctx, channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch passphrase attempts")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.PassphraseAttempt, len(attempts))
+	for index, attempt := range attempts {
+		result[index] = &models.PassphraseAttempt{
+			IP:          attempt.IP,
+			Failures:    attempt.Failures,
+			Locked:      attempt.Locked,
+			AttemptedAt: attempt.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		after    int
+		want     time.Duration
+	}{
+		{failures: 0, after: 5, want: 0},
+		{failures: 4, after: 5, want: 0},
+		{failures: 5, after: 5, want: time.Minute},
+		{failures: 6, after: 5, want: 2 * time.Minute},
+		{failures: 8, after: 5, want: 8 * time.Minute},
+		{failures: 12, after: 5, want: time.Hour},
+		{failures: 1000, after: 5, want: time.Hour},
+		{failures: 1000, after: 0, want: 0},
+	}
+
+	for _, test := range tests {
+		if got := backoff(test.failures, test.after, time.Minute, time.Hour); got != test.want {
+			t.Errorf("backoff after %d of %d failures is %s, want %s", test.failures, test.after, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// attendanceReport summarises the attendance of every user of a channel. A user that left and rejoined with the
+// same uid is reported once, from their first join to their last leave, with the time spent in the channel added up.
+// Users that are still in the channel have no leave time and their duration counts up to now
+func (r *Resolver) attendanceReport(ctx context.Context, channelData *models.Channel) ([]*models.AttendanceRecord, error) {
+	rows := []struct {
+		UID      int64          `db:"uid"`
+		Name     sql.NullString `db:"name"`
+		JoinedAt time.Time      `db:"joined_at"`
+		LeftAt   sql.NullTime   `db:"left_at"`
+		Duration int            `db:"duration"`
+	}{}
+
+	// Screen shares are part of the participant that started them, so they are left out of the report
+	err := r.DB.SelectContext(ctx, &rows, `SELECT attendance.uid, participants.name, MIN(attendance.joined_at) AS joined_at,
+		CASE WHEN BOOL_OR(attendance.left_at IS NULL) THEN NULL ELSE MAX(attendance.left_at) END AS left_at,
+		SUM(EXTRACT(EPOCH FROM COALESCE(attendance.left_at, NOW()) - attendance.joined_at))::INT AS duration
+		FROM attendance
+		LEFT JOIN participants ON participants.channel_id = attendance.channel_id AND participants.uid = attendance.uid
+		WHERE attendance.channel_id = $1
+		AND NOT EXISTS (SELECT 1 FROM participants WHERE participants.channel_id = $1 AND participants.screen_share_uid = attendance.uid)
+		GROUP BY attendance.uid, participants.name
+		ORDER BY joined_at`, channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch attendance")
+		return nil, errInternalServer
+	}
+
+	report := []*models.AttendanceRecord{}
+	for _, row := range rows {
+		record := &models.AttendanceRecord{
+			UID:      int(row.UID),
+			JoinedAt: row.JoinedAt,
+			Duration: row.Duration,
+		}
+
+		if row.Name.Valid {
+			name := row.Name.String
+			record.Name = &name
+		}
+
+		if row.LeftAt.Valid {
+			leftAt := row.LeftAt.Time
+			record.LeftAt = &leftAt
+		}
+
+		report = append(report, record)
+	}
+
+	return report, nil
+}
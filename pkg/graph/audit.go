@@ -0,0 +1,89 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+const maxAuditPage = 500
+
+// auditLog lists audit events, most recent first, optionally only those of a channel or an operation
+func (r *Resolver) auditLog(ctx context.Context, channel *string, operation *string, before *string, limit int) ([]*models.AuditEvent, error) {
+	if limit <= 0 || limit > maxAuditPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxAuditPage))
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		id, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	var events []struct {
+		models.AuditLogEntry
+		ChannelName sql.NullString `db:"channel_name"`
+	}
+	err := r.DB.SelectContext(ctx, &events, `SELECT audit_events.id, audit_events.created_at, audit_events.user_id, audit_events.ip, audit_events.request_id,
+		audit_events.operation, audit_events.channel_id, audit_events.arguments_hash, audit_events.succeeded, audit_events.error_code, channels.channel_name
+		FROM audit_events LEFT JOIN channels ON channels.id = audit_events.channel_id
+		WHERE ($1::TEXT IS NULL OR channels.channel_name = $1) AND ($2::TEXT IS NULL OR audit_events.operation = $2) AND ($3::INT IS NULL OR audit_events.id < $3)
+		ORDER BY audit_events.id DESC LIMIT $4`, channel, operation, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch audit events")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.AuditEvent, len(events))
+	for index, event := range events {
+		result[index] = &models.AuditEvent{
+			ID:            strconv.FormatInt(event.ID, 10),
+			Operation:     event.Operation,
+			UserID:        nullableID(event.UserID),
+			IP:            nullableString(event.IP),
+			RequestID:     nullableString(event.RequestID),
+			Channel:       nullableString(event.ChannelName),
+			ArgumentsHash: event.ArgumentsHash,
+			Succeeded:     event.Succeeded,
+			ErrorCode:     nullableString(event.ErrorCode),
+			CreatedAt:     event.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+// nullableString returns the value of a nullable column as an optional GraphQL field
+func nullableString(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.String
+}
+
+// nullableID returns the value of a nullable ID column as an optional GraphQL field
+func nullableID(value sql.NullInt64) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	id := strconv.FormatInt(value.Int64, 10)
+	return &id
+}
@@ -0,0 +1,268 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+var errBillingDisabled = apierror.New(apierror.CodeBadRequest, "Billing is not enabled")
+var errPlanNotFound = apierror.New(apierror.CodeNotFound, "Plan not found")
+
+// plan converts a plan for the API
+func plan(record *models.BillingPlan) *models.Plan {
+	return &models.Plan{
+		ID:    strconv.FormatInt(record.ID, 10),
+		Name:  record.Name,
+		Quota: planQuota(record),
+	}
+}
+
+// billingSubject returns who the signed in user is billing for: an organization it is a member of, or owns when
+// owner is set, or otherwise the user
+func (r *Resolver) billingSubject(ctx context.Context, user *models.UserAccount, organizationID *string, owner bool) (services.UsageSubject, error) {
+	if organizationID == nil {
+		return services.UsageSubject{UserID: sql.NullInt64{Int64: user.ID, Valid: true}}, nil
+	}
+
+	var id int64
+	var err error
+	if owner {
+		id, err = r.ownerOf(ctx, user, *organizationID)
+	} else {
+		id, _, err = r.memberOf(ctx, r.DB, user, *organizationID)
+	}
+	if err != nil {
+		return services.UsageSubject{}, err
+	}
+
+	return services.UsageSubject{OrganizationID: sql.NullInt64{Int64: id, Valid: true}}, nil
+}
+
+// billingReturnURL returns the URL configured with key that Stripe sends users back to, which defaults to FRONTEND_URL
+func billingReturnURL(key string) string {
+	if viper.GetString(key) != "" {
+		return viper.GetString(key)
+	}
+
+	return viper.GetString("FRONTEND_URL")
+}
+
+// plans lists the plans that can be subscribed to
+func (r *Resolver) plans(ctx context.Context) ([]*models.Plan, error) {
+	var records []models.BillingPlan
+	err := r.DB.SelectContext(ctx, &records, "SELECT * FROM plans WHERE active ORDER BY id")
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch plans")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.Plan, 0, len(records))
+	for i := range records {
+		result = append(result, plan(&records[i]))
+	}
+
+	return result, nil
+}
+
+// billingSubscription returns the latest subscription of the user or of an organization it is a member of
+func (r *Resolver) billingSubscription(ctx context.Context, user *models.UserAccount, organizationID *string) (*models.BillingSubscription, error) {
+	subject, err := r.billingSubject(ctx, user, organizationID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := services.LatestSubscription(ctx, r.DB, subject)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Interface("subject", subject).Msg("Could not fetch subscription")
+		return nil, errInternalServer
+	}
+
+	if subscription == nil {
+		return nil, nil
+	}
+
+	var record models.BillingPlan
+	err = r.DB.GetContext(ctx, &record, "SELECT * FROM plans WHERE id = $1", subscription.PlanID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("plan", subscription.PlanID).Msg("Could not fetch plan")
+		return nil, errInternalServer
+	}
+
+	result := &models.BillingSubscription{Plan: plan(&record), Status: subscription.Status}
+	if subscription.CurrentPeriodEnd.Valid {
+		result.CurrentPeriodEnd = &subscription.CurrentPeriodEnd.Time
+	}
+
+	return result, nil
+}
+
+// createCheckoutSession starts a Stripe Checkout session subscribing the user, or an organization it owns, to a plan.
+// Subjects that are already subscribed change their plan in the billing portal instead
+func (r *Resolver) createCheckoutSession(ctx context.Context, user *models.UserAccount, planID string, organizationID *string) (string, error) {
+	if !services.BillingEnabled() {
+		return "", errBillingDisabled
+	}
+
+	subject, err := r.billingSubject(ctx, user, organizationID, true)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := strconv.ParseInt(planID, 10, 64)
+	if err != nil {
+		return "", errPlanNotFound
+	}
+
+	var record models.BillingPlan
+	err = r.DB.GetContext(ctx, &record, "SELECT * FROM plans WHERE id = $1 AND active", id)
+	if err == sql.ErrNoRows {
+		return "", errPlanNotFound
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("plan", id).Msg("Could not fetch plan")
+		return "", errInternalServer
+	}
+
+	current, err := services.ActivePlan(ctx, r.DB, subject)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Interface("subject", subject).Msg("Could not fetch active plan")
+		return "", errInternalServer
+	}
+
+	if current != nil {
+		return "", apierror.New(apierror.CodeBadRequest, "Already subscribed to a plan, change it in the billing portal")
+	}
+
+	latest, err := services.LatestSubscription(ctx, r.DB, subject)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Interface("subject", subject).Msg("Could not fetch subscription")
+		return "", errInternalServer
+	}
+
+	checkout := utils.StripeCheckout{
+		PriceID:    record.StripePriceID,
+		SuccessURL: billingReturnURL("BILLING_SUCCESS_URL"),
+		CancelURL:  billingReturnURL("BILLING_CANCEL_URL"),
+	}
+
+	if subject.OrganizationID.Valid {
+		checkout.Metadata = map[string]string{services.StripeOrganizationKey: strconv.FormatInt(subject.OrganizationID.Int64, 10)}
+	} else {
+		checkout.Metadata = map[string]string{services.StripeUserKey: strconv.FormatInt(user.ID, 10)}
+		checkout.CustomerEmail = user.Email
+	}
+
+	// Subjects that subscribed before are billed as the same Stripe customer
+	if latest != nil {
+		checkout.Customer = latest.StripeCustomerID
+	}
+
+	url, err := utils.CreateStripeCheckout(checkout)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("plan", id).Msg("Could not create Stripe Checkout session")
+		return "", errInternalServer
+	}
+
+	return url, nil
+}
+
+// createBillingPortalSession returns the URL of the Stripe billing portal for the customer the user, or an
+// organization it owns, subscribed as
+func (r *Resolver) createBillingPortalSession(ctx context.Context, user *models.UserAccount, organizationID *string) (string, error) {
+	if !services.BillingEnabled() {
+		return "", errBillingDisabled
+	}
+
+	subject, err := r.billingSubject(ctx, user, organizationID, true)
+	if err != nil {
+		return "", err
+	}
+
+	latest, err := services.LatestSubscription(ctx, r.DB, subject)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Interface("subject", subject).Msg("Could not fetch subscription")
+		return "", errInternalServer
+	}
+
+	if latest == nil {
+		return "", apierror.New(apierror.CodeBadRequest, "Not subscribed to a plan")
+	}
+
+	url, err := utils.CreateStripePortal(latest.StripeCustomerID, billingReturnURL("BILLING_CANCEL_URL"))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("customer", latest.StripeCustomerID).Msg("Could not create Stripe billing portal session")
+		return "", errInternalServer
+	}
+
+	return url, nil
+}
+
+// nullableLimit stores a limit of a plan, which is unlimited when it is not set
+func nullableLimit(limit *int) sql.NullInt64 {
+	if limit == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: int64(*limit), Valid: true}
+}
+
+// createPlan adds a plan for a Stripe price
+func (r *Resolver) createPlan(ctx context.Context, input models.PlanInput) (*models.Plan, error) {
+	for _, limit := range []*int{input.ChannelsPerMonth, input.RecordingMinutesPerMonth, input.ParticipantMinutesPerMonth, input.PstnCallsPerMonth} {
+		if limit != nil && *limit < 0 {
+			return nil, apierror.New(apierror.CodeBadRequest, "Limits cannot be negative")
+		}
+	}
+
+	var record models.BillingPlan
+	err := r.DB.GetContext(ctx, &record, `INSERT INTO plans (name, stripe_price_id, channels_per_month, recording_minutes_per_month, participant_minutes_per_month, pstn_calls_per_month)
+		VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (stripe_price_id) DO NOTHING RETURNING *`,
+		input.Name, input.StripePriceID, nullableLimit(input.ChannelsPerMonth), nullableLimit(input.RecordingMinutesPerMonth),
+		nullableLimit(input.ParticipantMinutesPerMonth), nullableLimit(input.PstnCallsPerMonth))
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeBadRequest, "A plan for this Stripe price already exists")
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("price", input.StripePriceID).Msg("Could not create plan")
+		return nil, errInternalServer
+	}
+
+	return plan(&record), nil
+}
+
+// retirePlan stops offering a plan
+func (r *Resolver) retirePlan(ctx context.Context, planID string) error {
+	id, err := strconv.ParseInt(planID, 10, 64)
+	if err != nil {
+		return errPlanNotFound
+	}
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE plans SET active = FALSE WHERE id = $1", id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("plan", id).Msg("Could not retire plan")
+		return errInternalServer
+	}
+
+	if retired, err := result.RowsAffected(); err == nil && retired == 0 {
+		return errPlanNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) CreateCheckoutSession(ctx context.Context, planID string, organizationID *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "CreateCheckoutSession").Str("planId", planID).Interface("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	return r.createCheckoutSession(ctx, authUser, planID, organizationID)
+}
+
+func (r *mutationResolver) CreateBillingPortalSession(ctx context.Context, organizationID *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "CreateBillingPortalSession").Interface("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	return r.createBillingPortalSession(ctx, authUser, organizationID)
+}
+
+func (r *mutationResolver) CreatePlan(ctx context.Context, plan models.PlanInput) (*models.Plan, error) {
+	r.log(ctx).Info().Str("mutation", "CreatePlan").Interface("plan", plan).Msg("")
+
+	return r.createPlan(ctx, plan)
+}
+
+func (r *mutationResolver) RetirePlan(ctx context.Context, planID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RetirePlan").Str("planId", planID).Msg("")
+
+	err := r.retirePlan(ctx, planID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) Plans(ctx context.Context) ([]*models.Plan, error) {
+	r.log(ctx).Info().Str("query", "Plans").Msg("")
+
+	return r.plans(ctx)
+}
+
+func (r *queryResolver) BillingSubscription(ctx context.Context, organizationID *string) (*models.BillingSubscription, error) {
+	r.log(ctx).Info().Str("query", "BillingSubscription").Interface("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.billingSubscription(ctx, authUser, organizationID)
+}
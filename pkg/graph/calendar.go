@@ -0,0 +1,136 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// userCalendar gets the calendar of a host, reporting hosts that have not granted access to one
+func (r *Resolver) userCalendar(ctx context.Context, user *models.UserAccount, provider string) (*services.UserCalendar, error) {
+	calendar, err := services.GetUserCalendar(ctx, r.DB, user.ID, provider)
+	if errors.Is(err, services.ErrNoCalendar) {
+		return nil, apierror.New(apierror.CodeForbidden, "Sign in with Google or Microsoft and grant access to your calendar first")
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not get calendar")
+		return nil, apierror.New(apierror.CodeUnavailable, "Could not access your calendar")
+	}
+
+	return calendar, nil
+}
+
+// scheduleOnCalendar schedules a channel and creates its event in the calendar of the host, or updates the event
+// when the host added the channel to their calendar before
+func (r *Resolver) scheduleOnCalendar(ctx context.Context, channelData *models.Channel, user *models.UserAccount, startsAt time.Time, endsAt time.Time, attendees []string) (*models.CalendarEvent, error) {
+	if !endsAt.After(startsAt) {
+		return nil, apierror.New(apierror.CodeBadRequest, "Meeting must end after it starts")
+	}
+
+	if len(attendees) > maxInviteRecipients {
+		return nil, apierror.New(apierror.CodeBadRequest, "At most "+strconv.Itoa(maxInviteRecipients)+" attendees can be invited")
+	}
+
+	recipients, err := parseEmails(attendees)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored models.ChannelCalendarEvent
+	err = r.DB.GetContext(ctx, &stored, "SELECT id, created_at, updated_at, channel_id, user_id, provider, event_id, attendees FROM calendar_events WHERE channel_id = $1 AND user_id = $2", channelData.ID, user.ID)
+	existing := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not get calendar event")
+		return nil, errInternalServer
+	}
+
+	calendar, err := r.userCalendar(ctx, user, stored.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Repos.Channels.SetSchedule(ctx, channelData.ID, startsAt, endsAt)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not schedule channel")
+		return nil, errInternalServer
+	}
+
+	channelData.StartsAt = sql.NullTime{Time: startsAt, Valid: true}
+	channelData.EndsAt = sql.NullTime{Time: endsAt, Valid: true}
+	invite := &utils.CalendarInvite{MeetingEvent: r.meetingEvent(ctx, channelData), Attendees: recipients}
+
+	if existing {
+		err = calendar.Calendar.UpdateEvent(ctx, calendar.Client, stored.EventID, invite)
+		if err == nil {
+			err = r.DB.GetContext(ctx, &stored.ID, "UPDATE calendar_events SET attendees = $1, updated_at = NOW() WHERE id = $2 RETURNING id", pq.StringArray(recipients), stored.ID)
+		}
+	} else {
+		var eventID string
+		eventID, err = calendar.Calendar.CreateEvent(ctx, calendar.Client, invite)
+		if err == nil {
+			err = r.DB.GetContext(ctx, &stored.ID, "INSERT INTO calendar_events (channel_id, user_id, provider, event_id, attendees) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+				channelData.ID, user.ID, calendar.Provider, eventID, pq.StringArray(recipients))
+		}
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Str("provider", calendar.Provider).Msg("Could not save calendar event")
+		return nil, apierror.New(apierror.CodeUnavailable, "Could not add the meeting to your calendar")
+	}
+
+	return &models.CalendarEvent{
+		ID:        strconv.FormatInt(stored.ID, 10),
+		Provider:  calendar.Provider,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Attendees: recipients,
+	}, nil
+}
+
+// cancelCalendarEvent deletes the event of a channel from the calendar of the host
+func (r *Resolver) cancelCalendarEvent(ctx context.Context, channelData *models.Channel, user *models.UserAccount) error {
+	var stored models.ChannelCalendarEvent
+	err := r.DB.GetContext(ctx, &stored, "SELECT id, created_at, updated_at, channel_id, user_id, provider, event_id, attendees FROM calendar_events WHERE channel_id = $1 AND user_id = $2", channelData.ID, user.ID)
+	if err == sql.ErrNoRows {
+		return apierror.New(apierror.CodeBadRequest, "Meeting is not in your calendar")
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not get calendar event")
+		return errInternalServer
+	}
+
+	calendar, err := r.userCalendar(ctx, user, stored.Provider)
+	if err != nil {
+		return err
+	}
+
+	err = calendar.Calendar.CancelEvent(ctx, calendar.Client, stored.EventID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Str("provider", calendar.Provider).Msg("Could not cancel calendar event")
+		return apierror.New(apierror.CodeUnavailable, "Could not remove the meeting from your calendar")
+	}
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM calendar_events WHERE id = $1", stored.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not delete calendar event")
+		return errInternalServer
+	}
+
+	return nil
+}
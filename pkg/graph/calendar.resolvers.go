@@ -0,0 +1,71 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) ScheduleOnCalendar(ctx context.Context, passphrase string, startsAt time.Time, endsAt time.Time, attendees []string) (*models.CalendarEvent, error) {
+	r.log(ctx).Info().Str("mutation", "ScheduleOnCalendar").Str("passphrase", passphrase).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to schedule on calendar")
+		return nil, errNotHost("schedule on calendar")
+	}
+
+	return r.scheduleOnCalendar(ctx, channelData, authUser, startsAt, endsAt, attendees)
+}
+
+func (r *mutationResolver) CancelCalendarEvent(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "CancelCalendarEvent").Str("passphrase", passphrase).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to cancel calendar events")
+		return "", errNotHost("cancel calendar events")
+	}
+
+	err = r.cancelCalendarEvent(ctx, channelData, authUser)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
@@ -0,0 +1,204 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+const (
+	// maxCallMetricsRange is how far back from the last participant leaving call metrics go, for channels that are
+	// used for more than one meeting
+	maxCallMetricsRange = 24 * time.Hour
+	// callSearchMargin widens the time range calls are searched in, since Agora Analytics and attendance record
+	// joining and leaving at slightly different times
+	callSearchMargin = 5 * time.Minute
+)
+
+var errCallMetricsUnavailable = apierror.New(apierror.CodeUnavailable, "Call metrics are not available")
+
+// average returns the mean of values, or nil when there are none
+func average(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+
+	mean := total / float64(len(values))
+	return &mean
+}
+
+// callMetrics fetches the quality of the last meeting of a channel from Agora Analytics with the credentials of the
+// project of the channel, and merges it with the attendance and participants of the channel
+func (r *Resolver) callMetrics(ctx context.Context, channelData *models.Channel) (*models.CallMetrics, error) {
+	var bounds struct {
+		FirstJoin sql.NullTime `db:"first_join"`
+		LastLeave sql.NullTime `db:"last_leave"`
+		Ongoing   bool         `db:"ongoing"`
+	}
+	err := r.DB.GetContext(ctx, &bounds, `SELECT MIN(joined_at) AS first_join, MAX(left_at) AS last_leave, COALESCE(BOOL_OR(left_at IS NULL), FALSE) AS ongoing
+		FROM attendance WHERE channel_id = $1`, channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch attendance bounds")
+		return nil, errInternalServer
+	}
+
+	if !bounds.FirstJoin.Valid || !bounds.LastLeave.Valid {
+		return nil, apierror.New(apierror.CodeBadRequest, "No meeting has taken place in this channel")
+	}
+
+	if bounds.Ongoing && !channelData.EndedAt.Valid {
+		return nil, apierror.New(apierror.CodeBadRequest, "Call metrics are available once the meeting has finished")
+	}
+
+	end := bounds.LastLeave.Time
+	start := bounds.FirstJoin.Time
+	if end.Sub(start) > maxCallMetricsRange {
+		start = end.Add(-maxCallMetricsRange)
+	}
+
+	result := &models.CallMetrics{
+		Channel:   channelData.ChannelName,
+		Title:     channelData.Title,
+		StartedAt: start,
+		EndedAt:   end,
+		Users:     []*models.CallUserMetrics{},
+	}
+
+	err = r.DB.GetContext(ctx, &result.Participants, "SELECT COUNT(DISTINCT uid) FROM attendance WHERE channel_id = $1 AND joined_at >= $2", channelData.ID, start)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not count participants")
+		return nil, errInternalServer
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, err := r.Agora.SearchCalls(ctx, project, channelData.ChannelName, start.Add(-callSearchMargin), end.Add(callSearchMargin))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not search calls")
+		return nil, errCallMetricsUnavailable
+	}
+	result.Calls = len(calls)
+
+	stored := []models.Participant{}
+	err = r.DB.SelectContext(ctx, &stored, "SELECT id, created_at, channel_id, uid, screen_share_uid, name, user_id FROM participants WHERE channel_id = $1", channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch participants")
+		return nil, errInternalServer
+	}
+
+	names := map[int64]*string{}
+	for index := range stored {
+		if !stored[index].Name.Valid {
+			continue
+		}
+
+		names[int64(stored[index].UID)] = &stored[index].Name.String
+		if stored[index].ScreenShareUID.Valid {
+			names[int64(stored[index].ScreenShareUID.Int32)] = &stored[index].Name.String
+		}
+	}
+
+	users := map[int64]*models.CallUserMetrics{}
+	user := func(uid int64) *models.CallUserMetrics {
+		if users[uid] == nil {
+			users[uid] = &models.CallUserMetrics{UID: int(uid), Name: names[uid]}
+			result.Users = append(result.Users, users[uid])
+		}
+
+		return users[uid]
+	}
+
+	audioFreezes := map[int64][]float64{}
+	videoFreezes := map[int64][]float64{}
+	var attempts, successes int
+	for _, call := range calls {
+		sessions, err := r.Agora.CallSessions(ctx, project, call)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("call", call.CallID).Msg("Could not fetch call sessions")
+			return nil, errCallMetricsUnavailable
+		}
+
+		for index := range sessions {
+			session := &sessions[index]
+			metrics := user(session.UID)
+			metrics.Platform = &session.Platform
+			metrics.SdkVersion = &session.SDKVersion
+			metrics.Network = &session.Network
+
+			joinedAt := time.Unix(session.JoinTs, 0)
+			if session.JoinTs > 0 && (metrics.JoinedAt == nil || joinedAt.Before(*metrics.JoinedAt)) {
+				metrics.JoinedAt = &joinedAt
+			}
+
+			leftAt := time.Unix(session.LeaveTs, 0)
+			if session.LeaveTs > 0 && (metrics.LeftAt == nil || leftAt.After(*metrics.LeftAt)) {
+				metrics.LeftAt = &leftAt
+			}
+		}
+
+		quality, err := r.Agora.CallQuality(ctx, project, call)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("call", call.CallID).Msg("Could not fetch call quality")
+			return nil, errCallMetricsUnavailable
+		}
+
+		for _, measured := range quality {
+			user(measured.UID)
+			attempts += measured.JoinAttempts
+			successes += measured.JoinSuccesses
+			if measured.AudioFreezeRate != nil {
+				audioFreezes[measured.UID] = append(audioFreezes[measured.UID], *measured.AudioFreezeRate)
+			}
+			if measured.VideoFreezeRate != nil {
+				videoFreezes[measured.UID] = append(videoFreezes[measured.UID], *measured.VideoFreezeRate)
+			}
+		}
+	}
+
+	var audio, video []float64
+	for uid, metrics := range users {
+		metrics.AudioFreezeRate = average(audioFreezes[uid])
+		if metrics.AudioFreezeRate != nil {
+			audio = append(audio, *metrics.AudioFreezeRate)
+		}
+
+		metrics.VideoFreezeRate = average(videoFreezes[uid])
+		if metrics.VideoFreezeRate != nil {
+			video = append(video, *metrics.VideoFreezeRate)
+		}
+	}
+
+	result.AudioFreezeRate = average(audio)
+	result.VideoFreezeRate = average(video)
+	if attempts > 0 {
+		rate := float64(successes) / float64(attempts) * 100
+		result.JoinSuccessRate = &rate
+	}
+
+	sort.Slice(result.Users, func(i, j int) bool {
+		return result.Users[i].UID < result.Users[j].UID
+	})
+
+	return result, nil
+}
@@ -0,0 +1,36 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *queryResolver) CallMetrics(ctx context.Context, passphrase string) (*models.CallMetrics, error) {
+	r.log(ctx).Info().Str("query", "CallMetrics").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Msg("Unauthorized to view call metrics")
+		return nil, errNotHost("view call metrics")
+	}
+
+	return r.callMetrics(ctx, channelData)
+}
@@ -0,0 +1,596 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
+	"github.com/spf13/viper"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// getChannelRole fetches the channel a passphrase belongs to along with the type of the passphrase
+func (r *Resolver) getChannelRole(ctx context.Context, passphrase string) (*models.Channel, models.PassphraseType, error) {
+	if passphrase == "" {
+		return nil, "", apierror.New(apierror.CodeBadRequest, "Passphrase cannot be empty")
+	}
+
+	ip := middleware.GetClientIP(ctx)
+	failures, err := r.checkPassphraseAttempts(ctx, ip)
+	if err != nil {
+		return nil, "", err
+	}
+
+	channelData, role, err := r.Repos.Channels.ByPassphrase(ctx, passphrase)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
+		if err == sql.ErrNoRows {
+			r.recordPassphraseFailure(ctx, ip)
+		}
+		return nil, "", errChannelNotFound
+	}
+
+	if !role.IsValid() {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("role", role.String()).Msg("Invalid Passphrase; Interal Server Error")
+		return nil, "", errChannelNotFound
+	}
+
+	r.recordPassphraseSuccess(ctx, channelData, ip, failures)
+	middleware.SetAuditChannel(ctx, channelData.ID)
+	return channelData, role, nil
+}
+
+// getChannel fetches the channel a passphrase belongs to and reports whether it is a host or co-host passphrase
+func (r *Resolver) getChannel(ctx context.Context, passphrase string) (*models.Channel, bool, error) {
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return channelData, isHost(passphraseType), nil
+}
+
+// isHost reports whether a passphrase type has host privileges
+func isHost(passphraseType models.PassphraseType) bool {
+	return passphraseType == models.PassphraseTypeHost || passphraseType == models.PassphraseTypeCohost
+}
+
+// newSession generates the credentials to join a channel with a passphrase of the given type. The mode decides
+// which credentials are generated: audio only users cannot publish video and get no screen share user, while
+// screen share only users only get a screen share user
+func (r *Resolver) newSession(ctx context.Context, channelData *models.Channel, passphraseType models.PassphraseType, mode models.JoinMode) (*models.Session, error) {
+	host := isHost(passphraseType)
+	role := utils.ChannelRole(channelData, host)
+	canPublish := role == rtctoken.RolePublisher
+	if mode == models.JoinModeScreenshareOnly && !canPublish {
+		return nil, errNotHost("share screen")
+	}
+
+	session := &models.Session{
+		Title:      channelData.Title,
+		Channel:    channelData.ChannelName,
+		IsHost:     host,
+		Role:       passphraseType,
+		CanPublish: canPublish,
+		Status:     models.SessionStatusActive,
+		Secret:     channelData.ChannelSecret,
+		Sip:        sipDetails(channelData),
+		Whiteboard: r.whiteboardDetails(ctx, channelData, host, canPublish),
+		Mode:       mode,
+		Metadata:   r.channelMetadata(channelData),
+		Features:   r.channelFeatures(ctx, channelData),
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+	session.AppID = &project.AppID
+
+	_, span := utils.StartSpan(ctx, "GenerateMainUserCredentials")
+	if mode == models.JoinModeAudioOnly {
+		session.MainUser, err = r.Tokens.GenerateAudioUserCredentials(project, channelData.ChannelName, role, utils.TokenExpiry(channelData), utils.ChannelArea(channelData))
+	} else if mode != models.JoinModeScreenshareOnly {
+		session.MainUser, err = r.Tokens.GenerateUserCredentials(project, channelData.ChannelName, role, utils.TokenExpiry(channelData), true, false, utils.ChannelArea(channelData))
+	}
+	utils.EndSpan(span, err)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate main user credentials")
+		return nil, errInternalServer
+	}
+
+	if mode == models.JoinModeAudioOnly {
+		return session, nil
+	}
+
+	_, span = utils.StartSpan(ctx, "GenerateScreenShareCredentials")
+	session.ScreenShare, err = r.Tokens.GenerateUserCredentials(project, channelData.ChannelName, role, utils.TokenExpiry(channelData), false, false, utils.ChannelArea(channelData))
+	utils.EndSpan(span, err)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate screenshare user credentails")
+		return nil, errInternalServer
+	}
+
+	return session, nil
+}
+
+// whiteboardDetails generates the credentials to join the whiteboard room of a channel, or returns nil when the
+// channel has no whiteboard. Hosts administer the room, and viewers can only draw when they can publish. The
+// whiteboard is left out of the session when a token cannot be generated so that users can still join the call
+func (r *Resolver) whiteboardDetails(ctx context.Context, channelData *models.Channel, host bool, canPublish bool) *models.Whiteboard {
+	if !channelData.WhiteboardRoomUUID.Valid {
+		return nil
+	}
+
+	role := utils.WhiteboardRoleReader
+	if host {
+		role = utils.WhiteboardRoleAdmin
+	} else if canPublish {
+		role = utils.WhiteboardRoleWriter
+	}
+
+	lifespan := time.Duration(utils.TokenExpiry(channelData)) * time.Second
+	token, err := utils.WhiteboardRoomToken(ctx, channelData.WhiteboardRoomUUID.String, role, lifespan)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not generate whiteboard room token")
+		return nil
+	}
+
+	return &models.Whiteboard{
+		AppIdentifier: viper.GetString("WHITEBOARD_APP_IDENTIFIER"),
+		Region:        viper.GetString("WHITEBOARD_REGION"),
+		RoomUUID:      channelData.WhiteboardRoomUUID.String,
+		RoomToken:     token,
+		Writable:      role != utils.WhiteboardRoleReader,
+	}
+}
+
+// sipDetails returns the details SIP room systems join a channel with, or nil when SIP is not set up for it
+func sipDetails(channelData *models.Channel) *models.Sip {
+	if !channelData.SIPURI.Valid || channelData.DTMF == "" {
+		return nil
+	}
+
+	return &models.Sip{
+		URI: channelData.SIPURI.String,
+		Pin: channelData.DTMF,
+	}
+}
+
+// shortLinks returns the short links to the passphrases shared for a channel, creating them for passphrases that
+// have none. It returns nil when short links cannot be resolved, and on databases other than Postgres which have no
+// short links
+func (r *Resolver) shortLinks(ctx context.Context, db sqlx.QueryerContext, channelID int64, hostPassphrase *string, viewPassphrase string) (*models.ShortLinks, error) {
+	if !r.DB.Postgres() {
+		return nil, nil
+	}
+
+	viewSlug, err := services.CreateShortLink(ctx, db, channelID, viewPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostLink *string
+	if hostPassphrase != nil {
+		hostSlug, err := services.CreateShortLink(ctx, db, channelID, *hostPassphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		link := services.ShortLinkURL(hostSlug)
+		hostLink = &link
+	}
+
+	view := services.ShortLinkURL(viewSlug)
+	if view == "" {
+		return nil, nil
+	}
+
+	return &models.ShortLinks{Host: hostLink, View: view}, nil
+}
+
+// shareResponse builds the details that are shared to invite users to a channel
+func (r *Resolver) shareResponse(ctx context.Context, channelData *models.Channel, hostPassphrase *string, country *string) *models.ShareResponse {
+	var pstnResult *models.Pstn
+	if channelData.DTMF != "" {
+		pstnResult = r.pstnDetails(ctx, channelData.DTMF, country)
+	} else {
+		pstnResult = nil
+	}
+
+	// Short links are left out when they cannot be created, since the passphrases can still be shared
+	shortLinks, err := r.shortLinks(ctx, r.DB, channelData.ID, hostPassphrase, channelData.ViewerPassphrase)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not create short links")
+	}
+
+	return &models.ShareResponse{
+		Passphrase: &models.Passphrase{
+			Host: hostPassphrase,
+			View: channelData.ViewerPassphrase,
+		},
+		Channel:    channelData.ChannelName,
+		Title:      channelData.Title,
+		Pstn:       pstnResult,
+		Sip:        sipDetails(channelData),
+		ShortLinks: shortLinks,
+		Metadata:   r.channelMetadata(channelData),
+	}
+}
+
+// updateChannel changes the title and settings of a channel. Settings the input leaves out are kept
+func (r *Resolver) updateChannel(ctx context.Context, channelData *models.Channel, input models.UpdateChannelInput) error {
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return apierror.New(apierror.CodeBadRequest, "Title cannot be empty")
+		}
+		channelData.Title = title
+	}
+
+	if input.MaxParticipants != nil {
+		if *input.MaxParticipants < 0 {
+			return apierror.New(apierror.CodeBadRequest, "Participant limit cannot be negative")
+		}
+		channelData.MaxParticipants = sql.NullInt32{Int32: int32(*input.MaxParticipants), Valid: *input.MaxParticipants > 0}
+	}
+
+	if input.EnableWaitingRoom != nil {
+		if *input.EnableWaitingRoom {
+			if err := r.checkFeature(ctx, channelData, models.FeatureWaitingRoom); err != nil {
+				return err
+			}
+		}
+		channelData.WaitingRoom = *input.EnableWaitingRoom
+	}
+
+	if input.RecordingQuality != nil {
+		_, err := transcodingConfig(input.RecordingQuality)
+		if err != nil {
+			r.log(ctx).Debug().Err(err).Interface("Recording Quality", input.RecordingQuality).Msg("Invalid recording quality")
+			return err
+		}
+
+		quality, err := json.Marshal(input.RecordingQuality)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not encode recording quality")
+			return errInternalServer
+		}
+		channelData.RecordingQuality = types.NullJSONText{JSONText: quality, Valid: true}
+	}
+
+	if input.Area != nil {
+		// A running recording has to be stopped in the area it was started in
+		if channelData.RecordingSID.Valid && utils.ChannelArea(channelData) != *input.Area {
+			return apierror.New(apierror.CodeRecordingAlreadyActive, "Stop the recording before changing the area of the channel")
+		}
+		channelData.Area = sql.NullString{String: input.Area.String(), Valid: true}
+	}
+
+	createBridge := false
+	if input.EnablePstn != nil && *input.EnablePstn {
+		if channelData.EndedAt.Valid {
+			return errMeetingEnded
+		}
+
+		if viper.GetString("BACKEND_URL") == "" {
+			return apierror.New(apierror.CodeUnavailable, "Dial in is not available")
+		}
+
+		if err := r.checkFeature(ctx, channelData, models.FeaturePstn); err != nil {
+			return err
+		}
+
+		if channelData.DTMF == "" {
+			dtmf, err := r.uniqueDTMF(ctx)
+			if err != nil {
+				return err
+			}
+			channelData.DTMF = *dtmf
+		}
+
+		sipURI := services.SIPURI(channelData.DTMF)
+		channelData.SIPURI = sql.NullString{String: sipURI, Valid: sipURI != ""}
+		createBridge = true
+	} else if input.EnablePstn != nil {
+		channelData.DTMF = ""
+		channelData.SIPURI = sql.NullString{}
+	}
+
+	err := r.Repos.Channels.Update(ctx, channelData)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not update channel")
+		return errInternalServer
+	}
+
+	// Bridges are configured again when dial in is enabled, since there is no way to tell whether one was created
+	if createBridge {
+		services.CreateBridge(r.Logger, channelData.DTMF, strings.TrimSuffix(viper.GetString("BACKEND_URL"), "/"))
+	}
+
+	return nil
+}
+
+// maxChannelMetadataSize is the largest metadata, encoded as JSON, that can be attached to a channel
+const maxChannelMetadataSize = 16 * 1024
+
+// setChannelMetadata replaces the metadata of a channel
+func (r *Resolver) setChannelMetadata(ctx context.Context, channelData *models.Channel, metadata map[string]interface{}) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Msg("Invalid channel metadata")
+		return apierror.New(apierror.CodeBadRequest, "Metadata must be a JSON object")
+	}
+
+	if len(encoded) > maxChannelMetadataSize {
+		return apierror.New(apierror.CodeBadRequest, "Metadata cannot be larger than "+strconv.Itoa(maxChannelMetadataSize/1024)+" KB")
+	}
+
+	err = r.Repos.Channels.SetMetadata(ctx, channelData.ID, types.JSONText(encoded))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not set channel metadata")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// channelMetadata decodes the metadata of a channel. Metadata that cannot be decoded is left out
+func (r *Resolver) channelMetadata(channelData *models.Channel) map[string]interface{} {
+	result := map[string]interface{}{}
+	if len(channelData.Metadata) == 0 {
+		return result
+	}
+
+	err := channelData.Metadata.Unmarshal(&result)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not decode channel metadata")
+		return map[string]interface{}{}
+	}
+
+	return result
+}
+
+var customPassphrase = regexp.MustCompile("^[a-z0-9][a-z0-9-]{2,62}[a-z0-9]$")
+
+// errMeetingEnded is returned when joining a channel after a host has ended the meeting
+var errMeetingEnded = apierror.New(apierror.CodeMeetingEnded, "Meeting has ended")
+
+// errChannelLocked is returned when joining a channel that a host has locked
+var errChannelLocked = apierror.New(apierror.CodeChannelLocked, "Meeting is locked")
+
+// errChannelFull is returned when joining a channel that has reached its participant limit
+var errChannelFull = apierror.New(apierror.CodeChannelFull, "Meeting is full")
+
+// errPassphraseTaken is returned when a custom passphrase is already used by another channel
+var errPassphraseTaken = apierror.New(apierror.CodePassphraseTaken, "Passphrase is already taken")
+
+// validCustomPassphrase checks that a custom passphrase is 4 to 64 lowercase letters, digits or hyphens that
+// starts and ends with a letter or digit
+func validCustomPassphrase(passphrase string) bool {
+	return customPassphrase.MatchString(passphrase)
+}
+
+// meetingNotStarted is returned when joining a scheduled meeting before it starts. The extensions let clients
+// show a countdown until the meeting starts
+func meetingNotStarted(startsAt time.Time, now time.Time) error {
+	return &gqlerror.Error{
+		Message: "Meeting has not started",
+		Extensions: map[string]interface{}{
+			"code":     apierror.CodeMeetingNotStarted,
+			"startsAt": startsAt.UTC().Format(time.RFC3339),
+			"startsIn": int(startsAt.Sub(now).Seconds()),
+		},
+	}
+}
+
+// meetingEvent describes a scheduled channel as a calendar event, which lasts an hour when the channel has no end.
+// It returns nil for channels that are not scheduled
+func (r *Resolver) meetingEvent(ctx context.Context, channelData *models.Channel) *utils.MeetingEvent {
+	if !channelData.StartsAt.Valid {
+		return nil
+	}
+
+	endsAt := channelData.StartsAt.Time.Add(time.Hour)
+	if channelData.EndsAt.Valid {
+		endsAt = channelData.EndsAt.Time
+	}
+
+	event := &utils.MeetingEvent{
+		UID:      channelData.ChannelName + "@appbuilder.agora.io",
+		Title:    channelData.Title,
+		URL:      joinURL(channelData.ViewerPassphrase),
+		StartsAt: channelData.StartsAt.Time,
+		EndsAt:   endsAt,
+	}
+
+	if channelData.DTMF != "" {
+		pstn := r.pstnDetails(ctx, channelData.DTMF, nil)
+		event.Description = "Dial in: " + pstn.Number + " PIN: " + pstn.Dtmf
+	}
+
+	return event
+}
+
+// joinURL returns the link to join a channel with a passphrase, or an empty string when FRONTEND_URL is not set
+func joinURL(passphrase string) string {
+	if viper.GetString("FRONTEND_URL") == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(viper.GetString("FRONTEND_URL"), "/") + "/" + passphrase
+}
+
+// isWebURL checks whether the given string is an absolute http or https URL
+func isWebURL(rawURL string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+}
+
+// storageSettings validates the bucket supplied by a host and converts it into storage settings
+func storageSettings(storage *models.ChannelStorageInput) (*utils.StorageSettings, error) {
+	if !storage.Provider.IsValid() || storage.Bucket == "" || storage.AccessKey == "" || storage.SecretKey == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid storage")
+	}
+
+	settings := utils.StorageSettings{
+		Provider:  strings.ToLower(string(storage.Provider)),
+		Bucket:    storage.Bucket,
+		AccessKey: storage.AccessKey,
+		SecretKey: storage.SecretKey,
+	}
+
+	if storage.Region != nil {
+		settings.Region = *storage.Region
+	}
+
+	if _, err := utils.NewStorageProvider(settings); err != nil {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid storage region")
+	}
+
+	return &settings, nil
+}
+
+// channelStorage returns the storage that recordings of a channel are uploaded to
+func (r *Resolver) channelStorage(ctx context.Context, channelData *models.Channel) (utils.StorageProvider, error) {
+	storage, err := services.ChannelStorage(ctx, r.DB, channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not create storage provider")
+		return nil, errInternalServer
+	}
+
+	return storage, nil
+}
+
+// channelProject returns the Agora project that tokens and RESTful API requests of a channel use
+func (r *Resolver) channelProject(ctx context.Context, channelData *models.Channel) (*utils.AgoraProject, error) {
+	project, err := services.OrganizationProject(ctx, r.DB, channelData.OrganizationID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch Agora project")
+		return nil, errInternalServer
+	}
+
+	return project, nil
+}
+
+// checkCapacity refuses to let users join a channel that is locked, temporarily or by a host, or already has as many
+// participants as it allows.
+// Users can still join when the participant count is unavailable, so an outage of the Agora API does not block meetings
+func (r *Resolver) checkCapacity(ctx context.Context, channelData *models.Channel) error {
+	if channelData.Locked {
+		return errChannelLocked
+	}
+
+	if channelData.LockedUntil.Valid && channelData.LockedUntil.Time.After(time.Now()) {
+		return errChannelLockedOut
+	}
+
+	if !channelData.MaxParticipants.Valid {
+		return nil
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return err
+	}
+
+	list, err := r.Agora.GetChannelUsers(project, channelData.ChannelName)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not fetch channel users")
+		return nil
+	}
+
+	var ignore []int32
+	if channelData.RecordingUID.Valid {
+		ignore = append(ignore, channelData.RecordingUID.Int32)
+	}
+
+	if list.Participants(ignore...) >= int(channelData.MaxParticipants.Int32) {
+		r.Logger.Debug().Str("channel", channelData.ChannelName).Int32("maxParticipants", channelData.MaxParticipants.Int32).Msg("Channel is full")
+		return errChannelFull
+	}
+
+	return nil
+}
+
+// transferHost makes newOwner the owner of a channel. The new owner gets a new host passphrase, which they can
+// fetch with the share query, while the host passphrase of the previous owner keeps working as a co-host passphrase
+func (r *Resolver) transferHost(ctx context.Context, channelData *models.Channel, previousOwner *models.UserAccount, newOwner *models.UserAccount) (string, error) {
+	newPhrase, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Host Phrase generation failed")
+		return "", errInternalServer
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not start transaction")
+		return "", errInternalServer
+	}
+	defer tx.Rollback()
+
+	channels := r.Repos.WithTx(tx).Channels
+	err = channels.SetOwner(ctx, channelData.ID, newOwner.ID, newPhrase)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not update channel owner")
+		return "", errInternalServer
+	}
+
+	err = channels.RenamePassphrases(ctx, channelData.ID, models.PassphraseTypeHost, models.PassphraseTypeCohost, "Previous host")
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not demote previous host passphrase")
+		return "", errInternalServer
+	}
+
+	err = channels.AddPassphrases(ctx, models.ChannelPassphrase{
+		ChannelID:  channelData.ID,
+		Passphrase: newPhrase,
+		Name:       "Host",
+		Role:       models.PassphraseTypeHost,
+	})
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not add new host passphrase")
+		return "", errInternalServer
+	}
+
+	err = channels.LogHostTransfer(ctx, channelData.ID, previousOwner.ID, newOwner.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not log host transfer")
+		return "", errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Transferring host failed")
+		return "", errInternalServer
+	}
+
+	return newPhrase, nil
+}
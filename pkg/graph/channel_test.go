@@ -0,0 +1,141 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+//go:build sqlite
+// +build sqlite
+
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
+)
+
+// fakeTokens hands out fixed credentials, so that sessions can be built without an app certificate
+type fakeTokens struct {
+	roles []rtctoken.Role
+}
+
+func (f *fakeTokens) GenerateUserCredentials(project *utils.AgoraProject, channel string, role rtctoken.Role, expiry uint32, rtm bool, pstn bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	f.roles = append(f.roles, role)
+	credentials := &models.UserCredentials{Rtc: "rtc-" + channel, UID: 1, Area: area}
+	if rtm {
+		token := "rtm-" + channel
+		credentials.Rtm = &token
+	}
+	return credentials, nil
+}
+
+func (f *fakeTokens) GenerateAudioUserCredentials(project *utils.AgoraProject, channel string, role rtctoken.Role, expiry uint32, area models.AgoraArea) (*models.UserCredentials, error) {
+	return f.GenerateUserCredentials(project, channel, role, expiry, true, false, area)
+}
+
+func (f *fakeTokens) RenewUserCredentials(project *utils.AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32, audioOnly bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	return &models.UserCredentials{Rtc: "rtc-" + channel, UID: uid, Area: area}, nil
+}
+
+func TestJoinChannel(t *testing.T) {
+	audioOnly := models.JoinModeAudioOnly
+	tests := []struct {
+		name       string
+		passphrase string
+		mode       *models.JoinMode
+		// subscribeOnly keeps viewers of the channel from publishing
+		subscribeOnly bool
+		wantRole      models.PassphraseType
+		wantPublish   bool
+		wantShare     bool
+	}{
+		{name: "host", passphrase: "host-phrase", wantRole: models.PassphraseTypeHost, wantPublish: true, wantShare: true},
+		{name: "viewer", passphrase: "view-phrase", wantRole: models.PassphraseTypeViewer, wantPublish: true, wantShare: true},
+		{name: "subscribing viewer", passphrase: "view-phrase", subscribeOnly: true, wantRole: models.PassphraseTypeViewer, wantShare: true},
+		{name: "audio only", passphrase: "host-phrase", mode: &audioOnly, wantRole: models.PassphraseTypeHost, wantPublish: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// APP_CERTIFICATE is not set, so only the fake generator can build the credentials
+			tokens := &fakeTokens{}
+			r := newTestResolver(t)
+			r.Tokens = tokens
+			createTestChannel(t, r, "host-phrase", "view-phrase")
+			if test.subscribeOnly {
+				_, err := r.DB.Exec("UPDATE channels SET allow_viewers_to_publish = FALSE")
+				if err != nil {
+					t.Fatalf("could not keep viewers from publishing: %v", err)
+				}
+			}
+
+			name := "Ada"
+			session, err := (&queryResolver{r}).JoinChannel(context.Background(), test.passphrase, &name, test.mode)
+			if err != nil {
+				t.Fatalf("could not join channel: %v", err)
+			}
+
+			if session.Role != test.wantRole || session.CanPublish != test.wantPublish {
+				t.Fatalf("got %s that can publish %t, want %s that can publish %t", session.Role, session.CanPublish, test.wantRole, test.wantPublish)
+			}
+			if session.MainUser == nil || session.MainUser.Rtc != "rtc-"+session.Channel || session.MainUser.Rtm == nil {
+				t.Fatalf("main user has credentials %+v, want the fake credentials", session.MainUser)
+			}
+			if (session.ScreenShare != nil) != test.wantShare {
+				t.Fatalf("got screen share credentials %+v, want them %t", session.ScreenShare, test.wantShare)
+			}
+
+			wantRole := rtctoken.Role(rtctoken.RoleSubscriber)
+			if test.wantPublish {
+				wantRole = rtctoken.RolePublisher
+			}
+			for _, role := range tokens.roles {
+				if role != wantRole {
+					t.Fatalf("credentials were generated for role %v, want %v", role, wantRole)
+				}
+			}
+		})
+	}
+}
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		wantHost   bool
+	}{
+		{name: "host", passphrase: "host-phrase", wantHost: true},
+		{name: "viewer", passphrase: "view-phrase"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestResolver(t)
+			r.Tokens = &fakeTokens{}
+			createTestChannel(t, r, "host-phrase", "view-phrase")
+
+			share, err := (&queryResolver{r}).Share(context.Background(), test.passphrase, nil)
+			if err != nil {
+				t.Fatalf("could not share channel: %v", err)
+			}
+
+			if share.Passphrase.View != "view-phrase" {
+				t.Fatalf("got view passphrase %s, want view-phrase", share.Passphrase.View)
+			}
+			if test.wantHost && (share.Passphrase.Host == nil || *share.Passphrase.Host != "host-phrase") {
+				t.Fatalf("got host passphrase %v, want host-phrase", share.Passphrase.Host)
+			}
+			if !test.wantHost && share.Passphrase.Host != nil {
+				t.Fatalf("viewer got host passphrase %s", *share.Passphrase.Host)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+const (
+	// maxClientConfigSize is the largest configuration, encoded as JSON, that can be delivered to frontends
+	maxClientConfigSize = 64 * 1024
+	maxClientConfigPage = 100
+)
+
+// videoProfile matches the names of the video encoder profiles of the Agora SDKs, like 720p or 720p_1
+var videoProfile = regexp.MustCompile(`^[0-9]{3,4}p(_[0-9]+)?$`)
+
+var turnURL = regexp.MustCompile(`^(turns?|stun):[^\s]+$`)
+
+// clientConfigDocument is how a version of the client configuration is stored
+type clientConfigDocument struct {
+	Branding     map[string]interface{} `json:"branding"`
+	VideoProfile *string                `json:"videoProfile,omitempty"`
+	TurnServers  []*models.TurnServer   `json:"turnServers"`
+	CloudProxy   bool                   `json:"cloudProxy"`
+	Settings     map[string]interface{} `json:"settings"`
+}
+
+// currentClientConfig fetches the latest version of the client configuration, which is nil before it is first set
+func (r *Resolver) currentClientConfig(ctx context.Context) (*models.ClientConfigVersion, error) {
+	var stored models.ClientConfigVersion
+	err := r.DB.GetContext(ctx, &stored, "SELECT id, created_at, version, config, created_by FROM client_configs ORDER BY version DESC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch client config")
+		return nil, errInternalServer
+	}
+
+	return &stored, nil
+}
+
+// clientConfig returns the current client configuration along with the features that are turned on globally, or for
+// the channel of passphrase
+func (r *Resolver) clientConfig(ctx context.Context, passphrase *string) (*models.ClientConfig, error) {
+	channelData := &models.Channel{}
+	if passphrase != nil {
+		var err error
+		channelData, _, err = r.getChannel(ctx, *passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stored, err := r.currentClientConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	document := clientConfigDocument{}
+	result := &models.ClientConfig{}
+	if stored != nil {
+		err = json.Unmarshal(stored.Config, &document)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int("version", stored.Version).Msg("Could not decode client config")
+			return nil, errInternalServer
+		}
+
+		result.Version = stored.Version
+		result.UpdatedAt = &stored.CreatedAt
+	}
+
+	result.Branding = document.Branding
+	if result.Branding == nil {
+		result.Branding = map[string]interface{}{}
+	}
+
+	result.Settings = document.Settings
+	if result.Settings == nil {
+		result.Settings = map[string]interface{}{}
+	}
+
+	result.TurnServers = document.TurnServers
+	if result.TurnServers == nil {
+		result.TurnServers = []*models.TurnServer{}
+	}
+
+	result.VideoProfile = document.VideoProfile
+	result.CloudProxy = document.CloudProxy
+	result.Features = r.channelFeatures(ctx, channelData)
+
+	return result, nil
+}
+
+// saveClientConfig stores a configuration as the next version of the client configuration
+func (r *Resolver) saveClientConfig(ctx context.Context, document *clientConfigDocument) (*models.ClientConfig, error) {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not encode client config")
+		return nil, errInternalServer
+	}
+
+	if len(encoded) > maxClientConfigSize {
+		return nil, apierror.New(apierror.CodeBadRequest, "Client config can be at most "+strconv.Itoa(maxClientConfigSize/1024)+" KB")
+	}
+
+	createdBy := sql.NullInt64{}
+	if user, err := middleware.GetUserFromContext(ctx); err == nil {
+		createdBy = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	// Versions are unique, so an admin saving at the same time as another fails instead of overwriting their change
+	_, err = r.DB.ExecContext(ctx, "INSERT INTO client_configs (version, config, created_by) SELECT COALESCE(MAX(version), 0) + 1, $1, $2 FROM client_configs", string(encoded), createdBy)
+	if models.IsUniqueViolation(err) {
+		return nil, apierror.New(apierror.CodeBadRequest, "Client config was changed at the same time, try again")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not save client config")
+		return nil, errInternalServer
+	}
+
+	return r.clientConfig(ctx, nil)
+}
+
+// setClientConfig validates a configuration and stores it as a new version
+func (r *Resolver) setClientConfig(ctx context.Context, input models.ClientConfigInput) (*models.ClientConfig, error) {
+	if input.VideoProfile != nil && !videoProfile.MatchString(*input.VideoProfile) {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid video profile")
+	}
+
+	document := &clientConfigDocument{
+		Branding:     input.Branding,
+		VideoProfile: input.VideoProfile,
+		TurnServers:  []*models.TurnServer{},
+		CloudProxy:   input.CloudProxy != nil && *input.CloudProxy,
+		Settings:     input.Settings,
+	}
+
+	for _, server := range input.TurnServers {
+		if len(server.Urls) == 0 {
+			return nil, apierror.New(apierror.CodeBadRequest, "TURN servers need at least one URL")
+		}
+
+		for _, url := range server.Urls {
+			if !isTurnURL(url) {
+				return nil, apierror.New(apierror.CodeBadRequest, "Invalid TURN server URL "+url)
+			}
+		}
+
+		document.TurnServers = append(document.TurnServers, &models.TurnServer{
+			Urls:       server.Urls,
+			Username:   server.Username,
+			Credential: server.Credential,
+		})
+	}
+
+	return r.saveClientConfig(ctx, document)
+}
+
+// restoreClientConfig stores an earlier version of the client configuration as a new version
+func (r *Resolver) restoreClientConfig(ctx context.Context, version int) (*models.ClientConfig, error) {
+	var config string
+	err := r.DB.GetContext(ctx, &config, "SELECT config FROM client_configs WHERE version = $1", version)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Client config version not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int("version", version).Msg("Could not fetch client config version")
+		return nil, errInternalServer
+	}
+
+	var document clientConfigDocument
+	err = json.Unmarshal([]byte(config), &document)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int("version", version).Msg("Could not decode client config")
+		return nil, errInternalServer
+	}
+
+	return r.saveClientConfig(ctx, &document)
+}
+
+// clientConfigHistory lists the versions of the client configuration, most recent first
+func (r *Resolver) clientConfigHistory(ctx context.Context, limit int) ([]*models.ClientConfigHistoryEntry, error) {
+	if limit <= 0 || limit > maxClientConfigPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxClientConfigPage))
+	}
+
+	stored := []models.ClientConfigVersion{}
+	err := r.DB.SelectContext(ctx, &stored, "SELECT id, created_at, version, config, created_by FROM client_configs ORDER BY version DESC LIMIT $1", limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch client config history")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.ClientConfigHistoryEntry, len(stored))
+	for index, version := range stored {
+		result[index] = &models.ClientConfigHistoryEntry{
+			Version:   version.Version,
+			CreatedAt: version.CreatedAt,
+			CreatedBy: nullableID(version.CreatedBy),
+		}
+	}
+
+	return result, nil
+}
+
+// isTurnURL checks that a URL is a TURN, TURNS or STUN URL, the schemes WebRTC accepts for ICE servers
+func isTurnURL(url string) bool {
+	return turnURL.MatchString(url)
+}
@@ -0,0 +1,49 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) SetClientConfig(ctx context.Context, input models.ClientConfigInput) (*models.ClientConfig, error) {
+	r.log(ctx).Info().Str("mutation", "SetClientConfig").Msg("")
+
+	return r.setClientConfig(ctx, input)
+}
+
+func (r *mutationResolver) RestoreClientConfig(ctx context.Context, version int) (*models.ClientConfig, error) {
+	r.log(ctx).Info().Str("mutation", "RestoreClientConfig").Int("version", version).Msg("")
+
+	return r.restoreClientConfig(ctx, version)
+}
+
+func (r *queryResolver) ClientConfig(ctx context.Context, passphrase *string) (*models.ClientConfig, error) {
+	r.log(ctx).Info().Str("query", "ClientConfig").Interface("passphrase", passphrase).Msg("")
+
+	return r.clientConfig(ctx, passphrase)
+}
+
+func (r *queryResolver) ClientConfigHistory(ctx context.Context, limit *int) ([]*models.ClientConfigHistoryEntry, error) {
+	r.log(ctx).Info().Str("query", "ClientConfigHistory").Interface("limit", limit).Msg("")
+
+	pageSize := 20
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.clientConfigHistory(ctx, pageSize)
+}
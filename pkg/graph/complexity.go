@@ -0,0 +1,59 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// SetComplexity charges paginated fields for every item of the page they ask for instead of once, so that the
+// complexity limit of the server also bounds how many items a query can fetch
+func SetComplexity(complexity *generated.ComplexityRoot) {
+	complexity.Query.AuditLog = func(childComplexity int, channel *string, operation *string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxAuditPage)
+	}
+	complexity.Query.ChannelMessages = func(childComplexity int, passphrase string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxMessagePage)
+	}
+	complexity.Query.ClientConfigHistory = func(childComplexity int, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxClientConfigPage)
+	}
+	complexity.Query.Jobs = func(childComplexity int, status *models.JobStatus, kind *string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxJobPage)
+	}
+	complexity.Query.ListAllChannels = func(childComplexity int, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxAdminChannelPage)
+	}
+	complexity.Query.OrganizationChannels = func(childComplexity int, organizationID string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxOrganizationChannelPage)
+	}
+	complexity.Query.SupportTickets = func(childComplexity int, channel *string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxSupportTicketPage)
+	}
+	complexity.Query.WebhookDeliveries = func(childComplexity int, webhookID string, before *string, limit *int) int {
+		return pageComplexity(childComplexity, limit, maxWebhookDeliveryPage)
+	}
+}
+
+// pageComplexity is the complexity of a page of limit items. Limits above max are refused by the resolvers, so they
+// are charged as max
+func pageComplexity(childComplexity int, limit *int, max int) int {
+	items := 1
+	if limit != nil && *limit > 1 {
+		items = *limit
+	}
+	if items > max {
+		items = max
+	}
+
+	return 1 + childComplexity*items
+}
@@ -0,0 +1,371 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// emailProvider is the provider stored on users that signed up with their email
+const emailProvider = "email"
+
+// dummyPasswordHash is compared against when signing in with an unknown email, so that unknown emails take as long
+// to reject as wrong passwords
+const dummyPasswordHash = "$2a$10$odM0pUO2vRB81iyi8bVsFeOSzpsaoxx6x/UFK10UkBKOZRqX10f.6"
+
+var errInvalidCredentials = apierror.New(apierror.CodeInvalidCredentials, "Invalid email or password")
+
+var errEmailNotVerified = apierror.New(apierror.CodeEmailNotVerified, "Email is not verified")
+
+var errInvalidEmailToken = apierror.New(apierror.CodeBadRequest, services.ErrInvalidEmailToken.Error())
+
+var errNotAllowed = apierror.New(apierror.CodeForbidden, "Email not found in Allow List")
+
+func errAuthDisabled(method string) error {
+	return apierror.New(apierror.CodeUnavailable, method+" sign in is not enabled")
+}
+
+// normalizeEmail lower cases an email so that an address can only sign up once regardless of its case
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkEmailAuth checks that a native sign in method is enabled and that emails can be sent
+func (r *Resolver) checkEmailAuth(ctx context.Context, enabledKey string, method string) error {
+	if !viper.GetBool(enabledKey) || r.Mailer == nil {
+		r.log(ctx).Debug().Str("method", method).Bool("mailer", r.Mailer != nil).Msg("Sign in method is not available")
+		return errAuthDisabled(method)
+	}
+
+	return nil
+}
+
+// checkPassword rejects passwords shorter than PASSWORD_MIN_LENGTH
+func checkPassword(password string) error {
+	if len(password) < viper.GetInt("PASSWORD_MIN_LENGTH") {
+		return apierror.New(apierror.CodeBadRequest, "Password is too short")
+	}
+
+	return nil
+}
+
+// allowListed checks an email against ALLOW_LIST, like the emails of users signing in with OAuth
+func (r *Resolver) allowListed(ctx context.Context, email string) error {
+	router := &services.ServiceRouter{DB: r.DB, Logger: r.log(ctx), Redis: r.Redis, Recorder: r.Recorder, Agora: r.Agora, Repos: r.Repos, PubSub: r.PubSub}
+	ok, err := router.AllowListValidator(email)
+	if err != nil {
+		return errInternalServer
+	}
+
+	if !ok {
+		return errNotAllowed
+	}
+
+	return nil
+}
+
+// sendEmailLink emails a user a link to EMAIL_LINK_URL carrying a new single use token
+func (r *Resolver) sendEmailLink(ctx context.Context, user *models.UserAccount, purpose string, subject string, text string) error {
+	token, err := services.IssueEmailToken(ctx, r.DB, user.ID, purpose)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Str("purpose", purpose).Msg("Could not issue email token")
+		return errInternalServer
+	}
+
+	link, err := url.Parse(viper.GetString("EMAIL_LINK_URL"))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Invalid EMAIL_LINK_URL")
+		return errInternalServer
+	}
+
+	query := link.Query()
+	query.Set("action", purpose)
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	expiry := time.Duration(viper.GetInt("EMAIL_TOKEN_EXPIRY_MINUTES")) * time.Minute
+	body := text + "\n\n" + link.String() + "\n\nThe link expires in " + expiry.String() + ". If you did not request it, you can ignore this email."
+	err = r.Mailer.Send(&utils.MailMessage{To: user.Email, Subject: subject, Text: body})
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Str("purpose", purpose).Msg("Could not send email")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// userByEmail looks up the user that signed up with an email along with its password hash
+func (r *Resolver) userByEmail(ctx context.Context, email string) (*models.UserAccount, error) {
+	return r.Repos.Users.ByEmail(ctx, email)
+}
+
+// signInWithEmailToken consumes an emailed token, marks the email of its user as verified and signs the user in.
+// When a new password is given it is set on the user and every other session of the user is signed out
+func (r *Resolver) signInWithEmailToken(ctx context.Context, token string, purpose string, passwordHash *string) (*models.AuthSession, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	userID, err := services.ConsumeEmailToken(ctx, tx, token, purpose)
+	if err == services.ErrInvalidEmailToken {
+		return nil, errInvalidEmailToken
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("purpose", purpose).Msg("Could not consume email token")
+		return nil, errInternalServer
+	}
+
+	err = r.Repos.WithTx(tx).Users.VerifyEmail(ctx, userID, passwordHash)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not verify user email")
+		return nil, errInternalServer
+	}
+
+	if passwordHash != nil {
+		_, err = services.RevokeUserSessions(ctx, r.Repos.WithTx(tx), userID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not revoke sessions after password reset")
+			return nil, errInternalServer
+		}
+	}
+
+	tokens, err := services.CreateAuthSession(ctx, r.Repos.WithTx(tx), userID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not create session")
+		return nil, errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not commit sign in")
+		return nil, errInternalServer
+	}
+
+	return &models.AuthSession{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+// signUp creates a user with a password and emails it a link to verify its email. Signing up with an email that
+// is taken succeeds without creating a user, so that the response does not reveal which emails have accounts
+func (r *Resolver) signUp(ctx context.Context, email string, password string, name *string) (string, error) {
+	if err := r.checkEmailAuth(ctx, "ENABLE_PASSWORD_AUTH", "Password"); err != nil {
+		return "", err
+	}
+
+	email = normalizeEmail(email)
+	if !strings.Contains(email, "@") {
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid email")
+	}
+
+	if err := checkPassword(password); err != nil {
+		return "", err
+	}
+
+	if err := r.allowListed(ctx, email); err != nil {
+		return "", err
+	}
+
+	existing, err := r.userByEmail(ctx, email)
+	if err == nil {
+		if existing.PasswordHash.Valid && !existing.EmailVerifiedAt.Valid {
+			if err := r.sendEmailLink(ctx, existing, services.EmailTokenVerify, "Verify your email", "Open this link to verify your email and sign in:"); err != nil {
+				return "", err
+			}
+
+			return "success", nil
+		}
+
+		r.log(ctx).Info().Int64("User ID", existing.ID).Msg("Sign up with an email that already has an account")
+		return "success", nil
+	}
+
+	if err != sql.ErrNoRows {
+		r.log(ctx).Error().Err(err).Msg("Could not look up user")
+		return "", errInternalServer
+	}
+
+	passwordHash, err := services.HashPassword(password)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not hash password")
+		return "", errInternalServer
+	}
+
+	user := models.UserAccount{
+		Identifier:   email,
+		Email:        email,
+		Provider:     sql.NullString{String: emailProvider, Valid: true},
+		PasswordHash: sql.NullString{String: passwordHash, Valid: true},
+	}
+	if name != nil && *name != "" {
+		user.UserName = sql.NullString{String: *name, Valid: true}
+	}
+
+	err = r.Repos.Users.Create(ctx, &user)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not insert user")
+		return "", errInternalServer
+	}
+
+	if err := r.sendEmailLink(ctx, &user, services.EmailTokenVerify, "Verify your email", "Open this link to verify your email and sign in:"); err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+// login signs a user in with its email and password
+func (r *Resolver) login(ctx context.Context, email string, password string) (*models.AuthSession, error) {
+	if err := r.checkEmailAuth(ctx, "ENABLE_PASSWORD_AUTH", "Password"); err != nil {
+		return nil, err
+	}
+
+	user, err := r.userByEmail(ctx, normalizeEmail(email))
+	if err != nil && err != sql.ErrNoRows {
+		r.log(ctx).Error().Err(err).Msg("Could not look up user")
+		return nil, errInternalServer
+	}
+
+	if err == sql.ErrNoRows || !user.PasswordHash.Valid {
+		services.CheckPassword(dummyPasswordHash, password)
+		return nil, errInvalidCredentials
+	}
+
+	if !services.CheckPassword(user.PasswordHash.String, password) {
+		r.log(ctx).Info().Int64("User ID", user.ID).Msg("Wrong password")
+		return nil, errInvalidCredentials
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		return nil, errEmailNotVerified
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	tokens, err := services.CreateAuthSession(ctx, r.Repos.WithTx(tx), user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not create session")
+		return nil, errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not commit sign in")
+		return nil, errInternalServer
+	}
+
+	return &models.AuthSession{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+// requestPasswordReset emails a password reset link to a user. It succeeds for unknown emails as well
+func (r *Resolver) requestPasswordReset(ctx context.Context, email string) (string, error) {
+	if err := r.checkEmailAuth(ctx, "ENABLE_PASSWORD_AUTH", "Password"); err != nil {
+		return "", err
+	}
+
+	user, err := r.userByEmail(ctx, normalizeEmail(email))
+	if err == sql.ErrNoRows {
+		r.log(ctx).Info().Msg("Password reset requested for an unknown email")
+		return "success", nil
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not look up user")
+		return "", errInternalServer
+	}
+
+	if err := r.sendEmailLink(ctx, user, services.EmailTokenReset, "Reset your password", "Open this link to choose a new password:"); err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+// resetPassword sets a new password with a reset token, signs the user out everywhere else and signs it in
+func (r *Resolver) resetPassword(ctx context.Context, token string, password string) (*models.AuthSession, error) {
+	if err := r.checkEmailAuth(ctx, "ENABLE_PASSWORD_AUTH", "Password"); err != nil {
+		return nil, err
+	}
+
+	if err := checkPassword(password); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := services.HashPassword(password)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not hash password")
+		return nil, errInternalServer
+	}
+
+	return r.signInWithEmailToken(ctx, token, services.EmailTokenReset, &passwordHash)
+}
+
+// requestMagicLink emails a sign in link to a user, creating a user without a password for new emails
+func (r *Resolver) requestMagicLink(ctx context.Context, email string) (string, error) {
+	if err := r.checkEmailAuth(ctx, "ENABLE_MAGIC_LINK_AUTH", "Magic link"); err != nil {
+		return "", err
+	}
+
+	email = normalizeEmail(email)
+	if !strings.Contains(email, "@") {
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid email")
+	}
+
+	if err := r.allowListed(ctx, email); err != nil {
+		return "", err
+	}
+
+	user, err := r.userByEmail(ctx, email)
+	if err == sql.ErrNoRows {
+		user = &models.UserAccount{
+			Identifier: email,
+			Email:      email,
+			Provider:   sql.NullString{String: emailProvider, Valid: true},
+		}
+		err = r.Repos.Users.Create(ctx, user)
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not look up user")
+		return "", errInternalServer
+	}
+
+	if err := r.sendEmailLink(ctx, user, services.EmailTokenMagicLink, "Your sign in link", "Open this link to sign in:"); err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
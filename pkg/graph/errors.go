@@ -0,0 +1,60 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"errors"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+var errInternalServer error = apierror.New(apierror.CodeInternal, "Internal Server Error")
+var errBadRequest error = apierror.New(apierror.CodeBadRequest, "Bad Request")
+
+// errInvalidToken is returned when OAuth is enabled and the request does not carry a valid login token,
+// which clients handle by logging in again
+var errInvalidToken = apierror.New(apierror.CodeTokenExpired, "Invalid Token")
+
+// errChannelNotFound is returned when a passphrase does not belong to any channel
+var errChannelNotFound = apierror.New(apierror.CodeChannelNotFound, "Invalid URL")
+
+// errRecordingNotStarted is returned when managing the recording of a channel that is not being recorded
+var errRecordingNotStarted = apierror.New(apierror.CodeRecordingNotActive, "Recording not started")
+
+// errRecordingActive is returned when starting a recording while a recording of another kind is running
+var errRecordingActive = apierror.New(apierror.CodeRecordingAlreadyActive, "Another recording is already running")
+
+// errServiceDegraded is returned when Agora is unavailable, which clients can handle by trying again later
+var errServiceDegraded = apierror.New(apierror.CodeServiceDegraded, "Agora is unavailable, try again later")
+
+// agoraError returns the error for a failed request to Agora, telling apart outages of Agora and requests Agora
+// rejected. The code and reason Agora gave are passed on to clients
+func agoraError(err error) error {
+	if errors.Is(err, utils.ErrAgoraUnavailable) {
+		return errServiceDegraded
+	}
+
+	var agoraErr *utils.AgoraError
+	if errors.As(err, &agoraErr) {
+		return apierror.WithDetails(apierror.CodeAgoraError, "Agora rejected the request: "+agoraErr.Reason, map[string]interface{}{
+			"agoraStatus": agoraErr.Status,
+			"agoraCode":   agoraErr.Code,
+		})
+	}
+
+	return errInternalServer
+}
+
+// errNotHost is returned when a participant without host rights attempts action
+func errNotHost(action string) error {
+	return apierror.New(apierror.CodeNotHost, "Unauthorised to "+action)
+}
@@ -0,0 +1,189 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+// featureNames describe features in errors
+var featureNames = map[models.Feature]string{
+	models.FeatureWaitingRoom:   "Waiting room",
+	models.FeatureTranscription: "Transcription",
+	models.FeaturePstn:          "Phone calls",
+	models.FeatureLiveStreaming: "Live streaming",
+}
+
+// featureKey maps a feature of the API onto the name its flags are stored with
+func featureKey(feature models.Feature) string {
+	return strings.ToLower(string(feature))
+}
+
+// featureEnabled evaluates a feature flag for a channel. Features stay on when flags cannot be evaluated, so that an
+// outage of the database does not turn features off in meetings. Every feature behind a flag needs tables that only
+// Postgres has, so they are off on other databases
+func (r *Resolver) featureEnabled(ctx context.Context, channelData *models.Channel, feature models.Feature) bool {
+	if !r.DB.Postgres() {
+		return false
+	}
+
+	enabled, err := services.FeatureEnabled(ctx, r.DB, featureKey(feature), channelData.OrganizationID, channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("feature", feature.String()).Msg("Could not evaluate feature flags")
+	}
+
+	return enabled
+}
+
+// checkFeature returns a FEATURE_DISABLED error when a feature is turned off for a channel
+func (r *Resolver) checkFeature(ctx context.Context, channelData *models.Channel, feature models.Feature) error {
+	if r.featureEnabled(ctx, channelData, feature) {
+		return nil
+	}
+
+	r.log(ctx).Debug().Int64("Channel ID", channelData.ID).Str("feature", feature.String()).Msg("Feature is disabled")
+	return apierror.New(apierror.CodeFeatureDisabled, featureNames[feature]+" is disabled for this channel")
+}
+
+// channelFeatures lists the features that are turned on for a channel
+func (r *Resolver) channelFeatures(ctx context.Context, channelData *models.Channel) []models.Feature {
+	features := []models.Feature{}
+	for _, feature := range models.AllFeature {
+		if r.featureEnabled(ctx, channelData, feature) {
+			features = append(features, feature)
+		}
+	}
+
+	return features
+}
+
+// featureFlagScope resolves the organization or channel a feature flag is set for. Both are invalid for global flags
+func (r *Resolver) featureFlagScope(ctx context.Context, organizationID *string, channel *string) (sql.NullInt64, sql.NullInt64, error) {
+	if organizationID != nil && channel != nil {
+		return sql.NullInt64{}, sql.NullInt64{}, apierror.New(apierror.CodeBadRequest, "Feature flags are set for an organization or a channel, not both")
+	}
+
+	if organizationID != nil {
+		id, err := strconv.ParseInt(*organizationID, 10, 64)
+		if err != nil {
+			return sql.NullInt64{}, sql.NullInt64{}, apierror.New(apierror.CodeBadRequest, "Invalid organization ID")
+		}
+
+		return sql.NullInt64{Int64: id, Valid: true}, sql.NullInt64{}, nil
+	}
+
+	if channel != nil {
+		id, err := r.Repos.Channels.IDByName(ctx, *channel)
+		if err == sql.ErrNoRows {
+			return sql.NullInt64{}, sql.NullInt64{}, errChannelNotFound
+		}
+
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("channel", *channel).Msg("Could not fetch channel")
+			return sql.NullInt64{}, sql.NullInt64{}, errInternalServer
+		}
+
+		return sql.NullInt64{}, sql.NullInt64{Int64: id, Valid: true}, nil
+	}
+
+	return sql.NullInt64{}, sql.NullInt64{}, nil
+}
+
+// featureFlag maps a stored feature flag onto the schema
+func featureFlag(stored *models.FeatureFlagSetting, channel sql.NullString) *models.FeatureFlag {
+	return &models.FeatureFlag{
+		Feature:        models.Feature(strings.ToUpper(stored.Feature)),
+		Enabled:        stored.Enabled,
+		OrganizationID: nullableID(stored.OrganizationID),
+		Channel:        nullableString(channel),
+		UpdatedAt:      stored.UpdatedAt,
+	}
+}
+
+// featureFlags lists every feature flag
+func (r *Resolver) featureFlags(ctx context.Context) ([]*models.FeatureFlag, error) {
+	var stored []struct {
+		models.FeatureFlagSetting
+		ChannelName sql.NullString `db:"channel_name"`
+	}
+	err := r.DB.SelectContext(ctx, &stored, `SELECT feature_flags.id, feature_flags.created_at, feature_flags.updated_at, feature_flags.feature,
+		feature_flags.organization_id, feature_flags.channel_id, feature_flags.enabled, channels.channel_name
+		FROM feature_flags LEFT JOIN channels ON channels.id = feature_flags.channel_id ORDER BY feature_flags.feature, feature_flags.id`)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch feature flags")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.FeatureFlag, len(stored))
+	for index := range stored {
+		result[index] = featureFlag(&stored[index].FeatureFlagSetting, stored[index].ChannelName)
+	}
+
+	return result, nil
+}
+
+// setFeatureFlag creates or updates a feature flag. Flags are cached by every instance, so other instances apply the
+// change within FEATURE_FLAG_CACHE_SECONDS
+func (r *Resolver) setFeatureFlag(ctx context.Context, feature models.Feature, enabled bool, organizationID *string, channel *string) (*models.FeatureFlag, error) {
+	organization, channelID, err := r.featureFlagScope(ctx, organizationID, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored models.FeatureFlagSetting
+	err = r.DB.GetContext(ctx, &stored, `INSERT INTO feature_flags (feature, organization_id, channel_id, enabled) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (feature, COALESCE(organization_id, 0), COALESCE(channel_id, 0)) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at, feature, organization_id, channel_id, enabled`, featureKey(feature), organization, channelID, enabled)
+	if models.IsForeignKeyViolation(err) {
+		return nil, apierror.New(apierror.CodeNotFound, "Organization not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("feature", feature.String()).Msg("Could not set feature flag")
+		return nil, errInternalServer
+	}
+	services.InvalidateFeatureFlags()
+
+	var channelName sql.NullString
+	if channel != nil {
+		channelName = sql.NullString{String: *channel, Valid: true}
+	}
+
+	return featureFlag(&stored, channelName), nil
+}
+
+// removeFeatureFlag removes a feature flag
+func (r *Resolver) removeFeatureFlag(ctx context.Context, feature models.Feature, organizationID *string, channel *string) error {
+	organization, channelID, err := r.featureFlagScope(ctx, organizationID, channel)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM feature_flags WHERE feature = $1 AND COALESCE(organization_id, 0) = COALESCE($2, 0) AND COALESCE(channel_id, 0) = COALESCE($3, 0)",
+		featureKey(feature), organization, channelID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("feature", feature.String()).Msg("Could not remove feature flag")
+		return errInternalServer
+	}
+	services.InvalidateFeatureFlags()
+
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return apierror.New(apierror.CodeNotFound, "Feature flag not found")
+	}
+
+	return nil
+}
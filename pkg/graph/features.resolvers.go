@@ -0,0 +1,43 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) SetFeatureFlag(ctx context.Context, feature models.Feature, enabled bool, organizationID *string, channel *string) (*models.FeatureFlag, error) {
+	r.log(ctx).Info().Str("mutation", "SetFeatureFlag").Str("feature", feature.String()).Bool("enabled", enabled).Interface("organizationId", organizationID).Interface("channel", channel).Msg("")
+
+	return r.setFeatureFlag(ctx, feature, enabled, organizationID, channel)
+}
+
+func (r *mutationResolver) RemoveFeatureFlag(ctx context.Context, feature models.Feature, organizationID *string, channel *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RemoveFeatureFlag").Str("feature", feature.String()).Interface("organizationId", organizationID).Interface("channel", channel).Msg("")
+
+	err := r.removeFeatureFlag(ctx, feature, organizationID, channel)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) FeatureFlags(ctx context.Context) ([]*models.FeatureFlag, error) {
+	r.log(ctx).Info().Str("query", "FeatureFlags").Msg("")
+
+	return r.featureFlags(ctx)
+}
@@ -0,0 +1,212 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+const (
+	maxFeedbackComment  = 2000
+	maxFeedbackComments = 100
+	// defaultFeedbackRange is how far back organization feedback is summarised when no range is given
+	defaultFeedbackRange = 30 * 24 * time.Hour
+)
+
+// submitFeedback stores the rating of a meeting and queues a feedback.submitted event for it in the same transaction.
+// Feedback is accepted after the meeting ended, since that is when it is usually given
+func (r *Resolver) submitFeedback(ctx context.Context, channelData *models.Channel, rating int, comment *string, uid *int) error {
+	if rating < 1 || rating > 5 {
+		return apierror.New(apierror.CodeBadRequest, "Rating must be between 1 and 5")
+	}
+
+	text := sql.NullString{}
+	if comment != nil && strings.TrimSpace(*comment) != "" {
+		text = sql.NullString{String: strings.TrimSpace(*comment), Valid: true}
+	}
+
+	if len(text.String) > maxFeedbackComment {
+		return apierror.New(apierror.CodeBadRequest, "Comment must be at most "+strconv.Itoa(maxFeedbackComment)+" characters")
+	}
+
+	participant := sql.NullInt64{}
+	if uid != nil {
+		if _, err := r.getParticipant(ctx, channelData.ID, *uid); err != nil {
+			return err
+		}
+		participant = sql.NullInt64{Int64: int64(*uid), Valid: true}
+	}
+
+	userID := sql.NullInt64{}
+	if user, err := middleware.GetUserFromContext(ctx); err == nil {
+		userID = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return errInternalServer
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO feedback (channel_id, uid, user_id, rating, comment) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (channel_id, uid) DO UPDATE SET user_id = EXCLUDED.user_id, rating = EXCLUDED.rating, comment = EXCLUDED.comment, created_at = CURRENT_TIMESTAMP`,
+		channelData.ID, participant, userID, rating, text)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not store feedback")
+		return errInternalServer
+	}
+
+	data := map[string]interface{}{"rating": rating}
+	if text.Valid {
+		data["comment"] = text.String
+	}
+	if uid != nil {
+		data["uid"] = *uid
+	}
+
+	err = services.QueueChannelEvent(ctx, tx, channelData.ChannelName, models.WebhookFeedbackSubmitted, data)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not queue feedback event")
+		return errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not commit feedback")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// feedbackSummary summarises the feedback matching a condition on the feedback and channels tables, whose arguments
+// are passed along with it
+func (r *Resolver) feedbackSummary(ctx context.Context, condition string, args ...interface{}) (*models.FeedbackSummary, error) {
+	from := " FROM feedback INNER JOIN channels ON channels.id = feedback.channel_id WHERE " + condition
+
+	ratings := []struct {
+		Rating int `db:"rating"`
+		Count  int `db:"count"`
+	}{}
+	err := r.DB.SelectContext(ctx, &ratings, "SELECT feedback.rating, COUNT(*) AS count"+from+" GROUP BY feedback.rating", args...)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not count ratings")
+		return nil, errInternalServer
+	}
+
+	summary := &models.FeedbackSummary{
+		Distribution: make([]int, 5),
+		Daily:        []*models.DailyRating{},
+		Comments:     []*models.FeedbackComment{},
+	}
+
+	total := 0
+	for _, rating := range ratings {
+		summary.Distribution[rating.Rating-1] = rating.Count
+		summary.Count += rating.Count
+		total += rating.Rating * rating.Count
+	}
+
+	if summary.Count == 0 {
+		return summary, nil
+	}
+
+	average := float64(total) / float64(summary.Count)
+	summary.AverageRating = &average
+
+	days := []struct {
+		Date          string  `db:"date"`
+		Count         int     `db:"count"`
+		AverageRating float64 `db:"average_rating"`
+	}{}
+	err = r.DB.SelectContext(ctx, &days, `SELECT TO_CHAR(feedback.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date,
+		COUNT(*) AS count, AVG(feedback.rating)::FLOAT AS average_rating`+from+" GROUP BY date ORDER BY date", args...)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not summarise daily ratings")
+		return nil, errInternalServer
+	}
+
+	for _, day := range days {
+		summary.Daily = append(summary.Daily, &models.DailyRating{
+			Date:          day.Date,
+			Count:         day.Count,
+			AverageRating: day.AverageRating,
+		})
+	}
+
+	comments := []struct {
+		Rating    int            `db:"rating"`
+		Comment   string         `db:"comment"`
+		Name      sql.NullString `db:"name"`
+		CreatedAt time.Time      `db:"created_at"`
+	}{}
+	err = r.DB.SelectContext(ctx, &comments, `SELECT feedback.rating, feedback.comment, feedback.created_at,
+		(SELECT participants.name FROM participants WHERE participants.channel_id = feedback.channel_id AND participants.uid = feedback.uid) AS name`+
+		from+" AND feedback.comment IS NOT NULL ORDER BY feedback.created_at DESC LIMIT "+strconv.Itoa(maxFeedbackComments), args...)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch feedback comments")
+		return nil, errInternalServer
+	}
+
+	for _, comment := range comments {
+		summary.Comments = append(summary.Comments, &models.FeedbackComment{
+			Rating:    comment.Rating,
+			Comment:   comment.Comment,
+			Name:      nullableString(comment.Name),
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	return summary, nil
+}
+
+// channelFeedback summarises the feedback of a channel
+func (r *Resolver) channelFeedback(ctx context.Context, channelData *models.Channel) (*models.FeedbackSummary, error) {
+	return r.feedbackSummary(ctx, "feedback.channel_id = $1", channelData.ID)
+}
+
+// organizationFeedback summarises the feedback of the channels of an organization submitted in a time range, for
+// owners and admins of the organization
+func (r *Resolver) organizationFeedback(ctx context.Context, user *models.UserAccount, organizationID string, since *time.Time, until *time.Time) (*models.FeedbackSummary, error) {
+	id, role, err := r.memberOf(ctx, r.DB, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manages(role) {
+		return nil, errNotOrganizationAdmin
+	}
+
+	end := time.Now()
+	if until != nil {
+		end = *until
+	}
+
+	start := end.Add(-defaultFeedbackRange)
+	if since != nil {
+		start = *since
+	}
+
+	if !start.Before(end) {
+		return nil, apierror.New(apierror.CodeBadRequest, "since must be before until")
+	}
+
+	return r.feedbackSummary(ctx, "channels.organization_id = $1 AND feedback.created_at >= $2 AND feedback.created_at < $3", id, start, end)
+}
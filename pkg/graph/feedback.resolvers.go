@@ -0,0 +1,66 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) SubmitFeedback(ctx context.Context, passphrase string, rating int, comment *string, uid *int) (string, error) {
+	r.log(ctx).Info().Str("mutation", "SubmitFeedback").Str("passphrase", passphrase).Int("rating", rating).Interface("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.submitFeedback(ctx, channelData, rating, comment, uid)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) ChannelFeedback(ctx context.Context, passphrase string) (*models.FeedbackSummary, error) {
+	r.log(ctx).Info().Str("query", "ChannelFeedback").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Msg("Unauthorized to view feedback")
+		return nil, errNotHost("view feedback")
+	}
+
+	return r.channelFeedback(ctx, channelData)
+}
+
+func (r *queryResolver) OrganizationFeedback(ctx context.Context, organizationID string, since *time.Time, until *time.Time) (*models.FeedbackSummary, error) {
+	r.log(ctx).Info().Str("query", "OrganizationFeedback").Str("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.organizationFeedback(ctx, authUser, organizationID, since, until)
+}
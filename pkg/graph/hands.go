@@ -0,0 +1,170 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// errHandsUnavailable is returned when raising hands is used without Redis being configured
+var errHandsUnavailable = apierror.New(apierror.CodeUnavailable, "Raising hands is not available")
+
+// handsTTL is how long the raised hands of a channel are kept after the last hand was raised
+const handsTTL = 24 * time.Hour
+
+// handsKey is the Redis sorted set of the uids with a raised hand in a channel, scored by when they raised it
+func handsKey(channelID int64) string {
+	return "hands:" + strconv.FormatInt(channelID, 10)
+}
+
+// handNamesKey is the Redis hash of the names of the participants with a raised hand in a channel
+func handNamesKey(channelID int64) string {
+	return "hands:" + strconv.FormatInt(channelID, 10) + ":names"
+}
+
+// handsTopic is the topic that receives the queue of raised hands of a channel whenever it changes
+func handsTopic(channelID int64) string {
+	return "hands:" + strconv.FormatInt(channelID, 10)
+}
+
+// raisedHands returns the queue of raised hands of a channel, in the order the hands were raised
+func (r *Resolver) raisedHands(ctx context.Context, channelID int64) ([]*models.RaisedHand, error) {
+	if r.Redis == nil {
+		return nil, errHandsUnavailable
+	}
+
+	entries, err := r.Redis.ZRangeWithScores(ctx, handsKey(channelID), 0, -1).Result()
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Msg("Could not fetch raised hands")
+		return nil, errInternalServer
+	}
+
+	hands := []*models.RaisedHand{}
+	if len(entries) == 0 {
+		return hands, nil
+	}
+
+	uids := make([]string, len(entries))
+	for i, entry := range entries {
+		uids[i], _ = entry.Member.(string)
+	}
+
+	names, err := r.Redis.HMGet(ctx, handNamesKey(channelID), uids...).Result()
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Msg("Could not fetch names of raised hands")
+		return nil, errInternalServer
+	}
+
+	for i, entry := range entries {
+		uid, err := strconv.Atoi(uids[i])
+		if err != nil {
+			continue
+		}
+
+		hand := &models.RaisedHand{
+			UID:      uid,
+			RaisedAt: time.Unix(0, int64(entry.Score)*int64(time.Millisecond)),
+		}
+
+		if name, ok := names[i].(string); ok && name != "" {
+			hand.Name = &name
+		}
+
+		hands = append(hands, hand)
+	}
+
+	return hands, nil
+}
+
+// publishHands notifies the participants of a channel about the current queue of raised hands
+func (r *Resolver) publishHands(ctx context.Context, channelID int64) {
+	hands, err := r.raisedHands(ctx, channelID)
+	if err != nil {
+		return
+	}
+
+	message, err := json.Marshal(hands)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not encode raised hands")
+		return
+	}
+
+	r.PubSub.Publish(handsTopic(channelID), message)
+}
+
+// raiseHand adds a participant to the queue of raised hands of a channel. Raising a hand that is already raised
+// keeps its place in the queue
+func (r *Resolver) raiseHand(ctx context.Context, channelData *models.Channel, uid int) error {
+	if r.Redis == nil {
+		return errHandsUnavailable
+	}
+
+	if channelData.EndedAt.Valid {
+		return errMeetingEnded
+	}
+
+	participant, err := r.getParticipant(ctx, channelData.ID, uid)
+	if err != nil {
+		return err
+	}
+
+	member := strconv.Itoa(participant.UID)
+	_, err = r.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAddNX(ctx, handsKey(channelData.ID), &redis.Z{
+			Score:  float64(time.Now().UnixNano() / int64(time.Millisecond)),
+			Member: member,
+		})
+		pipe.HSet(ctx, handNamesKey(channelData.ID), member, participant.Name.String)
+		pipe.Expire(ctx, handsKey(channelData.ID), handsTTL)
+		pipe.Expire(ctx, handNamesKey(channelData.ID), handsTTL)
+		return nil
+	})
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Int("uid", uid).Msg("Could not raise hand")
+		return errInternalServer
+	}
+
+	r.publishHands(ctx, channelData.ID)
+	return nil
+}
+
+// lowerHand removes a participant from the queue of raised hands of a channel
+func (r *Resolver) lowerHand(ctx context.Context, channelData *models.Channel, uid int) error {
+	if r.Redis == nil {
+		return errHandsUnavailable
+	}
+
+	// Hands are stored under the main uid, so a screen share uid is resolved to it when the participant is known
+	member := strconv.Itoa(uid)
+	if participant, err := r.getParticipant(ctx, channelData.ID, uid); err == nil {
+		member = strconv.Itoa(participant.UID)
+	}
+
+	_, err := r.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, handsKey(channelData.ID), member)
+		pipe.HDel(ctx, handNamesKey(channelData.ID), member)
+		return nil
+	})
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Int("uid", uid).Msg("Could not lower hand")
+		return errInternalServer
+	}
+
+	r.publishHands(ctx, channelData.ID)
+	return nil
+}
@@ -0,0 +1,336 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	htmltemplate "html/template"
+	"net/mail"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// Mediums invitations are sent with
+const (
+	inviteMediumEmail = "email"
+	inviteMediumSMS   = "sms"
+)
+
+// maxInviteRecipients is how many invitations can be sent at once
+const maxInviteRecipients = 50
+
+// maxInviteMessageLength is the longest personal message, in characters, an invitation can carry
+const maxInviteMessageLength = 1000
+
+// invitation is what invitations to a channel are rendered from
+type invitation struct {
+	Title    string
+	Inviter  string
+	Message  string
+	JoinURL  string
+	Passcode string
+	DialIn   string
+	PIN      string
+	StartsAt string
+}
+
+var inviteSubject = texttemplate.Must(texttemplate.New("subject").Parse(
+	`{{if .Inviter}}{{.Inviter}} invited you to {{.Title}}{{else}}Invitation to {{.Title}}{{end}}`))
+
+var inviteText = texttemplate.Must(texttemplate.New("text").Parse(`{{if .Inviter}}{{.Inviter}} invited you to {{.Title}}.{{else}}You are invited to {{.Title}}.{{end}}
+{{if .Message}}
+{{.Message}}
+{{end}}{{if .StartsAt}}
+Starts at {{.StartsAt}}
+{{end}}
+{{if .JoinURL}}Join: {{.JoinURL}}{{else}}Join with the passphrase {{.Passcode}}{{end}}
+{{if .DialIn}}
+Dial in: {{.DialIn}} PIN: {{.PIN}}
+{{end}}`))
+
+// inviteSMS is kept short so that invitations fit in few text messages
+var inviteSMS = texttemplate.Must(texttemplate.New("sms").Parse(
+	`{{if .Inviter}}{{.Inviter}} invited you to {{.Title}}{{else}}Join {{.Title}}{{end}}{{if .StartsAt}} on {{.StartsAt}}{{end}}: ` +
+		`{{if .JoinURL}}{{.JoinURL}}{{else}}passphrase {{.Passcode}}{{end}}{{if .DialIn}}. Dial in {{.DialIn}} PIN {{.PIN}}{{end}}`))
+
+var inviteHTML = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<p>{{if .Inviter}}{{.Inviter}} invited you to <strong>{{.Title}}</strong>.{{else}}You are invited to <strong>{{.Title}}</strong>.{{end}}</p>
+{{if .Message}}<p style="white-space: pre-wrap;">{{.Message}}</p>{{end}}
+{{if .StartsAt}}<p>Starts at {{.StartsAt}}</p>{{end}}
+{{if .JoinURL}}<p><a href="{{.JoinURL}}" style="display: inline-block; padding: 10px 20px; background: #099dfd; color: #fff; text-decoration: none; border-radius: 4px;">Join meeting</a></p>
+<p style="font-size: 12px; color: #666;">{{.JoinURL}}</p>{{else}}<p>Join with the passphrase <strong>{{.Passcode}}</strong></p>{{end}}
+{{if .DialIn}}<p>Dial in: {{.DialIn}}<br>PIN: {{.PIN}}</p>{{end}}
+</body>
+</html>`))
+
+// newInvitation describes a channel for its invitations
+func (r *Resolver) newInvitation(ctx context.Context, channelData *models.Channel, inviter *models.UserAccount, message string) *invitation {
+	result := &invitation{
+		Title:    channelData.Title,
+		Message:  message,
+		JoinURL:  joinURL(channelData.ViewerPassphrase),
+		Passcode: channelData.ViewerPassphrase,
+	}
+
+	if inviter != nil && inviter.UserName.Valid {
+		result.Inviter = inviter.UserName.String
+	}
+
+	if channelData.DTMF != "" {
+		pstn := r.pstnDetails(ctx, channelData.DTMF, nil)
+		result.DialIn = pstn.Number
+		result.PIN = pstn.Dtmf
+	}
+
+	if channelData.StartsAt.Valid {
+		result.StartsAt = channelData.StartsAt.Time.UTC().Format("Monday, January 2, 2006 15:04 MST")
+	}
+
+	return result
+}
+
+// checkInviteLimit refuses to send count more invitations when the inviter would exceed INVITE_DAILY_LIMIT over the
+// last day. Signed in hosts are limited across their channels, and other hosts per channel
+func (r *Resolver) checkInviteLimit(ctx context.Context, channelData *models.Channel, inviter *models.UserAccount, count int) error {
+	limit := viper.GetInt("INVITE_DAILY_LIMIT")
+	if limit <= 0 {
+		return nil
+	}
+
+	var sent int
+	var err error
+	if inviter != nil {
+		err = r.DB.GetContext(ctx, &sent, "SELECT COUNT(*) FROM invitations WHERE user_id = $1 AND created_at > NOW() - INTERVAL '1 day'", inviter.ID)
+	} else {
+		err = r.DB.GetContext(ctx, &sent, "SELECT COUNT(*) FROM invitations WHERE channel_id = $1 AND user_id IS NULL AND created_at > NOW() - INTERVAL '1 day'", channelData.ID)
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not count invitations")
+		return errInternalServer
+	}
+
+	if sent+count > limit {
+		r.log(ctx).Debug().Int64("Channel ID", channelData.ID).Int("sent", sent).Msg("Invitation limit reached")
+		return apierror.New(apierror.CodeRateLimited, "You can send "+strconv.Itoa(limit-sent)+" more invitations today")
+	}
+
+	return nil
+}
+
+// deliverInvites sends an invitation to each recipient with deliver and records it along with the ID of the message
+// deliver returns. Recipients that could not be reached are reported as not sent rather than failing the others
+func (r *Resolver) deliverInvites(ctx context.Context, channelData *models.Channel, inviter *models.UserAccount, medium string, recipients []string, deliver func(recipient string, invitationID int64) (string, error)) ([]*models.InviteResult, error) {
+	err := r.checkInviteLimit(ctx, channelData, inviter, len(recipients))
+	if err != nil {
+		return nil, err
+	}
+
+	var inviterID sql.NullInt64
+	if inviter != nil {
+		inviterID = sql.NullInt64{Int64: inviter.ID, Valid: true}
+	}
+
+	results := []*models.InviteResult{}
+	for _, recipient := range recipients {
+		// Invitations are recorded before they are sent, so that providers can report their delivery
+		var invitationID int64
+		err = r.DB.GetContext(ctx, &invitationID, "INSERT INTO invitations (channel_id, user_id, medium, recipient, status) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			channelData.ID, inviterID, medium, recipient, models.InvitationPending)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not record invitation")
+			return nil, errInternalServer
+		}
+
+		status := models.InvitationSent
+		messageID, err := deliver(recipient, invitationID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("medium", medium).Int64("invitation", invitationID).Msg("Could not send invitation")
+			status = models.InvitationFailed
+		}
+
+		// Delivery reports that arrived while the message was being sent are kept
+		_, err = r.DB.ExecContext(ctx, "UPDATE invitations SET status = CASE WHEN status = $1 OR $2 = $3 THEN $2 ELSE status END, message_id = $4, updated_at = NOW() WHERE id = $5",
+			models.InvitationPending, status, models.InvitationFailed, sql.NullString{String: messageID, Valid: messageID != ""}, invitationID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("invitation", invitationID).Msg("Could not update invitation")
+		}
+
+		results = append(results, &models.InviteResult{
+			ID:        strconv.FormatInt(invitationID, 10),
+			Recipient: recipient,
+			Sent:      status == models.InvitationSent,
+			Status:    status,
+		})
+	}
+
+	return results, nil
+}
+
+// parseEmails validates and normalizes email addresses, dropping duplicates
+func parseEmails(emails []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, email := range emails {
+		address, err := mail.ParseAddress(strings.TrimSpace(email))
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid email "+email)
+		}
+
+		normalized := normalizeEmail(address.Address)
+		if !seen[normalized] {
+			seen[normalized] = true
+			result = append(result, normalized)
+		}
+	}
+
+	return result, nil
+}
+
+// sendInvites emails invitations to a channel, with a calendar event attached when the channel is scheduled
+func (r *Resolver) sendInvites(ctx context.Context, channelData *models.Channel, emails []string, message *string) ([]*models.InviteResult, error) {
+	if r.Mailer == nil {
+		return nil, apierror.New(apierror.CodeUnavailable, "Email invitations are not enabled")
+	}
+
+	if len(emails) == 0 || len(emails) > maxInviteRecipients {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invitations can be sent to 1 to "+strconv.Itoa(maxInviteRecipients)+" recipients at once")
+	}
+
+	recipients, err := parseEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+
+	text := ""
+	if message != nil {
+		text = strings.TrimSpace(*message)
+		if utf8.RuneCountInString(text) > maxInviteMessageLength {
+			return nil, apierror.New(apierror.CodeBadRequest, "Message cannot be longer than "+strconv.Itoa(maxInviteMessageLength)+" characters")
+		}
+	}
+
+	inviter, _ := middleware.GetUserFromContext(ctx)
+	details := r.newInvitation(ctx, channelData, inviter, text)
+
+	var subject, plain, html bytes.Buffer
+	err = inviteSubject.Execute(&subject, details)
+	if err == nil {
+		err = inviteText.Execute(&plain, details)
+	}
+	if err == nil {
+		err = inviteHTML.Execute(&html, details)
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not render invitation")
+		return nil, errInternalServer
+	}
+
+	var attachments []utils.MailAttachment
+	if event := r.meetingEvent(ctx, channelData); event != nil {
+		attachments = append(attachments, utils.MailAttachment{
+			Filename:    "invite.ics",
+			ContentType: "text/calendar; charset=UTF-8; method=PUBLISH",
+			Content:     []byte(event.ICS(time.Now())),
+		})
+	}
+
+	return r.deliverInvites(ctx, channelData, inviter, inviteMediumEmail, recipients, func(recipient string, invitationID int64) (string, error) {
+		return "", r.Mailer.Send(&utils.MailMessage{
+			To:          recipient,
+			Subject:     subject.String(),
+			Text:        plain.String(),
+			HTML:        html.String(),
+			Attachments: attachments,
+		})
+	})
+}
+
+// sendSMSInvites texts invitations to a channel. The delivery of the messages is tracked when the SMS provider can
+// report it
+func (r *Resolver) sendSMSInvites(ctx context.Context, channelData *models.Channel, phoneNumbers []string) ([]*models.InviteResult, error) {
+	if r.SMS == nil {
+		return nil, apierror.New(apierror.CodeUnavailable, "Text message invitations are not enabled")
+	}
+
+	if len(phoneNumbers) == 0 || len(phoneNumbers) > maxInviteRecipients {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invitations can be sent to 1 to "+strconv.Itoa(maxInviteRecipients)+" recipients at once")
+	}
+
+	recipients := []string{}
+	seen := map[string]bool{}
+	for _, phoneNumber := range phoneNumbers {
+		number, ok := normalizePhoneNumber(phoneNumber)
+		if !ok {
+			return nil, apierror.New(apierror.CodeBadRequest, "Phone number "+phoneNumber+" must be in international format, e.g. +14155550100")
+		}
+
+		if !seen[number] {
+			seen[number] = true
+			recipients = append(recipients, number)
+		}
+	}
+
+	inviter, _ := middleware.GetUserFromContext(ctx)
+
+	var body bytes.Buffer
+	err := inviteSMS.Execute(&body, r.newInvitation(ctx, channelData, inviter, ""))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not render invitation")
+		return nil, errInternalServer
+	}
+
+	tracker, tracked := r.SMS.(utils.SMSTracker)
+	return r.deliverInvites(ctx, channelData, inviter, inviteMediumSMS, recipients, func(recipient string, invitationID int64) (string, error) {
+		if tracked {
+			return tracker.SendTrackedSMS(recipient, body.String(), services.InvitationStatusURL(invitationID))
+		}
+
+		return "", r.SMS.SendSMS(recipient, body.String())
+	})
+}
+
+// invitations lists the invitations sent to a channel
+func (r *Resolver) invitations(ctx context.Context, channelData *models.Channel) ([]*models.Invitation, error) {
+	stored := []models.ChannelInvitation{}
+	err := r.DB.SelectContext(ctx, &stored, "SELECT id, created_at, updated_at, channel_id, user_id, medium, recipient, status, message_id FROM invitations WHERE channel_id = $1 ORDER BY id DESC", channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not list invitations")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.Invitation, len(stored))
+	for index, invitation := range stored {
+		result[index] = &models.Invitation{
+			ID:        strconv.FormatInt(invitation.ID, 10),
+			Medium:    invitation.Medium,
+			Recipient: invitation.Recipient,
+			Status:    invitation.Status,
+			CreatedAt: invitation.CreatedAt,
+			UpdatedAt: invitation.UpdatedAt,
+		}
+	}
+
+	return result, nil
+}
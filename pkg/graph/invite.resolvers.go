@@ -0,0 +1,68 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) SendInvites(ctx context.Context, passphrase string, emails []string, message *string) ([]*models.InviteResult, error) {
+	r.log(ctx).Info().Str("mutation", "SendInvites").Str("passphrase", passphrase).Int("recipients", len(emails)).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to send invites")
+		return nil, errNotHost("send invites")
+	}
+
+	return r.sendInvites(ctx, channelData, emails, message)
+}
+
+func (r *mutationResolver) SendSmsInvite(ctx context.Context, passphrase string, phoneNumbers []string) ([]*models.InviteResult, error) {
+	r.log(ctx).Info().Str("mutation", "SendSmsInvite").Str("passphrase", passphrase).Int("recipients", len(phoneNumbers)).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to send invites")
+		return nil, errNotHost("send invites")
+	}
+
+	return r.sendSMSInvites(ctx, channelData, phoneNumbers)
+}
+
+func (r *queryResolver) Invitations(ctx context.Context, passphrase string) ([]*models.Invitation, error) {
+	r.log(ctx).Info().Str("query", "Invitations").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to list invitations")
+		return nil, errNotHost("list invitations")
+	}
+
+	return r.invitations(ctx, channelData)
+}
@@ -0,0 +1,106 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+const maxJobPage = 500
+
+// job describes a background job to admins
+func job(stored *models.BackgroundJob) *models.Job {
+	result := &models.Job{
+		ID:          strconv.FormatInt(stored.ID, 10),
+		Kind:        stored.Kind,
+		Status:      models.JobStatus(strings.ToUpper(stored.Status)),
+		Payload:     stored.Payload,
+		Attempts:    stored.Attempts,
+		MaxAttempts: stored.MaxAttempts,
+		RunAt:       stored.RunAt,
+		CreatedAt:   stored.CreatedAt,
+		LastError:   nullableString(stored.LastError),
+	}
+
+	if stored.StartedAt.Valid {
+		result.StartedAt = &stored.StartedAt.Time
+	}
+	if stored.FinishedAt.Valid {
+		result.FinishedAt = &stored.FinishedAt.Time
+	}
+
+	return result
+}
+
+// jobs lists background jobs from the newest, optionally only those with a status or of a kind
+func (r *Resolver) jobs(ctx context.Context, status *models.JobStatus, kind *string, before *string, limit int) ([]*models.Job, error) {
+	if limit <= 0 || limit > maxJobPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxJobPage))
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		id, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	statusFilter := sql.NullString{}
+	if status != nil {
+		statusFilter = sql.NullString{String: strings.ToLower(string(*status)), Valid: true}
+	}
+
+	stored := []models.BackgroundJob{}
+	err := r.DB.SelectContext(ctx, &stored, `SELECT id, created_at, kind, unique_key, payload, status, attempts, max_attempts, run_at, started_at, finished_at, last_error
+		FROM jobs WHERE ($1::TEXT IS NULL OR status = $1) AND ($2::TEXT IS NULL OR kind = $2) AND ($3::INT IS NULL OR id < $3)
+		ORDER BY id DESC LIMIT $4`, statusFilter, kind, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch jobs")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.Job, len(stored))
+	for index := range stored {
+		result[index] = job(&stored[index])
+	}
+
+	return result, nil
+}
+
+// retryJob queues a dead job again
+func (r *Resolver) retryJob(ctx context.Context, id string) (*models.Job, error) {
+	jobID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid job ID")
+	}
+
+	stored, err := services.RetryJob(ctx, r.DB, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apierror.New(apierror.CodeNotFound, "Dead job not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("job", jobID).Msg("Could not retry job")
+		return nil, errInternalServer
+	}
+
+	return job(stored), nil
+}
@@ -0,0 +1,37 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) RetryJob(ctx context.Context, id string) (*models.Job, error) {
+	r.log(ctx).Info().Str("mutation", "RetryJob").Str("job", id).Msg("")
+
+	return r.retryJob(ctx, id)
+}
+
+func (r *queryResolver) Jobs(ctx context.Context, status *models.JobStatus, kind *string, before *string, limit *int) ([]*models.Job, error) {
+	r.log(ctx).Info().Str("query", "Jobs").Interface("status", status).Interface("kind", kind).Msg("")
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.jobs(ctx, status, kind, before, pageSize)
+}
@@ -0,0 +1,94 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
+)
+
+// liveStreamColumns lists the columns of the live_streams table that are mapped onto models.ChannelLiveStream
+const liveStreamColumns = "id, created_at, channel_id, converter_id, rtmp_url, status, stopped_at"
+
+// isRTMPURL checks whether the given string is an absolute rtmp or rtmps URL
+func isRTMPURL(rawURL string) bool {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsedURL.Scheme == "rtmp" || parsedURL.Scheme == "rtmps") && parsedURL.Host != ""
+}
+
+// streamURL appends the stream key to the RTMP URL of a streaming platform
+func streamURL(rtmpURL string, streamKey string) string {
+	return strings.TrimSuffix(rtmpURL, "/") + "/" + strings.TrimPrefix(streamKey, "/")
+}
+
+// liveStream converts a stored live stream into its GraphQL representation
+func liveStream(stream models.ChannelLiveStream) *models.LiveStream {
+	result := &models.LiveStream{
+		ID:        stream.ConverterID,
+		RtmpURL:   stream.RtmpURL,
+		Status:    stream.Status,
+		CreatedAt: stream.CreatedAt,
+	}
+
+	if stream.StoppedAt.Valid {
+		stoppedAt := stream.StoppedAt.Time
+		result.StoppedAt = &stoppedAt
+	}
+
+	return result
+}
+
+// injectedStreamColumns lists the columns of the injected_streams table that are mapped onto models.ChannelInjectedStream
+const injectedStreamColumns = "id, created_at, channel_id, player_id, uid, stream_url, status, stopped_at"
+
+// injectedStream converts a stored injected stream into its GraphQL representation
+func injectedStream(stream models.ChannelInjectedStream) *models.InjectedStream {
+	result := &models.InjectedStream{
+		ID:        stream.PlayerID,
+		UID:       stream.UID,
+		URL:       stream.StreamURL,
+		Status:    stream.Status,
+		CreatedAt: stream.CreatedAt,
+	}
+
+	if stream.StoppedAt.Valid {
+		stoppedAt := stream.StoppedAt.Time
+		result.StoppedAt = &stoppedAt
+	}
+
+	return result
+}
+
+// startMediaPull plays a stream into a channel as a new publisher and returns the player along with its credentials
+func (r *Resolver) startMediaPull(project *utils.AgoraProject, channelData *models.Channel, streamURL string) (*utils.Player, *models.UserCredentials, error) {
+	user, err := r.Tokens.GenerateUserCredentials(project, channelData.ChannelName, rtctoken.RolePublisher, utils.TokenExpiry(channelData), false, false, utils.ChannelArea(channelData))
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not generate player credentials")
+		return nil, nil, errInternalServer
+	}
+
+	player, err := r.Agora.StartMediaPull(project, channelData.ChannelName, channelData.ChannelName+"_"+strconv.Itoa(user.UID), streamURL, user)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not inject stream")
+		return nil, nil, agoraError(err)
+	}
+
+	return player, user, nil
+}
@@ -0,0 +1,78 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/loaders"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// loaders returns the loaders of the request, or loaders of its own when the request did not pass through the loader
+// middleware
+func (r *Resolver) loaders(ctx context.Context) *loaders.Loaders {
+	if requestLoaders := loaders.For(ctx); requestLoaders != nil {
+		return requestLoaders
+	}
+
+	return loaders.New(ctx, r.Repos)
+}
+
+// userByID loads the user with an ID in a batch with the other users of the request. It returns nil when there is
+// no ID or the user was deleted
+func (r *Resolver) userByID(ctx context.Context, id *string) (*models.User, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	userID, err := strconv.ParseInt(*id, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	user, err := r.loaders(ctx).Users.Load(userID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not load user")
+		return nil, errInternalServer
+	}
+
+	if user == nil {
+		return nil, nil
+	}
+
+	return &models.User{
+		Name:     user.UserName.String,
+		Email:    user.Email,
+		Provider: nullableString(user.Provider),
+	}, nil
+}
+
+// adminChannelByName loads the channel with a channel name in a batch with the other channels of the request. It
+// returns nil when there is no channel name or the channel was deleted
+func (r *Resolver) adminChannelByName(ctx context.Context, channelName *string) (*models.AdminChannel, error) {
+	if channelName == nil {
+		return nil, nil
+	}
+
+	channel, err := r.loaders(ctx).Channels.Load(*channelName)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("channel", *channelName).Msg("Could not load channel")
+		return nil, errInternalServer
+	}
+
+	if channel == nil {
+		return nil, nil
+	}
+
+	return adminChannel(channel), nil
+}
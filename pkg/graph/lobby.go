@@ -0,0 +1,184 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// lobbyTopic is the topic notified when a host decides on a lobby entry
+func lobbyTopic(lobbyID string) string {
+	return "lobby:" + lobbyID
+}
+
+// lobbyUpdatesTopic is the topic that receives the lobby updates of a channel
+func lobbyUpdatesTopic(channelID int64) string {
+	return "lobby-updates:" + strconv.FormatInt(channelID, 10)
+}
+
+// lobbyUpdate converts a lobby entry into the update sent to hosts
+func lobbyUpdate(entry models.LobbyEntry) *models.LobbyUpdate {
+	var name *string
+	if entry.Name.Valid {
+		entryName := entry.Name.String
+		name = &entryName
+	}
+
+	return &models.LobbyUpdate{
+		ID:          entry.LobbyID,
+		Name:        name,
+		Status:      entry.Status,
+		RequestedAt: entry.CreatedAt,
+	}
+}
+
+// publishLobbyUpdate notifies the hosts of the channel and the waiting viewer about a change to a lobby entry
+func (r *Resolver) publishLobbyUpdate(ctx context.Context, entry models.LobbyEntry) {
+	message, err := json.Marshal(lobbyUpdate(entry))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not encode lobby update")
+		return
+	}
+
+	r.PubSub.Publish(lobbyUpdatesTopic(entry.ChannelID), message)
+	r.PubSub.Publish(lobbyTopic(entry.LobbyID), message)
+}
+
+// getLobbyEntry fetches a lobby entry of a channel
+func (r *Resolver) getLobbyEntry(ctx context.Context, channelID int64, lobbyID string) (*models.LobbyEntry, error) {
+	var entry models.LobbyEntry
+	err := r.DB.GetContext(ctx, &entry, "SELECT id, created_at, channel_id, lobby_id, name, status, mode FROM lobby WHERE channel_id = $1 AND lobby_id = $2", channelID, lobbyID)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Invalid lobby ID")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("lobbyId", lobbyID).Msg("Could not fetch lobby entry")
+		return nil, errInternalServer
+	}
+
+	return &entry, nil
+}
+
+// enterLobby places a viewer in the waiting room of the channel and returns a pending session
+func (r *Resolver) enterLobby(ctx context.Context, channelData *models.Channel, passphraseType models.PassphraseType, name *string, mode models.JoinMode) (*models.Session, error) {
+	lobbyID, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Lobby ID generation failed")
+		return nil, errInternalServer
+	}
+
+	entry := models.LobbyEntry{
+		ChannelID: channelData.ID,
+		LobbyID:   lobbyID,
+		Status:    models.LobbyStatusPending,
+		Mode:      mode,
+	}
+
+	if name != nil {
+		entry.Name = sql.NullString{String: utils.FirstN(*name, 100), Valid: true}
+	}
+
+	err = r.DB.GetContext(ctx, &entry.CreatedAt, "INSERT INTO lobby (channel_id, lobby_id, name, status, mode) VALUES ($1, $2, $3, $4, $5) RETURNING created_at", entry.ChannelID, entry.LobbyID, entry.Name, entry.Status, entry.Mode)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Adding viewer to lobby failed")
+		return nil, errInternalServer
+	}
+
+	r.publishLobbyUpdate(ctx, entry)
+
+	return &models.Session{
+		Title:      channelData.Title,
+		Channel:    channelData.ChannelName,
+		IsHost:     false,
+		Role:       passphraseType,
+		CanPublish: false,
+		Status:     models.SessionStatusPending,
+		LobbyID:    &lobbyID,
+		Mode:       mode,
+		Metadata:   r.channelMetadata(channelData),
+		Features:   r.channelFeatures(ctx, channelData),
+	}, nil
+}
+
+// lobbySession returns the session of a viewer once a host has decided on their lobby entry. Admitted viewers
+// receive their credentials, while denied viewers only learn that they were denied
+func (r *Resolver) lobbySession(ctx context.Context, channelData *models.Channel, passphraseType models.PassphraseType, entry *models.LobbyEntry) (*models.Session, error) {
+	if entry.Status == models.LobbyStatusAdmitted && !channelData.EndedAt.Valid {
+		session, err := r.newSession(ctx, channelData, passphraseType, entry.Mode)
+		if err != nil {
+			return nil, err
+		}
+		session.LobbyID = &entry.LobbyID
+
+		var name *string
+		if entry.Name.Valid {
+			name = &entry.Name.String
+		}
+		r.recordParticipant(ctx, channelData.ID, session, name, nil)
+		return session, nil
+	}
+
+	return &models.Session{
+		Title:    channelData.Title,
+		Channel:  channelData.ChannelName,
+		Role:     passphraseType,
+		Status:   models.SessionStatusDenied,
+		LobbyID:  &entry.LobbyID,
+		Mode:     entry.Mode,
+		Metadata: r.channelMetadata(channelData),
+		Features: r.channelFeatures(ctx, channelData),
+	}, nil
+}
+
+// decideLobbyEntry admits or denies a viewer waiting in the lobby on behalf of a host
+func (r *Resolver) decideLobbyEntry(ctx context.Context, passphrase string, lobbyID string, status models.LobbyStatus) (string, error) {
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to manage lobby")
+		return "", errNotHost("manage lobby")
+	}
+
+	entry, err := r.getLobbyEntry(ctx, channelData.ID, lobbyID)
+	if err != nil {
+		return "", err
+	}
+
+	if entry.Status != models.LobbyStatusPending {
+		return "", apierror.New(apierror.CodeBadRequest, "Participant is no longer waiting")
+	}
+
+	result, err := r.DB.ExecContext(ctx, "UPDATE lobby SET status = $1 WHERE id = $2 AND status = $3", status, entry.ID, models.LobbyStatusPending)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("lobbyId", lobbyID).Msg("Updating lobby entry failed")
+		return "", errInternalServer
+	}
+
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return "", apierror.New(apierror.CodeBadRequest, "Participant is no longer waiting")
+	}
+
+	entry.Status = status
+	r.publishLobbyUpdate(ctx, *entry)
+
+	return "success", nil
+}
@@ -0,0 +1,143 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// maxMessageLength is the longest chat message that can be sent, in characters
+const maxMessageLength = 4000
+
+// maxMessagePage is the largest number of chat messages returned at once
+const maxMessagePage = 200
+
+// messagesTopic is the topic that receives the chat messages of a channel
+func messagesTopic(channelID int64) string {
+	return "messages:" + strconv.FormatInt(channelID, 10)
+}
+
+// chatMessage converts a stored message into the message sent to clients
+func chatMessage(message models.ChannelMessage) *models.ChatMessage {
+	var name *string
+	if message.Name.Valid {
+		senderName := message.Name.String
+		name = &senderName
+	}
+
+	return &models.ChatMessage{
+		ID:     strconv.FormatInt(message.ID, 10),
+		UID:    message.UID,
+		Name:   name,
+		Text:   message.Body,
+		SentAt: message.CreatedAt,
+	}
+}
+
+// sendMessage stores a chat message sent by a participant of the channel and notifies the other participants.
+// The sender is looked up by the uid they joined with so that the name shown cannot be picked freely
+func (r *Resolver) sendMessage(ctx context.Context, channelData *models.Channel, uid int, text string) (*models.ChatMessage, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Message cannot be empty")
+	}
+
+	if len([]rune(text)) > maxMessageLength {
+		return nil, apierror.New(apierror.CodeBadRequest, "Message is too long")
+	}
+
+	participant, err := r.getParticipant(ctx, channelData.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	banned, err := r.isBanned(ctx, channelData.ID, participant.UID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if banned {
+		return nil, errBanned
+	}
+
+	message := models.ChannelMessage{
+		ChannelID: channelData.ID,
+		UID:       participant.UID,
+		Name:      participant.Name,
+		UserID:    participant.UserID,
+		Body:      text,
+	}
+
+	err = r.DB.QueryRowxContext(ctx, "INSERT INTO channel_messages (channel_id, uid, name, user_id, body) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		message.ChannelID, message.UID, message.Name, message.UserID, message.Body).Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not store message")
+		return nil, errInternalServer
+	}
+
+	result := chatMessage(message)
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not encode message")
+		return result, nil
+	}
+
+	r.PubSub.Publish(messagesTopic(channelData.ID), encoded)
+	return result, nil
+}
+
+// channelMessages returns a page of the chat history of a channel in the order the messages were sent. Pages are
+// fetched backwards from the newest message, using the ID of the oldest message of a page as the next cursor
+func (r *Resolver) channelMessages(ctx context.Context, channelData *models.Channel, before *string, limit int) (*models.ChatMessagePage, error) {
+	if limit <= 0 || limit > maxMessagePage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxMessagePage))
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		id, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	// One extra message is fetched to find out whether there are older pages
+	messages := []models.ChannelMessage{}
+	err := r.DB.SelectContext(ctx, &messages, `SELECT id, created_at, channel_id, uid, name, user_id, body FROM channel_messages
+		WHERE channel_id = $1 AND ($2::INT IS NULL OR id < $2) ORDER BY id DESC LIMIT $3`, channelData.ID, cursor, limit+1)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch messages")
+		return nil, errInternalServer
+	}
+
+	page := &models.ChatMessagePage{
+		Messages: []*models.ChatMessage{},
+		HasMore:  len(messages) > limit,
+	}
+
+	if page.HasMore {
+		messages = messages[:limit]
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		page.Messages = append(page.Messages, chatMessage(messages[i]))
+	}
+
+	return page, nil
+}
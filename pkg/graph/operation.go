@@ -0,0 +1,108 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+// persistedOperation describes a registered operation to admins
+func persistedOperation(operation *models.StoredOperation) *models.PersistedOperation {
+	return &models.PersistedOperation{
+		ID:        strconv.FormatInt(operation.ID, 10),
+		Hash:      operation.Hash,
+		Name:      nullableString(operation.Name),
+		Document:  operation.Document,
+		CreatedAt: operation.CreatedAt,
+		CreatedBy: nullableID(operation.CreatedBy),
+	}
+}
+
+// registerOperations registers operation documents, none of them when one is invalid
+func (r *Resolver) registerOperations(ctx context.Context, documents []string) ([]*models.PersistedOperation, error) {
+	createdBy := sql.NullInt64{}
+	if user, err := middleware.GetUserFromContext(ctx); err == nil {
+		createdBy = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	schema := generated.NewExecutableSchema(generated.Config{}).Schema()
+	result := make([]*models.PersistedOperation, len(documents))
+	for index, document := range documents {
+		operation, err := services.RegisterOperation(ctx, tx, schema, document, createdBy)
+		if errors.Is(err, services.ErrInvalidOperation) {
+			return nil, apierror.New(apierror.CodeBadRequest, "Document "+strconv.Itoa(index+1)+": "+err.Error())
+		}
+
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not register operation")
+			return nil, errInternalServer
+		}
+
+		result[index] = persistedOperation(operation)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not commit operations")
+		return nil, errInternalServer
+	}
+
+	return result, nil
+}
+
+// unregisterOperation unregisters the operation with a hash, which can no longer be executed while the allowlist is
+// enabled
+func (r *Resolver) unregisterOperation(ctx context.Context, hash string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM persisted_operations WHERE hash = $1", hash)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("hash", hash).Msg("Could not unregister operation")
+		return errInternalServer
+	}
+
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return apierror.New(apierror.CodeNotFound, "Operation not found")
+	}
+
+	return nil
+}
+
+// persistedOperations lists the registered operations by name
+func (r *Resolver) persistedOperations(ctx context.Context) ([]*models.PersistedOperation, error) {
+	stored := []models.StoredOperation{}
+	err := r.DB.SelectContext(ctx, &stored, "SELECT id, created_at, hash, name, document, created_by FROM persisted_operations ORDER BY name, id")
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch persisted operations")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.PersistedOperation, len(stored))
+	for index := range stored {
+		result[index] = persistedOperation(&stored[index])
+	}
+
+	return result, nil
+}
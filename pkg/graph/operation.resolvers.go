@@ -0,0 +1,43 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) RegisterOperations(ctx context.Context, documents []string) ([]*models.PersistedOperation, error) {
+	r.log(ctx).Info().Str("mutation", "RegisterOperations").Int("documents", len(documents)).Msg("")
+
+	return r.registerOperations(ctx, documents)
+}
+
+func (r *mutationResolver) UnregisterOperation(ctx context.Context, hash string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "UnregisterOperation").Str("hash", hash).Msg("")
+
+	err := r.unregisterOperation(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) PersistedOperations(ctx context.Context) ([]*models.PersistedOperation, error) {
+	r.log(ctx).Info().Str("query", "PersistedOperations").Msg("")
+
+	return r.persistedOperations(ctx)
+}
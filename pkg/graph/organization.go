@@ -0,0 +1,615 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+const maxOrganizationChannelPage = 500
+
+// errNotOrganizationMember is returned when a user uses an organization it is not a member of
+var errNotOrganizationMember = apierror.New(apierror.CodeForbidden, "You are not a member of this organization")
+
+// errNotOrganizationAdmin is returned when a member manages an organization without the role to do so
+var errNotOrganizationAdmin = apierror.New(apierror.CodeForbidden, "Unauthorised to manage this organization")
+
+// errLastOrganizationOwner is returned when a change would leave an organization without an owner
+var errLastOrganizationOwner = apierror.New(apierror.CodeBadRequest, "Organizations need at least one owner")
+
+// manages reports whether a role can manage the members and channels of an organization
+func manages(role models.OrganizationRole) bool {
+	return role == models.OrganizationRoleOwner || role == models.OrganizationRoleAdmin
+}
+
+func organizationMember(member models.OrganizationMembership) *models.OrganizationMember {
+	result := &models.OrganizationMember{
+		UserID:   strconv.FormatInt(member.UserID, 10),
+		Role:     models.OrganizationRole(member.Role),
+		JoinedAt: member.CreatedAt,
+	}
+
+	if member.UserName.Valid {
+		result.Name = &member.UserName.String
+	}
+
+	if member.Email.Valid {
+		result.Email = &member.Email.String
+	}
+
+	return result
+}
+
+// organizationRole returns the role of a user in an organization, which is empty when the user is not a member
+func (r *Resolver) organizationRole(ctx context.Context, db sqlx.QueryerContext, organizationID int64, userID int64) (models.OrganizationRole, error) {
+	var role models.OrganizationRole
+	err := sqlx.GetContext(ctx, db, &role, "SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2", organizationID, userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", organizationID).Int64("user", userID).Msg("Could not fetch organization role")
+		return "", errInternalServer
+	}
+
+	return role, nil
+}
+
+// memberOf parses the ID of an organization and returns the role the user has in it. Users that are not members
+// get errNotOrganizationMember, so that organizations cannot be discovered by guessing their IDs
+func (r *Resolver) memberOf(ctx context.Context, db sqlx.QueryerContext, user *models.UserAccount, organizationID string) (int64, models.OrganizationRole, error) {
+	id, err := strconv.ParseInt(organizationID, 10, 64)
+	if err != nil {
+		return 0, "", apierror.New(apierror.CodeBadRequest, "Invalid organization ID")
+	}
+
+	role, err := r.organizationRole(ctx, db, id, user.ID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if role == "" {
+		r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Msg("Not a member of organization")
+		return 0, "", errNotOrganizationMember
+	}
+
+	return id, role, nil
+}
+
+// controlsChannel reports whether a user controls a channel as its owner or as an owner or admin of the organization
+// the channel belongs to
+func (r *Resolver) controlsChannel(ctx context.Context, user *models.UserAccount, channel *models.Channel) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+
+	if channel.OwnerID.Valid && channel.OwnerID.Int64 == user.ID {
+		return true, nil
+	}
+
+	if !channel.OrganizationID.Valid {
+		return false, nil
+	}
+
+	role, err := r.organizationRole(ctx, r.DB, channel.OrganizationID.Int64, user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return manages(role), nil
+}
+
+// createOrganization creates an organization with the user as its owner
+func (r *Resolver) createOrganization(ctx context.Context, user *models.UserAccount, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Name cannot be empty")
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	var organization models.OrganizationAccount
+	err = tx.GetContext(ctx, &organization, "INSERT INTO organizations (name) VALUES ($1) RETURNING id, created_at, name", name)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not create organization")
+		return nil, errInternalServer
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)", organization.ID, user.ID, models.OrganizationRoleOwner)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", organization.ID).Int64("user", user.ID).Msg("Could not add organization owner")
+		return nil, errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", organization.ID).Msg("Could not commit organization")
+		return nil, errInternalServer
+	}
+
+	return &models.Organization{
+		ID:        strconv.FormatInt(organization.ID, 10),
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+		Role:      models.OrganizationRoleOwner,
+	}, nil
+}
+
+// organizations lists the organizations the user is a member of, or only the given organization when it is set
+func (r *Resolver) organizations(ctx context.Context, user *models.UserAccount, organizationID sql.NullInt64) ([]*models.Organization, error) {
+	var organizations []struct {
+		models.OrganizationAccount
+		Role          string         `db:"role"`
+		AppID         sql.NullString `db:"app_id"`
+		CustomStorage bool           `db:"custom_storage"`
+	}
+	err := r.DB.SelectContext(ctx, &organizations, `SELECT organizations.id, organizations.created_at, organizations.name, organization_members.role,
+		organization_credentials.app_id, organization_storage.organization_id IS NOT NULL AS custom_storage
+		FROM organizations INNER JOIN organization_members ON organization_members.organization_id = organizations.id
+		LEFT JOIN organization_credentials ON organization_credentials.organization_id = organizations.id
+		LEFT JOIN organization_storage ON organization_storage.organization_id = organizations.id
+		WHERE organization_members.user_id = $1 AND ($2::INT IS NULL OR organizations.id = $2) ORDER BY organizations.id`, user.ID, organizationID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not list organizations")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.Organization, len(organizations))
+	for index, organization := range organizations {
+		result[index] = &models.Organization{
+			ID:            strconv.FormatInt(organization.ID, 10),
+			Name:          organization.Name,
+			CreatedAt:     organization.CreatedAt,
+			Role:          models.OrganizationRole(organization.Role),
+			CustomStorage: organization.CustomStorage,
+		}
+
+		if organization.AppID.Valid {
+			result[index].AppID = &organization.AppID.String
+		}
+	}
+
+	return result, nil
+}
+
+// organization returns an organization the user is a member of
+func (r *Resolver) organization(ctx context.Context, user *models.UserAccount, organizationID int64) (*models.Organization, error) {
+	organizations, err := r.organizations(ctx, user, sql.NullInt64{Int64: organizationID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(organizations) == 0 {
+		return nil, errNotOrganizationMember
+	}
+
+	return organizations[0], nil
+}
+
+// organizationMembers lists the members of an organization the user is a member of
+func (r *Resolver) organizationMembers(ctx context.Context, user *models.UserAccount, organizationID string) ([]*models.OrganizationMember, error) {
+	id, _, err := r.memberOf(ctx, r.DB, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []models.OrganizationMembership{}
+	err = r.DB.SelectContext(ctx, &members, `SELECT organization_members.id, organization_members.created_at, organization_members.organization_id,
+		organization_members.user_id, organization_members.role, users.user_name, users.email
+		FROM organization_members INNER JOIN users ON users.id = organization_members.user_id
+		WHERE organization_members.organization_id = $1 ORDER BY organization_members.id`, id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not list organization members")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.OrganizationMember, len(members))
+	for index, member := range members {
+		result[index] = organizationMember(member)
+	}
+
+	return result, nil
+}
+
+// fetchOrganizationMember returns a member of an organization along with its name and email
+func (r *Resolver) fetchOrganizationMember(ctx context.Context, db sqlx.QueryerContext, organizationID int64, userID int64) (*models.OrganizationMember, error) {
+	var member models.OrganizationMembership
+	err := sqlx.GetContext(ctx, db, &member, `SELECT organization_members.id, organization_members.created_at, organization_members.organization_id,
+		organization_members.user_id, organization_members.role, users.user_name, users.email
+		FROM organization_members INNER JOIN users ON users.id = organization_members.user_id
+		WHERE organization_members.organization_id = $1 AND organization_members.user_id = $2`, organizationID, userID)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Member not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", organizationID).Int64("user", userID).Msg("Could not fetch organization member")
+		return nil, errInternalServer
+	}
+
+	return organizationMember(member), nil
+}
+
+// addOrganizationMember adds a user to an organization. Owners and admins add members, and only owners add owners
+func (r *Resolver) addOrganizationMember(ctx context.Context, user *models.UserAccount, organizationID string, userIdentifier string, role models.OrganizationRole) (*models.OrganizationMember, error) {
+	id, callerRole, err := r.memberOf(ctx, r.DB, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manages(callerRole) || role == models.OrganizationRoleOwner && callerRole != models.OrganizationRoleOwner {
+		r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Str("role", role.String()).Msg("Unauthorised to add organization member")
+		return nil, errNotOrganizationAdmin
+	}
+
+	member, err := r.Repos.Users.ByIdentifierOrEmail(ctx, userIdentifier)
+	if err == sql.ErrNoRows {
+		r.log(ctx).Debug().Str("userIdentifier", userIdentifier).Msg("New member not found")
+		return nil, apierror.New(apierror.CodeNotFound, "User not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("userIdentifier", userIdentifier).Msg("Could not fetch new member")
+		return nil, errInternalServer
+	}
+
+	_, err = r.DB.ExecContext(ctx, "INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)", id, member.ID, role)
+	if models.IsUniqueViolation(err) {
+		return nil, apierror.New(apierror.CodeBadRequest, "User is already a member")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Int64("user", member.ID).Msg("Could not add organization member")
+		return nil, errInternalServer
+	}
+
+	return r.fetchOrganizationMember(ctx, r.DB, id, member.ID)
+}
+
+// changeOrganizationMember runs a change to a member of an organization while the organization is locked, and
+// rejects the change when it would leave the organization without an owner. Admins cannot change owners
+func (r *Resolver) changeOrganizationMember(ctx context.Context, user *models.UserAccount, organizationID string, memberID string, removesOwner bool, change func(tx *sqlx.Tx, organizationID int64, callerRole models.OrganizationRole, memberID int64) error) (int64, int64, error) {
+	member, err := strconv.ParseInt(memberID, 10, 64)
+	if err != nil {
+		return 0, 0, apierror.New(apierror.CodeBadRequest, "Invalid user ID")
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return 0, 0, errInternalServer
+	}
+	defer tx.Rollback()
+
+	id, callerRole, err := r.memberOf(ctx, tx, user, organizationID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, "SELECT id FROM organizations WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not lock organization")
+		return 0, 0, errInternalServer
+	}
+
+	memberRole, err := r.organizationRole(ctx, tx, id, member)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if memberRole == "" {
+		return 0, 0, apierror.New(apierror.CodeNotFound, "Member not found")
+	}
+
+	if memberRole == models.OrganizationRoleOwner && callerRole != models.OrganizationRoleOwner {
+		r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Int64("member", member).Msg("Unauthorised to change organization owner")
+		return 0, 0, errNotOrganizationAdmin
+	}
+
+	if memberRole == models.OrganizationRoleOwner && removesOwner {
+		var owners int
+		err = tx.GetContext(ctx, &owners, "SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2", id, models.OrganizationRoleOwner)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not count organization owners")
+			return 0, 0, errInternalServer
+		}
+
+		if owners <= 1 {
+			return 0, 0, errLastOrganizationOwner
+		}
+	}
+
+	err = change(tx, id, callerRole, member)
+	if err != nil {
+		if apierror.CodeOf(err) == "" {
+			r.log(ctx).Error().Err(err).Int64("Organization ID", id).Int64("member", member).Msg("Could not change organization member")
+			return 0, 0, errInternalServer
+		}
+		return 0, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not commit organization member change")
+		return 0, 0, errInternalServer
+	}
+
+	return id, member, nil
+}
+
+// setOrganizationMemberRole changes the role of a member. Only owners grant or take away the owner role
+func (r *Resolver) setOrganizationMemberRole(ctx context.Context, user *models.UserAccount, organizationID string, memberID string, role models.OrganizationRole) (*models.OrganizationMember, error) {
+	id, member, err := r.changeOrganizationMember(ctx, user, organizationID, memberID, role != models.OrganizationRoleOwner, func(tx *sqlx.Tx, id int64, callerRole models.OrganizationRole, member int64) error {
+		if !manages(callerRole) || role == models.OrganizationRoleOwner && callerRole != models.OrganizationRoleOwner {
+			r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Str("role", role.String()).Msg("Unauthorised to set organization role")
+			return errNotOrganizationAdmin
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE organization_members SET role = $1 WHERE organization_id = $2 AND user_id = $3", role, id, member)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.fetchOrganizationMember(ctx, r.DB, id, member)
+}
+
+// removeOrganizationMember removes a member from an organization. Owners and admins remove members and every
+// member can leave, as long as the organization keeps an owner. The channels of the member stay in the organization
+func (r *Resolver) removeOrganizationMember(ctx context.Context, user *models.UserAccount, organizationID string, memberID string) error {
+	_, _, err := r.changeOrganizationMember(ctx, user, organizationID, memberID, true, func(tx *sqlx.Tx, id int64, callerRole models.OrganizationRole, member int64) error {
+		if member != user.ID && !manages(callerRole) {
+			r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Int64("member", member).Msg("Unauthorised to remove organization member")
+			return errNotOrganizationAdmin
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2", id, member)
+		return err
+	})
+
+	return err
+}
+
+// setChannelOrganization moves a channel into an organization the user is a member of, or out of its organization
+// when organizationID is nil. Only users that control the channel can move it
+func (r *Resolver) setChannelOrganization(ctx context.Context, user *models.UserAccount, passphrase string, organizationID *string) error {
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return err
+	}
+
+	controls, err := r.controlsChannel(ctx, user, channelData)
+	if err != nil {
+		return err
+	}
+
+	if passphraseType != models.PassphraseTypeHost && !controls || channelData.OwnerID.Valid && !controls {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Int64("user", user.ID).Msg("Unauthorised to move channel")
+		return errNotHost("move channel")
+	}
+
+	// A running recording has to be stopped with the project it was started with
+	if channelData.RecordingSID.Valid {
+		return apierror.New(apierror.CodeBadRequest, "Stop the recording before moving the channel")
+	}
+
+	organization := sql.NullInt64{}
+	if organizationID != nil {
+		id, _, err := r.memberOf(ctx, r.DB, user, *organizationID)
+		if err != nil {
+			return err
+		}
+		organization = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	err = r.Repos.Channels.SetOrganization(ctx, channelData.ID, organization)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not move channel")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// organizationChannels lists the channels of an organization, most recently created first. Host passphrases are
+// only returned to owners and admins
+func (r *Resolver) organizationChannels(ctx context.Context, user *models.UserAccount, organizationID string, before *string, limit int) ([]*models.OrganizationChannel, error) {
+	if limit <= 0 || limit > maxOrganizationChannelPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxOrganizationChannelPage))
+	}
+
+	id, role, err := r.memberOf(ctx, r.DB, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		channelID, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: channelID, Valid: true}
+	}
+
+	channels, err := r.Repos.Channels.ListByOrganization(ctx, id, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not list organization channels")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.OrganizationChannel, len(channels))
+	for index := range channels {
+		channel := &channels[index]
+		result[index] = &models.OrganizationChannel{
+			ID:               strconv.FormatInt(channel.ID, 10),
+			Title:            channel.Title,
+			CreatedAt:        channel.CreatedAt.Time,
+			OwnerID:          nullableID(channel.OwnerID),
+			ViewerPassphrase: channel.ViewerPassphrase,
+		}
+
+		if manages(role) {
+			result[index].HostPassphrase = &channel.HostPassphrase
+		}
+
+		if channel.EndedAt.Valid {
+			result[index].EndedAt = &channel.EndedAt.Time
+		}
+	}
+
+	return result, nil
+}
+
+// ownerOf parses the ID of an organization and checks that the user is one of its owners
+func (r *Resolver) ownerOf(ctx context.Context, user *models.UserAccount, organizationID string) (int64, error) {
+	id, role, err := r.memberOf(ctx, r.DB, user, organizationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if role != models.OrganizationRoleOwner {
+		r.log(ctx).Debug().Int64("Organization ID", id).Int64("user", user.ID).Msg("Unauthorised to change organization settings")
+		return 0, errNotOrganizationAdmin
+	}
+
+	return id, nil
+}
+
+// checkNoOrganizationRecordings refuses to change the project of an organization while one of its channels is being
+// recorded, since a running recording has to be stopped with the project it was started with
+func (r *Resolver) checkNoOrganizationRecordings(ctx context.Context, organizationID int64) error {
+	recording, err := r.Repos.Channels.OrganizationRecording(ctx, organizationID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", organizationID).Msg("Could not check organization recordings")
+		return errInternalServer
+	}
+
+	if recording {
+		return apierror.New(apierror.CodeBadRequest, "Stop the recordings of the organization before changing its project")
+	}
+
+	return nil
+}
+
+// setOrganizationProject makes the channels of an organization use its own Agora project. The customer credentials
+// are checked with the Agora RESTful API before they are stored
+func (r *Resolver) setOrganizationProject(ctx context.Context, user *models.UserAccount, organizationID string, input models.AgoraProjectInput) (*models.Organization, error) {
+	id, err := r.ownerOf(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	project := utils.AgoraProject{
+		AppID:               strings.TrimSpace(input.AppID),
+		AppCertificate:      strings.TrimSpace(input.AppCertificate),
+		CustomerID:          strings.TrimSpace(input.CustomerID),
+		CustomerCertificate: strings.TrimSpace(input.CustomerCertificate),
+	}
+	if project.AppID == "" || project.AppCertificate == "" || project.CustomerID == "" || project.CustomerCertificate == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid Agora project")
+	}
+
+	err = r.Agora.CheckAgoraCredentials(ctx, &project)
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Int64("Organization ID", id).Msg("Agora rejected organization credentials")
+		return nil, apierror.New(apierror.CodeBadRequest, "Agora rejected the customer credentials")
+	}
+
+	err = r.checkNoOrganizationRecordings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = services.SaveOrganizationProject(ctx, r.DB, id, project)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not store organization project")
+		return nil, errInternalServer
+	}
+
+	return r.organization(ctx, user, id)
+}
+
+// removeOrganizationProject makes the channels of an organization use the Agora project of the deployment again
+func (r *Resolver) removeOrganizationProject(ctx context.Context, user *models.UserAccount, organizationID string) (*models.Organization, error) {
+	id, err := r.ownerOf(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.checkNoOrganizationRecordings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM organization_credentials WHERE organization_id = $1", id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not remove organization project")
+		return nil, errInternalServer
+	}
+
+	return r.organization(ctx, user, id)
+}
+
+// setOrganizationStorage stores the bucket that recordings of the channels of an organization are uploaded to
+func (r *Resolver) setOrganizationStorage(ctx context.Context, user *models.UserAccount, organizationID string, storage *models.ChannelStorageInput) (*models.Organization, error) {
+	id, err := r.ownerOf(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := storageSettings(storage)
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Str("provider", storage.Provider.String()).Str("bucket", storage.Bucket).Msg("Invalid organization storage")
+		return nil, err
+	}
+
+	err = services.SaveOrganizationStorage(ctx, r.DB, id, *settings)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not store organization storage")
+		return nil, errInternalServer
+	}
+
+	return r.organization(ctx, user, id)
+}
+
+// removeOrganizationStorage makes recordings of the channels of an organization use the storage of the deployment
+// again. Recordings that were already uploaded stay in the bucket of the organization
+func (r *Resolver) removeOrganizationStorage(ctx context.Context, user *models.UserAccount, organizationID string) (*models.Organization, error) {
+	id, err := r.ownerOf(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM organization_storage WHERE organization_id = $1", id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not remove organization storage")
+		return nil, errInternalServer
+	}
+
+	return r.organization(ctx, user, id)
+}
@@ -0,0 +1,198 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) CreateOrganization(ctx context.Context, name string) (*models.Organization, error) {
+	r.log(ctx).Info().Str("mutation", "CreateOrganization").Str("name", name).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.createOrganization(ctx, authUser, name)
+}
+
+func (r *mutationResolver) AddOrganizationMember(ctx context.Context, organizationID string, userIdentifier string, role *models.OrganizationRole) (*models.OrganizationMember, error) {
+	r.log(ctx).Info().Str("mutation", "AddOrganizationMember").Str("organizationId", organizationID).Str("userIdentifier", userIdentifier).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	memberRole := models.OrganizationRoleMember
+	if role != nil {
+		memberRole = *role
+	}
+
+	return r.addOrganizationMember(ctx, authUser, organizationID, userIdentifier, memberRole)
+}
+
+func (r *mutationResolver) SetOrganizationMemberRole(ctx context.Context, organizationID string, userID string, role models.OrganizationRole) (*models.OrganizationMember, error) {
+	r.log(ctx).Info().Str("mutation", "SetOrganizationMemberRole").Str("organizationId", organizationID).Str("userId", userID).Str("role", role.String()).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.setOrganizationMemberRole(ctx, authUser, organizationID, userID, role)
+}
+
+func (r *mutationResolver) RemoveOrganizationMember(ctx context.Context, organizationID string, userID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RemoveOrganizationMember").Str("organizationId", organizationID).Str("userId", userID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	err = r.removeOrganizationMember(ctx, authUser, organizationID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SetChannelOrganization(ctx context.Context, passphrase string, organizationID *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "SetChannelOrganization").Str("passphrase", passphrase).Interface("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	err = r.setChannelOrganization(ctx, authUser, passphrase, organizationID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SetOrganizationProject(ctx context.Context, organizationID string, project models.AgoraProjectInput) (*models.Organization, error) {
+	r.log(ctx).Info().Str("mutation", "SetOrganizationProject").Str("organizationId", organizationID).Str("appId", project.AppID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.setOrganizationProject(ctx, authUser, organizationID, project)
+}
+
+func (r *mutationResolver) RemoveOrganizationProject(ctx context.Context, organizationID string) (*models.Organization, error) {
+	r.log(ctx).Info().Str("mutation", "RemoveOrganizationProject").Str("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.removeOrganizationProject(ctx, authUser, organizationID)
+}
+
+func (r *mutationResolver) SetOrganizationStorage(ctx context.Context, organizationID string, storage models.ChannelStorageInput) (*models.Organization, error) {
+	r.log(ctx).Info().Str("mutation", "SetOrganizationStorage").Str("organizationId", organizationID).Str("bucket", storage.Bucket).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.setOrganizationStorage(ctx, authUser, organizationID, &storage)
+}
+
+func (r *mutationResolver) RemoveOrganizationStorage(ctx context.Context, organizationID string) (*models.Organization, error) {
+	r.log(ctx).Info().Str("mutation", "RemoveOrganizationStorage").Str("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.removeOrganizationStorage(ctx, authUser, organizationID)
+}
+
+func (r *organizationChannelResolver) Owner(ctx context.Context, obj *models.OrganizationChannel) (*models.User, error) {
+	return r.userByID(ctx, obj.OwnerID)
+}
+
+func (r *queryResolver) Organizations(ctx context.Context) ([]*models.Organization, error) {
+	r.log(ctx).Info().Str("query", "Organizations").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.organizations(ctx, authUser, sql.NullInt64{})
+}
+
+func (r *queryResolver) OrganizationMembers(ctx context.Context, organizationID string) ([]*models.OrganizationMember, error) {
+	r.log(ctx).Info().Str("query", "OrganizationMembers").Str("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.organizationMembers(ctx, authUser, organizationID)
+}
+
+func (r *queryResolver) OrganizationChannels(ctx context.Context, organizationID string, before *string, limit *int) ([]*models.OrganizationChannel, error) {
+	r.log(ctx).Info().Str("query", "OrganizationChannels").Str("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.organizationChannels(ctx, authUser, organizationID, before, pageSize)
+}
+
+// OrganizationChannel returns generated.OrganizationChannelResolver implementation.
+func (r *Resolver) OrganizationChannel() generated.OrganizationChannelResolver {
+	return &organizationChannelResolver{r}
+}
+
+type organizationChannelResolver struct{ *Resolver }
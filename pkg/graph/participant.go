@@ -0,0 +1,192 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// errBanned is returned when a participant that was removed from a channel tries to join it again
+var errBanned = apierror.New(apierror.CodeBanned, "You have been removed from this meeting")
+
+// recordParticipant stores the participant a session was issued to, so that hosts can see who joined. Failing to
+// store the participant does not keep them from joining. Participants are only stored on Postgres
+func (r *Resolver) recordParticipant(ctx context.Context, channelID int64, session *models.Session, name *string, user *models.UserAccount) {
+	if !r.DB.Postgres() {
+		return
+	}
+
+	participant := models.Participant{
+		ChannelID: channelID,
+		Mode:      session.Mode,
+	}
+
+	if session.ScreenShare != nil {
+		participant.ScreenShareUID = sql.NullInt32{Int32: int32(session.ScreenShare.UID), Valid: true}
+	}
+
+	// Screen share only sessions have no main user, so they are recognised by their screen share uid
+	if session.MainUser != nil {
+		participant.UID = session.MainUser.UID
+	} else if session.ScreenShare != nil {
+		participant.UID = session.ScreenShare.UID
+	}
+
+	if name != nil && *name != "" {
+		participant.Name = sql.NullString{String: utils.FirstN(*name, 100), Valid: true}
+	} else if user != nil && user.UserName.Valid {
+		participant.Name = user.UserName
+	}
+
+	if user != nil {
+		participant.UserID = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	_, err := r.DB.NamedExecContext(ctx, "INSERT INTO participants (channel_id, uid, screen_share_uid, name, user_id, mode) VALUES (:channel_id, :uid, :screen_share_uid, :name, :user_id, :mode) ON CONFLICT DO NOTHING", &participant)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Int("uid", participant.UID).Msg("Could not record participant")
+	}
+}
+
+// getParticipant fetches the participant of a channel that joined with a uid, either as main or screen share user
+func (r *Resolver) getParticipant(ctx context.Context, channelID int64, uid int) (*models.Participant, error) {
+	var participant models.Participant
+	err := r.DB.GetContext(ctx, &participant, "SELECT id, created_at, channel_id, uid, screen_share_uid, name, user_id, mode FROM participants WHERE channel_id = $1 AND (uid = $2 OR screen_share_uid = $2)", channelID, uid)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Invalid UID")
+	}
+
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Int("uid", uid).Msg("Could not fetch participant")
+		return nil, errInternalServer
+	}
+
+	return &participant, nil
+}
+
+// participantMode returns the mode a uid joined a channel with. Uids that were not recorded are assumed to have
+// joined with every kind of credentials
+func (r *Resolver) participantMode(ctx context.Context, channelID int64, uid int) (models.JoinMode, error) {
+	var mode models.JoinMode
+	err := r.DB.GetContext(ctx, &mode, "SELECT mode FROM participants WHERE channel_id = $1 AND uid = $2", channelID, uid)
+	if err == sql.ErrNoRows {
+		return models.JoinModeFull, nil
+	}
+
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Int("uid", uid).Msg("Could not fetch participant mode")
+		return "", errInternalServer
+	}
+
+	return mode, nil
+}
+
+// isBanned checks whether a uid or a signed in user is currently banned from a channel. Participants can only be
+// banned on Postgres
+func (r *Resolver) isBanned(ctx context.Context, channelID int64, uid int, user *models.UserAccount) (bool, error) {
+	if !r.DB.Postgres() {
+		return false, nil
+	}
+
+	userID := sql.NullInt64{}
+	if user != nil {
+		userID = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	var banned bool
+	err := r.DB.GetContext(ctx, &banned, "SELECT EXISTS (SELECT 1 FROM channel_bans WHERE channel_id = $1 AND banned_until > NOW() AND (uid = $2 OR user_id = $3))", channelID, uid, userID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelID).Msg("Could not check channel bans")
+		return false, errInternalServer
+	}
+
+	return banned, nil
+}
+
+// channelParticipants lists the users that are currently in a channel along with the names they joined with.
+// Recorders are left out and large audiences are only included in the total since Agora does not list them all
+func (r *Resolver) channelParticipants(ctx context.Context, channelData *models.Channel) (*models.ChannelParticipants, error) {
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := r.Agora.GetChannelUsers(project, channelData.ChannelName)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not fetch channel users")
+		return nil, agoraError(err)
+	}
+
+	result := &models.ChannelParticipants{
+		Participants: []*models.ChannelParticipant{},
+	}
+
+	var ignore []int32
+	if channelData.RecordingUID.Valid {
+		ignore = append(ignore, channelData.RecordingUID.Int32)
+	}
+	result.Total = list.Participants(ignore...)
+
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	stored := []models.Participant{}
+	err = r.DB.SelectContext(ctx, &stored, "SELECT id, created_at, channel_id, uid, screen_share_uid, name, user_id FROM participants WHERE channel_id = $1", channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch participants")
+		return nil, errInternalServer
+	}
+
+	names := map[int]*string{}
+	screenShares := map[int]bool{}
+	for _, participant := range stored {
+		var name *string
+		if participant.Name.Valid {
+			name = new(string)
+			*name = participant.Name.String
+		}
+
+		names[participant.UID] = name
+		if participant.ScreenShareUID.Valid {
+			names[int(participant.ScreenShareUID.Int32)] = name
+			screenShares[int(participant.ScreenShareUID.Int32)] = true
+		}
+	}
+
+	addUsers := func(users []int32, broadcaster bool) {
+		for _, user := range users {
+			if channelData.RecordingUID.Valid && user == channelData.RecordingUID.Int32 {
+				continue
+			}
+
+			uid := int(uint32(user))
+			result.Participants = append(result.Participants, &models.ChannelParticipant{
+				UID:           uid,
+				Name:          names[uid],
+				IsScreenShare: screenShares[uid],
+				IsBroadcaster: broadcaster,
+			})
+		}
+	}
+
+	// Every user can publish in communication channels, which are reported as a single list
+	addUsers(list.Data.Users, true)
+	addUsers(list.Data.Broadcasters, true)
+	addUsers(list.Data.Audience, false)
+
+	return result, nil
+}
@@ -0,0 +1,205 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// phoneProvider is the provider stored on users that signed up with their phone number
+const phoneProvider = "phone"
+
+// phoneNumberPattern matches phone numbers in E.164 format
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+var errInvalidPhoneNumber = apierror.New(apierror.CodeBadRequest, "Phone number must be in E.164 format")
+
+var errInvalidCode = apierror.New(apierror.CodeInvalidCredentials, "Invalid or expired code")
+
+// hashPhoneCode hashes a code along with the phone number it was sent to
+func hashPhoneCode(phoneNumber string, code string) string {
+	return utils.HashSecret(phoneNumber + ":" + code)
+}
+
+// checkPhoneAuth checks that phone sign in is enabled and that text messages can be sent
+func (r *Resolver) checkPhoneAuth(ctx context.Context, phoneNumber string) error {
+	if !viper.GetBool("ENABLE_PHONE_AUTH") || r.SMS == nil {
+		r.log(ctx).Debug().Bool("sms", r.SMS != nil).Msg("Phone sign in is not available")
+		return errAuthDisabled("Phone")
+	}
+
+	if !phoneNumberPattern.MatchString(phoneNumber) {
+		return errInvalidPhoneNumber
+	}
+
+	return nil
+}
+
+// requestOtp texts a new sign in code to a phone number. Codes that were sent before stop working and a new code
+// can only be requested every OTP_RESEND_SECONDS
+func (r *Resolver) requestOtp(ctx context.Context, phoneNumber string) (string, error) {
+	if err := r.checkPhoneAuth(ctx, phoneNumber); err != nil {
+		return "", err
+	}
+
+	if err := r.allowListed(ctx, phoneNumber); err != nil {
+		return "", err
+	}
+
+	var lastSent time.Time
+	err := r.DB.GetContext(ctx, &lastSent, "SELECT created_at FROM phone_codes WHERE phone_number = $1 ORDER BY id DESC LIMIT 1", phoneNumber)
+	if err != nil && err != sql.ErrNoRows {
+		r.log(ctx).Error().Err(err).Msg("Could not look up phone codes")
+		return "", errInternalServer
+	}
+
+	resend := time.Duration(viper.GetInt("OTP_RESEND_SECONDS")) * time.Second
+	if wait := time.Until(lastSent.Add(resend)); err == nil && wait > 0 {
+		return "", tooManyAttempts(wait)
+	}
+
+	code, err := utils.GenerateDigits(viper.GetInt("OTP_LENGTH"))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate code")
+		return "", errInternalServer
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return "", errInternalServer
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "UPDATE phone_codes SET used_at = NOW() WHERE phone_number = $1 AND used_at IS NULL", phoneNumber)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not expire previous codes")
+		return "", errInternalServer
+	}
+
+	expiry := time.Duration(viper.GetInt("OTP_EXPIRY_MINUTES")) * time.Minute
+	_, err = tx.ExecContext(ctx, "INSERT INTO phone_codes (phone_number, code_hash, expires_at) VALUES ($1, $2, $3)", phoneNumber, hashPhoneCode(phoneNumber, code), time.Now().Add(expiry))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not insert code")
+		return "", errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not commit code")
+		return "", errInternalServer
+	}
+
+	err = r.SMS.SendSMS(phoneNumber, "Your sign in code is "+code+". It expires in "+expiry.String()+".")
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not send code")
+		return "", errInternalServer
+	}
+
+	return "success", nil
+}
+
+// verifyOtp signs in with a code texted to a phone number, creating a user for new phone numbers. A code stops
+// working after OTP_MAX_ATTEMPTS wrong guesses
+func (r *Resolver) verifyOtp(ctx context.Context, phoneNumber string, code string) (*models.AuthSession, error) {
+	if err := r.checkPhoneAuth(ctx, phoneNumber); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	var phoneCode models.PhoneCode
+	err = tx.GetContext(ctx, &phoneCode, `SELECT id, created_at, phone_number, code_hash, expires_at, attempts, used_at FROM phone_codes
+		WHERE phone_number = $1 AND used_at IS NULL AND expires_at > NOW() ORDER BY id DESC LIMIT 1 FOR UPDATE`, phoneNumber)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidCode
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not look up phone code")
+		return nil, errInternalServer
+	}
+
+	if phoneCode.Attempts >= viper.GetInt("OTP_MAX_ATTEMPTS") {
+		return nil, errInvalidCode
+	}
+
+	if subtle.ConstantTimeCompare([]byte(phoneCode.CodeHash), []byte(hashPhoneCode(phoneNumber, code))) != 1 {
+		_, err = tx.ExecContext(ctx, "UPDATE phone_codes SET attempts = attempts + 1 WHERE id = $1", phoneCode.ID)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("code", phoneCode.ID).Msg("Could not record failed attempt")
+			return nil, errInternalServer
+		}
+
+		r.log(ctx).Info().Int64("code", phoneCode.ID).Int("attempts", phoneCode.Attempts+1).Msg("Wrong phone code")
+		return nil, errInvalidCode
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE phone_codes SET used_at = NOW() WHERE id = $1", phoneCode.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("code", phoneCode.ID).Msg("Could not use phone code")
+		return nil, errInternalServer
+	}
+
+	repos := r.Repos.WithTx(tx)
+	users := repos.Users
+	userID, err := users.IDByPhone(ctx, phoneNumber)
+	if err == sql.ErrNoRows {
+		user := models.UserAccount{
+			Identifier:  phoneNumber,
+			PhoneNumber: sql.NullString{String: phoneNumber, Valid: true},
+			Provider:    sql.NullString{String: phoneProvider, Valid: true},
+		}
+		err = users.Create(ctx, &user)
+		userID = user.ID
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not look up user")
+		return nil, errInternalServer
+	}
+
+	tokens, err := services.CreateAuthSession(ctx, repos, userID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not create session")
+		return nil, errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not commit sign in")
+		return nil, errInternalServer
+	}
+
+	return &models.AuthSession{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,241 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// Limits on the polls hosts can create
+const (
+	maxPollQuestionLength = 500
+	maxPollOptionLength   = 200
+	maxPollOptions        = 10
+)
+
+// pollColumns lists the columns of the polls table that are mapped onto models.ChannelPoll
+const pollColumns = "id, created_at, channel_id, poll_id, question, options, closed_at"
+
+// pollsTopic is the topic that receives the polls of a channel whenever they change
+func pollsTopic(channelID int64) string {
+	return "polls:" + strconv.FormatInt(channelID, 10)
+}
+
+// pollVoteCount is the number of votes an option of a poll received
+type pollVoteCount struct {
+	PollID int64 `db:"poll_id"`
+	Option int   `db:"option"`
+	Votes  int   `db:"votes"`
+}
+
+// pollResults counts the votes of polls and converts them into the polls sent to clients
+func (r *Resolver) pollResults(ctx context.Context, polls []models.ChannelPoll) ([]*models.Poll, error) {
+	results := []*models.Poll{}
+	if len(polls) == 0 {
+		return results, nil
+	}
+
+	ids := make([]int64, len(polls))
+	for i, poll := range polls {
+		ids[i] = poll.ID
+	}
+
+	query, args, err := sqlx.In("SELECT poll_id, option, COUNT(*) AS votes FROM poll_votes WHERE poll_id IN (?) GROUP BY poll_id, option", ids)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not build poll vote query")
+		return nil, errInternalServer
+	}
+
+	counts := []pollVoteCount{}
+	err = r.DB.SelectContext(ctx, &counts, r.DB.Rebind(query), args...)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not count poll votes")
+		return nil, errInternalServer
+	}
+
+	votes := map[int64]map[int]int{}
+	for _, count := range counts {
+		if votes[count.PollID] == nil {
+			votes[count.PollID] = map[int]int{}
+		}
+		votes[count.PollID][count.Option] = count.Votes
+	}
+
+	for _, poll := range polls {
+		result := &models.Poll{
+			ID:        poll.PollID,
+			Question:  poll.Question,
+			Options:   []*models.PollOption{},
+			Closed:    poll.ClosedAt.Valid,
+			CreatedAt: poll.CreatedAt,
+		}
+
+		for i, option := range poll.Options {
+			optionVotes := votes[poll.ID][i]
+			result.Options = append(result.Options, &models.PollOption{
+				Index: i,
+				Text:  option,
+				Votes: optionVotes,
+			})
+			result.TotalVotes += optionVotes
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// publishPoll counts the votes of a poll and notifies the participants of its channel about the new results
+func (r *Resolver) publishPoll(ctx context.Context, poll models.ChannelPoll) (*models.Poll, error) {
+	results, err := r.pollResults(ctx, []models.ChannelPoll{poll})
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := json.Marshal(results[0])
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not encode poll")
+		return results[0], nil
+	}
+
+	r.PubSub.Publish(pollsTopic(poll.ChannelID), message)
+	return results[0], nil
+}
+
+// getPoll fetches a poll of a channel
+func (r *Resolver) getPoll(ctx context.Context, channelID int64, pollID string) (*models.ChannelPoll, error) {
+	var poll models.ChannelPoll
+	err := r.DB.GetContext(ctx, &poll, "SELECT "+pollColumns+" FROM polls WHERE channel_id = $1 AND poll_id = $2", channelID, pollID)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Invalid poll ID")
+	}
+
+	if err != nil {
+		r.Logger.Error().Err(err).Str("pollId", pollID).Msg("Could not fetch poll")
+		return nil, errInternalServer
+	}
+
+	return &poll, nil
+}
+
+// channelPolls returns the polls of a channel with their results, oldest first
+func (r *Resolver) channelPolls(ctx context.Context, channelData *models.Channel) ([]*models.Poll, error) {
+	polls := []models.ChannelPoll{}
+	err := r.DB.SelectContext(ctx, &polls, "SELECT "+pollColumns+" FROM polls WHERE channel_id = $1 ORDER BY id", channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch polls")
+		return nil, errInternalServer
+	}
+
+	return r.pollResults(ctx, polls)
+}
+
+// createPoll opens a poll in a channel with the given options
+func (r *Resolver) createPoll(ctx context.Context, channelData *models.Channel, question string, options []string) (*models.Poll, error) {
+	question = strings.TrimSpace(question)
+	if question == "" || len([]rune(question)) > maxPollQuestionLength {
+		return nil, apierror.New(apierror.CodeBadRequest, "Question must be between 1 and "+strconv.Itoa(maxPollQuestionLength)+" characters")
+	}
+
+	if len(options) < 2 || len(options) > maxPollOptions {
+		return nil, apierror.New(apierror.CodeBadRequest, "Poll must have between 2 and "+strconv.Itoa(maxPollOptions)+" options")
+	}
+
+	poll := models.ChannelPoll{
+		ChannelID: channelData.ID,
+		Question:  question,
+	}
+
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || len([]rune(option)) > maxPollOptionLength {
+			return nil, apierror.New(apierror.CodeBadRequest, "Options must be between 1 and "+strconv.Itoa(maxPollOptionLength)+" characters")
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	pollID, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Poll ID generation failed")
+		return nil, errInternalServer
+	}
+	poll.PollID = pollID
+
+	err = r.DB.QueryRowxContext(ctx, "INSERT INTO polls (channel_id, poll_id, question, options) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		poll.ChannelID, poll.PollID, poll.Question, poll.Options).Scan(&poll.ID, &poll.CreatedAt)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not create poll")
+		return nil, errInternalServer
+	}
+
+	return r.publishPoll(ctx, poll)
+}
+
+// votePoll records the option a participant voted for. Participants can change their vote until the poll is closed
+func (r *Resolver) votePoll(ctx context.Context, channelData *models.Channel, pollID string, uid int, option int) error {
+	poll, err := r.getPoll(ctx, channelData.ID, pollID)
+	if err != nil {
+		return err
+	}
+
+	if poll.ClosedAt.Valid {
+		return apierror.New(apierror.CodeBadRequest, "Poll is closed")
+	}
+
+	if option < 0 || option >= len(poll.Options) {
+		return apierror.New(apierror.CodeBadRequest, "Invalid option")
+	}
+
+	participant, err := r.getParticipant(ctx, channelData.ID, uid)
+	if err != nil {
+		return err
+	}
+
+	// Votes are counted per participant, so the screen share user cannot vote a second time
+	_, err = r.DB.ExecContext(ctx, `INSERT INTO poll_votes (poll_id, uid, option) VALUES ($1, $2, $3)
+		ON CONFLICT (poll_id, uid) DO UPDATE SET option = EXCLUDED.option, created_at = CURRENT_TIMESTAMP`, poll.ID, participant.UID, option)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("pollId", pollID).Int("uid", uid).Msg("Could not record vote")
+		return errInternalServer
+	}
+
+	_, err = r.publishPoll(ctx, *poll)
+	return err
+}
+
+// closePoll stops a poll from accepting votes and publishes its final results
+func (r *Resolver) closePoll(ctx context.Context, channelData *models.Channel, pollID string) (*models.Poll, error) {
+	poll, err := r.getPoll(ctx, channelData.ID, pollID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.GetContext(ctx, &poll.ClosedAt, "UPDATE polls SET closed_at = CURRENT_TIMESTAMP WHERE id = $1 AND closed_at IS NULL RETURNING closed_at", poll.ID)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeBadRequest, "Poll is already closed")
+	}
+
+	if err != nil {
+		r.Logger.Error().Err(err).Str("pollId", pollID).Msg("Could not close poll")
+		return nil, errInternalServer
+	}
+
+	return r.publishPoll(ctx, *poll)
+}
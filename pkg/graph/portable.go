@@ -0,0 +1,36 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import "github.com/samyak-jain/agora_backend/pkg/apierror"
+
+// PortableFields are the root fields whose resolvers only use the tables that every database has: channels, users,
+// API keys and signing in with OAuth. The other fields need tables that only the Postgres schema has. Recording is
+// not portable, since recordings that Agora stopped or that run on empty channels are only cleared by the background
+// jobs, which need the jobs table of Postgres
+var PortableFields = map[string]bool{
+	"Query.joinChannel":          true,
+	"Query.share":                true,
+	"Query.getUser":              true,
+	"Query.apiKeys":              true,
+	"Query.getSessions":          true,
+	"Mutation.createChannel":     true,
+	"Mutation.updateUserName":    true,
+	"Mutation.createApiKey":      true,
+	"Mutation.revokeApiKey":      true,
+	"Mutation.logoutSession":     true,
+	"Mutation.refreshSession":    true,
+	"Mutation.logoutAllSessions": true,
+	"Mutation.revokeSession":     true,
+}
+
+// errNeedsPostgres is returned when a portable field is asked for a feature that needs the tables of Postgres
+var errNeedsPostgres = apierror.New(apierror.CodeFeatureDisabled, "Phone calls, channel storage, waiting rooms and organizations need a Postgres database")
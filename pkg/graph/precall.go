@@ -0,0 +1,63 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
+	"github.com/spf13/viper"
+)
+
+// precallChannelPrefix starts the names of channels of precall tests, so they are told apart from meetings in usage
+// and analytics
+const precallChannelPrefix = "precall-"
+
+// precallTest generates credentials for a new random channel, in the project and area of the channel of passphrase
+// when there is one. The tokens are only valid for PRECALL_TOKEN_EXPIRY_SECONDS
+func (r *Resolver) precallTest(ctx context.Context, passphrase *string) (*models.PrecallTest, error) {
+	project := utils.DefaultProject()
+	area := utils.DefaultArea()
+	if passphrase != nil {
+		channelData, _, err := r.getChannel(ctx, *passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		project, err = r.channelProject(ctx, channelData)
+		if err != nil {
+			return nil, err
+		}
+		area = utils.ChannelArea(channelData)
+	}
+
+	suffix, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate precall channel name")
+		return nil, errInternalServer
+	}
+
+	channel := precallChannelPrefix + suffix
+	expiry := viper.GetInt("PRECALL_TOKEN_EXPIRY_SECONDS")
+	user, err := r.Tokens.GenerateUserCredentials(project, channel, rtctoken.RolePublisher, uint32(expiry), false, false, area)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate precall test credentials")
+		return nil, errInternalServer
+	}
+
+	return &models.PrecallTest{
+		AppID:     project.AppID,
+		Channel:   channel,
+		User:      user,
+		ExpiresIn: expiry,
+	}, nil
+}
@@ -0,0 +1,128 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// phoneNumber matches phone numbers in E.164 format
+var phoneNumber = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// normalizePhoneNumber removes the separators people commonly write phone numbers with and reports whether the
+// result is a valid E.164 phone number
+func normalizePhoneNumber(number string) (string, bool) {
+	number = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(number)
+	return number, phoneNumber.MatchString(number)
+}
+
+// dialOutCall converts a stored PSTN call into its GraphQL representation
+func dialOutCall(call models.PSTNCall) *models.DialOutCall {
+	return &models.DialOutCall{
+		CallID:      call.CallID,
+		PhoneNumber: call.PhoneNumber,
+		Status:      call.Status,
+		CreatedAt:   call.CreatedAt,
+		UpdatedAt:   call.UpdatedAt,
+	}
+}
+
+// pstnDetails builds the details to dial in to the conference with the given DTMF. When a country is given and
+// there are numbers for it, only those numbers are listed, otherwise every number is. The main number is the
+// first listed number, or PSTN_NUMBER when no numbers have been added
+func (r *Resolver) pstnDetails(ctx context.Context, dtmf string, country *string) *models.Pstn {
+	// Numbers are only added on Postgres
+	numbers := []models.PSTNNumber{}
+	if r.DB.Postgres() {
+		err := r.DB.SelectContext(ctx, &numbers, "SELECT id, created_at, country, region, number FROM pstn_numbers ORDER BY country, id")
+		if err != nil {
+			r.Logger.Error().Err(err).Msg("Could not fetch PSTN numbers")
+		}
+	}
+
+	if country != nil {
+		local := []models.PSTNNumber{}
+		for _, number := range numbers {
+			if strings.EqualFold(number.Country, strings.TrimSpace(*country)) {
+				local = append(local, number)
+			}
+		}
+
+		if len(local) > 0 {
+			numbers = local
+		}
+	}
+
+	result := &models.Pstn{
+		Number:  viper.GetString("PSTN_NUMBER"),
+		Dtmf:    dtmf,
+		Numbers: []*models.DialInNumber{},
+	}
+
+	if result.Number == "" {
+		result.Number = "(800) 309-2350"
+	}
+
+	for _, number := range numbers {
+		dialIn := &models.DialInNumber{
+			Country: number.Country,
+			Number:  number.Number,
+		}
+
+		if number.Region.Valid {
+			region := number.Region.String
+			dialIn.Region = &region
+		}
+
+		result.Numbers = append(result.Numbers, dialIn)
+	}
+
+	if len(result.Numbers) > 0 {
+		result.Number = result.Numbers[0].Number
+	}
+
+	return result
+}
+
+// dtmfAttempts is how many PINs are generated before giving up on finding one that is not in use
+const dtmfAttempts = 5
+
+// uniqueDTMF generates a PIN that is not used by any channel that has not ended, so that callers cannot end up in
+// the wrong meeting
+func (r *Resolver) uniqueDTMF(ctx context.Context) (*string, error) {
+	for attempt := 0; attempt < dtmfAttempts; attempt++ {
+		dtmf, err := r.IDs.GenerateDTMF()
+		if err != nil {
+			r.Logger.Error().Err(err).Msg("DTMF generation failed")
+			return nil, errInternalServer
+		}
+
+		taken, err := r.Repos.Channels.DTMFTaken(ctx, *dtmf)
+		if err != nil {
+			r.Logger.Error().Err(err).Msg("Could not check DTMF")
+			return nil, errInternalServer
+		}
+
+		if !taken {
+			return dtmf, nil
+		}
+
+		r.Logger.Debug().Int("attempt", attempt).Msg("DTMF already in use")
+	}
+
+	r.Logger.Error().Int("attempts", dtmfAttempts).Msg("Could not generate an unused DTMF, DTMF_LENGTH may be too short")
+	return nil, errInternalServer
+}
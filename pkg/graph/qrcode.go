@@ -0,0 +1,106 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// qrCodeScale is the size in pixels of a module of QR codes rendered as PNG
+const qrCodeScale = 8
+
+// telURI returns a tel: URI that dials a number and enters a PIN after a pause, keeping only the digits of the
+// number and its leading +
+func telURI(number string, pin string) string {
+	var digits strings.Builder
+	for index, c := range strings.TrimSpace(number) {
+		if c >= '0' && c <= '9' || c == '+' && index == 0 {
+			digits.WriteRune(c)
+		}
+	}
+
+	return "tel:" + digits.String() + ",," + url.PathEscape(pin+"#")
+}
+
+// qrContent returns what the QR code of a channel encodes
+func (r *Resolver) qrContent(ctx context.Context, channelData *models.Channel, typeArg models.QRCodeType, country *string) (string, error) {
+	if typeArg == models.QRCodeTypePstn {
+		if channelData.DTMF == "" {
+			return "", apierror.New(apierror.CodeBadRequest, "Dial in is not enabled for this channel")
+		}
+
+		pstn := r.pstnDetails(ctx, channelData.DTMF, country)
+		number := pstn.Number
+		for _, dialIn := range pstn.Numbers {
+			if country != nil && strings.EqualFold(dialIn.Country, strings.TrimSpace(*country)) {
+				number = dialIn.Number
+				break
+			}
+		}
+
+		return telURI(number, pstn.Dtmf), nil
+	}
+
+	shortLinks, err := r.shortLinks(ctx, r.DB, channelData.ID, nil, channelData.ViewerPassphrase)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not create short links")
+	}
+
+	if shortLinks != nil {
+		return shortLinks.View, nil
+	}
+
+	link := joinURL(channelData.ViewerPassphrase)
+	if link == "" {
+		return "", apierror.New(apierror.CodeUnavailable, "Join links are not enabled")
+	}
+
+	return link, nil
+}
+
+// shareQr renders the QR code of a channel
+func (r *Resolver) shareQr(ctx context.Context, channelData *models.Channel, typeArg models.QRCodeType, format models.QRCodeFormat, country *string) (*models.QRCode, error) {
+	content, err := r.qrContent(ctx, channelData, typeArg, country)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := utils.NewQRCode([]byte(content))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("content", content).Msg("Could not encode QR code")
+		return nil, errInternalServer
+	}
+
+	var dataURL string
+	if format == models.QRCodeFormatPng {
+		image, err := code.PNG(qrCodeScale)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not render QR code")
+			return nil, errInternalServer
+		}
+
+		dataURL = "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+	} else {
+		dataURL = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(code.SVG()))
+	}
+
+	return &models.QRCode{
+		Content: content,
+		Format:  format,
+		DataURL: dataURL,
+	}, nil
+}
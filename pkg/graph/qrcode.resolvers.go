@@ -0,0 +1,36 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *queryResolver) ShareQr(ctx context.Context, passphrase string, typeArg models.QRCodeType, format *models.QRCodeFormat, country *string) (*models.QRCode, error) {
+	r.log(ctx).Info().Str("query", "ShareQr").Str("passphrase", passphrase).Str("type", typeArg.String()).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	imageFormat := models.QRCodeFormatSvg
+	if format != nil {
+		imageFormat = *format
+	}
+
+	return r.shareQr(ctx, channelData, typeArg, imageFormat, country)
+}
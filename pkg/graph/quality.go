@@ -0,0 +1,236 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// maxQualitySamples is the most call quality samples that can be reported at once
+const maxQualitySamples = 60
+
+// canReadQuality reports whether the call quality of a channel can be read by the caller, which hosts of the channel
+// and administrators can, so that support can look into complaints
+func (r *Resolver) canReadQuality(ctx context.Context, host bool) bool {
+	if host {
+		return true
+	}
+
+	user, err := middleware.GetUserFromContext(ctx)
+	return err == nil && hasRole(user, models.RoleAdmin)
+}
+
+// validQualityStats checks that the metrics of a sample are in range
+func validQualityStats(stats *models.QualityStatsInput) bool {
+	for _, value := range []*int{stats.Rtt, stats.Width, stats.Height, stats.FrameRate} {
+		if value != nil && *value < 0 {
+			return false
+		}
+	}
+
+	for _, value := range []*float64{stats.UplinkPacketLoss, stats.DownlinkPacketLoss} {
+		if value != nil && (*value < 0 || *value > 100) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nullInt32(value *int) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+
+	return sql.NullInt32{Int32: int32(*value), Valid: true}
+}
+
+func nullFloat64(value *float64) sql.NullFloat64 {
+	if value == nil {
+		return sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: *value, Valid: true}
+}
+
+// reportQualityStats stores call quality samples of a participant of a channel. Samples measured in the future, by
+// clients with skewed clocks, are stored as measured now
+func (r *Resolver) reportQualityStats(ctx context.Context, channelData *models.Channel, uid int, stats []*models.QualityStatsInput) error {
+	if channelData.EndedAt.Valid {
+		return errMeetingEnded
+	}
+
+	if len(stats) == 0 {
+		return nil
+	}
+
+	if len(stats) > maxQualitySamples {
+		return apierror.New(apierror.CodeBadRequest, "Too many samples, report them in smaller batches")
+	}
+
+	_, err := r.getParticipant(ctx, channelData.ID, uid)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	samples := make([]models.ChannelQualitySample, 0, len(stats))
+	for _, sample := range stats {
+		if !validQualityStats(sample) {
+			return apierror.New(apierror.CodeBadRequest, "Invalid quality stats")
+		}
+
+		measuredAt := now
+		if sample.MeasuredAt != nil && sample.MeasuredAt.Before(now) {
+			measuredAt = *sample.MeasuredAt
+		}
+
+		samples = append(samples, models.ChannelQualitySample{
+			ChannelID:          channelData.ID,
+			UID:                int64(uid),
+			ReportedAt:         measuredAt,
+			RTT:                nullInt32(sample.Rtt),
+			UplinkPacketLoss:   nullFloat64(sample.UplinkPacketLoss),
+			DownlinkPacketLoss: nullFloat64(sample.DownlinkPacketLoss),
+			Width:              nullInt32(sample.Width),
+			Height:             nullInt32(sample.Height),
+			FrameRate:          nullInt32(sample.FrameRate),
+		})
+	}
+
+	_, err = r.DB.NamedExecContext(ctx, "INSERT INTO quality_stats (channel_id, uid, reported_at, rtt, uplink_packet_loss, downlink_packet_loss, width, height, frame_rate) VALUES (:channel_id, :uid, :reported_at, :rtt, :uplink_packet_loss, :downlink_packet_loss, :width, :height, :frame_rate)", samples)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Int("uid", uid).Msg("Could not store quality stats")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// callQualityReport summarises the call quality each participant of a channel reported, with the samples in the order
+// they were measured. Packet loss is summarised over both directions, since either makes the call feel laggy
+func (r *Resolver) callQualityReport(ctx context.Context, channelData *models.Channel) ([]*models.ParticipantQuality, error) {
+	rows := []struct {
+		models.ChannelQualitySample
+		Name sql.NullString `db:"name"`
+	}{}
+
+	err := r.DB.SelectContext(ctx, &rows, `SELECT quality_stats.id, quality_stats.created_at, quality_stats.channel_id, quality_stats.uid,
+		quality_stats.reported_at, quality_stats.rtt, quality_stats.uplink_packet_loss, quality_stats.downlink_packet_loss,
+		quality_stats.width, quality_stats.height, quality_stats.frame_rate, participants.name
+		FROM quality_stats
+		LEFT JOIN participants ON participants.channel_id = quality_stats.channel_id
+		AND (participants.uid = quality_stats.uid OR participants.screen_share_uid = quality_stats.uid)
+		WHERE quality_stats.channel_id = $1
+		ORDER BY quality_stats.uid, quality_stats.reported_at`, channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch quality stats")
+		return nil, errInternalServer
+	}
+
+	report := []*models.ParticipantQuality{}
+	var current *models.ParticipantQuality
+	var rttTotal, rttCount, lossTotal, lossCount float64
+	summarise := func() {
+		if current == nil {
+			return
+		}
+
+		if rttCount > 0 {
+			average := rttTotal / rttCount
+			current.AverageRtt = &average
+		}
+
+		if lossCount > 0 {
+			average := lossTotal / lossCount
+			current.AveragePacketLoss = &average
+		}
+
+		report = append(report, current)
+	}
+
+	for _, row := range rows {
+		if current == nil || int64(current.UID) != row.UID {
+			summarise()
+			current = &models.ParticipantQuality{
+				UID:     int(row.UID),
+				Samples: []*models.QualitySample{},
+			}
+			if row.Name.Valid {
+				name := row.Name.String
+				current.Name = &name
+			}
+			rttTotal, rttCount, lossTotal, lossCount = 0, 0, 0, 0
+		}
+
+		sample := &models.QualitySample{MeasuredAt: row.ReportedAt}
+		if row.RTT.Valid {
+			rtt := int(row.RTT.Int32)
+			sample.Rtt = &rtt
+			rttTotal += float64(rtt)
+			rttCount++
+			if current.MaxRtt == nil || rtt > *current.MaxRtt {
+				current.MaxRtt = &rtt
+			}
+		}
+
+		for _, loss := range []sql.NullFloat64{row.UplinkPacketLoss, row.DownlinkPacketLoss} {
+			if !loss.Valid {
+				continue
+			}
+
+			value := loss.Float64
+			lossTotal += value
+			lossCount++
+			if current.MaxPacketLoss == nil || value > *current.MaxPacketLoss {
+				current.MaxPacketLoss = &value
+			}
+		}
+
+		if row.UplinkPacketLoss.Valid {
+			uplink := row.UplinkPacketLoss.Float64
+			sample.UplinkPacketLoss = &uplink
+		}
+
+		if row.DownlinkPacketLoss.Valid {
+			downlink := row.DownlinkPacketLoss.Float64
+			sample.DownlinkPacketLoss = &downlink
+		}
+
+		if row.Width.Valid {
+			width := int(row.Width.Int32)
+			sample.Width = &width
+		}
+
+		if row.Height.Valid {
+			height := int(row.Height.Int32)
+			sample.Height = &height
+			if current.MinHeight == nil || height < *current.MinHeight {
+				current.MinHeight = &height
+			}
+		}
+
+		if row.FrameRate.Valid {
+			frameRate := int(row.FrameRate.Int32)
+			sample.FrameRate = &frameRate
+		}
+
+		current.Samples = append(current.Samples, sample)
+	}
+	summarise()
+
+	return report, nil
+}
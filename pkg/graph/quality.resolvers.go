@@ -0,0 +1,52 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) ReportQualityStats(ctx context.Context, passphrase string, uid int, stats []*models.QualityStatsInput) (string, error) {
+	r.log(ctx).Info().Str("mutation", "ReportQualityStats").Str("passphrase", passphrase).Int("uid", uid).Int("samples", len(stats)).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.reportQualityStats(ctx, channelData, uid, stats)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) CallQualityReport(ctx context.Context, passphrase string) ([]*models.ParticipantQuality, error) {
+	r.log(ctx).Info().Str("query", "CallQualityReport").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.canReadQuality(ctx, host) {
+		r.log(ctx).Debug().Msg("Unauthorized to view call quality")
+		return nil, errNotHost("view call quality")
+	}
+
+	return r.callQualityReport(ctx, channelData)
+}
@@ -0,0 +1,219 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// maxQuestionLength is the longest question that can be asked, in characters
+const maxQuestionLength = 1000
+
+// questionColumns lists the columns of the questions table that are mapped onto models.ChannelQuestion
+const questionColumns = "id, created_at, channel_id, question_id, uid, name, body, status, upvotes"
+
+// questionsTopic is the topic that receives the questions of a channel whenever they change
+func questionsTopic(channelID int64) string {
+	return "questions:" + strconv.FormatInt(channelID, 10)
+}
+
+// question converts a stored question into the question sent to clients
+func question(stored models.ChannelQuestion) *models.Question {
+	result := &models.Question{
+		ID:      stored.QuestionID,
+		Text:    stored.Body,
+		Status:  stored.Status,
+		Upvotes: stored.Upvotes,
+		AskedAt: stored.CreatedAt,
+	}
+
+	if stored.UID.Valid {
+		uid := int(stored.UID.Int32)
+		result.UID = &uid
+	}
+
+	if stored.Name.Valid {
+		name := stored.Name.String
+		result.Name = &name
+	}
+
+	return result
+}
+
+// publishQuestion notifies the participants of a channel about a new or changed question
+func (r *Resolver) publishQuestion(stored models.ChannelQuestion) *models.Question {
+	result := question(stored)
+
+	message, err := json.Marshal(result)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not encode question")
+		return result
+	}
+
+	r.PubSub.Publish(questionsTopic(stored.ChannelID), message)
+	return result
+}
+
+// getQuestion fetches a question of a channel
+func (r *Resolver) getQuestion(ctx context.Context, channelID int64, questionID string) (*models.ChannelQuestion, error) {
+	var stored models.ChannelQuestion
+	err := r.DB.GetContext(ctx, &stored, "SELECT "+questionColumns+" FROM questions WHERE channel_id = $1 AND question_id = $2", channelID, questionID)
+	if err == sql.ErrNoRows {
+		return nil, apierror.New(apierror.CodeNotFound, "Invalid question ID")
+	}
+
+	if err != nil {
+		r.Logger.Error().Err(err).Str("questionId", questionID).Msg("Could not fetch question")
+		return nil, errInternalServer
+	}
+
+	return &stored, nil
+}
+
+// channelQuestions lists the questions asked in a channel, either oldest first or with the most upvoted first.
+// Dismissed questions are only listed for hosts
+func (r *Resolver) channelQuestions(ctx context.Context, channelData *models.Channel, host bool, sort models.QuestionSort) ([]*models.Question, error) {
+	order := "id"
+	if sort == models.QuestionSortUpvotes {
+		order = "upvotes DESC, id"
+	}
+
+	stored := []models.ChannelQuestion{}
+	err := r.DB.SelectContext(ctx, &stored, "SELECT "+questionColumns+" FROM questions WHERE channel_id = $1 AND ($2 OR status <> $3) ORDER BY "+order,
+		channelData.ID, host, models.QuestionStatusDismissed)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch questions")
+		return nil, errInternalServer
+	}
+
+	questions := []*models.Question{}
+	for _, entry := range stored {
+		questions = append(questions, question(entry))
+	}
+
+	return questions, nil
+}
+
+// askQuestion stores a question for the hosts of a channel. Questions asked with a uid show the name the
+// participant joined with, while questions without one are anonymous
+func (r *Resolver) askQuestion(ctx context.Context, channelData *models.Channel, text string, uid *int) (*models.Question, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Question cannot be empty")
+	}
+
+	if len([]rune(text)) > maxQuestionLength {
+		return nil, apierror.New(apierror.CodeBadRequest, "Question is too long")
+	}
+
+	questionID, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Question ID generation failed")
+		return nil, errInternalServer
+	}
+
+	stored := models.ChannelQuestion{
+		ChannelID:  channelData.ID,
+		QuestionID: questionID,
+		Body:       text,
+		Status:     models.QuestionStatusOpen,
+	}
+
+	if uid != nil {
+		participant, err := r.getParticipant(ctx, channelData.ID, *uid)
+		if err != nil {
+			return nil, err
+		}
+
+		stored.UID = sql.NullInt32{Int32: int32(participant.UID), Valid: true}
+		stored.Name = participant.Name
+	}
+
+	err = r.DB.QueryRowxContext(ctx, "INSERT INTO questions (channel_id, question_id, uid, name, body, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at",
+		stored.ChannelID, stored.QuestionID, stored.UID, stored.Name, stored.Body, stored.Status).Scan(&stored.ID, &stored.CreatedAt)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not store question")
+		return nil, errInternalServer
+	}
+
+	return r.publishQuestion(stored), nil
+}
+
+// upvoteQuestion counts the upvote of a participant for a question. Upvoting a question twice has no effect
+func (r *Resolver) upvoteQuestion(ctx context.Context, channelData *models.Channel, questionID string, uid int) (*models.Question, error) {
+	stored, err := r.getQuestion(ctx, channelData.ID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.Status != models.QuestionStatusOpen {
+		return nil, apierror.New(apierror.CodeBadRequest, "Question is no longer open")
+	}
+
+	participant, err := r.getParticipant(ctx, channelData.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO question_votes (question_id, uid) VALUES ($1, $2) ON CONFLICT DO NOTHING", stored.ID, participant.UID)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("questionId", questionID).Int("uid", uid).Msg("Could not record upvote")
+		return nil, errInternalServer
+	}
+
+	if inserted, err := result.RowsAffected(); err == nil && inserted == 0 {
+		return question(*stored), nil
+	}
+
+	err = tx.GetContext(ctx, &stored.Upvotes, "UPDATE questions SET upvotes = upvotes + 1 WHERE id = $1 RETURNING upvotes", stored.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("questionId", questionID).Msg("Could not count upvote")
+		return nil, errInternalServer
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.Logger.Error().Err(err).Str("questionId", questionID).Msg("Could not commit upvote")
+		return nil, errInternalServer
+	}
+
+	return r.publishQuestion(*stored), nil
+}
+
+// setQuestionStatus marks a question as answered or dismissed on behalf of a host
+func (r *Resolver) setQuestionStatus(ctx context.Context, channelData *models.Channel, questionID string, status models.QuestionStatus) (*models.Question, error) {
+	stored, err := r.getQuestion(ctx, channelData.ID, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "UPDATE questions SET status = $1 WHERE id = $2", status, stored.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("questionId", questionID).Msg("Could not update question")
+		return nil, errInternalServer
+	}
+
+	stored.Status = status
+	return r.publishQuestion(*stored), nil
+}
@@ -0,0 +1,362 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// getRecordingChannel fetches the channel for a host passphrase and makes sure a recording is in progress
+func (r *Resolver) getRecordingChannel(ctx context.Context, passphrase string) (*models.Channel, error) {
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
+		return nil, errNotHost("record channel")
+	}
+
+	if !channelData.RecordingRID.Valid || !channelData.RecordingSID.Valid || !channelData.RecordingUID.Valid {
+		r.log(ctx).Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
+		return nil, errRecordingNotStarted
+	}
+
+	return channelData, nil
+}
+
+// recordingStartTimeout is how long a recording can take to start. A channel still claimed by a start after that is
+// assumed to have lost the server that was starting it, and can be claimed again
+const recordingStartTimeout = 2 * time.Minute
+
+// recordingStartPoll is how often a host waits for the recording another host is starting
+const recordingStartPoll = 500 * time.Millisecond
+
+// errRecordingCancelled is returned when the meeting ended while a recording was starting
+var errRecordingCancelled = apierror.New(apierror.CodeRecordingNotActive, "Recording was stopped while it was starting")
+
+// claimRecording claims the channel for a recording in mode under the recording lock. It returns the SID of the
+// recording in mode that is already running instead, or claimed false when another host is still starting one
+func (r *Resolver) claimRecording(ctx context.Context, channelID int64, mode string) (sid string, claimed bool, err error) {
+	err = r.DB.WithAdvisoryLock(ctx, models.LockRecording, channelID, func(tx *sqlx.Tx) error {
+		channels := r.Repos.WithTx(tx).Channels
+		current, err := channels.ByID(ctx, channelID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not fetch channel")
+			return errInternalServer
+		}
+
+		starting := current.RecordingStatus.String == services.RecordingStarting && time.Since(current.RecordingStartedAt.Time) < recordingStartTimeout
+		if current.RecordingSID.Valid || starting {
+			if current.RecordingMode != mode {
+				r.log(ctx).Debug().Int64("Channel ID", channelID).Str("mode", current.RecordingMode).Msg("Another recording is already in progress")
+				return errRecordingActive
+			}
+
+			if starting {
+				r.log(ctx).Debug().Int64("Channel ID", channelID).Msg("Waiting for the recording another host is starting")
+				return nil
+			}
+
+			r.log(ctx).Info().Int64("Channel ID", channelID).Str("sid", current.RecordingSID.String).Msg("Recording already in progress")
+			sid = current.RecordingSID.String
+			return nil
+		}
+
+		err = r.checkQuota(ctx, tx, services.ChannelUsageSubject(current), models.UsageRecordingMinutes)
+		if err != nil {
+			return err
+		}
+
+		err = channels.ClaimRecording(ctx, channelID, mode, time.Now())
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not claim channel for recording")
+			return errInternalServer
+		}
+
+		claimed = true
+		return nil
+	})
+	if apierror.CodeOf(err) != "" {
+		return "", false, err
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not lock channel for recording")
+		return "", false, errInternalServer
+	}
+
+	return sid, claimed, nil
+}
+
+// startRecording starts a recording in mode on the channel with start unless one is already running and returns the
+// SID of the recording. The channel is claimed for the recording under an advisory lock, and the lock is let go of
+// before calling Agora so that no connection is held while Agora is slow. Hosts starting a recording at the same time
+// wait for the one that claimed the channel and get the recording it started. A running recording in another mode is
+// reported as errRecordingActive
+func (r *Resolver) startRecording(ctx context.Context, channelID int64, mode string, start func() (*utils.Recorder, error)) (string, error) {
+	for {
+		sid, claimed, err := r.claimRecording(ctx, channelID, mode)
+		if err != nil {
+			return "", err
+		}
+
+		if sid != "" {
+			return sid, nil
+		}
+
+		if claimed {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(recordingStartPoll):
+		}
+	}
+
+	recorder, err := start()
+	if err != nil {
+		// The claim is released even when the request was cancelled, so that hosts do not wait for a start that failed
+		if err := r.Repos.Channels.ReleaseRecording(context.Background(), channelID); err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not release channel for recording")
+		}
+		return "", err
+	}
+
+	err = r.storeRecording(ctx, channelID, recorder)
+	if err != nil {
+		// A recording that is not stored would never be stopped, since nothing knows its SID
+		if err := r.Recorder.Stop(recorder); err != nil && err != utils.ErrRecordingNotFound {
+			r.log(ctx).Error().Err(err).Str("sid", recorder.SID).Msg("Could not stop recording that was not stored")
+		}
+	}
+
+	if err == sql.ErrNoRows {
+		r.log(ctx).Info().Int64("Channel ID", channelID).Str("sid", recorder.SID).Msg("Stopped recording that was cancelled while starting")
+		return "", errRecordingCancelled
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Str("sid", recorder.SID).Msg("Updating database for recording failed")
+		if err := r.Repos.Channels.ReleaseRecording(context.Background(), channelID); err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not release channel for recording")
+		}
+		return "", errInternalServer
+	}
+
+	services.PublishRecordingUpdate(r.PubSub, channelID, services.RecordingStarted, recorder.SID)
+	return recorder.SID, nil
+}
+
+// storeRecording stores the recording that was started on a channel that was claimed for it. It returns
+// sql.ErrNoRows when the claim is gone
+func (r *Resolver) storeRecording(ctx context.Context, channelID int64, recorder *utils.Recorder) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	channels := r.Repos.WithTx(tx).Channels
+	err = channels.SetRecording(ctx, &models.Channel{
+		ID:                 channelID,
+		RecordingUID:       sql.NullInt32{Int32: recorder.UID, Valid: true},
+		RecordingRID:       sql.NullString{String: recorder.RID, Valid: true},
+		RecordingSID:       sql.NullString{String: recorder.SID, Valid: true},
+		RecordingMode:      recorder.Mode,
+		RecordingStatus:    sql.NullString{String: services.RecordingStarted, Valid: true},
+		RecordingStartedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.DB.Postgres() {
+		err = services.QueueChannelEvent(ctx, tx, recorder.Channel, models.WebhookRecordingStarted, map[string]interface{}{"sid": recorder.SID, "mode": recorder.Mode})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// endChannel stops the recording running on the channel and marks the channel as ended. The recording lock
+// keeps a recording from being started while the meeting is ending
+func (r *Resolver) endChannel(ctx context.Context, channelID int64) error {
+	var stoppedSID string
+	err := r.DB.WithAdvisoryLock(ctx, models.LockRecording, channelID, func(tx *sqlx.Tx) error {
+		channels := r.Repos.WithTx(tx).Channels
+		current, err := channels.ByID(ctx, channelID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not fetch channel")
+			return errInternalServer
+		}
+
+		if current.RecordingSID.Valid {
+			recorder, err := r.recorderFor(ctx, current)
+			if err != nil {
+				return err
+			}
+
+			err = r.Recorder.Stop(recorder)
+			// Recordings that Agora already stopped, like after they ran into an error, only have to be cleared
+			if err != nil && err != utils.ErrRecordingNotFound {
+				r.log(ctx).Error().Err(err).Msg("Stop recording failed")
+				return agoraError(err)
+			}
+
+			err = services.MeterRecording(ctx, tx, current.RecordingSID.String)
+			if err != nil {
+				r.log(ctx).Error().Err(err).Str("sid", current.RecordingSID.String).Msg("Could not meter recording")
+			}
+			stoppedSID = current.RecordingSID.String
+		}
+
+		err = channels.End(ctx, channelID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Ending meeting failed")
+			return errInternalServer
+		}
+
+		return nil
+	})
+	if apierror.CodeOf(err) != "" {
+		return err
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelID).Msg("Could not lock channel to end meeting")
+		return errInternalServer
+	}
+
+	if stoppedSID != "" {
+		services.PublishRecordingUpdate(r.PubSub, channelID, services.RecordingStopped, stoppedSID)
+	}
+
+	return nil
+}
+
+// recorderFor creates a Recorder attached to the recording that is running on the channel
+func (r *Resolver) recorderFor(ctx context.Context, channelData *models.Channel) (*utils.Recorder, error) {
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.Recorder{
+		Project: project,
+		Logger:  r.Logger,
+		Client:  r.Agora,
+		Channel: channelData.ChannelName,
+		UID:     channelData.RecordingUID.Int32,
+		RID:     channelData.RecordingRID.String,
+		SID:     channelData.RecordingSID.String,
+		Mode:    channelData.RecordingMode,
+		Area:    utils.ChannelArea(channelData),
+		Context: ctx,
+	}, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+var hexColor = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+// errInvalidBackgroundColor is returned for recording background colors that are not #RRGGBB
+var errInvalidBackgroundColor = apierror.New(apierror.CodeBadRequest, "Invalid recording background color")
+
+// recordingTitle returns the prefix used for recorded files, preferring the name of the user who started the recording
+func recordingTitle(authUser *models.UserAccount, channelTitle string) string {
+	var title string
+	if authUser == nil || !authUser.UserName.Valid || authUser.UserName.String == "" {
+		title = channelTitle
+	} else {
+		title = authUser.UserName.String
+	}
+
+	return utils.FirstN(nonAlphanumeric.ReplaceAllString(title, ""), 100)
+}
+
+// mixedVideoLayout maps a layout from the schema onto the layout code used by cloud recording
+func mixedVideoLayout(layout models.RecordingLayout) (int, error) {
+	switch layout {
+	case models.RecordingLayoutFloating:
+		return utils.LayoutFloating, nil
+	case models.RecordingLayoutGrid:
+		return utils.LayoutBestFit, nil
+	case models.RecordingLayoutSpotlight:
+		return utils.LayoutVertical, nil
+	default:
+		return 0, errBadRequest
+	}
+}
+
+// transcodingConfig applies the quality overrides requested for a recording on top of the deployment defaults
+func transcodingConfig(quality *models.RecordingQualityInput) (utils.TranscodingConfig, error) {
+	config := utils.DefaultTranscodingConfig()
+	if quality == nil {
+		return config, nil
+	}
+
+	if quality.Height != nil {
+		config.Height = *quality.Height
+	}
+
+	if quality.Width != nil {
+		config.Width = *quality.Width
+	}
+
+	if quality.Bitrate != nil {
+		config.Bitrate = *quality.Bitrate
+	}
+
+	if quality.Fps != nil {
+		config.Fps = *quality.Fps
+	}
+
+	if quality.Layout != nil {
+		layout, err := mixedVideoLayout(*quality.Layout)
+		if err != nil {
+			return config, err
+		}
+		config.MixedVideoLayout = layout
+	}
+
+	if quality.BackgroundColor != nil {
+		config.BackgroundColor = *quality.BackgroundColor
+	}
+
+	if config.Height <= 0 || config.Width <= 0 || config.Height*config.Width > 1920*1080 {
+		return config, apierror.New(apierror.CodeBadRequest, "Invalid recording resolution")
+	}
+
+	if config.Fps <= 0 || config.Fps > 30 || config.Bitrate <= 0 {
+		return config, apierror.New(apierror.CodeBadRequest, "Invalid recording frame rate or bitrate")
+	}
+
+	if !hexColor.MatchString(config.BackgroundColor) {
+		return config, errInvalidBackgroundColor
+	}
+
+	return config, nil
+}
@@ -0,0 +1,242 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+//go:build sqlite
+// +build sqlite
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// startWithMock starts a recording in mode on the channel of the host passphrase with the mock recorder
+func startWithMock(t *testing.T, r *Resolver, passphrase string, mode string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		t.Fatalf("could not get channel: %v", err)
+	}
+
+	sid, err := r.startRecording(ctx, channelData.ID, mode, func() (*utils.Recorder, error) {
+		recorder := &utils.Recorder{Channel: channelData.ChannelName, Mode: mode, Context: ctx}
+		if err := r.Recorder.Acquire(recorder); err != nil {
+			return nil, err
+		}
+
+		return recorder, r.Recorder.StartWeb(recorder, "https://example.com", channelData.Title)
+	})
+	if err != nil {
+		t.Fatalf("could not start %s recording: %v", mode, err)
+	}
+
+	return sid
+}
+
+// recordingRunning reports whether the mock recorder still has the recording of sid
+func recordingRunning(r *Resolver, sid string) bool {
+	_, err := r.Recorder.Query(&utils.Recorder{SID: sid})
+	return err == nil
+}
+
+func TestStartRecording(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		// running is the mode of a recording that is started before, if any
+		running  string
+		wantCode apierror.Code
+	}{
+		{name: "host starts recording", passphrase: "host-phrase"},
+		{name: "restart returns running recording", passphrase: "host-phrase", running: "mix"},
+		{name: "viewer cannot record", passphrase: "view-phrase", wantCode: apierror.CodeNotHost},
+		{name: "web recording running", passphrase: "host-phrase", running: "web", wantCode: apierror.CodeRecordingAlreadyActive},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestResolver(t)
+			createTestChannel(t, r, "host-phrase", "view-phrase")
+
+			var runningSID string
+			if test.running != "" {
+				runningSID = startWithMock(t, r, "host-phrase", test.running)
+			}
+
+			sid, err := (&mutationResolver{r}).StartRecordingSession(context.Background(), test.passphrase, nil, nil)
+			if test.wantCode != "" {
+				if apierror.CodeOf(err) != test.wantCode {
+					t.Fatalf("got error %v, want %s", err, test.wantCode)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("could not start recording: %v", err)
+			}
+			if runningSID != "" && sid != runningSID {
+				t.Fatalf("got recording %s, want running recording %s", sid, runningSID)
+			}
+			if !recordingRunning(r, sid) {
+				t.Fatalf("recording %s is not running", sid)
+			}
+
+			channelData, _, err := r.getChannel(context.Background(), "host-phrase")
+			if err != nil {
+				t.Fatalf("could not get channel: %v", err)
+			}
+			if channelData.RecordingSID.String != sid || channelData.RecordingMode != "mix" {
+				t.Fatalf("channel stores %s recording %s, want mix recording %s", channelData.RecordingMode, channelData.RecordingSID.String, sid)
+			}
+		})
+	}
+}
+
+func TestStartRecordingConcurrently(t *testing.T) {
+	r := newTestResolver(t)
+	createTestChannel(t, r, "host-phrase", "view-phrase")
+
+	sids := make([]string, 4)
+	errs := make([]error, len(sids))
+	var wg sync.WaitGroup
+	for i := range sids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sids[i], errs[i] = (&mutationResolver{r}).StartRecordingSession(context.Background(), "host-phrase", nil, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range sids {
+		if errs[i] != nil {
+			t.Fatalf("could not start recording: %v", errs[i])
+		}
+		if sids[i] != sids[0] {
+			t.Fatalf("got recordings %s and %s, want one recording", sids[0], sids[i])
+		}
+	}
+}
+
+func TestStartRecordingReleasesFailedStart(t *testing.T) {
+	r := newTestResolver(t)
+	createTestChannel(t, r, "host-phrase", "view-phrase")
+	ctx := context.Background()
+
+	channelData, _, err := r.getChannel(ctx, "host-phrase")
+	if err != nil {
+		t.Fatalf("could not get channel: %v", err)
+	}
+
+	failed := errors.New("acquire failed")
+	_, err = r.startRecording(ctx, channelData.ID, "mix", func() (*utils.Recorder, error) {
+		return nil, failed
+	})
+	if err != failed {
+		t.Fatalf("got error %v, want %v", err, failed)
+	}
+
+	// A claim that is not released would make the next start wait for the failed one
+	sid, err := (&mutationResolver{r}).StartRecordingSession(ctx, "host-phrase", nil, nil)
+	if err != nil {
+		t.Fatalf("could not start recording after a failed start: %v", err)
+	}
+	if !recordingRunning(r, sid) {
+		t.Fatalf("recording %s is not running", sid)
+	}
+}
+
+func TestStartRecordingCancelledWhileStarting(t *testing.T) {
+	r := newTestResolver(t)
+	createTestChannel(t, r, "host-phrase", "view-phrase")
+	ctx := context.Background()
+
+	channelData, _, err := r.getChannel(ctx, "host-phrase")
+	if err != nil {
+		t.Fatalf("could not get channel: %v", err)
+	}
+
+	var started string
+	_, err = r.startRecording(ctx, channelData.ID, "mix", func() (*utils.Recorder, error) {
+		recorder := &utils.Recorder{Channel: channelData.ChannelName, Mode: "mix", Context: ctx}
+		if err := r.Recorder.Acquire(recorder); err != nil {
+			return nil, err
+		}
+		if err := r.Recorder.Start(recorder, channelData.Title, nil, utils.TranscodingConfig{}); err != nil {
+			return nil, err
+		}
+		started = recorder.SID
+
+		// The meeting ends while Agora is starting the recording
+		return recorder, r.endChannel(ctx, channelData.ID)
+	})
+	if err != errRecordingCancelled {
+		t.Fatalf("got error %v, want %v", err, errRecordingCancelled)
+	}
+	if recordingRunning(r, started) {
+		t.Fatalf("recording %s of an ended meeting is still running", started)
+	}
+}
+
+func TestStopRecording(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		started    bool
+		wantCode   apierror.Code
+	}{
+		{name: "host stops recording", passphrase: "host-phrase", started: true},
+		{name: "no recording", passphrase: "host-phrase", wantCode: apierror.CodeRecordingNotActive},
+		{name: "viewer cannot stop", passphrase: "view-phrase", started: true, wantCode: apierror.CodeNotHost},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestResolver(t)
+			createTestChannel(t, r, "host-phrase", "view-phrase")
+			ctx := context.Background()
+
+			var sid string
+			if test.started {
+				var err error
+				sid, err = (&mutationResolver{r}).StartRecordingSession(ctx, "host-phrase", nil, nil)
+				if err != nil {
+					t.Fatalf("could not start recording: %v", err)
+				}
+			}
+
+			_, err := (&mutationResolver{r}).StopRecordingSession(ctx, test.passphrase)
+			if test.wantCode != "" {
+				if apierror.CodeOf(err) != test.wantCode {
+					t.Fatalf("got error %v, want %s", err, test.wantCode)
+				}
+				if sid != "" && !recordingRunning(r, sid) {
+					t.Fatalf("recording %s was stopped", sid)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("could not stop recording: %v", err)
+			}
+			if recordingRunning(r, sid) {
+				t.Fatalf("recording %s is still running", sid)
+			}
+		})
+	}
+}
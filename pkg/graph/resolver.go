@@ -13,7 +13,12 @@ package graph
 //go:generate go run github.com/99designs/gqlgen
 
 import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
 	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
 	"github.com/samyak-jain/agora_backend/utils"
 )
 
@@ -23,6 +28,28 @@ import (
 
 // Resolver is used for state management
 type Resolver struct {
-	DB     *models.Database
+	DB *models.Database
+	// Repos runs the statements on channels, users, tokens and recordings
+	Repos  *repository.Repositories
 	Logger *utils.Logger
+	PubSub utils.PubSub
+	// Redis holds signaling state shared between instances and is nil when REDIS_URL is not set
+	Redis *redis.Client
+	// Mailer sends the emails of email sign in and invitations and is nil when no mail provider is configured
+	Mailer utils.Mailer
+	// SMS sends the codes of phone sign in and is nil when SMS_PROVIDER is not set
+	SMS utils.SMSSender
+	// Recorder is the cloud recorder the recordings of channels are made with
+	Recorder utils.CloudRecorder
+	// Agora sends the requests to the Agora REST APIs
+	Agora *utils.AgoraClient
+	// Tokens generates the credentials users join channels with
+	Tokens utils.TokenGenerator
+	// IDs generates the names, passphrases and DTMFs of channels
+	IDs utils.IDGenerator
+}
+
+// log returns the logger of the request ctx belongs to, so that entries can be correlated with the request
+func (r *Resolver) log(ctx context.Context) *utils.Logger {
+	return middleware.GetLogger(ctx, r.Logger)
 }
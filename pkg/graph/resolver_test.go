@@ -0,0 +1,70 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+//go:build sqlite
+// +build sqlite
+
+package graph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/samyak-jain/agora_backend/migrations"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// newTestResolver returns a resolver on a migrated SQLite database that records with the in-memory cloud recorder.
+// Resolvers are called directly, so the checks of the schema for features SQLite does not support are skipped
+func newTestResolver(t *testing.T) *Resolver {
+	t.Helper()
+	utils.SetDefaults()
+
+	// Every connection to an in-memory database opens a database of its own, so the database is kept in a file
+	db, err := models.CreateDB(models.DBConfig{
+		Driver: models.DriverSQLite,
+		URL:    filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("could not open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := utils.Configure(utils.Config{})
+	err = migrations.Up(logger, db)
+	if err != nil {
+		t.Fatalf("could not migrate database: %v", err)
+	}
+
+	return &Resolver{
+		DB:       db,
+		Repos:    repository.New(db),
+		Logger:   logger,
+		PubSub:   utils.NewPubSub(),
+		Recorder: utils.NewMockCloudRecorder(),
+		Agora:    utils.NewAgoraClient(),
+		Tokens:   utils.CertificateTokenGenerator{},
+		IDs:      utils.RandomIDGenerator{},
+	}
+}
+
+// createTestChannel creates a channel with the given host and view passphrases
+func createTestChannel(t *testing.T, r *Resolver, hostPhrase string, viewPhrase string) {
+	t.Helper()
+
+	pstn := false
+	_, err := (&mutationResolver{r}).CreateChannel(context.Background(), "Test", "", &pstn, nil, nil, nil, &hostPhrase, &viewPhrase, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("could not create channel: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// errMissingRole is returned when a user uses an operation that requires a role it has not been granted
+var errMissingRole = apierror.New(apierror.CodeForbidden, "Unauthorised to use admin operations")
+
+// hasRole reports whether a user has a role. Users listed by email in ADMIN_EMAILS are administrators regardless of
+// their roles, so that the first administrator can grant roles to others
+func hasRole(user *models.UserAccount, role models.Role) bool {
+	if user.HasRole(role) {
+		return true
+	}
+
+	if role == models.RoleAdmin {
+		for _, email := range viper.GetStringSlice("ADMIN_EMAILS") {
+			if email == user.Email {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasRole implements the hasRole directive, which only resolves a field for signed in users that have a role
+func (r *Resolver) HasRole(ctx context.Context, obj interface{}, next graphql.Resolver, role models.Role) (interface{}, error) {
+	user, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	if !hasRole(user, role) {
+		r.log(ctx).Debug().Int64("user", user.ID).Str("role", role.String()).Msg("Missing role")
+		return nil, errMissingRole
+	}
+
+	return next(ctx)
+}
@@ -16,13 +16,13 @@ package graph
 import (
 	"context"
 	"database/sql"
-	"errors"
-	"regexp"
+	"encoding/json"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/rs/zerolog/log"
 	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
 	"github.com/samyak-jain/agora_backend/pkg/middleware"
 	"github.com/samyak-jain/agora_backend/pkg/models"
 	"github.com/samyak-jain/agora_backend/services"
@@ -30,58 +30,106 @@ import (
 	"github.com/spf13/viper"
 )
 
-func (r *mutationResolver) CreateChannel(ctx context.Context, title string, backendURL string, enablePstn *bool) (*models.ShareResponse, error) {
-	r.Logger.Info().Str("mutation", "CreateChannel").Str("title", title).Msg("Creating Channel")
+func (r *mutationResolver) CreateChannel(ctx context.Context, title string, backendURL string, enablePstn *bool, storage *models.ChannelStorageInput, tokenExpiry *int, allowViewersToPublish *bool, customHostPhrase *string, customViewPhrase *string, startsAt *time.Time, endsAt *time.Time, enableWaitingRoom *bool, maxParticipants *int, country *string, enableWhiteboard *bool, organizationID *string, area *models.AgoraArea) (*models.ShareResponse, error) {
+	r.log(ctx).Info().Str("mutation", "CreateChannel").Str("title", title).Msg("Creating Channel")
 	if enablePstn != nil {
-		r.Logger.Info().Bool("enablePstn", *enablePstn).Msg("")
+		r.log(ctx).Info().Bool("enablePstn", *enablePstn).Msg("")
 	}
 
-	if viper.GetBool("ENABLE_OAUTH") {
-		_, err := middleware.GetUserFromContext(ctx)
-		if err != nil {
-			r.Logger.Debug().Msg("Invalid Token")
-			return nil, errors.New("Invalid Token")
-		}
+	owner, err := r.authorizedUser(ctx, models.APIKeyScopeCreateChannel)
+	if err == errMissingScope || viper.GetBool("ENABLE_OAUTH") && err != nil {
+		r.log(ctx).Debug().Err(err).Msg("Unauthorized to create channel")
+		return nil, err
 	}
 
 	var pstnResponse *models.Pstn
+	var sipURI string
 	var newChannel *models.Channel
 
-	hostPhrase, err := utils.GenerateUUID()
-	if err != nil {
-		r.Logger.Error().Err(err).Msg("Host Phrase generation failed")
-		return nil, errInternalServer
+	var hostPhrase, viewPhrase string
+	if customHostPhrase != nil {
+		if !validCustomPassphrase(*customHostPhrase) {
+			r.log(ctx).Debug().Str("customHostPhrase", *customHostPhrase).Msg("Invalid custom host passphrase")
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid host passphrase")
+		}
+		hostPhrase = *customHostPhrase
+	} else {
+		hostPhrase, err = r.IDs.GenerateUUID()
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Host Phrase generation failed")
+			return nil, errInternalServer
+		}
 	}
 
-	viewPhrase, err := utils.GenerateUUID()
-	if err != nil {
-		r.Logger.Error().Err(err).Msg("View Phrase generation failed")
-		return nil, errInternalServer
+	if customViewPhrase != nil {
+		if !validCustomPassphrase(*customViewPhrase) {
+			r.log(ctx).Debug().Str("customViewPhrase", *customViewPhrase).Msg("Invalid custom view passphrase")
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid view passphrase")
+		}
+		viewPhrase = *customViewPhrase
+	} else {
+		viewPhrase, err = r.IDs.GenerateUUID()
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("View Phrase generation failed")
+			return nil, errInternalServer
+		}
+	}
+
+	if hostPhrase == viewPhrase {
+		return nil, apierror.New(apierror.CodeBadRequest, "Host and view passphrases must be different")
 	}
 
-	channelName, err := utils.GenerateUUID()
+	channelName, err := r.IDs.GenerateUUID()
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Channel Name generation failed")
+		r.log(ctx).Error().Err(err).Msg("Channel Name generation failed")
 		return nil, errInternalServer
 	}
 
 	channel := strings.ReplaceAll(channelName, "-", "")
 
-	secretGen, err := utils.GenerateUUID()
+	secretGen, err := r.IDs.GenerateUUID()
 	if err != nil {
 		return nil, err
 	}
 	secret := strings.ReplaceAll(secretGen, "-", "")
-	dtmfResult, err := utils.GenerateDTMF()
+	dtmfResult, err := r.uniqueDTMF(ctx)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("DTMF generation failed")
-		return nil, errInternalServer
+		return nil, err
+	}
+
+	if endsAt != nil && (startsAt == nil || !endsAt.After(*startsAt)) {
+		r.log(ctx).Debug().Interface("startsAt", startsAt).Interface("endsAt", endsAt).Msg("Invalid meeting schedule")
+		return nil, apierror.New(apierror.CodeBadRequest, "Meeting must end after it starts")
+	}
+
+	if maxParticipants != nil && *maxParticipants <= 0 {
+		r.log(ctx).Debug().Int("maxParticipants", *maxParticipants).Msg("Invalid participant limit")
+		return nil, apierror.New(apierror.CodeBadRequest, "Participant limit must be at least 1")
+	}
+
+	if tokenExpiry != nil && !utils.ValidTokenExpiry(*tokenExpiry) {
+		r.log(ctx).Debug().Int("tokenExpiry", *tokenExpiry).Msg("Invalid token expiry")
+		return nil, apierror.New(apierror.CodeBadRequest, "Token expiry must be between 1 and 86400 seconds")
+	}
+
+	if !r.DB.Postgres() && (*enablePstn || storage != nil || enableWaitingRoom != nil && *enableWaitingRoom || organizationID != nil) {
+		r.log(ctx).Debug().Str("driver", r.DB.DriverName()).Msg("Channel feature needs Postgres")
+		return nil, errNeedsPostgres
+	}
+
+	var channelStorage *utils.StorageSettings
+	if storage != nil {
+		channelStorage, err = storageSettings(storage)
+		if err != nil {
+			r.log(ctx).Debug().Err(err).Str("provider", storage.Provider.String()).Str("bucket", storage.Bucket).Msg("Invalid channel storage")
+			return nil, err
+		}
 	}
 
 	if *enablePstn {
 		if len(backendURL) <= 0 {
-			r.Logger.Error().Str("backend", backendURL).Msg("Backend URL is empty")
-			return nil, errors.New("Backend URL is empty")
+			r.log(ctx).Error().Str("backend", backendURL).Msg("Backend URL is empty")
+			return nil, apierror.New(apierror.CodeBadRequest, "Backend URL is empty")
 		}
 
 		// TODO: Refactor to remove duplicate code
@@ -93,24 +141,28 @@ func (r *mutationResolver) CreateChannel(ctx context.Context, title string, back
 
 		finalBackendURL := string(runeBackendURL)
 
-		var pstnNumber string
-		if viper.GetString("PSTN_NUMBER") == "" {
-			pstnNumber = "(800) 309-2350"
-		} else {
-			pstnNumber = viper.GetString("PSTN_NUMBER")
-		}
-
 		services.CreateBridge(r.Logger, *dtmfResult, finalBackendURL)
-		pstnResponse = &models.Pstn{
-			Number: pstnNumber,
-			Dtmf:   *dtmfResult,
-		}
+		pstnResponse = r.pstnDetails(ctx, *dtmfResult, country)
+		sipURI = services.SIPURI(*dtmfResult)
 
-		r.Logger.Info().Str("DTMF", *dtmfResult).Msg("PSTN PIN")
+		r.log(ctx).Info().Str("DTMF", *dtmfResult).Msg("PSTN PIN")
 	} else {
 		pstnResponse = nil
 	}
 
+	var whiteboardRoom string
+	if enableWhiteboard != nil && *enableWhiteboard {
+		whiteboardRoom, err = utils.CreateWhiteboardRoom(ctx)
+		if err == utils.ErrWhiteboardNotConfigured {
+			return nil, err
+		}
+
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not create whiteboard room")
+			return nil, errInternalServer
+		}
+	}
+
 	newChannel = &models.Channel{
 		Title:            title,
 		ChannelName:      channel,
@@ -118,122 +170,232 @@ func (r *mutationResolver) CreateChannel(ctx context.Context, title string, back
 		HostPassphrase:   hostPhrase,
 		ViewerPassphrase: viewPhrase,
 		DTMF:             *dtmfResult,
+		// Viewers could always publish before this flag existed, so it stays allowed unless disabled
+		AllowViewersToPublish: allowViewersToPublish == nil || *allowViewersToPublish,
+		WaitingRoom:           enableWaitingRoom != nil && *enableWaitingRoom,
+	}
+
+	if startsAt != nil {
+		newChannel.StartsAt = sql.NullTime{Time: *startsAt, Valid: true}
+	}
+
+	if endsAt != nil {
+		newChannel.EndsAt = sql.NullTime{Time: *endsAt, Valid: true}
+	}
+
+	if sipURI != "" {
+		newChannel.SIPURI = sql.NullString{String: sipURI, Valid: true}
+	}
+
+	if whiteboardRoom != "" {
+		newChannel.WhiteboardRoomUUID = sql.NullString{String: whiteboardRoom, Valid: true}
+	}
+
+	if owner != nil {
+		newChannel.OwnerID = sql.NullInt64{Int64: owner.ID, Valid: true}
+	}
+
+	if organizationID != nil {
+		if owner == nil {
+			r.log(ctx).Debug().Str("organizationId", *organizationID).Msg("Sign in to create organization channels")
+			return nil, errInvalidToken
+		}
+
+		id, _, err := r.memberOf(ctx, r.DB, owner, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+		newChannel.OrganizationID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	if maxParticipants != nil {
+		newChannel.MaxParticipants = sql.NullInt32{Int32: int32(*maxParticipants), Valid: true}
+	}
+
+	if tokenExpiry != nil {
+		newChannel.TokenExpirySeconds = sql.NullInt32{Int32: int32(*tokenExpiry), Valid: true}
+	}
+
+	if area != nil {
+		newChannel.Area = sql.NullString{String: area.String(), Valid: true}
+	}
+
+	// Owners that used up their participant minutes cannot create channels for more participants until the next month
+	for _, metric := range []models.UsageMetric{models.UsageChannels, models.UsageParticipantMinutes} {
+		err = r.checkQuota(ctx, r.DB, services.ChannelUsageSubject(newChannel), metric)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	channels := r.Repos.WithTx(tx).Channels
+	err = channels.Create(ctx, newChannel)
+	if models.IsUniqueViolation(err) {
+		r.log(ctx).Debug().Err(err).Str("host", hostPhrase).Str("view", viewPhrase).Msg("Custom passphrase already taken")
+		return nil, errPassphraseTaken
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Interface("channel details", newChannel).Msg("Adding new channel to DB Failed")
+		return nil, errInternalServer
+	}
+
+	// Usage and webhooks are only kept on Postgres
+	if r.DB.Postgres() {
+		err = services.MeterChannel(ctx, tx, newChannel)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", newChannel.ID).Msg("Could not meter channel")
+			return nil, errInternalServer
+		}
+
+		err = services.QueueChannelEvent(ctx, tx, newChannel.ChannelName, models.WebhookChannelCreated, nil)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", newChannel.ID).Msg("Could not queue channel event")
+			return nil, errInternalServer
+		}
+	}
+
+	passphrases := []models.ChannelPassphrase{
+		{ChannelID: newChannel.ID, Passphrase: hostPhrase, Name: "Host", Role: models.PassphraseTypeHost},
+		{ChannelID: newChannel.ID, Passphrase: viewPhrase, Name: "Viewer", Role: models.PassphraseTypeViewer},
+	}
+
+	err = channels.AddPassphrases(ctx, passphrases...)
+	if models.IsUniqueViolation(err) {
+		r.log(ctx).Debug().Err(err).Str("host", hostPhrase).Str("view", viewPhrase).Msg("Custom passphrase already taken")
+		return nil, errPassphraseTaken
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", newChannel.ID).Msg("Adding channel passphrases to DB Failed")
+		return nil, errInternalServer
+	}
+
+	shortLinks, err := r.shortLinks(ctx, tx, newChannel.ID, &hostPhrase, viewPhrase)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", newChannel.ID).Msg("Adding channel short links to DB Failed")
+		return nil, errInternalServer
 	}
 
-	_, err = r.DB.NamedExec("INSERT INTO channels (title, channel_name, channel_secret, host_passphrase, viewer_passphrase, dtmf) VALUES (:title, :channel_name, :channel_secret, :host_passphrase, :viewer_passphrase, :dtmf)", newChannel)
+	if channelStorage != nil {
+		err = services.SaveChannelStorage(ctx, tx, newChannel.ID, *channelStorage)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", newChannel.ID).Msg("Adding channel storage to DB Failed")
+			return nil, errInternalServer
+		}
+	}
 
+	err = tx.Commit()
 	if err != nil {
-		r.Logger.Error().Err(err).Interface("channel details", newChannel).Msg("Adding new channel to DB Failed")
+		r.log(ctx).Error().Err(err).Interface("channel details", newChannel).Msg("Adding new channel to DB Failed")
 		return nil, errInternalServer
 	}
 
+	middleware.SetAuditChannel(ctx, newChannel.ID)
+
 	return &models.ShareResponse{
 		Passphrase: &models.Passphrase{
 			Host: &hostPhrase,
 			View: viewPhrase,
 		},
-		Title:   title,
-		Channel: channel,
-		Pstn:    pstnResponse,
+		Title:      title,
+		Channel:    channel,
+		Pstn:       pstnResponse,
+		Sip:        sipDetails(newChannel),
+		ShortLinks: shortLinks,
+		Metadata:   map[string]interface{}{},
 	}, nil
 }
 
 func (r *mutationResolver) MutePstn(ctx context.Context, uid int, passphrase string, mute *bool) (*models.UIDMuteState, error) {
-	r.Logger.Info().Str("mutation", "MutePSTN").Int("uid", uid).Str("passphrase", passphrase).Bool("mute", *mute).Msg("Creating Channel")
+	r.log(ctx).Info().Str("mutation", "MutePSTN").Int("uid", uid).Str("passphrase", passphrase).Bool("mute", *mute).Msg("Creating Channel")
 
-	var channelData models.Channel
-
-	if passphrase == "" {
-		return nil, errors.New("Passphrase cannot be empty")
-	}
-
-	err := r.DB.Get(&channelData, "SELECT title, channel_name, channel_secret, host_passphrase, viewer_passphrase, dtmf FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	channelData, host, err := r.getChannel(ctx, passphrase)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return nil, errors.New("Invalid URL")
+		return nil, err
 	}
 
-	if passphrase == channelData.HostPassphrase {
-		if channelData.DTMF == "" {
-			r.Logger.Error().Interface("Channel Data", channelData).Msg("DTMF is empty")
-			return nil, errBadRequest
-		}
-
-		services.MutePSTN(r.Logger, uid, *mute, channelData.DTMF)
+	if !host {
+		r.log(ctx).Error().Interface("Channel Data", channelData).Msg("Passphrase does not have permission to mute")
+		return nil, errBadRequest
+	}
 
-		return &models.UIDMuteState{
-			UID:  uid,
-			Mute: *mute,
-		}, nil
-	} else if passphrase == channelData.ViewerPassphrase {
-		r.Logger.Error().Interface("Channel Data", channelData).Msg("Passphrase does not have permission to mute")
+	if channelData.DTMF == "" {
+		r.log(ctx).Error().Interface("Channel Data", channelData).Msg("DTMF is empty")
 		return nil, errBadRequest
-	} else {
-		r.Logger.Debug().Str("passphrase", passphrase).Msg("Invalid Passphrase; Interal Server Error")
-		return nil, errors.New("Invalid URL")
 	}
-}
 
-func (r *mutationResolver) SetPresenter(ctx context.Context, uid int, passphrase string) (int, error) {
-	r.Logger.Info().Str("mutation", "SetPresenter").Str("passphrase", passphrase).Int("uid", uid).Msg("")
+	services.MutePSTN(r.Logger, uid, *mute, channelData.DTMF)
 
-	if passphrase == "" {
-		return 0, errors.New("Passphrase cannot be empty")
-	}
+	return &models.UIDMuteState{
+		UID:  uid,
+		Mute: *mute,
+	}, nil
+}
 
-	var channelData models.Channel
+func (r *mutationResolver) SetPresenter(ctx context.Context, uid int, passphrase string) (int, error) {
+	r.log(ctx).Info().Str("mutation", "SetPresenter").Str("passphrase", passphrase).Int("uid", uid).Msg("")
 
-	err := r.DB.Get(&channelData, "SELECT id, title, channel_name, channel_secret, host_passphrase, viewer_passphrase, recording_rid, recording_sid, recording_uid FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	channelData, _, err := r.getChannel(ctx, passphrase)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return 0, errors.New("Invalid URL")
+		return 0, err
 	}
 
 	if !channelData.RecordingRID.Valid || !channelData.RecordingSID.Valid || !channelData.RecordingUID.Valid {
-		r.Logger.Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
-		return 0, errors.New("Recording not started")
+		r.log(ctx).Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
+		return 0, errRecordingNotStarted
 	}
 
-	err = utils.ChangeRecordingMode(channelData.ChannelName, int(channelData.RecordingUID.Int32), channelData.RecordingRID.String, channelData.RecordingSID.String, 2, strconv.Itoa(uid), r.Logger)
+	recorder, err := r.recorderFor(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Stop recording failed")
-		return 0, errInternalServer
+		return 0, err
+	}
+
+	err = r.Recorder.ChangeMode(recorder, 2, strconv.Itoa(uid))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Stop recording failed")
+		return 0, agoraError(err)
 	}
 
 	return uid, nil
 }
 
 func (r *mutationResolver) SetNormal(ctx context.Context, passphrase string) (string, error) {
-	r.Logger.Info().Str("mutation", "SetPresenter").Str("passphrase", passphrase).Msg("")
-
-	if passphrase == "" {
-		return "", errors.New("Passphrase cannot be empty")
-	}
-
-	var channelData models.Channel
+	r.log(ctx).Info().Str("mutation", "SetPresenter").Str("passphrase", passphrase).Msg("")
 
-	err := r.DB.Get(&channelData, "SELECT id, title, channel_name, channel_secret, host_passphrase, viewer_passphrase, recording_rid, recording_sid, recording_uid FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	channelData, _, err := r.getChannel(ctx, passphrase)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return "", errors.New("Invalid URL")
+		return "", err
 	}
 
 	if !channelData.RecordingRID.Valid || !channelData.RecordingSID.Valid || !channelData.RecordingUID.Valid {
-		r.Logger.Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
-		return "", errors.New("Recording not started")
+		r.log(ctx).Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
+		return "", errRecordingNotStarted
 	}
 
-	err = utils.ChangeRecordingMode(channelData.ChannelName, int(channelData.RecordingUID.Int32), channelData.RecordingRID.String, channelData.RecordingSID.String, 1, "", r.Logger)
+	recorder, err := r.recorderFor(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Stop recording failed")
-		return "", errInternalServer
+		return "", err
+	}
+
+	err = r.Recorder.ChangeMode(recorder, 1, "")
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Stop recording failed")
+		return "", agoraError(err)
 	}
 
 	return "success", nil
 }
 
 func (r *mutationResolver) UpdateUserName(ctx context.Context, name string) (*models.User, error) {
-	r.Logger.Info().Str("mutation", "UpdateUserName").Str("name", name).Msg("")
+	r.log(ctx).Info().Str("mutation", "UpdateUserName").Str("name", name).Msg("")
 
 	if !viper.GetBool("ENABLE_OAUTH") {
 		return nil, nil
@@ -241,20 +403,13 @@ func (r *mutationResolver) UpdateUserName(ctx context.Context, name string) (*mo
 
 	authUser, err := middleware.GetUserFromContext(ctx)
 	if err != nil {
-		r.Logger.Debug().Msg("Invalid Token")
-		return nil, errors.New("Invalid Token")
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
 	}
 
-	_, err = r.DB.NamedExec("UPDATE users SET user_name = ':name' WHERE identifier = ':ident'", &models.UserAccount{
-		Identifier: authUser.Identifier,
-		UserName: sql.NullString{
-			String: name,
-			Valid:  true,
-		},
-	})
-
+	err = r.Repos.Users.UpdateName(ctx, authUser.Identifier, name)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("identifier", authUser.Identifier).Msg("Username update failed")
+		r.log(ctx).Error().Err(err).Str("identifier", authUser.Identifier).Msg("Username update failed")
 		return nil, errInternalServer
 	}
 
@@ -263,332 +418,2216 @@ func (r *mutationResolver) UpdateUserName(ctx context.Context, name string) (*mo
 	}, nil
 }
 
-func (r *mutationResolver) StartRecordingSession(ctx context.Context, passphrase string, secret *string) (string, error) {
-	r.Logger.Info().Str("mutation", "StartRecordingSession").Str("passphrase", passphrase).Msg("")
+func (r *mutationResolver) StartRecordingSession(ctx context.Context, passphrase string, secret *string, recordingQuality *models.RecordingQualityInput) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StartRecordingSession").Str("passphrase", passphrase).Msg("")
 	if secret != nil {
-		r.Logger.Info().Str("secret", *secret).Msg("")
+		r.log(ctx).Info().Str("secret", *secret).Msg("")
+	}
+
+	authUser, err := r.authorizedUser(ctx, models.APIKeyScopeStartRecording)
+	if err == errMissingScope || viper.GetBool("ENABLE_OAUTH") && err != nil {
+		r.log(ctx).Debug().Err(err).Msg("Unauthorized to start recording")
+		return "", err
+	}
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
 	}
 
-	var channelData models.Channel
-	var host bool
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
+		return "", errNotHost("record channel")
+	}
 
-	var authUser *models.UserAccount
-	var err error
-	if viper.GetBool("ENABLE_OAUTH") {
-		authUser, err = middleware.GetUserFromContext(ctx)
+	finalTitle := recordingTitle(authUser, channelData.Title)
+
+	if recordingQuality == nil && channelData.RecordingQuality.Valid {
+		err = channelData.RecordingQuality.Unmarshal(&recordingQuality)
 		if err != nil {
-			r.Logger.Debug().Msg("Invalid Token")
-			return "", errors.New("Invalid Token")
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not decode recording quality")
+			return "", errInternalServer
 		}
 	}
 
-	if passphrase == "" {
-		return "", errors.New("Passphrase cannot be empty")
+	transcoding, err := transcodingConfig(recordingQuality)
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Interface("Recording Quality", recordingQuality).Msg("Invalid recording quality")
+		return "", err
 	}
 
-	err = r.DB.Get(&channelData, "SELECT id, title, channel_name, channel_secret, host_passphrase, viewer_passphrase FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	storage, err := r.channelStorage(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return "", errors.New("Invalid URL")
+		return "", err
 	}
 
-	if passphrase == channelData.HostPassphrase {
-		host = true
-	} else if passphrase == channelData.ViewerPassphrase {
-		host = false
-	} else {
-		r.Logger.Debug().Str("passphrase", passphrase).Msg("Invalid Passphrase; Interal Server Error")
-		return "", errors.New("Invalid URL")
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return "", err
 	}
 
-	if !host {
-		r.Logger.Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
-		return "", errors.New("Unauthorised to record channel")
+	return r.startRecording(ctx, channelData.ID, "mix", func() (*utils.Recorder, error) {
+		recorder := &utils.Recorder{
+			Project: project,
+			Logger:  r.Logger,
+			Client:  r.Agora,
+			Channel: channelData.ChannelName,
+			Mode:    "mix",
+			Storage: storage,
+			Area:    utils.ChannelArea(channelData),
+			Context: ctx,
+		}
+
+		err := r.Recorder.Acquire(recorder)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Acquire Failed")
+			return nil, agoraError(err)
+		}
+
+		err = r.Recorder.Start(recorder, finalTitle, secret, transcoding)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Start Failed")
+			return nil, agoraError(err)
+		}
+
+		return recorder, nil
+	})
+}
+
+func (r *mutationResolver) StartWebRecording(ctx context.Context, url string, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StartWebRecording").Str("url", url).Str("passphrase", passphrase).Msg("")
+
+	authUser, err := r.authorizedUser(ctx, models.APIKeyScopeStartRecording)
+	if err == errMissingScope || viper.GetBool("ENABLE_OAUTH") && err != nil {
+		r.log(ctx).Debug().Err(err).Msg("Unauthorized to start recording")
+		return "", err
 	}
 
-	var title string
-	if authUser == nil || !authUser.UserName.Valid || authUser.UserName.String == "" {
-		title = channelData.Title
-	} else {
-		title = authUser.UserName.String
+	if !isWebURL(url) {
+		r.log(ctx).Debug().Str("url", url).Msg("Invalid web recording URL")
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid recording URL")
 	}
 
-	reg, err := regexp.Compile("[^a-zA-Z0-9]+")
+	channelData, host, err := r.getChannel(ctx, passphrase)
 	if err != nil {
-		log.Error().Err(err).Msg("Regex Compilation failed")
+		return "", err
 	}
 
-	finalTitle := utils.FirstN(reg.ReplaceAllString(title, ""), 100)
-
-	recorder := &utils.Recorder{
-		Logger: r.Logger,
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
+		return "", errNotHost("record channel")
 	}
-	recorder.Channel = channelData.ChannelName
 
-	err = recorder.Acquire()
+	storage, err := r.channelStorage(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Acquire Failed")
-		return "", errInternalServer
+		return "", err
 	}
 
-	err = recorder.Start(finalTitle, secret)
+	project, err := r.channelProject(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Start Failed")
-		return "", errInternalServer
-	}
-	recordDetails := models.Channel{
-		ID:           channelData.ID,
-		RecordingUID: sql.NullInt32{Int32: recorder.UID, Valid: true},
-		RecordingRID: sql.NullString{String: recorder.RID, Valid: true},
-		RecordingSID: sql.NullString{String: recorder.SID, Valid: true},
+		return "", err
 	}
 
-	_, err = r.DB.NamedExec("UPDATE channels SET (recording_uid, recording_sid, recording_rid) = (:recording_uid, :recording_sid, :recording_rid) WHERE id = :id", &recordDetails)
-	if err != nil {
-		r.Logger.Error().Err(err).Msg("Updating database for recording failed")
-		return "", errInternalServer
-	}
+	return r.startRecording(ctx, channelData.ID, "web", func() (*utils.Recorder, error) {
+		recorder := &utils.Recorder{
+			Project: project,
+			Logger:  r.Logger,
+			Client:  r.Agora,
+			Channel: channelData.ChannelName,
+			Mode:    "web",
+			Storage: storage,
+			Area:    utils.ChannelArea(channelData),
+			Context: ctx,
+		}
 
-	return "success", nil
-}
+		err := r.Recorder.Acquire(recorder)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Acquire Failed")
+			return nil, agoraError(err)
+		}
 
-func (r *mutationResolver) StopRecordingSession(ctx context.Context, passphrase string) (string, error) {
-	r.Logger.Info().Str("mutation", "StopRecordingSession").Str("passphrase", passphrase).Msg("")
+		err = r.Recorder.StartWeb(recorder, url, recordingTitle(authUser, channelData.Title))
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Start Web Recording Failed")
+			return nil, agoraError(err)
+		}
 
-	var channelData models.Channel
-	var host bool
+		return recorder, nil
+	})
+}
 
-	if passphrase == "" {
-		return "", errors.New("Passphrase cannot be empty")
-	}
+func (r *mutationResolver) StopRecordingSession(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StopRecordingSession").Str("passphrase", passphrase).Msg("")
 
-	err := r.DB.Get(&channelData, "SELECT id, title, channel_name, channel_secret, host_passphrase, viewer_passphrase, recording_rid, recording_sid, recording_uid FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	channelData, host, err := r.getChannel(ctx, passphrase)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return "", errors.New("Invalid URL")
+		return "", err
 	}
 
-	if passphrase == channelData.HostPassphrase {
-		host = true
-	} else if passphrase == channelData.ViewerPassphrase {
-		host = false
-	} else {
-		r.Logger.Debug().Str("passphrase", passphrase).Msg("Invalid Passphrase; Interal Server Error")
-		return "", errors.New("Invalid URL")
-	}
 	if !host {
-		r.Logger.Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
-		return "", errors.New("Unauthorised to record channel")
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to record channel")
+		return "", errNotHost("record channel")
 	}
 
 	if !channelData.RecordingRID.Valid || !channelData.RecordingSID.Valid || !channelData.RecordingUID.Valid {
-		r.Logger.Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
-		return "", errors.New("Recording not started")
+		r.log(ctx).Debug().Interface("Channel Data", channelData).Msg("RID or SID or UID not in DB")
+		return "", errRecordingNotStarted
+	}
+
+	recorder, err := r.recorderFor(ctx, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Recorder.Stop(recorder)
+	// Recordings that Agora no longer knows about are cleared by the reconciler
+	if err != nil && err != utils.ErrRecordingNotFound {
+		r.log(ctx).Error().Err(err).Msg("Stop recording failed")
+		return "", agoraError(err)
 	}
 
-	err = utils.Stop(channelData.ChannelName, int(channelData.RecordingUID.Int32), channelData.RecordingRID.String, channelData.RecordingSID.String, r.Logger)
+	err = r.Repos.Channels.SetRecordingPaused(ctx, channelData.ID, false)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Stop recording failed")
+		r.log(ctx).Error().Err(err).Msg("Resetting paused recording state failed")
 		return "", errInternalServer
 	}
 
+	services.PublishRecordingUpdate(r.PubSub, channelData.ID, services.RecordingStopped, recorder.SID)
 	return "success", nil
 }
 
-func (r *mutationResolver) LogoutSession(ctx context.Context, token string) ([]string, error) {
-	r.Logger.Info().Str("mutation", "LogoutSession").Str("token", token).Msg("")
+func (r *mutationResolver) PauseRecordingSession(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "PauseRecordingSession").Str("passphrase", passphrase).Msg("")
 
-	authUser, err := middleware.GetUserFromContext(ctx)
+	channelData, err := r.getRecordingChannel(ctx, passphrase)
 	if err != nil {
-		r.Logger.Debug().Msg("Invalid Token")
-		return nil, errors.New("Invalid Token")
+		return "", err
 	}
 
-	res, err := r.DB.NamedExec("DELETE FROM tokens WHERE token_id = :token_id AND user_id = :user_id", &models.Token{
-		TokenID: token,
-		UserID:  authUser.ID,
-	})
-
-	if err != nil {
-		r.Logger.Error().Err(err).Msg("Could not delete token from database")
-		return nil, errInternalServer
+	if channelData.RecordingPaused {
+		r.log(ctx).Debug().Str("channel", channelData.ChannelName).Msg("Recording already paused")
+		return "", apierror.New(apierror.CodeBadRequest, "Recording already paused")
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	recorder, err := r.recorderFor(ctx, channelData)
 	if err != nil {
-		r.Logger.Error().Str("Token", token).Int64("User ID", authUser.ID).Msg("Could not get Rows Affected by DELETE in database")
-		return nil, errInternalServer
-	}
-
-	if rowsAffected < 1 {
-		r.Logger.Debug().Str("Sub", authUser.Identifier).Msg("Token does not exist")
-		return nil, errBadRequest
+		return "", err
 	}
 
-	tokens := []models.Token{}
-	string_token_slice := []string{}
-	err = r.DB.Select(&tokens, "SELECT * FROM tokens WHERE user_id = $1", authUser.ID)
+	err = r.Recorder.Pause(recorder)
 	if err != nil {
-		r.Logger.Error().Err(err).Int64("User ID", authUser.ID).Msg("Could not get tokens for this user ID")
-		return nil, errInternalServer
+		r.log(ctx).Error().Err(err).Msg("Pause recording failed")
+		return "", agoraError(err)
 	}
 
-	for _, v := range tokens {
-		string_token_slice = append(string_token_slice, v.TokenID)
+	err = r.Repos.Channels.SetRecordingPaused(ctx, channelData.ID, true)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Updating database for paused recording failed")
+		return "", errInternalServer
 	}
 
-	return string_token_slice, nil
+	return "success", nil
 }
 
-func (r *queryResolver) JoinChannel(ctx context.Context, passphrase string) (*models.Session, error) {
-	r.Logger.Info().Str("query", "JoinChannel").Str("passphrase", passphrase).Msg("")
-
-	var channelData models.Channel
-	var host bool
+func (r *mutationResolver) ResumeRecordingSession(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "ResumeRecordingSession").Str("passphrase", passphrase).Msg("")
 
-	if passphrase == "" {
-		return nil, errors.New("Passphrase cannot be empty")
+	channelData, err := r.getRecordingChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
 	}
 
-	err := r.DB.Get(&channelData, "SELECT title, channel_name, channel_secret, host_passphrase, viewer_passphrase FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
-	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return nil, errors.New("Invalid URL")
+	if !channelData.RecordingPaused {
+		r.log(ctx).Debug().Str("channel", channelData.ChannelName).Msg("Recording is not paused")
+		return "", apierror.New(apierror.CodeBadRequest, "Recording is not paused")
 	}
 
-	if passphrase == channelData.HostPassphrase {
-		host = true
-	} else if passphrase == channelData.ViewerPassphrase {
-		host = false
-	} else {
-		r.Logger.Debug().Str("passphrase", passphrase).Msg("Invalid Passphrase; Interal Server Error")
-		return nil, errors.New("Invalid URL")
+	recorder, err := r.recorderFor(ctx, channelData)
+	if err != nil {
+		return "", err
 	}
 
-	mainUser, err := utils.GenerateUserCredentials(channelData.ChannelName, true, false)
+	err = r.Recorder.Resume(recorder)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Could not generate main user credentials")
-		return nil, errInternalServer
+		r.log(ctx).Error().Err(err).Msg("Resume recording failed")
+		return "", agoraError(err)
 	}
 
-	screenShare, err := utils.GenerateUserCredentials(channelData.ChannelName, false, false)
+	err = r.Repos.Channels.SetRecordingPaused(ctx, channelData.ID, false)
 	if err != nil {
-		r.Logger.Error().Err(err).Msg("Could not generate screenshare user credentails")
-		return nil, errInternalServer
+		r.log(ctx).Error().Err(err).Msg("Updating database for resumed recording failed")
+		return "", errInternalServer
 	}
 
-	return &models.Session{
-		Title:       channelData.Title,
-		Channel:     channelData.ChannelName,
-		IsHost:      host,
-		MainUser:    mainUser,
-		ScreenShare: screenShare,
-		Secret:      channelData.ChannelSecret,
-	}, nil
+	return "success", nil
 }
 
-func (r *queryResolver) Share(ctx context.Context, passphrase string) (*models.ShareResponse, error) {
-	r.Logger.Info().Str("query", "Share").Str("passphrase", passphrase).Msg("Share")
+func (r *mutationResolver) UpdateRecordingLayout(ctx context.Context, passphrase string, layout models.RecordingLayoutInput) (string, error) {
+	r.log(ctx).Info().Str("mutation", "UpdateRecordingLayout").Str("passphrase", passphrase).Interface("layout", layout).Msg("")
 
-	var channelData models.Channel
-	var host bool
+	channelData, err := r.getRecordingChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
 
-	if passphrase == "" {
-		return nil, errors.New("Passphrase cannot be empty")
+	if channelData.RecordingMode == "web" {
+		r.log(ctx).Debug().Str("channel", channelData.ChannelName).Msg("Layout cannot be changed for web recordings")
+		return "", apierror.New(apierror.CodeBadRequest, "Layout cannot be changed for web recordings")
 	}
 
-	err := r.DB.Get(&channelData, "SELECT title, channel_name, channel_secret, host_passphrase, viewer_passphrase, dtmf FROM channels WHERE host_passphrase = $1 OR viewer_passphrase = $1", passphrase)
+	videoLayout, err := mixedVideoLayout(layout.Type)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("passphrase", passphrase).Msg("Invalid Passphrase")
-		return nil, errors.New("Invalid URL")
+		return "", err
 	}
 
-	if passphrase == channelData.HostPassphrase {
-		host = true
-	} else if passphrase == channelData.ViewerPassphrase {
-		host = false
-	} else {
-		r.Logger.Debug().Str("passphrase", passphrase).Msg("Invalid Passphrase; Interal Server Error")
-		return nil, errors.New("Invalid URL")
+	var maxResolutionUID string
+	if layout.Type == models.RecordingLayoutSpotlight {
+		if layout.SpotlightUID == nil {
+			return "", apierror.New(apierror.CodeBadRequest, "Spotlight layout requires a spotlight UID")
+		}
+		maxResolutionUID = strconv.Itoa(*layout.SpotlightUID)
 	}
 
-	var hostPassphrase *string
-	if host {
-		hostPassphrase = &channelData.HostPassphrase
-	} else {
-		hostPassphrase = nil
+	var backgroundColor string
+	if layout.BackgroundColor != nil {
+		if !hexColor.MatchString(*layout.BackgroundColor) {
+			return "", errInvalidBackgroundColor
+		}
+		backgroundColor = *layout.BackgroundColor
 	}
 
-	var pstnResult *models.Pstn
-	var pstnNumber string
-	if viper.GetString("PSTN_NUMBER") == "" {
-		pstnNumber = "(800) 309-2350"
-	} else {
-		pstnNumber = viper.GetString("PSTN_NUMBER")
+	recorder, err := r.recorderFor(ctx, channelData)
+	if err != nil {
+		return "", err
 	}
 
-	if channelData.DTMF != "" {
-		pstnResult = &models.Pstn{
-			Number: pstnNumber,
-			Dtmf:   channelData.DTMF,
-		}
-	} else {
-		pstnResult = nil
+	err = r.Recorder.UpdateLayout(recorder, videoLayout, maxResolutionUID, backgroundColor)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Update recording layout failed")
+		return "", agoraError(err)
 	}
 
-	return &models.ShareResponse{
-		Passphrase: &models.Passphrase{
-			Host: hostPassphrase,
-			View: channelData.ViewerPassphrase,
-		},
-		Channel: channelData.ChannelName,
-		Title:   channelData.Title,
-		Pstn:    pstnResult,
-	}, nil
+	return "success", nil
 }
 
-func (r *queryResolver) GetUser(ctx context.Context) (*models.User, error) {
-	r.Logger.Info().Str("query", "GetUser").Msg("")
+func (r *mutationResolver) SetRecordingRetention(ctx context.Context, passphrase string, days *int) (*int, error) {
+	r.log(ctx).Info().Str("mutation", "SetRecordingRetention").Str("passphrase", passphrase).Interface("days", days).Msg("")
 
-	if !viper.GetBool("ENABLE_OAUTH") {
-		return &models.User{
-			Name: "",
-		}, nil
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
 	}
 
-	authUser, err := middleware.GetUserFromContext(ctx)
-	if err != nil {
-		r.Logger.Debug().Msg("Invalid Token")
-		return &models.User{
-			Name: "",
-		}, errors.New("Invalid Token")
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to change recording retention")
+		return nil, errNotHost("change recording retention")
 	}
 
-	if !authUser.UserName.Valid {
-		return &models.User{
-			Name: "",
-		}, nil
+	retention := sql.NullInt32{}
+	if days != nil {
+		if *days < 0 {
+			return nil, apierror.New(apierror.CodeBadRequest, "Retention cannot be negative")
+		}
+		retention = sql.NullInt32{Int32: int32(*days), Valid: true}
 	}
 
-	return &models.User{
-		Name: authUser.UserName.String,
-	}, nil
+	err = r.Repos.Channels.SetRecordingRetention(ctx, channelData.ID, retention)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Updating recording retention failed")
+		return nil, errInternalServer
+	}
+
+	return days, nil
 }
 
-// Mutation returns generated.MutationResolver implementation.
-func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
+func (r *mutationResolver) RenewToken(ctx context.Context, passphrase string, uid int) (*models.UserCredentials, error) {
+	r.log(ctx).Info().Str("mutation", "RenewToken").Str("passphrase", passphrase).Int("uid", uid).Msg("")
 
-// Query returns generated.QueryResolver implementation.
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	if uid <= 0 {
+		r.log(ctx).Debug().Int("uid", uid).Msg("Invalid UID")
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid UID")
+	}
+
+	user, _ := middleware.GetUserFromContext(ctx)
+	banned, err := r.isBanned(ctx, channelData.ID, uid, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if banned {
+		return nil, errBanned
+	}
+
+	mode, err := r.participantMode(ctx, channelData.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := r.Tokens.RenewUserCredentials(project, channelData.ChannelName, uid, utils.ChannelRole(channelData, host), utils.TokenExpiry(channelData), mode == models.JoinModeAudioOnly, utils.ChannelArea(channelData))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int("uid", uid).Msg("Could not renew user credentials")
+		return nil, errInternalServer
+	}
+
+	return credentials, nil
+}
+
+func (r *mutationResolver) AddCoHost(ctx context.Context, passphrase string, name string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "AddCoHost").Str("passphrase", passphrase).Str("name", name).Msg("")
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if passphraseType != models.PassphraseTypeHost {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to add co-host")
+		return "", errNotHost("add co-host")
+	}
+
+	if strings.TrimSpace(name) == "" {
+		return "", apierror.New(apierror.CodeBadRequest, "Name cannot be empty")
+	}
+
+	coHostPhrase, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Co-host Phrase generation failed")
+		return "", errInternalServer
+	}
+
+	err = r.Repos.Channels.AddPassphrases(ctx, models.ChannelPassphrase{
+		ChannelID:  channelData.ID,
+		Passphrase: coHostPhrase,
+		Name:       strings.TrimSpace(name),
+		Role:       models.PassphraseTypeCohost,
+	})
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Adding co-host to DB Failed")
+		return "", errInternalServer
+	}
+
+	return coHostPhrase, nil
+}
+
+func (r *mutationResolver) RotatePassphrases(ctx context.Context, passphrase string, which []models.PassphraseType) (*models.ShareResponse, error) {
+	r.log(ctx).Info().Str("mutation", "RotatePassphrases").Str("passphrase", passphrase).Interface("which", which).Msg("")
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if passphraseType != models.PassphraseTypeHost {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to rotate passphrases")
+		return nil, errNotHost("rotate passphrases")
+	}
+
+	if len(which) == 0 {
+		which = []models.PassphraseType{models.PassphraseTypeHost, models.PassphraseTypeViewer}
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	channels := r.Repos.WithTx(tx).Channels
+	for _, role := range which {
+		if role == models.PassphraseTypeCohost {
+			// Co-host passphrases are handed out individually, so they are revoked and can be added again with addCoHost
+			err = channels.DeletePassphrases(ctx, channelData.ID, role)
+			if err != nil {
+				r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Revoking co-host passphrases failed")
+				return nil, errInternalServer
+			}
+			continue
+		}
+
+		newPhrase, err := r.IDs.GenerateUUID()
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Passphrase generation failed")
+			return nil, errInternalServer
+		}
+
+		err = channels.SetPassphrase(ctx, channelData.ID, role, newPhrase)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Rotating channel passphrase failed")
+			return nil, errInternalServer
+		}
+
+		if role == models.PassphraseTypeHost {
+			channelData.HostPassphrase = newPhrase
+		} else {
+			channelData.ViewerPassphrase = newPhrase
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Rotating channel passphrases failed")
+		return nil, errInternalServer
+	}
+
+	return r.shareResponse(ctx, channelData, &channelData.HostPassphrase, nil), nil
+}
+
+func (r *mutationResolver) AdmitParticipant(ctx context.Context, passphrase string, lobbyID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "AdmitParticipant").Str("passphrase", passphrase).Str("lobbyId", lobbyID).Msg("")
+
+	return r.decideLobbyEntry(ctx, passphrase, lobbyID, models.LobbyStatusAdmitted)
+}
+
+func (r *mutationResolver) DenyParticipant(ctx context.Context, passphrase string, lobbyID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "DenyParticipant").Str("passphrase", passphrase).Str("lobbyId", lobbyID).Msg("")
+
+	return r.decideLobbyEntry(ctx, passphrase, lobbyID, models.LobbyStatusDenied)
+}
+
+func (r *mutationResolver) EndMeeting(ctx context.Context, passphrase string, kickParticipants *bool) (string, error) {
+	r.log(ctx).Info().Str("mutation", "EndMeeting").Str("passphrase", passphrase).Interface("kickParticipants", kickParticipants).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to end meeting")
+		return "", errNotHost("end meeting")
+	}
+
+	err = r.endChannel(ctx, channelData.ID)
+	if err != nil {
+		return "", err
+	}
+
+	waiting := []models.LobbyEntry{}
+	err = r.DB.SelectContext(ctx, &waiting, "UPDATE lobby SET status = $1 WHERE channel_id = $2 AND status = $3 RETURNING id, created_at, channel_id, lobby_id, name, status", models.LobbyStatusDenied, channelData.ID, models.LobbyStatusPending)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not deny waiting participants")
+	}
+
+	for _, entry := range waiting {
+		r.publishLobbyUpdate(ctx, entry)
+	}
+
+	if kickParticipants != nil && *kickParticipants {
+		project, err := r.channelProject(ctx, channelData)
+		if err != nil {
+			return "", err
+		}
+
+		// Tokens that were already issued stay valid for up to 24 hours, so participants are banned for as long
+		err = r.Agora.BanChannel(project, channelData.ChannelName, 24*time.Hour)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not remove participants")
+			return "", agoraError(err)
+		}
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) RemoveParticipant(ctx context.Context, passphrase string, uid int, banMinutes *int) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RemoveParticipant").Str("passphrase", passphrase).Int("uid", uid).Interface("banMinutes", banMinutes).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to remove participant")
+		return "", errNotHost("remove participant")
+	}
+
+	if uid <= 0 {
+		r.log(ctx).Debug().Int("uid", uid).Msg("Invalid UID")
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid UID")
+	}
+
+	ban := 0
+	if banMinutes != nil {
+		ban = *banMinutes
+	}
+
+	if ban < 0 || ban > 1440 {
+		r.log(ctx).Debug().Int("banMinutes", ban).Msg("Invalid ban duration")
+		return "", apierror.New(apierror.CodeBadRequest, "Ban duration must be between 0 and 1440 minutes")
+	}
+
+	participant := models.Participant{
+		ChannelID: channelData.ID,
+		UID:       uid,
+	}
+	err = r.DB.GetContext(ctx, &participant, "SELECT id, created_at, channel_id, uid, screen_share_uid, name, user_id FROM participants WHERE channel_id = $1 AND (uid = $2 OR screen_share_uid = $2)", channelData.ID, uid)
+	if err != nil && err != sql.ErrNoRows {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Int("uid", uid).Msg("Could not fetch participant")
+		return "", errInternalServer
+	}
+
+	uids := []int{participant.UID}
+	if participant.ScreenShareUID.Valid {
+		uids = append(uids, int(participant.ScreenShareUID.Int32))
+	}
+
+	// Agora removes a user by keeping them out of the channel for at least a minute
+	kickDuration := time.Minute
+	if ban > 0 {
+		kickDuration = time.Duration(ban) * time.Minute
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	for _, kickedUID := range uids {
+		err = r.Agora.KickUser(project, channelData.ChannelName, kickedUID, kickDuration)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("channel", channelData.ChannelName).Int("uid", kickedUID).Msg("Could not remove participant")
+			return "", agoraError(err)
+		}
+	}
+
+	if ban > 0 {
+		_, err = r.DB.ExecContext(ctx, "INSERT INTO channel_bans (channel_id, uid, user_id, banned_until) VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 minute')", channelData.ID, participant.UID, participant.UserID, ban)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Int("uid", participant.UID).Msg("Could not store ban")
+			return "", errInternalServer
+		}
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) UpdateChannel(ctx context.Context, passphrase string, input models.UpdateChannelInput) (*models.ShareResponse, error) {
+	r.log(ctx).Info().Str("mutation", "UpdateChannel").Str("passphrase", passphrase).Interface("input", input).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to update channel")
+		return nil, errNotHost("update channel")
+	}
+
+	err = r.updateChannel(ctx, channelData, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.shareResponse(ctx, channelData, &passphrase, nil), nil
+}
+
+func (r *mutationResolver) LockChannel(ctx context.Context, passphrase string, locked *bool) (string, error) {
+	r.log(ctx).Info().Str("mutation", "LockChannel").Str("passphrase", passphrase).Interface("locked", locked).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to lock channel")
+		return "", errNotHost("lock channel")
+	}
+
+	err = r.Repos.Channels.SetLocked(ctx, channelData.ID, locked == nil || *locked)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not lock channel")
+		return "", errInternalServer
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SetChannelMetadata(ctx context.Context, passphrase string, metadata map[string]interface{}) (map[string]interface{}, error) {
+	r.log(ctx).Info().Str("mutation", "SetChannelMetadata").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to set channel metadata")
+		return nil, errNotHost("set channel metadata")
+	}
+
+	err = r.setChannelMetadata(ctx, channelData, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+func (r *mutationResolver) TransferHost(ctx context.Context, passphrase string, newOwnerIdentifier string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "TransferHost").Str("passphrase", passphrase).Str("newOwnerIdentifier", newOwnerIdentifier).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	controls, err := r.controlsChannel(ctx, authUser, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	// Channels created without signing in have no owner yet, so any host can hand them over. Owners and admins of the
+	// organization of a channel can hand it over like its owner
+	if !controls && (passphraseType != models.PassphraseTypeHost || channelData.OwnerID.Valid) {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Int64("user", authUser.ID).Msg("Unauthorized to transfer host")
+		return "", errNotHost("transfer host")
+	}
+
+	newOwner, err := r.Repos.Users.ByIdentifierOrEmail(ctx, newOwnerIdentifier)
+	if err == sql.ErrNoRows {
+		r.log(ctx).Debug().Str("newOwnerIdentifier", newOwnerIdentifier).Msg("New owner not found")
+		return "", apierror.New(apierror.CodeNotFound, "User not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("newOwnerIdentifier", newOwnerIdentifier).Msg("Could not fetch new owner")
+		return "", errInternalServer
+	}
+
+	if newOwner.ID == authUser.ID {
+		return "", apierror.New(apierror.CodeBadRequest, "You are already the host")
+	}
+
+	_, err = r.transferHost(ctx, channelData, authUser, newOwner)
+	if err != nil {
+		return "", err
+	}
+
+	r.log(ctx).Info().Int64("Channel ID", channelData.ID).Int64("from", authUser.ID).Int64("to", newOwner.ID).Msg("Transferred host")
+
+	return "success", nil
+}
+
+func (r *mutationResolver) DialOut(ctx context.Context, passphrase string, phoneNumber string) (*models.DialOutCall, error) {
+	r.log(ctx).Info().Str("mutation", "DialOut").Str("passphrase", passphrase).Str("phoneNumber", phoneNumber).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to dial out")
+		return nil, errNotHost("dial out")
+	}
+
+	err = r.checkFeature(ctx, channelData, models.FeaturePstn)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelData.DTMF == "" {
+		r.log(ctx).Error().Interface("Channel Data", channelData).Msg("DTMF is empty")
+		return nil, errBadRequest
+	}
+
+	number, ok := normalizePhoneNumber(phoneNumber)
+	if !ok {
+		r.log(ctx).Debug().Str("phoneNumber", phoneNumber).Msg("Invalid phone number")
+		return nil, apierror.New(apierror.CodeBadRequest, "Phone number must be in international format, e.g. +14155550100")
+	}
+
+	err = r.checkQuota(ctx, r.DB, services.ChannelUsageSubject(channelData), models.UsagePSTNCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	callID, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Call ID generation failed")
+		return nil, errInternalServer
+	}
+
+	var call models.PSTNCall
+	err = r.DB.GetContext(ctx, &call, "INSERT INTO pstn_calls (channel_id, call_id, phone_number, status) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at, channel_id, call_id, phone_number, status", channelData.ID, callID, number, models.PSTNCallDialing)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not store PSTN call")
+		return nil, errInternalServer
+	}
+
+	err = services.DialOut(channelData.DTMF, number, callID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("callId", callID).Msg("Dial out failed")
+
+		_, err = r.DB.ExecContext(ctx, "UPDATE pstn_calls SET status = $1, updated_at = NOW() WHERE id = $2", models.PSTNCallFailed, call.ID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("callId", callID).Msg("Could not update PSTN call status")
+		}
+
+		return nil, errInternalServer
+	}
+
+	err = services.MeterPSTNCall(ctx, r.DB, channelData, callID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("callId", callID).Msg("Could not meter PSTN call")
+	}
+
+	return dialOutCall(call), nil
+}
+
+func (r *mutationResolver) RotateDtmf(ctx context.Context, passphrase string) (*models.Pstn, error) {
+	r.log(ctx).Info().Str("mutation", "RotateDtmf").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to rotate DTMF")
+		return nil, errNotHost("rotate DTMF")
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	dtmf, err := r.uniqueDTMF(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// SIP URIs contain the DTMF, so they are provisioned again for channels that have one
+	sipURI := channelData.SIPURI
+	if sipURI.Valid {
+		sipURI = sql.NullString{String: services.SIPURI(*dtmf), Valid: services.SIPURI(*dtmf) != ""}
+	}
+
+	err = r.Repos.Channels.SetDialIn(ctx, channelData.ID, *dtmf, sipURI)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not rotate DTMF")
+		return nil, errInternalServer
+	}
+
+	// The bridge of the old DTMF is left in place, but callers using it no longer find a channel to join
+	if viper.GetString("BACKEND_URL") != "" {
+		services.CreateBridge(r.Logger, *dtmf, strings.TrimSuffix(viper.GetString("BACKEND_URL"), "/"))
+	} else {
+		r.log(ctx).Error().Int64("Channel ID", channelData.ID).Msg("BACKEND_URL is not set, so no bridge was created for the new DTMF")
+	}
+
+	return r.pstnDetails(ctx, *dtmf, nil), nil
+}
+
+func (r *mutationResolver) StartLiveStream(ctx context.Context, passphrase string, rtmpURL string, streamKey string) (*models.LiveStream, error) {
+	r.log(ctx).Info().Str("mutation", "StartLiveStream").Str("passphrase", passphrase).Str("rtmpUrl", rtmpURL).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to start live stream")
+		return nil, errNotHost("start live stream")
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	err = r.checkFeature(ctx, channelData, models.FeatureLiveStreaming)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isRTMPURL(rtmpURL) || strings.TrimSpace(streamKey) == "" {
+		r.log(ctx).Debug().Str("rtmpUrl", rtmpURL).Msg("Invalid RTMP URL")
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid RTMP URL or stream key")
+	}
+
+	suffix, err := r.IDs.GenerateUUID()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Converter name generation failed")
+		return nil, errInternalServer
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := r.Agora.StartMediaPush(project, channelData.ChannelName, channelData.ChannelName+"_"+suffix[:8], streamURL(rtmpURL, strings.TrimSpace(streamKey)))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not start live stream")
+		return nil, agoraError(err)
+	}
+
+	var stream models.ChannelLiveStream
+	err = r.DB.GetContext(ctx, &stream, "INSERT INTO live_streams (channel_id, converter_id, rtmp_url, status) VALUES ($1, $2, $3, $4) RETURNING "+liveStreamColumns, channelData.ID, converter.ID, rtmpURL, models.StreamRunning)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("converter", converter.ID).Msg("Could not store live stream")
+
+		// A stream that is not stored cannot be stopped later, so it is stopped right away
+		if err := r.Agora.StopMediaPush(project, converter.ID); err != nil {
+			r.log(ctx).Error().Err(err).Str("converter", converter.ID).Msg("Could not stop live stream")
+		}
+
+		return nil, errInternalServer
+	}
+
+	return liveStream(stream), nil
+}
+
+func (r *mutationResolver) StopLiveStream(ctx context.Context, passphrase string, streamID *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StopLiveStream").Str("passphrase", passphrase).Interface("streamId", streamID).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to stop live stream")
+		return "", errNotHost("stop live stream")
+	}
+
+	// Every running stream of the channel is stopped when no stream is given
+	streams := []models.ChannelLiveStream{}
+	err = r.DB.SelectContext(ctx, &streams, "SELECT "+liveStreamColumns+" FROM live_streams WHERE channel_id = $1 AND status = $2 AND ($3::TEXT IS NULL OR converter_id = $3)", channelData.ID, models.StreamRunning, streamID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch live streams")
+		return "", errInternalServer
+	}
+
+	if len(streams) == 0 {
+		return "", apierror.New(apierror.CodeNotFound, "Live stream not found")
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	for _, stream := range streams {
+		err = r.Agora.StopMediaPush(project, stream.ConverterID)
+		if err != nil && err != utils.ErrConverterNotFound {
+			r.log(ctx).Error().Err(err).Str("converter", stream.ConverterID).Msg("Could not stop live stream")
+			return "", agoraError(err)
+		}
+
+		_, err = r.DB.ExecContext(ctx, "UPDATE live_streams SET status = $1, stopped_at = NOW() WHERE id = $2", models.StreamStopped, stream.ID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("converter", stream.ConverterID).Msg("Could not update live stream")
+			return "", errInternalServer
+		}
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) InjectStream(ctx context.Context, passphrase string, url string) (*models.InjectedStream, error) {
+	r.log(ctx).Info().Str("mutation", "InjectStream").Str("passphrase", passphrase).Str("url", url).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to inject stream")
+		return nil, errNotHost("inject stream")
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	if !isRTMPURL(url) && !isWebURL(url) {
+		r.log(ctx).Debug().Str("url", url).Msg("Invalid stream URL")
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid stream URL")
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	player, user, err := r.startMediaPull(project, channelData, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream models.ChannelInjectedStream
+	err = r.DB.GetContext(ctx, &stream, "INSERT INTO injected_streams (channel_id, player_id, uid, stream_url, status) VALUES ($1, $2, $3, $4, $5) RETURNING "+injectedStreamColumns, channelData.ID, player.ID, user.UID, url, models.StreamRunning)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("player", player.ID).Msg("Could not store injected stream")
+
+		// A stream that is not stored cannot be stopped later, so it is stopped right away
+		if err := r.Agora.StopMediaPull(project, player.ID); err != nil {
+			r.log(ctx).Error().Err(err).Str("player", player.ID).Msg("Could not stop injected stream")
+		}
+
+		return nil, errInternalServer
+	}
+
+	return injectedStream(stream), nil
+}
+
+func (r *mutationResolver) StopInjectedStream(ctx context.Context, passphrase string, streamID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StopInjectedStream").Str("passphrase", passphrase).Str("streamId", streamID).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to stop injected stream")
+		return "", errNotHost("stop injected stream")
+	}
+
+	var stream models.ChannelInjectedStream
+	err = r.DB.GetContext(ctx, &stream, "SELECT "+injectedStreamColumns+" FROM injected_streams WHERE channel_id = $1 AND player_id = $2 AND status = $3", channelData.ID, streamID, models.StreamRunning)
+	if err == sql.ErrNoRows {
+		return "", apierror.New(apierror.CodeNotFound, "Injected stream not found")
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch injected stream")
+		return "", errInternalServer
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.Agora.StopMediaPull(project, stream.PlayerID)
+	if err != nil && err != utils.ErrPlayerNotFound {
+		r.log(ctx).Error().Err(err).Str("player", stream.PlayerID).Msg("Could not stop injected stream")
+		return "", agoraError(err)
+	}
+
+	_, err = r.DB.ExecContext(ctx, "UPDATE injected_streams SET status = $1, stopped_at = NOW() WHERE id = $2", models.StreamStopped, stream.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("player", stream.PlayerID).Msg("Could not update injected stream")
+		return "", errInternalServer
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) StartTranscription(ctx context.Context, passphrase string, language *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StartTranscription").Str("passphrase", passphrase).Interface("language", language).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to start transcription")
+		return "", errNotHost("start transcription")
+	}
+
+	if channelData.EndedAt.Valid {
+		return "", errMeetingEnded
+	}
+
+	err = r.checkFeature(ctx, channelData, models.FeatureTranscription)
+	if err != nil {
+		return "", err
+	}
+
+	transcriptionLanguageCode := "en-US"
+	if language != nil {
+		transcriptionLanguageCode = *language
+	}
+
+	if !transcriptionLanguage.MatchString(transcriptionLanguageCode) {
+		r.log(ctx).Debug().Str("language", transcriptionLanguageCode).Msg("Invalid transcription language")
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid language")
+	}
+
+	transcription, err := r.startTranscription(ctx, channelData, transcriptionLanguageCode)
+	if err != nil {
+		return "", err
+	}
+
+	return transcription.TaskID, nil
+}
+
+func (r *mutationResolver) StopTranscription(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "StopTranscription").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to stop transcription")
+		return "", errNotHost("stop transcription")
+	}
+
+	stopped, err := r.stopTranscriptions(ctx, channelData)
+	if err != nil {
+		return "", err
+	}
+
+	if stopped == 0 {
+		return "", apierror.New(apierror.CodeBadRequest, "Transcription not started")
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SendChannelMessage(ctx context.Context, passphrase string, uid int, text string) (*models.ChatMessage, error) {
+	r.log(ctx).Info().Str("mutation", "SendChannelMessage").Str("passphrase", passphrase).Int("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	return r.sendMessage(ctx, channelData, uid, text)
+}
+
+func (r *mutationResolver) CreatePoll(ctx context.Context, passphrase string, question string, options []string) (*models.Poll, error) {
+	r.log(ctx).Info().Str("mutation", "CreatePoll").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to create poll")
+		return nil, errNotHost("create poll")
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	return r.createPoll(ctx, channelData, question, options)
+}
+
+func (r *mutationResolver) VotePoll(ctx context.Context, passphrase string, pollID string, uid int, option int) (string, error) {
+	r.log(ctx).Info().Str("mutation", "VotePoll").Str("passphrase", passphrase).Str("pollId", pollID).Int("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if channelData.EndedAt.Valid {
+		return "", errMeetingEnded
+	}
+
+	err = r.votePoll(ctx, channelData, pollID, uid, option)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) ClosePoll(ctx context.Context, passphrase string, pollID string) (*models.Poll, error) {
+	r.log(ctx).Info().Str("mutation", "ClosePoll").Str("passphrase", passphrase).Str("pollId", pollID).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to close poll")
+		return nil, errNotHost("close poll")
+	}
+
+	return r.closePoll(ctx, channelData, pollID)
+}
+
+func (r *mutationResolver) RaiseHand(ctx context.Context, passphrase string, uid int) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RaiseHand").Str("passphrase", passphrase).Int("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.raiseHand(ctx, channelData, uid)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) LowerHand(ctx context.Context, passphrase string, uid int) (string, error) {
+	r.log(ctx).Info().Str("mutation", "LowerHand").Str("passphrase", passphrase).Int("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.lowerHand(ctx, channelData, uid)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) AskQuestion(ctx context.Context, passphrase string, text string, uid *int) (*models.Question, error) {
+	r.log(ctx).Info().Str("mutation", "AskQuestion").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	return r.askQuestion(ctx, channelData, text, uid)
+}
+
+func (r *mutationResolver) UpvoteQuestion(ctx context.Context, passphrase string, questionID string, uid int) (*models.Question, error) {
+	r.log(ctx).Info().Str("mutation", "UpvoteQuestion").Str("passphrase", passphrase).Str("questionId", questionID).Int("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	return r.upvoteQuestion(ctx, channelData, questionID, uid)
+}
+
+func (r *mutationResolver) AnswerQuestion(ctx context.Context, passphrase string, questionID string) (*models.Question, error) {
+	r.log(ctx).Info().Str("mutation", "AnswerQuestion").Str("passphrase", passphrase).Str("questionId", questionID).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to answer question")
+		return nil, errNotHost("answer question")
+	}
+
+	return r.setQuestionStatus(ctx, channelData, questionID, models.QuestionStatusAnswered)
+}
+
+func (r *mutationResolver) DismissQuestion(ctx context.Context, passphrase string, questionID string) (*models.Question, error) {
+	r.log(ctx).Info().Str("mutation", "DismissQuestion").Str("passphrase", passphrase).Str("questionId", questionID).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to dismiss question")
+		return nil, errNotHost("dismiss question")
+	}
+
+	return r.setQuestionStatus(ctx, channelData, questionID, models.QuestionStatusDismissed)
+}
+
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, name string, scopes []models.APIKeyScope) (*models.CreatedAPIKey, error) {
+	r.log(ctx).Info().Str("mutation", "CreateAPIKey").Str("name", name).Interface("scopes", scopes).Msg("")
+
+	user, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.createAPIKey(ctx, user, name, scopes)
+}
+
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RevokeAPIKey").Str("id", id).Msg("")
+
+	user, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	err = r.revokeAPIKey(ctx, user, id)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) LogoutSession(ctx context.Context, token string) ([]string, error) {
+	r.log(ctx).Info().Str("mutation", "LogoutSession").Str("token", token).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	deleted, err := services.RevokeAuthSession(ctx, r.DB, r.Repos, authUser.ID, token)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not delete token from database")
+		return nil, errInternalServer
+	}
+
+	if !deleted {
+		r.log(ctx).Debug().Str("Sub", authUser.Identifier).Msg("Token does not exist")
+		return nil, errBadRequest
+	}
+
+	string_token_slice := []string{}
+	tokens, err := r.Repos.Tokens.ListForUser(ctx, authUser.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", authUser.ID).Msg("Could not get tokens for this user ID")
+		return nil, errInternalServer
+	}
+
+	for _, v := range tokens {
+		string_token_slice = append(string_token_slice, v.TokenID)
+	}
+
+	return string_token_slice, nil
+}
+
+func (r *mutationResolver) RefreshSession(ctx context.Context, refreshToken string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "RefreshSession").Msg("")
+
+	return r.refreshSession(ctx, refreshToken)
+}
+
+func (r *mutationResolver) LogoutAllSessions(ctx context.Context) (int, error) {
+	r.log(ctx).Info().Str("mutation", "LogoutAllSessions").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return 0, errInvalidToken
+	}
+
+	deleted, err := services.RevokeAllAuthSessions(ctx, r.DB, r.Repos, authUser.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", authUser.ID).Msg("Could not delete tokens from database")
+		return 0, errInternalServer
+	}
+
+	return int(deleted), nil
+}
+
+func (r *mutationResolver) RevokeSession(ctx context.Context, tokenID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RevokeSession").Str("tokenId", tokenID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	id, err := strconv.ParseInt(tokenID, 10, 64)
+	if err != nil {
+		return "", apierror.New(apierror.CodeBadRequest, "Invalid session ID")
+	}
+
+	deleted, err := services.RevokeAuthSessionByID(ctx, r.DB, r.Repos, authUser.ID, id)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", authUser.ID).Msg("Could not delete token from database")
+		return "", errInternalServer
+	}
+
+	if !deleted {
+		r.log(ctx).Debug().Str("Sub", authUser.Identifier).Str("tokenId", tokenID).Msg("Token does not exist")
+		return "", apierror.New(apierror.CodeNotFound, "Session not found")
+	}
+
+	return "success", nil
+}
+
+func (r *mutationResolver) SignUp(ctx context.Context, email string, password string, name *string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "SignUp").Str("email", email).Msg("")
+
+	return r.signUp(ctx, email, password, name)
+}
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "VerifyEmail").Msg("")
+
+	if err := r.checkEmailAuth(ctx, "ENABLE_PASSWORD_AUTH", "Password"); err != nil {
+		return nil, err
+	}
+
+	return r.signInWithEmailToken(ctx, token, services.EmailTokenVerify, nil)
+}
+
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "Login").Str("email", email).Msg("")
+
+	return r.login(ctx, email, password)
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RequestPasswordReset").Str("email", email).Msg("")
+
+	return r.requestPasswordReset(ctx, email)
+}
+
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, password string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "ResetPassword").Msg("")
+
+	return r.resetPassword(ctx, token, password)
+}
+
+func (r *mutationResolver) RequestMagicLink(ctx context.Context, email string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RequestMagicLink").Str("email", email).Msg("")
+
+	return r.requestMagicLink(ctx, email)
+}
+
+func (r *mutationResolver) LoginWithMagicLink(ctx context.Context, token string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "LoginWithMagicLink").Msg("")
+
+	if err := r.checkEmailAuth(ctx, "ENABLE_MAGIC_LINK_AUTH", "Magic link"); err != nil {
+		return nil, err
+	}
+
+	return r.signInWithEmailToken(ctx, token, services.EmailTokenMagicLink, nil)
+}
+
+func (r *mutationResolver) RequestOtp(ctx context.Context, phoneNumber string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "RequestOtp").Str("phoneNumber", phoneNumber).Msg("")
+
+	return r.requestOtp(ctx, phoneNumber)
+}
+
+func (r *mutationResolver) VerifyOtp(ctx context.Context, phoneNumber string, code string) (*models.AuthSession, error) {
+	r.log(ctx).Info().Str("mutation", "VerifyOtp").Str("phoneNumber", phoneNumber).Msg("")
+
+	return r.verifyOtp(ctx, phoneNumber, code)
+}
+
+func (r *mutationResolver) EnrollTwoFactor(ctx context.Context) (*models.TwoFactorEnrollment, error) {
+	r.log(ctx).Info().Str("mutation", "EnrollTwoFactor").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.enrollTwoFactor(ctx, authUser)
+}
+
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	r.log(ctx).Info().Str("mutation", "ConfirmTwoFactor").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.confirmTwoFactor(ctx, authUser, code)
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "DisableTwoFactor").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	return r.disableTwoFactor(ctx, authUser, code)
+}
+
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context) ([]string, error) {
+	r.log(ctx).Info().Str("mutation", "RegenerateRecoveryCodes").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.regenerateRecoveryCodes(ctx, authUser)
+}
+
+func (r *mutationResolver) DeleteMyAccount(ctx context.Context) (string, error) {
+	r.log(ctx).Info().Str("mutation", "DeleteMyAccount").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	return r.deleteMyAccount(ctx, authUser)
+}
+
+func (r *mutationResolver) ExportMyData(ctx context.Context) (*models.DataExport, error) {
+	r.log(ctx).Info().Str("mutation", "ExportMyData").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.exportMyData(ctx, authUser)
+}
+
+func (r *queryResolver) JoinChannel(ctx context.Context, passphrase string, name *string, mode *models.JoinMode) (*models.Session, error) {
+	r.log(ctx).Info().Str("query", "JoinChannel").Str("passphrase", passphrase).Msg("")
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	host := isHost(passphraseType)
+
+	if channelData.EndedAt.Valid {
+		return nil, errMeetingEnded
+	}
+
+	// Hosts can join early to prepare the meeting
+	now := time.Now()
+	if !host && channelData.StartsAt.Valid && now.Before(channelData.StartsAt.Time) {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Time("startsAt", channelData.StartsAt.Time).Msg("Meeting has not started")
+		return nil, meetingNotStarted(channelData.StartsAt.Time, now)
+	}
+
+	// Users that are not signed in can only be recognised by uid, which changes every time they join
+	user, _ := middleware.GetUserFromContext(ctx)
+	if user != nil {
+		banned, err := r.isBanned(ctx, channelData.ID, 0, user)
+		if err != nil {
+			return nil, err
+		}
+
+		if banned {
+			return nil, errBanned
+		}
+	}
+
+	if !host {
+		err = r.checkCapacity(ctx, channelData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	joinMode := models.JoinModeFull
+	if mode != nil {
+		joinMode = *mode
+	}
+
+	if !host && channelData.WaitingRoom && r.featureEnabled(ctx, channelData, models.FeatureWaitingRoom) {
+		return r.enterLobby(ctx, channelData, passphraseType, name, joinMode)
+	}
+
+	session, err := r.newSession(ctx, channelData, passphraseType, joinMode)
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordParticipant(ctx, channelData.ID, session, name, user)
+
+	return session, nil
+}
+
+func (r *queryResolver) Share(ctx context.Context, passphrase string, country *string) (*models.ShareResponse, error) {
+	r.log(ctx).Info().Str("query", "Share").Str("passphrase", passphrase).Msg("Share")
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Co-hosts share their own passphrase so that the host passphrase is only known to the host. The owner of the
+	// channel can always get the host passphrase, which is how the host passphrase reaches a new owner after a transfer.
+	// Owners and admins of the organization of the channel get it the same way
+	user, _ := middleware.GetUserFromContext(ctx)
+	isOwner, err := r.controlsChannel(ctx, user, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostPassphrase *string
+	if passphraseType == models.PassphraseTypeHost || isOwner {
+		hostPassphrase = &channelData.HostPassphrase
+	} else if passphraseType == models.PassphraseTypeCohost {
+		hostPassphrase = &passphrase
+	} else {
+		hostPassphrase = nil
+	}
+
+	return r.shareResponse(ctx, channelData, hostPassphrase, country), nil
+}
+
+func (r *queryResolver) GetUser(ctx context.Context) (*models.User, error) {
+	r.log(ctx).Info().Str("query", "GetUser").Msg("")
+
+	if !viper.GetBool("ENABLE_OAUTH") {
+		return &models.User{
+			Name: "",
+		}, nil
+	}
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return &models.User{
+			Name: "",
+		}, errInvalidToken
+	}
+
+	if !authUser.UserName.Valid {
+		return &models.User{
+			Name: "",
+		}, nil
+	}
+
+	return &models.User{
+		Name:     authUser.UserName.String,
+		Provider: nullableString(authUser.Provider),
+	}, nil
+}
+
+func (r *queryResolver) RecordingStatus(ctx context.Context, passphrase string) (*models.RecordingStatus, error) {
+	r.log(ctx).Info().Str("query", "RecordingStatus").Str("passphrase", passphrase).Msg("")
+
+	channelData, err := r.getRecordingChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, err := r.recorderFor(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.Recorder.Query(recorder)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Query recording failed")
+		return nil, agoraError(err)
+	}
+
+	var uploadStatus *string
+	if result.ServerResponse.UploadingStatus != "" {
+		uploadStatus = &result.ServerResponse.UploadingStatus
+	}
+
+	files := []*models.RecordingFile{}
+	for _, file := range result.Files() {
+		files = append(files, &models.RecordingFile{
+			Filename:       file.Filename,
+			TrackType:      file.TrackType,
+			UID:            file.UID,
+			MixedAllUser:   file.MixedAllUser,
+			IsPlayable:     file.IsPlayable,
+			SliceStartTime: int(file.SliceStartTime),
+		})
+	}
+
+	return &models.RecordingStatus{
+		ServerState:  utils.RecordingState(result.ServerResponse.Status),
+		Status:       result.ServerResponse.Status,
+		UploadStatus: uploadStatus,
+		FileListMode: result.ServerResponse.FileListMode,
+		Files:        files,
+	}, nil
+}
+
+func (r *queryResolver) Recordings(ctx context.Context, passphrase string) ([]*models.Recording, error) {
+	r.log(ctx).Info().Str("query", "Recordings").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to list recordings")
+		return nil, errNotHost("list recordings")
+	}
+
+	recordings, err := r.Repos.Recordings.ListForChannel(ctx, channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch recordings")
+		return nil, errInternalServer
+	}
+
+	storage, err := r.channelStorage(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.Recording{}
+	for _, recording := range recordings {
+		downloadURL, expiresAt, err := utils.PresignRecordingURL(storage, recording.FileName)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Str("file", recording.FileName).Msg("Could not presign recording URL")
+			return nil, errInternalServer
+		}
+
+		var trackType *string
+		if recording.TrackType.Valid {
+			track := recording.TrackType.String
+			trackType = &track
+		}
+
+		result = append(result, &models.Recording{
+			FileName:   recording.FileName,
+			TrackType:  trackType,
+			IsPlayable: recording.IsPlayable,
+			CreatedAt:  recording.CreatedAt,
+			URL:        downloadURL,
+			ExpiresAt:  expiresAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *queryResolver) MeetingIcs(ctx context.Context, passphrase string) (string, error) {
+	r.log(ctx).Info().Str("query", "MeetingICS").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	event := r.meetingEvent(ctx, channelData)
+	if event == nil {
+		return "", apierror.New(apierror.CodeBadRequest, "Meeting is not scheduled")
+	}
+
+	return event.ICS(time.Now()), nil
+}
+
+func (r *queryResolver) Participants(ctx context.Context, passphrase string) (*models.ChannelParticipants, error) {
+	r.log(ctx).Info().Str("query", "Participants").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.channelParticipants(ctx, channelData)
+}
+
+func (r *queryResolver) AttendanceReport(ctx context.Context, passphrase string) ([]*models.AttendanceRecord, error) {
+	r.log(ctx).Info().Str("query", "AttendanceReport").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view attendance")
+		return nil, errNotHost("view attendance")
+	}
+
+	return r.attendanceReport(ctx, channelData)
+}
+
+func (r *queryResolver) DialOutCalls(ctx context.Context, passphrase string) ([]*models.DialOutCall, error) {
+	r.log(ctx).Info().Str("query", "DialOutCalls").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view calls")
+		return nil, errNotHost("view calls")
+	}
+
+	calls := []models.PSTNCall{}
+	err = r.DB.SelectContext(ctx, &calls, "SELECT id, created_at, updated_at, channel_id, call_id, phone_number, status FROM pstn_calls WHERE channel_id = $1 ORDER BY created_at DESC", channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch PSTN calls")
+		return nil, errInternalServer
+	}
+
+	result := []*models.DialOutCall{}
+	for _, call := range calls {
+		result = append(result, dialOutCall(call))
+	}
+
+	return result, nil
+}
+
+func (r *queryResolver) LiveStreams(ctx context.Context, passphrase string) ([]*models.LiveStream, error) {
+	r.log(ctx).Info().Str("query", "LiveStreams").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view live streams")
+		return nil, errNotHost("view live streams")
+	}
+
+	streams := []models.ChannelLiveStream{}
+	err = r.DB.SelectContext(ctx, &streams, "SELECT "+liveStreamColumns+" FROM live_streams WHERE channel_id = $1 ORDER BY created_at DESC", channelData.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch live streams")
+		return nil, errInternalServer
+	}
+
+	result := []*models.LiveStream{}
+	for _, stream := range streams {
+		result = append(result, liveStream(stream))
+	}
+
+	return result, nil
+}
+
+func (r *queryResolver) Transcript(ctx context.Context, passphrase string) ([]*models.TranscriptFile, error) {
+	r.log(ctx).Info().Str("query", "Transcript").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view transcript")
+		return nil, errNotHost("view transcript")
+	}
+
+	return r.transcriptFiles(ctx, channelData)
+}
+
+func (r *queryResolver) RecordingTranscript(ctx context.Context, passphrase string) ([]*models.RecordingTranscript, error) {
+	r.log(ctx).Info().Str("query", "RecordingTranscript").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view recording transcript")
+		return nil, errNotHost("view recording transcript")
+	}
+
+	return r.recordingTranscripts(ctx, channelData)
+}
+
+func (r *queryResolver) ChannelMessages(ctx context.Context, passphrase string, before *string, limit *int) (*models.ChatMessagePage, error) {
+	r.log(ctx).Info().Str("query", "ChannelMessages").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := 50
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.channelMessages(ctx, channelData, before, pageSize)
+}
+
+func (r *queryResolver) Polls(ctx context.Context, passphrase string) ([]*models.Poll, error) {
+	r.log(ctx).Info().Str("query", "Polls").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.channelPolls(ctx, channelData)
+}
+
+func (r *queryResolver) RaisedHands(ctx context.Context, passphrase string) ([]*models.RaisedHand, error) {
+	r.log(ctx).Info().Str("query", "RaisedHands").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.raisedHands(ctx, channelData.ID)
+}
+
+func (r *queryResolver) Questions(ctx context.Context, passphrase string, sort *models.QuestionSort) ([]*models.Question, error) {
+	r.log(ctx).Info().Str("query", "Questions").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	order := models.QuestionSortRecent
+	if sort != nil {
+		order = *sort
+	}
+
+	return r.channelQuestions(ctx, channelData, host, order)
+}
+
+func (r *queryResolver) PassphraseAttempts(ctx context.Context, passphrase string) ([]*models.PassphraseAttempt, error) {
+	r.log(ctx).Info().Str("query", "PassphraseAttempts").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to view passphrase attempts")
+		return nil, errNotHost("view passphrase attempts")
+	}
+
+	return r.passphraseAttempts(ctx, channelData)
+}
+
+func (r *queryResolver) APIKeys(ctx context.Context) ([]*models.APIKey, error) {
+	r.log(ctx).Info().Str("query", "APIKeys").Msg("")
+
+	user, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.apiKeys(ctx, user)
+}
+
+func (r *queryResolver) GetSessions(ctx context.Context) ([]*models.LoginSession, error) {
+	r.log(ctx).Info().Str("query", "GetSessions").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.loginSessions(ctx, authUser)
+}
+
+func (r *queryResolver) DataExports(ctx context.Context) ([]*models.DataExport, error) {
+	r.log(ctx).Info().Str("query", "DataExports").Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.dataExports(ctx, authUser)
+}
+
+func (r *subscriptionResolver) LobbyUpdates(ctx context.Context, passphrase string) (<-chan *models.LobbyUpdate, error) {
+	r.log(ctx).Info().Str("subscription", "LobbyUpdates").Str("passphrase", passphrase).Msg("")
+
+	channelData, host, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !host {
+		r.log(ctx).Debug().Str("passphrase", passphrase).Str("channel", channelData.ChannelName).Msg("Unauthorized to watch lobby")
+		return nil, errNotHost("watch lobby")
+	}
+
+	// Subscribe before reading the pending entries so that no entry is missed in between
+	messages := r.PubSub.Subscribe(ctx, lobbyUpdatesTopic(channelData.ID))
+
+	pending := []models.LobbyEntry{}
+	err = r.DB.SelectContext(ctx, &pending, "SELECT id, created_at, channel_id, lobby_id, name, status FROM lobby WHERE channel_id = $1 AND status = $2 ORDER BY created_at", channelData.ID, models.LobbyStatusPending)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch lobby")
+		return nil, errInternalServer
+	}
+
+	updates := make(chan *models.LobbyUpdate, len(pending))
+	for _, entry := range pending {
+		updates <- lobbyUpdate(entry)
+	}
+
+	go func() {
+		defer close(updates)
+
+		for message := range messages {
+			var update models.LobbyUpdate
+			if err := json.Unmarshal(message, &update); err != nil {
+				r.log(ctx).Error().Err(err).Msg("Invalid lobby update")
+				continue
+			}
+
+			select {
+			case updates <- &update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+func (r *subscriptionResolver) LobbyStatus(ctx context.Context, passphrase string, lobbyID string) (<-chan *models.Session, error) {
+	r.log(ctx).Info().Str("subscription", "LobbyStatus").Str("passphrase", passphrase).Str("lobbyId", lobbyID).Msg("")
+
+	channelData, passphraseType, err := r.getChannelRole(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before reading the entry so that a decision made in between is not missed
+	messages := r.PubSub.Subscribe(ctx, lobbyTopic(lobbyID))
+
+	if _, err := r.getLobbyEntry(ctx, channelData.ID, lobbyID); err != nil {
+		return nil, err
+	}
+
+	sessions := make(chan *models.Session, 1)
+	go func() {
+		defer close(sessions)
+
+		for {
+			entry, err := r.getLobbyEntry(ctx, channelData.ID, lobbyID)
+			if err != nil {
+				return
+			}
+
+			if entry.Status != models.LobbyStatusPending {
+				session, err := r.lobbySession(ctx, channelData, passphraseType, entry)
+				if err != nil {
+					return
+				}
+
+				select {
+				case sessions <- session:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			select {
+			case _, ok := <-messages:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return sessions, nil
+}
+
+func (r *subscriptionResolver) MessageAdded(ctx context.Context, passphrase string) (<-chan *models.ChatMessage, error) {
+	r.log(ctx).Info().Str("subscription", "MessageAdded").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := r.PubSub.Subscribe(ctx, messagesTopic(channelData.ID))
+
+	chatMessages := make(chan *models.ChatMessage)
+	go func() {
+		defer close(chatMessages)
+
+		for message := range messages {
+			var chatMessage models.ChatMessage
+			if err := json.Unmarshal(message, &chatMessage); err != nil {
+				r.log(ctx).Error().Err(err).Msg("Invalid chat message")
+				continue
+			}
+
+			select {
+			case chatMessages <- &chatMessage:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return chatMessages, nil
+}
+
+func (r *subscriptionResolver) PollResults(ctx context.Context, passphrase string) (<-chan *models.Poll, error) {
+	r.log(ctx).Info().Str("subscription", "PollResults").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := r.PubSub.Subscribe(ctx, pollsTopic(channelData.ID))
+
+	polls := make(chan *models.Poll)
+	go func() {
+		defer close(polls)
+
+		for message := range messages {
+			var poll models.Poll
+			if err := json.Unmarshal(message, &poll); err != nil {
+				r.log(ctx).Error().Err(err).Msg("Invalid poll")
+				continue
+			}
+
+			select {
+			case polls <- &poll:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return polls, nil
+}
+
+func (r *subscriptionResolver) HandRaised(ctx context.Context, passphrase string) (<-chan []*models.RaisedHand, error) {
+	r.log(ctx).Info().Str("subscription", "HandRaised").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before reading the queue so that no change is missed in between
+	messages := r.PubSub.Subscribe(ctx, handsTopic(channelData.ID))
+
+	current, err := r.raisedHands(ctx, channelData.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make(chan []*models.RaisedHand, 1)
+	queues <- current
+
+	go func() {
+		defer close(queues)
+
+		for message := range messages {
+			var hands []*models.RaisedHand
+			if err := json.Unmarshal(message, &hands); err != nil {
+				r.log(ctx).Error().Err(err).Msg("Invalid raised hands")
+				continue
+			}
+
+			select {
+			case queues <- hands:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return queues, nil
+}
+
+func (r *subscriptionResolver) QuestionUpdates(ctx context.Context, passphrase string) (<-chan *models.Question, error) {
+	r.log(ctx).Info().Str("subscription", "QuestionUpdates").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := r.PubSub.Subscribe(ctx, questionsTopic(channelData.ID))
+
+	questions := make(chan *models.Question)
+	go func() {
+		defer close(questions)
+
+		for message := range messages {
+			var update models.Question
+			if err := json.Unmarshal(message, &update); err != nil {
+				r.log(ctx).Error().Err(err).Msg("Invalid question update")
+				continue
+			}
+
+			select {
+			case questions <- &update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return questions, nil
+}
+
+func (r *subscriptionResolver) RecordingStatus(ctx context.Context, passphrase string) (<-chan *models.RecordingUpdate, error) {
+	r.log(ctx).Info().Str("subscription", "RecordingStatus").Str("passphrase", passphrase).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return services.SubscribeRecordingUpdates(ctx, r.PubSub, channelData.ID), nil
+}
+
+// Mutation returns generated.MutationResolver implementation.
+func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
+
+// Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
-
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-var errInternalServer error = errors.New("Internal Server Error")
-var errBadRequest error = errors.New("Bad Request")
+type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,85 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+// errRefreshTokenReused is returned when a refresh token is presented twice. The sign in it belongs to is revoked
+var errRefreshTokenReused = apierror.New(apierror.CodeTokenExpired, services.ErrRefreshTokenReused.Error())
+
+// refreshSession exchanges a refresh token for new tokens
+func (r *Resolver) refreshSession(ctx context.Context, refreshToken string) (*models.AuthSession, error) {
+	tokens, err := services.RefreshAuthSession(ctx, r.DB, r.Repos, refreshToken)
+	if err == services.ErrInvalidRefreshToken {
+		r.log(ctx).Debug().Msg("Invalid refresh token")
+		return nil, errInvalidToken
+	}
+
+	if err == services.ErrRefreshTokenReused {
+		r.log(ctx).Warn().Str("ip", middleware.GetClientIP(ctx)).Msg("Refresh token reused, revoked its sign in")
+		return nil, errRefreshTokenReused
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not refresh session")
+		return nil, errInternalServer
+	}
+
+	return &models.AuthSession{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	}, nil
+}
+
+// loginSessions lists the access tokens of a user that have not expired, most recently used first
+func (r *Resolver) loginSessions(ctx context.Context, user *models.UserAccount) ([]*models.LoginSession, error) {
+	tokens, err := r.Repos.Tokens.ListActive(ctx, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not get tokens for this user ID")
+		return nil, errInternalServer
+	}
+
+	var currentID int64
+	if current, err := middleware.GetTokenFromContext(ctx); err == nil {
+		currentID = current.ID
+	}
+
+	sessions := make([]*models.LoginSession, len(tokens))
+	for index, token := range tokens {
+		sessions[index] = &models.LoginSession{
+			ID:        strconv.FormatInt(token.ID, 10),
+			CreatedAt: token.CreatedAt,
+			UserAgent: nullableString(token.UserAgent),
+			IP:        nullableString(token.IP),
+			Location:  nullableString(token.Location),
+			Current:   token.ID == currentID,
+		}
+
+		if token.LastUsedAt.Valid {
+			sessions[index].LastUsedAt = &token.LastUsedAt.Time
+		}
+
+		if token.ExpiresAt.Valid {
+			sessions[index].ExpiresAt = &token.ExpiresAt.Time
+		}
+	}
+
+	return sessions, nil
+}
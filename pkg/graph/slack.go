@@ -0,0 +1,212 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// maxSlackRequestSize limits how much of a Slack request is read before its signature is verified
+const maxSlackRequestSize = 1 << 16
+
+// slackActionEndMeeting is the action of the button that ends a meeting started from Slack
+const slackActionEndMeeting = "end_meeting"
+
+// slackInteraction is the part of a Slack interaction payload the backend uses
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// readSlackRequest verifies that a request was signed with SLACK_SIGNING_SECRET and parses its form. It responds to
+// requests that cannot be verified
+func (r *Resolver) readSlackRequest(w http.ResponseWriter, req *http.Request) (url.Values, bool) {
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxSlackRequestSize))
+	if err != nil {
+		r.log(req.Context()).Error().Err(err).Msg("Could not read Slack request")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	err = utils.VerifySlackSignature(body, req.Header.Get("X-Slack-Request-Timestamp"), req.Header.Get("X-Slack-Signature"), viper.GetString("SLACK_SIGNING_SECRET"), time.Now())
+	if err != nil {
+		r.log(req.Context()).Error().Err(err).Msg("Invalid Slack signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, false
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		r.log(req.Context()).Error().Err(err).Msg("Could not parse Slack request")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	return form, true
+}
+
+// replySlack responds to a Slack request with a message
+func (r *Resolver) replySlack(ctx context.Context, w http.ResponseWriter, message *utils.SlackMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(message)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not reply to Slack")
+	}
+}
+
+// slackUser finds the user that signed in with the Slack account that sent a command
+func (r *Resolver) slackUser(ctx context.Context, slackUserID string) (*models.UserAccount, error) {
+	user, err := r.Repos.Users.BySlackID(ctx, slackUserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return user, err
+}
+
+// slackJoinBlocks describes how to join a channel with a passphrase, with a button when FRONTEND_URL is set
+func slackJoinBlocks(text string, passphrase string, button string) []utils.SlackBlock {
+	link := joinURL(passphrase)
+	if link == "" {
+		return []utils.SlackBlock{utils.SlackSection(text + "\nPassphrase: `" + passphrase + "`")}
+	}
+
+	return []utils.SlackBlock{
+		utils.SlackSection(text),
+		utils.SlackActions(utils.SlackButton("join", button, link, "")),
+		utils.SlackContext("<" + link + "|" + link + ">"),
+	}
+}
+
+// SlackCommand is a REST route implementing the /meet slash command. It creates a channel the way createChannel
+// does, titled with the text of the command, and posts its join link in the conversation. The host link is only
+// shown to the user that sent the command.
+// When ENABLE_OAUTH is set, Slack users need to have signed in with Slack, and own the channels they create
+func (r *Resolver) SlackCommand(w http.ResponseWriter, req *http.Request) {
+	form, ok := r.readSlackRequest(w, req)
+	if !ok {
+		return
+	}
+
+	ctx := req.Context()
+	slackUserID := form.Get("user_id")
+	title := strings.TrimSpace(form.Get("text"))
+	if title == "" {
+		title = "Meeting"
+	}
+
+	r.log(ctx).Info().Str("command", form.Get("command")).Str("team", form.Get("team_id")).Str("user", slackUserID).Str("title", title).Msg("Slack command")
+
+	owner, err := r.slackUser(ctx, slackUserID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("user", slackUserID).Msg("Could not find Slack user")
+		r.replySlack(ctx, w, &utils.SlackMessage{Text: "Could not start the meeting, please try again"})
+		return
+	}
+
+	if owner != nil {
+		ctx = middleware.WithUser(ctx, owner)
+	} else if viper.GetBool("ENABLE_OAUTH") {
+		r.replySlack(ctx, w, &utils.SlackMessage{Text: "Sign in to App Builder with Slack to start meetings from Slack"})
+		return
+	}
+
+	enablePSTN := false
+	share, err := (&mutationResolver{r}).CreateChannel(ctx, title, "", &enablePSTN, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Str("user", slackUserID).Msg("Could not create channel from Slack")
+		r.replySlack(ctx, w, &utils.SlackMessage{Text: "Could not start the meeting: " + err.Error()})
+		return
+	}
+
+	escapedTitle := utils.SlackEscape(title)
+	r.replySlack(ctx, w, &utils.SlackMessage{
+		ResponseType: "in_channel",
+		Text:         "<@" + slackUserID + "> started " + escapedTitle,
+		Blocks:       slackJoinBlocks("<@"+slackUserID+"> started *"+escapedTitle+"*", share.Passphrase.View, "Join"),
+	})
+
+	// The host link is posted once the reply has been sent, so that it follows the join link in the conversation
+	responseURL := form.Get("response_url")
+	hostBlocks := slackJoinBlocks("Join *"+escapedTitle+"* as host. Only you can see this link", *share.Passphrase.Host, "Join as host")
+	endButton := utils.SlackButton(slackActionEndMeeting, "End meeting", "", *share.Passphrase.Host)
+	endButton.Style = "danger"
+	hostBlocks = append(hostBlocks, utils.SlackActions(endButton))
+	go func() {
+		err := utils.PostSlackMessage(responseURL, &utils.SlackMessage{
+			ResponseType: "ephemeral",
+			Text:         "Join " + escapedTitle + " as host",
+			Blocks:       hostBlocks,
+		})
+		if err != nil {
+			r.Logger.Error().Err(err).Str("channel", share.Channel).Msg("Could not post host link to Slack")
+		}
+	}()
+}
+
+// SlackInteraction is a REST route that receives the buttons clicked on messages posted by SlackCommand. Slack sends
+// the clicks of link buttons too, which only need to be acknowledged
+func (r *Resolver) SlackInteraction(w http.ResponseWriter, req *http.Request) {
+	form, ok := r.readSlackRequest(w, req)
+	if !ok {
+		return
+	}
+
+	ctx := req.Context()
+	var interaction slackInteraction
+	err := json.Unmarshal([]byte(form.Get("payload")), &interaction)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not parse Slack interaction")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	for _, action := range interaction.Actions {
+		r.log(ctx).Info().Str("type", interaction.Type).Str("action", action.ActionID).Str("user", interaction.User.ID).Msg("Slack interaction")
+		if action.ActionID != slackActionEndMeeting {
+			continue
+		}
+
+		// The button is only shown to the host, and carries their passphrase
+		text := "The meeting has ended"
+		_, err := (&mutationResolver{r}).EndMeeting(ctx, action.Value, nil)
+		if err != nil {
+			r.log(ctx).Debug().Err(err).Str("user", interaction.User.ID).Msg("Could not end meeting from Slack")
+			text = "Could not end the meeting: " + err.Error()
+		}
+
+		err = utils.PostSlackMessage(interaction.ResponseURL, &utils.SlackMessage{ReplaceOriginal: true, Text: text})
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not reply to Slack interaction")
+		}
+	}
+}
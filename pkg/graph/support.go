@@ -0,0 +1,173 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	maxProblemDescription = 5000
+	maxSupportTicketPage  = 500
+	// supportLogPrefix is where client logs are stored in the recording bucket, apart from recordings
+	supportLogPrefix = "support/"
+)
+
+// reportProblem creates a support ticket for a problem reported in a channel. Tickets are created for signed out
+// users too, and are linked to the user when they are signed in. With attachLogs, the ticket reserves a key in the
+// recording bucket and a URL to upload the logs to that key is returned
+func (r *Resolver) reportProblem(ctx context.Context, channelData *models.Channel, description string, uid *int, attachLogs bool) (*models.ProblemReport, error) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Describe the problem")
+	}
+
+	if len(description) > maxProblemDescription {
+		return nil, apierror.New(apierror.CodeBadRequest, "Description must be at most "+strconv.Itoa(maxProblemDescription)+" characters")
+	}
+
+	ticket := models.ChannelSupportTicket{
+		ChannelID:   sql.NullInt64{Int64: channelData.ID, Valid: true},
+		Description: description,
+	}
+
+	if uid != nil {
+		if _, err := r.getParticipant(ctx, channelData.ID, *uid); err != nil {
+			return nil, err
+		}
+		ticket.UID = sql.NullInt64{Int64: int64(*uid), Valid: true}
+	}
+
+	if user, err := middleware.GetUserFromContext(ctx); err == nil {
+		ticket.UserID = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
+
+	var storage utils.StorageProvider
+	if attachLogs {
+		var err error
+		storage, err = utils.GlobalStorageProvider()
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not create storage provider for client logs")
+			return nil, apierror.New(apierror.CodeUnavailable, "Uploading logs is not available")
+		}
+
+		suffix, err := r.IDs.GenerateUUID()
+		if err != nil {
+			r.log(ctx).Error().Err(err).Msg("Could not generate log key")
+			return nil, errInternalServer
+		}
+		ticket.LogKey = sql.NullString{String: supportLogPrefix + channelData.ChannelName + "/" + suffix, Valid: true}
+	}
+
+	err := r.DB.GetContext(ctx, &ticket.ID, "INSERT INTO support_tickets (channel_id, uid, user_id, description, log_key) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		ticket.ChannelID, ticket.UID, ticket.UserID, ticket.Description, ticket.LogKey)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not create support ticket")
+		return nil, errInternalServer
+	}
+
+	report := &models.ProblemReport{TicketID: strconv.FormatInt(ticket.ID, 10)}
+	if storage == nil {
+		return report, nil
+	}
+
+	expiry := time.Duration(viper.GetInt("SUPPORT_LOG_UPLOAD_EXPIRY_MINUTES")) * time.Minute
+	upload, err := storage.PresignUpload(ticket.LogKey.String, expiry)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("Ticket ID", ticket.ID).Msg("Could not presign log upload")
+		return nil, errInternalServer
+	}
+
+	headers := map[string]interface{}{}
+	for name, value := range upload.Headers {
+		headers[name] = value
+	}
+
+	report.LogUpload = &models.LogUpload{
+		URL:       upload.URL,
+		Headers:   headers,
+		ExpiresAt: time.Now().UTC().Add(expiry),
+	}
+
+	return report, nil
+}
+
+// supportTickets lists support tickets, most recent first, optionally only those of a channel. Download URLs of logs
+// are left out when the recording bucket is not configured
+func (r *Resolver) supportTickets(ctx context.Context, channel *string, before *string, limit int) ([]*models.SupportTicket, error) {
+	if limit <= 0 || limit > maxSupportTicketPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxSupportTicketPage))
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		id, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	var tickets []struct {
+		models.ChannelSupportTicket
+		ChannelName sql.NullString `db:"channel_name"`
+	}
+	err := r.DB.SelectContext(ctx, &tickets, `SELECT support_tickets.id, support_tickets.created_at, support_tickets.channel_id, support_tickets.uid,
+		support_tickets.user_id, support_tickets.description, support_tickets.log_key, channels.channel_name
+		FROM support_tickets LEFT JOIN channels ON channels.id = support_tickets.channel_id
+		WHERE ($1::TEXT IS NULL OR channels.channel_name = $1) AND ($2::BIGINT IS NULL OR support_tickets.id < $2)
+		ORDER BY support_tickets.id DESC LIMIT $3`, channel, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not fetch support tickets")
+		return nil, errInternalServer
+	}
+
+	storage, err := utils.GlobalStorageProvider()
+	if err != nil {
+		r.log(ctx).Debug().Err(err).Msg("Recording bucket is not configured, leaving out log URLs")
+	}
+
+	result := make([]*models.SupportTicket, len(tickets))
+	for index, ticket := range tickets {
+		result[index] = &models.SupportTicket{
+			ID:          strconv.FormatInt(ticket.ID, 10),
+			CreatedAt:   ticket.CreatedAt,
+			Channel:     nullableString(ticket.ChannelName),
+			UserID:      nullableID(ticket.UserID),
+			Description: ticket.Description,
+		}
+
+		if ticket.UID.Valid {
+			uid := int(ticket.UID.Int64)
+			result[index].UID = &uid
+		}
+
+		if ticket.LogKey.Valid && storage != nil {
+			logURL, _, err := utils.PresignRecordingURL(storage, ticket.LogKey.String)
+			if err != nil {
+				r.log(ctx).Error().Err(err).Int64("Ticket ID", ticket.ID).Msg("Could not presign log download")
+				continue
+			}
+			result[index].LogURL = &logURL
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/internal/generated"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) ReportProblem(ctx context.Context, passphrase string, description string, uid *int, attachLogs *bool) (*models.ProblemReport, error) {
+	r.log(ctx).Info().Str("mutation", "ReportProblem").Str("passphrase", passphrase).Interface("uid", uid).Msg("")
+
+	channelData, _, err := r.getChannel(ctx, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reportProblem(ctx, channelData, description, uid, attachLogs != nil && *attachLogs)
+}
+
+func (r *queryResolver) SupportTickets(ctx context.Context, channel *string, before *string, limit *int) ([]*models.SupportTicket, error) {
+	r.log(ctx).Info().Str("query", "SupportTickets").Interface("channel", channel).Msg("")
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.supportTickets(ctx, channel, before, pageSize)
+}
+
+func (r *supportTicketResolver) ChannelDetails(ctx context.Context, obj *models.SupportTicket) (*models.AdminChannel, error) {
+	return r.adminChannelByName(ctx, obj.Channel)
+}
+
+func (r *supportTicketResolver) User(ctx context.Context, obj *models.SupportTicket) (*models.User, error) {
+	return r.userByID(ctx, obj.UserID)
+}
+
+// SupportTicket returns generated.SupportTicketResolver implementation.
+func (r *Resolver) SupportTicket() generated.SupportTicketResolver { return &supportTicketResolver{r} }
+
+type supportTicketResolver struct{ *Resolver }
@@ -0,0 +1,224 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
+)
+
+// transcriptionColumns lists the columns of the transcriptions table that are mapped onto models.Transcription
+const transcriptionColumns = "id, created_at, channel_id, task_id, builder_token, language, file_prefix, status, stopped_at"
+
+// transcriptionLanguage matches language codes such as en-US
+var transcriptionLanguage = regexp.MustCompile("^[a-z]{2,3}-[A-Z]{2}$")
+
+// errTranscriptionRunning is returned when starting a transcription on a channel that is already transcribed
+var errTranscriptionRunning = apierror.New(apierror.CodeBadRequest, "Transcription is already running")
+
+// startTranscription starts captioning a channel in the given language. The transcript is uploaded to the storage of
+// the channel under a prefix that is unique to the transcription
+func (r *Resolver) startTranscription(ctx context.Context, channelData *models.Channel, language string) (*models.Transcription, error) {
+	var running bool
+	err := r.DB.GetContext(ctx, &running, "SELECT EXISTS (SELECT 1 FROM transcriptions WHERE channel_id = $1 AND status = $2)", channelData.ID, models.StreamRunning)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not check transcriptions")
+		return nil, errInternalServer
+	}
+
+	if running {
+		return nil, errTranscriptionRunning
+	}
+
+	storage, err := r.channelStorage(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber, err := r.Tokens.GenerateUserCredentials(project, channelData.ChannelName, rtctoken.RoleSubscriber, utils.TokenExpiry(channelData), false, false, utils.ChannelArea(channelData))
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not generate transcription credentials")
+		return nil, errInternalServer
+	}
+
+	publisher, err := r.Tokens.GenerateUserCredentials(project, channelData.ChannelName, rtctoken.RolePublisher, utils.TokenExpiry(channelData), false, false, utils.ChannelArea(channelData))
+	if err != nil {
+		r.Logger.Error().Err(err).Msg("Could not generate transcription credentials")
+		return nil, errInternalServer
+	}
+
+	prefix := []string{"transcripts", channelData.ChannelName, strconv.FormatInt(time.Now().Unix(), 10)}
+	task, err := r.Agora.StartTranscription(project, channelData.ChannelName, language, subscriber, publisher, storage.StorageConfig(prefix))
+	if err != nil {
+		r.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not start transcription")
+		return nil, agoraError(err)
+	}
+
+	var transcription models.Transcription
+	err = r.DB.GetContext(ctx, &transcription, "INSERT INTO transcriptions (channel_id, task_id, builder_token, language, file_prefix, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING "+transcriptionColumns,
+		channelData.ID, task.TaskID, task.BuilderToken, language, strings.Join(prefix, "/"), models.StreamRunning)
+	if err != nil {
+		r.Logger.Error().Err(err).Str("task", task.TaskID).Msg("Could not store transcription")
+
+		// A transcription that is not stored cannot be stopped later, so it is stopped right away
+		if err := r.Agora.StopTranscription(project, task.TaskID, task.BuilderToken); err != nil {
+			r.Logger.Error().Err(err).Str("task", task.TaskID).Msg("Could not stop transcription")
+		}
+
+		return nil, errInternalServer
+	}
+
+	return &transcription, nil
+}
+
+// stopTranscriptions stops every running transcription of a channel and returns how many were stopped
+func (r *Resolver) stopTranscriptions(ctx context.Context, channelData *models.Channel) (int, error) {
+	transcriptions := []models.Transcription{}
+	err := r.DB.SelectContext(ctx, &transcriptions, "SELECT "+transcriptionColumns+" FROM transcriptions WHERE channel_id = $1 AND status = $2", channelData.ID, models.StreamRunning)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch transcriptions")
+		return 0, errInternalServer
+	}
+
+	project, err := r.channelProject(ctx, channelData)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, transcription := range transcriptions {
+		err = r.Agora.StopTranscription(project, transcription.TaskID, transcription.BuilderToken)
+		if err != nil && err != utils.ErrTranscriptionNotFound {
+			r.Logger.Error().Err(err).Str("task", transcription.TaskID).Msg("Could not stop transcription")
+			return 0, agoraError(err)
+		}
+
+		_, err = r.DB.ExecContext(ctx, "UPDATE transcriptions SET status = $1, stopped_at = NOW() WHERE id = $2", models.StreamStopped, transcription.ID)
+		if err != nil {
+			r.Logger.Error().Err(err).Str("task", transcription.TaskID).Msg("Could not update transcription")
+			return 0, errInternalServer
+		}
+	}
+
+	return len(transcriptions), nil
+}
+
+// transcriptFiles lists the transcript files uploaded for every transcription of a channel with download URLs
+func (r *Resolver) transcriptFiles(ctx context.Context, channelData *models.Channel) ([]*models.TranscriptFile, error) {
+	transcriptions := []models.Transcription{}
+	err := r.DB.SelectContext(ctx, &transcriptions, "SELECT "+transcriptionColumns+" FROM transcriptions WHERE channel_id = $1 ORDER BY created_at", channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch transcriptions")
+		return nil, errInternalServer
+	}
+
+	files := []*models.TranscriptFile{}
+	if len(transcriptions) == 0 {
+		return files, nil
+	}
+
+	storage, err := r.channelStorage(ctx, channelData)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transcription := range transcriptions {
+		keys, err := storage.ListObjects(transcription.FilePrefix + "/")
+		if err != nil {
+			r.Logger.Error().Err(err).Str("prefix", transcription.FilePrefix).Msg("Could not list transcript files")
+			return nil, errInternalServer
+		}
+
+		for _, key := range keys {
+			signedURL, expiresAt, err := utils.PresignRecordingURL(storage, key)
+			if err != nil {
+				r.Logger.Error().Err(err).Str("key", key).Msg("Could not presign transcript file")
+				return nil, errInternalServer
+			}
+
+			files = append(files, &models.TranscriptFile{
+				FileName:  path.Base(key),
+				Language:  transcription.Language,
+				CreatedAt: transcription.CreatedAt,
+				URL:       signedURL,
+				ExpiresAt: expiresAt,
+			})
+		}
+	}
+
+	return files, nil
+}
+
+// recordingTranscripts returns the transcripts of every recording session of a channel, oldest first
+func (r *Resolver) recordingTranscripts(ctx context.Context, channelData *models.Channel) ([]*models.RecordingTranscript, error) {
+	transcripts := []models.ChannelTranscript{}
+	err := r.DB.SelectContext(ctx, &transcripts, "SELECT id, created_at, updated_at, channel_id, sid, status, error FROM recording_transcripts WHERE channel_id = $1 ORDER BY created_at", channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch recording transcripts")
+		return nil, errInternalServer
+	}
+
+	segments := []models.ChannelTranscriptSegment{}
+	err = r.DB.SelectContext(ctx, &segments, `SELECT recording_transcript_segments.id, recording_transcript_segments.transcript_id, recording_transcript_segments.start_ms,
+		recording_transcript_segments.end_ms, recording_transcript_segments.text FROM recording_transcript_segments
+		INNER JOIN recording_transcripts ON recording_transcripts.id = recording_transcript_segments.transcript_id
+		WHERE recording_transcripts.channel_id = $1 ORDER BY recording_transcript_segments.start_ms`, channelData.ID)
+	if err != nil {
+		r.Logger.Error().Err(err).Int64("Channel ID", channelData.ID).Msg("Could not fetch recording transcript segments")
+		return nil, errInternalServer
+	}
+
+	result := []*models.RecordingTranscript{}
+	byID := map[int64]*models.RecordingTranscript{}
+	for _, transcript := range transcripts {
+		recordingTranscript := &models.RecordingTranscript{
+			Status:    transcript.Status,
+			CreatedAt: transcript.CreatedAt,
+			Segments:  []*models.TranscriptSegment{},
+		}
+
+		if transcript.Error.Valid {
+			message := transcript.Error.String
+			recordingTranscript.Error = &message
+		}
+
+		byID[transcript.ID] = recordingTranscript
+		result = append(result, recordingTranscript)
+	}
+
+	for _, segment := range segments {
+		transcript, ok := byID[segment.TranscriptID]
+		if !ok {
+			continue
+		}
+
+		transcript.Segments = append(transcript.Segments, &models.TranscriptSegment{
+			Start: float64(segment.StartMs) / 1000,
+			End:   float64(segment.EndMs) / 1000,
+			Text:  segment.Text,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,338 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// recoveryCodeCount is the number of recovery codes a user gets when enabling two factor authentication
+const recoveryCodeCount = 10
+
+var errTwoFactorRequired = apierror.New(apierror.CodeTwoFactorRequired, "Two factor code required")
+
+var errInvalidTwoFactorCode = apierror.New(apierror.CodeInvalidCredentials, "Invalid two factor code")
+
+var errTwoFactorNotEnabled = apierror.New(apierror.CodeBadRequest, "Two factor authentication is not enabled")
+
+var errTwoFactorEnabled = apierror.New(apierror.CodeBadRequest, "Two factor authentication is already enabled")
+
+// twoFactorLockout returns how long the second factor of a user is locked after an invalid code. The lock starts at a
+// minute once the user entered TWO_FACTOR_LOCKOUT_AFTER invalid codes in a row and doubles with every further one, up
+// to TWO_FACTOR_MAX_LOCKOUT_MINUTES
+func twoFactorLockout(failures int) time.Duration {
+	maxWait := time.Duration(viper.GetInt("TWO_FACTOR_MAX_LOCKOUT_MINUTES")) * time.Minute
+	return backoff(failures, viper.GetInt("TWO_FACTOR_LOCKOUT_AFTER"), time.Minute, maxWait)
+}
+
+// useTwoFactorCode checks a TOTP code of the enrolled secret of a user, then a recovery code, and marks the code used
+func (r *Resolver) useTwoFactorCode(ctx context.Context, tx *sqlx.Tx, userID int64, state *models.TwoFactorState, code string) (bool, error) {
+	secret, err := utils.Decrypt(state.Secret.String)
+	if err != nil {
+		return false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTP(secret, code, time.Now(), state.LastStep); ok {
+		err = r.Repos.WithTx(tx).Users.SetTOTPStep(ctx, userID, step)
+		return err == nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL", userID, utils.HashSecret(strings.ToLower(code)))
+	if err != nil {
+		return false, err
+	}
+
+	used, err := result.RowsAffected()
+	return used == 1, err
+}
+
+// checkTwoFactor requires a valid second factor code from users that enabled two factor authentication.
+// Invalid codes are counted and lock the second factor for a while once there are too many of them.
+// It reports whether the user has two factor authentication enabled
+func (r *Resolver) checkTwoFactor(ctx context.Context, userID int64, code string) (bool, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return false, errInternalServer
+	}
+	defer tx.Rollback()
+
+	// The second factor is locked until tx ends, so that a code can only be used once
+	state, err := r.Repos.WithTx(tx).Users.LockTwoFactor(ctx, userID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not look up two factor state")
+		return false, errInternalServer
+	}
+
+	if !state.EnabledAt.Valid {
+		return false, nil
+	}
+
+	if code == "" {
+		return true, errTwoFactorRequired
+	}
+
+	if state.LockedUntil.Valid {
+		if wait := time.Until(state.LockedUntil.Time); wait > 0 {
+			r.log(ctx).Info().Int64("User ID", userID).Dur("wait", wait).Msg("Two factor code refused")
+			return true, tooManyAttempts(wait)
+		}
+	}
+
+	ok, err := r.useTwoFactorCode(ctx, tx, userID, state, code)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not check two factor code")
+		return true, errInternalServer
+	}
+
+	users := r.Repos.WithTx(tx).Users
+	if !ok {
+		// The failure is committed, since the code is otherwise rolled back along with it
+		failures := state.Failures + 1
+		lockedUntil := sql.NullTime{}
+		if wait := twoFactorLockout(failures); wait > 0 {
+			lockedUntil = sql.NullTime{Time: time.Now().Add(wait), Valid: true}
+		}
+
+		err = users.RecordTwoFactorFailure(ctx, userID, lockedUntil)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not record invalid two factor code")
+			return true, errInternalServer
+		}
+
+		r.log(ctx).Info().Int64("User ID", userID).Int("failures", failures).Msg("Invalid two factor code")
+		return true, errInvalidTwoFactorCode
+	}
+
+	if state.Failures > 0 {
+		err = users.ResetTwoFactorFailures(ctx, userID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not reset invalid two factor codes")
+			return true, errInternalServer
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", userID).Msg("Could not commit two factor code")
+		return true, errInternalServer
+	}
+
+	return true, nil
+}
+
+// TwoFactor implements the twoFactor directive, which only resolves a field for users that enabled two factor
+// authentication when the request carries a valid code. Requests without a user are left to the resolver to check
+func (r *Resolver) TwoFactor(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	user, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		return next(ctx)
+	}
+
+	if _, err := r.checkTwoFactor(ctx, user.ID, middleware.GetTwoFactorCode(ctx)); err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// replaceRecoveryCodes invalidates the recovery codes of a user and generates new ones
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int64) ([]string, error) {
+	_, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for index := range codes {
+		secret, err := utils.GenerateSecret("")
+		if err != nil {
+			return nil, err
+		}
+
+		codes[index] = secret[:8] + "-" + secret[8:16]
+		_, err = tx.ExecContext(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, utils.HashSecret(codes[index]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// enrollTwoFactor generates a TOTP secret for a user, which protects the account once it is confirmed
+func (r *Resolver) enrollTwoFactor(ctx context.Context, user *models.UserAccount) (*models.TwoFactorEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate TOTP secret")
+		return nil, errInternalServer
+	}
+
+	encrypted, err := utils.Encrypt(secret)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not encrypt TOTP secret")
+		return nil, apierror.New(apierror.CodeUnavailable, "Two factor authentication is not available")
+	}
+
+	err = r.Repos.Users.SetTOTPSecret(ctx, user.ID, encrypted)
+	if err == sql.ErrNoRows {
+		return nil, errTwoFactorEnabled
+	}
+
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not store TOTP secret")
+		return nil, errInternalServer
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Identifier
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret: secret,
+		URL:    utils.TOTPURL(viper.GetString("TOTP_ISSUER"), account, secret),
+	}, nil
+}
+
+// confirmTwoFactor enables two factor authentication with a code of the enrolled secret and returns recovery codes
+func (r *Resolver) confirmTwoFactor(ctx context.Context, user *models.UserAccount, code string) ([]string, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	state, err := r.Repos.WithTx(tx).Users.LockTwoFactor(ctx, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not look up two factor state")
+		return nil, errInternalServer
+	}
+
+	if state.EnabledAt.Valid {
+		return nil, errTwoFactorEnabled
+	}
+
+	if !state.Secret.Valid {
+		return nil, apierror.New(apierror.CodeBadRequest, "Enroll in two factor authentication first")
+	}
+
+	secret, err := utils.Decrypt(state.Secret.String)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not decrypt TOTP secret")
+		return nil, errInternalServer
+	}
+
+	step, ok := utils.ValidateTOTP(secret, strings.TrimSpace(code), time.Now(), state.LastStep)
+	if !ok {
+		return nil, errInvalidTwoFactorCode
+	}
+
+	err = r.Repos.WithTx(tx).Users.EnableTwoFactor(ctx, user.ID, step)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not enable two factor authentication")
+		return nil, errInternalServer
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, tx, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not generate recovery codes")
+		return nil, errInternalServer
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not commit two factor authentication")
+		return nil, errInternalServer
+	}
+
+	return codes, nil
+}
+
+// disableTwoFactor turns two factor authentication off with a TOTP or recovery code
+func (r *Resolver) disableTwoFactor(ctx context.Context, user *models.UserAccount, code string) (string, error) {
+	enabled, err := r.checkTwoFactor(ctx, user.ID, code)
+	if err != nil {
+		return "", err
+	}
+
+	if !enabled {
+		return "", errTwoFactorNotEnabled
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return "", errInternalServer
+	}
+	defer tx.Rollback()
+
+	err = r.Repos.WithTx(tx).Users.DisableTwoFactor(ctx, user.ID)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", user.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not disable two factor authentication")
+		return "", errInternalServer
+	}
+
+	return "success", nil
+}
+
+// regenerateRecoveryCodes replaces the recovery codes of a user that enabled two factor authentication
+func (r *Resolver) regenerateRecoveryCodes(ctx context.Context, user *models.UserAccount) ([]string, error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not start transaction")
+		return nil, errInternalServer
+	}
+	defer tx.Rollback()
+
+	state, err := r.Repos.WithTx(tx).Users.LockTwoFactor(ctx, user.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not look up two factor state")
+		return nil, errInternalServer
+	}
+
+	if !state.EnabledAt.Valid {
+		return nil, errTwoFactorNotEnabled
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, tx, user.ID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("User ID", user.ID).Msg("Could not generate recovery codes")
+		return nil, errInternalServer
+	}
+
+	return codes, nil
+}
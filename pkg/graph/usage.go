@@ -0,0 +1,124 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/services"
+)
+
+// usagePeriodLayout is the format of the months usage is reported for
+const usagePeriodLayout = "2006-01"
+
+// quotaNames describe the metrics in quota errors
+var quotaNames = map[models.UsageMetric]string{
+	models.UsageChannels:           "channel",
+	models.UsageRecordingMinutes:   "recording minute",
+	models.UsageParticipantMinutes: "participant minute",
+	models.UsagePSTNCalls:          "phone call",
+}
+
+// checkQuota returns a QUOTA_EXCEEDED error when the subject has used up its monthly quota of a metric. The usage is
+// read with db, which is the transaction of callers that hold a lock so that the check does not wait for another
+// connection of the pool
+func (r *Resolver) checkQuota(ctx context.Context, db sqlx.QueryerContext, subject services.UsageSubject, metric models.UsageMetric) error {
+	// Usage is only metered on Postgres, so there are no quotas without it
+	if !r.DB.Postgres() {
+		return nil
+	}
+
+	exceeded, err := services.QuotaExceeded(ctx, db, subject, metric)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Str("metric", string(metric)).Msg("Could not check quota")
+		return errInternalServer
+	}
+
+	if exceeded {
+		r.log(ctx).Debug().Interface("subject", subject).Str("metric", string(metric)).Msg("Quota exceeded")
+		return apierror.New(apierror.CodeQuotaExceeded, "Monthly "+quotaNames[metric]+" quota exceeded")
+	}
+
+	return nil
+}
+
+// quota returns a monthly quota under a plan for the API, which is nil when the metric is unlimited
+func quota(record *models.BillingPlan, metric models.UsageMetric) *int {
+	limit := int(services.PlanQuota(record, metric))
+	if limit <= 0 {
+		return nil
+	}
+
+	return &limit
+}
+
+// planQuota returns the monthly quotas under a plan, or the default quotas when there is no plan
+func planQuota(record *models.BillingPlan) *models.UsageQuota {
+	return &models.UsageQuota{
+		Channels:           quota(record, models.UsageChannels),
+		RecordingMinutes:   quota(record, models.UsageRecordingMinutes),
+		ParticipantMinutes: quota(record, models.UsageParticipantMinutes),
+		PstnCalls:          quota(record, models.UsagePSTNCalls),
+	}
+}
+
+// usage reports the usage of the user, or of an organization the user is a member of, in a month
+func (r *Resolver) usage(ctx context.Context, user *models.UserAccount, period *string, organizationID *string) (*models.Usage, error) {
+	start := services.MonthStart(time.Now())
+	if period != nil {
+		month, err := time.Parse(usagePeriodLayout, *period)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Period must be formatted as YYYY-MM")
+		}
+		start = month
+	}
+
+	subject := services.UsageSubject{UserID: sql.NullInt64{Int64: user.ID, Valid: true}}
+	if organizationID != nil {
+		id, _, err := r.memberOf(ctx, r.DB, user, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+		subject = services.UsageSubject{OrganizationID: sql.NullInt64{Int64: id, Valid: true}}
+	}
+
+	totals, err := services.UsageTotals(ctx, r.DB, subject, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not fetch usage")
+		return nil, errInternalServer
+	}
+
+	record, err := services.ActivePlan(ctx, r.DB, subject)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not fetch active plan")
+		return nil, errInternalServer
+	}
+
+	result := &models.Usage{
+		Period:             start.Format(usagePeriodLayout),
+		Channels:           int(totals[models.UsageChannels]),
+		RecordingMinutes:   totals[models.UsageRecordingMinutes],
+		ParticipantMinutes: totals[models.UsageParticipantMinutes],
+		PstnCalls:          int(totals[models.UsagePSTNCalls]),
+		Quota:              planQuota(record),
+	}
+
+	if record != nil {
+		result.Plan = plan(record)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,288 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// webhookSecretPrefix marks the secrets webhook requests are signed with
+const webhookSecretPrefix = "whsec_"
+
+// maxWebhookDeliveryPage is the largest page of webhook deliveries that can be requested
+const maxWebhookDeliveryPage = 100
+
+var errWebhookNotFound = apierror.New(apierror.CodeNotFound, "Webhook not found")
+
+// webhookEvents maps the events of the API onto the events stored and delivered
+var webhookEvents = map[models.WebhookEvent]string{
+	models.WebhookEventChannelCreated:     models.WebhookChannelCreated,
+	models.WebhookEventMeetingStarted:     models.WebhookMeetingStarted,
+	models.WebhookEventMeetingEnded:       models.WebhookMeetingEnded,
+	models.WebhookEventRecordingStarted:   models.WebhookRecordingStarted,
+	models.WebhookEventRecordingAvailable: models.WebhookRecordingAvailable,
+	models.WebhookEventFeedbackSubmitted:  models.WebhookFeedbackSubmitted,
+}
+
+// webhookEvent maps a stored event onto the API
+func webhookEvent(event string) models.WebhookEvent {
+	for apiEvent, stored := range webhookEvents {
+		if stored == event {
+			return apiEvent
+		}
+	}
+
+	return models.WebhookEvent(event)
+}
+
+// webhook maps a stored webhook onto the schema
+func webhook(stored *models.WebhookEndpoint) *models.Webhook {
+	result := &models.Webhook{
+		ID:             strconv.FormatInt(stored.ID, 10),
+		URL:            stored.URL,
+		Events:         []models.WebhookEvent{},
+		OrganizationID: nullableID(stored.OrganizationID),
+		APIKeyID:       nullableID(stored.APIKeyID),
+		CreatedAt:      stored.CreatedAt,
+	}
+
+	for _, event := range stored.Events {
+		result.Events = append(result.Events, webhookEvent(event))
+	}
+
+	return result
+}
+
+// webhooks lists the webhooks of an organization the user manages, or of the API keys of the user
+func (r *Resolver) webhooks(ctx context.Context, user *models.UserAccount, organizationID *string) ([]*models.Webhook, error) {
+	stored := []models.WebhookEndpoint{}
+	if organizationID != nil {
+		id, role, err := r.memberOf(ctx, r.DB, user, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !manages(role) {
+			return nil, errNotOrganizationAdmin
+		}
+
+		err = r.DB.SelectContext(ctx, &stored, "SELECT * FROM webhooks WHERE organization_id = $1 ORDER BY id", id)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("Organization ID", id).Msg("Could not list webhooks")
+			return nil, errInternalServer
+		}
+	} else {
+		err := r.DB.SelectContext(ctx, &stored, `SELECT webhooks.* FROM webhooks INNER JOIN api_keys ON api_keys.id = webhooks.api_key_id
+			WHERE api_keys.user_id = $1 AND api_keys.revoked_at IS NULL ORDER BY webhooks.id`, user.ID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not list webhooks")
+			return nil, errInternalServer
+		}
+	}
+
+	result := make([]*models.Webhook, len(stored))
+	for index := range stored {
+		result[index] = webhook(&stored[index])
+	}
+
+	return result, nil
+}
+
+// ownedWebhook returns a webhook of an API key of the user or of an organization the user manages
+func (r *Resolver) ownedWebhook(ctx context.Context, user *models.UserAccount, id string) (*models.WebhookEndpoint, error) {
+	webhookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, errWebhookNotFound
+	}
+
+	var stored models.WebhookEndpoint
+	err = r.DB.GetContext(ctx, &stored, `SELECT webhooks.* FROM webhooks LEFT JOIN api_keys ON api_keys.id = webhooks.api_key_id
+		WHERE webhooks.id = $1 AND (api_keys.user_id = $2 OR webhooks.organization_id IN (
+			SELECT organization_id FROM organization_members WHERE user_id = $2 AND role = ANY($3)))`,
+		webhookID, user.ID, pq.Array([]string{models.OrganizationRoleOwner.String(), models.OrganizationRoleAdmin.String()}))
+	if err == sql.ErrNoRows {
+		return nil, errWebhookNotFound
+	}
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("webhook", webhookID).Msg("Could not fetch webhook")
+		return nil, errInternalServer
+	}
+
+	return &stored, nil
+}
+
+// createWebhook registers a webhook for an organization the user manages or for an API key of the user
+func (r *Resolver) createWebhook(ctx context.Context, user *models.UserAccount, webhookURL string, events []models.WebhookEvent, organizationID *string, apiKeyID *string) (*models.CreatedWebhook, error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "Webhook URL must be an https URL")
+	}
+
+	if err := utils.CheckWebhookHost(ctx, parsed.Hostname()); err != nil {
+		return nil, apierror.New(apierror.CodeBadRequest, err.Error())
+	}
+
+	if len(events) == 0 {
+		return nil, apierror.New(apierror.CodeBadRequest, "Webhooks need at least one event")
+	}
+
+	if (organizationID == nil) == (apiKeyID == nil) {
+		return nil, apierror.New(apierror.CodeBadRequest, "Webhooks belong to either an organization or an API key")
+	}
+
+	stored := models.WebhookEndpoint{URL: webhookURL, Events: pq.StringArray{}}
+	for _, event := range events {
+		stored.Events = append(stored.Events, webhookEvents[event])
+	}
+
+	if organizationID != nil {
+		id, role, err := r.memberOf(ctx, r.DB, user, *organizationID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !manages(role) {
+			return nil, errNotOrganizationAdmin
+		}
+		stored.OrganizationID = sql.NullInt64{Int64: id, Valid: true}
+	} else {
+		keyID, err := strconv.ParseInt(*apiKeyID, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeNotFound, "API key not found")
+		}
+
+		var owned bool
+		err = r.DB.GetContext(ctx, &owned, "SELECT EXISTS (SELECT 1 FROM api_keys WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL)", keyID, user.ID)
+		if err != nil {
+			r.log(ctx).Error().Err(err).Int64("key", keyID).Msg("Could not fetch API key")
+			return nil, errInternalServer
+		}
+
+		if !owned {
+			return nil, apierror.New(apierror.CodeNotFound, "API key not found")
+		}
+		stored.APIKeyID = sql.NullInt64{Int64: keyID, Valid: true}
+	}
+
+	secret, err := utils.GenerateSecret(webhookSecretPrefix)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not generate webhook secret")
+		return nil, errInternalServer
+	}
+
+	stored.Secret, err = utils.Encrypt(secret)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not encrypt webhook secret")
+		return nil, errInternalServer
+	}
+
+	insert, err := r.DB.PrepareNamedContext(ctx, "INSERT INTO webhooks (organization_id, api_key_id, url, secret, events) VALUES (:organization_id, :api_key_id, :url, :secret, :events) RETURNING id, created_at")
+	if err != nil {
+		r.log(ctx).Error().Err(err).Msg("Could not prepare webhook insert")
+		return nil, errInternalServer
+	}
+	defer insert.Close()
+
+	err = insert.GetContext(ctx, &stored, stored)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("user", user.ID).Msg("Could not create webhook")
+		return nil, errInternalServer
+	}
+
+	return &models.CreatedWebhook{
+		Webhook: webhook(&stored),
+		Secret:  secret,
+	}, nil
+}
+
+// deleteWebhook deletes a webhook along with its delivery log
+func (r *Resolver) deleteWebhook(ctx context.Context, user *models.UserAccount, id string) error {
+	stored, err := r.ownedWebhook(ctx, user, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1", stored.ID)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("webhook", stored.ID).Msg("Could not delete webhook")
+		return errInternalServer
+	}
+
+	return nil
+}
+
+// webhookDeliveries lists the deliveries of a webhook from the newest, before the delivery with the ID before
+func (r *Resolver) webhookDeliveries(ctx context.Context, user *models.UserAccount, webhookID string, before *string, limit int) ([]*models.WebhookDelivery, error) {
+	if limit <= 0 || limit > maxWebhookDeliveryPage {
+		return nil, apierror.New(apierror.CodeBadRequest, "Limit must be between 1 and "+strconv.Itoa(maxWebhookDeliveryPage))
+	}
+
+	stored, err := r.ownedWebhook(ctx, user, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := sql.NullInt64{}
+	if before != nil {
+		deliveryID, err := strconv.ParseInt(*before, 10, 64)
+		if err != nil {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid cursor")
+		}
+		cursor = sql.NullInt64{Int64: deliveryID, Valid: true}
+	}
+
+	deliveries := []models.WebhookDeliveryLog{}
+	err = r.DB.SelectContext(ctx, &deliveries, "SELECT * FROM webhook_deliveries WHERE webhook_id = $1 AND ($2::INT IS NULL OR id < $2) ORDER BY id DESC LIMIT $3", stored.ID, cursor, limit)
+	if err != nil {
+		r.log(ctx).Error().Err(err).Int64("webhook", stored.ID).Msg("Could not list webhook deliveries")
+		return nil, errInternalServer
+	}
+
+	result := make([]*models.WebhookDelivery, len(deliveries))
+	for index := range deliveries {
+		delivery := &deliveries[index]
+		result[index] = &models.WebhookDelivery{
+			ID:        strconv.FormatInt(delivery.ID, 10),
+			EventID:   delivery.EventID,
+			Event:     webhookEvent(delivery.Event),
+			Payload:   delivery.Payload,
+			Attempts:  delivery.Attempts,
+			CreatedAt: delivery.CreatedAt,
+		}
+
+		if delivery.NextAttemptAt.Valid {
+			result[index].NextAttemptAt = &delivery.NextAttemptAt.Time
+		}
+		if delivery.DeliveredAt.Valid {
+			result[index].DeliveredAt = &delivery.DeliveredAt.Time
+		}
+		if delivery.FailedAt.Valid {
+			result[index].FailedAt = &delivery.FailedAt.Time
+		}
+		if delivery.LastStatus.Valid {
+			status := int(delivery.LastStatus.Int32)
+			result[index].LastStatus = &status
+		}
+		if delivery.LastError.Valid {
+			result[index].LastError = &delivery.LastError.String
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+func (r *mutationResolver) CreateWebhook(ctx context.Context, url string, events []models.WebhookEvent, organizationID *string, apiKeyID *string) (*models.CreatedWebhook, error) {
+	r.log(ctx).Info().Str("mutation", "CreateWebhook").Str("url", url).Interface("events", events).Interface("organizationId", organizationID).Interface("apiKeyId", apiKeyID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.createWebhook(ctx, authUser, url, events, organizationID, apiKeyID)
+}
+
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, webhookID string) (string, error) {
+	r.log(ctx).Info().Str("mutation", "DeleteWebhook").Str("webhookId", webhookID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return "", errInvalidToken
+	}
+
+	err = r.deleteWebhook(ctx, authUser, webhookID)
+	if err != nil {
+		return "", err
+	}
+
+	return "success", nil
+}
+
+func (r *queryResolver) Webhooks(ctx context.Context, organizationID *string) ([]*models.Webhook, error) {
+	r.log(ctx).Info().Str("query", "Webhooks").Interface("organizationId", organizationID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	return r.webhooks(ctx, authUser, organizationID)
+}
+
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID string, before *string, limit *int) ([]*models.WebhookDelivery, error) {
+	r.log(ctx).Info().Str("query", "WebhookDeliveries").Str("webhookId", webhookID).Msg("")
+
+	authUser, err := middleware.GetUserFromContext(ctx)
+	if err != nil {
+		r.log(ctx).Debug().Msg("Invalid Token")
+		return nil, errInvalidToken
+	}
+
+	pageSize := 100
+	if limit != nil {
+		pageSize = *limit
+	}
+
+	return r.webhookDeliveries(ctx, authUser, webhookID, before, pageSize)
+}
@@ -0,0 +1,109 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// Package loaders batches the lookups that resolvers of nested fields make within a request, so that a list of
+// channels fetches the owners of all of them with one statement rather than one statement per channel
+package loaders
+
+//go:generate go run github.com/vektah/dataloaden UserLoader int64 *github.com/samyak-jain/agora_backend/pkg/models.UserAccount
+//go:generate go run github.com/vektah/dataloaden ChannelLoader string *github.com/samyak-jain/agora_backend/pkg/repository.ListedChannel
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+)
+
+type contextKey struct{}
+
+const (
+	// wait is how long a loader collects keys before it fetches them. Resolvers of the items of a list run
+	// concurrently, so they all ask for their key well within it
+	wait = 2 * time.Millisecond
+	// maxBatch bounds the keys fetched with one statement
+	maxBatch = 100
+)
+
+// Loaders caches the users and channels looked up during a request
+type Loaders struct {
+	// Users looks up users by their ID
+	Users *UserLoader
+	// Channels looks up channels by their channel name
+	Channels *ChannelLoader
+}
+
+// New creates the loaders of a request, which fetch from repos with the context of the request
+func New(ctx context.Context, repos *repository.Repositories) *Loaders {
+	return &Loaders{
+		Users: NewUserLoader(UserLoaderConfig{
+			Wait:     wait,
+			MaxBatch: maxBatch,
+			Fetch: func(ids []int64) ([]*models.UserAccount, []error) {
+				users, err := repos.Users.ByIDs(ctx, ids)
+				if err != nil {
+					return nil, []error{err}
+				}
+
+				byID := make(map[int64]*models.UserAccount, len(users))
+				for index := range users {
+					byID[users[index].ID] = &users[index]
+				}
+
+				result := make([]*models.UserAccount, len(ids))
+				for index, id := range ids {
+					result[index] = byID[id]
+				}
+
+				return result, nil
+			},
+		}),
+		Channels: NewChannelLoader(ChannelLoaderConfig{
+			Wait:     wait,
+			MaxBatch: maxBatch,
+			Fetch: func(channelNames []string) ([]*repository.ListedChannel, []error) {
+				channels, err := repos.Channels.ByNames(ctx, channelNames)
+				if err != nil {
+					return nil, []error{err}
+				}
+
+				byName := make(map[string]*repository.ListedChannel, len(channels))
+				for index := range channels {
+					byName[channels[index].ChannelName] = &channels[index]
+				}
+
+				result := make([]*repository.ListedChannel, len(channelNames))
+				for index, channelName := range channelNames {
+					result[index] = byName[channelName]
+				}
+
+				return result, nil
+			},
+		}),
+	}
+}
+
+// Middleware gives every request loaders of its own, so that nothing is cached between requests
+func Middleware(repos *repository.Repositories) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), contextKey{}, New(r.Context(), repos))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// For returns the loaders of the request of ctx, or nil when the request did not pass through Middleware
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(contextKey{}).(*Loaders)
+	return loaders
+}
@@ -0,0 +1,84 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errOperationNotAllowed = "OPERATION_NOT_ALLOWED"
+
+// OperationAllowlist is a gqlgen extension that only executes operations registered in persisted_operations. Clients
+// either send the document of a registered operation, or only its hash in the persistedQuery extension the way
+// automatic persisted queries are sent, in which case the registered document is executed. It replaces the automatic
+// persisted queries extension, which would let clients register operations of their own
+type OperationAllowlist struct {
+	DB     *models.Database
+	Logger *utils.Logger
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = OperationAllowlist{}
+
+// ExtensionName returns the name of the extension
+func (OperationAllowlist) ExtensionName() string {
+	return "OperationAllowlist"
+}
+
+// Validate accepts every schema
+func (OperationAllowlist) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters refuses operations that are not registered and fills in the document of those sent by
+// hash
+func (allowlist OperationAllowlist) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	hash := ""
+	if params.Query != "" {
+		hash = models.OperationHash(params.Query)
+	} else if persistedQuery, ok := params.Extensions["persistedQuery"].(map[string]interface{}); ok {
+		hash, _ = persistedQuery["sha256Hash"].(string)
+	}
+
+	if hash == "" {
+		return errNotAllowed()
+	}
+
+	var document string
+	err := allowlist.DB.GetContext(ctx, &document, "SELECT document FROM persisted_operations WHERE hash = $1", hash)
+	if err == sql.ErrNoRows {
+		GetLogger(ctx, allowlist.Logger).Info().Str("hash", hash).Msg("Refused unregistered operation")
+		return errNotAllowed()
+	}
+
+	if err != nil {
+		GetLogger(ctx, allowlist.Logger).Error().Err(err).Str("hash", hash).Msg("Could not look up operation")
+		return gqlerror.Errorf("internal system error")
+	}
+
+	params.Query = document
+	return nil
+}
+
+func errNotAllowed() *gqlerror.Error {
+	err := gqlerror.Errorf("only registered operations can be executed")
+	errcode.Set(err, errOperationNotAllowed)
+	return err
+}
@@ -0,0 +1,75 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// APIKeyHeader is the header backends authenticate with an API key in
+const APIKeyHeader = "X-API-Key"
+
+var apiKeyContextKey = &contextKey{"apiKey"}
+
+// APIKeyHandler is a middleware that authenticates requests that carry an API key. Like AuthHandler, requests with an
+// invalid key are passed on unauthenticated and refused by the operations that need authentication
+func APIKeyHandler(repos *repository.Repositories, logger *utils.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if r.Method == "OPTIONS" || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(AuthenticateAPIKey(r.Context(), repos, logger, key)))
+		})
+	}
+}
+
+// AuthenticateAPIKey returns ctx with the API key key, or ctx as is when key is not a valid API key. It lets APIs
+// other than HTTP, such as gRPC, authenticate with API keys the same way
+func AuthenticateAPIKey(ctx context.Context, repos *repository.Repositories, logger *utils.Logger, key string) context.Context {
+	apiKey, err := repos.APIKeys.ByHash(ctx, utils.HashSecret(key))
+	if err != nil {
+		logger.Debug().Err(err).Msg("Passed invalid API key")
+		return ctx
+	}
+
+	owner, err := repos.Users.ByID(ctx, apiKey.UserID)
+	if err != nil {
+		logger.Error().Err(err).Int64("id", apiKey.UserID).Int64("key", apiKey.ID).Msg("User does not exist for the provided API key")
+		return ctx
+	}
+	apiKey.Owner = owner
+
+	err = repos.APIKeys.Touch(ctx, apiKey.ID)
+	if err != nil {
+		logger.Error().Err(err).Int64("key", apiKey.ID).Msg("Could not update API key usage")
+	}
+
+	return context.WithValue(ctx, apiKeyContextKey, apiKey)
+}
+
+// GetAPIKeyFromContext fetches the API key a request was authenticated with from the context
+func GetAPIKeyFromContext(ctx context.Context) (*models.APICredential, error) {
+	if apiKey, ok := ctx.Value(apiKeyContextKey).(*models.APICredential); ok {
+		return apiKey, nil
+	}
+
+	return nil, errors.New("No API key")
+}
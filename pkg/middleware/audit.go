@@ -0,0 +1,144 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+var auditContextKey = &contextKey{"audit"}
+
+// auditTarget collects the channel a mutation acts on while it is resolved
+type auditTarget struct {
+	channelID sql.NullInt64
+}
+
+// SetAuditChannel records the channel the mutation being resolved acts on in its audit event. It does nothing outside
+// of a mutation
+func SetAuditChannel(ctx context.Context, channelID int64) {
+	if target, ok := ctx.Value(auditContextKey).(*auditTarget); ok {
+		target.channelID = sql.NullInt64{Int64: channelID, Valid: true}
+	}
+}
+
+// Audit is a GraphQL extension that records an audit event for every mutation with the user or address that made it,
+// the channel it acted on, a hash of its arguments and whether it succeeded
+type Audit struct {
+	DB     *models.Database
+	Logger *utils.Logger
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Audit{}
+
+// ExtensionName returns the name of the extension
+func (Audit) ExtensionName() string {
+	return "Audit"
+}
+
+// Validate accepts every schema
+func (Audit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField records the mutations once they are resolved. Failing to record an event is logged but does not
+// fail the mutation
+func (audit Audit) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	field := graphql.GetFieldContext(ctx)
+	if field == nil || field.Object != "Mutation" {
+		return next(ctx)
+	}
+
+	target := &auditTarget{}
+	result, err := next(context.WithValue(ctx, auditContextKey, target))
+	audit.record(ctx, field.Field.Name, field.Args, target, err)
+
+	return result, err
+}
+
+// Run runs a mutation made outside of GraphQL, such as through the REST API, and records its audit event like those of
+// the GraphQL mutation of the same operation
+func (audit Audit) Run(ctx context.Context, operation string, args map[string]interface{}, mutation func(ctx context.Context) error) error {
+	target := &auditTarget{}
+	err := mutation(context.WithValue(ctx, auditContextKey, target))
+	audit.record(ctx, operation, args, target, err)
+
+	return err
+}
+
+// record records the audit event of a mutation that returned err
+func (audit Audit) record(ctx context.Context, operation string, args map[string]interface{}, target *auditTarget, err error) {
+	event := models.AuditLogEntry{
+		Operation:     operation,
+		ChannelID:     target.channelID,
+		ArgumentsHash: hashArguments(args),
+		Succeeded:     err == nil,
+	}
+
+	if ip := GetClientIP(ctx); ip != "" {
+		event.IP = sql.NullString{String: ip, Valid: true}
+	}
+
+	if requestID := GetRequestID(ctx); requestID != "" {
+		event.RequestID = sql.NullString{String: requestID, Valid: true}
+	}
+
+	if user, userErr := GetUserFromContext(ctx); userErr == nil {
+		event.UserID = sql.NullInt64{Int64: user.ID, Valid: true}
+	} else if apiKey, keyErr := GetAPIKeyFromContext(ctx); keyErr == nil {
+		event.UserID = sql.NullInt64{Int64: apiKey.UserID, Valid: true}
+	}
+
+	if code := apierror.CodeOf(err); code != "" {
+		event.ErrorCode = sql.NullString{String: string(code), Valid: true}
+	}
+
+	// The user is looked up again since mutations such as deleteMyAccount delete it
+	_, dbErr := audit.DB.NamedExecContext(ctx, `INSERT INTO audit_events (user_id, ip, request_id, operation, channel_id, arguments_hash, succeeded, error_code)
+		VALUES ((SELECT id FROM users WHERE id = :user_id), :ip, :request_id, :operation, :channel_id, :arguments_hash, :succeeded, :error_code)`, event)
+	if dbErr != nil {
+		GetLogger(ctx, audit.Logger).Error().Err(dbErr).Str("operation", event.Operation).Msg("Could not record audit event")
+	}
+}
+
+// secretArguments are left out of the hashed arguments. Unlike generated secrets, passwords can be guessed from their hash
+var secretArguments = map[string]bool{
+	"password": true,
+}
+
+// hashArguments returns the SHA-256 of the arguments of a field encoded as JSON, which sorts the keys of maps
+func hashArguments(args map[string]interface{}) string {
+	hashed := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		if !secretArguments[name] {
+			hashed[name] = value
+		}
+	}
+
+	encoded, err := json.Marshal(hashed)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
@@ -15,8 +15,10 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
 	"github.com/samyak-jain/agora_backend/utils"
 
 	"github.com/spf13/viper"
@@ -27,9 +29,13 @@ type contextKey struct {
 }
 
 var userContextKey = &contextKey{"user"}
+var tokenContextKey = &contextKey{"token"}
+
+// tokenUsageInterval limits how often the last use of a token is written, so that not every request is a write
+const tokenUsageInterval = time.Minute
 
 // AuthHandler is a middleware for authentication
-func AuthHandler(db *models.Database, logger *utils.Logger) func(http.Handler) http.Handler {
+func AuthHandler(repos *repository.Repositories, logger *utils.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method == "OPTIONS" {
@@ -50,26 +56,26 @@ func AuthHandler(db *models.Database, logger *utils.Logger) func(http.Handler) h
 				splitToken := strings.Split(header, "Bearer ")
 				token := splitToken[1]
 
-				var tokenData models.Token
-				var user models.User
-
 				// Fetch the token
-				err := db.Get(&tokenData, "SELECT token_id, user_id FROM tokens WHERE token_id=$1", token)
+				tokenData, err := repos.Tokens.ByTokenID(r.Context(), token)
 				if err != nil {
 					logger.Debug().Str("token", token).Msg("Passed Invalid token")
 					next.ServeHTTP(w, r)
 					return
 				}
 
-				err = db.Get(&user, "SELECT id, identifier, user_name, email FROM users WHERE id=$1", tokenData.UserID)
+				user, err := repos.Users.ByID(r.Context(), tokenData.UserID)
 				if err != nil {
 					logger.Error().Int64("id", tokenData.UserID).Str("token", token).Msg("User does not exist for the provided token")
 					next.ServeHTTP(w, r)
 					return
 				}
 
+				recordTokenUsage(repos, logger, r, tokenData)
+
 				logger.Info().Str("token", token).Interface("user", user).Msg("Successfull")
-				ctx := context.WithValue(r.Context(), userContextKey, &user)
+				ctx := context.WithValue(r.Context(), userContextKey, user)
+				ctx = context.WithValue(ctx, tokenContextKey, tokenData)
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
@@ -80,6 +86,35 @@ func AuthHandler(db *models.Database, logger *utils.Logger) func(http.Handler) h
 	}
 }
 
+// recordTokenUsage stores the time, user agent, address and country of a request made with a token so users can
+// recognise their sessions. The country is read from GEOIP_COUNTRY_HEADER, which a CDN in front of the server can set
+func recordTokenUsage(repos *repository.Repositories, logger *utils.Logger, r *http.Request, token *models.Token) {
+	ip := GetClientIP(r.Context())
+	userAgent := r.UserAgent()
+	if token.LastUsedAt.Valid && time.Since(token.LastUsedAt.Time) < tokenUsageInterval && token.IP.String == ip && token.UserAgent.String == userAgent {
+		return
+	}
+
+	var location string
+	if header := viper.GetString("GEOIP_COUNTRY_HEADER"); header != "" {
+		location = r.Header.Get(header)
+	}
+
+	err := repos.Tokens.RecordUsage(r.Context(), token.ID, userAgent, ip, location)
+	if err != nil {
+		logger.Error().Err(err).Int64("token", token.ID).Msg("Could not record token usage")
+	}
+}
+
+// GetTokenFromContext fetches the access token the request was authenticated with from the context
+func GetTokenFromContext(ctx context.Context) (*models.Token, error) {
+	if token, ok := ctx.Value(tokenContextKey).(*models.Token); ok {
+		return token, nil
+	}
+
+	return nil, errors.New("No such token")
+}
+
 // GetUserFromContext fetches the user from the context
 func GetUserFromContext(ctx context.Context) (*models.UserAccount, error) {
 	userObject := ctx.Value(userContextKey)
@@ -89,3 +124,9 @@ func GetUserFromContext(ctx context.Context) (*models.UserAccount, error) {
 
 	return nil, errors.New("No such user")
 }
+
+// WithUser returns a context authenticated as user, for requests that are authenticated without an access token,
+// like Slack commands sent by users that signed in with Slack
+func WithUser(ctx context.Context, user *models.UserAccount) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
@@ -0,0 +1,88 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// SupportedFields is a gqlgen extension that refuses operations selecting root fields other than Fields, which are
+// named like Query.joinChannel. It keeps deployments whose database lacks the tables of some features from running
+// their resolvers, with Reason telling clients why
+type SupportedFields struct {
+	Fields map[string]bool
+	Reason string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = SupportedFields{}
+
+// ExtensionName returns the name of the extension
+func (SupportedFields) ExtensionName() string {
+	return "SupportedFields"
+}
+
+// Validate accepts every schema
+func (SupportedFields) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext refuses the operation when it selects a root field that is not supported
+func (extension SupportedFields) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	operation := rc.Doc.Operations.ForName(rc.OperationName)
+	if operation == nil {
+		return nil
+	}
+
+	object := strings.Title(string(operation.Operation))
+	if field := extension.unsupported(object, operation.SelectionSet); field != "" {
+		err := gqlerror.Errorf("%s is not available: %s", field, extension.Reason)
+		errcode.Set(err, string(apierror.CodeFeatureDisabled))
+		return err
+	}
+
+	return nil
+}
+
+// unsupported returns the first field of a root selection set that is not supported, or an empty string when every
+// field is. Fields of introspection are always supported
+func (extension SupportedFields) unsupported(object string, selectionSet ast.SelectionSet) string {
+	for _, selection := range selectionSet {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(selection.Name, "__") && !extension.Fields[object+"."+selection.Name] {
+				return object + "." + selection.Name
+			}
+		case *ast.InlineFragment:
+			if field := extension.unsupported(object, selection.SelectionSet); field != "" {
+				return field
+			}
+		case *ast.FragmentSpread:
+			if selection.Definition == nil {
+				continue
+			}
+			if field := extension.unsupported(object, selection.Definition.SelectionSet); field != "" {
+				return field
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,106 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimit is a gqlgen extension that refuses operations that nest selections deeper than Limit. Fields of
+// introspection and the selections below them are not counted, so that clients can still fetch the schema
+type DepthLimit struct {
+	Limit int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+// ExtensionName returns the name of the extension
+func (DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate accepts every schema
+func (DepthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext refuses the operation when it is nested too deep
+func (extension DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	operation := rc.Doc.Operations.ForName(rc.OperationName)
+	if operation == nil {
+		return nil
+	}
+
+	if depth := selectionDepth(operation.SelectionSet, map[string]bool{}); depth > extension.Limit {
+		err := gqlerror.Errorf("operation has a depth of %d, which exceeds the limit of %d", depth, extension.Limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+
+	return nil
+}
+
+// selectionDepth is how many fields deep a selection set goes. Fragments already being expanded are skipped, which
+// validation refuses anyway
+func selectionDepth(selectionSet ast.SelectionSet, expanding map[string]bool) int {
+	depth := 0
+	for _, selection := range selectionSet {
+		nested := 0
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			nested = 1 + selectionDepth(selection.SelectionSet, expanding)
+		case *ast.InlineFragment:
+			nested = selectionDepth(selection.SelectionSet, expanding)
+		case *ast.FragmentSpread:
+			if selection.Definition == nil || expanding[selection.Name] {
+				continue
+			}
+			expanding[selection.Name] = true
+			nested = selectionDepth(selection.Definition.SelectionSet, expanding)
+			delete(expanding, selection.Name)
+		}
+
+		if nested > depth {
+			depth = nested
+		}
+	}
+
+	return depth
+}
+
+// BodyLimitHandler is a middleware that refuses request bodies larger than limit bytes. Multipart requests are left to
+// the multipart transport of gqlgen, which limits uploads on its own
+func BodyLimitHandler(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+var clientIPContextKey = &contextKey{"clientIP"}
+
+// ClientIP returns the address of the client that made a request. When TRUST_PROXY is enabled the address added to
+// X-Forwarded-For by the proxy in front of the server is used instead of the address of the connection
+func ClientIP(r *http.Request) string {
+	if viper.GetBool("TRUST_PROXY") {
+		forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+		if ip := strings.TrimSpace(forwarded[len(forwarded)-1]); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// ClientIPHandler is a middleware that stores the address of the client in the context of the request
+func ClientIPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientIPContextKey, ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetClientIP returns the address of the client stored by ClientIPHandler, or an empty string outside of a request
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey).(string)
+	return ip
+}
+
+// unlimitedPrefixes are the routes providers post webhooks to. Providers send them from a few addresses in bursts, like
+// an NCS event for every file of a recording, and retry the ones that are refused, so they are verified by their
+// signature instead of being limited by address
+var unlimitedPrefixes = []string{"/webhooks/", "/slack/"}
+
+// RateLimitHandler is a middleware that limits requests to RATE_LIMIT_USER_PER_MINUTE per user or API key for
+// authenticated requests and to RATE_LIMIT_IP_PER_MINUTE per address otherwise. It has to run after AuthHandler and
+// APIKeyHandler. Requests are let through when Redis cannot be reached so that an outage of Redis does not take the
+// API down with it. Webhooks of providers are not limited
+func RateLimitHandler(limiter *utils.RateLimiter, logger *utils.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" || unlimited(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			key := "ip:" + ClientIP(r)
+			limit := utils.RateLimit(viper.GetInt("RATE_LIMIT_IP_PER_MINUTE"))
+			if user, err := GetUserFromContext(ctx); err == nil {
+				key = "user:" + strconv.FormatInt(user.ID, 10)
+				limit = utils.RateLimit(viper.GetInt("RATE_LIMIT_USER_PER_MINUTE"))
+			} else if apiKey, err := GetAPIKeyFromContext(ctx); err == nil {
+				key = "apikey:" + strconv.FormatInt(apiKey.ID, 10)
+				limit = utils.RateLimit(viper.GetInt("RATE_LIMIT_USER_PER_MINUTE"))
+			}
+
+			allowed, wait, err := limiter.Allow(ctx, key, limit)
+			if err != nil {
+				GetLogger(ctx, logger).Error().Err(err).Str("key", key).Msg("Rate limit check failed")
+			} else if !allowed {
+				GetLogger(ctx, logger).Debug().Str("key", key).Dur("wait", wait).Msg("Rate limit exceeded")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// unlimited reports whether requests to path are left out of the rate limits
+func unlimited(path string) bool {
+	for _, prefix := range unlimitedPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PassphraseRateLimit is a GraphQL extension that limits the fields that take the passphrase of a channel the
+// caller has not joined yet to RATE_LIMIT_PASSPHRASE_PER_MINUTE per address, which slows down guessing passphrases.
+// It relies on ClientIPHandler for the address of the client
+type PassphraseRateLimit struct {
+	Limiter *utils.RateLimiter
+	Logger  *utils.Logger
+	// Fields are the limited fields, as the type and the field name separated by a dot, e.g. Query.joinChannel
+	Fields []string
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = PassphraseRateLimit{}
+
+// ExtensionName returns the name of the extension
+func (PassphraseRateLimit) ExtensionName() string {
+	return "PassphraseRateLimit"
+}
+
+// Validate accepts every schema
+func (PassphraseRateLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField refuses to resolve a limited field once the client has used up its attempts
+func (extension PassphraseRateLimit) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	field := graphql.GetFieldContext(ctx)
+	if field == nil || !field.IsResolver || !extension.limits(field.Object+"."+field.Field.Name) {
+		return next(ctx)
+	}
+
+	err := extension.Check(ctx, field.Field.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// Check counts an attempt at a passphrase by the client of ctx and returns an error once it has used up its attempts.
+// It lets operations made outside of GraphQL, such as through the REST API, share the limit of the fields
+func (extension PassphraseRateLimit) Check(ctx context.Context, operation string) error {
+	ip := GetClientIP(ctx)
+	if ip == "" {
+		return nil
+	}
+
+	key := "passphrase:" + ip
+	allowed, wait, err := extension.Limiter.Allow(ctx, key, utils.RateLimit(viper.GetInt("RATE_LIMIT_PASSPHRASE_PER_MINUTE")))
+	if err != nil {
+		GetLogger(ctx, extension.Logger).Error().Err(err).Str("key", key).Msg("Rate limit check failed")
+		return nil
+	}
+
+	if !allowed {
+		GetLogger(ctx, extension.Logger).Info().Str("key", key).Str("field", operation).Msg("Passphrase rate limit exceeded")
+		return apierror.New(apierror.CodeRateLimited, "Too many attempts, try again in "+strconv.Itoa(int(math.Ceil(wait.Seconds())))+" seconds")
+	}
+
+	return nil
+}
+
+func (extension PassphraseRateLimit) limits(name string) bool {
+	for _, field := range extension.Fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// RequestIDHeader is the header request IDs are read from and returned in
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDContextKey = &contextKey{"requestID"}
+var loggerContextKey = &contextKey{"logger"}
+
+// validRequestID limits the request IDs accepted from clients so that they can be logged safely
+var validRequestID = regexp.MustCompile("^[a-zA-Z0-9._-]{1,128}$")
+
+// RequestIDHandler is a middleware that uses the request ID sent by the client, or generates one, returns it in the
+// response and adds it to a logger stored in the context of the request
+func RequestIDHandler(logger *utils.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				generated, err := utils.GenerateUUID()
+				if err != nil {
+					logger.Error().Err(err).Msg("Request ID generation failed")
+					next.ServeHTTP(w, r)
+					return
+				}
+				requestID = generated
+			}
+
+			w.Header().Set(RequestIDHeader, requestID)
+
+			requestLogger := logger.With().Str("requestId", requestID).Logger()
+			ctx := context.WithValue(r.Context(), requestIDContextKey, requestID)
+			ctx = context.WithValue(ctx, loggerContextKey, &utils.Logger{Logger: &requestLogger})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetRequestID returns the ID of the request ctx belongs to, or an empty string outside of a request
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// GetLogger returns the logger of the request ctx belongs to, which adds the request ID to every entry. The
+// fallback is returned outside of a request
+func GetLogger(ctx context.Context, fallback *utils.Logger) *utils.Logger {
+	if logger, ok := ctx.Value(loggerContextKey).(*utils.Logger); ok {
+		return logger
+	}
+
+	return fallback
+}
@@ -0,0 +1,86 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/samyak-jain/agora_backend/utils"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is a GraphQL extension that records a span for every operation and for every field that has a resolver,
+// so that the time spent in each resolver shows up in the trace of the request
+type Tracing struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracing{}
+
+// ExtensionName returns the name of the extension
+func (Tracing) ExtensionName() string {
+	return "Tracing"
+}
+
+// Validate accepts every schema
+func (Tracing) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse records a span for each response of an operation, which is every message for subscriptions
+func (Tracing) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+
+	operation := graphql.GetOperationContext(ctx)
+	name := operation.OperationName
+	if name == "" && operation.Operation != nil {
+		name = operation.Operation.Name
+	}
+
+	attributes := []label.KeyValue{label.String("graphql.operation.name", name)}
+	if operation.Operation != nil {
+		attributes = append(attributes, label.String("graphql.operation.type", string(operation.Operation.Operation)))
+	}
+
+	ctx, span := utils.StartSpan(ctx, "graphql."+name, trace.WithAttributes(attributes...))
+	response := next(ctx)
+
+	var err error
+	if response != nil && len(response.Errors) > 0 {
+		err = response.Errors
+	}
+	utils.EndSpan(span, err)
+
+	return response
+}
+
+// InterceptField records a span for fields resolved by a resolver. Fields that only read a struct are skipped as
+// they take no time and would drown out the resolvers
+func (Tracing) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	field := graphql.GetFieldContext(ctx)
+	if field == nil || !field.IsResolver {
+		return next(ctx)
+	}
+
+	ctx, span := utils.StartSpan(ctx, field.Object+"."+field.Field.Name, trace.WithAttributes(
+		label.String("graphql.field.path", field.Path().String()),
+	))
+	result, err := next(ctx)
+	utils.EndSpan(span, err)
+
+	return result, err
+}
@@ -0,0 +1,41 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// TwoFactorHeader carries the TOTP or recovery code of the user for operations that require a second factor
+const TwoFactorHeader = "X-Two-Factor-Code"
+
+var twoFactorContextKey = &contextKey{"twoFactor"}
+
+// TwoFactorHandler stores the second factor code sent with a request in its context
+func TwoFactorHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := r.Header.Get(TwoFactorHeader)
+		if code == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), twoFactorContextKey, code)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetTwoFactorCode returns the second factor code stored by TwoFactorHandler, or an empty string if none was sent
+func GetTwoFactorCode(ctx context.Context) string {
+	code, _ := ctx.Value(twoFactorContextKey).(string)
+	return code
+}
@@ -0,0 +1,44 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APICredential is an API key a user created for its own backend. Only the hash of the key is stored
+type APICredential struct {
+	ID         int64          `db:"id"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UserID     int64          `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	// Owner is the user that created the key, which is loaded along with the key when authenticating a request
+	Owner *UserAccount `db:"-"`
+}
+
+// HasScope reports whether the key can be used for the operations of a scope
+func (key *APICredential) HasScope(scope APIKeyScope) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope.String() {
+			return true
+		}
+	}
+
+	return false
+}
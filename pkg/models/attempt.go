@@ -0,0 +1,29 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChannelPassphraseAttempt is a passphrase lookup recorded to slow down guessing passphrases. Failed lookups are
+// recorded without a channel, successful ones only when the client failed recently and may have guessed the passphrase
+type ChannelPassphraseAttempt struct {
+	ID        int64         `db:"id"`
+	CreatedAt time.Time     `db:"created_at"`
+	IP        string        `db:"ip"`
+	ChannelID sql.NullInt64 `db:"channel_id"`
+	Succeeded bool          `db:"succeeded"`
+	// Failures is the number of recent failed lookups of the client before this one
+	Failures int  `db:"failures"`
+	Locked   bool `db:"locked"`
+}
@@ -0,0 +1,31 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuditLogEntry records a mutation for compliance. Arguments are only stored as a hash since they contain
+// passphrases and secrets
+type AuditLogEntry struct {
+	ID            int64          `db:"id"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UserID        sql.NullInt64  `db:"user_id"`
+	IP            sql.NullString `db:"ip"`
+	RequestID     sql.NullString `db:"request_id"`
+	Operation     string         `db:"operation"`
+	ChannelID     sql.NullInt64  `db:"channel_id"`
+	ArgumentsHash string         `db:"arguments_hash"`
+	Succeeded     bool           `db:"succeeded"`
+	ErrorCode     sql.NullString `db:"error_code"`
+}
@@ -0,0 +1,65 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Statuses of a subscription that entitle its subject to the limits of its plan. Past due subscriptions keep their
+// plan while Stripe retries the payment
+var LiveSubscriptionStatuses = []string{"active", "trialing", "past_due"}
+
+// BillingPlan is a plan that can be subscribed to with a Stripe price. Limits that are not set are unlimited
+type BillingPlan struct {
+	ID                         int64         `db:"id"`
+	CreatedAt                  time.Time     `db:"created_at"`
+	Name                       string        `db:"name"`
+	StripePriceID              string        `db:"stripe_price_id"`
+	ChannelsPerMonth           sql.NullInt64 `db:"channels_per_month"`
+	RecordingMinutesPerMonth   sql.NullInt64 `db:"recording_minutes_per_month"`
+	ParticipantMinutesPerMonth sql.NullInt64 `db:"participant_minutes_per_month"`
+	PSTNCallsPerMonth          sql.NullInt64 `db:"pstn_calls_per_month"`
+	Active                     bool          `db:"active"`
+}
+
+// Limit returns the monthly limit of a metric under the plan
+func (plan *BillingPlan) Limit(metric UsageMetric) sql.NullInt64 {
+	switch metric {
+	case UsageChannels:
+		return plan.ChannelsPerMonth
+	case UsageRecordingMinutes:
+		return plan.RecordingMinutesPerMonth
+	case UsageParticipantMinutes:
+		return plan.ParticipantMinutesPerMonth
+	case UsagePSTNCalls:
+		return plan.PSTNCallsPerMonth
+	default:
+		return sql.NullInt64{}
+	}
+}
+
+// StripeSubscription is the Stripe subscription of a user or an organization to a plan, as last reported by
+// Stripe webhooks. EventAt is when the event the subscription was last updated from was created
+type StripeSubscription struct {
+	ID                   int64         `db:"id"`
+	CreatedAt            time.Time     `db:"created_at"`
+	UpdatedAt            time.Time     `db:"updated_at"`
+	UserID               sql.NullInt64 `db:"user_id"`
+	OrganizationID       sql.NullInt64 `db:"organization_id"`
+	PlanID               int64         `db:"plan_id"`
+	StripeCustomerID     string        `db:"stripe_customer_id"`
+	StripeSubscriptionID string        `db:"stripe_subscription_id"`
+	Status               string        `db:"status"`
+	CurrentPeriodEnd     sql.NullTime  `db:"current_period_end"`
+	EventAt              time.Time     `db:"event_at"`
+}
@@ -0,0 +1,29 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ChannelCalendarEvent is a channel added to the calendar of a user, so that the event can be updated or cancelled later
+type ChannelCalendarEvent struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	ChannelID int64     `db:"channel_id"`
+	UserID    int64     `db:"user_id"`
+	// Provider is the OAuth provider whose calendar has the event
+	Provider  string         `db:"provider"`
+	EventID   string         `db:"event_id"`
+	Attendees pq.StringArray `db:"attendees"`
+}
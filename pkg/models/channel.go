@@ -10,7 +10,11 @@
 
 package models
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx/types"
+)
 
 // Channel Model contains all the details for a particular channel session
 type Channel struct {
@@ -24,4 +28,51 @@ type Channel struct {
 	RecordingUID     sql.NullInt32  `db:"recording_uid"`
 	RecordingSID     sql.NullString `db:"recording_sid"`
 	RecordingRID     sql.NullString `db:"recording_rid"`
+	RecordingPaused  bool           `db:"recording_paused"`
+	RecordingMode    string         `db:"recording_mode"`
+	RecordingStatus  sql.NullString `db:"recording_status"`
+	RetentionDays    sql.NullInt32  `db:"recording_retention_days"`
+	// TokenExpirySeconds overrides TOKEN_EXPIRY_SECONDS for the channel
+	TokenExpirySeconds sql.NullInt32 `db:"token_expiry_seconds"`
+	// AllowViewersToPublish gives viewers publisher tokens instead of subscriber tokens
+	AllowViewersToPublish bool         `db:"allow_viewers_to_publish"`
+	StartsAt              sql.NullTime `db:"starts_at"`
+	EndsAt                sql.NullTime `db:"ends_at"`
+	WaitingRoom           bool         `db:"waiting_room"`
+	EndedAt               sql.NullTime `db:"ended_at"`
+	// MaxParticipants limits how many users can be in the channel at once
+	MaxParticipants sql.NullInt32 `db:"max_participants"`
+	// Locked keeps users without a host passphrase from joining
+	Locked bool `db:"locked"`
+	// OwnerID is the signed in user that controls the channel
+	OwnerID sql.NullInt64 `db:"owner_id"`
+	// SIPURI is the URI SIP room systems join the channel with, using the DTMF as PIN
+	SIPURI sql.NullString `db:"sip_uri"`
+	// WhiteboardRoomUUID is the Interactive Whiteboard room shared by the participants of the channel
+	WhiteboardRoomUUID sql.NullString `db:"whiteboard_room_uuid"`
+	// LockedUntil keeps users without a host passphrase from joining after its passphrase was probably guessed
+	LockedUntil sql.NullTime `db:"locked_until"`
+	// OrganizationID is the organization whose owners and admins control the channel along with its owner
+	OrganizationID sql.NullInt64 `db:"organization_id"`
+	// RecordingStartedAt is when the running recording started, which is when its recording minutes are metered from
+	RecordingStartedAt sql.NullTime `db:"recording_started_at"`
+	// Metadata is a JSON object integrators attach to the channel, like the IDs of their own records
+	Metadata types.JSONText `db:"metadata"`
+	// RecordingQuality is the RecordingQualityInput, as JSON, recordings are started with when none is given
+	RecordingQuality types.NullJSONText `db:"recording_quality"`
+	// Area is the AgoraArea that overrides AGORA_AREA for the channel
+	Area sql.NullString `db:"area"`
+}
+
+// ChannelColumns lists the columns of the channels table that are mapped onto Channel, qualified with the table name
+// so that they can be selected along with joined tables. Columns added to Channel have to be added here as well
+const ChannelColumns = "channels.id, channels.title, channels.channel_name, channels.channel_secret, channels.host_passphrase, channels.viewer_passphrase, channels.dtmf, channels.recording_uid, channels.recording_sid, channels.recording_rid, channels.recording_paused, channels.recording_mode, channels.recording_status, channels.recording_retention_days, channels.token_expiry_seconds, channels.allow_viewers_to_publish, channels.starts_at, channels.ends_at, channels.waiting_room, channels.ended_at, channels.max_participants, channels.locked, channels.owner_id, channels.sip_uri, channels.whiteboard_room_uuid, channels.locked_until, channels.organization_id, channels.recording_started_at, channels.metadata, channels.recording_quality, channels.area"
+
+// ChannelPassphrase is a passphrase that gives access to a channel with a particular role
+type ChannelPassphrase struct {
+	ID         int64          `db:"id"`
+	ChannelID  int64          `db:"channel_id"`
+	Passphrase string         `db:"passphrase"`
+	Name       string         `db:"name"`
+	Role       PassphraseType `db:"role"`
 }
@@ -0,0 +1,27 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// ClientConfigVersion is a version of the configuration delivered to frontends. Every change creates a new version,
+// and the latest version is the one in use
+type ClientConfigVersion struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	Version   int            `db:"version"`
+	Config    types.JSONText `db:"config"`
+	CreatedBy sql.NullInt64  `db:"created_by"`
+}
@@ -11,20 +11,260 @@
 package models
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Namespaces for advisory locks so that locks on rows of different tables do not collide
+const (
+	LockRecording int32 = iota + 1
+	LockEventOutbox
+)
+
+// Drivers a database can be opened with
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	// DriverSQLite is only available in binaries built with the sqlite tag, since its driver requires cgo
+	DriverSQLite = "sqlite3"
 )
 
 // Database contains a pointer to the database object
 type Database struct {
 	*sqlx.DB
+	// locks stand in for advisory locks on SQLite, which is only ever used by a single process
+	locks sync.Map
 }
 
-// CreateDB is used to initialize a new database connection
-func CreateDB(dbURL string) (*Database, error) {
-	db, err := sqlx.Connect("postgres", dbURL)
+// DBConfig configures the connections to a database
+type DBConfig struct {
+	Driver string
+	URL    string
+	// MaxOpenConns limits the connections of the pool, which is unlimited when it is 0
+	MaxOpenConns int
+	// MaxIdleConns is how many connections are kept open while they are not used, the default of database/sql
+	// when it is 0
+	MaxIdleConns int
+	// ConnMaxLifetime is how long a connection is reused before it is replaced, forever when it is 0
+	ConnMaxLifetime time.Duration
+	// QueryTimeout cancels statements that run for longer, even when the context they run with has no deadline or a
+	// later one. Statements are not limited when it is 0
+	QueryTimeout time.Duration
+}
+
+// CreateDB is used to initialize a new database connection with the driver of config. Statements run with a traced
+// context are recorded as spans of the trace
+func CreateDB(config DBConfig) (*Database, error) {
+	var connector driver.Connector
+	var system string
+	var err error
+	switch config.Driver {
+	case DriverPostgres:
+		connector, err = pq.NewConnector(config.URL)
+		system = "postgresql"
+	case DriverMySQL:
+		connector, err = mysqlConnector(config.URL)
+		system = "mysql"
+	case DriverSQLite:
+		connector, err = sqliteConnector(config.URL)
+		system = "sqlite"
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", config.Driver)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	return &Database{db}, nil
+	db := sqlx.NewDb(sql.OpenDB(tracedConnector{connector, system, config.QueryTimeout}), config.Driver)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	if config.Driver == DriverSQLite {
+		// The in-memory database is dropped when its last connection is closed
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Database{DB: db}, nil
+}
+
+// mysqlConnector connects to a MySQL database with a DSN like user:password@tcp(localhost:3306)/agora. Times are
+// always parsed, since the models scan them into time.Time, and sessions use UTC like the driver unless the DSN sets
+// another time zone
+func mysqlConnector(dbURL string) (driver.Connector, error) {
+	config, err := mysql.ParseDSN(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ParseTime = true
+	if _, ok := config.Params["time_zone"]; !ok && config.Loc == time.UTC {
+		if config.Params == nil {
+			config.Params = map[string]string{}
+		}
+		config.Params["time_zone"] = "'+00:00'"
+	}
+
+	return mysql.NewConnector(config)
+}
+
+// sqliteConnector connects to a SQLite database, like file:dev.db?_fk=1&_busy_timeout=5000 or :memory:. An in-memory
+// database is shared by the connections of the pool and lives for as long as the process runs
+func sqliteConnector(dbURL string) (driver.Connector, error) {
+	registered := false
+	for _, driver := range sql.Drivers() {
+		registered = registered || driver == DriverSQLite
+	}
+
+	if !registered {
+		return nil, errors.New("SQLite support is not built in, build with -tags sqlite")
+	}
+
+	if dbURL == ":memory:" {
+		dbURL = "file::memory:?cache=shared&_fk=1"
+	}
+
+	// The driver has no connector of its own, so it is looked up through a handle that is never connected
+	handle, err := sql.Open(DriverSQLite, dbURL)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	return dsnConnector{dbURL, handle.Driver()}, nil
+}
+
+// Postgres reports whether the database is a Postgres database. Only the Postgres schema has the tables of every
+// feature, SQLite and MySQL only have those of channels, recordings and signing in
+func (db *Database) Postgres() bool {
+	return !db.SQLite() && !db.MySQL()
+}
+
+// SQLite reports whether the database is a SQLite database, whose statements have to be written in its dialect
+func (db *Database) SQLite() bool {
+	return db.DriverName() == DriverSQLite
+}
+
+// MySQL reports whether the database is a MySQL database, whose statements have to be written in its dialect
+func (db *Database) MySQL() bool {
+	return db.DriverName() == DriverMySQL
+}
+
+// WithAdvisoryLock runs fn in a transaction that holds a Postgres advisory lock on key within namespace.
+// Concurrent callers for the same key wait until the transaction of the current holder has finished. SQLite has no
+// advisory locks, so a lock of the process is held instead
+func (db *Database) WithAdvisoryLock(ctx context.Context, namespace int32, key int64, fn func(tx *sqlx.Tx) error) error {
+	if db.MySQL() {
+		return db.withNamedLock(ctx, namespace, key, fn)
+	}
+
+	if db.SQLite() {
+		lock, _ := db.locks.LoadOrStore([2]int64{int64(namespace), key}, &sync.Mutex{})
+		lock.(*sync.Mutex).Lock()
+		defer lock.(*sync.Mutex).Unlock()
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if !db.SQLite() {
+		_, err = tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2::int)", namespace, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withNamedLock runs fn in a transaction while holding a MySQL named lock on key within namespace. Named locks belong
+// to a session rather than a transaction, so the lock is taken and released on the connection of the transaction
+func (db *Database) withNamedLock(ctx context.Context, namespace int32, key int64, fn func(tx *sqlx.Tx) error) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	name := fmt.Sprintf("agora:%d:%d", namespace, key)
+	var locked sql.NullInt64
+	err = conn.GetContext(ctx, &locked, "SELECT GET_LOCK(?, -1)", name)
+	if err != nil {
+		return err
+	}
+
+	if locked.Int64 != 1 {
+		return fmt.Errorf("could not acquire lock %s", name)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sqliteViolation reports whether err was caused by a unique or a foreign key constraint of SQLite. Binaries built
+// with the sqlite tag replace it, since the error types of the driver are only available there
+var sqliteViolation = func(err error) (unique bool, foreignKey bool) {
+	return false, false
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" || errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return true
+	}
+
+	unique, _ := sqliteViolation(err)
+	return unique
+}
+
+// IsForeignKeyViolation reports whether err was caused by a foreign key constraint, like a reference to a row that
+// does not exist
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" || errors.As(err, &mysqlErr) && mysqlErr.Number == 1452 {
+		return true
+	}
+
+	_, foreignKey := sqliteViolation(err)
+	return foreignKey
 }
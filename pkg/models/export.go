@@ -0,0 +1,28 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserDataExport is an archive of everything stored about a user, built in the background after the user requests it.
+// The archive is dropped once it expires
+type UserDataExport struct {
+	ID          int64        `db:"id"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UserID      int64        `db:"user_id"`
+	Status      string       `db:"status"`
+	CompletedAt sql.NullTime `db:"completed_at"`
+	ExpiresAt   sql.NullTime `db:"expires_at"`
+	Archive     []byte       `db:"archive"`
+}
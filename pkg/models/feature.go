@@ -0,0 +1,27 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// FeatureFlagSetting turns a feature on or off globally, for the channels of an organization or for a channel. Flags of a
+// channel take precedence over flags of its organization, which take precedence over global flags
+type FeatureFlagSetting struct {
+	ID             int64         `db:"id"`
+	CreatedAt      time.Time     `db:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at"`
+	Feature        string        `db:"feature"`
+	OrganizationID sql.NullInt64 `db:"organization_id"`
+	ChannelID      sql.NullInt64 `db:"channel_id"`
+	Enabled        bool          `db:"enabled"`
+}
@@ -0,0 +1,38 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Statuses of an invitation set by the backend. Providers that report the delivery of messages replace them with
+// their own statuses, like delivered or undelivered
+const (
+	InvitationPending = "pending"
+	InvitationSent    = "sent"
+	InvitationFailed  = "failed"
+)
+
+// ChannelInvitation is an invitation to join a channel sent by email or text message
+type ChannelInvitation struct {
+	ID        int64         `db:"id"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+	ChannelID int64         `db:"channel_id"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	Medium    string        `db:"medium"`
+	Recipient string        `db:"recipient"`
+	Status    string        `db:"status"`
+	// MessageID is the ID the provider gave the message, for providers that report its delivery
+	MessageID sql.NullString `db:"message_id"`
+}
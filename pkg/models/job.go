@@ -0,0 +1,41 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BackgroundJob is a unit of work queued for the job workers. RunAt is when a pending job is due, or when the lease
+// of a running job runs out and it may be claimed again
+type BackgroundJob struct {
+	ID          int64          `db:"id"`
+	CreatedAt   time.Time      `db:"created_at"`
+	Kind        string         `db:"kind"`
+	UniqueKey   sql.NullString `db:"unique_key"`
+	Payload     string         `db:"payload"`
+	Status      string         `db:"status"`
+	Attempts    int            `db:"attempts"`
+	MaxAttempts int            `db:"max_attempts"`
+	RunAt       time.Time      `db:"run_at"`
+	StartedAt   sql.NullTime   `db:"started_at"`
+	FinishedAt  sql.NullTime   `db:"finished_at"`
+	LastError   sql.NullString `db:"last_error"`
+}
+
+// Status of a background job. Jobs that failed every attempt are kept as dead until they are retried
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+)
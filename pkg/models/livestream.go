@@ -0,0 +1,45 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Status of a live stream, an injected stream or a transcription
+const (
+	StreamRunning = "running"
+	StreamStopped = "stopped"
+)
+
+// ChannelLiveStream is a Media Push converter that streams a channel to an RTMP URL. The stream key is not stored
+type ChannelLiveStream struct {
+	ID          int64        `db:"id"`
+	CreatedAt   time.Time    `db:"created_at"`
+	ChannelID   int64        `db:"channel_id"`
+	ConverterID string       `db:"converter_id"`
+	RtmpURL     string       `db:"rtmp_url"`
+	Status      string       `db:"status"`
+	StoppedAt   sql.NullTime `db:"stopped_at"`
+}
+
+// ChannelInjectedStream is a Media Pull player that plays an online stream into a channel as a user
+type ChannelInjectedStream struct {
+	ID        int64        `db:"id"`
+	CreatedAt time.Time    `db:"created_at"`
+	ChannelID int64        `db:"channel_id"`
+	PlayerID  string       `db:"player_id"`
+	UID       int          `db:"uid"`
+	StreamURL string       `db:"stream_url"`
+	Status    string       `db:"status"`
+	StoppedAt sql.NullTime `db:"stopped_at"`
+}
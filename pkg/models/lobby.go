@@ -0,0 +1,27 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LobbyEntry is a viewer waiting in the waiting room of a channel to be admitted by a host
+type LobbyEntry struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	ChannelID int64          `db:"channel_id"`
+	LobbyID   string         `db:"lobby_id"`
+	Name      sql.NullString `db:"name"`
+	Status    LobbyStatus    `db:"status"`
+	Mode      JoinMode       `db:"mode"`
+}
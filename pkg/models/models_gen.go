@@ -2,9 +2,420 @@
 
 package models
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type AdminChannel struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ChannelName string  `json:"channelName"`
+	OwnerID     *string `json:"ownerId"`
+	// The user that created the channel, unless they were deleted since
+	Owner     *User      `json:"owner"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Recording bool       `json:"recording"`
+	Locked    bool       `json:"locked"`
+}
+
+// Credentials of an Agora project. Everything except the app ID is stored encrypted
+type AgoraProjectInput struct {
+	AppID               string `json:"appId"`
+	AppCertificate      string `json:"appCertificate"`
+	CustomerID          string `json:"customerId"`
+	CustomerCertificate string `json:"customerCertificate"`
+}
+
+type APIKey struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []APIKeyScope `json:"scopes"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	LastUsedAt *time.Time    `json:"lastUsedAt"`
+}
+
+type AttendanceRecord struct {
+	UID      int        `json:"uid"`
+	Name     *string    `json:"name"`
+	JoinedAt time.Time  `json:"joinedAt"`
+	LeftAt   *time.Time `json:"leftAt"`
+	Duration int        `json:"duration"`
+}
+
+type AuditEvent struct {
+	ID        string  `json:"id"`
+	Operation string  `json:"operation"`
+	UserID    *string `json:"userId"`
+	// The user that made the request, unless they were deleted since
+	User      *User   `json:"user"`
+	IP        *string `json:"ip"`
+	RequestID *string `json:"requestId"`
+	Channel   *string `json:"channel"`
+	// The channel the request was made on, unless it was deleted since
+	ChannelDetails *AdminChannel `json:"channelDetails"`
+	ArgumentsHash  string        `json:"argumentsHash"`
+	Succeeded      bool          `json:"succeeded"`
+	ErrorCode      *string       `json:"errorCode"`
+	CreatedAt      time.Time     `json:"createdAt"`
+}
+
+type AuthSession struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// Subscription of a user or an organization to a plan, as last reported by Stripe
+type BillingSubscription struct {
+	Plan *Plan `json:"plan"`
+	// Stripe status of the subscription. Plans apply while it is active, trialing or past_due
+	Status           string     `json:"status"`
+	CurrentPeriodEnd *time.Time `json:"currentPeriodEnd"`
+}
+
+// A scheduled channel in the calendar of its host
+type CalendarEvent struct {
+	ID string `json:"id"`
+	// The provider whose calendar has the event, google or microsoft
+	Provider  string    `json:"provider"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Attendees []string  `json:"attendees"`
+}
+
+// Server side quality of the last meeting held in a channel, from Agora Analytics
+type CallMetrics struct {
+	Channel string `json:"channel"`
+	Title   string `json:"title"`
+	// When the first participant joined and the last one left, from the attendance of the channel
+	StartedAt    time.Time `json:"startedAt"`
+	EndedAt      time.Time `json:"endedAt"`
+	Participants int       `json:"participants"`
+	// Calls Agora Analytics recorded for the channel in that time
+	Calls int `json:"calls"`
+	// Percentage of attempts to join that succeeded
+	JoinSuccessRate *float64           `json:"joinSuccessRate"`
+	AudioFreezeRate *float64           `json:"audioFreezeRate"`
+	VideoFreezeRate *float64           `json:"videoFreezeRate"`
+	Users           []*CallUserMetrics `json:"users"`
+}
+
+// Quality Agora measured for a user of a call, merged with what the user joined with
+type CallUserMetrics struct {
+	UID        int        `json:"uid"`
+	Name       *string    `json:"name"`
+	Platform   *string    `json:"platform"`
+	SdkVersion *string    `json:"sdkVersion"`
+	Network    *string    `json:"network"`
+	JoinedAt   *time.Time `json:"joinedAt"`
+	LeftAt     *time.Time `json:"leftAt"`
+	// Percentage of time audio received from the user was frozen
+	AudioFreezeRate *float64 `json:"audioFreezeRate"`
+	// Percentage of time video received from the user was frozen
+	VideoFreezeRate *float64 `json:"videoFreezeRate"`
+}
+
+type ChannelParticipant struct {
+	UID           int     `json:"uid"`
+	Name          *string `json:"name"`
+	IsScreenShare bool    `json:"isScreenShare"`
+	IsBroadcaster bool    `json:"isBroadcaster"`
+}
+
+type ChannelParticipants struct {
+	Total        int                   `json:"total"`
+	Participants []*ChannelParticipant `json:"participants"`
+}
+
+type ChannelStorageInput struct {
+	Provider  StorageProvider `json:"provider"`
+	Region    *int            `json:"region"`
+	Bucket    string          `json:"bucket"`
+	AccessKey string          `json:"accessKey"`
+	SecretKey string          `json:"secretKey"`
+}
+
+type ChatMessage struct {
+	ID     string    `json:"id"`
+	UID    int       `json:"uid"`
+	Name   *string   `json:"name"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sentAt"`
+}
+
+type ChatMessagePage struct {
+	Messages []*ChatMessage `json:"messages"`
+	HasMore  bool           `json:"hasMore"`
+}
+
+// Configuration of frontends that operators change without redeploying them
+type ClientConfig struct {
+	// Increases with every change, 0 until the configuration is first set
+	Version   int        `json:"version"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+	// Colors, logos and other branding of the frontend
+	Branding map[string]interface{} `json:"branding"`
+	// Agora video encoder profile, like 720p_1
+	VideoProfile *string `json:"videoProfile"`
+	// TURN servers to relay media through on restrictive networks
+	TurnServers []*TurnServer `json:"turnServers"`
+	// Whether to connect through the Agora cloud proxy
+	CloudProxy bool `json:"cloudProxy"`
+	// Features that are turned on, for the channel of the passphrase when one is given
+	Features []Feature `json:"features"`
+	// Any other settings of the frontend
+	Settings map[string]interface{} `json:"settings"`
+}
+
+type ClientConfigHistoryEntry struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy *string   `json:"createdBy"`
+}
+
+type ClientConfigInput struct {
+	Branding     map[string]interface{} `json:"branding"`
+	VideoProfile *string                `json:"videoProfile"`
+	TurnServers  []*TurnServerInput     `json:"turnServers"`
+	CloudProxy   *bool                  `json:"cloudProxy"`
+	Settings     map[string]interface{} `json:"settings"`
+}
+
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
+
+type CreatedWebhook struct {
+	Webhook *Webhook `json:"webhook"`
+	// Secret requests to the webhook are signed with. It is only returned when the webhook is created
+	Secret string `json:"secret"`
+}
+
+type DailyAnalytics struct {
+	// Day in UTC, formatted as YYYY-MM-DD
+	Date string `json:"date"`
+	// Meetings that started on the day. A meeting lasts from the first participant joining a channel to the last one leaving
+	Meetings               int      `json:"meetings"`
+	AverageDurationMinutes *float64 `json:"averageDurationMinutes"`
+	// The most channels that had a meeting at the same time
+	PeakConcurrentChannels int `json:"peakConcurrentChannels"`
+	// Minutes of recordings that stopped on the day
+	RecordingMinutes float64 `json:"recordingMinutes"`
+	// Calls placed to phone numbers with dialOut
+	PstnCalls int `json:"pstnCalls"`
+	// Minutes participants spent in channels dialed in by phone
+	PstnMinutes float64 `json:"pstnMinutes"`
+}
+
+type DailyRating struct {
+	// Day the ratings were submitted on in UTC, formatted as YYYY-MM-DD
+	Date          string  `json:"date"`
+	Count         int     `json:"count"`
+	AverageRating float64 `json:"averageRating"`
+}
+
+// An archive of everything stored about the user. The archive is downloaded from downloadUrl with the access token of
+// the user once it is READY
+type DataExport struct {
+	ID          string           `json:"id"`
+	Status      DataExportStatus `json:"status"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	CompletedAt *time.Time       `json:"completedAt"`
+	ExpiresAt   *time.Time       `json:"expiresAt"`
+	DownloadURL *string          `json:"downloadUrl"`
+}
+
+type DialInNumber struct {
+	Country string  `json:"country"`
+	Region  *string `json:"region"`
+	Number  string  `json:"number"`
+}
+
+type DialOutCall struct {
+	CallID      string    `json:"callId"`
+	PhoneNumber string    `json:"phoneNumber"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Turns a feature on or off globally, for the channels of an organization or for a channel. Flags of a channel take
+// precedence over flags of its organization, which take precedence over global flags
+type FeatureFlag struct {
+	Feature        Feature   `json:"feature"`
+	Enabled        bool      `json:"enabled"`
+	OrganizationID *string   `json:"organizationId"`
+	Channel        *string   `json:"channel"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type FeedbackComment struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+	// The name the participant joined with, when they gave their uid
+	Name      *string   `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type FeedbackSummary struct {
+	Count         int      `json:"count"`
+	AverageRating *float64 `json:"averageRating"`
+	// Number of ratings of 1 to 5, in that order
+	Distribution []int          `json:"distribution"`
+	Daily        []*DailyRating `json:"daily"`
+	// The most recent comments, up to 100
+	Comments []*FeedbackComment `json:"comments"`
+}
+
+type InjectedStream struct {
+	ID        string     `json:"id"`
+	UID       int        `json:"uid"`
+	URL       string     `json:"url"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	StoppedAt *time.Time `json:"stoppedAt"`
+}
+
+// An invitation to a channel. Its status is pending, sent or failed, or the delivery status reported by the SMS
+// provider, like delivered or undelivered
+type Invitation struct {
+	ID        string    `json:"id"`
+	Medium    string    `json:"medium"`
+	Recipient string    `json:"recipient"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Whether an invitation reached a recipient
+type InviteResult struct {
+	ID        string `json:"id"`
+	Recipient string `json:"recipient"`
+	Sent      bool   `json:"sent"`
+	Status    string `json:"status"`
+}
+
+// Work run in the background by the servers, like the cleanup of expired recordings
+type Job struct {
+	ID     string    `json:"id"`
+	Kind   string    `json:"kind"`
+	Status JobStatus `json:"status"`
+	// JSON payload the job runs with
+	Payload     string `json:"payload"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"maxAttempts"`
+	// When a pending job is due, or when a running job may be claimed again by another server
+	RunAt      time.Time  `json:"runAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+	LastError  *string    `json:"lastError"`
+}
+
+type LiveStream struct {
+	ID        string     `json:"id"`
+	RtmpURL   string     `json:"rtmpUrl"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	StoppedAt *time.Time `json:"stoppedAt"`
+}
+
+type LobbyUpdate struct {
+	ID          string      `json:"id"`
+	Name        *string     `json:"name"`
+	Status      LobbyStatus `json:"status"`
+	RequestedAt time.Time   `json:"requestedAt"`
+}
+
+// A URL client logs are uploaded to with a PUT request
+type LogUpload struct {
+	URL string `json:"url"`
+	// Headers to send with the upload
+	Headers   map[string]interface{} `json:"headers"`
+	ExpiresAt time.Time              `json:"expiresAt"`
+}
+
+type LoginSession struct {
+	ID         string     `json:"id"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	UserAgent  *string    `json:"userAgent"`
+	IP         *string    `json:"ip"`
+	Location   *string    `json:"location"`
+	Current    bool       `json:"current"`
+}
+
+type MeetingAnalytics struct {
+	Since                  time.Time         `json:"since"`
+	Until                  time.Time         `json:"until"`
+	Meetings               int               `json:"meetings"`
+	AverageDurationMinutes *float64          `json:"averageDurationMinutes"`
+	PeakConcurrentChannels int               `json:"peakConcurrentChannels"`
+	RecordingMinutes       float64           `json:"recordingMinutes"`
+	PstnCalls              int               `json:"pstnCalls"`
+	PstnMinutes            float64           `json:"pstnMinutes"`
+	Daily                  []*DailyAnalytics `json:"daily"`
+}
+
+// A team that shares the channels created in it. Owners and admins of an organization control its channels like the
+// user that created them
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Role of the signed in user in the organization
+	Role OrganizationRole `json:"role"`
+	// App ID of the Agora project the channels of the organization use, when the organization has its own project
+	AppID *string `json:"appId"`
+	// Whether recordings of the channels of the organization are uploaded to a bucket of the organization
+	CustomStorage bool `json:"customStorage"`
+}
+
+type OrganizationChannel struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"createdAt"`
+	OwnerID   *string   `json:"ownerId"`
+	// The user that created the channel, unless they were deleted since
+	Owner *User `json:"owner"`
+	// Only returned to owners and admins of the organization
+	HostPassphrase   *string    `json:"hostPassphrase"`
+	ViewerPassphrase string     `json:"viewerPassphrase"`
+	EndedAt          *time.Time `json:"endedAt"`
+}
+
+type OrganizationMember struct {
+	UserID   string           `json:"userId"`
+	Name     *string          `json:"name"`
+	Email    *string          `json:"email"`
+	Role     OrganizationRole `json:"role"`
+	JoinedAt time.Time        `json:"joinedAt"`
+}
+
 type Pstn struct {
-	Number string `json:"number"`
-	Dtmf   string `json:"dtmf"`
+	Number  string          `json:"number"`
+	Dtmf    string          `json:"dtmf"`
+	Numbers []*DialInNumber `json:"numbers"`
+}
+
+// The call quality a participant of a channel reported, summarised over the call
+type ParticipantQuality struct {
+	UID               int      `json:"uid"`
+	Name              *string  `json:"name"`
+	AverageRtt        *float64 `json:"averageRtt"`
+	MaxRtt            *int     `json:"maxRtt"`
+	AveragePacketLoss *float64 `json:"averagePacketLoss"`
+	MaxPacketLoss     *float64 `json:"maxPacketLoss"`
+	// The lowest video height sent, which drops when the SDK adapts to a poor network
+	MinHeight *int             `json:"minHeight"`
+	Samples   []*QualitySample `json:"samples"`
 }
 
 type Passphrase struct {
@@ -12,13 +423,206 @@ type Passphrase struct {
 	View string  `json:"view"`
 }
 
+type PassphraseAttempt struct {
+	IP          string    `json:"ip"`
+	Failures    int       `json:"failures"`
+	Locked      bool      `json:"locked"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+// An operation document that can be executed while OPERATION_ALLOWLIST is enabled
+type PersistedOperation struct {
+	ID string `json:"id"`
+	// SHA-256 of the document in hex. Clients can send it in the persistedQuery extension instead of the document
+	Hash string `json:"hash"`
+	// Name of the first operation of the document
+	Name      *string   `json:"name"`
+	Document  string    `json:"document"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy *string   `json:"createdBy"`
+}
+
+// A plan that can be subscribed to with Stripe
+type Plan struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Quota *UsageQuota `json:"quota"`
+}
+
+// A plan for a Stripe price. Limits that are not set are unlimited
+type PlanInput struct {
+	Name                       string `json:"name"`
+	StripePriceID              string `json:"stripePriceId"`
+	ChannelsPerMonth           *int   `json:"channelsPerMonth"`
+	RecordingMinutesPerMonth   *int   `json:"recordingMinutesPerMonth"`
+	ParticipantMinutesPerMonth *int   `json:"participantMinutesPerMonth"`
+	PstnCallsPerMonth          *int   `json:"pstnCallsPerMonth"`
+}
+
+type Poll struct {
+	ID         string        `json:"id"`
+	Question   string        `json:"question"`
+	Options    []*PollOption `json:"options"`
+	TotalVotes int           `json:"totalVotes"`
+	Closed     bool          `json:"closed"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type PollOption struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// Credentials for a throwaway channel that client apps can use to run network and device checks before joining
+type PrecallTest struct {
+	AppID string `json:"appId"`
+	// A channel created for this test only, that no meeting uses
+	Channel string `json:"channel"`
+	// Credentials to publish and subscribe in the channel, to loop media back for the quality test
+	User *UserCredentials `json:"user"`
+	// The number of seconds the tokens are valid for
+	ExpiresIn int `json:"expiresIn"`
+}
+
+type ProblemReport struct {
+	TicketID string `json:"ticketId"`
+	// Where to upload the logs of the client, when they were requested
+	LogUpload *LogUpload `json:"logUpload"`
+}
+
+type QRCode struct {
+	// The link or URI the QR code encodes
+	Content string       `json:"content"`
+	Format  QRCodeFormat `json:"format"`
+	// The image as a data URL, that can be used as the source of an img element
+	DataURL string `json:"dataUrl"`
+}
+
+type QualitySample struct {
+	MeasuredAt         time.Time `json:"measuredAt"`
+	Rtt                *int      `json:"rtt"`
+	UplinkPacketLoss   *float64  `json:"uplinkPacketLoss"`
+	DownlinkPacketLoss *float64  `json:"downlinkPacketLoss"`
+	Width              *int      `json:"width"`
+	Height             *int      `json:"height"`
+	FrameRate          *int      `json:"frameRate"`
+}
+
+// Call quality measured by a client, from the statistics of the Agora SDK
+type QualityStatsInput struct {
+	// When the sample was measured, the time it is reported at when not set
+	MeasuredAt *time.Time `json:"measuredAt"`
+	// Round trip time to the Agora edge server in milliseconds
+	Rtt *int `json:"rtt"`
+	// Percentage of packets sent that were lost
+	UplinkPacketLoss *float64 `json:"uplinkPacketLoss"`
+	// Percentage of packets received that were lost
+	DownlinkPacketLoss *float64 `json:"downlinkPacketLoss"`
+	// Resolution and frame rate of the video sent
+	Width     *int `json:"width"`
+	Height    *int `json:"height"`
+	FrameRate *int `json:"frameRate"`
+}
+
+type Question struct {
+	ID      string         `json:"id"`
+	Text    string         `json:"text"`
+	UID     *int           `json:"uid"`
+	Name    *string        `json:"name"`
+	Status  QuestionStatus `json:"status"`
+	Upvotes int            `json:"upvotes"`
+	AskedAt time.Time      `json:"askedAt"`
+}
+
+type RaisedHand struct {
+	UID      int       `json:"uid"`
+	Name     *string   `json:"name"`
+	RaisedAt time.Time `json:"raisedAt"`
+}
+
+type Recording struct {
+	FileName   string    `json:"fileName"`
+	TrackType  *string   `json:"trackType"`
+	IsPlayable bool      `json:"isPlayable"`
+	CreatedAt  time.Time `json:"createdAt"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type RecordingFile struct {
+	Filename       string `json:"filename"`
+	TrackType      string `json:"trackType"`
+	UID            string `json:"uid"`
+	MixedAllUser   bool   `json:"mixedAllUser"`
+	IsPlayable     bool   `json:"isPlayable"`
+	SliceStartTime int    `json:"sliceStartTime"`
+}
+
+type RecordingLayoutInput struct {
+	Type            RecordingLayout `json:"type"`
+	SpotlightUID    *int            `json:"spotlightUid"`
+	BackgroundColor *string         `json:"backgroundColor"`
+}
+
+type RecordingQualityInput struct {
+	Height          *int             `json:"height"`
+	Width           *int             `json:"width"`
+	Bitrate         *int             `json:"bitrate"`
+	Fps             *int             `json:"fps"`
+	Layout          *RecordingLayout `json:"layout"`
+	BackgroundColor *string          `json:"backgroundColor"`
+}
+
+type RecordingStatus struct {
+	ServerState  string           `json:"serverState"`
+	Status       int              `json:"status"`
+	UploadStatus *string          `json:"uploadStatus"`
+	FileListMode string           `json:"fileListMode"`
+	Files        []*RecordingFile `json:"files"`
+}
+
+type RecordingTranscript struct {
+	Status    string               `json:"status"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Error     *string              `json:"error"`
+	Segments  []*TranscriptSegment `json:"segments"`
+}
+
+// A change of the recording of a channel
+type RecordingUpdate struct {
+	// started when a recording starts, stopped when a host stops it and exited when cloud recording ended it
+	Status string `json:"status"`
+	// The SID of the recording that changed
+	Sid       string    `json:"sid"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Sip struct {
+	URI string `json:"uri"`
+	Pin string `json:"pin"`
+}
+
 type Session struct {
 	Channel     string           `json:"channel"`
 	Title       string           `json:"title"`
 	IsHost      bool             `json:"isHost"`
+	Role        PassphraseType   `json:"role"`
+	CanPublish  bool             `json:"canPublish"`
+	Status      SessionStatus    `json:"status"`
+	LobbyID     *string          `json:"lobbyId"`
 	Secret      string           `json:"secret"`
 	MainUser    *UserCredentials `json:"mainUser"`
 	ScreenShare *UserCredentials `json:"screenShare"`
+	Sip         *Sip             `json:"sip"`
+	Whiteboard  *Whiteboard      `json:"whiteboard"`
+	Mode        JoinMode         `json:"mode"`
+	// App ID of the Agora project the credentials of the session are for. Only set along with credentials
+	AppID *string `json:"appId"`
+	// Data integrators attached to the channel with setChannelMetadata
+	Metadata map[string]interface{} `json:"metadata"`
+	// Features that are turned on for the channel, so that clients can hide the others
+	Features []Feature `json:"features"`
 }
 
 type ShareResponse struct {
@@ -26,6 +630,67 @@ type ShareResponse struct {
 	Channel    string      `json:"channel"`
 	Title      string      `json:"title"`
 	Pstn       *Pstn       `json:"pstn"`
+	Sip        *Sip        `json:"sip"`
+	// Short links to the join links, or null when FRONTEND_URL is not set
+	ShortLinks *ShortLinks `json:"shortLinks"`
+	// Data integrators attached to the channel with setChannelMetadata
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// Short links that redirect to the join links of a channel. They stop working when the meeting ends or
+// SHORT_LINK_GRACE_HOURS after its scheduled end, and when passphrases are rotated
+type ShortLinks struct {
+	Host *string `json:"host"`
+	View string  `json:"view"`
+}
+
+type SupportTicket struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// The channel the problem was reported in, unless it was deleted since
+	Channel *string `json:"channel"`
+	// The channel the problem was reported in, unless it was deleted since
+	ChannelDetails *AdminChannel `json:"channelDetails"`
+	UID            *int          `json:"uid"`
+	UserID         *string       `json:"userId"`
+	// The user that reported the problem, unless they were deleted since
+	User        *User  `json:"user"`
+	Description string `json:"description"`
+	// A time limited download URL of the logs requested with the report. It fails if the client never uploaded them
+	LogURL *string `json:"logUrl"`
+}
+
+type TranscriptFile struct {
+	FileName  string    `json:"fileName"`
+	Language  string    `json:"language"`
+	CreatedAt time.Time `json:"createdAt"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type TranscriptSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+type TurnServer struct {
+	Urls       []string `json:"urls"`
+	Username   *string  `json:"username"`
+	Credential *string  `json:"credential"`
+}
+
+type TurnServerInput struct {
+	Urls       []string `json:"urls"`
+	Username   *string  `json:"username"`
+	Credential *string  `json:"credential"`
+}
+
+// A TOTP secret that has to be confirmed with a code from the authenticator app before it protects the account
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	// otpauth URL to show as a QR code
+	URL string `json:"url"`
 }
 
 type UIDMuteState struct {
@@ -33,13 +698,888 @@ type UIDMuteState struct {
 	Mute bool `json:"mute"`
 }
 
+// Settings of a channel to change. Settings that are left out are kept
+type UpdateChannelInput struct {
+	Title *string `json:"title"`
+	// Enabling dial in creates a bridge for the channel. Disabling it removes its DTMF, so that callers cannot join
+	EnablePstn *bool `json:"enablePSTN"`
+	// The most participants that can join, or 0 to remove the limit
+	MaxParticipants   *int  `json:"maxParticipants"`
+	EnableWaitingRoom *bool `json:"enableWaitingRoom"`
+	// The quality recordings are started with when startRecordingSession is not given one
+	RecordingQuality *RecordingQualityInput `json:"recordingQuality"`
+	// The area the channel is restricted to. It cannot change while the channel is being recorded
+	Area *AgoraArea `json:"area"`
+}
+
+// Usage metered in a month. Usage of channels in an organization counts towards the organization, and usage of other
+// channels counts towards their owner
+type Usage struct {
+	// Month the usage was metered in, formatted as YYYY-MM
+	Period             string  `json:"period"`
+	Channels           int     `json:"channels"`
+	RecordingMinutes   float64 `json:"recordingMinutes"`
+	ParticipantMinutes float64 `json:"participantMinutes"`
+	// Calls placed to phone numbers with dialOut
+	PstnCalls int `json:"pstnCalls"`
+	// Plan the quota is set by, when subscribed to one
+	Plan  *Plan       `json:"plan"`
+	Quota *UsageQuota `json:"quota"`
+}
+
+// Monthly limits of usage. Limits that are not set are unlimited
+type UsageQuota struct {
+	Channels           *int `json:"channels"`
+	RecordingMinutes   *int `json:"recordingMinutes"`
+	ParticipantMinutes *int `json:"participantMinutes"`
+	PstnCalls          *int `json:"pstnCalls"`
+}
+
+type UsageStats struct {
+	Users                   int `json:"users"`
+	Channels                int `json:"channels"`
+	ChannelsLast24Hours     int `json:"channelsLast24Hours"`
+	ParticipantsLast24Hours int `json:"participantsLast24Hours"`
+	Recordings              int `json:"recordings"`
+	ActiveRecordings        int `json:"activeRecordings"`
+}
+
 type User struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// The OAuth provider the user signed in with
+	Provider *string `json:"provider"`
 }
 
 type UserCredentials struct {
 	Rtc string  `json:"rtc"`
 	Rtm *string `json:"rtm"`
 	UID int     `json:"uid"`
+	// The area the SDK has to be restricted to with setArea, so that media stays within it
+	Area AgoraArea `json:"area"`
+}
+
+// A URL events of channels are POSTed to as JSON, signed in the Webhook-Signature header. Webhooks of an organization
+// receive the events of its channels, and webhooks of an API key receive the events of channels its owner owns outside
+// of organizations
+type Webhook struct {
+	ID             string         `json:"id"`
+	URL            string         `json:"url"`
+	Events         []WebhookEvent `json:"events"`
+	OrganizationID *string        `json:"organizationId"`
+	APIKeyID       *string        `json:"apiKeyId"`
+	CreatedAt      time.Time      `json:"createdAt"`
+}
+
+// An event queued for delivery to a webhook. Failed deliveries are retried with exponential backoff
+type WebhookDelivery struct {
+	ID            string       `json:"id"`
+	EventID       string       `json:"eventId"`
+	Event         WebhookEvent `json:"event"`
+	Payload       string       `json:"payload"`
+	Attempts      int          `json:"attempts"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	NextAttemptAt *time.Time   `json:"nextAttemptAt"`
+	DeliveredAt   *time.Time   `json:"deliveredAt"`
+	FailedAt      *time.Time   `json:"failedAt"`
+	// Status the webhook responded to the last attempt with
+	LastStatus *int    `json:"lastStatus"`
+	LastError  *string `json:"lastError"`
+}
+
+type Whiteboard struct {
+	AppIdentifier string `json:"appIdentifier"`
+	Region        string `json:"region"`
+	RoomUUID      string `json:"roomUuid"`
+	RoomToken     string `json:"roomToken"`
+	Writable      bool   `json:"writable"`
+}
+
+// Geographic areas Agora can be restricted to, so that media, tokens and recordings stay within them
+type AgoraArea string
+
+const (
+	AgoraAreaGlobal       AgoraArea = "GLOBAL"
+	AgoraAreaNorthAmerica AgoraArea = "NORTH_AMERICA"
+	AgoraAreaEurope       AgoraArea = "EUROPE"
+	AgoraAreaAsia         AgoraArea = "ASIA"
+	AgoraAreaJapan        AgoraArea = "JAPAN"
+	AgoraAreaIndia        AgoraArea = "INDIA"
+	AgoraAreaChina        AgoraArea = "CHINA"
+)
+
+var AllAgoraArea = []AgoraArea{
+	AgoraAreaGlobal,
+	AgoraAreaNorthAmerica,
+	AgoraAreaEurope,
+	AgoraAreaAsia,
+	AgoraAreaJapan,
+	AgoraAreaIndia,
+	AgoraAreaChina,
+}
+
+func (e AgoraArea) IsValid() bool {
+	switch e {
+	case AgoraAreaGlobal, AgoraAreaNorthAmerica, AgoraAreaEurope, AgoraAreaAsia, AgoraAreaJapan, AgoraAreaIndia, AgoraAreaChina:
+		return true
+	}
+	return false
+}
+
+func (e AgoraArea) String() string {
+	return string(e)
+}
+
+func (e *AgoraArea) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AgoraArea(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AgoraArea", str)
+	}
+	return nil
+}
+
+func (e AgoraArea) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type APIKeyScope string
+
+const (
+	APIKeyScopeCreateChannel  APIKeyScope = "CREATE_CHANNEL"
+	APIKeyScopeStartRecording APIKeyScope = "START_RECORDING"
+)
+
+var AllAPIKeyScope = []APIKeyScope{
+	APIKeyScopeCreateChannel,
+	APIKeyScopeStartRecording,
+}
+
+func (e APIKeyScope) IsValid() bool {
+	switch e {
+	case APIKeyScopeCreateChannel, APIKeyScopeStartRecording:
+		return true
+	}
+	return false
+}
+
+func (e APIKeyScope) String() string {
+	return string(e)
+}
+
+func (e *APIKeyScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = APIKeyScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ApiKeyScope", str)
+	}
+	return nil
+}
+
+func (e APIKeyScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "PENDING"
+	DataExportStatusReady   DataExportStatus = "READY"
+	DataExportStatusFailed  DataExportStatus = "FAILED"
+)
+
+var AllDataExportStatus = []DataExportStatus{
+	DataExportStatusPending,
+	DataExportStatusReady,
+	DataExportStatusFailed,
+}
+
+func (e DataExportStatus) IsValid() bool {
+	switch e {
+	case DataExportStatusPending, DataExportStatusReady, DataExportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e DataExportStatus) String() string {
+	return string(e)
+}
+
+func (e *DataExportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DataExportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DataExportStatus", str)
+	}
+	return nil
+}
+
+func (e DataExportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Features that can be turned off with feature flags. Features are on unless a flag turns them off
+type Feature string
+
+const (
+	FeatureWaitingRoom   Feature = "WAITING_ROOM"
+	FeatureTranscription Feature = "TRANSCRIPTION"
+	FeaturePstn          Feature = "PSTN"
+	FeatureLiveStreaming Feature = "LIVE_STREAMING"
+)
+
+var AllFeature = []Feature{
+	FeatureWaitingRoom,
+	FeatureTranscription,
+	FeaturePstn,
+	FeatureLiveStreaming,
+}
+
+func (e Feature) IsValid() bool {
+	switch e {
+	case FeatureWaitingRoom, FeatureTranscription, FeaturePstn, FeatureLiveStreaming:
+		return true
+	}
+	return false
+}
+
+func (e Feature) String() string {
+	return string(e)
+}
+
+func (e *Feature) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Feature(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Feature", str)
+	}
+	return nil
+}
+
+func (e Feature) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "PENDING"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	// Failed every attempt and is kept until it is retried
+	JobStatusDead JobStatus = "DEAD"
+)
+
+var AllJobStatus = []JobStatus{
+	JobStatusPending,
+	JobStatusRunning,
+	JobStatusSucceeded,
+	JobStatusDead,
+}
+
+func (e JobStatus) IsValid() bool {
+	switch e {
+	case JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e JobStatus) String() string {
+	return string(e)
+}
+
+func (e *JobStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JobStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JobStatus", str)
+	}
+	return nil
+}
+
+func (e JobStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type JoinMode string
+
+const (
+	JoinModeFull            JoinMode = "FULL"
+	JoinModeAudioOnly       JoinMode = "AUDIO_ONLY"
+	JoinModeScreenshareOnly JoinMode = "SCREENSHARE_ONLY"
+)
+
+var AllJoinMode = []JoinMode{
+	JoinModeFull,
+	JoinModeAudioOnly,
+	JoinModeScreenshareOnly,
+}
+
+func (e JoinMode) IsValid() bool {
+	switch e {
+	case JoinModeFull, JoinModeAudioOnly, JoinModeScreenshareOnly:
+		return true
+	}
+	return false
+}
+
+func (e JoinMode) String() string {
+	return string(e)
+}
+
+func (e *JoinMode) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JoinMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JoinMode", str)
+	}
+	return nil
+}
+
+func (e JoinMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type LobbyStatus string
+
+const (
+	LobbyStatusPending  LobbyStatus = "PENDING"
+	LobbyStatusAdmitted LobbyStatus = "ADMITTED"
+	LobbyStatusDenied   LobbyStatus = "DENIED"
+)
+
+var AllLobbyStatus = []LobbyStatus{
+	LobbyStatusPending,
+	LobbyStatusAdmitted,
+	LobbyStatusDenied,
+}
+
+func (e LobbyStatus) IsValid() bool {
+	switch e {
+	case LobbyStatusPending, LobbyStatusAdmitted, LobbyStatusDenied:
+		return true
+	}
+	return false
+}
+
+func (e LobbyStatus) String() string {
+	return string(e)
+}
+
+func (e *LobbyStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LobbyStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LobbyStatus", str)
+	}
+	return nil
+}
+
+func (e LobbyStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "OWNER"
+	OrganizationRoleAdmin  OrganizationRole = "ADMIN"
+	OrganizationRoleMember OrganizationRole = "MEMBER"
+)
+
+var AllOrganizationRole = []OrganizationRole{
+	OrganizationRoleOwner,
+	OrganizationRoleAdmin,
+	OrganizationRoleMember,
+}
+
+func (e OrganizationRole) IsValid() bool {
+	switch e {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+func (e OrganizationRole) String() string {
+	return string(e)
+}
+
+func (e *OrganizationRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrganizationRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrganizationRole", str)
+	}
+	return nil
+}
+
+func (e OrganizationRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PassphraseType string
+
+const (
+	PassphraseTypeHost   PassphraseType = "HOST"
+	PassphraseTypeCohost PassphraseType = "COHOST"
+	PassphraseTypeViewer PassphraseType = "VIEWER"
+)
+
+var AllPassphraseType = []PassphraseType{
+	PassphraseTypeHost,
+	PassphraseTypeCohost,
+	PassphraseTypeViewer,
+}
+
+func (e PassphraseType) IsValid() bool {
+	switch e {
+	case PassphraseTypeHost, PassphraseTypeCohost, PassphraseTypeViewer:
+		return true
+	}
+	return false
+}
+
+func (e PassphraseType) String() string {
+	return string(e)
+}
+
+func (e *PassphraseType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PassphraseType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PassphraseType", str)
+	}
+	return nil
+}
+
+func (e PassphraseType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type QRCodeFormat string
+
+const (
+	QRCodeFormatPng QRCodeFormat = "PNG"
+	QRCodeFormatSvg QRCodeFormat = "SVG"
+)
+
+var AllQRCodeFormat = []QRCodeFormat{
+	QRCodeFormatPng,
+	QRCodeFormatSvg,
+}
+
+func (e QRCodeFormat) IsValid() bool {
+	switch e {
+	case QRCodeFormatPng, QRCodeFormatSvg:
+		return true
+	}
+	return false
+}
+
+func (e QRCodeFormat) String() string {
+	return string(e)
+}
+
+func (e *QRCodeFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QRCodeFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QRCodeFormat", str)
+	}
+	return nil
+}
+
+func (e QRCodeFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// What a QR code to a channel encodes
+type QRCodeType string
+
+const (
+	// The join link of the channel, using its short link when there is one
+	QRCodeTypeJoin QRCodeType = "JOIN"
+	// A tel: URI that dials in to the channel and enters its PIN
+	QRCodeTypePstn QRCodeType = "PSTN"
+)
+
+var AllQRCodeType = []QRCodeType{
+	QRCodeTypeJoin,
+	QRCodeTypePstn,
+}
+
+func (e QRCodeType) IsValid() bool {
+	switch e {
+	case QRCodeTypeJoin, QRCodeTypePstn:
+		return true
+	}
+	return false
+}
+
+func (e QRCodeType) String() string {
+	return string(e)
+}
+
+func (e *QRCodeType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QRCodeType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QRCodeType", str)
+	}
+	return nil
+}
+
+func (e QRCodeType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type QuestionSort string
+
+const (
+	QuestionSortRecent  QuestionSort = "RECENT"
+	QuestionSortUpvotes QuestionSort = "UPVOTES"
+)
+
+var AllQuestionSort = []QuestionSort{
+	QuestionSortRecent,
+	QuestionSortUpvotes,
+}
+
+func (e QuestionSort) IsValid() bool {
+	switch e {
+	case QuestionSortRecent, QuestionSortUpvotes:
+		return true
+	}
+	return false
+}
+
+func (e QuestionSort) String() string {
+	return string(e)
+}
+
+func (e *QuestionSort) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QuestionSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QuestionSort", str)
+	}
+	return nil
+}
+
+func (e QuestionSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type QuestionStatus string
+
+const (
+	QuestionStatusOpen      QuestionStatus = "OPEN"
+	QuestionStatusAnswered  QuestionStatus = "ANSWERED"
+	QuestionStatusDismissed QuestionStatus = "DISMISSED"
+)
+
+var AllQuestionStatus = []QuestionStatus{
+	QuestionStatusOpen,
+	QuestionStatusAnswered,
+	QuestionStatusDismissed,
+}
+
+func (e QuestionStatus) IsValid() bool {
+	switch e {
+	case QuestionStatusOpen, QuestionStatusAnswered, QuestionStatusDismissed:
+		return true
+	}
+	return false
+}
+
+func (e QuestionStatus) String() string {
+	return string(e)
+}
+
+func (e *QuestionStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QuestionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QuestionStatus", str)
+	}
+	return nil
+}
+
+func (e QuestionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RecordingLayout string
+
+const (
+	RecordingLayoutFloating  RecordingLayout = "FLOATING"
+	RecordingLayoutGrid      RecordingLayout = "GRID"
+	RecordingLayoutSpotlight RecordingLayout = "SPOTLIGHT"
+)
+
+var AllRecordingLayout = []RecordingLayout{
+	RecordingLayoutFloating,
+	RecordingLayoutGrid,
+	RecordingLayoutSpotlight,
+}
+
+func (e RecordingLayout) IsValid() bool {
+	switch e {
+	case RecordingLayoutFloating, RecordingLayoutGrid, RecordingLayoutSpotlight:
+		return true
+	}
+	return false
+}
+
+func (e RecordingLayout) String() string {
+	return string(e)
+}
+
+func (e *RecordingLayout) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RecordingLayout(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RecordingLayout", str)
+	}
+	return nil
+}
+
+func (e RecordingLayout) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleAdmin Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SessionStatus string
+
+const (
+	SessionStatusActive  SessionStatus = "ACTIVE"
+	SessionStatusPending SessionStatus = "PENDING"
+	SessionStatusDenied  SessionStatus = "DENIED"
+)
+
+var AllSessionStatus = []SessionStatus{
+	SessionStatusActive,
+	SessionStatusPending,
+	SessionStatusDenied,
+}
+
+func (e SessionStatus) IsValid() bool {
+	switch e {
+	case SessionStatusActive, SessionStatusPending, SessionStatusDenied:
+		return true
+	}
+	return false
+}
+
+func (e SessionStatus) String() string {
+	return string(e)
+}
+
+func (e *SessionStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SessionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SessionStatus", str)
+	}
+	return nil
+}
+
+func (e SessionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type StorageProvider string
+
+const (
+	StorageProviderAws     StorageProvider = "AWS"
+	StorageProviderGcs     StorageProvider = "GCS"
+	StorageProviderAzure   StorageProvider = "AZURE"
+	StorageProviderAlibaba StorageProvider = "ALIBABA"
+)
+
+var AllStorageProvider = []StorageProvider{
+	StorageProviderAws,
+	StorageProviderGcs,
+	StorageProviderAzure,
+	StorageProviderAlibaba,
+}
+
+func (e StorageProvider) IsValid() bool {
+	switch e {
+	case StorageProviderAws, StorageProviderGcs, StorageProviderAzure, StorageProviderAlibaba:
+		return true
+	}
+	return false
+}
+
+func (e StorageProvider) String() string {
+	return string(e)
+}
+
+func (e *StorageProvider) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StorageProvider(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StorageProvider", str)
+	}
+	return nil
+}
+
+func (e StorageProvider) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventChannelCreated     WebhookEvent = "CHANNEL_CREATED"
+	WebhookEventMeetingStarted     WebhookEvent = "MEETING_STARTED"
+	WebhookEventMeetingEnded       WebhookEvent = "MEETING_ENDED"
+	WebhookEventRecordingStarted   WebhookEvent = "RECORDING_STARTED"
+	WebhookEventRecordingAvailable WebhookEvent = "RECORDING_AVAILABLE"
+	// A participant rated a meeting with submitFeedback
+	WebhookEventFeedbackSubmitted WebhookEvent = "FEEDBACK_SUBMITTED"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventChannelCreated,
+	WebhookEventMeetingStarted,
+	WebhookEventMeetingEnded,
+	WebhookEventRecordingStarted,
+	WebhookEventRecordingAvailable,
+	WebhookEventFeedbackSubmitted,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventChannelCreated, WebhookEventMeetingStarted, WebhookEventMeetingEnded, WebhookEventRecordingStarted, WebhookEventRecordingAvailable, WebhookEventFeedbackSubmitted:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
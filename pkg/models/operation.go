@@ -0,0 +1,35 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+)
+
+// StoredOperation is a GraphQL operation document that may be executed while OPERATION_ALLOWLIST is enabled
+type StoredOperation struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	Hash      string         `db:"hash"`
+	Name      sql.NullString `db:"name"`
+	Document  string         `db:"document"`
+	CreatedBy sql.NullInt64  `db:"created_by"`
+}
+
+// OperationHash is the SHA-256 of an operation document in hex, the same hash clients send for automatic persisted
+// queries
+func OperationHash(document string) string {
+	hash := sha256.Sum256([]byte(document))
+	return hex.EncodeToString(hash[:])
+}
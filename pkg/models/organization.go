@@ -0,0 +1,58 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// OrganizationAccount is a team of users that shares ownership of the channels created in it
+type OrganizationAccount struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	Name      string    `db:"name"`
+}
+
+// OrganizationMembership grants a user a role in an organization
+type OrganizationMembership struct {
+	ID             int64     `db:"id"`
+	CreatedAt      time.Time `db:"created_at"`
+	OrganizationID int64     `db:"organization_id"`
+	UserID         int64     `db:"user_id"`
+	Role           string    `db:"role"`
+	// UserName and Email are loaded along with the membership when listing the members of an organization
+	UserName sql.NullString `db:"user_name"`
+	Email    sql.NullString `db:"email"`
+}
+
+// OrganizationCredentials is the Agora project the channels of an organization are created in. Everything except the
+// app ID is encrypted
+type OrganizationCredentials struct {
+	OrganizationID      int64     `db:"organization_id"`
+	CreatedAt           time.Time `db:"created_at"`
+	AppID               string    `db:"app_id"`
+	AppCertificate      string    `db:"app_certificate"`
+	CustomerID          string    `db:"customer_id"`
+	CustomerCertificate string    `db:"customer_certificate"`
+}
+
+// OrganizationStorage is a bucket that the recordings of the channels of an organization are uploaded to. The access
+// key and secret key are encrypted
+type OrganizationStorage struct {
+	OrganizationID int64     `db:"organization_id"`
+	CreatedAt      time.Time `db:"created_at"`
+	Provider       string    `db:"provider"`
+	Region         int       `db:"region"`
+	Bucket         string    `db:"bucket"`
+	AccessKey      string    `db:"access_key"`
+	SecretKey      string    `db:"secret_key"`
+}
@@ -0,0 +1,38 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Participant is a user that was issued credentials to join a channel
+type Participant struct {
+	ID             int64          `db:"id"`
+	CreatedAt      time.Time      `db:"created_at"`
+	ChannelID      int64          `db:"channel_id"`
+	UID            int            `db:"uid"`
+	ScreenShareUID sql.NullInt32  `db:"screen_share_uid"`
+	Name           sql.NullString `db:"name"`
+	UserID         sql.NullInt64  `db:"user_id"`
+	Mode           JoinMode       `db:"mode"`
+}
+
+// ChannelBan keeps a participant from joining a channel again until it expires
+type ChannelBan struct {
+	ID          int64         `db:"id"`
+	CreatedAt   time.Time     `db:"created_at"`
+	ChannelID   int64         `db:"channel_id"`
+	UID         int           `db:"uid"`
+	UserID      sql.NullInt64 `db:"user_id"`
+	BannedUntil time.Time     `db:"banned_until"`
+}
@@ -0,0 +1,27 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PhoneCode is a one time code texted to a phone number to sign in with. Only the hash of the code is stored
+type PhoneCode struct {
+	ID          int64        `db:"id"`
+	CreatedAt   time.Time    `db:"created_at"`
+	PhoneNumber string       `db:"phone_number"`
+	CodeHash    string       `db:"code_hash"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	Attempts    int          `db:"attempts"`
+	UsedAt      sql.NullTime `db:"used_at"`
+}
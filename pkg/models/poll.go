@@ -0,0 +1,38 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ChannelPoll is a question a host asked the participants of a channel to vote on
+type ChannelPoll struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	ChannelID int64          `db:"channel_id"`
+	PollID    string         `db:"poll_id"`
+	Question  string         `db:"question"`
+	Options   pq.StringArray `db:"options"`
+	ClosedAt  sql.NullTime   `db:"closed_at"`
+}
+
+// PollVote is the option a participant voted for in a poll. Participants have one vote per poll
+type PollVote struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	PollID    int64     `db:"poll_id"`
+	UID       int       `db:"uid"`
+	Option    int       `db:"option"`
+}
@@ -0,0 +1,42 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Status of a call placed with dial out
+const (
+	PSTNCallDialing = "dialing"
+	PSTNCallFailed  = "failed"
+)
+
+// PSTNCall is a phone call placed by the PSTN gateway to bring a participant into a channel
+type PSTNCall struct {
+	ID          int64     `db:"id"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	ChannelID   int64     `db:"channel_id"`
+	CallID      string    `db:"call_id"`
+	PhoneNumber string    `db:"phone_number"`
+	Status      string    `db:"status"`
+}
+
+// PSTNNumber is a phone number participants in a country can dial in to channels with
+type PSTNNumber struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	Country   string         `db:"country"`
+	Region    sql.NullString `db:"region"`
+	Number    string         `db:"number"`
+}
@@ -0,0 +1,34 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChannelQualitySample is a sample of the call quality a participant of a channel reported. Metrics the client could not
+// measure are null
+type ChannelQualitySample struct {
+	ID         int64     `db:"id"`
+	CreatedAt  time.Time `db:"created_at"`
+	ChannelID  int64     `db:"channel_id"`
+	UID        int64     `db:"uid"`
+	ReportedAt time.Time `db:"reported_at"`
+	// RTT is the round trip time to the Agora edge server in milliseconds
+	RTT sql.NullInt32 `db:"rtt"`
+	// UplinkPacketLoss and DownlinkPacketLoss are percentages of packets lost
+	UplinkPacketLoss   sql.NullFloat64 `db:"uplink_packet_loss"`
+	DownlinkPacketLoss sql.NullFloat64 `db:"downlink_packet_loss"`
+	// Width, Height and FrameRate describe the video the participant was sending
+	Width     sql.NullInt32 `db:"width"`
+	Height    sql.NullInt32 `db:"height"`
+	FrameRate sql.NullInt32 `db:"frame_rate"`
+}
@@ -0,0 +1,37 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChannelQuestion is a question the audience of a channel asked the hosts
+type ChannelQuestion struct {
+	ID         int64          `db:"id"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ChannelID  int64          `db:"channel_id"`
+	QuestionID string         `db:"question_id"`
+	UID        sql.NullInt32  `db:"uid"`
+	Name       sql.NullString `db:"name"`
+	Body       string         `db:"body"`
+	Status     QuestionStatus `db:"status"`
+	Upvotes    int            `db:"upvotes"`
+}
+
+// QuestionVote is an upvote a participant gave a question. Participants can upvote a question once
+type QuestionVote struct {
+	ID         int64     `db:"id"`
+	CreatedAt  time.Time `db:"created_at"`
+	QuestionID int64     `db:"question_id"`
+	UID        int       `db:"uid"`
+}
@@ -0,0 +1,29 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ChannelRecording is a file uploaded to the storage bucket by cloud recording
+type ChannelRecording struct {
+	ID             int64          `db:"id"`
+	CreatedAt      time.Time      `db:"created_at"`
+	ChannelID      int64          `db:"channel_id"`
+	SID            string         `db:"sid"`
+	FileName       string         `db:"file_name"`
+	TrackType      sql.NullString `db:"track_type"`
+	UID            sql.NullString `db:"uid"`
+	IsPlayable     bool           `db:"is_playable"`
+	SliceStartTime sql.NullInt64  `db:"slice_start_time"`
+}
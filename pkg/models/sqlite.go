@@ -0,0 +1,33 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+//go:build sqlite
+// +build sqlite
+
+package models
+
+import (
+	"errors"
+
+	// The SQLite driver requires cgo, so it is only registered in binaries built with the sqlite tag
+	"github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sqliteViolation = func(err error) (bool, bool) {
+		var sqliteErr sqlite3.Error
+		if !errors.As(err, &sqliteErr) {
+			return false, false
+		}
+
+		unique := sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+		return unique, sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+	}
+}
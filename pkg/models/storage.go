@@ -0,0 +1,26 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"time"
+)
+
+// ChannelStorage is a bucket supplied by the host of a channel. The access key and secret key are encrypted
+type ChannelStorage struct {
+	ChannelID int64     `db:"channel_id"`
+	CreatedAt time.Time `db:"created_at"`
+	Provider  string    `db:"provider"`
+	Region    int       `db:"region"`
+	Bucket    string    `db:"bucket"`
+	AccessKey string    `db:"access_key"`
+	SecretKey string    `db:"secret_key"`
+}
@@ -0,0 +1,273 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedConnector opens connections that record a span for every statement run with a context that is being traced
+// and that cancel statements which run for longer than timeout
+type tracedConnector struct {
+	driver.Connector
+	// system names the database in spans, like postgresql
+	system string
+	// timeout limits how long a statement may run, on top of the deadline of its context. Statements are not limited
+	// when it is 0
+	timeout time.Duration
+}
+
+// Connect opens a traced connection
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracedConn{conn, c.system, c.timeout}, nil
+}
+
+// dsnConnector opens connections with a data source name for drivers that have no connector of their own
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect opens a connection to the data source
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the driver of the connector
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// tracedConn wraps a connection of a driver that supports contexts for queries, statements and transactions
+type tracedConn struct {
+	driver.Conn
+	system  string
+	timeout time.Duration
+}
+
+// withTimeout derives the context a statement runs with from the context it was called with. A statement that
+// outlives its context is cancelled by the driver, so the context has to be cancelled once the statement is done
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// startQuerySpan starts a span for a statement when ctx is being traced. Statements run without a traced context,
+// such as those of background jobs, are not recorded so that they do not each become a trace of their own
+func startQuerySpan(ctx context.Context, system string, query string) (context.Context, trace.Span, bool) {
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx, nil, false
+	}
+
+	ctx, span := otel.Tracer("github.com/samyak-jain/agora_backend/pkg/models").Start(ctx, "db.query", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		label.String("db.system", system),
+		label.String("db.statement", query),
+	))
+	return ctx, span, true
+}
+
+// endQuerySpan marks the span of a statement as failed when err is set and ends it
+func endQuerySpan(span trace.Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// QueryContext runs a query, recording it in a span
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	ctx, span, traced := startQuerySpan(ctx, c.system, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if traced {
+		endQuerySpan(span, err)
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return timedRows{rows, cancel}, nil
+}
+
+// ExecContext runs a statement, recording it in a span
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+
+	ctx, span, traced := startQuerySpan(ctx, c.system, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	if traced {
+		endQuerySpan(span, err)
+	}
+
+	return result, err
+}
+
+// PrepareContext prepares a statement whose executions are recorded in spans like statements that are not prepared
+func (c tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tracedStmt{stmt, query, c.system, c.timeout}, nil
+}
+
+// BeginTx starts a transaction
+func (c tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+// Ping checks whether the connection is still alive
+func (c tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+// ResetSession lets the driver check whether the connection can be reused before it is handed out again
+func (c tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// CheckNamedValue lets the driver convert arguments of its own types, like the unsigned integers of MySQL
+func (c tracedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+// tracedStmt wraps a prepared statement of a driver, which may only support executions without a context
+type tracedStmt struct {
+	driver.Stmt
+	query   string
+	system  string
+	timeout time.Duration
+}
+
+// ExecContext runs the statement, recording it in a span
+func (s tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, cancel := withTimeout(ctx, s.timeout)
+	defer cancel()
+
+	ctx, span, traced := startQuerySpan(ctx, s.system, s.query)
+
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(positionalValues(args))
+	}
+
+	if traced {
+		endQuerySpan(span, err)
+	}
+
+	return result, err
+}
+
+// QueryContext runs the statement as a query, recording it in a span
+func (s tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, cancel := withTimeout(ctx, s.timeout)
+	ctx, span, traced := startQuerySpan(ctx, s.system, s.query)
+
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(positionalValues(args))
+	}
+
+	if traced {
+		endQuerySpan(span, err)
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return timedRows{rows, cancel}, nil
+}
+
+// timedRows are the rows of a query whose context lasts until the rows are closed, since drivers read rows while
+// they are being scanned
+type timedRows struct {
+	driver.Rows
+	cancel context.CancelFunc
+}
+
+// Close closes the rows and releases the context of their query
+func (r timedRows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+// positionalValues drops the names of arguments, which Postgres does not support, for statements that only take
+// positional arguments
+func positionalValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for _, arg := range args {
+		values[arg.Ordinal-1] = arg.Value
+	}
+
+	return values
+}
@@ -0,0 +1,57 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Transcription is a Real-Time Transcription task that captions a channel and stores its transcript
+type Transcription struct {
+	ID           int64        `db:"id"`
+	CreatedAt    time.Time    `db:"created_at"`
+	ChannelID    int64        `db:"channel_id"`
+	TaskID       string       `db:"task_id"`
+	BuilderToken string       `db:"builder_token"`
+	Language     string       `db:"language"`
+	FilePrefix   string       `db:"file_prefix"`
+	Status       string       `db:"status"`
+	StoppedAt    sql.NullTime `db:"stopped_at"`
+}
+
+// Status of a recording transcript
+const (
+	TranscriptPending    = "pending"
+	TranscriptProcessing = "processing"
+	TranscriptDone       = "done"
+	TranscriptFailed     = "failed"
+)
+
+// ChannelTranscript is the transcript of a recording session, generated once its files have been uploaded
+type ChannelTranscript struct {
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+	ChannelID int64          `db:"channel_id"`
+	SID       string         `db:"sid"`
+	Status    string         `db:"status"`
+	Error     sql.NullString `db:"error"`
+}
+
+// ChannelTranscriptSegment is a piece of speech in a recording transcript, timed from the start of the recording
+type ChannelTranscriptSegment struct {
+	ID           int64  `db:"id"`
+	TranscriptID int64  `db:"transcript_id"`
+	StartMs      int64  `db:"start_ms"`
+	EndMs        int64  `db:"end_ms"`
+	Text         string `db:"text"`
+}
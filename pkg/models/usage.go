@@ -0,0 +1,41 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UsageMetric is something that usage is metered and quotas are enforced for
+type UsageMetric string
+
+// Metrics recorded in usage_records
+const (
+	UsageChannels           UsageMetric = "channels"
+	UsageRecordingMinutes   UsageMetric = "recording_minutes"
+	UsageParticipantMinutes UsageMetric = "participant_minutes"
+	UsagePSTNCalls          UsageMetric = "pstn_calls"
+)
+
+// UsageRecord is metered usage of a channel. It counts towards the organization of the channel, or its owner when
+// the channel is not in an organization. Source identifies what was metered so that nothing is metered twice
+type UsageRecord struct {
+	ID             int64         `db:"id"`
+	CreatedAt      time.Time     `db:"created_at"`
+	OccurredAt     time.Time     `db:"occurred_at"`
+	UserID         sql.NullInt64 `db:"user_id"`
+	OrganizationID sql.NullInt64 `db:"organization_id"`
+	ChannelID      sql.NullInt64 `db:"channel_id"`
+	Metric         UsageMetric   `db:"metric"`
+	Quantity       float64       `db:"quantity"`
+	Source         string        `db:"source"`
+}
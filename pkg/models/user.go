@@ -13,14 +13,47 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // UserAccount model contains all relevant details of a particular user
 type UserAccount struct {
-	ID         int64          `db:"id"`
-	UserName   sql.NullString `db:"user_name"`
-	Email      string         `db:"email"`
-	Identifier string         `db:"identifier"`
+	ID       int64          `db:"id"`
+	UserName sql.NullString `db:"user_name"`
+	// Email is empty for users that signed up with their phone number
+	Email      string `db:"email"`
+	Identifier string `db:"identifier"`
+	// Provider is the OAuth provider the user signed in with, NULL for users that signed in before it was recorded
+	Provider sql.NullString `db:"provider"`
+	// PasswordHash is the bcrypt hash of the password of users that signed up with their email
+	PasswordHash    sql.NullString `db:"password_hash"`
+	EmailVerifiedAt sql.NullTime   `db:"email_verified_at"`
+	PhoneNumber     sql.NullString `db:"phone_number"`
+	// Roles grant access to the operations guarded by the hasRole directive
+	Roles pq.StringArray `db:"roles"`
+}
+
+// HasRole reports whether the user has been granted a role
+func (user *UserAccount) HasRole(role Role) bool {
+	for _, granted := range user.Roles {
+		if granted == role.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TwoFactorState is the second factor of a user. LastStep is the TOTP time step of the last code that was used, so
+// that a code cannot be used twice. Failures counts the invalid codes since the last valid one, and no code is
+// accepted before LockedUntil
+type TwoFactorState struct {
+	Secret      sql.NullString `db:"totp_secret"`
+	EnabledAt   sql.NullTime   `db:"totp_enabled_at"`
+	LastStep    int64          `db:"totp_last_step"`
+	Failures    int            `db:"totp_failures"`
+	LockedUntil sql.NullTime   `db:"totp_locked_until"`
 }
 
 type Auth struct {
@@ -30,13 +63,39 @@ type Auth struct {
 	RefreshToken string    `db:"refresh_token"`
 	TokenType    string    `db:"token_type"`
 	Expiry       time.Time `db:"expiry"`
+	// UserID is the user who signed in with the credentials, and Provider is the provider they signed in with
+	UserID   sql.NullInt64  `db:"user_id"`
+	Provider sql.NullString `db:"provider"`
+	// Scope lists the scopes the user granted, separated by spaces
+	Scope sql.NullString `db:"scope"`
 }
 
-// Token stores the token of a user
+// Token stores the access token of a user. Tokens issued before refresh tokens were introduced have no expiry or family
 type Token struct {
-	ID      int64  `db:"id"`
-	TokenID string `db:"token_id"`
-	UserID  int64  `db:"user_id"`
+	ID        int64          `db:"id"`
+	CreatedAt time.Time      `db:"created_at"`
+	TokenID   string         `db:"token_id"`
+	UserID    int64          `db:"user_id"`
+	ExpiresAt sql.NullTime   `db:"expires_at"`
+	FamilyID  sql.NullString `db:"family_id"`
+	// LastUsedAt, UserAgent, IP and Location describe the last request made with the token
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	UserAgent  sql.NullString `db:"user_agent"`
+	IP         sql.NullString `db:"ip"`
+	Location   sql.NullString `db:"location"`
+}
+
+// RefreshToken is a single use token that is exchanged for a new access token and refresh token. The tokens issued
+// from one sign in share a family, which is revoked as a whole when a used refresh token is presented again
+type RefreshToken struct {
+	ID        int64        `db:"id"`
+	CreatedAt time.Time    `db:"created_at"`
+	UserID    int64        `db:"user_id"`
+	FamilyID  string       `db:"family_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
 }
 
 // GetAllTokens fetches the token id of all the tokens of that user
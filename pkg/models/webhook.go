@@ -0,0 +1,58 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Events of channels delivered to webhooks and the event bus
+const (
+	WebhookChannelCreated     = "channel.created"
+	WebhookMeetingStarted     = "meeting.started"
+	WebhookMeetingEnded       = "meeting.ended"
+	WebhookRecordingStarted   = "recording.started"
+	WebhookRecordingAvailable = "recording.available"
+	WebhookFeedbackSubmitted  = "feedback.submitted"
+)
+
+// WebhookEndpoint is a URL events of channels are delivered to. Webhooks of an organization receive the events of its
+// channels, and webhooks of an API key receive the events of the channels its owner owns outside of organizations.
+// Secret is encrypted with ENCRYPTION_KEY
+type WebhookEndpoint struct {
+	ID             int64          `db:"id"`
+	CreatedAt      time.Time      `db:"created_at"`
+	OrganizationID sql.NullInt64  `db:"organization_id"`
+	APIKeyID       sql.NullInt64  `db:"api_key_id"`
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	Events         pq.StringArray `db:"events"`
+}
+
+// WebhookDeliveryLog is an event queued for delivery to a webhook. NextAttemptAt is cleared once the event was
+// delivered or delivery was given up on
+type WebhookDeliveryLog struct {
+	ID            int64          `db:"id"`
+	CreatedAt     time.Time      `db:"created_at"`
+	WebhookID     int64          `db:"webhook_id"`
+	EventID       string         `db:"event_id"`
+	Event         string         `db:"event"`
+	Payload       string         `db:"payload"`
+	Attempts      int            `db:"attempts"`
+	NextAttemptAt sql.NullTime   `db:"next_attempt_at"`
+	DeliveredAt   sql.NullTime   `db:"delivered_at"`
+	FailedAt      sql.NullTime   `db:"failed_at"`
+	LastStatus    sql.NullInt32  `db:"last_status"`
+	LastError     sql.NullString `db:"last_error"`
+}
@@ -0,0 +1,61 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// APIKeyRepo runs the statements on the API keys users created for their backends
+type APIKeyRepo struct {
+	store
+}
+
+// ByHash fetches the API key that has not been revoked with the hash keyHash
+func (repo *APIKeyRepo) ByHash(ctx context.Context, keyHash string) (*models.APICredential, error) {
+	var key models.APICredential
+	err := repo.get(ctx, &key, "SELECT id, created_at, user_id, name, prefix, key_hash, scopes, last_used_at, revoked_at FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// Touch records that an API key was just used
+func (repo *APIKeyRepo) Touch(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return err
+}
+
+// ListForUser lists the API keys of a user that have not been revoked
+func (repo *APIKeyRepo) ListForUser(ctx context.Context, userID int64) ([]models.APICredential, error) {
+	keys := []models.APICredential{}
+	err := repo.selectAll(ctx, &keys, "SELECT id, created_at, user_id, name, prefix, key_hash, scopes, last_used_at, revoked_at FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id", userID)
+	return keys, err
+}
+
+// Create stores key and sets its ID and creation time
+func (repo *APIKeyRepo) Create(ctx context.Context, key *models.APICredential) error {
+	err := repo.insert(ctx, &key.ID, "INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes) VALUES (:user_id, :name, :prefix, :key_hash, :scopes)", key)
+	if err != nil {
+		return err
+	}
+
+	return repo.get(ctx, &key.CreatedAt, "SELECT created_at FROM api_keys WHERE id = $1", key.ID)
+}
+
+// Revoke revokes an API key of a user and returns sql.ErrNoRows when the user has no such key
+func (repo *APIKeyRepo) Revoke(ctx context.Context, id int64, userID int64) error {
+	return repo.execOne(ctx, "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", id, userID)
+}
@@ -0,0 +1,58 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// AttemptRepo runs the statements on the passphrase lookups recorded to slow down guessing passphrases
+type AttemptRepo struct {
+	store
+}
+
+// RecentFailures counts the failed lookups of a client since a time and returns when the last one was. The time is
+// passed in UTC, as SQLite compares it with the creation times as text
+func (repo *AttemptRepo) RecentFailures(ctx context.Context, ip string, since time.Time) (int, sql.NullTime, error) {
+	var failures int
+	var last sql.NullTime
+	err := repo.get(ctx, &failures, "SELECT COUNT(*) FROM passphrase_attempts WHERE ip = $1 AND NOT succeeded AND created_at > $2", ip, since.UTC())
+	if err != nil || failures == 0 {
+		return failures, last, err
+	}
+
+	// The time of the last failure is selected from the column rather than with MAX, which SQLite returns as text
+	err = repo.get(ctx, &last, "SELECT created_at FROM passphrase_attempts WHERE ip = $1 AND NOT succeeded ORDER BY created_at DESC LIMIT 1", ip)
+	return failures, last, err
+}
+
+// RecordFailure records a lookup of a passphrase that does not exist
+func (repo *AttemptRepo) RecordFailure(ctx context.Context, ip string) error {
+	_, err := repo.exec(ctx, "INSERT INTO passphrase_attempts (ip, succeeded) VALUES ($1, FALSE)", ip)
+	return err
+}
+
+// RecordSuccess records a successful lookup of the passphrase of a channel by a client that failed recently
+func (repo *AttemptRepo) RecordSuccess(ctx context.Context, ip string, channelID int64, failures int, locked bool) error {
+	_, err := repo.exec(ctx, "INSERT INTO passphrase_attempts (ip, channel_id, succeeded, failures, locked) VALUES ($1, $2, TRUE, $3, $4)", ip, channelID, failures, locked)
+	return err
+}
+
+// ListForChannel lists the last 100 lookups recorded for the passphrases of a channel, most recent first
+func (repo *AttemptRepo) ListForChannel(ctx context.Context, channelID int64) ([]models.ChannelPassphraseAttempt, error) {
+	attempts := []models.ChannelPassphraseAttempt{}
+	err := repo.selectAll(ctx, &attempts, "SELECT id, created_at, ip, channel_id, succeeded, failures, locked FROM passphrase_attempts WHERE channel_id = $1 ORDER BY created_at DESC LIMIT 100", channelID)
+	return attempts, err
+}
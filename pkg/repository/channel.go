@@ -0,0 +1,291 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// ListedChannel is a channel of a listing along with when it was created
+type ListedChannel struct {
+	models.Channel
+	CreatedAt sql.NullTime `db:"created_at"`
+}
+
+// ChannelStats counts the channels of the deployment
+type ChannelStats struct {
+	Channels     int `db:"channels"`
+	CreatedSince int `db:"created_since"`
+	Recording    int `db:"recording"`
+}
+
+// ChannelRepo runs the statements on channels and their passphrases
+type ChannelRepo struct {
+	store
+}
+
+// ByPassphrase fetches the channel a passphrase belongs to along with the type of the passphrase
+func (repo *ChannelRepo) ByPassphrase(ctx context.Context, passphrase string) (*models.Channel, models.PassphraseType, error) {
+	var result struct {
+		models.Channel
+		Role models.PassphraseType `db:"role"`
+	}
+	err := repo.get(ctx, &result, "SELECT "+models.ChannelColumns+", channel_passphrases.role FROM channels INNER JOIN channel_passphrases ON channel_passphrases.channel_id = channels.id WHERE channel_passphrases.passphrase = $1", passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &result.Channel, result.Role, nil
+}
+
+// ByID fetches a channel
+func (repo *ChannelRepo) ByID(ctx context.Context, id int64) (*models.Channel, error) {
+	var channel models.Channel
+	err := repo.get(ctx, &channel, "SELECT "+models.ChannelColumns+" FROM channels WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &channel, nil
+}
+
+// ByNames fetches the channels with channel names along with when they were created, in no particular order.
+// Channels that do not exist are left out
+func (repo *ChannelRepo) ByNames(ctx context.Context, channelNames []string) ([]ListedChannel, error) {
+	channels := []ListedChannel{}
+	if len(channelNames) == 0 {
+		return channels, nil
+	}
+
+	args := make([]interface{}, len(channelNames))
+	for index, channelName := range channelNames {
+		args[index] = channelName
+	}
+
+	err := repo.selectAll(ctx, &channels, "SELECT "+models.ChannelColumns+", channels.created_at FROM channels WHERE channel_name IN ("+placeholders(len(channelNames))+")", args...)
+	return channels, err
+}
+
+// IDByName looks up the ID of the channel with a channel name
+func (repo *ChannelRepo) IDByName(ctx context.Context, channelName string) (int64, error) {
+	var id int64
+	err := repo.get(ctx, &id, "SELECT id FROM channels WHERE channel_name = $1", channelName)
+	return id, err
+}
+
+// List lists up to limit channels created before the channel with ID before, most recently created first
+func (repo *ChannelRepo) List(ctx context.Context, before sql.NullInt64, limit int) ([]ListedChannel, error) {
+	channels := []ListedChannel{}
+	err := repo.selectAll(ctx, &channels, repo.dialect(
+		"SELECT "+models.ChannelColumns+", channels.created_at FROM channels WHERE ($1::INT IS NULL OR id < $1) ORDER BY id DESC LIMIT $2",
+		"SELECT "+models.ChannelColumns+", channels.created_at FROM channels WHERE ($1 IS NULL OR id < $1) ORDER BY id DESC LIMIT $2",
+	), before, limit)
+	return channels, err
+}
+
+// ListByOrganization lists the channels of an organization like List
+func (repo *ChannelRepo) ListByOrganization(ctx context.Context, organizationID int64, before sql.NullInt64, limit int) ([]ListedChannel, error) {
+	channels := []ListedChannel{}
+	err := repo.selectAll(ctx, &channels, repo.dialect(
+		"SELECT "+models.ChannelColumns+", channels.created_at FROM channels WHERE organization_id = $1 AND ($2::INT IS NULL OR id < $2) ORDER BY id DESC LIMIT $3",
+		"SELECT "+models.ChannelColumns+", channels.created_at FROM channels WHERE organization_id = $1 AND ($2 IS NULL OR id < $2) ORDER BY id DESC LIMIT $3",
+	), organizationID, before, limit)
+	return channels, err
+}
+
+// Create inserts a channel and sets its ID
+func (repo *ChannelRepo) Create(ctx context.Context, channel *models.Channel) error {
+	return repo.insert(ctx, &channel.ID, "INSERT INTO channels (title, channel_name, channel_secret, host_passphrase, viewer_passphrase, dtmf, token_expiry_seconds, allow_viewers_to_publish, starts_at, ends_at, waiting_room, max_participants, owner_id, sip_uri, whiteboard_room_uuid, organization_id, area) VALUES (:title, :channel_name, :channel_secret, :host_passphrase, :viewer_passphrase, :dtmf, :token_expiry_seconds, :allow_viewers_to_publish, :starts_at, :ends_at, :waiting_room, :max_participants, :owner_id, :sip_uri, :whiteboard_room_uuid, :organization_id, :area)", channel)
+}
+
+// Update stores the settings hosts can change on a channel
+func (repo *ChannelRepo) Update(ctx context.Context, channel *models.Channel) error {
+	return repo.namedExec(ctx, "UPDATE channels SET title = :title, max_participants = :max_participants, waiting_room = :waiting_room, recording_quality = :recording_quality, dtmf = :dtmf, sip_uri = :sip_uri, area = :area WHERE id = :id", channel)
+}
+
+// SetMetadata replaces the metadata of a channel
+func (repo *ChannelRepo) SetMetadata(ctx context.Context, id int64, metadata types.JSONText) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET metadata = $1 WHERE id = $2", metadata, id)
+	return err
+}
+
+// SetSchedule sets when a channel starts and ends
+func (repo *ChannelRepo) SetSchedule(ctx context.Context, id int64, startsAt time.Time, endsAt time.Time) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET starts_at = $1, ends_at = $2 WHERE id = $3", startsAt, endsAt, id)
+	return err
+}
+
+// SetOrganization moves a channel to an organization, or out of its organization when organizationID is NULL
+func (repo *ChannelRepo) SetOrganization(ctx context.Context, id int64, organizationID sql.NullInt64) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET organization_id = $1 WHERE id = $2", organizationID, id)
+	return err
+}
+
+// SetLocked locks or unlocks a channel, lifting a lock after failed passphrase attempts
+func (repo *ChannelRepo) SetLocked(ctx context.Context, id int64, locked bool) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET locked = $1, locked_until = NULL WHERE id = $2", locked, id)
+	return err
+}
+
+// LockFor locks a channel for a number of minutes and returns when the lock ends
+func (repo *ChannelRepo) LockFor(ctx context.Context, id int64, minutes int) (sql.NullTime, error) {
+	var lockedUntil sql.NullTime
+	if repo.mysql() {
+		_, err := repo.exec(ctx, "UPDATE channels SET locked_until = CURRENT_TIMESTAMP + INTERVAL $1 MINUTE WHERE id = $2", minutes, id)
+		if err != nil {
+			return lockedUntil, err
+		}
+
+		err = repo.get(ctx, &lockedUntil, "SELECT locked_until FROM channels WHERE id = $1", id)
+		return lockedUntil, err
+	}
+
+	err := repo.get(ctx, &lockedUntil, repo.dialect(
+		"UPDATE channels SET locked_until = NOW() + $1 * INTERVAL '1 minute' WHERE id = $2 RETURNING locked_until",
+		"UPDATE channels SET locked_until = DATETIME('now', $1 || ' minutes') WHERE id = $2 RETURNING locked_until",
+	), minutes, id)
+	return lockedUntil, err
+}
+
+// SetDialIn sets the DTMF and SIP URI that callers join a channel with
+func (repo *ChannelRepo) SetDialIn(ctx context.Context, id int64, dtmf string, sipURI sql.NullString) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET dtmf = $1, sip_uri = $2 WHERE id = $3", dtmf, sipURI, id)
+	return err
+}
+
+// DTMFTaken reports whether a DTMF is used by a channel that has not ended
+func (repo *ChannelRepo) DTMFTaken(ctx context.Context, dtmf string) (bool, error) {
+	var taken bool
+	err := repo.get(ctx, &taken, "SELECT EXISTS (SELECT 1 FROM channels WHERE dtmf = $1 AND ended_at IS NULL)", dtmf)
+	return taken, err
+}
+
+// SetOwner makes a user the owner of a channel along with its new host passphrase
+func (repo *ChannelRepo) SetOwner(ctx context.Context, id int64, ownerID int64, hostPassphrase string) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET owner_id = $1, host_passphrase = $2 WHERE id = $3", ownerID, hostPassphrase, id)
+	return err
+}
+
+// LogHostTransfer records that a channel was handed over from one user to another
+func (repo *ChannelRepo) LogHostTransfer(ctx context.Context, id int64, fromUserID int64, toUserID int64) error {
+	_, err := repo.exec(ctx, "INSERT INTO host_transfers (channel_id, from_user_id, to_user_id) VALUES ($1, $2, $3)", id, fromUserID, toUserID)
+	return err
+}
+
+// AddPassphrases adds passphrases to channels
+func (repo *ChannelRepo) AddPassphrases(ctx context.Context, passphrases ...models.ChannelPassphrase) error {
+	for index := range passphrases {
+		err := repo.namedExec(ctx, "INSERT INTO channel_passphrases (channel_id, passphrase, name, role) VALUES (:channel_id, :passphrase, :name, :role)", &passphrases[index])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetPassphrase replaces the host or viewer passphrase of a channel
+func (repo *ChannelRepo) SetPassphrase(ctx context.Context, id int64, role models.PassphraseType, passphrase string) error {
+	query := "UPDATE channels SET viewer_passphrase = $1 WHERE id = $2"
+	if role == models.PassphraseTypeHost {
+		query = "UPDATE channels SET host_passphrase = $1 WHERE id = $2"
+	}
+
+	_, err := repo.exec(ctx, query, passphrase, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.exec(ctx, "UPDATE channel_passphrases SET passphrase = $1 WHERE channel_id = $2 AND role = $3", passphrase, id, role)
+	return err
+}
+
+// RenamePassphrases gives the passphrases of a channel with one role another role and name
+func (repo *ChannelRepo) RenamePassphrases(ctx context.Context, id int64, from models.PassphraseType, to models.PassphraseType, name string) error {
+	_, err := repo.exec(ctx, "UPDATE channel_passphrases SET role = $1, name = $2 WHERE channel_id = $3 AND role = $4", to, name, id, from)
+	return err
+}
+
+// DeletePassphrases revokes the passphrases of a channel with a role
+func (repo *ChannelRepo) DeletePassphrases(ctx context.Context, id int64, role models.PassphraseType) error {
+	_, err := repo.exec(ctx, "DELETE FROM channel_passphrases WHERE channel_id = $1 AND role = $2", id, role)
+	return err
+}
+
+// ClaimRecording marks a recording in mode as starting on a channel since startedAt, which keeps others from starting
+// one until it is stored with SetRecording or released with ReleaseRecording
+func (repo *ChannelRepo) ClaimRecording(ctx context.Context, id int64, mode string, startedAt time.Time) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET recording_status = 'starting', recording_mode = $1, recording_started_at = $2 WHERE id = $3 AND recording_sid IS NULL", mode, startedAt, id)
+	return err
+}
+
+// SetRecording stores the recording that was started on a channel it was claimed for. It returns sql.ErrNoRows when
+// the claim is gone, like when the meeting ended while the recording was starting
+func (repo *ChannelRepo) SetRecording(ctx context.Context, channel *models.Channel) error {
+	return repo.execOne(ctx, "UPDATE channels SET recording_uid = $1, recording_sid = $2, recording_rid = $3, recording_paused = $4, recording_mode = $5, recording_status = $6, recording_started_at = $7 WHERE id = $8 AND recording_status = 'starting' AND recording_sid IS NULL",
+		channel.RecordingUID, channel.RecordingSID, channel.RecordingRID, channel.RecordingPaused, channel.RecordingMode, channel.RecordingStatus, channel.RecordingStartedAt, channel.ID)
+}
+
+// ReleaseRecording gives up the claim of a recording that could not be started on a channel
+func (repo *ChannelRepo) ReleaseRecording(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET recording_status = NULL, recording_started_at = NULL WHERE id = $1 AND recording_status = 'starting' AND recording_sid IS NULL", id)
+	return err
+}
+
+// SetRecordingPaused stores whether the recording of a channel is paused
+func (repo *ChannelRepo) SetRecordingPaused(ctx context.Context, id int64, paused bool) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET recording_paused = $1 WHERE id = $2", paused, id)
+	return err
+}
+
+// SetRecordingRetention sets for how many days the recordings of a channel are kept, forever when days is NULL
+func (repo *ChannelRepo) SetRecordingRetention(ctx context.Context, id int64, days sql.NullInt32) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET recording_retention_days = $1 WHERE id = $2", days, id)
+	return err
+}
+
+// ClearRecording marks the recording of a channel as stopped
+func (repo *ChannelRepo) ClearRecording(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET recording_status = 'stopped', recording_uid = NULL, recording_sid = NULL, recording_rid = NULL, recording_paused = FALSE, recording_started_at = NULL WHERE id = $1", id)
+	return err
+}
+
+// End marks a channel as ended along with its recording, if one was running. A recording that is still starting
+// loses its claim, so that it is stopped once it started
+func (repo *ChannelRepo) End(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE channels SET ended_at = COALESCE(ended_at, CURRENT_TIMESTAMP), recording_status = CASE WHEN recording_sid IS NOT NULL THEN 'stopped' WHEN recording_status = 'starting' THEN NULL ELSE recording_status END, recording_uid = NULL, recording_sid = NULL, recording_rid = NULL, recording_paused = FALSE, recording_started_at = NULL WHERE id = $1", id)
+	return err
+}
+
+// OrganizationRecording reports whether one of the channels of an organization is being recorded
+func (repo *ChannelRepo) OrganizationRecording(ctx context.Context, organizationID int64) (bool, error) {
+	var recording bool
+	err := repo.get(ctx, &recording, "SELECT EXISTS (SELECT 1 FROM channels WHERE organization_id = $1 AND recording_sid IS NOT NULL)", organizationID)
+	return recording, err
+}
+
+// Stats counts the channels, those created after since and those being recorded
+func (repo *ChannelRepo) Stats(ctx context.Context, since time.Time) (*ChannelStats, error) {
+	var stats ChannelStats
+	// Times are compared as text on SQLite, where the default timestamps are in UTC
+	err := repo.get(ctx, &stats, "SELECT COUNT(*) AS channels, COUNT(CASE WHEN created_at > $1 THEN 1 END) AS created_since, COUNT(recording_sid) AS recording FROM channels",
+		since.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,50 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// CredentialRepo runs the statements on the tokens OAuth providers exchanged codes for
+type CredentialRepo struct {
+	store
+}
+
+// ByCode fetches the credentials a code was exchanged for
+func (repo *CredentialRepo) ByCode(ctx context.Context, code string) (*models.Auth, error) {
+	var credentials models.Auth
+	err := repo.get(ctx, &credentials, "SELECT id, code, access_token, refresh_token, token_type, expiry FROM credentials WHERE code = $1", code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+// Create stores the credentials a code was exchanged for
+func (repo *CredentialRepo) Create(ctx context.Context, credentials *models.Auth) error {
+	return repo.namedExec(ctx, "INSERT INTO credentials (code, access_token, refresh_token, token_type, expiry, provider, scope) VALUES (:code, :access_token, :refresh_token, :token_type, :expiry, :provider, :scope)", credentials)
+}
+
+// SetAccessToken replaces the access token of the credentials of a code after it was refreshed
+func (repo *CredentialRepo) SetAccessToken(ctx context.Context, code string, accessToken string) error {
+	_, err := repo.exec(ctx, "UPDATE credentials SET access_token = $1 WHERE code = $2", accessToken, code)
+	return err
+}
+
+// LinkUser records the user who signed in with the credentials of a code
+func (repo *CredentialRepo) LinkUser(ctx context.Context, code string, userID int64) error {
+	_, err := repo.exec(ctx, "UPDATE credentials SET user_id = $1 WHERE code = $2", userID, code)
+	return err
+}
@@ -0,0 +1,36 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// RecordingRepo runs the statements on the files uploaded by cloud recording
+type RecordingRepo struct {
+	store
+}
+
+// ListForChannel lists the recording files of a channel, most recent first
+func (repo *RecordingRepo) ListForChannel(ctx context.Context, channelID int64) ([]models.ChannelRecording, error) {
+	recordings := []models.ChannelRecording{}
+	err := repo.selectAll(ctx, &recordings, "SELECT id, created_at, channel_id, sid, file_name, track_type, uid, is_playable, slice_start_time FROM recordings WHERE channel_id = $1 ORDER BY created_at DESC", channelID)
+	return recordings, err
+}
+
+// Count counts the recording files
+func (repo *RecordingRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := repo.get(ctx, &count, "SELECT COUNT(*) FROM recordings")
+	return count, err
+}
@@ -0,0 +1,337 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// Package repository runs the statements on the channels, users, tokens and recordings tables for the resolvers.
+// Statements are prepared once per database and bound to a transaction when the repositories are used within one.
+// Statements are written for Postgres, with a SQLite variant where the dialects differ
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// Repositories groups the repositories of the tables the resolvers work with
+type Repositories struct {
+	Channels    *ChannelRepo
+	Users       *UserRepo
+	Tokens      *TokenRepo
+	Recordings  *RecordingRepo
+	APIKeys     *APIKeyRepo
+	Attempts    *AttemptRepo
+	Credentials *CredentialRepo
+
+	statements *statements
+}
+
+// New creates the repositories of a database
+func New(db *models.Database) *Repositories {
+	return newRepositories(store{statements: &statements{
+		db:       db,
+		prepared: map[string]preparedStmt{},
+		named:    map[string]*sqlx.NamedStmt{},
+	}})
+}
+
+func newRepositories(s store) *Repositories {
+	return &Repositories{
+		Channels:    &ChannelRepo{s},
+		Users:       &UserRepo{s},
+		Tokens:      &TokenRepo{s},
+		Recordings:  &RecordingRepo{s},
+		APIKeys:     &APIKeyRepo{s},
+		Attempts:    &AttemptRepo{s},
+		Credentials: &CredentialRepo{s},
+		statements:  s.statements,
+	}
+}
+
+// WithTx returns repositories that run their statements within tx. They share the prepared statements of the
+// repositories they were created from
+func (repos *Repositories) WithTx(tx *sqlx.Tx) *Repositories {
+	return newRepositories(store{statements: repos.statements, tx: tx})
+}
+
+// Close closes the statements that were prepared
+func (repos *Repositories) Close() error {
+	return repos.statements.close()
+}
+
+// statements caches the statements prepared on a database by their query
+type statements struct {
+	db *models.Database
+
+	mu       sync.Mutex
+	prepared map[string]preparedStmt
+	named    map[string]*sqlx.NamedStmt
+}
+
+// preparedStmt is a prepared statement along with the order it takes its arguments in
+type preparedStmt struct {
+	*sqlx.Stmt
+	// params lists the argument each parameter of a statement rebound for MySQL takes, nil when the statement takes its
+	// arguments as they are given
+	params []int
+}
+
+// args orders args for the parameters of the statement
+func (stmt preparedStmt) args(args []interface{}) []interface{} {
+	if stmt.params == nil {
+		return args
+	}
+
+	ordered := make([]interface{}, len(stmt.params))
+	for index, param := range stmt.params {
+		ordered[index] = args[param]
+	}
+
+	return ordered
+}
+
+// placeholder matches the $1 style parameters statements are written with
+var placeholder = regexp.MustCompile(`\$[0-9]+`)
+
+// placeholders lists n parameters for an IN clause, starting at $1
+func placeholders(n int) string {
+	params := make([]string, n)
+	for index := range params {
+		params[index] = "$" + strconv.Itoa(index+1)
+	}
+
+	return strings.Join(params, ", ")
+}
+
+// rebind rewrites the parameters of query into the ? parameters of MySQL, which are bound in the order they appear in
+// and take an argument each, even when they refer to the same one. It returns the argument each parameter takes
+func rebind(query string) (string, []int) {
+	params := []int{}
+	query = placeholder.ReplaceAllStringFunc(query, func(param string) string {
+		position, _ := strconv.Atoi(param[1:])
+		params = append(params, position-1)
+		return "?"
+	})
+
+	return query, params
+}
+
+// prepare returns the statement of query, preparing it the first time it is used
+func (s *statements) prepare(ctx context.Context, query string) (preparedStmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stmt, ok := s.prepared[query]; ok {
+		return stmt, nil
+	}
+
+	stmt := preparedStmt{}
+	rebound := query
+	if s.db.MySQL() {
+		rebound, stmt.params = rebind(query)
+	}
+
+	var err error
+	stmt.Stmt, err = s.db.PreparexContext(ctx, rebound)
+	if err != nil {
+		return stmt, err
+	}
+
+	s.prepared[query] = stmt
+	return stmt, nil
+}
+
+// prepareNamed returns the statement of a query with named parameters, preparing it the first time it is used
+func (s *statements) prepareNamed(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stmt, ok := s.named[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := s.db.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.named[query] = stmt
+	return stmt, nil
+}
+
+// close closes every prepared statement, returning the first error
+func (s *statements) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var first error
+	for query, stmt := range s.prepared {
+		if err := stmt.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(s.prepared, query)
+	}
+
+	for query, stmt := range s.named {
+		if err := stmt.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(s.named, query)
+	}
+
+	return first
+}
+
+// store runs prepared statements on the database, or within tx when it is set
+type store struct {
+	statements *statements
+	tx         *sqlx.Tx
+}
+
+// stmt returns the prepared statement of query, bound to the transaction of the store, along with args ordered for
+// its parameters
+func (s store) stmt(ctx context.Context, query string, args []interface{}) (*sqlx.Stmt, []interface{}, error) {
+	stmt, err := s.statements.prepare(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.tx == nil {
+		return stmt.Stmt, stmt.args(args), nil
+	}
+
+	return s.tx.StmtxContext(ctx, stmt.Stmt), stmt.args(args), nil
+}
+
+// namedStmt returns the prepared statement of a query with named parameters, bound to the transaction of the store
+func (s store) namedStmt(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	stmt, err := s.statements.prepareNamed(ctx, query)
+	if err != nil || s.tx == nil {
+		return stmt, err
+	}
+
+	return s.tx.NamedStmtContext(ctx, stmt), nil
+}
+
+// dialect picks the variant of a statement for the database of the store out of those for Postgres, SQLite and
+// MySQL. A database without a variant of its own uses the one before it, so that a statement SQLite and MySQL share
+// is only given once. Every variant uses $1 style parameters, which SQLite binds in the order they first appear in
+// and which are rebound for MySQL
+func (s store) dialect(variants ...string) string {
+	index := 0
+	switch {
+	case s.statements.db.MySQL():
+		index = 2
+	case s.statements.db.SQLite():
+		index = 1
+	}
+
+	if index >= len(variants) {
+		index = len(variants) - 1
+	}
+
+	return variants[index]
+}
+
+// mysql reports whether the statements of the store run on MySQL, which has no RETURNING clause
+func (s store) mysql() bool {
+	return s.statements.db.MySQL()
+}
+
+func (s store) get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, args, err := s.stmt(ctx, query, args)
+	if err != nil {
+		return err
+	}
+
+	return stmt.GetContext(ctx, dest, args...)
+}
+
+func (s store) selectAll(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, args, err := s.stmt(ctx, query, args)
+	if err != nil {
+		return err
+	}
+
+	return stmt.SelectContext(ctx, dest, args...)
+}
+
+func (s store) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, args, err := s.stmt(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+// execOne runs a statement that changes a single row and returns sql.ErrNoRows when there was no row to change
+func (s store) execOne(ctx context.Context, query string, args ...interface{}) error {
+	result, err := s.exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s store) namedGet(ctx context.Context, dest interface{}, query string, arg interface{}) error {
+	stmt, err := s.namedStmt(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return stmt.GetContext(ctx, dest, arg)
+}
+
+func (s store) namedExec(ctx context.Context, query string, arg interface{}) error {
+	stmt, err := s.namedStmt(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, arg)
+	return err
+}
+
+// insert runs an INSERT statement with named parameters and sets id to the ID of the row it inserted. MySQL cannot
+// return the ID from the statement, so the ID it generated for the row is read from the result instead
+func (s store) insert(ctx context.Context, id *int64, query string, arg interface{}) error {
+	if !s.mysql() {
+		return s.namedGet(ctx, id, query+" RETURNING id", arg)
+	}
+
+	stmt, err := s.namedStmt(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	result, err := stmt.ExecContext(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	*id, err = result.LastInsertId()
+	return err
+}
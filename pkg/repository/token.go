@@ -0,0 +1,150 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// TokenRepo runs the statements on the access tokens and refresh tokens of users
+type TokenRepo struct {
+	store
+}
+
+// ListForUser lists every access token of a user
+func (repo *TokenRepo) ListForUser(ctx context.Context, userID int64) ([]models.Token, error) {
+	tokens := []models.Token{}
+	err := repo.selectAll(ctx, &tokens, "SELECT id, created_at, token_id, user_id, expires_at, family_id FROM tokens WHERE user_id = $1", userID)
+	return tokens, err
+}
+
+// ListActive lists the access tokens of a user that have not expired, most recently used first
+func (repo *TokenRepo) ListActive(ctx context.Context, userID int64) ([]models.Token, error) {
+	tokens := []models.Token{}
+	err := repo.selectAll(ctx, &tokens, `SELECT id, created_at, token_id, user_id, expires_at, family_id, last_used_at, user_agent, ip, location FROM tokens
+		WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP) ORDER BY COALESCE(last_used_at, created_at) DESC`, userID)
+	return tokens, err
+}
+
+// ByTokenID looks up an access token that has not expired
+func (repo *TokenRepo) ByTokenID(ctx context.Context, tokenID string) (*models.Token, error) {
+	var token models.Token
+	err := repo.get(ctx, &token, "SELECT id, token_id, user_id, last_used_at, user_agent, ip FROM tokens WHERE token_id = $1 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)", tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RecordUsage stores the time, user agent, address and location of the last request made with an access token.
+// Empty addresses and locations are not recorded
+func (repo *TokenRepo) RecordUsage(ctx context.Context, id int64, userAgent string, ip string, location string) error {
+	_, err := repo.exec(ctx, "UPDATE tokens SET last_used_at = CURRENT_TIMESTAMP, user_agent = $1, ip = NULLIF($2, ''), location = COALESCE(NULLIF($3, ''), location) WHERE id = $4",
+		userAgent, ip, location, id)
+	return err
+}
+
+// Create stores an access token of a user in a family of tokens. The expiry is stored in UTC, as SQLite compares it
+// with CURRENT_TIMESTAMP as text
+func (repo *TokenRepo) Create(ctx context.Context, tokenID string, userID int64, expiresAt time.Time, familyID string) error {
+	_, err := repo.exec(ctx, "INSERT INTO tokens (token_id, user_id, expires_at, family_id) VALUES ($1, $2, $3, $4)", tokenID, userID, expiresAt.UTC(), familyID)
+	return err
+}
+
+// Delete deletes an access token of a user and returns its family. It returns sql.ErrNoRows when the user has no
+// such token
+func (repo *TokenRepo) Delete(ctx context.Context, tokenID string, userID int64) (sql.NullString, error) {
+	return repo.deleteReturningFamily(ctx, "token_id = $1 AND user_id = $2", tokenID, userID)
+}
+
+// DeleteByID is Delete for the ID of the access token, which is how sessions are listed
+func (repo *TokenRepo) DeleteByID(ctx context.Context, id int64, userID int64) (sql.NullString, error) {
+	return repo.deleteReturningFamily(ctx, "id = $1 AND user_id = $2", id, userID)
+}
+
+// deleteReturningFamily deletes the access token matched by condition and returns its family. MySQL cannot return
+// the family from the statement, so it is read and locked before the token is deleted
+func (repo *TokenRepo) deleteReturningFamily(ctx context.Context, condition string, args ...interface{}) (sql.NullString, error) {
+	var familyID sql.NullString
+	if !repo.mysql() {
+		err := repo.get(ctx, &familyID, "DELETE FROM tokens WHERE "+condition+" RETURNING family_id", args...)
+		return familyID, err
+	}
+
+	err := repo.get(ctx, &familyID, "SELECT family_id FROM tokens WHERE "+condition+" FOR UPDATE", args...)
+	if err != nil {
+		return familyID, err
+	}
+
+	_, err = repo.exec(ctx, "DELETE FROM tokens WHERE "+condition, args...)
+	return familyID, err
+}
+
+// DeleteForUser deletes every access token of a user and revokes every refresh token of the user. It returns the
+// number of access tokens that were deleted
+func (repo *TokenRepo) DeleteForUser(ctx context.Context, userID int64) (int64, error) {
+	result, err := repo.exec(ctx, "DELETE FROM tokens WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = repo.exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL", userID)
+	return deleted, err
+}
+
+// CreateRefresh stores the hash of a refresh token of a user in a family of tokens
+func (repo *TokenRepo) CreateRefresh(ctx context.Context, userID int64, familyID string, tokenHash string, expiresAt time.Time) error {
+	_, err := repo.exec(ctx, "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)", userID, familyID, tokenHash, expiresAt.UTC())
+	return err
+}
+
+// LockRefresh looks up the refresh token with a hash and locks it until the transaction of the repository ends
+func (repo *TokenRepo) LockRefresh(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	// SQLite has no row locks, but of two transactions that write to the database the second one fails
+	err := repo.get(ctx, &token, repo.dialect(
+		"SELECT id, created_at, user_id, family_id, token_hash, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE",
+		"SELECT id, created_at, user_id, family_id, token_hash, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1",
+		"SELECT id, created_at, user_id, family_id, token_hash, expires_at, used_at, revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE",
+	), tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkRefreshUsed records that a refresh token was exchanged, after which it cannot be used again
+func (repo *TokenRepo) MarkRefreshUsed(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return err
+}
+
+// RevokeFamily revokes every refresh token of a family and deletes its access tokens
+func (repo *TokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := repo.exec(ctx, "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL", familyID)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.exec(ctx, "DELETE FROM tokens WHERE family_id = $1", familyID)
+	return err
+}
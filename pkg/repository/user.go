@@ -0,0 +1,196 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// UserRepo runs the statements on users
+type UserRepo struct {
+	store
+}
+
+// ByID looks up a user along with its roles
+func (repo *UserRepo) ByID(ctx context.Context, id int64) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := repo.get(ctx, &user, "SELECT id, identifier, user_name, COALESCE(email, '') AS email, provider, roles FROM users WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ByEmail looks up the user that signed up with an email along with its password hash
+func (repo *UserRepo) ByEmail(ctx context.Context, email string) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := repo.get(ctx, &user, "SELECT id, identifier, user_name, email, provider, password_hash, email_verified_at FROM users WHERE LOWER(email) = $1", email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ByProviderEmail looks up the user with the email an OAuth provider gave, compared as it was given
+func (repo *UserRepo) ByProviderEmail(ctx context.Context, email string) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := repo.get(ctx, &user, "SELECT id, identifier, user_name, email, provider FROM users WHERE email = $1", email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ByIdentifierOrEmail looks up a user by its identifier or its email
+func (repo *UserRepo) ByIdentifierOrEmail(ctx context.Context, identifier string) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := repo.get(ctx, &user, "SELECT id, user_name, COALESCE(email, '') AS email, identifier FROM users WHERE identifier = $1 OR email = $1 LIMIT 1", identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ByIDs fetches the users with IDs, in no particular order. Users that do not exist are left out
+func (repo *UserRepo) ByIDs(ctx context.Context, ids []int64) ([]models.UserAccount, error) {
+	users := []models.UserAccount{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for index, id := range ids {
+		args[index] = id
+	}
+
+	err := repo.selectAll(ctx, &users, "SELECT id, identifier, user_name, COALESCE(email, '') AS email, provider FROM users WHERE id IN ("+placeholders(len(ids))+")", args...)
+	return users, err
+}
+
+// BySlackID looks up the user that signed in with a Slack account
+func (repo *UserRepo) BySlackID(ctx context.Context, slackUserID string) (*models.UserAccount, error) {
+	var user models.UserAccount
+	err := repo.get(ctx, &user, "SELECT id, identifier, user_name, COALESCE(email, '') AS email, provider, roles FROM users WHERE provider = 'slack' AND identifier = $1", slackUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// IDByPhone looks up the ID of the user that signed up with a phone number
+func (repo *UserRepo) IDByPhone(ctx context.Context, phoneNumber string) (int64, error) {
+	var id int64
+	err := repo.get(ctx, &id, "SELECT id FROM users WHERE phone_number = $1", phoneNumber)
+	return id, err
+}
+
+// Create inserts a user and sets its ID. Users without an email, like those that signed up with their phone
+// number, are stored without one
+func (repo *UserRepo) Create(ctx context.Context, user *models.UserAccount) error {
+	return repo.insert(ctx, &user.ID, "INSERT INTO users (identifier, user_name, email, provider, password_hash, phone_number) VALUES (:identifier, :user_name, NULLIF(:email, ''), :provider, :password_hash, :phone_number)", user)
+}
+
+// SetProvider records the provider a user signs in with
+func (repo *UserRepo) SetProvider(ctx context.Context, id int64, provider string) error {
+	_, err := repo.exec(ctx, "UPDATE users SET provider = $1 WHERE id = $2", provider, id)
+	return err
+}
+
+// UpdateName sets the name of the user with an identifier
+func (repo *UserRepo) UpdateName(ctx context.Context, identifier string, name string) error {
+	_, err := repo.exec(ctx, "UPDATE users SET user_name = $1 WHERE identifier = $2", name, identifier)
+	return err
+}
+
+// VerifyEmail marks the email of a user as verified and sets its password hash when one is given
+func (repo *UserRepo) VerifyEmail(ctx context.Context, id int64, passwordHash *string) error {
+	_, err := repo.exec(ctx, "UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP), password_hash = COALESCE($1, password_hash) WHERE id = $2", passwordHash, id)
+	return err
+}
+
+// SetRoles replaces the roles of a user. It returns sql.ErrNoRows when there is no such user
+func (repo *UserRepo) SetRoles(ctx context.Context, id int64, roles pq.StringArray) error {
+	return repo.execOne(ctx, "UPDATE users SET roles = $1 WHERE id = $2", roles, id)
+}
+
+// Delete deletes a user. It returns sql.ErrNoRows when there is no such user
+func (repo *UserRepo) Delete(ctx context.Context, id int64) error {
+	return repo.execOne(ctx, "DELETE FROM users WHERE id = $1", id)
+}
+
+// LockTwoFactor loads the second factor of a user and locks it until the transaction of the repository ends
+func (repo *UserRepo) LockTwoFactor(ctx context.Context, id int64) (*models.TwoFactorState, error) {
+	var state models.TwoFactorState
+	// SQLite has no row locks, but of two transactions that write to the database the second one fails
+	err := repo.get(ctx, &state, repo.dialect(
+		"SELECT totp_secret, totp_enabled_at, totp_last_step, totp_failures, totp_locked_until FROM users WHERE id = $1 FOR UPDATE",
+		"SELECT totp_secret, totp_enabled_at, totp_last_step, totp_failures, totp_locked_until FROM users WHERE id = $1",
+		"SELECT totp_secret, totp_enabled_at, totp_last_step, totp_failures, totp_locked_until FROM users WHERE id = $1 FOR UPDATE",
+	), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SetTOTPStep records the TOTP time step of the last code a user signed in with
+func (repo *UserRepo) SetTOTPStep(ctx context.Context, id int64, step int64) error {
+	_, err := repo.exec(ctx, "UPDATE users SET totp_last_step = $1 WHERE id = $2", step, id)
+	return err
+}
+
+// RecordTwoFactorFailure counts an invalid second factor code of a user and refuses codes until lockedUntil when it
+// is valid
+func (repo *UserRepo) RecordTwoFactorFailure(ctx context.Context, id int64, lockedUntil sql.NullTime) error {
+	_, err := repo.exec(ctx, "UPDATE users SET totp_failures = totp_failures + 1, totp_locked_until = $1 WHERE id = $2", lockedUntil, id)
+	return err
+}
+
+// ResetTwoFactorFailures forgets the invalid second factor codes of a user after a valid one
+func (repo *UserRepo) ResetTwoFactorFailures(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE users SET totp_failures = 0, totp_locked_until = NULL WHERE id = $1", id)
+	return err
+}
+
+// SetTOTPSecret stores the encrypted TOTP secret a user enrolls with. It returns sql.ErrNoRows when the user
+// already enabled two factor authentication
+func (repo *UserRepo) SetTOTPSecret(ctx context.Context, id int64, encrypted string) error {
+	return repo.execOne(ctx, "UPDATE users SET totp_secret = $1, totp_last_step = 0 WHERE id = $2 AND totp_enabled_at IS NULL", encrypted, id)
+}
+
+// EnableTwoFactor enables two factor authentication for a user that confirmed its secret with the code of step
+func (repo *UserRepo) EnableTwoFactor(ctx context.Context, id int64, step int64) error {
+	_, err := repo.exec(ctx, "UPDATE users SET totp_enabled_at = CURRENT_TIMESTAMP, totp_last_step = $1 WHERE id = $2", step, id)
+	return err
+}
+
+// DisableTwoFactor removes the TOTP secret of a user
+func (repo *UserRepo) DisableTwoFactor(ctx context.Context, id int64) error {
+	_, err := repo.exec(ctx, "UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, totp_failures = 0, totp_locked_until = NULL WHERE id = $1", id)
+	return err
+}
+
+// Count counts the users
+func (repo *UserRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := repo.get(ctx, &count, "SELECT COUNT(*) FROM users")
+	return count, err
+}
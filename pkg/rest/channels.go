@@ -0,0 +1,148 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// CreateChannelRequest is the body of POST /v1/channels. Its fields are the arguments of the createChannel mutation
+type CreateChannelRequest struct {
+	Title                 string                      `json:"title"`
+	BackendURL            string                      `json:"backendURL"`
+	EnablePSTN            *bool                       `json:"enablePSTN"`
+	Storage               *models.ChannelStorageInput `json:"storage"`
+	TokenExpiry           *int                        `json:"tokenExpiry"`
+	AllowViewersToPublish *bool                       `json:"allowViewersToPublish"`
+	CustomHostPhrase      *string                     `json:"customHostPhrase"`
+	CustomViewPhrase      *string                     `json:"customViewPhrase"`
+	StartsAt              *time.Time                  `json:"startsAt"`
+	EndsAt                *time.Time                  `json:"endsAt"`
+	EnableWaitingRoom     *bool                       `json:"enableWaitingRoom"`
+	MaxParticipants       *int                        `json:"maxParticipants"`
+	Country               *string                     `json:"country"`
+	EnableWhiteboard      *bool                       `json:"enableWhiteboard"`
+	OrganizationID        *string                     `json:"organizationId"`
+	Area                  *models.AgoraArea           `json:"area"`
+}
+
+func (api *API) createChannelEndpoint() endpoint {
+	return endpoint{
+		Method:      "POST",
+		Path:        "/v1/channels",
+		OperationID: "createChannel",
+		Summary:     "Creates a channel and returns its passphrases, like the createChannel mutation",
+		Body:        CreateChannelRequest{},
+		Response:    models.ShareResponse{},
+		Status:      http.StatusCreated,
+		Handle:      api.createChannel,
+	}
+}
+
+// createChannel creates a channel with the createChannel mutation
+func (api *API) createChannel(r *http.Request) (interface{}, error) {
+	var request CreateChannelRequest
+	err := decode(r, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Title == "" || request.BackendURL == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "title and backendURL are required")
+	}
+
+	if request.Area != nil && !request.Area.IsValid() {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid area")
+	}
+
+	if request.Storage != nil && !request.Storage.Provider.IsValid() {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid storage provider")
+	}
+
+	// enablePSTN is the only argument the mutation expects GraphQL to fill in the default of
+	if request.EnablePSTN == nil {
+		enablePSTN := false
+		request.EnablePSTN = &enablePSTN
+	}
+
+	var response *models.ShareResponse
+	err = api.Audit.Run(r.Context(), "createChannel", arguments(request), func(ctx context.Context) error {
+		var err error
+		response, err = api.Resolver.Mutation().CreateChannel(ctx, request.Title, request.BackendURL, request.EnablePSTN, request.Storage,
+			request.TokenExpiry, request.AllowViewersToPublish, request.CustomHostPhrase, request.CustomViewPhrase, request.StartsAt,
+			request.EndsAt, request.EnableWaitingRoom, request.MaxParticipants, request.Country, request.EnableWhiteboard,
+			request.OrganizationID, request.Area)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (api *API) joinChannelEndpoint() endpoint {
+	modes := make([]string, len(models.AllJoinMode))
+	for index, mode := range models.AllJoinMode {
+		modes[index] = mode.String()
+	}
+
+	return endpoint{
+		Method:      "GET",
+		Path:        "/v1/channels/{passphrase}/join",
+		OperationID: "joinChannel",
+		Summary:     "Returns the credentials to join the channel of a passphrase with, like the joinChannel query",
+		Parameters: []parameter{
+			{Name: "passphrase", In: "path", Description: "Host or viewer passphrase of the channel", Required: true},
+			{Name: "name", In: "query", Description: "Name of the participant, shown to the others"},
+			{Name: "mode", In: "query", Description: "What the participant joins with. Defaults to FULL", Enum: modes},
+		},
+		Response: models.Session{},
+		Status:   http.StatusOK,
+		Handle:   api.joinChannel,
+	}
+}
+
+// joinChannel joins a channel with the joinChannel query, counting the attempt at its passphrase
+func (api *API) joinChannel(r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	passphrase := mux.Vars(r)["passphrase"]
+	query := r.URL.Query()
+
+	var name *string
+	if query.Get("name") != "" {
+		value := query.Get("name")
+		name = &value
+	}
+
+	mode := models.JoinModeFull
+	if query.Get("mode") != "" {
+		mode = models.JoinMode(query.Get("mode"))
+		if !mode.IsValid() {
+			return nil, apierror.New(apierror.CodeBadRequest, "Invalid mode")
+		}
+	}
+
+	if api.PassphraseLimit != nil {
+		err := api.PassphraseLimit.Check(ctx, "joinChannel")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return api.Resolver.Query().JoinChannel(ctx, passphrase, name, &mode)
+}
@@ -0,0 +1,211 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// enums lists the values of the enums of the GraphQL schema the API uses, which reflection cannot find
+var enums = enumValues(
+	models.AllAgoraArea,
+	models.AllFeature,
+	models.AllJoinMode,
+	models.AllPassphraseType,
+	models.AllRecordingLayout,
+	models.AllSessionStatus,
+	models.AllStorageProvider,
+)
+
+// enumValues maps the types of the slices of enum values it is given to the values as strings
+func enumValues(slices ...interface{}) map[reflect.Type][]string {
+	result := map[reflect.Type][]string{}
+	for _, slice := range slices {
+		value := reflect.ValueOf(slice)
+		values := make([]string, value.Len())
+		for index := range values {
+			values[index] = fmt.Sprint(value.Index(index).Interface())
+		}
+
+		result[value.Type().Elem()] = values
+	}
+
+	return result
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas collects the schemas of the structs used by the endpoints, which are referred to by name
+type schemas map[string]interface{}
+
+// of returns the schema of a type, adding the structs it uses to s
+func (s schemas) of(t reflect.Type) map[string]interface{} {
+	if values, ok := enums[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.of(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+
+		schema["nullable"] = true
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": true}
+	case reflect.Struct:
+		if _, ok := s[t.Name()]; !ok {
+			// The name is taken before the fields are described, so that structs referring to themselves do not recurse
+			s[t.Name()] = nil
+			s[t.Name()] = s.object(t)
+		}
+
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object describes the fields of a struct by their JSON names. Fields that are not pointers are required
+func (s schemas) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.of(field.Type)
+		if field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// openAPISpec generates the OpenAPI 3 specification of endpoints
+func openAPISpec(endpoints []endpoint) map[string]interface{} {
+	components := schemas{}
+	errorResponse := map[string]interface{}{
+		"description": "The request failed. The status depends on the code of the error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": components.of(reflect.TypeOf(Error{}))},
+		},
+	}
+
+	paths := map[string]interface{}{}
+	for _, endpoint := range endpoints {
+		operation := map[string]interface{}{
+			"operationId": endpoint.OperationID,
+			"summary":     endpoint.Summary,
+			"responses": map[string]interface{}{
+				strconv.Itoa(endpoint.Status): map[string]interface{}{
+					"description": http.StatusText(endpoint.Status),
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": components.of(reflect.TypeOf(endpoint.Response))},
+					},
+				},
+				"default": errorResponse,
+			},
+		}
+
+		if len(endpoint.Parameters) > 0 {
+			parameters := make([]interface{}, len(endpoint.Parameters))
+			for index, param := range endpoint.Parameters {
+				schema := map[string]interface{}{"type": "string"}
+				if param.Enum != nil {
+					schema["enum"] = param.Enum
+				}
+
+				parameters[index] = map[string]interface{}{
+					"name":        param.Name,
+					"in":          param.In,
+					"description": param.Description,
+					"required":    param.Required,
+					"schema":      schema,
+				}
+			}
+			operation["parameters"] = parameters
+		}
+
+		if endpoint.Body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": components.of(reflect.TypeOf(endpoint.Body))},
+				},
+			}
+		}
+
+		path, ok := paths[endpoint.Path].(map[string]interface{})
+		if !ok {
+			path = map[string]interface{}{}
+			paths[endpoint.Path] = path
+		}
+		path[strings.ToLower(endpoint.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "App Builder REST API",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": components,
+			"securitySchemes": map[string]interface{}{
+				"loginToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+				"apiKey":     map[string]interface{}{"type": "apiKey", "in": "header", "name": middleware.APIKeyHeader},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"loginToken": []string{}},
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{},
+		},
+	}
+}
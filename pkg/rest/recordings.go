@@ -0,0 +1,75 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package rest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// StartRecordingRequest is the body of POST /v1/recordings/start. Its fields are the arguments of the
+// startRecordingSession mutation
+type StartRecordingRequest struct {
+	Passphrase       string                        `json:"passphrase"`
+	Secret           *string                       `json:"secret"`
+	RecordingQuality *models.RecordingQualityInput `json:"recordingQuality"`
+}
+
+// StartRecordingResponse is the response to POST /v1/recordings/start
+type StartRecordingResponse struct {
+	// SID identifies the recording to Agora
+	SID string `json:"sid"`
+}
+
+func (api *API) startRecordingEndpoint() endpoint {
+	return endpoint{
+		Method:      "POST",
+		Path:        "/v1/recordings/start",
+		OperationID: "startRecording",
+		Summary:     "Starts recording the channel of a host passphrase, like the startRecordingSession mutation",
+		Body:        StartRecordingRequest{},
+		Response:    StartRecordingResponse{},
+		Status:      http.StatusCreated,
+		Handle:      api.startRecording,
+	}
+}
+
+// startRecording starts a recording with the startRecordingSession mutation
+func (api *API) startRecording(r *http.Request) (interface{}, error) {
+	var request StartRecordingRequest
+	err := decode(r, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Passphrase == "" {
+		return nil, apierror.New(apierror.CodeBadRequest, "passphrase is required")
+	}
+
+	if request.RecordingQuality != nil && request.RecordingQuality.Layout != nil && !request.RecordingQuality.Layout.IsValid() {
+		return nil, apierror.New(apierror.CodeBadRequest, "Invalid recording layout")
+	}
+
+	var sid string
+	err = api.Audit.Run(r.Context(), "startRecordingSession", arguments(request), func(ctx context.Context) error {
+		var err error
+		sid, err = api.Resolver.Mutation().StartRecordingSession(ctx, request.Passphrase, request.Secret, request.RecordingQuality)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return StartRecordingResponse{SID: sid}, nil
+}
@@ -0,0 +1,156 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// Package rest is a versioned REST API for integrators that cannot use GraphQL. Its endpoints call the same resolvers
+// as the GraphQL API, so that they behave the same way, and its OpenAPI 3 specification is generated from the
+// endpoints themselves
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/graph"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// API serves the REST API. Requests are authenticated by the same middleware as GraphQL requests
+type API struct {
+	Resolver *graph.Resolver
+	Logger   *utils.Logger
+	// Audit records the mutations made through the API along with those made through GraphQL
+	Audit middleware.Audit
+	// PassphraseLimit limits attempts at passphrases the way it does for GraphQL. It is nil without Redis
+	PassphraseLimit *middleware.PassphraseRateLimit
+	// MaxBodyBytes is the largest request body, the same as that of GraphQL requests
+	MaxBodyBytes int64
+}
+
+// Error is the body of responses to requests that failed
+type Error struct {
+	// Code is the same code GraphQL errors carry in their extensions
+	Code      apierror.Code `json:"code"`
+	Message   string        `json:"message"`
+	RequestID *string       `json:"requestId"`
+}
+
+// parameter is a path or query parameter of an endpoint
+type parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	// Enum lists the values of the parameter when it is an enum
+	Enum []string
+}
+
+// endpoint is an operation of the API along with what its specification is generated from
+type endpoint struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Parameters  []parameter
+	// Body is a value of the type of the request body, or nil when there is none
+	Body interface{}
+	// Response is a value of the type of the response body
+	Response interface{}
+	// Status is the status of successful responses
+	Status int
+	Handle func(r *http.Request) (interface{}, error)
+}
+
+// endpoints lists the operations of the API
+func (api *API) endpoints() []endpoint {
+	return []endpoint{
+		api.createChannelEndpoint(),
+		api.joinChannelEndpoint(),
+		api.startRecordingEndpoint(),
+	}
+}
+
+// Routes adds the endpoints of the API and its specification at /v1/openapi.json to router
+func (api *API) Routes(router *mux.Router) {
+	for _, endpoint := range api.endpoints() {
+		router.Handle(endpoint.Path, api.handler(endpoint)).Methods(endpoint.Method)
+	}
+
+	spec := openAPISpec(api.endpoints())
+	router.HandleFunc("/v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	}).Methods("GET")
+}
+
+// handler answers the requests of an endpoint with the JSON of its result, or of the error it failed with
+func (api *API) handler(endpoint endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, api.MaxBodyBytes)
+		result, err := endpoint.Handle(r)
+		if err != nil {
+			api.writeError(w, r, err)
+			return
+		}
+
+		writeJSON(w, endpoint.Status, result)
+	})
+}
+
+// writeError answers a request with an error and the status of its code
+func (api *API) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	response := Error{
+		Code:    apierror.CodeOf(err),
+		Message: err.Error(),
+	}
+	if response.Code == "" {
+		response.Code = apierror.CodeBadRequest
+	}
+
+	if requestID := middleware.GetRequestID(r.Context()); requestID != "" {
+		response.RequestID = &requestID
+	}
+
+	writeJSON(w, apierror.HTTPStatus(err), response)
+}
+
+// writeJSON answers a request with a status and value encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// decode decodes the JSON body of a request into value. Unknown fields are refused so that typos are not silently
+// ignored
+func decode(r *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(value)
+	if err != nil {
+		return apierror.New(apierror.CodeBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	return nil
+}
+
+// arguments returns the fields of a request body as the arguments of its audit event
+func arguments(body interface{}) map[string]interface{} {
+	args := map[string]interface{}{}
+
+	encoded, err := json.Marshal(body)
+	if err == nil {
+		json.Unmarshal(encoded, &args)
+	}
+
+	return args
+}
@@ -0,0 +1,1890 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// gRPC API for internal services. Calls are authenticated with an API key in the x-api-key metadata and behave like
+// the GraphQL operations they are named after. Errors carry the code GraphQL errors have in their extensions in the
+// error-code trailer
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        (unknown)
+// source: appbuilder/v1/appbuilder.proto
+
+package appbuilderv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Geographic areas Agora can be restricted to, so that media, tokens and recordings stay within them
+type AgoraArea int32
+
+const (
+	AgoraArea_AGORA_AREA_UNSPECIFIED   AgoraArea = 0
+	AgoraArea_AGORA_AREA_GLOBAL        AgoraArea = 1
+	AgoraArea_AGORA_AREA_NORTH_AMERICA AgoraArea = 2
+	AgoraArea_AGORA_AREA_EUROPE        AgoraArea = 3
+	AgoraArea_AGORA_AREA_ASIA          AgoraArea = 4
+	AgoraArea_AGORA_AREA_JAPAN         AgoraArea = 5
+	AgoraArea_AGORA_AREA_INDIA         AgoraArea = 6
+	AgoraArea_AGORA_AREA_CHINA         AgoraArea = 7
+)
+
+// Enum value maps for AgoraArea.
+var (
+	AgoraArea_name = map[int32]string{
+		0: "AGORA_AREA_UNSPECIFIED",
+		1: "AGORA_AREA_GLOBAL",
+		2: "AGORA_AREA_NORTH_AMERICA",
+		3: "AGORA_AREA_EUROPE",
+		4: "AGORA_AREA_ASIA",
+		5: "AGORA_AREA_JAPAN",
+		6: "AGORA_AREA_INDIA",
+		7: "AGORA_AREA_CHINA",
+	}
+	AgoraArea_value = map[string]int32{
+		"AGORA_AREA_UNSPECIFIED":   0,
+		"AGORA_AREA_GLOBAL":        1,
+		"AGORA_AREA_NORTH_AMERICA": 2,
+		"AGORA_AREA_EUROPE":        3,
+		"AGORA_AREA_ASIA":          4,
+		"AGORA_AREA_JAPAN":         5,
+		"AGORA_AREA_INDIA":         6,
+		"AGORA_AREA_CHINA":         7,
+	}
+)
+
+func (x AgoraArea) Enum() *AgoraArea {
+	p := new(AgoraArea)
+	*p = x
+	return p
+}
+
+func (x AgoraArea) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AgoraArea) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[0].Descriptor()
+}
+
+func (AgoraArea) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[0]
+}
+
+func (x AgoraArea) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AgoraArea.Descriptor instead.
+func (AgoraArea) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{0}
+}
+
+type StorageProvider int32
+
+const (
+	StorageProvider_STORAGE_PROVIDER_UNSPECIFIED StorageProvider = 0
+	StorageProvider_STORAGE_PROVIDER_AWS         StorageProvider = 1
+	StorageProvider_STORAGE_PROVIDER_GCS         StorageProvider = 2
+	StorageProvider_STORAGE_PROVIDER_AZURE       StorageProvider = 3
+	StorageProvider_STORAGE_PROVIDER_ALIBABA     StorageProvider = 4
+)
+
+// Enum value maps for StorageProvider.
+var (
+	StorageProvider_name = map[int32]string{
+		0: "STORAGE_PROVIDER_UNSPECIFIED",
+		1: "STORAGE_PROVIDER_AWS",
+		2: "STORAGE_PROVIDER_GCS",
+		3: "STORAGE_PROVIDER_AZURE",
+		4: "STORAGE_PROVIDER_ALIBABA",
+	}
+	StorageProvider_value = map[string]int32{
+		"STORAGE_PROVIDER_UNSPECIFIED": 0,
+		"STORAGE_PROVIDER_AWS":         1,
+		"STORAGE_PROVIDER_GCS":         2,
+		"STORAGE_PROVIDER_AZURE":       3,
+		"STORAGE_PROVIDER_ALIBABA":     4,
+	}
+)
+
+func (x StorageProvider) Enum() *StorageProvider {
+	p := new(StorageProvider)
+	*p = x
+	return p
+}
+
+func (x StorageProvider) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StorageProvider) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[1].Descriptor()
+}
+
+func (StorageProvider) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[1]
+}
+
+func (x StorageProvider) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StorageProvider.Descriptor instead.
+func (StorageProvider) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{1}
+}
+
+type JoinMode int32
+
+const (
+	// Joins with everything, like FULL
+	JoinMode_JOIN_MODE_UNSPECIFIED      JoinMode = 0
+	JoinMode_JOIN_MODE_FULL             JoinMode = 1
+	JoinMode_JOIN_MODE_AUDIO_ONLY       JoinMode = 2
+	JoinMode_JOIN_MODE_SCREENSHARE_ONLY JoinMode = 3
+)
+
+// Enum value maps for JoinMode.
+var (
+	JoinMode_name = map[int32]string{
+		0: "JOIN_MODE_UNSPECIFIED",
+		1: "JOIN_MODE_FULL",
+		2: "JOIN_MODE_AUDIO_ONLY",
+		3: "JOIN_MODE_SCREENSHARE_ONLY",
+	}
+	JoinMode_value = map[string]int32{
+		"JOIN_MODE_UNSPECIFIED":      0,
+		"JOIN_MODE_FULL":             1,
+		"JOIN_MODE_AUDIO_ONLY":       2,
+		"JOIN_MODE_SCREENSHARE_ONLY": 3,
+	}
+)
+
+func (x JoinMode) Enum() *JoinMode {
+	p := new(JoinMode)
+	*p = x
+	return p
+}
+
+func (x JoinMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JoinMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[2].Descriptor()
+}
+
+func (JoinMode) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[2]
+}
+
+func (x JoinMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JoinMode.Descriptor instead.
+func (JoinMode) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{2}
+}
+
+type PassphraseType int32
+
+const (
+	PassphraseType_PASSPHRASE_TYPE_UNSPECIFIED PassphraseType = 0
+	PassphraseType_PASSPHRASE_TYPE_HOST        PassphraseType = 1
+	PassphraseType_PASSPHRASE_TYPE_COHOST      PassphraseType = 2
+	PassphraseType_PASSPHRASE_TYPE_VIEWER      PassphraseType = 3
+)
+
+// Enum value maps for PassphraseType.
+var (
+	PassphraseType_name = map[int32]string{
+		0: "PASSPHRASE_TYPE_UNSPECIFIED",
+		1: "PASSPHRASE_TYPE_HOST",
+		2: "PASSPHRASE_TYPE_COHOST",
+		3: "PASSPHRASE_TYPE_VIEWER",
+	}
+	PassphraseType_value = map[string]int32{
+		"PASSPHRASE_TYPE_UNSPECIFIED": 0,
+		"PASSPHRASE_TYPE_HOST":        1,
+		"PASSPHRASE_TYPE_COHOST":      2,
+		"PASSPHRASE_TYPE_VIEWER":      3,
+	}
+)
+
+func (x PassphraseType) Enum() *PassphraseType {
+	p := new(PassphraseType)
+	*p = x
+	return p
+}
+
+func (x PassphraseType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PassphraseType) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[3].Descriptor()
+}
+
+func (PassphraseType) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[3]
+}
+
+func (x PassphraseType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PassphraseType.Descriptor instead.
+func (PassphraseType) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{3}
+}
+
+type SessionStatus int32
+
+const (
+	SessionStatus_SESSION_STATUS_UNSPECIFIED SessionStatus = 0
+	SessionStatus_SESSION_STATUS_ACTIVE      SessionStatus = 1
+	SessionStatus_SESSION_STATUS_PENDING     SessionStatus = 2
+	SessionStatus_SESSION_STATUS_DENIED      SessionStatus = 3
+)
+
+// Enum value maps for SessionStatus.
+var (
+	SessionStatus_name = map[int32]string{
+		0: "SESSION_STATUS_UNSPECIFIED",
+		1: "SESSION_STATUS_ACTIVE",
+		2: "SESSION_STATUS_PENDING",
+		3: "SESSION_STATUS_DENIED",
+	}
+	SessionStatus_value = map[string]int32{
+		"SESSION_STATUS_UNSPECIFIED": 0,
+		"SESSION_STATUS_ACTIVE":      1,
+		"SESSION_STATUS_PENDING":     2,
+		"SESSION_STATUS_DENIED":      3,
+	}
+)
+
+func (x SessionStatus) Enum() *SessionStatus {
+	p := new(SessionStatus)
+	*p = x
+	return p
+}
+
+func (x SessionStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[4].Descriptor()
+}
+
+func (SessionStatus) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[4]
+}
+
+func (x SessionStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionStatus.Descriptor instead.
+func (SessionStatus) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{4}
+}
+
+type RecordingLayout int32
+
+const (
+	// Keeps the layout of the channel
+	RecordingLayout_RECORDING_LAYOUT_UNSPECIFIED RecordingLayout = 0
+	RecordingLayout_RECORDING_LAYOUT_FLOATING    RecordingLayout = 1
+	RecordingLayout_RECORDING_LAYOUT_GRID        RecordingLayout = 2
+	RecordingLayout_RECORDING_LAYOUT_SPOTLIGHT   RecordingLayout = 3
+)
+
+// Enum value maps for RecordingLayout.
+var (
+	RecordingLayout_name = map[int32]string{
+		0: "RECORDING_LAYOUT_UNSPECIFIED",
+		1: "RECORDING_LAYOUT_FLOATING",
+		2: "RECORDING_LAYOUT_GRID",
+		3: "RECORDING_LAYOUT_SPOTLIGHT",
+	}
+	RecordingLayout_value = map[string]int32{
+		"RECORDING_LAYOUT_UNSPECIFIED": 0,
+		"RECORDING_LAYOUT_FLOATING":    1,
+		"RECORDING_LAYOUT_GRID":        2,
+		"RECORDING_LAYOUT_SPOTLIGHT":   3,
+	}
+)
+
+func (x RecordingLayout) Enum() *RecordingLayout {
+	p := new(RecordingLayout)
+	*p = x
+	return p
+}
+
+func (x RecordingLayout) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecordingLayout) Descriptor() protoreflect.EnumDescriptor {
+	return file_appbuilder_v1_appbuilder_proto_enumTypes[5].Descriptor()
+}
+
+func (RecordingLayout) Type() protoreflect.EnumType {
+	return &file_appbuilder_v1_appbuilder_proto_enumTypes[5]
+}
+
+func (x RecordingLayout) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecordingLayout.Descriptor instead.
+func (RecordingLayout) EnumDescriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{5}
+}
+
+// Bucket the recordings of a channel are uploaded to instead of the bucket of the deployment
+type ChannelStorage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Provider  StorageProvider `protobuf:"varint,1,opt,name=provider,proto3,enum=appbuilder.v1.StorageProvider" json:"provider,omitempty"`
+	Region    int32           `protobuf:"varint,2,opt,name=region,proto3" json:"region,omitempty"`
+	Bucket    string          `protobuf:"bytes,3,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	AccessKey string          `protobuf:"bytes,4,opt,name=access_key,json=accessKey,proto3" json:"access_key,omitempty"`
+	SecretKey string          `protobuf:"bytes,5,opt,name=secret_key,json=secretKey,proto3" json:"secret_key,omitempty"`
+}
+
+func (x *ChannelStorage) Reset() {
+	*x = ChannelStorage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelStorage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelStorage) ProtoMessage() {}
+
+func (x *ChannelStorage) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelStorage.ProtoReflect.Descriptor instead.
+func (*ChannelStorage) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ChannelStorage) GetProvider() StorageProvider {
+	if x != nil {
+		return x.Provider
+	}
+	return StorageProvider_STORAGE_PROVIDER_UNSPECIFIED
+}
+
+func (x *ChannelStorage) GetRegion() int32 {
+	if x != nil {
+		return x.Region
+	}
+	return 0
+}
+
+func (x *ChannelStorage) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *ChannelStorage) GetAccessKey() string {
+	if x != nil {
+		return x.AccessKey
+	}
+	return ""
+}
+
+func (x *ChannelStorage) GetSecretKey() string {
+	if x != nil {
+		return x.SecretKey
+	}
+	return ""
+}
+
+type CreateChannelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Title       string          `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	BackendUrl  string          `protobuf:"bytes,2,opt,name=backend_url,json=backendUrl,proto3" json:"backend_url,omitempty"`
+	EnablePstn  bool            `protobuf:"varint,3,opt,name=enable_pstn,json=enablePstn,proto3" json:"enable_pstn,omitempty"`
+	Storage     *ChannelStorage `protobuf:"bytes,4,opt,name=storage,proto3" json:"storage,omitempty"`
+	TokenExpiry *int32          `protobuf:"varint,5,opt,name=token_expiry,json=tokenExpiry,proto3,oneof" json:"token_expiry,omitempty"`
+	// Defaults to true
+	AllowViewersToPublish *bool                  `protobuf:"varint,6,opt,name=allow_viewers_to_publish,json=allowViewersToPublish,proto3,oneof" json:"allow_viewers_to_publish,omitempty"`
+	CustomHostPhrase      *string                `protobuf:"bytes,7,opt,name=custom_host_phrase,json=customHostPhrase,proto3,oneof" json:"custom_host_phrase,omitempty"`
+	CustomViewPhrase      *string                `protobuf:"bytes,8,opt,name=custom_view_phrase,json=customViewPhrase,proto3,oneof" json:"custom_view_phrase,omitempty"`
+	StartsAt              *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt                *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	EnableWaitingRoom     bool                   `protobuf:"varint,11,opt,name=enable_waiting_room,json=enableWaitingRoom,proto3" json:"enable_waiting_room,omitempty"`
+	MaxParticipants       *int32                 `protobuf:"varint,12,opt,name=max_participants,json=maxParticipants,proto3,oneof" json:"max_participants,omitempty"`
+	Country               *string                `protobuf:"bytes,13,opt,name=country,proto3,oneof" json:"country,omitempty"`
+	EnableWhiteboard      bool                   `protobuf:"varint,14,opt,name=enable_whiteboard,json=enableWhiteboard,proto3" json:"enable_whiteboard,omitempty"`
+	OrganizationId        *string                `protobuf:"bytes,15,opt,name=organization_id,json=organizationId,proto3,oneof" json:"organization_id,omitempty"`
+	Area                  AgoraArea              `protobuf:"varint,16,opt,name=area,proto3,enum=appbuilder.v1.AgoraArea" json:"area,omitempty"`
+}
+
+func (x *CreateChannelRequest) Reset() {
+	*x = CreateChannelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateChannelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateChannelRequest) ProtoMessage() {}
+
+func (x *CreateChannelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateChannelRequest.ProtoReflect.Descriptor instead.
+func (*CreateChannelRequest) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateChannelRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetBackendUrl() string {
+	if x != nil {
+		return x.BackendUrl
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetEnablePstn() bool {
+	if x != nil {
+		return x.EnablePstn
+	}
+	return false
+}
+
+func (x *CreateChannelRequest) GetStorage() *ChannelStorage {
+	if x != nil {
+		return x.Storage
+	}
+	return nil
+}
+
+func (x *CreateChannelRequest) GetTokenExpiry() int32 {
+	if x != nil && x.TokenExpiry != nil {
+		return *x.TokenExpiry
+	}
+	return 0
+}
+
+func (x *CreateChannelRequest) GetAllowViewersToPublish() bool {
+	if x != nil && x.AllowViewersToPublish != nil {
+		return *x.AllowViewersToPublish
+	}
+	return false
+}
+
+func (x *CreateChannelRequest) GetCustomHostPhrase() string {
+	if x != nil && x.CustomHostPhrase != nil {
+		return *x.CustomHostPhrase
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetCustomViewPhrase() string {
+	if x != nil && x.CustomViewPhrase != nil {
+		return *x.CustomViewPhrase
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *CreateChannelRequest) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *CreateChannelRequest) GetEnableWaitingRoom() bool {
+	if x != nil {
+		return x.EnableWaitingRoom
+	}
+	return false
+}
+
+func (x *CreateChannelRequest) GetMaxParticipants() int32 {
+	if x != nil && x.MaxParticipants != nil {
+		return *x.MaxParticipants
+	}
+	return 0
+}
+
+func (x *CreateChannelRequest) GetCountry() string {
+	if x != nil && x.Country != nil {
+		return *x.Country
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetEnableWhiteboard() bool {
+	if x != nil {
+		return x.EnableWhiteboard
+	}
+	return false
+}
+
+func (x *CreateChannelRequest) GetOrganizationId() string {
+	if x != nil && x.OrganizationId != nil {
+		return *x.OrganizationId
+	}
+	return ""
+}
+
+func (x *CreateChannelRequest) GetArea() AgoraArea {
+	if x != nil {
+		return x.Area
+	}
+	return AgoraArea_AGORA_AREA_UNSPECIFIED
+}
+
+type Pstn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number string `protobuf:"bytes,1,opt,name=number,proto3" json:"number,omitempty"`
+	Dtmf   string `protobuf:"bytes,2,opt,name=dtmf,proto3" json:"dtmf,omitempty"`
+}
+
+func (x *Pstn) Reset() {
+	*x = Pstn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pstn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pstn) ProtoMessage() {}
+
+func (x *Pstn) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pstn.ProtoReflect.Descriptor instead.
+func (*Pstn) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pstn) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *Pstn) GetDtmf() string {
+	if x != nil {
+		return x.Dtmf
+	}
+	return ""
+}
+
+type Sip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uri string `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Pin string `protobuf:"bytes,2,opt,name=pin,proto3" json:"pin,omitempty"`
+}
+
+func (x *Sip) Reset() {
+	*x = Sip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sip) ProtoMessage() {}
+
+func (x *Sip) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sip.ProtoReflect.Descriptor instead.
+func (*Sip) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Sip) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *Sip) GetPin() string {
+	if x != nil {
+		return x.Pin
+	}
+	return ""
+}
+
+type CreateChannelResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Title   string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// Unset when the caller is not allowed to host the channel
+	HostPassphrase *string `protobuf:"bytes,3,opt,name=host_passphrase,json=hostPassphrase,proto3,oneof" json:"host_passphrase,omitempty"`
+	ViewPassphrase string  `protobuf:"bytes,4,opt,name=view_passphrase,json=viewPassphrase,proto3" json:"view_passphrase,omitempty"`
+	Pstn           *Pstn   `protobuf:"bytes,5,opt,name=pstn,proto3" json:"pstn,omitempty"`
+	Sip            *Sip    `protobuf:"bytes,6,opt,name=sip,proto3" json:"sip,omitempty"`
+}
+
+func (x *CreateChannelResponse) Reset() {
+	*x = CreateChannelResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateChannelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateChannelResponse) ProtoMessage() {}
+
+func (x *CreateChannelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateChannelResponse.ProtoReflect.Descriptor instead.
+func (*CreateChannelResponse) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateChannelResponse) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *CreateChannelResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateChannelResponse) GetHostPassphrase() string {
+	if x != nil && x.HostPassphrase != nil {
+		return *x.HostPassphrase
+	}
+	return ""
+}
+
+func (x *CreateChannelResponse) GetViewPassphrase() string {
+	if x != nil {
+		return x.ViewPassphrase
+	}
+	return ""
+}
+
+func (x *CreateChannelResponse) GetPstn() *Pstn {
+	if x != nil {
+		return x.Pstn
+	}
+	return nil
+}
+
+func (x *CreateChannelResponse) GetSip() *Sip {
+	if x != nil {
+		return x.Sip
+	}
+	return nil
+}
+
+type MintTokensRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Passphrase string `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	// Name of the participant, shown to the others
+	Name *string  `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Mode JoinMode `protobuf:"varint,3,opt,name=mode,proto3,enum=appbuilder.v1.JoinMode" json:"mode,omitempty"`
+}
+
+func (x *MintTokensRequest) Reset() {
+	*x = MintTokensRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MintTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MintTokensRequest) ProtoMessage() {}
+
+func (x *MintTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MintTokensRequest.ProtoReflect.Descriptor instead.
+func (*MintTokensRequest) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MintTokensRequest) GetPassphrase() string {
+	if x != nil {
+		return x.Passphrase
+	}
+	return ""
+}
+
+func (x *MintTokensRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *MintTokensRequest) GetMode() JoinMode {
+	if x != nil {
+		return x.Mode
+	}
+	return JoinMode_JOIN_MODE_UNSPECIFIED
+}
+
+// Tokens of a participant for the Agora SDKs
+type Credentials struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rtc string  `protobuf:"bytes,1,opt,name=rtc,proto3" json:"rtc,omitempty"`
+	Rtm *string `protobuf:"bytes,2,opt,name=rtm,proto3,oneof" json:"rtm,omitempty"`
+	Uid int32   `protobuf:"varint,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	// The area the SDK has to be restricted to with setArea
+	Area AgoraArea `protobuf:"varint,4,opt,name=area,proto3,enum=appbuilder.v1.AgoraArea" json:"area,omitempty"`
+}
+
+func (x *Credentials) Reset() {
+	*x = Credentials{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Credentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credentials) ProtoMessage() {}
+
+func (x *Credentials) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credentials.ProtoReflect.Descriptor instead.
+func (*Credentials) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Credentials) GetRtc() string {
+	if x != nil {
+		return x.Rtc
+	}
+	return ""
+}
+
+func (x *Credentials) GetRtm() string {
+	if x != nil && x.Rtm != nil {
+		return *x.Rtm
+	}
+	return ""
+}
+
+func (x *Credentials) GetUid() int32 {
+	if x != nil {
+		return x.Uid
+	}
+	return 0
+}
+
+func (x *Credentials) GetArea() AgoraArea {
+	if x != nil {
+		return x.Area
+	}
+	return AgoraArea_AGORA_AREA_UNSPECIFIED
+}
+
+type MintTokensResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channel    string         `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Title      string         `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	IsHost     bool           `protobuf:"varint,3,opt,name=is_host,json=isHost,proto3" json:"is_host,omitempty"`
+	Role       PassphraseType `protobuf:"varint,4,opt,name=role,proto3,enum=appbuilder.v1.PassphraseType" json:"role,omitempty"`
+	CanPublish bool           `protobuf:"varint,5,opt,name=can_publish,json=canPublish,proto3" json:"can_publish,omitempty"`
+	// PENDING while the participant waits in the lobby, in which case no credentials are returned yet
+	Status      SessionStatus `protobuf:"varint,6,opt,name=status,proto3,enum=appbuilder.v1.SessionStatus" json:"status,omitempty"`
+	LobbyId     *string       `protobuf:"bytes,7,opt,name=lobby_id,json=lobbyId,proto3,oneof" json:"lobby_id,omitempty"`
+	Secret      string        `protobuf:"bytes,8,opt,name=secret,proto3" json:"secret,omitempty"`
+	MainUser    *Credentials  `protobuf:"bytes,9,opt,name=main_user,json=mainUser,proto3" json:"main_user,omitempty"`
+	ScreenShare *Credentials  `protobuf:"bytes,10,opt,name=screen_share,json=screenShare,proto3" json:"screen_share,omitempty"`
+	// App ID of the Agora project the credentials are for
+	AppId *string `protobuf:"bytes,11,opt,name=app_id,json=appId,proto3,oneof" json:"app_id,omitempty"`
+}
+
+func (x *MintTokensResponse) Reset() {
+	*x = MintTokensResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MintTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MintTokensResponse) ProtoMessage() {}
+
+func (x *MintTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MintTokensResponse.ProtoReflect.Descriptor instead.
+func (*MintTokensResponse) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MintTokensResponse) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *MintTokensResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *MintTokensResponse) GetIsHost() bool {
+	if x != nil {
+		return x.IsHost
+	}
+	return false
+}
+
+func (x *MintTokensResponse) GetRole() PassphraseType {
+	if x != nil {
+		return x.Role
+	}
+	return PassphraseType_PASSPHRASE_TYPE_UNSPECIFIED
+}
+
+func (x *MintTokensResponse) GetCanPublish() bool {
+	if x != nil {
+		return x.CanPublish
+	}
+	return false
+}
+
+func (x *MintTokensResponse) GetStatus() SessionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return SessionStatus_SESSION_STATUS_UNSPECIFIED
+}
+
+func (x *MintTokensResponse) GetLobbyId() string {
+	if x != nil && x.LobbyId != nil {
+		return *x.LobbyId
+	}
+	return ""
+}
+
+func (x *MintTokensResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *MintTokensResponse) GetMainUser() *Credentials {
+	if x != nil {
+		return x.MainUser
+	}
+	return nil
+}
+
+func (x *MintTokensResponse) GetScreenShare() *Credentials {
+	if x != nil {
+		return x.ScreenShare
+	}
+	return nil
+}
+
+func (x *MintTokensResponse) GetAppId() string {
+	if x != nil && x.AppId != nil {
+		return *x.AppId
+	}
+	return ""
+}
+
+type RecordingQuality struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height          *int32          `protobuf:"varint,1,opt,name=height,proto3,oneof" json:"height,omitempty"`
+	Width           *int32          `protobuf:"varint,2,opt,name=width,proto3,oneof" json:"width,omitempty"`
+	Bitrate         *int32          `protobuf:"varint,3,opt,name=bitrate,proto3,oneof" json:"bitrate,omitempty"`
+	Fps             *int32          `protobuf:"varint,4,opt,name=fps,proto3,oneof" json:"fps,omitempty"`
+	Layout          RecordingLayout `protobuf:"varint,5,opt,name=layout,proto3,enum=appbuilder.v1.RecordingLayout" json:"layout,omitempty"`
+	BackgroundColor *string         `protobuf:"bytes,6,opt,name=background_color,json=backgroundColor,proto3,oneof" json:"background_color,omitempty"`
+}
+
+func (x *RecordingQuality) Reset() {
+	*x = RecordingQuality{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordingQuality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordingQuality) ProtoMessage() {}
+
+func (x *RecordingQuality) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordingQuality.ProtoReflect.Descriptor instead.
+func (*RecordingQuality) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RecordingQuality) GetHeight() int32 {
+	if x != nil && x.Height != nil {
+		return *x.Height
+	}
+	return 0
+}
+
+func (x *RecordingQuality) GetWidth() int32 {
+	if x != nil && x.Width != nil {
+		return *x.Width
+	}
+	return 0
+}
+
+func (x *RecordingQuality) GetBitrate() int32 {
+	if x != nil && x.Bitrate != nil {
+		return *x.Bitrate
+	}
+	return 0
+}
+
+func (x *RecordingQuality) GetFps() int32 {
+	if x != nil && x.Fps != nil {
+		return *x.Fps
+	}
+	return 0
+}
+
+func (x *RecordingQuality) GetLayout() RecordingLayout {
+	if x != nil {
+		return x.Layout
+	}
+	return RecordingLayout_RECORDING_LAYOUT_UNSPECIFIED
+}
+
+func (x *RecordingQuality) GetBackgroundColor() string {
+	if x != nil && x.BackgroundColor != nil {
+		return *x.BackgroundColor
+	}
+	return ""
+}
+
+type StartRecordingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Passphrase string  `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Secret     *string `protobuf:"bytes,2,opt,name=secret,proto3,oneof" json:"secret,omitempty"`
+	// Defaults to the recording quality of the channel
+	RecordingQuality *RecordingQuality `protobuf:"bytes,3,opt,name=recording_quality,json=recordingQuality,proto3" json:"recording_quality,omitempty"`
+}
+
+func (x *StartRecordingRequest) Reset() {
+	*x = StartRecordingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartRecordingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRecordingRequest) ProtoMessage() {}
+
+func (x *StartRecordingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRecordingRequest.ProtoReflect.Descriptor instead.
+func (*StartRecordingRequest) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StartRecordingRequest) GetPassphrase() string {
+	if x != nil {
+		return x.Passphrase
+	}
+	return ""
+}
+
+func (x *StartRecordingRequest) GetSecret() string {
+	if x != nil && x.Secret != nil {
+		return *x.Secret
+	}
+	return ""
+}
+
+func (x *StartRecordingRequest) GetRecordingQuality() *RecordingQuality {
+	if x != nil {
+		return x.RecordingQuality
+	}
+	return nil
+}
+
+type StartRecordingResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Identifies the recording to Agora
+	Sid string `protobuf:"bytes,1,opt,name=sid,proto3" json:"sid,omitempty"`
+}
+
+func (x *StartRecordingResponse) Reset() {
+	*x = StartRecordingResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StartRecordingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRecordingResponse) ProtoMessage() {}
+
+func (x *StartRecordingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRecordingResponse.ProtoReflect.Descriptor instead.
+func (*StartRecordingResponse) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StartRecordingResponse) GetSid() string {
+	if x != nil {
+		return x.Sid
+	}
+	return ""
+}
+
+type StopRecordingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Passphrase string `protobuf:"bytes,1,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+}
+
+func (x *StopRecordingRequest) Reset() {
+	*x = StopRecordingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopRecordingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRecordingRequest) ProtoMessage() {}
+
+func (x *StopRecordingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRecordingRequest.ProtoReflect.Descriptor instead.
+func (*StopRecordingRequest) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StopRecordingRequest) GetPassphrase() string {
+	if x != nil {
+		return x.Passphrase
+	}
+	return ""
+}
+
+type StopRecordingResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StopRecordingResponse) Reset() {
+	*x = StopRecordingResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopRecordingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRecordingResponse) ProtoMessage() {}
+
+func (x *StopRecordingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_appbuilder_v1_appbuilder_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRecordingResponse.ProtoReflect.Descriptor instead.
+func (*StopRecordingResponse) Descriptor() ([]byte, []int) {
+	return file_appbuilder_v1_appbuilder_proto_rawDescGZIP(), []int{12}
+}
+
+var File_appbuilder_v1_appbuilder_proto protoreflect.FileDescriptor
+
+var file_appbuilder_v1_appbuilder_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f,
+	0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0d, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xba, 0x01, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f,
+	0x76, 0x69, 0x64, 0x65, 0x72, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x4b, 0x65, 0x79, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x22, 0xfa, 0x06,
+	0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x55, 0x72, 0x6c, 0x12, 0x1f, 0x0a,
+	0x0b, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x70, 0x73, 0x74, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0a, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x50, 0x73, 0x74, 0x6e, 0x12, 0x37,
+	0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x52, 0x07,
+	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x12, 0x26, 0x0a, 0x0c, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52,
+	0x0b, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x78, 0x70, 0x69, 0x72, 0x79, 0x88, 0x01, 0x01, 0x12,
+	0x3c, 0x0a, 0x18, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x65, 0x72, 0x73,
+	0x5f, 0x74, 0x6f, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x01, 0x52, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x56, 0x69, 0x65, 0x77, 0x65, 0x72,
+	0x73, 0x54, 0x6f, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a,
+	0x12, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x70, 0x68, 0x72,
+	0x61, 0x73, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x10, 0x63, 0x75, 0x73,
+	0x74, 0x6f, 0x6d, 0x48, 0x6f, 0x73, 0x74, 0x50, 0x68, 0x72, 0x61, 0x73, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x31, 0x0a, 0x12, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x5f,
+	0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x10,
+	0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x56, 0x69, 0x65, 0x77, 0x50, 0x68, 0x72, 0x61, 0x73, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x37, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x73, 0x74, 0x61, 0x72, 0x74, 0x73, 0x41, 0x74, 0x12, 0x33, 0x0a, 0x07,
+	0x65, 0x6e, 0x64, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x06, 0x65, 0x6e, 0x64, 0x73, 0x41,
+	0x74, 0x12, 0x2e, 0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74,
+	0x69, 0x6e, 0x67, 0x5f, 0x72, 0x6f, 0x6f, 0x6d, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x6f, 0x6f,
+	0x6d, 0x12, 0x2e, 0x0a, 0x10, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69,
+	0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x48, 0x04, 0x52, 0x0f, 0x6d,
+	0x61, 0x78, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x1d, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x05, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x2b, 0x0a, 0x11, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x77, 0x68, 0x69, 0x74, 0x65,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x65, 0x6e, 0x61,
+	0x62, 0x6c, 0x65, 0x57, 0x68, 0x69, 0x74, 0x65, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x12, 0x2c, 0x0a,
+	0x0f, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x48, 0x06, 0x52, 0x0e, 0x6f, 0x72, 0x67, 0x61, 0x6e, 0x69,
+	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x04, 0x61,
+	0x72, 0x65, 0x61, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x70, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x67, 0x6f, 0x72, 0x61, 0x41,
+	0x72, 0x65, 0x61, 0x52, 0x04, 0x61, 0x72, 0x65, 0x61, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x42, 0x1b, 0x0a, 0x19, 0x5f, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x65, 0x72, 0x73, 0x5f, 0x74, 0x6f, 0x5f,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x42, 0x15, 0x0a, 0x13, 0x5f, 0x63, 0x75, 0x73, 0x74,
+	0x6f, 0x6d, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x42, 0x15,
+	0x0a, 0x13, 0x5f, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x70,
+	0x68, 0x72, 0x61, 0x73, 0x65, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x6f, 0x72, 0x67, 0x61, 0x6e,
+	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x22, 0x32, 0x0a, 0x04, 0x50, 0x73,
+	0x74, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x74,
+	0x6d, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x74, 0x6d, 0x66, 0x22, 0x29,
+	0x0a, 0x03, 0x53, 0x69, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x69, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x70, 0x69, 0x6e, 0x22, 0x81, 0x02, 0x0a, 0x15, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x12, 0x2c, 0x0a, 0x0f, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x70, 0x61, 0x73, 0x73,
+	0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0e,
+	0x68, 0x6f, 0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x27, 0x0a, 0x0f, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68,
+	0x72, 0x61, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x76, 0x69, 0x65, 0x77,
+	0x50, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x70, 0x73,
+	0x74, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75,
+	0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x73, 0x74, 0x6e, 0x52, 0x04, 0x70,
+	0x73, 0x74, 0x6e, 0x12, 0x24, 0x0a, 0x03, 0x73, 0x69, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x69, 0x70, 0x52, 0x03, 0x73, 0x69, 0x70, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x68, 0x6f,
+	0x73, 0x74, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x22, 0x82, 0x01,
+	0x0a, 0x11, 0x4d, 0x69, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72,
+	0x61, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x70,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x69, 0x6e, 0x4d,
+	0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x22, 0x7e, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x72, 0x74, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x72, 0x74, 0x63, 0x12, 0x15, 0x0a, 0x03, 0x72, 0x74, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x03, 0x72, 0x74, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x2c, 0x0a, 0x04,
+	0x61, 0x72, 0x65, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x70,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x67, 0x6f, 0x72, 0x61,
+	0x41, 0x72, 0x65, 0x61, 0x52, 0x04, 0x61, 0x72, 0x65, 0x61, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x72,
+	0x74, 0x6d, 0x22, 0xcb, 0x03, 0x0a, 0x12, 0x4d, 0x69, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f,
+	0x68, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x48, 0x6f,
+	0x73, 0x74, 0x12, 0x31, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x1d, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x6e, 0x5f, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x63, 0x61, 0x6e, 0x50,
+	0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x12, 0x34, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x08,
+	0x6c, 0x6f, 0x62, 0x62, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x07, 0x6c, 0x6f, 0x62, 0x62, 0x79, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65,
+	0x63, 0x72, 0x65, 0x74, 0x12, 0x37, 0x0a, 0x09, 0x6d, 0x61, 0x69, 0x6e, 0x5f, 0x75, 0x73, 0x65,
+	0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69,
+	0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x61, 0x6c, 0x73, 0x52, 0x08, 0x6d, 0x61, 0x69, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x12, 0x3d, 0x0a,
+	0x0c, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x73, 0x52,
+	0x0b, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x06,
+	0x61, 0x70, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x05,
+	0x61, 0x70, 0x70, 0x49, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6c, 0x6f, 0x62,
+	0x62, 0x79, 0x5f, 0x69, 0x64, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x61, 0x70, 0x70, 0x5f, 0x69, 0x64,
+	0x22, 0xa6, 0x02, 0x0a, 0x10, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x51, 0x75,
+	0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1b, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x88,
+	0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x48, 0x01, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a,
+	0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x02,
+	0x52, 0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03,
+	0x66, 0x70, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x03, 0x66, 0x70, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x36, 0x0a, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1e, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x52, 0x06, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x2e, 0x0a, 0x10, 0x62,
+	0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x04, 0x52, 0x0f, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x43, 0x6f, 0x6c, 0x6f, 0x72, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x77, 0x69, 0x64, 0x74, 0x68,
+	0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x42, 0x06, 0x0a, 0x04,
+	0x5f, 0x66, 0x70, 0x73, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x5f, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x22, 0xad, 0x01, 0x0a, 0x15, 0x53, 0x74,
+	0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72,
+	0x61, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x88, 0x01, 0x01,
+	0x12, 0x4c, 0x0a, 0x11, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x71, 0x75,
+	0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x70,
+	0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x10, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x42, 0x09,
+	0x0a, 0x07, 0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x22, 0x2a, 0x0a, 0x16, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x73, 0x69, 0x64, 0x22, 0x36, 0x0a, 0x14, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a,
+	0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x70, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x22, 0x17, 0x0a,
+	0x15, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0xca, 0x01, 0x0a, 0x09, 0x41, 0x67, 0x6f, 0x72, 0x61,
+	0x41, 0x72, 0x65, 0x61, 0x12, 0x1a, 0x0a, 0x16, 0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41, 0x52,
+	0x45, 0x41, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x15, 0x0a, 0x11, 0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f, 0x47,
+	0x4c, 0x4f, 0x42, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x41, 0x47, 0x4f, 0x52, 0x41,
+	0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f, 0x4e, 0x4f, 0x52, 0x54, 0x48, 0x5f, 0x41, 0x4d, 0x45, 0x52,
+	0x49, 0x43, 0x41, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41,
+	0x52, 0x45, 0x41, 0x5f, 0x45, 0x55, 0x52, 0x4f, 0x50, 0x45, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f,
+	0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f, 0x41, 0x53, 0x49, 0x41, 0x10,
+	0x04, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f,
+	0x4a, 0x41, 0x50, 0x41, 0x4e, 0x10, 0x05, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x47, 0x4f, 0x52, 0x41,
+	0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f, 0x49, 0x4e, 0x44, 0x49, 0x41, 0x10, 0x06, 0x12, 0x14, 0x0a,
+	0x10, 0x41, 0x47, 0x4f, 0x52, 0x41, 0x5f, 0x41, 0x52, 0x45, 0x41, 0x5f, 0x43, 0x48, 0x49, 0x4e,
+	0x41, 0x10, 0x07, 0x2a, 0xa1, 0x01, 0x0a, 0x0f, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50,
+	0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x1c, 0x53, 0x54, 0x4f, 0x52, 0x41,
+	0x47, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x54, 0x4f,
+	0x52, 0x41, 0x47, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x41, 0x57,
+	0x53, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x50,
+	0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x47, 0x43, 0x53, 0x10, 0x02, 0x12, 0x1a, 0x0a,
+	0x16, 0x53, 0x54, 0x4f, 0x52, 0x41, 0x47, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45,
+	0x52, 0x5f, 0x41, 0x5a, 0x55, 0x52, 0x45, 0x10, 0x03, 0x12, 0x1c, 0x0a, 0x18, 0x53, 0x54, 0x4f,
+	0x52, 0x41, 0x47, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x41, 0x4c,
+	0x49, 0x42, 0x41, 0x42, 0x41, 0x10, 0x04, 0x2a, 0x73, 0x0a, 0x08, 0x4a, 0x6f, 0x69, 0x6e, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x4a, 0x4f, 0x49, 0x4e, 0x5f, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12,
+	0x0a, 0x0e, 0x4a, 0x4f, 0x49, 0x4e, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x46, 0x55, 0x4c, 0x4c,
+	0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x4a, 0x4f, 0x49, 0x4e, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f,
+	0x41, 0x55, 0x44, 0x49, 0x4f, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a,
+	0x4a, 0x4f, 0x49, 0x4e, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x43, 0x52, 0x45, 0x45, 0x4e,
+	0x53, 0x48, 0x41, 0x52, 0x45, 0x5f, 0x4f, 0x4e, 0x4c, 0x59, 0x10, 0x03, 0x2a, 0x83, 0x01, 0x0a,
+	0x0e, 0x50, 0x61, 0x73, 0x73, 0x70, 0x68, 0x72, 0x61, 0x73, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x1f, 0x0a, 0x1b, 0x50, 0x41, 0x53, 0x53, 0x50, 0x48, 0x52, 0x41, 0x53, 0x45, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x18, 0x0a, 0x14, 0x50, 0x41, 0x53, 0x53, 0x50, 0x48, 0x52, 0x41, 0x53, 0x45, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x48, 0x4f, 0x53, 0x54, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41,
+	0x53, 0x53, 0x50, 0x48, 0x52, 0x41, 0x53, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x4f,
+	0x48, 0x4f, 0x53, 0x54, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41, 0x53, 0x53, 0x50, 0x48,
+	0x52, 0x41, 0x53, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56, 0x49, 0x45, 0x57, 0x45, 0x52,
+	0x10, 0x03, 0x2a, 0x81, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x1a, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12,
+	0x1a, 0x0a, 0x16, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x53,
+	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x45,
+	0x4e, 0x49, 0x45, 0x44, 0x10, 0x03, 0x2a, 0x8d, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x67, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x20, 0x0a, 0x1c, 0x52, 0x45,
+	0x43, 0x4f, 0x52, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x41, 0x59, 0x4f, 0x55, 0x54, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1d, 0x0a, 0x19,
+	0x52, 0x45, 0x43, 0x4f, 0x52, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x41, 0x59, 0x4f, 0x55, 0x54,
+	0x5f, 0x46, 0x4c, 0x4f, 0x41, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x52,
+	0x45, 0x43, 0x4f, 0x52, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x41, 0x59, 0x4f, 0x55, 0x54, 0x5f,
+	0x47, 0x52, 0x49, 0x44, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44,
+	0x49, 0x4e, 0x47, 0x5f, 0x4c, 0x41, 0x59, 0x4f, 0x55, 0x54, 0x5f, 0x53, 0x50, 0x4f, 0x54, 0x4c,
+	0x49, 0x47, 0x48, 0x54, 0x10, 0x03, 0x32, 0x6c, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5a, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x23, 0x2e, 0x61, 0x70, 0x70, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24,
+	0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x32, 0x61, 0x0a, 0x0c, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x0a, 0x4d, 0x69, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x73, 0x12, 0x20, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x69, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xcd, 0x01, 0x0a, 0x10, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5d, 0x0a, 0x0e,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x24,
+	0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0d, 0x53,
+	0x74, 0x6f, 0x70, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x23, 0x2e, 0x61,
+	0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x6f,
+	0x70, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x24, 0x2e, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x48, 0x5a, 0x46, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x61, 0x6d, 0x79, 0x61, 0x6b, 0x2d, 0x6a, 0x61, 0x69,
+	0x6e, 0x2f, 0x61, 0x67, 0x6f, 0x72, 0x61, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x65, 0x72, 0x76, 0x31, 0x3b, 0x61, 0x70, 0x70, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x76,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_appbuilder_v1_appbuilder_proto_rawDescOnce sync.Once
+	file_appbuilder_v1_appbuilder_proto_rawDescData = file_appbuilder_v1_appbuilder_proto_rawDesc
+)
+
+func file_appbuilder_v1_appbuilder_proto_rawDescGZIP() []byte {
+	file_appbuilder_v1_appbuilder_proto_rawDescOnce.Do(func() {
+		file_appbuilder_v1_appbuilder_proto_rawDescData = protoimpl.X.CompressGZIP(file_appbuilder_v1_appbuilder_proto_rawDescData)
+	})
+	return file_appbuilder_v1_appbuilder_proto_rawDescData
+}
+
+var file_appbuilder_v1_appbuilder_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_appbuilder_v1_appbuilder_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_appbuilder_v1_appbuilder_proto_goTypes = []interface{}{
+	(AgoraArea)(0),                 // 0: appbuilder.v1.AgoraArea
+	(StorageProvider)(0),           // 1: appbuilder.v1.StorageProvider
+	(JoinMode)(0),                  // 2: appbuilder.v1.JoinMode
+	(PassphraseType)(0),            // 3: appbuilder.v1.PassphraseType
+	(SessionStatus)(0),             // 4: appbuilder.v1.SessionStatus
+	(RecordingLayout)(0),           // 5: appbuilder.v1.RecordingLayout
+	(*ChannelStorage)(nil),         // 6: appbuilder.v1.ChannelStorage
+	(*CreateChannelRequest)(nil),   // 7: appbuilder.v1.CreateChannelRequest
+	(*Pstn)(nil),                   // 8: appbuilder.v1.Pstn
+	(*Sip)(nil),                    // 9: appbuilder.v1.Sip
+	(*CreateChannelResponse)(nil),  // 10: appbuilder.v1.CreateChannelResponse
+	(*MintTokensRequest)(nil),      // 11: appbuilder.v1.MintTokensRequest
+	(*Credentials)(nil),            // 12: appbuilder.v1.Credentials
+	(*MintTokensResponse)(nil),     // 13: appbuilder.v1.MintTokensResponse
+	(*RecordingQuality)(nil),       // 14: appbuilder.v1.RecordingQuality
+	(*StartRecordingRequest)(nil),  // 15: appbuilder.v1.StartRecordingRequest
+	(*StartRecordingResponse)(nil), // 16: appbuilder.v1.StartRecordingResponse
+	(*StopRecordingRequest)(nil),   // 17: appbuilder.v1.StopRecordingRequest
+	(*StopRecordingResponse)(nil),  // 18: appbuilder.v1.StopRecordingResponse
+	(*timestamppb.Timestamp)(nil),  // 19: google.protobuf.Timestamp
+}
+var file_appbuilder_v1_appbuilder_proto_depIdxs = []int32{
+	1,  // 0: appbuilder.v1.ChannelStorage.provider:type_name -> appbuilder.v1.StorageProvider
+	6,  // 1: appbuilder.v1.CreateChannelRequest.storage:type_name -> appbuilder.v1.ChannelStorage
+	19, // 2: appbuilder.v1.CreateChannelRequest.starts_at:type_name -> google.protobuf.Timestamp
+	19, // 3: appbuilder.v1.CreateChannelRequest.ends_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: appbuilder.v1.CreateChannelRequest.area:type_name -> appbuilder.v1.AgoraArea
+	8,  // 5: appbuilder.v1.CreateChannelResponse.pstn:type_name -> appbuilder.v1.Pstn
+	9,  // 6: appbuilder.v1.CreateChannelResponse.sip:type_name -> appbuilder.v1.Sip
+	2,  // 7: appbuilder.v1.MintTokensRequest.mode:type_name -> appbuilder.v1.JoinMode
+	0,  // 8: appbuilder.v1.Credentials.area:type_name -> appbuilder.v1.AgoraArea
+	3,  // 9: appbuilder.v1.MintTokensResponse.role:type_name -> appbuilder.v1.PassphraseType
+	4,  // 10: appbuilder.v1.MintTokensResponse.status:type_name -> appbuilder.v1.SessionStatus
+	12, // 11: appbuilder.v1.MintTokensResponse.main_user:type_name -> appbuilder.v1.Credentials
+	12, // 12: appbuilder.v1.MintTokensResponse.screen_share:type_name -> appbuilder.v1.Credentials
+	5,  // 13: appbuilder.v1.RecordingQuality.layout:type_name -> appbuilder.v1.RecordingLayout
+	14, // 14: appbuilder.v1.StartRecordingRequest.recording_quality:type_name -> appbuilder.v1.RecordingQuality
+	7,  // 15: appbuilder.v1.ChannelService.CreateChannel:input_type -> appbuilder.v1.CreateChannelRequest
+	11, // 16: appbuilder.v1.TokenService.MintTokens:input_type -> appbuilder.v1.MintTokensRequest
+	15, // 17: appbuilder.v1.RecordingService.StartRecording:input_type -> appbuilder.v1.StartRecordingRequest
+	17, // 18: appbuilder.v1.RecordingService.StopRecording:input_type -> appbuilder.v1.StopRecordingRequest
+	10, // 19: appbuilder.v1.ChannelService.CreateChannel:output_type -> appbuilder.v1.CreateChannelResponse
+	13, // 20: appbuilder.v1.TokenService.MintTokens:output_type -> appbuilder.v1.MintTokensResponse
+	16, // 21: appbuilder.v1.RecordingService.StartRecording:output_type -> appbuilder.v1.StartRecordingResponse
+	18, // 22: appbuilder.v1.RecordingService.StopRecording:output_type -> appbuilder.v1.StopRecordingResponse
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_appbuilder_v1_appbuilder_proto_init() }
+func file_appbuilder_v1_appbuilder_proto_init() {
+	if File_appbuilder_v1_appbuilder_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_appbuilder_v1_appbuilder_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChannelStorage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateChannelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Pstn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Sip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateChannelResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MintTokensRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Credentials); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MintTokensResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordingQuality); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartRecordingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartRecordingResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopRecordingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_appbuilder_v1_appbuilder_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopRecordingResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[8].OneofWrappers = []interface{}{}
+	file_appbuilder_v1_appbuilder_proto_msgTypes[9].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_appbuilder_v1_appbuilder_proto_rawDesc,
+			NumEnums:      6,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_appbuilder_v1_appbuilder_proto_goTypes,
+		DependencyIndexes: file_appbuilder_v1_appbuilder_proto_depIdxs,
+		EnumInfos:         file_appbuilder_v1_appbuilder_proto_enumTypes,
+		MessageInfos:      file_appbuilder_v1_appbuilder_proto_msgTypes,
+	}.Build()
+	File_appbuilder_v1_appbuilder_proto = out.File
+	file_appbuilder_v1_appbuilder_proto_rawDesc = nil
+	file_appbuilder_v1_appbuilder_proto_goTypes = nil
+	file_appbuilder_v1_appbuilder_proto_depIdxs = nil
+}
@@ -0,0 +1,123 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/rpc/appbuilderv1"
+)
+
+// CreateChannel creates a channel with the createChannel mutation
+func (s *Server) CreateChannel(ctx context.Context, req *appbuilderv1.CreateChannelRequest) (*appbuilderv1.CreateChannelResponse, error) {
+	var storage *models.ChannelStorageInput
+	if req.Storage != nil {
+		region := int(req.Storage.Region)
+		storage = &models.ChannelStorageInput{
+			Provider:  models.StorageProvider(enumName(req.Storage.Provider.String(), "STORAGE_PROVIDER_")),
+			Region:    &region,
+			Bucket:    req.Storage.Bucket,
+			AccessKey: req.Storage.AccessKey,
+			SecretKey: req.Storage.SecretKey,
+		}
+	}
+
+	var startsAt, endsAt *time.Time
+	if req.StartsAt != nil {
+		value := req.StartsAt.AsTime()
+		startsAt = &value
+	}
+	if req.EndsAt != nil {
+		value := req.EndsAt.AsTime()
+		endsAt = &value
+	}
+
+	var area *models.AgoraArea
+	if name := enumName(req.Area.String(), "AGORA_AREA_"); name != "" {
+		value := models.AgoraArea(name)
+		area = &value
+	}
+
+	var share *models.ShareResponse
+	err := s.Audit.Run(ctx, "createChannel", arguments(req), func(ctx context.Context) error {
+		var err error
+		share, err = s.Resolver.Mutation().CreateChannel(ctx, req.Title, req.BackendUrl, &req.EnablePstn, storage, intPointer(req.TokenExpiry),
+			req.AllowViewersToPublish, req.CustomHostPhrase, req.CustomViewPhrase, startsAt, endsAt, &req.EnableWaitingRoom,
+			intPointer(req.MaxParticipants), req.Country, &req.EnableWhiteboard, req.OrganizationId, area)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &appbuilderv1.CreateChannelResponse{
+		Channel: share.Channel,
+		Title:   share.Title,
+	}
+
+	if share.Passphrase != nil {
+		resp.HostPassphrase = share.Passphrase.Host
+		resp.ViewPassphrase = share.Passphrase.View
+	}
+
+	if share.Pstn != nil {
+		resp.Pstn = &appbuilderv1.Pstn{Number: share.Pstn.Number, Dtmf: share.Pstn.Dtmf}
+	}
+
+	if share.Sip != nil {
+		resp.Sip = &appbuilderv1.Sip{Uri: share.Sip.URI, Pin: share.Sip.Pin}
+	}
+
+	return resp, nil
+}
+
+// MintTokens joins a channel with the joinChannel query
+func (s *Server) MintTokens(ctx context.Context, req *appbuilderv1.MintTokensRequest) (*appbuilderv1.MintTokensResponse, error) {
+	mode := models.JoinModeFull
+	if name := enumName(req.Mode.String(), "JOIN_MODE_"); name != "" {
+		mode = models.JoinMode(name)
+	}
+
+	session, err := s.Resolver.Query().JoinChannel(ctx, req.Passphrase, req.Name, &mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appbuilderv1.MintTokensResponse{
+		Channel:     session.Channel,
+		Title:       session.Title,
+		IsHost:      session.IsHost,
+		Role:        appbuilderv1.PassphraseType(appbuilderv1.PassphraseType_value["PASSPHRASE_TYPE_"+session.Role.String()]),
+		CanPublish:  session.CanPublish,
+		Status:      appbuilderv1.SessionStatus(appbuilderv1.SessionStatus_value["SESSION_STATUS_"+session.Status.String()]),
+		LobbyId:     session.LobbyID,
+		Secret:      session.Secret,
+		MainUser:    credentials(session.MainUser),
+		ScreenShare: credentials(session.ScreenShare),
+		AppId:       session.AppID,
+	}, nil
+}
+
+// credentials converts the credentials of a session, which are nil while a participant waits in the lobby
+func credentials(user *models.UserCredentials) *appbuilderv1.Credentials {
+	if user == nil {
+		return nil
+	}
+
+	return &appbuilderv1.Credentials{
+		Rtc:  user.Rtc,
+		Rtm:  user.Rtm,
+		Uid:  int32(user.UID),
+		Area: appbuilderv1.AgoraArea(appbuilderv1.AgoraArea_value["AGORA_AREA_"+user.Area.String()]),
+	}
+}
@@ -0,0 +1,62 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package rpc
+
+import (
+	"context"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/rpc/appbuilderv1"
+)
+
+// StartRecording starts a recording with the startRecordingSession mutation
+func (s *Server) StartRecording(ctx context.Context, req *appbuilderv1.StartRecordingRequest) (*appbuilderv1.StartRecordingResponse, error) {
+	var quality *models.RecordingQualityInput
+	if req.RecordingQuality != nil {
+		quality = &models.RecordingQualityInput{
+			Height:          intPointer(req.RecordingQuality.Height),
+			Width:           intPointer(req.RecordingQuality.Width),
+			Bitrate:         intPointer(req.RecordingQuality.Bitrate),
+			Fps:             intPointer(req.RecordingQuality.Fps),
+			BackgroundColor: req.RecordingQuality.BackgroundColor,
+		}
+
+		if name := enumName(req.RecordingQuality.Layout.String(), "RECORDING_LAYOUT_"); name != "" {
+			layout := models.RecordingLayout(name)
+			quality.Layout = &layout
+		}
+	}
+
+	var sid string
+	err := s.Audit.Run(ctx, "startRecordingSession", arguments(req), func(ctx context.Context) error {
+		var err error
+		sid, err = s.Resolver.Mutation().StartRecordingSession(ctx, req.Passphrase, req.Secret, quality)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &appbuilderv1.StartRecordingResponse{Sid: sid}, nil
+}
+
+// StopRecording stops a recording with the stopRecordingSession mutation
+func (s *Server) StopRecording(ctx context.Context, req *appbuilderv1.StopRecordingRequest) (*appbuilderv1.StopRecordingResponse, error) {
+	err := s.Audit.Run(ctx, "stopRecordingSession", arguments(req), func(ctx context.Context) error {
+		_, err := s.Resolver.Mutation().StopRecordingSession(ctx, req.Passphrase)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &appbuilderv1.StopRecordingResponse{}, nil
+}
@@ -0,0 +1,156 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+// Package rpc serves the channel, token and recording operations over gRPC for internal services. Like the REST API
+// it calls the same resolvers as the GraphQL API, so that the operations behave the same way
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/samyak-jain/agora_backend --go-grpc_out=../.. --go-grpc_opt=module=github.com/samyak-jain/agora_backend appbuilder/v1/appbuilder.proto
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/apierror"
+	"github.com/samyak-jain/agora_backend/pkg/graph"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/rpc/appbuilderv1"
+	"github.com/samyak-jain/agora_backend/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Server implements the gRPC services
+type Server struct {
+	appbuilderv1.UnimplementedChannelServiceServer
+	appbuilderv1.UnimplementedTokenServiceServer
+	appbuilderv1.UnimplementedRecordingServiceServer
+
+	Resolver *graph.Resolver
+	Logger   *utils.Logger
+	// Audit records the mutations made through gRPC along with those made through GraphQL
+	Audit middleware.Audit
+}
+
+// GRPCServer creates a gRPC server with the services of s, which only accepts calls with a valid API key
+func (s *Server) GRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.authenticate))
+	appbuilderv1.RegisterChannelServiceServer(server, s)
+	appbuilderv1.RegisterTokenServiceServer(server, s)
+	appbuilderv1.RegisterRecordingServiceServer(server, s)
+
+	return server
+}
+
+// authenticate authenticates calls with the API key in their metadata and presents the errors they fail with
+func (s *Server) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	key := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(strings.ToLower(middleware.APIKeyHeader)); len(keys) > 0 {
+			key = keys[0]
+		}
+	}
+
+	if key != "" {
+		ctx = middleware.AuthenticateAPIKey(ctx, s.Resolver.Repos, s.Logger, key)
+	}
+
+	if _, err := middleware.GetAPIKeyFromContext(ctx); err != nil {
+		return nil, status.Error(codes.Unauthenticated, "A valid API key is required")
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		s.Logger.Debug().Err(err).Str("method", info.FullMethod).Msg("gRPC call failed")
+		return nil, statusError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+// statusCodes are the gRPC codes of the codes of errors. Errors without a code are messages for the caller
+var statusCodes = map[apierror.Code]codes.Code{
+	apierror.CodeInternal:               codes.Internal,
+	apierror.CodeBadRequest:             codes.InvalidArgument,
+	apierror.CodeTokenExpired:           codes.Unauthenticated,
+	apierror.CodeChannelNotFound:        codes.NotFound,
+	apierror.CodeNotHost:                codes.PermissionDenied,
+	apierror.CodeMeetingNotStarted:      codes.FailedPrecondition,
+	apierror.CodeMeetingEnded:           codes.FailedPrecondition,
+	apierror.CodeChannelLocked:          codes.PermissionDenied,
+	apierror.CodeChannelFull:            codes.ResourceExhausted,
+	apierror.CodeBanned:                 codes.PermissionDenied,
+	apierror.CodePassphraseTaken:        codes.AlreadyExists,
+	apierror.CodeRecordingAlreadyActive: codes.AlreadyExists,
+	apierror.CodeRecordingNotActive:     codes.FailedPrecondition,
+	apierror.CodeUnavailable:            codes.Unavailable,
+	apierror.CodeRateLimited:            codes.ResourceExhausted,
+	apierror.CodeForbidden:              codes.PermissionDenied,
+	apierror.CodeInvalidCredentials:     codes.Unauthenticated,
+	apierror.CodeEmailNotVerified:       codes.PermissionDenied,
+	apierror.CodeTwoFactorRequired:      codes.PermissionDenied,
+	apierror.CodeQuotaExceeded:          codes.ResourceExhausted,
+	apierror.CodeFeatureDisabled:        codes.PermissionDenied,
+	apierror.CodeServiceDegraded:        codes.Unavailable,
+	apierror.CodeAgoraError:             codes.Unavailable,
+}
+
+// statusError converts an error of a resolver to a gRPC status, and sends its code in the error-code trailer
+func statusError(ctx context.Context, err error) error {
+	code := apierror.CodeOf(err)
+	if code == "" {
+		code = apierror.CodeBadRequest
+	}
+
+	statusCode, ok := statusCodes[code]
+	if !ok {
+		statusCode = codes.Unknown
+	}
+
+	grpc.SetTrailer(ctx, metadata.Pairs("error-code", string(code)))
+	return status.Error(statusCode, err.Error())
+}
+
+// arguments returns the fields of a request as the arguments of its audit event
+func arguments(req proto.Message) map[string]interface{} {
+	args := map[string]interface{}{}
+
+	encoded, err := protojson.Marshal(req)
+	if err == nil {
+		json.Unmarshal(encoded, &args)
+	}
+
+	return args
+}
+
+// intPointer converts an optional field of a request to an optional argument of a resolver
+func intPointer(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+
+	result := int(*value)
+	return &result
+}
+
+// enumName converts a protobuf enum to its value in the GraphQL schema, which is its name without the prefix of its
+// type. It returns an empty string for the unspecified value
+func enumName(name string, prefix string) string {
+	if strings.HasSuffix(name, "_UNSPECIFIED") {
+		return ""
+	}
+
+	return strings.TrimPrefix(name, prefix)
+}
@@ -0,0 +1,203 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/middleware"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// exportSections are the files of a data export, each holding the rows of a query about the user as a JSON array.
+// Secrets such as password hashes and token hashes are left out
+var exportSections = []struct {
+	file  string
+	query string
+}{
+	{"user.json", `SELECT id, created_at, identifier, user_name, email, phone_number, provider, roles, email_verified_at,
+		totp_enabled_at FROM users WHERE id = $1`},
+	{"sessions.json", "SELECT id, created_at, expires_at, last_used_at, user_agent, ip, location FROM tokens WHERE user_id = $1"},
+	{"api_keys.json", "SELECT id, created_at, name, prefix, scopes, last_used_at, revoked_at FROM api_keys WHERE user_id = $1"},
+	{"channels.json", "SELECT id, created_at, title, channel_name FROM channels WHERE owner_id = $1"},
+	{"participation.json", "SELECT channel_id, created_at, uid, name FROM participants WHERE user_id = $1"},
+	{"attendance.json", `SELECT attendance.channel_id, attendance.uid, attendance.joined_at, attendance.left_at FROM attendance
+		INNER JOIN participants ON participants.channel_id = attendance.channel_id AND participants.uid = attendance.uid
+		WHERE participants.user_id = $1`},
+	{"messages.json", "SELECT id, created_at, channel_id, name, body FROM channel_messages WHERE user_id = $1"},
+	{"audit_events.json", "SELECT created_at, ip, operation, channel_id, succeeded, error_code FROM audit_events WHERE user_id = $1"},
+}
+
+// BuildDataExports builds the pending data exports and drops the expired ones
+func (router *ServiceRouter) BuildDataExports(ctx context.Context) error {
+	for ctx.Err() == nil && router.BuildDataExport(ctx) {
+	}
+
+	_, err := router.DB.ExecContext(ctx, "DELETE FROM data_exports WHERE expires_at < NOW()")
+	return err
+}
+
+// BuildDataExport builds the oldest pending data export. Exports are locked while they are built so that every
+// instance can build exports. It reports whether an export was found
+func (router *ServiceRouter) BuildDataExport(ctx context.Context) bool {
+	tx, err := router.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not start transaction")
+		return false
+	}
+	defer tx.Rollback()
+
+	var export models.UserDataExport
+	err = tx.GetContext(ctx, &export, `SELECT id, created_at, user_id, status FROM data_exports WHERE status = $1
+		ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED`, models.DataExportStatusPending)
+	if err != nil {
+		return false
+	}
+
+	archive, err := buildExportArchive(ctx, tx, export.UserID)
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("export", export.ID).Int64("User ID", export.UserID).Msg("Could not build data export")
+
+		// The export stays locked until the transaction ends
+		tx.Rollback()
+		_, err = router.DB.ExecContext(ctx, "UPDATE data_exports SET status = $1, completed_at = NOW() WHERE id = $2", models.DataExportStatusFailed, export.ID)
+		if err != nil {
+			router.Logger.Error().Err(err).Int64("export", export.ID).Msg("Could not mark data export as failed")
+		}
+		return true
+	}
+
+	expiresAt := time.Now().Add(time.Duration(viper.GetInt("DATA_EXPORT_EXPIRY_HOURS")) * time.Hour)
+	_, err = tx.ExecContext(ctx, "UPDATE data_exports SET status = $1, completed_at = NOW(), expires_at = $2, archive = $3 WHERE id = $4",
+		models.DataExportStatusReady, expiresAt, archive, export.ID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("export", export.ID).Msg("Could not store data export")
+		return true
+	}
+
+	router.Logger.Info().Int64("export", export.ID).Int64("User ID", export.UserID).Int("bytes", len(archive)).Msg("Built data export")
+	return true
+}
+
+// buildExportArchive zips the sections of the data export of a user
+func buildExportArchive(ctx context.Context, tx *sqlx.Tx, userID int64) ([]byte, error) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, section := range exportSections {
+		var rows string
+		err := tx.GetContext(ctx, &rows, "SELECT COALESCE(json_agg(section), '[]')::TEXT FROM ("+section.query+") section", userID)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := writer.Create(section.file)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = file.Write([]byte(rows))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return archive.Bytes(), nil
+}
+
+// DownloadDataExport is a REST route that serves the archive of a ready data export to the user it belongs to
+func (router *ServiceRouter) DownloadDataExport(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var export models.UserDataExport
+	err = router.DB.GetContext(r.Context(), &export, "SELECT id, created_at, user_id, status, archive FROM data_exports WHERE id = $1 AND user_id = $2 AND status = $3 AND expires_at > NOW()",
+		id, user.ID, models.DataExportStatusReady)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"data-export-"+strconv.FormatInt(export.ID, 10)+".zip\"")
+	w.Write(export.Archive)
+}
+
+// DeleteAccount erases a user. Its tokens, keys and codes are deleted along with it, it is removed as the owner of its
+// channels and the recordings of those channels, except those shared with an organization, are deleted by the next
+// retention run. Organizations it is the only owner of get a new owner. Its attendance and
+// participation are deleted and its name is removed from its messages. It reports whether the user existed
+func DeleteAccount(ctx context.Context, db *models.Database, userID int64) (bool, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"UPDATE recordings SET delete_after = NOW() WHERE channel_id IN (SELECT id FROM channels WHERE owner_id = $1 AND organization_id IS NULL)",
+		// Organizations the user is the only owner of are handed to their longest serving admin, or member otherwise
+		`UPDATE organization_members SET role = 'OWNER' WHERE id IN (
+			SELECT DISTINCT ON (members.organization_id) members.id FROM organization_members members
+			INNER JOIN organization_members owners ON owners.organization_id = members.organization_id
+			AND owners.user_id = $1 AND owners.role = 'OWNER'
+			WHERE members.user_id <> $1 AND NOT EXISTS (SELECT 1 FROM organization_members others
+				WHERE others.organization_id = members.organization_id AND others.role = 'OWNER' AND others.user_id <> $1)
+			ORDER BY members.organization_id, members.role = 'ADMIN' DESC, members.id)`,
+		`DELETE FROM attendance USING participants WHERE participants.channel_id = attendance.channel_id
+			AND participants.uid = attendance.uid AND participants.user_id = $1`,
+		"DELETE FROM participants WHERE user_id = $1",
+		"UPDATE channel_messages SET name = NULL WHERE user_id = $1",
+		"DELETE FROM phone_codes WHERE phone_number = (SELECT phone_number FROM users WHERE id = $1)",
+	}
+	for _, statement := range statements {
+		_, err = tx.ExecContext(ctx, statement, userID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return deleted > 0, tx.Commit()
+}
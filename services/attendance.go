@@ -0,0 +1,127 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// RTC channel event types sent by the Agora Notification Callback Service
+const (
+	ChannelEventCreate             = 101
+	ChannelEventDestroy            = 102
+	ChannelEventBroadcasterJoin    = 103
+	ChannelEventBroadcasterLeave   = 104
+	ChannelEventAudienceJoin       = 105
+	ChannelEventAudienceLeave      = 106
+	ChannelEventCommunicationJoin  = 107
+	ChannelEventCommunicationLeave = 108
+	ChannelEventRoleToBroadcaster  = 111
+	ChannelEventRoleToAudience     = 112
+)
+
+// rtcProductID is the product ID NCS uses for RTC channel events
+const rtcProductID = 1
+
+// ChannelEventPayload is the payload of an RTC channel event
+type ChannelEventPayload struct {
+	ChannelName string `json:"channelName"`
+	UID         int64  `json:"uid"`
+	Platform    int    `json:"platform"`
+	ClientSeq   int64  `json:"clientSeq"`
+	// Ts is the time of the event in seconds since the Unix epoch
+	Ts     int64 `json:"ts"`
+	Reason int   `json:"reason"`
+	// Duration is the number of seconds the user was in the channel, only sent when a user leaves
+	Duration int64 `json:"duration"`
+}
+
+// ChannelEvent is the body of an RTC channel event sent by the Agora Notification Callback Service
+type ChannelEvent struct {
+	NoticeID  string              `json:"noticeId"`
+	ProductID int                 `json:"productId"`
+	EventType int                 `json:"eventType"`
+	NotifyMs  int64               `json:"notifyMs"`
+	Payload   ChannelEventPayload `json:"payload"`
+}
+
+// ChannelWebhook is a REST route that receives RTC channel events from the Agora Notification Callback Service
+// and records when users join and leave channels. The creation and destruction of channels is delivered to webhooks as
+// the start and end of meetings
+func (router *ServiceRouter) ChannelWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var event ChannelEvent
+	if !router.decodeNCSEvent(w, r, &event) {
+		return
+	}
+
+	if event.ProductID != rtcProductID {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var err error
+	switch event.EventType {
+	case ChannelEventBroadcasterJoin, ChannelEventAudienceJoin, ChannelEventCommunicationJoin:
+		err = router.recordJoin(ctx, event.Payload)
+	case ChannelEventBroadcasterLeave, ChannelEventAudienceLeave, ChannelEventCommunicationLeave:
+		err = router.recordLeave(ctx, event.Payload)
+	case ChannelEventCreate:
+		err = QueueChannelEvent(ctx, router.DB, event.Payload.ChannelName, models.WebhookMeetingStarted, map[string]interface{}{"startedAt": time.Unix(event.Payload.Ts, 0)})
+	case ChannelEventDestroy:
+		err = QueueChannelEvent(ctx, router.DB, event.Payload.ChannelName, models.WebhookMeetingEnded, map[string]interface{}{"endedAt": time.Unix(event.Payload.Ts, 0)})
+	}
+
+	if err != nil {
+		router.Logger.Error().Err(err).Interface("Event", event).Msg("Could not update attendance for NCS event")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// recordJoin starts a stay of a user in a channel. Notifications that are delivered again are ignored
+func (router *ServiceRouter) recordJoin(ctx context.Context, payload ChannelEventPayload) error {
+	_, err := router.DB.ExecContext(ctx, `INSERT INTO attendance (channel_id, uid, joined_at)
+		SELECT id, $2, TO_TIMESTAMP($3) FROM channels WHERE channel_name = $1
+		ON CONFLICT (channel_id, uid, joined_at) DO NOTHING`,
+		payload.ChannelName, payload.UID, payload.Ts)
+	return err
+}
+
+// recordLeave ends the latest stay of a user in a channel. Since NCS does not guarantee the order of notifications,
+// the stay is created from the duration of the leave event when its join has not been received
+func (router *ServiceRouter) recordLeave(ctx context.Context, payload ChannelEventPayload) error {
+	res, err := router.DB.ExecContext(ctx, `UPDATE attendance SET left_at = TO_TIMESTAMP($3) WHERE id = (
+		SELECT attendance.id FROM attendance INNER JOIN channels ON channels.id = attendance.channel_id
+		WHERE channels.channel_name = $1 AND attendance.uid = $2 AND attendance.left_at IS NULL AND attendance.joined_at <= TO_TIMESTAMP($3)
+		ORDER BY attendance.joined_at DESC LIMIT 1)`,
+		payload.ChannelName, payload.UID, payload.Ts)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil || updated > 0 {
+		return err
+	}
+
+	_, err = router.DB.ExecContext(ctx, `INSERT INTO attendance (channel_id, uid, joined_at, left_at)
+		SELECT id, $2, TO_TIMESTAMP($3 - $4), TO_TIMESTAMP($3) FROM channels WHERE channel_name = $1
+		ON CONFLICT (channel_id, uid, joined_at) DO UPDATE SET left_at = EXCLUDED.left_at`,
+		payload.ChannelName, payload.UID, payload.Ts, payload.Duration)
+	return err
+}
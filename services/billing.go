@@ -0,0 +1,211 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// maxStripeEventSize is the largest Stripe webhook payload that is read
+const maxStripeEventSize = 1 << 20
+
+// Metadata keys of Stripe subscriptions that tell who a subscription belongs to
+const (
+	StripeUserKey         = "user_id"
+	StripeOrganizationKey = "organization_id"
+)
+
+// BillingEnabled reports whether plans can be subscribed to with Stripe
+func BillingEnabled() bool {
+	return viper.GetString("STRIPE_SECRET_KEY") != ""
+}
+
+// subjectSubscriptionCondition matches the subscriptions of the subject passed as $1 (organization) and $2 (user)
+const subjectSubscriptionCondition = `(CASE WHEN $1::INT IS NULL THEN subscriptions.user_id = $2 ELSE subscriptions.organization_id = $1 END)`
+
+// LatestSubscription returns the most recent subscription of a subject, whatever its status, or nil when the
+// subject never subscribed
+func LatestSubscription(ctx context.Context, db sqlx.QueryerContext, subject UsageSubject) (*models.StripeSubscription, error) {
+	var subscription models.StripeSubscription
+	err := sqlx.GetContext(ctx, db, &subscription, `SELECT * FROM subscriptions WHERE `+subjectSubscriptionCondition+`
+		ORDER BY created_at DESC LIMIT 1`, subject.OrganizationID, subject.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+// ActivePlan returns the plan of the live subscription of a subject, or nil when the subject has none and the
+// default quotas apply
+func ActivePlan(ctx context.Context, db sqlx.QueryerContext, subject UsageSubject) (*models.BillingPlan, error) {
+	if !subject.Valid() {
+		return nil, nil
+	}
+
+	var plan models.BillingPlan
+	err := sqlx.GetContext(ctx, db, &plan, `SELECT plans.* FROM subscriptions INNER JOIN plans ON plans.id = subscriptions.plan_id
+		WHERE `+subjectSubscriptionCondition+` AND subscriptions.status = ANY($3)
+		ORDER BY subscriptions.current_period_end DESC NULLS LAST LIMIT 1`,
+		subject.OrganizationID, subject.UserID, pq.Array(models.LiveSubscriptionStatuses))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// PlanQuota returns the monthly quota of a metric under a plan, falling back to the default quota when there is no
+// plan. It is 0 when the metric is unlimited
+func PlanQuota(plan *models.BillingPlan, metric models.UsageMetric) float64 {
+	if plan == nil {
+		return Quota(metric)
+	}
+
+	limit := plan.Limit(metric)
+	if !limit.Valid {
+		return 0
+	}
+
+	return float64(limit.Int64)
+}
+
+// stripeEvent is a Stripe webhook event
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeSubscription is the part of a Stripe subscription the backend keeps track of
+type stripeSubscription struct {
+	ID               string            `json:"id"`
+	Customer         string            `json:"customer"`
+	Status           string            `json:"status"`
+	CurrentPeriodEnd int64             `json:"current_period_end"`
+	Metadata         map[string]string `json:"metadata"`
+	Items            struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// StripeWebhook is a REST route that receives subscription events from Stripe, signed with STRIPE_WEBHOOK_SECRET
+func (router *ServiceRouter) StripeWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxStripeEventSize))
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not read Stripe event")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = utils.VerifyStripeSignature(payload, r.Header.Get("Stripe-Signature"), viper.GetString("STRIPE_WEBHOOK_SECRET"), time.Now())
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Invalid Stripe signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var event stripeEvent
+	err = json.Unmarshal(payload, &event)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not parse Stripe event")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	router.Logger.Info().Str("id", event.ID).Str("type", event.Type).Msg("Stripe event")
+
+	switch event.Type {
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		var subscription stripeSubscription
+		err = json.Unmarshal(event.Data.Object, &subscription)
+		if err == nil {
+			err = router.saveSubscription(ctx, &subscription, time.Unix(event.Created, 0))
+		}
+	}
+
+	if err != nil {
+		router.Logger.Error().Err(err).Str("id", event.ID).Msg("Could not handle Stripe event")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// saveSubscription stores the state of a Stripe subscription. Stripe does not deliver events in order, so a
+// subscription is only updated from events newer than the one it was last updated from
+func (router *ServiceRouter) saveSubscription(ctx context.Context, subscription *stripeSubscription, eventAt time.Time) error {
+	var subject UsageSubject
+	if id, err := strconv.ParseInt(subscription.Metadata[StripeOrganizationKey], 10, 64); err == nil {
+		subject.OrganizationID = sql.NullInt64{Int64: id, Valid: true}
+	} else if id, err := strconv.ParseInt(subscription.Metadata[StripeUserKey], 10, 64); err == nil {
+		subject.UserID = sql.NullInt64{Int64: id, Valid: true}
+	} else {
+		router.Logger.Info().Str("subscription", subscription.ID).Msg("Ignoring Stripe subscription that was not created by the backend")
+		return nil
+	}
+
+	if len(subscription.Items.Data) == 0 {
+		router.Logger.Error().Str("subscription", subscription.ID).Msg("Stripe subscription has no price")
+		return nil
+	}
+
+	var planID int64
+	err := router.DB.GetContext(ctx, &planID, "SELECT id FROM plans WHERE stripe_price_id = $1", subscription.Items.Data[0].Price.ID)
+	if err == sql.ErrNoRows {
+		router.Logger.Error().Str("subscription", subscription.ID).Str("price", subscription.Items.Data[0].Price.ID).Msg("Stripe subscription is to an unknown price")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var periodEnd sql.NullTime
+	if subscription.CurrentPeriodEnd > 0 {
+		periodEnd = sql.NullTime{Time: time.Unix(subscription.CurrentPeriodEnd, 0), Valid: true}
+	}
+
+	_, err = router.DB.ExecContext(ctx, `INSERT INTO subscriptions (user_id, organization_id, plan_id, stripe_customer_id, stripe_subscription_id, status, current_period_end, event_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (stripe_subscription_id) DO UPDATE SET plan_id = excluded.plan_id, status = excluded.status,
+		current_period_end = excluded.current_period_end, event_at = excluded.event_at, updated_at = NOW()
+		WHERE subscriptions.event_at <= excluded.event_at`,
+		subject.UserID, subject.OrganizationID, planID, subscription.Customer, subscription.ID, subscription.Status, periodEnd, eventAt)
+	return err
+}
@@ -0,0 +1,117 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoCalendar is returned when a user has not signed in with a provider that granted access to their calendar
+var ErrNoCalendar = errors.New("No calendar access granted")
+
+// UserCalendar is the calendar of a user along with a client that makes requests as them
+type UserCalendar struct {
+	Provider string
+	Calendar utils.Calendar
+	Client   *http.Client
+}
+
+// hasScope reports whether a space separated list of granted scopes contains a scope. Microsoft prefixes the scopes
+// it grants with the resource they belong to
+func hasScope(granted string, scope string) bool {
+	for _, field := range strings.Fields(granted) {
+		if field == scope || strings.HasSuffix(field, "/"+scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetUserCalendar finds the latest credentials of a user that grant access to a calendar. When provider is not empty
+// only the calendar of that provider is used, like when updating an event created before.
+// Access tokens are refreshed and stored when they have expired
+func GetUserCalendar(ctx context.Context, db *models.Database, userID int64, provider string) (*UserCalendar, error) {
+	names := []string{}
+	for name := range utils.Calendars {
+		if provider == "" || provider == name {
+			names = append(names, name)
+		}
+	}
+
+	credentials := []models.Auth{}
+	query, args, err := sqlx.In("SELECT id, code, access_token, refresh_token, token_type, expiry, user_id, provider, scope FROM credentials WHERE user_id = ? AND provider IN (?) AND scope IS NOT NULL ORDER BY id DESC", userID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.SelectContext(ctx, &credentials, db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, credential := range credentials {
+		calendar := utils.Calendars[credential.Provider.String]
+		if !hasScope(credential.Scope.String, calendar.Scope()) {
+			continue
+		}
+
+		oauthProvider, err := GetProvider(credential.Provider.String)
+		if err != nil {
+			return nil, err
+		}
+
+		// Refreshing tokens does not redirect, so the config needs no redirect URL
+		config, err := oauthProvider.Config(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := config.TokenSource(ctx, &oauth2.Token{
+			AccessToken:  credential.AccessToken,
+			RefreshToken: credential.RefreshToken,
+			TokenType:    credential.TokenType,
+			Expiry:       credential.Expiry,
+		}).Token()
+		if err != nil {
+			return nil, err
+		}
+
+		if token.AccessToken != credential.AccessToken {
+			// Microsoft rotates refresh tokens along with access tokens
+			refreshToken := credential.RefreshToken
+			if token.RefreshToken != "" {
+				refreshToken = token.RefreshToken
+			}
+
+			_, err = db.ExecContext(ctx, "UPDATE credentials SET access_token = $1, refresh_token = $2, expiry = $3 WHERE id = $4",
+				token.AccessToken, refreshToken, token.Expiry, credential.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &UserCalendar{
+			Provider: credential.Provider.String,
+			Calendar: calendar,
+			Client:   oauth2.NewClient(ctx, oauth2.StaticTokenSource(token)),
+		}, nil
+	}
+
+	return nil, ErrNoCalendar
+}
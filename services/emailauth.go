@@ -0,0 +1,95 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Purposes of the single use tokens that are emailed to users
+const (
+	EmailTokenVerify    = "verify"
+	EmailTokenReset     = "reset"
+	EmailTokenMagicLink = "magic_link"
+)
+
+const emailTokenPrefix = "abe_"
+
+// ErrInvalidEmailToken is returned when an emailed token does not exist, has expired or has already been used
+var ErrInvalidEmailToken = errors.New("Invalid or expired link")
+
+// HashPassword hashes a password with bcrypt so that it can be stored
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a hash created by HashPassword
+func CheckPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// IssueEmailToken creates a single use token for a user that is valid for EMAIL_TOKEN_EXPIRY_MINUTES.
+// The unused tokens previously issued to the user for the same purpose stop working
+func IssueEmailToken(ctx context.Context, db *models.Database, userID int64, purpose string) (string, error) {
+	token, err := utils.GenerateSecret(emailTokenPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "UPDATE email_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL", userID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(viper.GetInt("EMAIL_TOKEN_EXPIRY_MINUTES")) * time.Minute)
+	_, err = tx.ExecContext(ctx, "INSERT INTO email_tokens (user_id, purpose, token_hash, expires_at) VALUES ($1, $2, $3, $4)", userID, purpose, utils.HashSecret(token), expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// ConsumeEmailToken marks an emailed token as used and returns the user it was issued to
+func ConsumeEmailToken(ctx context.Context, tx *sqlx.Tx, token string, purpose string) (int64, error) {
+	var userID int64
+	err := tx.GetContext(ctx, &userID, `UPDATE email_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW() RETURNING user_id`, utils.HashSecret(token), purpose)
+	if err == sql.ErrNoRows {
+		return 0, ErrInvalidEmailToken
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,109 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// Features that can be turned off with feature flags. Features are on unless a flag turns them off
+const (
+	FeatureWaitingRoom   = "waiting_room"
+	FeatureTranscription = "transcription"
+	FeaturePSTN          = "pstn"
+	FeatureLiveStreaming = "live_streaming"
+)
+
+// featureScope identifies the flag of a feature globally, when both IDs are 0, for an organization or for a channel
+type featureScope struct {
+	feature        string
+	organizationID int64
+	channelID      int64
+}
+
+// featureFlagCache holds every feature flag, which are few enough to load at once, and is reloaded once it is older
+// than FEATURE_FLAG_CACHE_SECONDS. Instances share no cache, so a change takes up to that long to reach them all
+type featureFlagCache struct {
+	mu       sync.Mutex
+	loadedAt time.Time
+	flags    map[featureScope]bool
+}
+
+var cachedFeatureFlags featureFlagCache
+
+// load returns the cached flags, reloading them when they are stale. When reloading fails, stale flags are used
+// until the database is reachable again
+func (cache *featureFlagCache) load(ctx context.Context, db sqlx.QueryerContext) (map[featureScope]bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	ttl := time.Duration(viper.GetInt("FEATURE_FLAG_CACHE_SECONDS")) * time.Second
+	if cache.flags != nil && time.Since(cache.loadedAt) < ttl {
+		return cache.flags, nil
+	}
+
+	stored := []models.FeatureFlagSetting{}
+	err := sqlx.SelectContext(ctx, db, &stored, "SELECT id, created_at, updated_at, feature, organization_id, channel_id, enabled FROM feature_flags")
+	if err != nil {
+		if cache.flags != nil {
+			return cache.flags, nil
+		}
+
+		return nil, err
+	}
+
+	flags := map[featureScope]bool{}
+	for _, flag := range stored {
+		flags[featureScope{flag.Feature, flag.OrganizationID.Int64, flag.ChannelID.Int64}] = flag.Enabled
+	}
+
+	cache.flags = flags
+	cache.loadedAt = time.Now()
+	return flags, nil
+}
+
+// InvalidateFeatureFlags makes the next evaluation of a feature flag reload the flags, so that changes made by this
+// instance apply right away
+func InvalidateFeatureFlags() {
+	cachedFeatureFlags.mu.Lock()
+	defer cachedFeatureFlags.mu.Unlock()
+
+	cachedFeatureFlags.loadedAt = time.Time{}
+}
+
+// FeatureEnabled evaluates whether a feature is on for a channel, from the flag of the channel, of its organization
+// or the global flag, whichever is set first
+func FeatureEnabled(ctx context.Context, db sqlx.QueryerContext, feature string, organizationID sql.NullInt64, channelID int64) (bool, error) {
+	flags, err := cachedFeatureFlags.load(ctx, db)
+	if err != nil {
+		return true, err
+	}
+
+	scopes := []featureScope{{feature: feature, channelID: channelID}}
+	if organizationID.Valid {
+		scopes = append(scopes, featureScope{feature: feature, organizationID: organizationID.Int64})
+	}
+	scopes = append(scopes, featureScope{feature: feature})
+
+	for _, scope := range scopes {
+		if enabled, ok := flags[scope]; ok {
+			return enabled, nil
+		}
+	}
+
+	return true, nil
+}
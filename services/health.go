@@ -0,0 +1,135 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// healthCheckTimeout bounds how long a single dependency check may take so that probes answer in time
+const healthCheckTimeout = 2 * time.Second
+
+// How long the result of checking the Agora credentials is reused, as every check is a request to the Agora
+// RESTful API. Failures are checked again sooner so that a backend recovers quickly from a transient error
+const (
+	agoraCheckInterval      = 5 * time.Minute
+	agoraCheckRetryInterval = 30 * time.Second
+)
+
+// DependencyStatus is the result of checking a dependency of the backend
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse is returned by the health and readiness endpoints
+type HealthResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// agoraCheck caches the last result of checking the Agora credentials
+type agoraCheck struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+var cachedAgoraCheck agoraCheck
+
+// checkAgora verifies the Agora credentials, reusing the previous result while it is recent enough
+func (router *ServiceRouter) checkAgora(ctx context.Context) error {
+	cachedAgoraCheck.mu.Lock()
+	defer cachedAgoraCheck.mu.Unlock()
+
+	interval := agoraCheckInterval
+	if cachedAgoraCheck.err != nil {
+		interval = agoraCheckRetryInterval
+	}
+
+	if !cachedAgoraCheck.checkedAt.IsZero() && time.Since(cachedAgoraCheck.checkedAt) < interval {
+		return cachedAgoraCheck.err
+	}
+
+	cachedAgoraCheck.err = router.Agora.CheckAgoraCredentials(ctx, utils.DefaultProject())
+	cachedAgoraCheck.checkedAt = time.Now()
+	return cachedAgoraCheck.err
+}
+
+// dependencyStatus converts the result of a check into its status
+func dependencyStatus(err error) DependencyStatus {
+	if err != nil {
+		return DependencyStatus{Status: "error", Error: err.Error()}
+	}
+
+	return DependencyStatus{Status: "ok"}
+}
+
+// writeHealth runs the given checks and responds with the status of each dependency. The response has status
+// 503 when any dependency is unhealthy
+func (router *ServiceRouter) writeHealth(w http.ResponseWriter, r *http.Request, checks map[string]func(ctx context.Context) error) {
+	response := HealthResponse{
+		Status:       "ok",
+		Dependencies: map[string]DependencyStatus{},
+	}
+
+	for name, check := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := check(ctx)
+		cancel()
+
+		if err != nil {
+			router.Logger.Error().Err(err).Str("dependency", name).Msg("Health check failed")
+			response.Status = "error"
+		}
+		response.Dependencies[name] = dependencyStatus(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if response.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	json.NewEncoder(w).Encode(&response)
+}
+
+// Healthz reports whether the backend is alive, which requires its database to be reachable
+func (router *ServiceRouter) Healthz(w http.ResponseWriter, r *http.Request) {
+	router.writeHealth(w, r, map[string]func(ctx context.Context) error{
+		"database": router.DB.PingContext,
+	})
+}
+
+// Readyz reports whether the backend can serve requests: the database and Redis, when configured, have to be
+// reachable and the Agora credentials have to be valid
+func (router *ServiceRouter) Readyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]func(ctx context.Context) error{
+		"database": router.DB.PingContext,
+		"agora":    router.checkAgora,
+	}
+
+	if router.Redis != nil {
+		checks["redis"] = func(ctx context.Context) error {
+			return router.Redis.Ping(ctx).Err()
+		}
+	}
+
+	router.writeHealth(w, r, checks)
+}
@@ -0,0 +1,77 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// InvitationStatusURL returns the URL the SMS provider reports the delivery of an invitation to, or an empty string
+// when BACKEND_URL or ENCRYPTION_KEY is not set. The URL is signed like the status URLs of PSTN calls
+func InvitationStatusURL(invitationID int64) string {
+	if viper.GetString("BACKEND_URL") == "" || viper.GetString("ENCRYPTION_KEY") == "" {
+		return ""
+	}
+
+	id := strconv.FormatInt(invitationID, 10)
+	query := url.Values{}
+	query.Set("id", id)
+	query.Set("signature", callSignature("invitation:"+id))
+
+	return strings.TrimSuffix(viper.GetString("BACKEND_URL"), "/") + "/webhooks/sms/status?" + query.Encode()
+}
+
+// SMSStatusWebhook is a REST route that receives the delivery status of text message invitations from Twilio
+func (router *ServiceRouter) SMSStatusWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.URL.Query().Get("id")
+	signature, err := hex.DecodeString(r.URL.Query().Get("signature"))
+	if err != nil || id == "" || viper.GetString("ENCRYPTION_KEY") == "" {
+		router.Logger.Error().Str("id", id).Msg("Invalid SMS status request")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	expected, _ := hex.DecodeString(callSignature("invitation:" + id))
+	if !hmac.Equal(signature, expected) {
+		router.Logger.Error().Str("id", id).Msg("Invalid SMS status signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	status := strings.ToLower(r.PostFormValue("MessageStatus"))
+	if status == "" {
+		router.Logger.Error().Str("id", id).Msg("SMS status request has no status")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	router.Logger.Info().Str("id", id).Str("status", status).Msg("SMS invitation status")
+
+	// Statuses can arrive after the invitation failed to send, which is final
+	_, err = router.DB.ExecContext(ctx, "UPDATE invitations SET status = $1, updated_at = NOW() WHERE id = $2 AND status <> $3",
+		status, id, models.InvitationFailed)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("id", id).Msg("Could not update invitation status")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
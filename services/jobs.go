@@ -0,0 +1,271 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// Kinds of background jobs
+const (
+	JobRecordingRetention      = "recording_retention"
+	JobUsageMetering           = "usage_metering"
+	JobRecordingReconciliation = "recording_reconciliation"
+	JobRecordingTranscription  = "recording_transcription"
+	JobDataExports             = "data_exports"
+	JobWebhookDeliveries       = "webhook_deliveries"
+	JobEventPublishing         = "event_publishing"
+)
+
+// jobLease is how long a claimed job is kept from other servers. Jobs are cancelled once it runs out, since another
+// server may claim them again
+const jobLease = 10 * time.Minute
+
+// maxJobBackoff caps the time between two attempts of a job
+const maxJobBackoff = time.Hour
+
+// JobHandler runs a job with its JSON payload. Jobs whose handler returns an error are retried with exponential backoff
+// until JOB_MAX_ATTEMPTS
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// jobSchedule is a job that is queued every interval, once for all the servers
+type jobSchedule struct {
+	Kind     string
+	Interval time.Duration
+}
+
+// jobHandlers maps every kind of job onto the handler that runs it
+func (router *ServiceRouter) jobHandlers() map[string]JobHandler {
+	return map[string]JobHandler{
+		JobRecordingRetention: func(ctx context.Context, payload json.RawMessage) error {
+			return router.DeleteExpiredRecordings(ctx)
+		},
+		JobUsageMetering: func(ctx context.Context, payload json.RawMessage) error {
+			return router.MeterAttendance(ctx)
+		},
+		JobRecordingReconciliation: func(ctx context.Context, payload json.RawMessage) error {
+			return router.ReconcileRecordings(ctx, time.Duration(viper.GetInt("RECORDING_EMPTY_TIMEOUT_MINUTES"))*time.Minute)
+		},
+		JobRecordingTranscription: func(ctx context.Context, payload json.RawMessage) error {
+			return router.TranscribeRecordings(ctx)
+		},
+		JobDataExports: func(ctx context.Context, payload json.RawMessage) error {
+			return router.BuildDataExports(ctx)
+		},
+		JobWebhookDeliveries: func(ctx context.Context, payload json.RawMessage) error {
+			return router.DeliverWebhooks(ctx)
+		},
+		JobEventPublishing: func(ctx context.Context, payload json.RawMessage) error {
+			return router.PublishOutbox(ctx)
+		},
+	}
+}
+
+// jobSchedules lists the jobs that are queued periodically
+func jobSchedules() []jobSchedule {
+	return []jobSchedule{
+		{JobRecordingRetention, time.Duration(viper.GetInt("RECORDING_RETENTION_INTERVAL_MINUTES")) * time.Minute},
+		{JobUsageMetering, time.Duration(viper.GetInt("USAGE_METERING_INTERVAL_MINUTES")) * time.Minute},
+		{JobRecordingReconciliation, time.Duration(viper.GetInt("RECORDING_RECONCILE_INTERVAL_MINUTES")) * time.Minute},
+		{JobRecordingTranscription, time.Duration(viper.GetInt("RECORDING_TRANSCRIPT_INTERVAL_MINUTES")) * time.Minute},
+		{JobDataExports, time.Duration(viper.GetInt("DATA_EXPORT_INTERVAL_MINUTES")) * time.Minute},
+		{JobWebhookDeliveries, time.Duration(viper.GetInt("WEBHOOK_DELIVERY_INTERVAL_SECONDS")) * time.Second},
+		{JobEventPublishing, time.Duration(viper.GetInt("EVENT_PUBLISH_INTERVAL_SECONDS")) * time.Second},
+	}
+}
+
+// EnqueueJob queues a job of a kind with a payload that is encoded to JSON, to run at runAt. A job is only queued once
+// for a kind and key, unless key is empty
+func EnqueueJob(ctx context.Context, db sqlx.ExecerContext, kind string, key string, payload interface{}, runAt time.Time) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO jobs (kind, unique_key, payload, status, max_attempts, run_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (kind, unique_key) DO NOTHING`,
+		kind, sql.NullString{String: key, Valid: key != ""}, string(encoded), models.JobPending, viper.GetInt("JOB_MAX_ATTEMPTS"), runAt)
+	return err
+}
+
+// RetryJob queues a dead job again with a fresh set of attempts
+func RetryJob(ctx context.Context, db sqlx.QueryerContext, id int64) (*models.BackgroundJob, error) {
+	var job models.BackgroundJob
+	err := sqlx.GetContext(ctx, db, &job, `UPDATE jobs SET status = $1, attempts = 0, run_at = NOW(), started_at = NULL, finished_at = NULL
+		WHERE id = $2 AND status = $3
+		RETURNING id, created_at, kind, unique_key, payload, status, attempts, max_attempts, run_at, started_at, finished_at, last_error`,
+		models.JobPending, id, models.JobDead)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Jobs runs the background jobs that are due every interval, queues the periodic jobs and drops the succeeded jobs
+// older than JOB_RETENTION_DAYS. It blocks until ctx is done and should be run in its own goroutine. Jobs are cancelled
+// along with ctx and handed back to the queue for another server
+func (router *ServiceRouter) Jobs(ctx context.Context, interval time.Duration) {
+	handlers := router.jobHandlers()
+	schedules := jobSchedules()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		router.scheduleJobs(ctx, schedules)
+		for ctx.Err() == nil && router.RunJob(ctx, handlers) {
+		}
+
+		if ctx.Err() == nil {
+			router.expireJobs(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			// The outbox is flushed once more on the way out, so that the events queued by the last requests are not
+			// left waiting for another server
+			err := router.PublishOutbox(context.Background())
+			if err != nil {
+				router.Logger.Error().Err(err).Msg("Could not flush outbox")
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scheduleJobs queues the periodic jobs of the current interval. Jobs are keyed by the start of their interval, so that
+// every server can schedule them and they still only run once
+func (router *ServiceRouter) scheduleJobs(ctx context.Context, schedules []jobSchedule) {
+	now := time.Now()
+	for _, schedule := range schedules {
+		if schedule.Interval <= 0 {
+			continue
+		}
+
+		start := now.Truncate(schedule.Interval)
+		err := EnqueueJob(ctx, router.DB, schedule.Kind, strconv.FormatInt(start.Unix(), 10), struct{}{}, start)
+		if err != nil {
+			router.Logger.Error().Err(err).Str("kind", schedule.Kind).Msg("Could not schedule job")
+		}
+	}
+}
+
+// RunJob runs the job that has been due the longest. Jobs are claimed by pushing back when they are due, so that
+// several servers can share the queue and jobs of a server that stops are run again once their lease runs out. Jobs
+// that fail every attempt are kept as dead. It reports whether a job was due
+func (router *ServiceRouter) RunJob(ctx context.Context, handlers map[string]JobHandler) bool {
+	var job models.BackgroundJob
+	err := router.DB.GetContext(ctx, &job, `UPDATE jobs SET status = $1, attempts = attempts + 1, started_at = NOW(), run_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id = (SELECT id FROM jobs WHERE status IN ($1, $3) AND run_at <= NOW() AND attempts < max_attempts ORDER BY run_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING id, created_at, kind, unique_key, payload, status, attempts, max_attempts, run_at, started_at, finished_at, last_error`,
+		models.JobRunning, int(jobLease.Seconds()), models.JobPending)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			router.Logger.Error().Err(err).Msg("Could not claim job")
+		}
+		return false
+	}
+
+	err = router.runJob(ctx, handlers, &job)
+
+	// The outcome is stored even when ctx was cancelled while the job ran, so that it is not left to run out its lease
+	cancelled := ctx.Err() != nil
+	ctx = context.Background()
+	if err == nil {
+		_, err = router.DB.ExecContext(ctx, "UPDATE jobs SET status = $1, finished_at = NOW(), last_error = NULL WHERE id = $2",
+			models.JobSucceeded, job.ID)
+		if err != nil {
+			router.Logger.Error().Err(err).Int64("job", job.ID).Msg("Could not mark job as succeeded")
+		}
+		return true
+	}
+
+	if cancelled {
+		// Jobs cut short by a shutdown are handed back without counting the attempt
+		router.Logger.Info().Err(err).Int64("job", job.ID).Str("kind", job.Kind).Msg("Job was cancelled")
+		_, err = router.DB.ExecContext(ctx, "UPDATE jobs SET status = $1, attempts = attempts - 1, run_at = NOW(), started_at = NULL WHERE id = $2",
+			models.JobPending, job.ID)
+		if err != nil {
+			router.Logger.Error().Err(err).Int64("job", job.ID).Msg("Could not hand back job")
+		}
+		return false
+	}
+
+	router.Logger.Error().Err(err).Int64("job", job.ID).Str("kind", job.Kind).Int("attempts", job.Attempts).Msg("Job failed")
+
+	if job.Attempts >= job.MaxAttempts {
+		_, err = router.DB.ExecContext(ctx, "UPDATE jobs SET status = $1, finished_at = NOW(), last_error = $2 WHERE id = $3",
+			models.JobDead, err.Error(), job.ID)
+	} else {
+		backoff := time.Duration(viper.GetInt("JOB_RETRY_SECONDS")) * time.Second
+		for attempt := 1; attempt < job.Attempts && backoff < maxJobBackoff; attempt++ {
+			backoff *= 2
+		}
+		if backoff > maxJobBackoff {
+			backoff = maxJobBackoff
+		}
+
+		_, err = router.DB.ExecContext(ctx, "UPDATE jobs SET status = $1, run_at = NOW() + $2 * INTERVAL '1 second', last_error = $3 WHERE id = $4",
+			models.JobPending, int(backoff.Seconds()), err.Error(), job.ID)
+	}
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("job", job.ID).Msg("Could not update job")
+	}
+
+	return true
+}
+
+// runJob runs a claimed job with its handler within its lease. Panics are turned into errors so that they count as a
+// failed attempt instead of stopping the workers
+func (router *ServiceRouter) runJob(ctx context.Context, handlers map[string]JobHandler, job *models.BackgroundJob) (err error) {
+	handler, ok := handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for jobs of kind %s", job.Kind)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, jobLease)
+	defer cancel()
+
+	return handler(ctx, json.RawMessage(job.Payload))
+}
+
+// expireJobs gives up on the jobs whose last attempt ran out of its lease, which happens when the server running it
+// stopped, and drops the succeeded jobs older than JOB_RETENTION_DAYS
+func (router *ServiceRouter) expireJobs(ctx context.Context) {
+	_, err := router.DB.ExecContext(ctx, `UPDATE jobs SET status = $1, finished_at = NOW(), last_error = 'The job did not finish within its lease'
+		WHERE status = $2 AND run_at <= NOW() AND attempts >= max_attempts`, models.JobDead, models.JobRunning)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not expire jobs")
+	}
+
+	_, err = router.DB.ExecContext(ctx, "DELETE FROM jobs WHERE status = $1 AND finished_at < NOW() - $2 * INTERVAL '1 day'",
+		models.JobSucceeded, viper.GetInt("JOB_RETENTION_DAYS"))
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not delete old jobs")
+	}
+}
@@ -0,0 +1,237 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// Cloud recording event types sent by the Agora Notification Callback Service
+const (
+	RecordingEventError           = 1
+	RecordingEventWarning         = 2
+	RecordingEventStatusUpdate    = 3
+	RecordingEventFileInfos       = 4
+	RecordingEventSessionExit     = 11
+	RecordingEventSessionFailover = 12
+	RecordingEventUploaderStarted = 30
+	RecordingEventUploaded        = 31
+	RecordingEventBackuped        = 32
+	RecordingEventRecorderStarted = 40
+)
+
+// cloudRecordingProductID is the product ID NCS uses for cloud recording events
+const cloudRecordingProductID = 3
+
+type NCSPayload struct {
+	Cname       string                 `json:"cname"`
+	UID         string                 `json:"uid"`
+	SID         string                 `json:"sid"`
+	Sequence    int                    `json:"sequence"`
+	SendTs      int64                  `json:"sendts"`
+	ServiceType int                    `json:"serviceType"`
+	Details     map[string]interface{} `json:"details"`
+}
+
+// NCSEvent is the body of a notification sent by the Agora Notification Callback Service
+type NCSEvent struct {
+	NoticeID  string     `json:"noticeId"`
+	ProductID int        `json:"productId"`
+	EventType int        `json:"eventType"`
+	NotifyMs  int64      `json:"notifyMs"`
+	Payload   NCSPayload `json:"payload"`
+}
+
+// verifyNCSSignature checks the body against the signature headers sent by NCS, preferring the SHA256 signature
+func verifyNCSSignature(r *http.Request, body []byte) bool {
+	secret := viper.GetString("NCS_SECRET")
+	if secret == "" {
+		return false
+	}
+
+	var signature string
+	var hashFunc func() hash.Hash
+	if r.Header.Get("Agora-Signature-V2") != "" {
+		signature = r.Header.Get("Agora-Signature-V2")
+		hashFunc = sha256.New
+	} else {
+		signature = r.Header.Get("Agora-Signature")
+		hashFunc = sha1.New
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(hashFunc, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// decodeNCSEvent verifies the signature of a notification and decodes it into event. When it fails the response has
+// already been written
+func (router *ServiceRouter) decodeNCSEvent(w http.ResponseWriter, r *http.Request, event interface{}) bool {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not read NCS request body")
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	if !verifyNCSSignature(r, body) {
+		router.Logger.Error().Str("body", string(body)).Msg("Invalid NCS signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	err = json.Unmarshal(body, event)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("body", string(body)).Msg("Could not parse NCS event")
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	router.Logger.Info().Interface("Event", event).Msg("NCS Event")
+	return true
+}
+
+// RecordingWebhook is a REST route that receives cloud recording events from the Agora Notification Callback Service
+func (router *ServiceRouter) RecordingWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var event NCSEvent
+	if !router.decodeNCSEvent(w, r, &event) {
+		return
+	}
+
+	var err error
+
+	if event.ProductID != cloudRecordingProductID {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch event.EventType {
+	case RecordingEventFileInfos:
+		err = router.storeRecordingFiles(ctx, event.Payload)
+	case RecordingEventRecorderStarted:
+		err = router.setRecordingStatus(ctx, event.Payload, "recording")
+	case RecordingEventUploaded:
+		err = router.setRecordingStatus(ctx, event.Payload, "uploaded")
+		if err == nil {
+			err = router.queueTranscript(ctx, event.Payload)
+		}
+		if err == nil {
+			err = QueueChannelEvent(ctx, router.DB, event.Payload.Cname, models.WebhookRecordingAvailable, map[string]interface{}{"sid": event.Payload.SID})
+		}
+	case RecordingEventBackuped:
+		err = router.setRecordingStatus(ctx, event.Payload, "backuped")
+	case RecordingEventSessionFailover:
+		err = router.failoverRecording(ctx, event.Payload)
+	case RecordingEventSessionExit:
+		err = router.endRecording(ctx, event.Payload)
+	case RecordingEventError:
+		router.Logger.Error().Str("channel", event.Payload.Cname).Str("sid", event.Payload.SID).Interface("details", event.Payload.Details).Msg("Cloud recording error")
+	}
+
+	if err != nil {
+		router.Logger.Error().Err(err).Interface("Event", event).Msg("Could not update channel for NCS event")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (router *ServiceRouter) setRecordingStatus(ctx context.Context, payload NCSPayload, status string) error {
+	_, err := router.DB.ExecContext(ctx, "UPDATE channels SET recording_status = $1 WHERE channel_name = $2 AND recording_sid = $3", status, payload.Cname, payload.SID)
+	return err
+}
+
+// storeRecordingFiles saves the files reported by cloud recording so that they can be listed and downloaded later
+func (router *ServiceRouter) storeRecordingFiles(ctx context.Context, payload NCSPayload) error {
+	fileList, err := json.Marshal(payload.Details["fileList"])
+	if err != nil {
+		return err
+	}
+
+	result := utils.QueryResponse{
+		ServerResponse: utils.QueryServerResponse{
+			FileList: fileList,
+		},
+	}
+
+	for _, file := range result.Files() {
+		_, err = router.DB.ExecContext(ctx, `INSERT INTO recordings (channel_id, sid, file_name, track_type, uid, is_playable, slice_start_time)
+			SELECT id, $2, $3, $4, $5, $6, $7 FROM channels WHERE channel_name = $1
+			ON CONFLICT (sid, file_name) DO NOTHING`,
+			payload.Cname, payload.SID, file.Filename, file.TrackType, file.UID, file.IsPlayable, file.SliceStartTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failoverRecording stores the UID the recorder rejoined the channel with after a failover
+func (router *ServiceRouter) failoverRecording(ctx context.Context, payload NCSPayload) error {
+	newUID, ok := payload.Details["newUid"].(float64)
+	if !ok {
+		router.Logger.Error().Interface("Details", payload.Details).Msg("No new UID in failover event")
+		return nil
+	}
+
+	_, err := router.DB.ExecContext(ctx, "UPDATE channels SET recording_uid = $1 WHERE channel_name = $2 AND recording_sid = $3", int32(newUID), payload.Cname, payload.SID)
+	return err
+}
+
+// endRecording clears the recording details from the channel once the recorder has exited
+func (router *ServiceRouter) endRecording(ctx context.Context, payload NCSPayload) error {
+	return router.clearRecording(ctx, payload.Cname, payload.SID, RecordingExited)
+}
+
+// clearRecording clears the details of a recording session from the channel, records the final status and sends it to
+// the subscribers of the recording status of the channel
+func (router *ServiceRouter) clearRecording(ctx context.Context, channel string, sid string, status string) error {
+	err := MeterRecording(ctx, router.DB, sid)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("sid", sid).Msg("Could not meter recording")
+	}
+
+	var channelID int64
+	err = router.DB.GetContext(ctx, &channelID, "UPDATE channels SET recording_status = $3, recording_uid = NULL, recording_sid = NULL, recording_rid = NULL, recording_paused = FALSE, recording_started_at = NULL WHERE channel_name = $1 AND recording_sid = $2 RETURNING id", channel, sid, status)
+	if err == sql.ErrNoRows {
+		// The recording was already cleared
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	PublishRecordingUpdate(router.PubSub, channelID, status, sid)
+	return nil
+}
@@ -13,23 +13,17 @@ package services
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
 
-	"github.com/coreos/go-oidc"
 	"github.com/rs/zerolog/log"
 	"github.com/samyak-jain/agora_backend/pkg/models"
-	"github.com/samyak-jain/agora_backend/utils"
 	"github.com/spf13/viper"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/microsoft"
-	"golang.org/x/oauth2/slack"
 )
 
 // User contains all the information that we get as a response from oauth
@@ -42,8 +36,11 @@ type User struct {
 
 // TokenTemplate is a struct that will be used to template the token into the html that will be served for Desktop and Mobile
 type TokenTemplate struct {
-	Token  string
-	Scheme string
+	Token        string
+	RefreshToken string
+	Scheme       string
+	// Origin is the origin of the redirect URL, which is the only window the desktop page hands the tokens to
+	Origin string
 }
 
 // Details contains all the OAuth related information parsed from the request
@@ -124,7 +121,7 @@ func parseState(r *http.Request) (*Details, error) {
 }
 
 // Handler is the handler that will do most of the heavy lifting for OAuth
-func (router *ServiceRouter) Handler(w http.ResponseWriter, r *http.Request) (*string, *string, *string, error) {
+func (router *ServiceRouter) Handler(w http.ResponseWriter, r *http.Request) (*string, *AuthTokens, *string, error) {
 	err := r.ParseForm()
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -139,14 +136,23 @@ func (router *ServiceRouter) Handler(w http.ResponseWriter, r *http.Request) (*s
 		return nil, nil, nil, err
 	}
 
-	oauthConfig, provider, err := router.GetOAuthConfig(oauthDetails.OAuthSite, oauthDetails.BackendURL+"/oauth")
-	router.Logger.Debug().Interface("OAuth Config", oauthConfig).Interface("Provider", provider).Msg("OAuth Configuration Debug Information")
+	provider, err := GetProvider(oauthDetails.OAuthSite)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		router.Logger.Error().Err(err).Str("site", oauthDetails.OAuthSite).Msg("OAuth provider is not available")
+		return nil, nil, nil, err
+	}
+
+	ctx := r.Context()
+	oauthConfig, err := provider.Config(ctx, oauthDetails.BackendURL+"/oauth")
+	router.Logger.Debug().Interface("OAuth Config", oauthConfig).Str("Provider", provider.Name()).Msg("OAuth Configuration Debug Information")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
+		router.Logger.Error().Err(err).Str("provider", provider.Name()).Msg("Could not make OAuth config")
 		return nil, nil, nil, err
 	}
 
-	userInfo, err := router.GetUserInfo(*oauthConfig, *oauthDetails, provider)
+	userInfo, err := router.GetUserInfo(ctx, provider, oauthConfig, *oauthDetails)
 	router.Logger.Debug().Interface("User Info", userInfo).Msg("Debug User Information")
 	if err != nil {
 		return nil, nil, nil, err
@@ -171,75 +177,67 @@ func (router *ServiceRouter) Handler(w http.ResponseWriter, r *http.Request) (*s
 		return nil, nil, nil, errors.New("Email is not verified")
 	}
 
-	bearerToken, err := utils.GenerateUUID()
+	tx, err := router.DB.BeginTxx(ctx, nil)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Error().Err(err).Msg("Could not generate bearer token")
+		router.Logger.Error().Err(err).Msg("Could not start transaction")
 		return nil, nil, nil, err
 	}
+	defer tx.Rollback()
 
-	var userData models.UserAccount
-	err = router.DB.Get(&userData, "SELECT id, identifier, user_name, email FROM users WHERE email=$1", userInfo.Email)
-
-	if err != nil {
-		tx := router.DB.MustBegin()
+	repos := router.Repos.WithTx(tx)
+	userData, err := repos.Users.ByProviderEmail(ctx, userInfo.Email)
 
-		statement, err := tx.PrepareNamed("INSERT INTO users (identifier, user_name, email) VALUES (:identifier, :user_name, :email) RETURNING id")
+	if err == nil && !userData.Provider.Valid {
+		// Users created before providers were recorded signed in with the provider they use now
+		err = repos.Users.SetProvider(ctx, userData.ID, provider.Name())
 		if err != nil {
-			router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Msg("Could not insert user")
-			tx.Rollback()
+			router.Logger.Error().Err(err).Int64("user", userData.ID).Msg("Could not record user provider")
 			return nil, nil, nil, err
 		}
-
-		var userID int64
+	} else if err != nil {
 		var userName sql.NullString
 		if userInfo.Name == "" {
 			userName = sql.NullString{Valid: false}
 		} else {
 			userName = sql.NullString{String: userInfo.Name, Valid: true}
 		}
-		err = statement.Get(&userID, &models.UserAccount{
+		userData = &models.UserAccount{
 			Identifier: userInfo.ID,
 			UserName:   userName,
 			Email:      userInfo.Email,
-		})
-		if err != nil {
-			router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Msg("Could not fetch User Database ID")
-			tx.Rollback()
-			return nil, nil, nil, err
+			Provider:   sql.NullString{String: provider.Name(), Valid: true},
 		}
-
-		_, err = tx.NamedExec("INSERT INTO tokens (token_id, user_id) VALUES (:token_id, :user_id)", &models.Token{
-			TokenID: bearerToken,
-			UserID:  userID,
-		})
-
+		err = repos.Users.Create(ctx, userData)
 		if err != nil {
-			router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Str("token", bearerToken).Msg("Could not insert token")
-			tx.Rollback()
+			router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Msg("Could not insert user")
 			return nil, nil, nil, err
 		}
+	}
 
-		tx.Commit()
-	} else {
+	err = repos.Credentials.LinkUser(ctx, oauthDetails.Code, userData.ID)
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("user", userData.ID).Msg("Could not link credentials to user")
+		return nil, nil, nil, err
+	}
 
-		_, err = router.DB.NamedExec("INSERT INTO tokens (token_id, user_id) VALUES (:token_id, :user_id)", &models.Token{
-			TokenID: bearerToken,
-			UserID:  userData.ID,
-		})
+	tokens, err := CreateAuthSession(ctx, repos, userData.ID)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Msg("Could not insert token")
+		return nil, nil, nil, err
+	}
 
-		if err != nil {
-			router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Str("token", bearerToken).Msg("Could not insert token")
-			return nil, nil, nil, err
-		}
+	err = tx.Commit()
+	if err != nil {
+		router.Logger.Error().Err(err).Str("identifier", userInfo.ID).Msg("Could not commit sign in")
+		return nil, nil, nil, err
 	}
 
-	return &oauthDetails.RedirectURL, &bearerToken, &oauthDetails.Platform, nil
+	return &oauthDetails.RedirectURL, tokens, &oauthDetails.Platform, nil
 }
 
 // OAuth is a REST route that is called when the oauth provider redirects to here and provides the code
 func (o *ServiceRouter) OAuth(w http.ResponseWriter, r *http.Request) {
-	redirect, token, platform, err := o.Handler(w, r)
+	redirect, tokens, platform, err := o.Handler(w, r)
 	if err != nil || platform == nil {
 		log.Print(err)
 		fmt.Fprint(w, err)
@@ -254,9 +252,10 @@ func (o *ServiceRouter) OAuth(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		newURL.Path = path.Join(newURL.Path, *token)
-
-		fmt.Printf("%+v\n", newURL)
+		// The refresh token is passed in the fragment, which browsers neither send to servers nor put in the Referer
+		// header, so that it does not end up in access logs
+		newURL.Path = path.Join(newURL.Path, tokens.AccessToken)
+		newURL.Fragment = "refreshToken=" + tokens.RefreshToken
 
 		http.Redirect(w, r, newURL.String(), http.StatusSeeOther)
 	} else if *platform == "mobile" {
@@ -267,10 +266,18 @@ func (o *ServiceRouter) OAuth(w http.ResponseWriter, r *http.Request) {
 		}
 
 		t.Execute(w, TokenTemplate{
-			Token:  *token,
-			Scheme: viper.GetString("SCHEME"),
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			Scheme:       viper.GetString("SCHEME"),
 		})
 	} else if *platform == "desktop" {
+		redirectURL, err := url.Parse(*redirect)
+		if err != nil || redirectURL.Scheme == "" || redirectURL.Host == "" {
+			log.Error().Err(err).Str("redirect_url", *redirect).Msg("Failed to parse redirect url")
+			fmt.Fprint(w, "Invalid redirect URL")
+			return
+		}
+
 		t, err := template.ParseFiles("web/desktop.html")
 		if err != nil {
 			fmt.Fprint(w, "Internal Server Error")
@@ -278,261 +285,68 @@ func (o *ServiceRouter) OAuth(w http.ResponseWriter, r *http.Request) {
 		}
 
 		t.Execute(w, TokenTemplate{
-			Token: *token,
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			Origin:       redirectURL.Scheme + "://" + redirectURL.Host,
 		})
 	}
 }
 
-// GetOAuthConfig makes the oauth2 config for the relevant site
-func (r *ServiceRouter) GetOAuthConfig(site string, redirectURI string) (*oauth2.Config, *oidc.Provider, error) {
-	var provider *oidc.Provider
-	var err error
-
-	ctx := context.Background()
-
-	var client_id string
-	var client_secret string
-
-	switch site {
-	case "google":
-		provider, err = oidc.NewProvider(ctx, "https://accounts.google.com")
-		client_id = viper.GetString("GOOGLE_CLIENT_ID")
-		client_secret = viper.GetString("GOOGLE_CLIENT_SECRET")
-		if err != nil {
-			r.Logger.Error().Err(err).Msg("Google Provider failed")
-			return nil, nil, err
-		}
-	case "microsoft":
-		return &oauth2.Config{
-			ClientID:     viper.GetString("MICROSOFT_CLIENT_ID"),
-			ClientSecret: viper.GetString("MICROSOFT_CLIENT_SECRET"),
-			Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "offline_access"},
-			Endpoint:     microsoft.AzureADEndpoint("common"),
-			RedirectURL:  redirectURI,
-		}, nil, nil
-	case "slack":
-		return &oauth2.Config{
-			ClientID:     viper.GetString("SLACK_CLIENT_ID"),
-			ClientSecret: viper.GetString("SLACK_CLIENT_SECRET"),
-			Scopes:       []string{"users.profile:read"},
-			Endpoint:     slack.Endpoint,
-			RedirectURL:  redirectURI,
-		}, nil, nil
-	case "apple":
-		provider, err = oidc.NewProvider(ctx, "https://appleid.apple.com")
-		if err != nil {
-			r.Logger.Error().Err(err).Msg("Apple Provider failed")
-			return nil, nil, err
-		}
-		client_id = viper.GetString("APPLE_CLIENT_ID")
-		client_secret, err = GenerateAppleClientSecret(viper.GetString("APPLE_PRIVATE_KEY"), viper.GetString("APPLE_TEAM_ID"), client_id, viper.GetString("APPLE_KEY_ID"))
-		if err != nil {
-			r.Logger.Error().Err(err).Msg("Could not generate Apple Client Secret")
-			return nil, nil, err
-		}
-
-	default:
-		r.Logger.Error().Msg("Unknown state parameter passed")
-		return nil, nil, errors.New("Unknow state parameter passed")
-	}
-
-	if client_id == "" || client_secret == "" {
-		r.Logger.Error().Str("ID", client_id).Str("Secret", client_secret).Msg("No Client ID or Client Secret")
-		return nil, nil, errors.New("Invalid Config")
-	}
-
-	return &oauth2.Config{
-		ClientID:     client_id,
-		ClientSecret: client_secret,
-		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
-		Endpoint:     provider.Endpoint(),
-		RedirectURL:  redirectURI,
-	}, provider, nil
-}
-
-// GetUserInfo fetches the User Info from the Open ID Endpoint
-func (r *ServiceRouter) GetUserInfo(oauthConfig oauth2.Config, oauthDetails Details, provider *oidc.Provider) (*User, error) {
-
-	var tokenData models.Auth
+// GetUserInfo exchanges the code with the provider and fetches the signed in user.
+// Exchanged tokens are stored in the credentials table so that a code can be redeemed again, and so that the
+// calendar of the user can be used when they granted access to it
+func (r *ServiceRouter) GetUserInfo(ctx context.Context, provider Provider, oauthConfig *oauth2.Config, oauthDetails Details) (*User, error) {
 	var token *oauth2.Token
-	err := r.DB.Get(&tokenData, "SELECT id, code, access_token, refresh_token, token_type, expiry FROM credentials WHERE code=$1", oauthDetails.Code)
+	tokenData, err := r.Repos.Credentials.ByCode(ctx, oauthDetails.Code)
 	if err != nil {
 		r.Logger.Debug().Msg("Code not found in database")
 
-		token, err = oauthConfig.Exchange(oauth2.NoContext, oauthDetails.Code)
+		token, err = oauthConfig.Exchange(ctx, oauthDetails.Code)
 		if err != nil {
 			r.Logger.Error().Err(err).Interface("OAuth Details", oauthDetails).Interface("config", oauthConfig).Msg("OAuth Token Exchange failed")
 			return nil, err
 		}
 
-		_, err = r.DB.NamedExec("INSERT INTO credentials (code, access_token, refresh_token, token_type, expiry) VALUES (:code, :access_token, :refresh_token, :token_type, :expiry)", &models.Auth{
+		// Providers list the scopes the user granted, which tells whether the credentials can access their calendar
+		scope, _ := token.Extra("scope").(string)
+		err = r.Repos.Credentials.Create(ctx, &models.Auth{
 			Code:         oauthDetails.Code,
 			AccessToken:  token.AccessToken,
 			RefreshToken: token.RefreshToken,
 			TokenType:    token.TokenType,
 			Expiry:       token.Expiry,
+			Provider:     sql.NullString{String: provider.Name(), Valid: true},
+			Scope:        sql.NullString{String: scope, Valid: scope != ""},
 		})
 		if err != nil {
 			r.Logger.Error().Err(err).Msg("Cannot insert credentials")
 		}
 	} else {
-		token.AccessToken = tokenData.AccessToken
-		token.RefreshToken = tokenData.RefreshToken
-		token.Expiry = tokenData.Expiry
-		token.TokenType = tokenData.TokenType
+		token = &oauth2.Token{
+			AccessToken:  tokenData.AccessToken,
+			RefreshToken: tokenData.RefreshToken,
+			Expiry:       tokenData.Expiry,
+			TokenType:    tokenData.TokenType,
+		}
 
-		tokenSource := oauthConfig.TokenSource(oauth2.NoContext, token)
+		tokenSource := oauthConfig.TokenSource(ctx, token)
 		newToken, err := tokenSource.Token()
 		if err != nil {
 			return nil, err
 		}
 
 		if newToken.AccessToken != token.AccessToken {
-			r.DB.NamedExec("UPDATE credentials SET access_token = ':access_token' WHERE code = ':code'", &models.Auth{
-				AccessToken: newToken.AccessToken,
-				Code:        oauthDetails.Code,
-			})
+			r.Repos.Credentials.SetAccessToken(ctx, oauthDetails.Code, newToken.AccessToken)
 		}
 
 		token = newToken
 	}
 
-	if provider == nil {
-		if oauthDetails.OAuthSite == "slack" {
-			// Adding this since Slack does not publicly publish it's .well-known discovery URL.
-			// So we will have to manually hard code the UserInfo URL until we find that URL
-			userInfoURL := "https://slack.com/api/users.profile.get"
-
-			type authedSlackUser struct {
-				ID string
-			}
-
-			authedUser, ok := token.Extra("user_id").(string)
-			if !ok {
-				r.Logger.Error().Str("OAuth Details", oauthDetails.Code).Interface("OAuth Exchange", token).Msg("No UserID in Slack OAuth Response")
-				return nil, errors.New("No UserID in Slack OAuth Response")
-			}
-
-			client := oauthConfig.Client(oauth2.NoContext, token)
-
-			data := url.Values{}
-			data.Set("user", authedUser)
-			response, err := client.PostForm(userInfoURL, data)
-			if err != nil {
-				r.Logger.Error().Err(err).Str("OAuth Details", oauthDetails.Code).Str("token", token.AccessToken).Msg("Could not fetch user info details")
-				return nil, err
-			}
-			defer response.Body.Close()
-
-			contents, err := ioutil.ReadAll(response.Body)
-			if err != nil {
-				r.Logger.Error().Interface("Response Body", response.Body).Err(err).Msg("Could not read response body")
-				return nil, err
-			}
-
-			type SlackUser struct {
-				Name  string `json:"display_name_normalized"`
-				Email string
-			}
-
-			type SlackResponse struct {
-				Ok      bool
-				Profile *SlackUser `json:"profile,omitempty"`
-				Error   string     `json:"error,omitempty"`
-			}
-
-			var user SlackResponse
-			err = json.Unmarshal(contents, &user)
-			if err != nil {
-				r.Logger.Error().Err(err).Str("body", string(contents)).Msg("Could not parse response body")
-				return nil, err
-			}
-
-			if user.Error != "" {
-				r.Logger.Error().Str("Error", user.Error).Str("id", authedUser).Str("body", string(contents)).Msg("Could not fetch Userinfo for slack")
-				return nil, errors.New(user.Error)
-			}
-
-			return &User{ID: authedUser, Name: user.Profile.Name, Email: user.Profile.Email, EmailVerified: true}, nil
-		}
-
-		if oauthDetails.OAuthSite == "microsoft" {
-			client := &http.Client{}
-			req, err := http.NewRequest("GET", "https://graph.microsoft.com/oidc/userinfo", nil)
-			if err != nil {
-				log.Error().Err(err).Str("code", oauthDetails.Code).Str("token", token.AccessToken).Msg("Could not fetch user info details")
-				return nil, err
-			}
-
-			bearer := "Bearer " + token.AccessToken
-			req.Header.Add("Authorization", bearer)
-
-			response, err := client.Do(req)
-			if err != nil {
-				log.Error().Err(err).Str("code", oauthDetails.Code).Str("token", token.AccessToken).Msg("Could not fetch user info details")
-				return nil, err
-			}
-
-			defer response.Body.Close()
-
-			contents, err := ioutil.ReadAll(response.Body)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not read response body")
-				return nil, err
-			}
-
-			var user *User
-			err = json.Unmarshal(contents, &user)
-			if err != nil {
-				log.Error().Err(err).Str("body", string(contents)).Msg("Could not parse response body")
-				return nil, err
-			}
-
-			user.EmailVerified = true
-			return user, nil
-		}
-
-		r.Logger.Error().Interface("OAuth Config", oauthConfig).Interface("OAuth Details", oauthDetails).Msg("Provider should not be nil")
-		return nil, errors.New("Provider should not be nil")
-	}
-
-	if oauthDetails.OAuthSite == "apple" {
-		rawIDToken, ok := token.Extra("id_token").(string)
-		if !ok {
-			r.Logger.Error().Interface("token", token).Msg("Could not get id_token from apple token")
-			return nil, errors.New("Could not get id_token from apple token")
-		}
-		idTokenVerifier := provider.Verifier(&oidc.Config{ClientID: oauthConfig.ClientID})
-		idToken, err := idTokenVerifier.Verify(oauth2.NoContext, rawIDToken)
-		if err != nil {
-			r.Logger.Error().Str("rawIDToken", rawIDToken).Interface("idTokenVerifier", idTokenVerifier).Interface("OAuth Config", oauthConfig).Interface("OAuth Details", oauthDetails).Msg("Could not verify id_token")
-			return nil, errors.New("Could not verify id_token")
-		}
-
-		// Get Email from idToken
-		var claims struct {
-			Email string `json:"email"`
-		}
-
-		if err := idToken.Claims(&claims); err != nil {
-			return &User{ID: idToken.Subject, EmailVerified: true}, nil
-		}
-
-		return &User{ID: idToken.Subject, Email: claims.Email, EmailVerified: true}, nil
-
-	}
-
-	tokenSource := oauthConfig.TokenSource(oauth2.NoContext, token)
-	userInfo, err := provider.UserInfo(oauth2.NoContext, tokenSource)
+	user, err := provider.UserInfo(ctx, oauthConfig, token)
 	if err != nil {
-		r.Logger.Error().Err(err).Str("code", oauthDetails.Code).Interface("config", oauthConfig).Interface("token", token).Msg("Fetching UserInfo Failed")
+		r.Logger.Error().Err(err).Str("provider", provider.Name()).Str("code", oauthDetails.Code).Msg("Fetching UserInfo Failed")
 		return nil, err
 	}
 
-	return &User{
-		ID:            userInfo.Subject,
-		Name:          userInfo.Profile,
-		Email:         userInfo.Email,
-		EmailVerified: userInfo.EmailVerified,
-	}, nil
+	return user, nil
 }
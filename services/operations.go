@@ -0,0 +1,55 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ErrInvalidOperation is returned for operation documents that do not parse or do not validate against the schema
+var ErrInvalidOperation = errors.New("Invalid operation")
+
+// RegisterOperation validates an operation document against schema and stores it, so that it can be executed while
+// OPERATION_ALLOWLIST is enabled. Registering a document again returns the operation registered before
+func RegisterOperation(ctx context.Context, db sqlx.QueryerContext, schema *ast.Schema, document string, createdBy sql.NullInt64) (*models.StoredOperation, error) {
+	query, errs := gqlparser.LoadQuery(schema, document)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOperation, strings.TrimSpace(errs.Error()))
+	}
+
+	if len(query.Operations) == 0 {
+		return nil, fmt.Errorf("%w: the document has no operation", ErrInvalidOperation)
+	}
+
+	name := sql.NullString{}
+	if query.Operations[0].Name != "" {
+		name = sql.NullString{String: query.Operations[0].Name, Valid: true}
+	}
+
+	var operation models.StoredOperation
+	err := sqlx.GetContext(ctx, db, &operation, `INSERT INTO persisted_operations (hash, name, document, created_by) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (hash) DO UPDATE SET hash = EXCLUDED.hash RETURNING id, created_at, hash, name, document, created_by`,
+		models.OperationHash(document), name, document, createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &operation, nil
+}
@@ -0,0 +1,98 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// outboxBatchSize is how many events are published per transaction
+const outboxBatchSize = 100
+
+// PublishOutbox publishes the events in the outbox to the event bus in EVENT_BUS, when one is configured
+func (router *ServiceRouter) PublishOutbox(ctx context.Context) error {
+	bus, err := utils.NewEventBus()
+	if err != nil || bus == nil {
+		return err
+	}
+
+	for ctx.Err() == nil && router.PublishEvents(ctx, bus) {
+	}
+
+	return nil
+}
+
+// PublishEvents publishes a batch of events from the outbox in the order they were queued and removes them once they
+// were published. Only one server publishes at a time, and publishing stops at the first failure, so that events are
+// not published out of order. Events are published at least once. It reports whether a full batch was published
+func (router *ServiceRouter) PublishEvents(ctx context.Context, bus utils.EventBus) bool {
+	tx, err := router.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not start transaction")
+		return false
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock($1, 0)", models.LockEventOutbox)
+	if err != nil || !locked {
+		return false
+	}
+
+	var events []struct {
+		ID        int64  `db:"id"`
+		EventID   string `db:"event_id"`
+		Event     string `db:"event"`
+		ChannelID int64  `db:"channel_id"`
+		Payload   string `db:"payload"`
+	}
+	err = tx.SelectContext(ctx, &events, "SELECT id, event_id, event, channel_id, payload FROM event_outbox ORDER BY id LIMIT $1", outboxBatchSize)
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not fetch outbox")
+		return false
+	}
+
+	published := []int64{}
+	for _, event := range events {
+		err = bus.Publish(event.Event, strconv.FormatInt(event.ChannelID, 10), []byte(event.Payload))
+		if err != nil {
+			router.Logger.Error().Err(err).Str("event", event.EventID).Msg("Could not publish event")
+
+			_, err = tx.ExecContext(ctx, "UPDATE event_outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2", err.Error(), event.ID)
+			if err != nil {
+				router.Logger.Error().Err(err).Str("event", event.EventID).Msg("Could not update outbox")
+			}
+			break
+		}
+
+		published = append(published, event.ID)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM event_outbox WHERE id = ANY($1)", pq.Array(published))
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		router.Logger.Error().Err(err).Msg("Could not remove published events from outbox")
+		return false
+	}
+
+	if len(published) > 0 {
+		router.Logger.Info().Int("events", len(published)).Msg("Published events")
+	}
+
+	return len(published) == outboxBatchSize
+}
@@ -0,0 +1,75 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// SaveOrganizationProject encrypts the credentials of the Agora project of an organization and stores them,
+// replacing the project the organization had before
+func SaveOrganizationProject(ctx context.Context, db sqlx.ExecerContext, organizationID int64, project utils.AgoraProject) error {
+	encrypted := []string{project.AppCertificate, project.CustomerID, project.CustomerCertificate}
+	for index, value := range encrypted {
+		ciphertext, err := utils.Encrypt(value)
+		if err != nil {
+			return err
+		}
+		encrypted[index] = ciphertext
+	}
+
+	_, err := db.ExecContext(ctx, `INSERT INTO organization_credentials (organization_id, app_id, app_certificate, customer_id, customer_certificate) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id) DO UPDATE SET app_id = EXCLUDED.app_id, app_certificate = EXCLUDED.app_certificate,
+		customer_id = EXCLUDED.customer_id, customer_certificate = EXCLUDED.customer_certificate, created_at = CURRENT_TIMESTAMP`,
+		organizationID, project.AppID, encrypted[0], encrypted[1], encrypted[2])
+	return err
+}
+
+// OrganizationProject returns the Agora project that channels of an organization are created in. Channels outside of
+// an organization, and channels of organizations without their own project, use the project configured for the
+// deployment
+func OrganizationProject(ctx context.Context, db sqlx.QueryerContext, organizationID sql.NullInt64) (*utils.AgoraProject, error) {
+	if !organizationID.Valid {
+		return utils.DefaultProject(), nil
+	}
+
+	var credentials models.OrganizationCredentials
+	err := sqlx.GetContext(ctx, db, &credentials, "SELECT organization_id, app_id, app_certificate, customer_id, customer_certificate FROM organization_credentials WHERE organization_id = $1", organizationID.Int64)
+	if err == sql.ErrNoRows {
+		return utils.DefaultProject(), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	project := &utils.AgoraProject{AppID: credentials.AppID}
+	for _, field := range []struct {
+		ciphertext string
+		plaintext  *string
+	}{
+		{credentials.AppCertificate, &project.AppCertificate},
+		{credentials.CustomerID, &project.CustomerID},
+		{credentials.CustomerCertificate, &project.CustomerCertificate},
+	} {
+		*field.plaintext, err = utils.Decrypt(field.ciphertext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return project, nil
+}
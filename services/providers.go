@@ -0,0 +1,385 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-oidc"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/oauth2/microsoft"
+	"golang.org/x/oauth2/slack"
+)
+
+// Provider is an OAuth identity provider that users can sign in with.
+// Every provider reads its credentials from <NAME>_CLIENT_ID and <NAME>_CLIENT_SECRET
+// and is only available when ENABLE_<NAME>_OAUTH is set
+type Provider interface {
+	// Name is the site passed in the OAuth state and the provider stored on the user
+	Name() string
+	// Config makes the oauth2 config used to exchange the code
+	Config(ctx context.Context, redirectURI string) (*oauth2.Config, error)
+	// UserInfo fetches the signed in user using the exchanged token
+	UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error)
+}
+
+var providers = map[string]Provider{
+	"google":    &oidcProvider{name: "google", issuer: "https://accounts.google.com"},
+	"oidc":      &oidcProvider{name: "oidc"},
+	"microsoft": &microsoftProvider{},
+	"apple":     &appleProvider{},
+	"github":    &githubProvider{},
+	"slack":     &slackProvider{},
+}
+
+// GetProvider returns the provider of a site if it has been enabled
+func GetProvider(site string) (Provider, error) {
+	provider, ok := providers[site]
+	if !ok {
+		return nil, fmt.Errorf("Unknown OAuth provider %q", site)
+	}
+
+	if !viper.GetBool(configKey(site, "ENABLE_%s_OAUTH")) {
+		return nil, fmt.Errorf("OAuth provider %q is not enabled", site)
+	}
+
+	return provider, nil
+}
+
+func configKey(name string, format string) string {
+	return fmt.Sprintf(format, strings.ToUpper(name))
+}
+
+// clientCredentials reads the client id and secret of a provider from the config
+func clientCredentials(name string) (string, string, error) {
+	clientID := viper.GetString(configKey(name, "%s_CLIENT_ID"))
+	clientSecret := viper.GetString(configKey(name, "%s_CLIENT_SECRET"))
+	if clientID == "" || clientSecret == "" {
+		return "", "", fmt.Errorf("No Client ID or Client Secret for %s", name)
+	}
+
+	return clientID, clientSecret, nil
+}
+
+// getJSON makes an authenticated GET request and decodes the JSON response into result
+func getJSON(ctx context.Context, client *http.Client, endpoint string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", endpoint, response.StatusCode, string(contents))
+	}
+
+	return json.Unmarshal(contents, result)
+}
+
+// oidcProvider signs users in with a provider that publishes an OpenID Connect discovery document.
+// Providers without a fixed issuer, such as the generic oidc provider used for Okta, Keycloak or AD FS,
+// read it from <NAME>_ISSUER_URL
+type oidcProvider struct {
+	name   string
+	issuer string
+}
+
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	issuer := p.issuer
+	if issuer == "" {
+		issuer = viper.GetString(configKey(p.name, "%s_ISSUER_URL"))
+	}
+
+	if issuer == "" {
+		return nil, fmt.Errorf("No Issuer URL for %s", p.name)
+	}
+
+	return oidc.NewProvider(ctx, issuer)
+}
+
+func (p *oidcProvider) Config(ctx context.Context, redirectURI string) (*oauth2.Config, error) {
+	clientID, clientSecret, err := clientCredentials(p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       append([]string{oidc.ScopeOpenID, "profile", "email"}, viper.GetStringSlice(configKey(p.name, "%s_SCOPES"))...),
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  redirectURI,
+	}, nil
+}
+
+func (p *oidcProvider) UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo, err := provider.UserInfo(ctx, config.TokenSource(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+
+	// Some enterprise identity providers such as AD FS never send email_verified for the emails they manage
+	return &User{
+		ID:            userInfo.Subject,
+		Name:          userInfo.Profile,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified || viper.GetBool(configKey(p.name, "%s_TRUST_EMAIL")),
+	}, nil
+}
+
+// microsoftProvider signs users in with Microsoft accounts and Azure AD.
+// MICROSOFT_TENANT restricts sign in to a single Azure AD tenant
+type microsoftProvider struct{}
+
+func (p *microsoftProvider) Name() string {
+	return "microsoft"
+}
+
+func (p *microsoftProvider) Config(ctx context.Context, redirectURI string) (*oauth2.Config, error) {
+	clientID, clientSecret, err := clientCredentials(p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email", "offline_access"},
+		Endpoint:     microsoft.AzureADEndpoint(viper.GetString("MICROSOFT_TENANT")),
+		RedirectURL:  redirectURI,
+	}, nil
+}
+
+// UserInfo fetches the signed in user. Microsoft does not send email_verified by default, since Azure AD lets tenants
+// set any email on their users, so emails are only treated as verified when it is sent or MICROSOFT_TRUST_EMAIL is set
+func (p *microsoftProvider) UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error) {
+	var userInfo struct {
+		ID            string `json:"sub"`
+		Name          string `json:"given_name"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	err := getJSON(ctx, config.Client(ctx, token), "https://graph.microsoft.com/oidc/userinfo", &userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:            userInfo.ID,
+		Name:          userInfo.Name,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified || viper.GetBool(configKey(p.Name(), "%s_TRUST_EMAIL")),
+	}, nil
+}
+
+// appleProvider signs users in with Sign in with Apple. The client secret is a JWT signed with APPLE_PRIVATE_KEY
+type appleProvider struct{}
+
+func (p *appleProvider) Name() string {
+	return "apple"
+}
+
+func (p *appleProvider) Config(ctx context.Context, redirectURI string) (*oauth2.Config, error) {
+	provider, err := oidc.NewProvider(ctx, "https://appleid.apple.com")
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := viper.GetString("APPLE_CLIENT_ID")
+	if clientID == "" {
+		return nil, errors.New("No Client ID for apple")
+	}
+
+	clientSecret, err := GenerateAppleClientSecret(viper.GetString("APPLE_PRIVATE_KEY"), viper.GetString("APPLE_TEAM_ID"), clientID, viper.GetString("APPLE_KEY_ID"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  redirectURI,
+	}, nil
+}
+
+func (p *appleProvider) UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("Could not get id_token from apple token")
+	}
+
+	provider, err := oidc.NewProvider(ctx, "https://appleid.apple.com")
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, errors.New("Could not verify id_token")
+	}
+
+	// Get Email from idToken
+	var claims struct {
+		Email string `json:"email"`
+	}
+
+	if err := idToken.Claims(&claims); err != nil {
+		return &User{ID: idToken.Subject, EmailVerified: true}, nil
+	}
+
+	return &User{ID: idToken.Subject, Email: claims.Email, EmailVerified: true}, nil
+}
+
+// githubProvider signs users in with GitHub. The primary email is used since the public profile email is optional
+type githubProvider struct{}
+
+func (p *githubProvider) Name() string {
+	return "github"
+}
+
+func (p *githubProvider) Config(ctx context.Context, redirectURI string) (*oauth2.Config, error) {
+	clientID, clientSecret, err := clientCredentials(p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"read:user", "user:email"},
+		Endpoint:     github.Endpoint,
+		RedirectURL:  redirectURI,
+	}, nil
+}
+
+func (p *githubProvider) UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error) {
+	client := config.Client(ctx, token)
+
+	var profile struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	err := getJSON(ctx, client, "https://api.github.com/user", &profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	err = getJSON(ctx, client, "https://api.github.com/user/emails", &emails)
+	if err != nil {
+		return nil, err
+	}
+
+	name := profile.Name
+	if name == "" {
+		name = profile.Login
+	}
+
+	user := &User{ID: strconv.FormatInt(profile.ID, 10), Name: name}
+	for _, email := range emails {
+		if email.Primary {
+			user.Email = email.Email
+			user.EmailVerified = email.Verified
+		}
+	}
+
+	return user, nil
+}
+
+// slackProvider signs users in with Slack. Slack does not publicly publish its .well-known discovery URL,
+// so the profile is fetched from the users.profile.get API
+type slackProvider struct{}
+
+func (p *slackProvider) Name() string {
+	return "slack"
+}
+
+func (p *slackProvider) Config(ctx context.Context, redirectURI string) (*oauth2.Config, error) {
+	clientID, clientSecret, err := clientCredentials(p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"users.profile:read"},
+		Endpoint:     slack.Endpoint,
+		RedirectURL:  redirectURI,
+	}, nil
+}
+
+func (p *slackProvider) UserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (*User, error) {
+	authedUser, ok := token.Extra("user_id").(string)
+	if !ok {
+		return nil, errors.New("No UserID in Slack OAuth Response")
+	}
+
+	var user struct {
+		Ok      bool
+		Profile *struct {
+			Name  string `json:"display_name_normalized"`
+			Email string
+		} `json:"profile,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+	err := getJSON(ctx, config.Client(ctx, token), "https://slack.com/api/users.profile.get?"+url.Values{"user": {authedUser}}.Encode(), &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Error != "" {
+		return nil, errors.New(user.Error)
+	}
+
+	return &User{ID: authedUser, Name: user.Profile.Name, Email: user.Profile.Email, EmailVerified: true}, nil
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/samyak-jain/agora_backend/pkg/models"
 	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
 	"github.com/spf13/viper"
 )
 
@@ -127,19 +128,36 @@ type PSTNResponse struct {
 }
 
 func (router *ServiceRouter) PSTN(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	query := r.URL.Query()
 	conferenceID := query.Get("confID")
 
 	router.Logger.Debug().Str("Conference ID", conferenceID).Msg("Got conference ID")
 
 	var channelData models.Channel
-	err := router.DB.Get(&channelData, "SELECT channel_name, channel_secret FROM channels WHERE dtmf=$1", conferenceID)
+	err := router.DB.GetContext(ctx, &channelData, "SELECT id, channel_name, channel_secret, token_expiry_seconds, organization_id, area FROM channels WHERE dtmf=$1 AND ended_at IS NULL ORDER BY id DESC LIMIT 1", conferenceID)
 	if err != nil {
 		router.Logger.Error().Err(err).Str("Conference ID", conferenceID).Msg("Could not fetch relevant channel from DB")
 		return
 	}
 
-	user, err := utils.GenerateUserCredentials(channelData.ChannelName, false, true)
+	enabled, err := FeatureEnabled(r.Context(), router.DB, FeaturePSTN, channelData.OrganizationID, channelData.ID)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not evaluate feature flags")
+	}
+
+	if !enabled {
+		router.Logger.Debug().Str("channel", channelData.ChannelName).Msg("PSTN is disabled for channel")
+		return
+	}
+
+	project, err := OrganizationProject(ctx, router.DB, channelData.OrganizationID)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("channel", channelData.ChannelName).Msg("Could not fetch Agora project")
+		return
+	}
+
+	user, err := utils.GenerateUserCredentials(project, channelData.ChannelName, rtctoken.RolePublisher, utils.TokenExpiry(&channelData), false, true, utils.ChannelArea(&channelData))
 	if err != nil {
 		router.Logger.Error().Err(err).Msg("Could not generate main user credentials")
 		return
@@ -158,7 +176,7 @@ func (router *ServiceRouter) PSTN(w http.ResponseWriter, r *http.Request) {
 			Type: "callStream",
 			App:  "agora",
 			Fields: AgoraFields{
-				AppID:          viper.GetString("APP_ID"),
+				AppID:          project.AppID,
 				ChannelName:    channelData.ChannelName,
 				Token:          user.Rtc,
 				UID:            user.UID,
@@ -175,7 +193,7 @@ func (router *ServiceRouter) PSTN(w http.ResponseWriter, r *http.Request) {
 			Type: "callStream",
 			App:  "agora",
 			Fields: AgoraFields{
-				AppID:       viper.GetString("APP_ID"),
+				AppID:       project.AppID,
 				ChannelName: channelData.ChannelName,
 				Token:       user.Rtc,
 				UID:         user.UID,
@@ -0,0 +1,162 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+type DialOutDetails struct {
+	ConferenceID      string `json:"conferenceID"`
+	ToNumber          string `json:"toNumber"`
+	FromNumber        string `json:"fromNumber,omitempty"`
+	RequestID         string `json:"requestID"`
+	StatusCallbackURL string `json:"statusCallbackUrl,omitempty"`
+}
+
+type DialOutRequest struct {
+	DialOut DialOutDetails `json:"dialOut"`
+}
+
+type DialOutRequestList struct {
+	AuthAccount AuthAccount      `json:"authAccount"`
+	RequestList []DialOutRequest `json:"requestList"`
+}
+
+type DialOutCall struct {
+	Request DialOutRequestList `json:"request"`
+}
+
+// DialOut asks the PSTN gateway to call a phone number and bridge the call into the conference of a channel.
+// Changes to the state of the call are posted to /webhooks/pstn/call when BACKEND_URL and ENCRYPTION_KEY are set
+func DialOut(confID string, phoneNumber string, callID string) error {
+	request := DialOutCall{
+		Request: DialOutRequestList{
+			AuthAccount: AuthAccount{
+				Email:     viper.GetString("PSTN_EMAIL"),
+				Password:  viper.GetString("PSTN_PASSWORD"),
+				PartnerID: "turbobridge",
+				AccountID: viper.GetString("PSTN_ACCOUNT"),
+			},
+			RequestList: []DialOutRequest{
+				{
+					DialOut: DialOutDetails{
+						ConferenceID:      confID,
+						ToNumber:          phoneNumber,
+						FromNumber:        viper.GetString("PSTN_CALLER_ID"),
+						RequestID:         callID,
+						StatusCallbackURL: callStatusURL(callID),
+					},
+				},
+			},
+		},
+	}
+
+	requestBody, err := json.Marshal(&request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", "https://api-dev.turbobridge.com/4.3/LCM", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Dial out failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// callStatusURL returns the URL the PSTN gateway reports the state of a call to. The URL is signed so that
+// the status of a call can only be changed by someone that was given its URL
+func callStatusURL(callID string) string {
+	if viper.GetString("BACKEND_URL") == "" || viper.GetString("ENCRYPTION_KEY") == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("id", callID)
+	query.Set("signature", callSignature(callID))
+
+	return strings.TrimSuffix(viper.GetString("BACKEND_URL"), "/") + "/webhooks/pstn/call?" + query.Encode()
+}
+
+// callSignature signs a call ID with ENCRYPTION_KEY
+func callSignature(callID string) string {
+	mac := hmac.New(sha256.New, []byte(viper.GetString("ENCRYPTION_KEY")))
+	mac.Write([]byte(callID))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CallStatus is the state of a call reported by the PSTN gateway
+type CallStatus struct {
+	Status string `json:"status"`
+}
+
+// PSTNCallWebhook is a REST route that receives changes to the state of calls placed with DialOut
+func (router *ServiceRouter) PSTNCallWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	callID := r.URL.Query().Get("id")
+	signature, err := hex.DecodeString(r.URL.Query().Get("signature"))
+	if err != nil || callID == "" || viper.GetString("ENCRYPTION_KEY") == "" {
+		router.Logger.Error().Str("id", callID).Msg("Invalid PSTN call status request")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	expected, _ := hex.DecodeString(callSignature(callID))
+	if !hmac.Equal(signature, expected) {
+		router.Logger.Error().Str("id", callID).Msg("Invalid PSTN call signature")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var status CallStatus
+	err = json.NewDecoder(r.Body).Decode(&status)
+	if err != nil || status.Status == "" {
+		router.Logger.Error().Err(err).Str("id", callID).Msg("Could not parse PSTN call status")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	router.Logger.Info().Str("id", callID).Str("status", status.Status).Msg("PSTN call status")
+
+	_, err = router.DB.ExecContext(ctx, "UPDATE pstn_calls SET status = $1, updated_at = NOW() WHERE call_id = $2", strings.ToLower(status.Status), callID)
+	if err != nil {
+		router.Logger.Error().Err(err).Str("id", callID).Msg("Could not update PSTN call status")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
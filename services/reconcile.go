@@ -0,0 +1,167 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// ReconcileRecordings compares the recordings of every channel with cloud recording. Recordings that cloud recording
+// no longer knows about are cleared from the channel, and recordings on channels that have been empty since before
+// emptyTimeout are stopped. The time a channel was first seen empty is kept in empty_recordings by recording SID, so
+// that the next run can pick up where this one left off on any server
+func (router *ServiceRouter) ReconcileRecordings(ctx context.Context, emptyTimeout time.Duration) error {
+	channels := []models.Channel{}
+	err := router.DB.SelectContext(ctx, &channels, "SELECT id, channel_name, recording_uid, recording_sid, recording_rid, recording_mode, organization_id, area FROM channels WHERE recording_sid IS NOT NULL")
+	if err != nil {
+		return err
+	}
+
+	emptySince, err := router.emptyRecordings(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stillEmpty := map[string]time.Time{}
+
+	// keepEmpty carries the time a channel was first seen empty over to the next run when this run could not tell
+	// whether it still is, so that errors of Agora do not restart the timeout
+	keepEmpty := func(sid string) {
+		if since, ok := emptySince[sid]; ok {
+			stillEmpty[sid] = since
+		}
+	}
+
+	for _, channel := range channels {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		project, err := OrganizationProject(ctx, router.DB, channel.OrganizationID)
+		if err != nil {
+			router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Msg("Could not fetch Agora project")
+			keepEmpty(channel.RecordingSID.String)
+			continue
+		}
+
+		recorder := &utils.Recorder{
+			Project: project,
+			Logger:  router.Logger,
+			Client:  router.Agora,
+			Channel: channel.ChannelName,
+			UID:     channel.RecordingUID.Int32,
+			RID:     channel.RecordingRID.String,
+			SID:     channel.RecordingSID.String,
+			Mode:    channel.RecordingMode,
+			Area:    utils.ChannelArea(&channel),
+		}
+
+		_, err = router.Recorder.Query(recorder)
+		if err == utils.ErrRecordingNotFound {
+			router.Logger.Info().Str("channel", channel.ChannelName).Str("sid", recorder.SID).Msg("Clearing recording that is no longer running")
+			err = router.clearRecording(ctx, channel.ChannelName, recorder.SID, RecordingExited)
+			if err != nil {
+				router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Msg("Could not clear recording")
+			}
+			continue
+		}
+
+		if err != nil {
+			router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Str("sid", recorder.SID).Msg("Could not query recording")
+			keepEmpty(recorder.SID)
+			continue
+		}
+
+		users, err := router.Agora.GetChannelUsers(project, channel.ChannelName)
+		if err != nil {
+			router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Msg("Could not fetch channel users")
+			keepEmpty(recorder.SID)
+			continue
+		}
+
+		if users.Participants(recorder.UID) > 0 {
+			continue
+		}
+
+		since, ok := emptySince[recorder.SID]
+		if !ok {
+			since = now
+		}
+
+		if now.Sub(since) < emptyTimeout {
+			stillEmpty[recorder.SID] = since
+			continue
+		}
+
+		router.Logger.Info().Str("channel", channel.ChannelName).Str("sid", recorder.SID).Time("empty since", since).Msg("Stopping recording on empty channel")
+		err = router.Recorder.Stop(recorder)
+		if err != nil && err != utils.ErrRecordingNotFound {
+			router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Msg("Could not stop recording")
+			stillEmpty[recorder.SID] = since
+			continue
+		}
+
+		err = router.clearRecording(ctx, channel.ChannelName, recorder.SID, RecordingStopped)
+		if err != nil {
+			router.Logger.Error().Err(err).Str("channel", channel.ChannelName).Msg("Could not clear recording")
+		}
+	}
+
+	return router.saveEmptyRecordings(ctx, stillEmpty)
+}
+
+// emptyRecordings maps the SIDs of the recordings whose channel was empty at the last run to the time it was first seen
+// empty
+func (router *ServiceRouter) emptyRecordings(ctx context.Context) (map[string]time.Time, error) {
+	var rows []struct {
+		SID        string    `db:"sid"`
+		EmptySince time.Time `db:"empty_since"`
+	}
+	err := router.DB.SelectContext(ctx, &rows, "SELECT sid, empty_since FROM empty_recordings")
+	if err != nil {
+		return nil, err
+	}
+
+	emptySince := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		emptySince[row.SID] = row.EmptySince
+	}
+
+	return emptySince, nil
+}
+
+// saveEmptyRecordings replaces the recordings whose channel is empty with those of emptySince
+func (router *ServiceRouter) saveEmptyRecordings(ctx context.Context, emptySince map[string]time.Time) error {
+	tx, err := router.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM empty_recordings")
+	if err != nil {
+		return err
+	}
+
+	for sid, since := range emptySince {
+		_, err = tx.ExecContext(ctx, "INSERT INTO empty_recordings (sid, empty_since) VALUES ($1, $2)", sid, since)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,71 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// Statuses of the recording of a channel
+const (
+	RecordingStarted = "started"
+	RecordingStopped = "stopped"
+	RecordingExited  = "exited"
+	// RecordingStarting is the status of a channel while a recording is being started on it, which is not published
+	RecordingStarting = "starting"
+)
+
+// recordingTopic is the topic that receives the changes of the recording of a channel
+func recordingTopic(channelID int64) string {
+	return "recording:" + strconv.FormatInt(channelID, 10)
+}
+
+// PublishRecordingUpdate sends a change of the recording of a channel to the subscribers of its recording status
+func PublishRecordingUpdate(pubSub utils.PubSub, channelID int64, status string, sid string) {
+	message, err := json.Marshal(models.RecordingUpdate{Status: status, Sid: sid, UpdatedAt: time.Now()})
+	if err != nil {
+		return
+	}
+
+	pubSub.Publish(recordingTopic(channelID), message)
+}
+
+// SubscribeRecordingUpdates returns the changes of the recording of a channel until ctx is done, after which the
+// channel is closed
+func SubscribeRecordingUpdates(ctx context.Context, pubSub utils.PubSub, channelID int64) <-chan *models.RecordingUpdate {
+	messages := pubSub.Subscribe(ctx, recordingTopic(channelID))
+
+	updates := make(chan *models.RecordingUpdate)
+	go func() {
+		defer close(updates)
+
+		for message := range messages {
+			var update models.RecordingUpdate
+			if err := json.Unmarshal(message, &update); err != nil {
+				continue
+			}
+
+			select {
+			case updates <- &update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
@@ -0,0 +1,61 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+func TestRecordingUpdatesOfChannel(t *testing.T) {
+	pubSub := utils.NewPubSub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := SubscribeRecordingUpdates(ctx, pubSub, 1)
+	PublishRecordingUpdate(pubSub, 2, RecordingStarted, "other")
+	PublishRecordingUpdate(pubSub, 1, RecordingStarted, "first")
+	PublishRecordingUpdate(pubSub, 1, RecordingExited, "first")
+
+	for _, want := range []string{RecordingStarted, RecordingExited} {
+		select {
+		case update := <-updates:
+			if update.Status != want || update.Sid != "first" {
+				t.Fatalf("got %s of %s, want %s of first", update.Status, update.Sid, want)
+			}
+			if update.UpdatedAt.IsZero() {
+				t.Fatal("update has no time")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s update", want)
+		}
+	}
+}
+
+func TestRecordingUpdatesEndWithContext(t *testing.T) {
+	pubSub := utils.NewPubSub()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates := SubscribeRecordingUpdates(ctx, pubSub, 1)
+	cancel()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("got an update after the subscription ended")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("updates were not closed")
+	}
+}
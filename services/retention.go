@@ -0,0 +1,80 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"path"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// DeleteExpiredRecordings removes the recordings whose retention window has passed from storage and the database.
+// The retention of a channel overrides RECORDING_RETENTION_DAYS and a retention of 0 days keeps recordings forever,
+// unless the recordings were scheduled for deletion when the owner of the channel deleted its account. It runs as a
+// background job every RECORDING_RETENTION_INTERVAL_MINUTES
+func (router *ServiceRouter) DeleteExpiredRecordings(ctx context.Context) error {
+	recordings := []models.ChannelRecording{}
+	err := router.DB.SelectContext(ctx, &recordings, `SELECT recordings.id, recordings.channel_id, recordings.sid, recordings.file_name FROM recordings
+		INNER JOIN channels ON channels.id = recordings.channel_id
+		WHERE recordings.delete_after <= NOW() OR (COALESCE(channels.recording_retention_days, $1) > 0
+		AND recordings.created_at < NOW() - COALESCE(channels.recording_retention_days, $1) * INTERVAL '1 day')`,
+		viper.GetInt("RECORDING_RETENTION_DAYS"))
+	if err != nil {
+		return err
+	}
+
+	storages := map[int64]utils.StorageProvider{}
+	deletedPrefixes := map[string]bool{}
+	for _, recording := range recordings {
+		storage, ok := storages[recording.ChannelID]
+		if !ok {
+			storage, err = ChannelStorage(ctx, router.DB, recording.ChannelID)
+			if err != nil {
+				router.Logger.Error().Err(err).Int64("Channel ID", recording.ChannelID).Msg("Could not create storage provider")
+				continue
+			}
+			storages[recording.ChannelID] = storage
+		}
+
+		prefix := recordingPrefix(recording)
+		if !deletedPrefixes[prefix] {
+			count, err := storage.DeleteObjects(prefix)
+			if err != nil {
+				router.Logger.Error().Err(err).Str("prefix", prefix).Int("deleted", count).Msg("Could not delete expired recording objects")
+				continue
+			}
+
+			router.Logger.Info().Str("prefix", prefix).Int("deleted", count).Msg("Deleted expired recording objects")
+			deletedPrefixes[prefix] = true
+		}
+
+		_, err = router.DB.ExecContext(ctx, "DELETE FROM recordings WHERE id = $1", recording.ID)
+		if err != nil {
+			router.Logger.Error().Err(err).Int64("Recording ID", recording.ID).Msg("Could not delete expired recording")
+		}
+	}
+
+	return nil
+}
+
+// recordingPrefix returns the prefix shared by every object of the recording session the file belongs to,
+// including HLS segments which are not reported individually
+func recordingPrefix(recording models.ChannelRecording) string {
+	directory := path.Dir(recording.FileName)
+	if directory == "." {
+		return recording.SID
+	}
+
+	return directory + "/" + recording.SID
+}
@@ -0,0 +1,203 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+const refreshTokenPrefix = "abr_"
+
+// ErrInvalidRefreshToken is returned when a refresh token does not exist, has expired or has been revoked
+var ErrInvalidRefreshToken = errors.New("Invalid refresh token")
+
+// ErrRefreshTokenReused is returned when a refresh token is used a second time, which means it was stolen by either
+// the caller or the client that used it first. Every token of its family is revoked
+var ErrRefreshTokenReused = errors.New("Refresh token has already been used")
+
+// AuthTokens are the tokens a client authenticates with. The access token is sent as a bearer token until it
+// expires, after which the refresh token is exchanged for new tokens
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// issueTokens stores a new access token and refresh token for a user in a family of tokens
+func issueTokens(ctx context.Context, repos *repository.Repositories, userID int64, familyID string) (*AuthTokens, error) {
+	accessToken, err := utils.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.GenerateSecret(refreshTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(viper.GetInt("ACCESS_TOKEN_EXPIRY_MINUTES")) * time.Minute),
+	}
+
+	err = repos.Tokens.Create(ctx, accessToken, userID, tokens.ExpiresAt, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiry := time.Now().Add(time.Duration(viper.GetInt("REFRESH_TOKEN_EXPIRY_DAYS")) * 24 * time.Hour)
+	err = repos.Tokens.CreateRefresh(ctx, userID, familyID, utils.HashSecret(refreshToken), refreshExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreateAuthSession issues the tokens of a new sign in of a user, with repositories bound to the transaction of the
+// sign in
+func CreateAuthSession(ctx context.Context, repos *repository.Repositories, userID int64) (*AuthTokens, error) {
+	familyID, err := utils.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	return issueTokens(ctx, repos, userID, familyID)
+}
+
+// RefreshAuthSession exchanges a refresh token for new tokens. The refresh token can only be used once and using it
+// again revokes the tokens that were issued in exchange for it, as well as every other token of the sign in
+func RefreshAuthSession(ctx context.Context, db *models.Database, repos *repository.Repositories, refreshToken string) (*AuthTokens, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txRepos := repos.WithTx(tx)
+
+	stored, err := txRepos.Tokens.LockRefresh(ctx, utils.HashSecret(refreshToken))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt.Valid || stored.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt.Valid {
+		err = txRepos.Tokens.RevokeFamily(ctx, stored.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	err = txRepos.Tokens.MarkRefreshUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := issueTokens(ctx, txRepos, stored.UserID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeAuthSession signs a user out of the sign in an access token belongs to, deleting the access token and
+// revoking the refresh tokens issued with it. It reports whether the access token existed
+func RevokeAuthSession(ctx context.Context, db *models.Database, repos *repository.Repositories, userID int64, accessToken string) (bool, error) {
+	return revokeToken(ctx, db, repos, func(tokens *repository.TokenRepo) (sql.NullString, error) {
+		return tokens.Delete(ctx, accessToken, userID)
+	})
+}
+
+// RevokeAuthSessionByID is RevokeAuthSession for the ID of the access token, which is how sessions are listed
+func RevokeAuthSessionByID(ctx context.Context, db *models.Database, repos *repository.Repositories, userID int64, tokenID int64) (bool, error) {
+	return revokeToken(ctx, db, repos, func(tokens *repository.TokenRepo) (sql.NullString, error) {
+		return tokens.DeleteByID(ctx, tokenID, userID)
+	})
+}
+
+// revokeToken deletes an access token with remove, which returns its family, and revokes the family
+func revokeToken(ctx context.Context, db *models.Database, repos *repository.Repositories, remove func(*repository.TokenRepo) (sql.NullString, error)) (bool, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	txRepos := repos.WithTx(tx)
+
+	familyID, err := remove(txRepos.Tokens)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if familyID.Valid {
+		err = txRepos.Tokens.RevokeFamily(ctx, familyID.String)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// RevokeAllAuthSessions signs a user out everywhere, deleting every access token and revoking every refresh token
+// of the user. It returns the number of access tokens that were deleted
+func RevokeAllAuthSessions(ctx context.Context, db *models.Database, repos *repository.Repositories, userID int64) (int64, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	deleted, err := RevokeUserSessions(ctx, repos.WithTx(tx), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+// RevokeUserSessions is RevokeAllAuthSessions as part of a transaction, with repositories bound to it
+func RevokeUserSessions(ctx context.Context, repos *repository.Repositories, userID int64) (int64, error) {
+	return repos.Tokens.DeleteForUser(ctx, userID)
+}
@@ -0,0 +1,108 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// Short links start with shortLinkLength characters and grow up to maxShortLinkLength when slugs collide
+const (
+	shortLinkLength    = 6
+	maxShortLinkLength = 8
+)
+
+// shortLinkValid selects short links whose channel has not ended and is not more than SHORT_LINK_GRACE_HOURS past
+// its scheduled end
+const shortLinkValid = "channels.ended_at IS NULL AND (channels.ends_at IS NULL OR channels.ends_at + MAKE_INTERVAL(hours => $2) > NOW())"
+
+// ShortLinkURL returns the short link of a slug, or an empty string when short links cannot be resolved because
+// FRONTEND_URL is not set. Links are served from SHORT_LINK_URL, which defaults to BACKEND_URL
+func ShortLinkURL(slug string) string {
+	base := viper.GetString("SHORT_LINK_URL")
+	if base == "" {
+		base = viper.GetString("BACKEND_URL")
+	}
+
+	if base == "" || viper.GetString("FRONTEND_URL") == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(base, "/") + "/s/" + slug
+}
+
+// CreateShortLink returns the slug of the short link to a passphrase of a channel, creating it when the passphrase
+// has none. Passphrases that are rotated get new short links, so that the old links stop working
+func CreateShortLink(ctx context.Context, db sqlx.QueryerContext, channelID int64, passphrase string) (string, error) {
+	var slug string
+	err := sqlx.GetContext(ctx, db, &slug, "SELECT slug FROM short_links WHERE channel_id = $1 AND passphrase = $2", channelID, passphrase)
+	if err != sql.ErrNoRows {
+		return slug, err
+	}
+
+	// Conflicting slugs are skipped rather than failing, so that the transaction the link is created in can go on
+	for length := shortLinkLength; length <= maxShortLinkLength; length++ {
+		slug, err = utils.GenerateSlug(length)
+		if err != nil {
+			return "", err
+		}
+
+		err = sqlx.GetContext(ctx, db, &slug, "INSERT INTO short_links (slug, channel_id, passphrase) VALUES ($1, $2, $3) ON CONFLICT (slug) DO NOTHING RETURNING slug", slug, channelID, passphrase)
+		if err != sql.ErrNoRows {
+			return slug, err
+		}
+	}
+
+	return "", errors.New("Could not generate a unique short link")
+}
+
+// ShortLinkRedirect is a REST route that redirects a short link to the join link of its passphrase. Links of
+// channels that have ended, and of passphrases that have been rotated, are gone
+func (router *ServiceRouter) ShortLinkRedirect(w http.ResponseWriter, r *http.Request) {
+	if viper.GetString("FRONTEND_URL") == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var link struct {
+		Passphrase string `db:"passphrase"`
+		Valid      bool   `db:"valid"`
+	}
+	err := router.DB.GetContext(r.Context(), &link, "SELECT short_links.passphrase, "+shortLinkValid+" AS valid FROM short_links "+
+		"INNER JOIN channels ON channels.id = short_links.channel_id "+
+		"INNER JOIN channel_passphrases ON channel_passphrases.channel_id = short_links.channel_id AND channel_passphrases.passphrase = short_links.passphrase "+
+		"WHERE short_links.slug = $1", mux.Vars(r)["slug"], viper.GetInt("SHORT_LINK_GRACE_HOURS"))
+	if err == sql.ErrNoRows {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		router.Logger.Error().Err(err).Str("slug", mux.Vars(r)["slug"]).Msg("Could not resolve short link")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !link.Valid {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	http.Redirect(w, r, strings.TrimSuffix(viper.GetString("FRONTEND_URL"), "/")+"/"+link.Passphrase, http.StatusFound)
+}
@@ -0,0 +1,29 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// SIPURI returns the URI SIP room systems dial to join the conference with the given ID through the PSTN gateway.
+// The gateway answers SIP calls on SIP_DOMAIN, so no URI is returned when it is not set
+func SIPURI(confID string) string {
+	domain := strings.TrimSpace(viper.GetString("SIP_DOMAIN"))
+	if domain == "" || confID == "" {
+		return ""
+	}
+
+	return "sip:" + url.PathEscape(confID) + "@" + domain
+}
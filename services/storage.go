@@ -0,0 +1,98 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+)
+
+// SaveChannelStorage encrypts the credentials of a bucket supplied by a host and stores it for the channel
+func SaveChannelStorage(ctx context.Context, db sqlx.ExecerContext, channelID int64, settings utils.StorageSettings) error {
+	accessKey, err := utils.Encrypt(settings.AccessKey)
+	if err != nil {
+		return err
+	}
+
+	secretKey, err := utils.Encrypt(settings.SecretKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO channel_storage (channel_id, provider, region, bucket, access_key, secret_key) VALUES ($1, $2, $3, $4, $5, $6)",
+		channelID, settings.Provider, settings.Region, settings.Bucket, accessKey, secretKey)
+	return err
+}
+
+// SaveOrganizationStorage encrypts the credentials of a bucket supplied by an organization and stores it, replacing
+// the bucket the organization had before
+func SaveOrganizationStorage(ctx context.Context, db sqlx.ExecerContext, organizationID int64, settings utils.StorageSettings) error {
+	accessKey, err := utils.Encrypt(settings.AccessKey)
+	if err != nil {
+		return err
+	}
+
+	secretKey, err := utils.Encrypt(settings.SecretKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO organization_storage (organization_id, provider, region, bucket, access_key, secret_key) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE SET provider = EXCLUDED.provider, region = EXCLUDED.region, bucket = EXCLUDED.bucket,
+		access_key = EXCLUDED.access_key, secret_key = EXCLUDED.secret_key, created_at = CURRENT_TIMESTAMP`,
+		organizationID, settings.Provider, settings.Region, settings.Bucket, accessKey, secretKey)
+	return err
+}
+
+// ChannelStorage returns the storage that recordings of a channel are uploaded to. Channels without their own
+// bucket use the bucket of their organization, and otherwise the storage configured for the deployment, which is the
+// only storage on databases other than Postgres
+func ChannelStorage(ctx context.Context, db *models.Database, channelID int64) (utils.StorageProvider, error) {
+	if !db.Postgres() {
+		return utils.GlobalStorageProvider()
+	}
+
+	var storage models.ChannelStorage
+	err := db.GetContext(ctx, &storage, `SELECT channel_id, provider, region, bucket, access_key, secret_key FROM channel_storage WHERE channel_id = $1
+		UNION ALL SELECT channels.id AS channel_id, organization_storage.provider, organization_storage.region, organization_storage.bucket,
+		organization_storage.access_key, organization_storage.secret_key FROM organization_storage
+		INNER JOIN channels ON channels.organization_id = organization_storage.organization_id WHERE channels.id = $1
+		LIMIT 1`, channelID)
+	if err == sql.ErrNoRows {
+		return utils.GlobalStorageProvider()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, err := utils.Decrypt(storage.AccessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey, err := utils.Decrypt(storage.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.NewStorageProvider(utils.StorageSettings{
+		Provider:  storage.Provider,
+		Region:    storage.Region,
+		Bucket:    storage.Bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	})
+}
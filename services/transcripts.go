@@ -0,0 +1,169 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// transcriptStaleAfter is how long a transcript can be processing before it is assumed the server processing it
+// stopped and it is picked up again
+const transcriptStaleAfter = time.Hour
+
+// queueTranscript queues a recording session for transcription once its files have been uploaded
+func (router *ServiceRouter) queueTranscript(ctx context.Context, payload NCSPayload) error {
+	if viper.GetString("STT_PROVIDER") == "" {
+		return nil
+	}
+
+	_, err := router.DB.ExecContext(ctx, `INSERT INTO recording_transcripts (channel_id, sid, status)
+		SELECT id, $2, $3 FROM channels WHERE channel_name = $1
+		ON CONFLICT (sid) DO NOTHING`, payload.Cname, payload.SID, models.TranscriptPending)
+	return err
+}
+
+// TranscribeRecordings transcribes every queued recording one at a time with the provider in STT_PROVIDER. Transcripts
+// are claimed with row locks so that several servers can share the queue
+func (router *ServiceRouter) TranscribeRecordings(ctx context.Context) error {
+	stt, err := utils.NewSpeechToText()
+	if err != nil || stt == nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		var transcript models.ChannelTranscript
+		err = router.DB.GetContext(ctx, &transcript, `UPDATE recording_transcripts SET status = $1, updated_at = NOW() WHERE id = (
+			SELECT id FROM recording_transcripts WHERE status = $2 OR (status = $1 AND updated_at < NOW() - $3 * INTERVAL '1 second')
+			ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+			RETURNING id, created_at, updated_at, channel_id, sid, status, error`,
+			models.TranscriptProcessing, models.TranscriptPending, int(transcriptStaleAfter.Seconds()))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		err = router.transcribe(ctx, stt, transcript)
+		if err != nil {
+			router.Logger.Error().Err(err).Str("sid", transcript.SID).Msg("Could not transcribe recording")
+
+			_, err = router.DB.ExecContext(ctx, "UPDATE recording_transcripts SET status = $1, error = $2, updated_at = NOW() WHERE id = $3", models.TranscriptFailed, err.Error(), transcript.ID)
+			if err != nil {
+				router.Logger.Error().Err(err).Str("sid", transcript.SID).Msg("Could not update recording transcript")
+			}
+			continue
+		}
+
+		router.Logger.Info().Str("sid", transcript.SID).Msg("Transcribed recording")
+	}
+
+	return ctx.Err()
+}
+
+// transcribe transcribes the MP4 files of a recording session in order and stores the segments, timed from the start
+// of the first file
+func (router *ServiceRouter) transcribe(ctx context.Context, stt utils.SpeechToText, transcript models.ChannelTranscript) error {
+	recordings := []models.ChannelRecording{}
+	err := router.DB.SelectContext(ctx, &recordings, `SELECT id, created_at, channel_id, sid, file_name, track_type, uid, is_playable, slice_start_time FROM recordings
+		WHERE sid = $1 AND file_name LIKE '%.mp4' ORDER BY slice_start_time, id`, transcript.SID)
+	if err != nil {
+		return err
+	}
+
+	if len(recordings) == 0 {
+		return errors.New("Recording has no MP4 files")
+	}
+
+	storage, err := ChannelStorage(ctx, router.DB, transcript.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	segments := []models.ChannelTranscriptSegment{}
+	for _, recording := range recordings {
+		signedURL, err := storage.PresignURL(recording.FileName, time.Hour)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return fmt.Errorf("Downloading %s failed with status %d", recording.FileName, resp.StatusCode)
+		}
+
+		fileSegments, err := stt.Transcribe(resp.Body, path.Base(recording.FileName))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		offset := recording.SliceStartTime.Int64 - recordings[0].SliceStartTime.Int64
+		for _, segment := range fileSegments {
+			if strings.TrimSpace(segment.Text) == "" {
+				continue
+			}
+
+			segments = append(segments, models.ChannelTranscriptSegment{
+				TranscriptID: transcript.ID,
+				StartMs:      offset + segment.Start.Milliseconds(),
+				EndMs:        offset + segment.End.Milliseconds(),
+				Text:         segment.Text,
+			})
+		}
+	}
+
+	tx, err := router.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM recording_transcript_segments WHERE transcript_id = $1", transcript.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(segments) > 0 {
+		_, err = tx.NamedExecContext(ctx, "INSERT INTO recording_transcript_segments (transcript_id, start_ms, end_ms, text) VALUES (:transcript_id, :start_ms, :end_ms, :text)", segments)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE recording_transcripts SET status = $1, error = NULL, updated_at = NOW() WHERE id = $2", models.TranscriptDone, transcript.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
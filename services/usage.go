@@ -0,0 +1,162 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// quotaKeys are the configuration keys of the monthly quota of each metric
+var quotaKeys = map[models.UsageMetric]string{
+	models.UsageChannels:           "QUOTA_CHANNELS_PER_MONTH",
+	models.UsageRecordingMinutes:   "QUOTA_RECORDING_MINUTES_PER_MONTH",
+	models.UsageParticipantMinutes: "QUOTA_PARTICIPANT_MINUTES_PER_MONTH",
+	models.UsagePSTNCalls:          "QUOTA_PSTN_CALLS_PER_MONTH",
+}
+
+// UsageSubject is who usage counts towards: the organization of a channel, or its owner when the channel is not in
+// an organization. Channels created without signing in have no subject
+type UsageSubject struct {
+	UserID         sql.NullInt64
+	OrganizationID sql.NullInt64
+}
+
+// ChannelUsageSubject returns who the usage of a channel counts towards
+func ChannelUsageSubject(channel *models.Channel) UsageSubject {
+	if channel.OrganizationID.Valid {
+		return UsageSubject{OrganizationID: channel.OrganizationID}
+	}
+
+	return UsageSubject{UserID: channel.OwnerID}
+}
+
+// Valid reports whether usage can be attributed to the subject
+func (subject UsageSubject) Valid() bool {
+	return subject.UserID.Valid || subject.OrganizationID.Valid
+}
+
+// Quota returns the default monthly quota of a metric, for subjects that are not subscribed to a plan. It is 0 when
+// the metric is unlimited
+func Quota(metric models.UsageMetric) float64 {
+	return viper.GetFloat64(quotaKeys[metric])
+}
+
+// MonthStart returns the start of the month, in UTC, that usage at the given time is metered in
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageTotals sums the usage of a subject between start and end by metric
+func UsageTotals(ctx context.Context, db sqlx.QueryerContext, subject UsageSubject, start time.Time, end time.Time) (map[models.UsageMetric]float64, error) {
+	var totals []struct {
+		Metric   models.UsageMetric `db:"metric"`
+		Quantity float64            `db:"quantity"`
+	}
+	err := sqlx.SelectContext(ctx, db, &totals, `SELECT metric, SUM(quantity) AS quantity FROM usage_records
+		WHERE (CASE WHEN $1::INT IS NULL THEN user_id = $2 AND organization_id IS NULL ELSE organization_id = $1 END)
+		AND occurred_at >= $3 AND occurred_at < $4 GROUP BY metric`,
+		subject.OrganizationID, subject.UserID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[models.UsageMetric]float64{}
+	for _, total := range totals {
+		result[total.Metric] = total.Quantity
+	}
+
+	return result, nil
+}
+
+// QuotaExceeded reports whether the subject has used up its quota of a metric for the current month, which is set by
+// the plan it is subscribed to. Usage that has not been metered yet, like a recording that is still running, is not
+// counted
+func QuotaExceeded(ctx context.Context, db sqlx.QueryerContext, subject UsageSubject, metric models.UsageMetric) (bool, error) {
+	if !subject.Valid() {
+		return false, nil
+	}
+
+	plan, err := ActivePlan(ctx, db, subject)
+	if err != nil {
+		return false, err
+	}
+
+	quota := PlanQuota(plan, metric)
+	if quota <= 0 {
+		return false, nil
+	}
+
+	start := MonthStart(time.Now())
+	totals, err := UsageTotals(ctx, db, subject, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return false, err
+	}
+
+	return totals[metric] >= quota, nil
+}
+
+// MeterChannel records the creation of a channel
+func MeterChannel(ctx context.Context, db sqlx.ExecerContext, channel *models.Channel) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO usage_records (user_id, organization_id, channel_id, metric, quantity, source)
+		VALUES ($1, $2, $3, $4, 1, 'channel:' || $3::TEXT) ON CONFLICT (metric, source) DO NOTHING`,
+		channel.OwnerID, channel.OrganizationID, channel.ID, models.UsageChannels)
+	return err
+}
+
+// MeterRecording records the minutes of the recording session with the given SID. It has to be called before the
+// recording is cleared from its channel, and a session is only metered once however often it is called
+func MeterRecording(ctx context.Context, db sqlx.ExecerContext, sid string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO usage_records (user_id, organization_id, channel_id, metric, quantity, source)
+		SELECT owner_id, organization_id, id, $2, EXTRACT(EPOCH FROM NOW() - recording_started_at) / 60, 'recording:' || recording_sid
+		FROM channels WHERE recording_sid = $1 AND recording_started_at IS NOT NULL
+		ON CONFLICT (metric, source) DO NOTHING`,
+		sid, models.UsageRecordingMinutes)
+	return err
+}
+
+// MeterPSTNCall records a call placed to a phone number from a channel
+func MeterPSTNCall(ctx context.Context, db sqlx.ExecerContext, channel *models.Channel, callID string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO usage_records (user_id, organization_id, channel_id, metric, quantity, source)
+		VALUES ($1, $2, $3, $4, 1, 'pstn:' || $5) ON CONFLICT (metric, source) DO NOTHING`,
+		channel.OwnerID, channel.OrganizationID, channel.ID, models.UsagePSTNCalls, callID)
+	return err
+}
+
+// MeterAttendance records the participant minutes of every stay in a channel that has ended since the last run. It
+// runs as a background job every USAGE_METERING_INTERVAL_MINUTES
+func (router *ServiceRouter) MeterAttendance(ctx context.Context) error {
+	result, err := router.DB.ExecContext(ctx, `WITH metered AS (
+			UPDATE attendance SET metered_at = NOW() WHERE left_at IS NOT NULL AND metered_at IS NULL
+			RETURNING id, channel_id, joined_at, left_at
+		)
+		INSERT INTO usage_records (occurred_at, user_id, organization_id, channel_id, metric, quantity, source)
+		SELECT metered.left_at, channels.owner_id, channels.organization_id, channels.id, $1,
+		EXTRACT(EPOCH FROM metered.left_at - metered.joined_at) / 60, 'attendance:' || metered.id
+		FROM metered INNER JOIN channels ON channels.id = metered.channel_id
+		ON CONFLICT (metric, source) DO NOTHING`, models.UsageParticipantMinutes)
+	if err != nil {
+		return err
+	}
+
+	metered, err := result.RowsAffected()
+	if err == nil && metered > 0 {
+		router.Logger.Info().Int64("stays", metered).Msg("Metered participant minutes")
+	}
+
+	return nil
+}
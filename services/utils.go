@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/go-redis/redis/v8"
 	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/pkg/repository"
 	"github.com/samyak-jain/agora_backend/utils"
 	"github.com/spf13/viper"
 )
@@ -28,6 +30,16 @@ import (
 type ServiceRouter struct {
 	DB     *models.Database
 	Logger *utils.Logger
+	// Redis is nil when REDIS_URL is not set
+	Redis *redis.Client
+	// Recorder is the cloud recorder the recordings of channels are made with
+	Recorder utils.CloudRecorder
+	// Agora sends the requests to the Agora REST APIs
+	Agora *utils.AgoraClient
+	// Repos runs the statements on the tables that have repositories
+	Repos *repository.Repositories
+	// PubSub delivers the changes of recordings to the subscribers of their status
+	PubSub utils.PubSub
 }
 
 // AllowListValidator takes an email and searches the Allow List for a match
@@ -0,0 +1,142 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils"
+	"github.com/spf13/viper"
+)
+
+// webhookLease is how long a claimed delivery is kept from other servers while it is being sent
+const webhookLease = time.Minute
+
+// maxWebhookBackoff caps the time between two attempts to deliver an event
+const maxWebhookBackoff = 6 * time.Hour
+
+// QueueChannelEvent queues an event of a channel for delivery to the webhooks subscribed to it and, when EVENT_BUS is
+// set, to the event bus outbox. Data is sent as the data field of the event, next to the channel it happened in.
+// Queueing in the transaction of the change the event is about keeps events from being lost or sent for changes that
+// were rolled back
+func QueueChannelEvent(ctx context.Context, db sqlx.ExecerContext, channelName string, event string, data interface{}) error {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := utils.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `WITH event AS (
+			SELECT channels.id, channels.owner_id, channels.organization_id, jsonb_build_object('id', $2::TEXT, 'type', $3::TEXT,
+			'createdAt', NOW(), 'channel', jsonb_build_object('id', channels.id::TEXT, 'title', channels.title, 'organizationId', channels.organization_id::TEXT),
+			'data', $4::JSONB) AS payload
+			FROM channels WHERE channels.channel_name = $1
+		), outbox AS (
+			INSERT INTO event_outbox (event_id, event, channel_id, payload) SELECT $2, $3, event.id, event.payload FROM event WHERE $5
+		)
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+		SELECT webhooks.id, $2, $3, event.payload FROM event INNER JOIN webhooks ON $3 = ANY(webhooks.events)
+		LEFT JOIN api_keys ON api_keys.id = webhooks.api_key_id
+		WHERE (CASE WHEN event.organization_id IS NULL
+			THEN api_keys.user_id = event.owner_id AND api_keys.revoked_at IS NULL
+			ELSE webhooks.organization_id = event.organization_id END)`,
+		channelName, eventID, event, string(encoded), viper.GetString("EVENT_BUS") != "")
+	return err
+}
+
+// DeliverWebhooks sends the queued webhook events that are due and drops the delivery log older than
+// WEBHOOK_LOG_RETENTION_DAYS
+func (router *ServiceRouter) DeliverWebhooks(ctx context.Context) error {
+	for ctx.Err() == nil && router.DeliverWebhook(ctx) {
+	}
+
+	_, err := router.DB.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE next_attempt_at IS NULL AND created_at < NOW() - $1 * INTERVAL '1 day'",
+		viper.GetInt("WEBHOOK_LOG_RETENTION_DAYS"))
+	return err
+}
+
+// DeliverWebhook makes the next due attempt to deliver an event to a webhook. Deliveries are claimed by pushing back
+// their next attempt, so that several servers can share the queue and deliveries of a server that stops are retried.
+// Failed attempts are retried with exponential backoff until WEBHOOK_MAX_ATTEMPTS. It reports whether a delivery was
+// due
+func (router *ServiceRouter) DeliverWebhook(ctx context.Context) bool {
+	var delivery struct {
+		models.WebhookDeliveryLog
+		URL    string `db:"url"`
+		Secret string `db:"secret"`
+	}
+	err := router.DB.GetContext(ctx, &delivery, `UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = NOW() + $1 * INTERVAL '1 second'
+		FROM webhooks WHERE webhooks.id = webhook_deliveries.webhook_id AND webhook_deliveries.id = (
+			SELECT id FROM webhook_deliveries WHERE next_attempt_at <= NOW() ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event_id, webhook_deliveries.event,
+		webhook_deliveries.payload, webhook_deliveries.attempts, webhooks.url, webhooks.secret`,
+		int(webhookLease.Seconds()))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			router.Logger.Error().Err(err).Msg("Could not claim webhook delivery")
+		}
+		return false
+	}
+
+	secret, err := utils.Decrypt(delivery.Secret)
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("webhook", delivery.WebhookID).Msg("Could not decrypt webhook secret")
+		return true
+	}
+
+	status, err := utils.PostWebhook(delivery.URL, secret, delivery.EventID, delivery.Event, []byte(delivery.Payload))
+	lastStatus := sql.NullInt32{Int32: int32(status), Valid: status != 0}
+	if err == nil {
+		_, err = router.DB.ExecContext(ctx, "UPDATE webhook_deliveries SET delivered_at = NOW(), next_attempt_at = NULL, last_status = $1, last_error = NULL WHERE id = $2",
+			lastStatus, delivery.ID)
+		if err != nil {
+			router.Logger.Error().Err(err).Int64("delivery", delivery.ID).Msg("Could not mark webhook delivery as delivered")
+		}
+		return true
+	}
+
+	router.Logger.Info().Err(err).Int64("delivery", delivery.ID).Int64("webhook", delivery.WebhookID).Int("attempts", delivery.Attempts).Msg("Webhook delivery failed")
+
+	if delivery.Attempts >= viper.GetInt("WEBHOOK_MAX_ATTEMPTS") {
+		_, err = router.DB.ExecContext(ctx, "UPDATE webhook_deliveries SET failed_at = NOW(), next_attempt_at = NULL, last_status = $1, last_error = $2 WHERE id = $3",
+			lastStatus, err.Error(), delivery.ID)
+	} else {
+		backoff := time.Duration(viper.GetInt("WEBHOOK_RETRY_SECONDS")) * time.Second
+		for attempt := 1; attempt < delivery.Attempts && backoff < maxWebhookBackoff; attempt++ {
+			backoff *= 2
+		}
+		if backoff > maxWebhookBackoff {
+			backoff = maxWebhookBackoff
+		}
+
+		_, err = router.DB.ExecContext(ctx, "UPDATE webhook_deliveries SET next_attempt_at = NOW() + $1 * INTERVAL '1 second', last_status = $2, last_error = $3 WHERE id = $4",
+			int(backoff.Seconds()), lastStatus, err.Error(), delivery.ID)
+	}
+	if err != nil {
+		router.Logger.Error().Err(err).Int64("delivery", delivery.ID).Msg("Could not update webhook delivery")
+	}
+
+	return true
+}
@@ -0,0 +1,41 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CheckAgoraCredentials verifies that the customer credentials of a project are accepted by the Agora RESTful API by
+// listing the projects of the account
+func (c *AgoraClient) CheckAgoraCredentials(ctx context.Context, project *AgoraProject) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.agora.io/dev/v1/projects", nil)
+	if err != nil {
+		return err
+	}
+
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Agora RESTful API responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
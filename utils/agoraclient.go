@@ -0,0 +1,254 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ErrAgoraUnavailable is returned for requests to the Agora REST APIs while Agora keeps failing, without sending them
+var ErrAgoraUnavailable = errors.New("Agora is unavailable")
+
+// maxAgoraBackoff caps the time between two attempts of a request to Agora
+const maxAgoraBackoff = 5 * time.Second
+
+// circuit counts the consecutive failures of requests to a host, and is open while requests to it fail fast
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+// AgoraClient sends requests to the Agora REST APIs, retrying the ones that can safely be sent again and failing fast
+// while a host keeps failing
+type AgoraClient struct {
+	mu sync.Mutex
+	// circuits holds the circuit of every host requests were sent to
+	circuits map[string]*circuit
+}
+
+// NewAgoraClient creates a client with every circuit closed. The server creates one, which is shared by everything that
+// calls Agora so that they see the same circuits
+func NewAgoraClient() *AgoraClient {
+	return &AgoraClient{circuits: map[string]*circuit{}}
+}
+
+// allowRequest reports whether a request can be sent to host. Once the cooldown of an open circuit is over, a single
+// request is let through to probe whether the host recovered while the others keep failing fast
+func (c *AgoraClient) allowRequest(host string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.circuits[host]
+	if !ok || state.failures < viper.GetInt("AGORA_CIRCUIT_FAILURES") {
+		return true
+	}
+
+	now := time.Now()
+	if now.Before(state.openUntil) {
+		return false
+	}
+
+	state.openUntil = now.Add(time.Duration(viper.GetInt("AGORA_CIRCUIT_COOLDOWN_SECONDS")) * time.Second)
+	return true
+}
+
+// recordResult closes the circuit of host after a request succeeded, and opens it once enough requests failed in a row
+func (c *AgoraClient) recordResult(host string, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.circuits[host]
+	if !ok {
+		state = &circuit{}
+		c.circuits[host] = state
+	}
+
+	if !failed {
+		state.failures = 0
+		state.openUntil = time.Time{}
+		return
+	}
+
+	state.failures++
+	if state.failures >= viper.GetInt("AGORA_CIRCUIT_FAILURES") {
+		state.openUntil = time.Now().Add(time.Duration(viper.GetInt("AGORA_CIRCUIT_COOLDOWN_SECONDS")) * time.Second)
+	}
+}
+
+// idempotent reports whether sending a request more than once has the same effect as sending it once
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether an attempt of a request is worth retrying. Idempotent requests are retried after network
+// errors and transient statuses, when Agora is overloaded or briefly down. Other requests, like acquiring or starting
+// a recording, may have been carried out when their response was lost, so they are only retried when Agora refused
+// the connection or the request before handling it
+func retryable(method string, resp *http.Response, err error) bool {
+	if !idempotent(method) {
+		return errors.Is(err, syscall.ECONNREFUSED) || err == nil && resp.StatusCode == http.StatusTooManyRequests
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// agoraBackoff returns how long to wait before retrying a request that failed attempt times, with full jitter so that
+// servers retrying at once are spread out
+func agoraBackoff(attempt int) time.Duration {
+	backoff := time.Duration(viper.GetInt("AGORA_RETRY_BASE_MS")) * time.Millisecond
+	for i := 1; i < attempt && backoff < maxAgoraBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxAgoraBackoff {
+		backoff = maxAgoraBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// Do sends a request to an Agora REST API. Each attempt times out after AGORA_TIMEOUT_SECONDS, and attempts that are
+// retryable are retried up to AGORA_MAX_RETRIES times. Requests fail fast with ErrAgoraUnavailable while the circuit
+// of the host is open, which happens after AGORA_CIRCUIT_FAILURES requests to it failed in a row
+func (c *AgoraClient) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if !c.allowRequest(host) {
+		return nil, ErrAgoraUnavailable
+	}
+
+	client := &http.Client{Timeout: time.Duration(viper.GetInt("AGORA_TIMEOUT_SECONDS")) * time.Second}
+	retries := viper.GetInt("AGORA_MAX_RETRIES")
+	if req.Body != nil && req.GetBody == nil {
+		// The body cannot be sent again
+		retries = 0
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(agoraBackoff(attempt)):
+			}
+
+			req, err = rewind(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = client.Do(req)
+		if err != nil && req.Context().Err() != nil {
+			// The caller gave up on the request, which says nothing about Agora
+			return nil, err
+		}
+
+		if attempt >= retries || !retryable(req.Method, resp, err) {
+			break
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	c.recordResult(host, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAgoraUnavailable, err)
+	}
+
+	return resp, nil
+}
+
+// rewind copies a request with a fresh body, so that it can be sent again
+func rewind(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return retry, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry.Body = body
+	return retry, nil
+}
+
+// AgoraError is an error response of an Agora REST API, with the code and reason Agora gave for it
+type AgoraError struct {
+	// Operation is the request that failed, like "acquire"
+	Operation string
+	Status    int
+	Code      int
+	Reason    string
+}
+
+func (e *AgoraError) Error() string {
+	return fmt.Sprintf("Agora %s failed with status %d, code %d: %s", e.Operation, e.Status, e.Code, e.Reason)
+}
+
+// agoraErrorBody is how the Agora REST APIs describe errors. Some of them give the reason as message instead
+type agoraErrorBody struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// decodeAgoraResponse decodes a successful response of an Agora REST API into result. Other responses are returned as
+// an AgoraError for operation
+func decodeAgoraResponse(resp *http.Response, operation string, result interface{}) error {
+	if resp.StatusCode != http.StatusOK {
+		var body agoraErrorBody
+		json.NewDecoder(resp.Body).Decode(&body)
+
+		reason := body.Reason
+		if reason == "" {
+			reason = body.Message
+		}
+		if reason == "" {
+			reason = http.StatusText(resp.StatusCode)
+		}
+
+		return &AgoraError{Operation: operation, Status: resp.StatusCode, Code: body.Code, Reason: reason}
+	}
+
+	err := json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("Could not decode Agora %s response: %w", operation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"strings"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// agoraDomains are the domains of the Agora REST APIs that keep requests within an area. Areas without one of
+// their own use the global domain
+var agoraDomains = map[models.AgoraArea]string{
+	models.AgoraAreaNorthAmerica: "api-us-west-1.agora.io",
+	models.AgoraAreaEurope:       "api-eu-central-1.agora.io",
+	models.AgoraAreaAsia:         "api-ap-southeast-1.agora.io",
+	models.AgoraAreaJapan:        "api-ap-northeast-1.agora.io",
+	models.AgoraAreaIndia:        "api-ap-southeast-1.agora.io",
+	models.AgoraAreaChina:        "api-cn-east-1.sd-rtn.com",
+}
+
+// recordingRegions are the regions of cloud recording that recordings of an area are made in
+var recordingRegions = map[models.AgoraArea]string{
+	models.AgoraAreaNorthAmerica: "NA",
+	models.AgoraAreaEurope:       "EU",
+	models.AgoraAreaAsia:         "AP",
+	models.AgoraAreaJapan:        "AP",
+	models.AgoraAreaIndia:        "AP",
+	models.AgoraAreaChina:        "CN",
+}
+
+// DefaultArea returns the area configured with AGORA_AREA, or GLOBAL when it is not a valid area
+func DefaultArea() models.AgoraArea {
+	area := models.AgoraArea(strings.ToUpper(viper.GetString("AGORA_AREA")))
+	if !area.IsValid() {
+		return models.AgoraAreaGlobal
+	}
+
+	return area
+}
+
+// ChannelArea returns the area a channel is restricted to. The area set on the channel takes precedence over
+// AGORA_AREA
+func ChannelArea(channel *models.Channel) models.AgoraArea {
+	if channel != nil && channel.Area.Valid {
+		area := models.AgoraArea(channel.Area.String)
+		if area.IsValid() {
+			return area
+		}
+	}
+
+	return DefaultArea()
+}
+
+// agoraAPIURL returns the base URL of the Agora REST APIs for requests that have to stay within an area
+func agoraAPIURL(area models.AgoraArea) string {
+	domain, ok := agoraDomains[area]
+	if !ok {
+		domain = "api.agora.io"
+	}
+
+	return "https://" + domain
+}
@@ -0,0 +1,238 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CalendarInvite is a meeting added to the calendar of its host, who invites the attendees
+type CalendarInvite struct {
+	*MeetingEvent
+	Attendees []string
+}
+
+// description is the body of the calendar event, which starts with the join link
+func (invite *CalendarInvite) description() string {
+	lines := []string{}
+	if invite.URL != "" {
+		lines = append(lines, "Join: "+invite.URL)
+	}
+
+	if invite.Description != "" {
+		lines = append(lines, invite.Description)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Calendar creates events in the calendar of a user through the API of the provider they signed in with.
+// The client authenticates requests as the user
+type Calendar interface {
+	// Scope is the OAuth scope that grants access to the calendar
+	Scope() string
+	// CreateEvent adds the invite to the primary calendar and returns the ID of the event
+	CreateEvent(ctx context.Context, client *http.Client, invite *CalendarInvite) (string, error)
+	// UpdateEvent replaces the details of an event created before
+	UpdateEvent(ctx context.Context, client *http.Client, eventID string, invite *CalendarInvite) error
+	// CancelEvent deletes an event and notifies its attendees
+	CancelEvent(ctx context.Context, client *http.Client, eventID string) error
+}
+
+// Calendars are the calendars of the OAuth providers that have one
+var Calendars = map[string]Calendar{
+	"google":    &GoogleCalendar{},
+	"microsoft": &OutlookCalendar{},
+}
+
+// calendarRequest sends a JSON request to a calendar API and decodes the JSON response into result, when it is not nil
+func calendarRequest(ctx context.Context, client *http.Client, method string, endpoint string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %d: %s", method, endpoint, response.StatusCode, string(contents))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(contents, result)
+}
+
+// GoogleCalendar creates events with the Google Calendar API
+type GoogleCalendar struct{}
+
+const googleCalendarEvents = "https://www.googleapis.com/calendar/v3/calendars/primary/events"
+
+type googleEventTime struct {
+	DateTime string `json:"dateTime"`
+}
+
+type googleAttendee struct {
+	Email string `json:"email"`
+}
+
+type googleEvent struct {
+	ID          string           `json:"id,omitempty"`
+	Summary     string           `json:"summary"`
+	Description string           `json:"description"`
+	Location    string           `json:"location,omitempty"`
+	Start       googleEventTime  `json:"start"`
+	End         googleEventTime  `json:"end"`
+	Attendees   []googleAttendee `json:"attendees"`
+}
+
+func (c *GoogleCalendar) Scope() string {
+	return "https://www.googleapis.com/auth/calendar.events"
+}
+
+func (c *GoogleCalendar) event(invite *CalendarInvite) *googleEvent {
+	event := &googleEvent{
+		Summary:     invite.Title,
+		Description: invite.description(),
+		Location:    invite.URL,
+		Start:       googleEventTime{DateTime: invite.StartsAt.UTC().Format(time.RFC3339)},
+		End:         googleEventTime{DateTime: invite.EndsAt.UTC().Format(time.RFC3339)},
+		Attendees:   []googleAttendee{},
+	}
+
+	for _, attendee := range invite.Attendees {
+		event.Attendees = append(event.Attendees, googleAttendee{Email: attendee})
+	}
+
+	return event
+}
+
+func (c *GoogleCalendar) CreateEvent(ctx context.Context, client *http.Client, invite *CalendarInvite) (string, error) {
+	var created googleEvent
+	err := calendarRequest(ctx, client, "POST", googleCalendarEvents+"?sendUpdates=all", c.event(invite), &created)
+	return created.ID, err
+}
+
+func (c *GoogleCalendar) UpdateEvent(ctx context.Context, client *http.Client, eventID string, invite *CalendarInvite) error {
+	return calendarRequest(ctx, client, "PUT", googleCalendarEvents+"/"+url.PathEscape(eventID)+"?sendUpdates=all", c.event(invite), nil)
+}
+
+func (c *GoogleCalendar) CancelEvent(ctx context.Context, client *http.Client, eventID string) error {
+	return calendarRequest(ctx, client, "DELETE", googleCalendarEvents+"/"+url.PathEscape(eventID)+"?sendUpdates=all", nil, nil)
+}
+
+// OutlookCalendar creates events in Outlook calendars with the Microsoft Graph API, which invites the attendees
+// when the event is created or updated
+type OutlookCalendar struct{}
+
+const outlookCalendarEvents = "https://graph.microsoft.com/v1.0/me/events"
+
+type outlookEventTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+type outlookBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type outlookLocation struct {
+	DisplayName string `json:"displayName"`
+}
+
+type outlookEmailAddress struct {
+	Address string `json:"address"`
+}
+
+type outlookAttendee struct {
+	EmailAddress outlookEmailAddress `json:"emailAddress"`
+	Type         string              `json:"type"`
+}
+
+type outlookEvent struct {
+	ID        string            `json:"id,omitempty"`
+	Subject   string            `json:"subject"`
+	Body      outlookBody       `json:"body"`
+	Location  outlookLocation   `json:"location"`
+	Start     outlookEventTime  `json:"start"`
+	End       outlookEventTime  `json:"end"`
+	Attendees []outlookAttendee `json:"attendees"`
+}
+
+func (c *OutlookCalendar) Scope() string {
+	return "Calendars.ReadWrite"
+}
+
+func (c *OutlookCalendar) event(invite *CalendarInvite) *outlookEvent {
+	// Graph expects local date times without an offset along with their time zone
+	const graphTimeFormat = "2006-01-02T15:04:05"
+	event := &outlookEvent{
+		Subject:   invite.Title,
+		Body:      outlookBody{ContentType: "text", Content: invite.description()},
+		Location:  outlookLocation{DisplayName: invite.URL},
+		Start:     outlookEventTime{DateTime: invite.StartsAt.UTC().Format(graphTimeFormat), TimeZone: "UTC"},
+		End:       outlookEventTime{DateTime: invite.EndsAt.UTC().Format(graphTimeFormat), TimeZone: "UTC"},
+		Attendees: []outlookAttendee{},
+	}
+
+	for _, attendee := range invite.Attendees {
+		event.Attendees = append(event.Attendees, outlookAttendee{EmailAddress: outlookEmailAddress{Address: attendee}, Type: "required"})
+	}
+
+	return event
+}
+
+func (c *OutlookCalendar) CreateEvent(ctx context.Context, client *http.Client, invite *CalendarInvite) (string, error) {
+	var created outlookEvent
+	err := calendarRequest(ctx, client, "POST", outlookCalendarEvents, c.event(invite), &created)
+	return created.ID, err
+}
+
+func (c *OutlookCalendar) UpdateEvent(ctx context.Context, client *http.Client, eventID string, invite *CalendarInvite) error {
+	return calendarRequest(ctx, client, "PATCH", outlookCalendarEvents+"/"+url.PathEscape(eventID), c.event(invite), nil)
+}
+
+func (c *OutlookCalendar) CancelEvent(ctx context.Context, client *http.Client, eventID string) error {
+	// Deleting an event as its organizer sends a cancellation to the attendees
+	return calendarRequest(ctx, client, "DELETE", outlookCalendarEvents+"/"+url.PathEscape(eventID), nil, nil)
+}
@@ -0,0 +1,143 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// maxAnalyticsCalls is the most calls of a channel fetched from Agora Analytics at once
+const maxAnalyticsCalls = 50
+
+// AnalyticsCall is a call Agora Analytics recorded for a channel. A channel that was emptied and joined again has a
+// call for each time it was used
+type AnalyticsCall struct {
+	CallID      string `json:"call_id"`
+	Cname       string `json:"cname"`
+	CreatedTs   int64  `json:"created_ts"`
+	DestroyedTs int64  `json:"destroyed_ts"`
+}
+
+// AnalyticsSession is a session of a user in a call, from joining to leaving
+type AnalyticsSession struct {
+	UID        int64  `json:"uid"`
+	Network    string `json:"network"`
+	Platform   string `json:"platform"`
+	SDKVersion string `json:"sdk_version"`
+	JoinTs     int64  `json:"join_ts"`
+	LeaveTs    int64  `json:"leave_ts"`
+}
+
+// AnalyticsUserQuality is the quality Agora measured server side for a user in a call. Metrics that are not
+// available for the call, like video freezes of users that did not send video, are nil
+type AnalyticsUserQuality struct {
+	UID             int64    `json:"uid"`
+	JoinAttempts    int      `json:"join_attempts"`
+	JoinSuccesses   int      `json:"join_successes"`
+	AudioFreezeRate *float64 `json:"audio_freeze_rate"`
+	VideoFreezeRate *float64 `json:"video_freeze_rate"`
+}
+
+// analyticsURL returns the URL of an endpoint of the Agora Analytics RESTful API, which is AGORA_ANALYTICS_URL
+func analyticsURL(endpoint string, query url.Values) string {
+	return strings.TrimSuffix(viper.GetString("AGORA_ANALYTICS_URL"), "/") + "/" + endpoint + "?" + query.Encode()
+}
+
+// analyticsQuery returns the query identifying the project and time range of a request to Agora Analytics
+func analyticsQuery(project *AgoraProject, start time.Time, end time.Time) url.Values {
+	query := url.Values{}
+	query.Set("appid", project.appID())
+	query.Set("start_ts", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end_ts", strconv.FormatInt(end.Unix(), 10))
+	return query
+}
+
+// getAnalytics sends a request to Agora Analytics with the customer credentials of the project and decodes the
+// response into result
+func (c *AgoraClient) getAnalytics(ctx context.Context, project *AgoraProject, endpoint string, query url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", analyticsURL(endpoint, query), nil)
+	if err != nil {
+		return err
+	}
+
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Agora Analytics responded to %s with status %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// SearchCalls lists the calls of a channel that took place in a time range
+func (c *AgoraClient) SearchCalls(ctx context.Context, project *AgoraProject, channel string, start time.Time, end time.Time) ([]AnalyticsCall, error) {
+	query := analyticsQuery(project, start, end)
+	query.Set("cname", channel)
+	query.Set("page_no", "1")
+	query.Set("page_size", strconv.Itoa(maxAnalyticsCalls))
+
+	var result struct {
+		CallLists []AnalyticsCall `json:"call_lists"`
+	}
+	err := c.getAnalytics(ctx, project, "call/lists", query, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.CallLists, nil
+}
+
+// CallSessions lists the sessions of the users of a call
+func (c *AgoraClient) CallSessions(ctx context.Context, project *AgoraProject, call AnalyticsCall) ([]AnalyticsSession, error) {
+	query := analyticsQuery(project, time.Unix(call.CreatedTs, 0), time.Unix(call.DestroyedTs, 0))
+	query.Set("call_id", call.CallID)
+
+	var result struct {
+		CallLists []AnalyticsSession `json:"call_lists"`
+	}
+	err := c.getAnalytics(ctx, project, "call/details", query, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.CallLists, nil
+}
+
+// CallQuality fetches the quality of the users of a call
+func (c *AgoraClient) CallQuality(ctx context.Context, project *AgoraProject, call AnalyticsCall) ([]AnalyticsUserQuality, error) {
+	query := analyticsQuery(project, time.Unix(call.CreatedTs, 0), time.Unix(call.DestroyedTs, 0))
+	query.Set("call_id", call.CallID)
+
+	var result struct {
+		Metrics []AnalyticsUserQuality `json:"metrics"`
+	}
+	err := c.getAnalytics(ctx, project, "call/metrics", query, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Metrics, nil
+}
@@ -0,0 +1,147 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ChannelUserList is the list of users in a channel as reported by the Agora RESTful API
+type ChannelUserList struct {
+	Success bool `json:"success"`
+	Data    struct {
+		ChannelExist  bool    `json:"channel_exist"`
+		Mode          int     `json:"mode"`
+		Total         int     `json:"total"`
+		Users         []int32 `json:"users"`
+		Broadcasters  []int32 `json:"broadcasters"`
+		Audience      []int32 `json:"audience"`
+		AudienceTotal int     `json:"audience_total"`
+	} `json:"data"`
+}
+
+// Participants returns the number of users in the channel, not counting the users in ignore such as recorders
+func (list *ChannelUserList) Participants(ignore ...int32) int {
+	if !list.Data.ChannelExist {
+		return 0
+	}
+
+	users := append(append(append([]int32{}, list.Data.Users...), list.Data.Broadcasters...), list.Data.Audience...)
+	count := len(users)
+	if list.Data.AudienceTotal > len(list.Data.Audience) {
+		count += list.Data.AudienceTotal - len(list.Data.Audience)
+	}
+
+	for _, user := range users {
+		for _, ignored := range ignore {
+			if user == ignored {
+				count--
+			}
+		}
+	}
+
+	return count
+}
+
+// GetChannelUsers fetches the users that are currently in a channel
+func (c *AgoraClient) GetChannelUsers(project *AgoraProject, channel string) (*ChannelUserList, error) {
+	req, err := http.NewRequest("GET", "https://api.agora.io/dev/v1/channel/user/"+project.appID()+"/"+channel, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Fetching channel users failed with status %d", resp.StatusCode)
+	}
+
+	var result ChannelUserList
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// KickingRule bans users from joining a channel through the Agora RESTful API
+type KickingRule struct {
+	AppID      string   `json:"appid"`
+	Cname      string   `json:"cname,omitempty"`
+	UID        string   `json:"uid,omitempty"`
+	IP         string   `json:"ip,omitempty"`
+	Time       int      `json:"time"`
+	Privileges []string `json:"privileges"`
+}
+
+// BanChannel removes every user from a channel and keeps them from joining again for the given duration,
+// which is rounded down to minutes and capped at 24 hours by Agora
+func (c *AgoraClient) BanChannel(project *AgoraProject, channel string, duration time.Duration) error {
+	return c.createKickingRule(project, KickingRule{
+		AppID:      project.appID(),
+		Cname:      channel,
+		Time:       int(duration.Minutes()),
+		Privileges: []string{"join_channel"},
+	})
+}
+
+// KickUser removes a user from a channel. When duration is at least a minute the user is also kept from joining
+// again for that long, otherwise they are only removed
+func (c *AgoraClient) KickUser(project *AgoraProject, channel string, uid int, duration time.Duration) error {
+	return c.createKickingRule(project, KickingRule{
+		AppID:      project.appID(),
+		Cname:      channel,
+		UID:        strconv.Itoa(uid),
+		Time:       int(duration.Minutes()),
+		Privileges: []string{"join_channel"},
+	})
+}
+
+func (c *AgoraClient) createKickingRule(project *AgoraProject, rule KickingRule) error {
+	requestBody, err := json.Marshal(&rule)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", "https://api.agora.io/dev/v1/kicking-rule", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Creating kicking rule failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// CloudRecorder records channels. The state of each recording, like its resource ID and SID, is kept in the Recorder
+// it is given
+type CloudRecorder interface {
+	// Acquire reserves a resource for a new recording of rec.Channel, setting rec.UID, rec.Token and rec.RID
+	Acquire(rec *Recorder) error
+	// Start starts recording the streams of the channel in mix mode, setting rec.SID
+	Start(rec *Recorder, channelTitle string, secret *string, transcodingConfig TranscodingConfig) error
+	// StartWeb starts recording the web page at pageURL, setting rec.SID
+	StartWeb(rec *Recorder, pageURL string, channelTitle string) error
+	// Pause stops recording until Resume is called
+	Pause(rec *Recorder) error
+	// Resume continues a recording that was paused
+	Resume(rec *Recorder) error
+	// UpdateLayout changes the video layout of a mix mode recording
+	UpdateLayout(rec *Recorder, layout int, maxResolutionUID string, backgroundColor string) error
+	// ChangeMode switches a mix mode recording to layout, with the video of maxUID in the largest region
+	ChangeMode(rec *Recorder, layout int, maxUID string) error
+	// Stop stops a recording. It returns ErrRecordingNotFound when the recording is no longer running
+	Stop(rec *Recorder) error
+	// Query fetches the status of a recording. It returns ErrRecordingNotFound when the recording is no longer running
+	Query(rec *Recorder) (*QueryResponse, error)
+}
+
+// NewCloudRecorder creates the recorder selected by CLOUD_RECORDER, which is either agora or mock. The mock recorder
+// only keeps recordings in memory, so that recording can be tried out without Agora credentials
+func NewCloudRecorder() (CloudRecorder, error) {
+	switch viper.GetString("CLOUD_RECORDER") {
+	case "", "agora":
+		return &AgoraCloudRecorder{}, nil
+	case "mock":
+		return NewMockCloudRecorder(), nil
+	default:
+		return nil, fmt.Errorf("Unknown cloud recorder %s", viper.GetString("CLOUD_RECORDER"))
+	}
+}
+
+// AgoraCloudRecorder records channels with Agora Cloud Recording
+type AgoraCloudRecorder struct{}
+
+// Acquire reserves a cloud recording resource
+func (a *AgoraCloudRecorder) Acquire(rec *Recorder) error {
+	return rec.Acquire()
+}
+
+// Start starts a mix mode recording
+func (a *AgoraCloudRecorder) Start(rec *Recorder, channelTitle string, secret *string, transcodingConfig TranscodingConfig) error {
+	return rec.Start(channelTitle, secret, transcodingConfig)
+}
+
+// StartWeb starts a web recording
+func (a *AgoraCloudRecorder) StartWeb(rec *Recorder, pageURL string, channelTitle string) error {
+	return rec.StartWeb(pageURL, channelTitle)
+}
+
+// Pause pauses a recording
+func (a *AgoraCloudRecorder) Pause(rec *Recorder) error {
+	return rec.Pause()
+}
+
+// Resume resumes a paused recording
+func (a *AgoraCloudRecorder) Resume(rec *Recorder) error {
+	return rec.Resume()
+}
+
+// UpdateLayout changes the layout of a mix mode recording
+func (a *AgoraCloudRecorder) UpdateLayout(rec *Recorder, layout int, maxResolutionUID string, backgroundColor string) error {
+	return rec.UpdateLayout(layout, maxResolutionUID, backgroundColor)
+}
+
+// ChangeMode switches the layout of a mix mode recording
+func (a *AgoraCloudRecorder) ChangeMode(rec *Recorder, layout int, maxUID string) error {
+	return rec.ChangeMode(layout, maxUID)
+}
+
+// Stop stops a recording
+func (a *AgoraCloudRecorder) Stop(rec *Recorder) error {
+	return rec.Stop()
+}
+
+// Query fetches the status of a recording
+func (a *AgoraCloudRecorder) Query(rec *Recorder) (*QueryResponse, error) {
+	return rec.Query()
+}
@@ -0,0 +1,177 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// mockRecording is a recording of the mock recorder
+type mockRecording struct {
+	mode      string
+	status    int
+	paused    bool
+	layout    int
+	startedAt time.Time
+}
+
+// MockCloudRecorder keeps recordings in memory instead of recording channels. Recordings run until they are stopped,
+// and query as having recorded a single playlist named after their SID
+type MockCloudRecorder struct {
+	mu         sync.Mutex
+	resources  map[string]bool
+	recordings map[string]*mockRecording
+}
+
+// NewMockCloudRecorder creates a mock recorder without recordings
+func NewMockCloudRecorder() *MockCloudRecorder {
+	return &MockCloudRecorder{
+		resources:  map[string]bool{},
+		recordings: map[string]*mockRecording{},
+	}
+}
+
+// Acquire reserves a resource like cloud recording, without generating a token
+func (m *MockCloudRecorder) Acquire(rec *Recorder) error {
+	rid, err := GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.resources[rid] = true
+	rec.UID = int32(newUID(false))
+	rec.Token = ""
+	rec.RID = rid
+	return nil
+}
+
+// start starts a recording on an acquired resource
+func (m *MockCloudRecorder) start(rec *Recorder, mode string) error {
+	sid, err := GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.resources[rec.RID] {
+		return &AgoraError{Operation: "start", Status: 400, Code: 2, Reason: "invalid resource ID"}
+	}
+	delete(m.resources, rec.RID)
+
+	m.recordings[sid] = &mockRecording{
+		mode:      mode,
+		status:    5,
+		layout:    LayoutFloating,
+		startedAt: time.Now(),
+	}
+	rec.SID = sid
+	return nil
+}
+
+// Start starts a mix mode recording
+func (m *MockCloudRecorder) Start(rec *Recorder, channelTitle string, secret *string, transcodingConfig TranscodingConfig) error {
+	return m.start(rec, "mix")
+}
+
+// StartWeb starts a web recording
+func (m *MockCloudRecorder) StartWeb(rec *Recorder, pageURL string, channelTitle string) error {
+	return m.start(rec, "web")
+}
+
+// update changes a running recording
+func (m *MockCloudRecorder) update(rec *Recorder, change func(recording *mockRecording) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recording, ok := m.recordings[rec.SID]
+	if !ok {
+		return ErrRecordingNotFound
+	}
+
+	return change(recording)
+}
+
+// Pause pauses a recording
+func (m *MockCloudRecorder) Pause(rec *Recorder) error {
+	return m.update(rec, func(recording *mockRecording) error {
+		recording.paused = true
+		return nil
+	})
+}
+
+// Resume resumes a paused recording
+func (m *MockCloudRecorder) Resume(rec *Recorder) error {
+	return m.update(rec, func(recording *mockRecording) error {
+		recording.paused = false
+		return nil
+	})
+}
+
+// errMockWebLayout is returned when changing the layout of a web recording, which cloud recording does not support
+var errMockWebLayout = &AgoraError{Operation: "updateLayout", Status: 400, Code: 2, Reason: "web recordings have no layout"}
+
+// UpdateLayout changes the layout of a mix mode recording
+func (m *MockCloudRecorder) UpdateLayout(rec *Recorder, layout int, maxResolutionUID string, backgroundColor string) error {
+	return m.update(rec, func(recording *mockRecording) error {
+		if recording.mode != "mix" {
+			return errMockWebLayout
+		}
+		recording.layout = layout
+		return nil
+	})
+}
+
+// ChangeMode switches the layout of a mix mode recording
+func (m *MockCloudRecorder) ChangeMode(rec *Recorder, layout int, maxUID string) error {
+	return m.UpdateLayout(rec, layout, maxUID, "")
+}
+
+// Stop stops a recording
+func (m *MockCloudRecorder) Stop(rec *Recorder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.recordings[rec.SID]; !ok {
+		return ErrRecordingNotFound
+	}
+
+	delete(m.recordings, rec.SID)
+	return nil
+}
+
+// Query returns the status of a recording
+func (m *MockCloudRecorder) Query(rec *Recorder) (*QueryResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recording, ok := m.recordings[rec.SID]
+	if !ok {
+		return nil, ErrRecordingNotFound
+	}
+
+	return &QueryResponse{
+		ResourceID: rec.RID,
+		SID:        rec.SID,
+		ServerResponse: QueryServerResponse{
+			FileListMode:   "string",
+			FileList:       json.RawMessage(strconv.Quote(rec.SID + ".m3u8")),
+			Status:         recording.status,
+			SliceStartTime: recording.startedAt.UnixNano() / int64(time.Millisecond),
+		},
+	}, nil
+}
@@ -27,21 +27,123 @@ type AgoraConfig struct {
 func SetDefaults() {
 	viper.SetDefault("LOG_DIR", "./logs")
 	viper.SetDefault("PORT", "8080")
-	viper.SetDefault("MIGRATION_SOURCE", "file://db/migrations") // Will be used in the future
+	viper.SetDefault("GRPC_PORT", "")
+	// Shutdown is given up on after this long, which should be shorter than the time deploys wait before killing the
+	// server
+	viper.SetDefault("SHUTDOWN_TIMEOUT_SECONDS", 25)
+	// MySQL is used with DATABASE_DRIVER set to mysql and a DSN like user:password@tcp(localhost:3306)/agora as
+	// DATABASE_URL. SQLite, with DATABASE_DRIVER set to sqlite3, is meant for local development and only supported by
+	// binaries built with the sqlite tag
+	viper.SetDefault("DATABASE_DRIVER", "postgres")
+	viper.SetDefault("DATABASE_MAX_OPEN_CONNS", 20)
+	viper.SetDefault("DATABASE_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DATABASE_CONN_MAX_LIFETIME_MINUTES", 30)
+	// Statements that run for longer are cancelled, even when the request they belong to is still waiting for them
+	viper.SetDefault("DATABASE_QUERY_TIMEOUT_SECONDS", 10)
+	// Migrations are read from the binary unless MIGRATION_SOURCE points at a directory, like file://migrations/migrations
+	viper.SetDefault("MIGRATION_SOURCE", "")
 	viper.SetDefault("ALLOWED_ORIGIN", "*")
+	viper.SetDefault("GRAPHQL_COMPLEXITY_LIMIT", 20000)
+	viper.SetDefault("GRAPHQL_DEPTH_LIMIT", 12)
+	viper.SetDefault("GRAPHQL_MAX_BODY_BYTES", 1<<20)
+	viper.SetDefault("GRAPHQL_MAX_UPLOAD_BYTES", 32<<20)
+	viper.SetDefault("OPERATION_ALLOWLIST", false)
 	viper.SetDefault("ENABLE_OAUTH", false)
 	viper.SetDefault("ENABLE_GOOGLE_OAUTH", false)
 	viper.SetDefault("ENABLE_APPLE_OAUTH", false)
 	viper.SetDefault("ENABLE_MICROSOFT_OAUTH", false)
 	viper.SetDefault("ENABLE_SLACK_OAUTH", false)
+	viper.SetDefault("ENABLE_GITHUB_OAUTH", false)
+	viper.SetDefault("ENABLE_OIDC_OAUTH", false)
+	viper.SetDefault("OIDC_SCOPES", []string{})
+	viper.SetDefault("OIDC_TRUST_EMAIL", false)
+	viper.SetDefault("ENABLE_PASSWORD_AUTH", false)
+	viper.SetDefault("ENABLE_MAGIC_LINK_AUTH", false)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("EMAIL_TOKEN_EXPIRY_MINUTES", 60)
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("ENABLE_PHONE_AUTH", false)
+	viper.SetDefault("OTP_LENGTH", 6)
+	viper.SetDefault("OTP_EXPIRY_MINUTES", 10)
+	viper.SetDefault("OTP_MAX_ATTEMPTS", 5)
+	viper.SetDefault("OTP_RESEND_SECONDS", 30)
+	viper.SetDefault("TOTP_ISSUER", "App Builder")
+	viper.SetDefault("TWO_FACTOR_LOCKOUT_AFTER", 5)
+	viper.SetDefault("TWO_FACTOR_MAX_LOCKOUT_MINUTES", 60)
+	viper.SetDefault("DATA_EXPORT_INTERVAL_MINUTES", 1)
+	viper.SetDefault("DATA_EXPORT_EXPIRY_HOURS", 48)
+	viper.SetDefault("USAGE_METERING_INTERVAL_MINUTES", 5)
+	viper.SetDefault("QUOTA_CHANNELS_PER_MONTH", 0)
+	viper.SetDefault("QUOTA_RECORDING_MINUTES_PER_MONTH", 0)
+	viper.SetDefault("QUOTA_PARTICIPANT_MINUTES_PER_MONTH", 0)
+	viper.SetDefault("QUOTA_PSTN_CALLS_PER_MONTH", 0)
+	viper.SetDefault("WEBHOOK_DELIVERY_INTERVAL_SECONDS", 10)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_RETRY_SECONDS", 30)
+	viper.SetDefault("WEBHOOK_LOG_RETENTION_DAYS", 30)
+	viper.SetDefault("JOB_POLL_INTERVAL_SECONDS", 5)
+	viper.SetDefault("JOB_MAX_ATTEMPTS", 5)
+	viper.SetDefault("JOB_RETRY_SECONDS", 30)
+	// Dead jobs are kept until they are retried
+	viper.SetDefault("JOB_RETENTION_DAYS", 7)
+	viper.SetDefault("EVENT_BUS", "")
+	viper.SetDefault("EVENT_BUS_TOPIC", "appbuilder.events")
+	viper.SetDefault("EVENT_PUBLISH_INTERVAL_SECONDS", 5)
+	viper.SetDefault("INVITE_DAILY_LIMIT", 100)
+	viper.SetDefault("SHORT_LINK_GRACE_HOURS", 24)
+	viper.SetDefault("MICROSOFT_TENANT", "common")
 	viper.SetDefault("ENABLE_CONSOLE_LOGGING", true)
 	viper.SetDefault("ENABLE_FILE_LOGGING", true)
 	viper.SetDefault("LOG_LEVEL", "DEBUG")
 	viper.SetDefault("ALLOW_LIST", []string{"*"})
+	viper.SetDefault("ADMIN_EMAILS", []string{})
+	viper.SetDefault("TOKEN_EXPIRY_SECONDS", 86400)
+	viper.SetDefault("PRECALL_TOKEN_EXPIRY_SECONDS", 300)
+	viper.SetDefault("SUPPORT_LOG_UPLOAD_EXPIRY_MINUTES", 15)
+	viper.SetDefault("ACCESS_TOKEN_EXPIRY_MINUTES", 60)
+	viper.SetDefault("REFRESH_TOKEN_EXPIRY_DAYS", 30)
 	viper.SetDefault("RECORDING_VENDOR", 1)
+	viper.SetDefault("STORAGE_PROVIDER", "")
 	viper.SetDefault("RECORDING_REGION", 0)
+	viper.SetDefault("RECORDING_HEIGHT", 720)
+	viper.SetDefault("RECORDING_WIDTH", 1280)
+	viper.SetDefault("RECORDING_BITRATE", 2260)
+	viper.SetDefault("RECORDING_FPS", 15)
+	viper.SetDefault("RECORDING_LAYOUT", 1)
+	viper.SetDefault("RECORDING_BACKGROUND_COLOR", "#000000")
+	viper.SetDefault("RECORDING_URL_EXPIRY_SECONDS", 3600)
+	viper.SetDefault("RECORDING_RETENTION_DAYS", 0)
+	viper.SetDefault("RECORDING_RETENTION_INTERVAL_MINUTES", 60)
+	viper.SetDefault("RECORDING_RECONCILE_INTERVAL_MINUTES", 5)
+	viper.SetDefault("RECORDING_EMPTY_TIMEOUT_MINUTES", 10)
 	viper.SetDefault("RUN_MIGRATION", false)
 	viper.SetDefault("PSTN_NUMBER", "(800) 309-2350")
+	viper.SetDefault("DTMF_LENGTH", 8)
+	viper.SetDefault("MEDIA_PUSH_REGION", "na")
+	viper.SetDefault("AGORA_AREA", "GLOBAL")
+	viper.SetDefault("CLOUD_RECORDER", "agora")
+	viper.SetDefault("AGORA_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("AGORA_MAX_RETRIES", 3)
+	viper.SetDefault("AGORA_RETRY_BASE_MS", 200)
+	viper.SetDefault("AGORA_CIRCUIT_FAILURES", 5)
+	viper.SetDefault("AGORA_CIRCUIT_COOLDOWN_SECONDS", 30)
+	viper.SetDefault("AGORA_ANALYTICS_URL", "https://api.agora.io/beta/analytics")
+	viper.SetDefault("FEATURE_FLAG_CACHE_SECONDS", 30)
+	viper.SetDefault("WHITEBOARD_REGION", "us-sv")
+	viper.SetDefault("STT_PROVIDER", "")
+	viper.SetDefault("STT_API_URL", "https://api.openai.com/v1/audio/transcriptions")
+	viper.SetDefault("STT_MODEL", "whisper-1")
+	viper.SetDefault("RECORDING_TRANSCRIPT_INTERVAL_MINUTES", 1)
+	viper.SetDefault("TRACING_SERVICE_NAME", "app-builder-backend")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("TRUST_PROXY", false)
+	viper.SetDefault("RATE_LIMIT_IP_PER_MINUTE", 300)
+	viper.SetDefault("RATE_LIMIT_USER_PER_MINUTE", 600)
+	viper.SetDefault("RATE_LIMIT_PASSPHRASE_PER_MINUTE", 10)
+	viper.SetDefault("PASSPHRASE_FAILURE_WINDOW_MINUTES", 15)
+	viper.SetDefault("PASSPHRASE_BACKOFF_AFTER", 5)
+	viper.SetDefault("PASSPHRASE_LOCKOUT_AFTER", 20)
+	viper.SetDefault("PASSPHRASE_LOCKOUT_MINUTES", 15)
 
 	if viper.GetString("RUN_MIGRATION") == "true" {
 		viper.SetDefault("RUN_MIGRATION", true)
@@ -71,6 +173,26 @@ func SetDefaults() {
 		viper.SetDefault("ENABLE_SLACK_OAUTH", true)
 	}
 
+	if viper.GetString("ENABLE_GITHUB_OAUTH") == "true" {
+		viper.SetDefault("ENABLE_GITHUB_OAUTH", true)
+	}
+
+	if viper.GetString("ENABLE_OIDC_OAUTH") == "true" {
+		viper.SetDefault("ENABLE_OIDC_OAUTH", true)
+	}
+
+	if viper.GetString("ENABLE_PASSWORD_AUTH") == "true" {
+		viper.SetDefault("ENABLE_PASSWORD_AUTH", true)
+	}
+
+	if viper.GetString("ENABLE_MAGIC_LINK_AUTH") == "true" {
+		viper.SetDefault("ENABLE_MAGIC_LINK_AUTH", true)
+	}
+
+	if viper.GetString("ENABLE_PHONE_AUTH") == "true" {
+		viper.SetDefault("ENABLE_PHONE_AUTH", true)
+	}
+
 	if viper.GetString("ALLOWED_ORIGIN") == "" {
 		viper.Set("ALLOWED_ORIGIN", "*")
 	}
@@ -94,7 +216,8 @@ func SetupConfig(configDir *string) error {
 
 	viper.AutomaticEnv()
 
-	if viper.GetBool("ENABLE_SLACK_OAUTH") || viper.GetBool("ENABLE_GOOGLE_OAUTH") || viper.GetBool("ENABLE_APPLE_OAUTH") || viper.GetBool("ENABLE_MICROSOFT_OAUTH") {
+	if viper.GetBool("ENABLE_SLACK_OAUTH") || viper.GetBool("ENABLE_GOOGLE_OAUTH") || viper.GetBool("ENABLE_APPLE_OAUTH") || viper.GetBool("ENABLE_MICROSOFT_OAUTH") || viper.GetBool("ENABLE_GITHUB_OAUTH") || viper.GetBool("ENABLE_OIDC_OAUTH") ||
+		viper.GetBool("ENABLE_PASSWORD_AUTH") || viper.GetBool("ENABLE_MAGIC_LINK_AUTH") || viper.GetBool("ENABLE_PHONE_AUTH") {
 		viper.SetDefault("ENABLE_OAUTH", true)
 	}
 
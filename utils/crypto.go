@@ -0,0 +1,93 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// encryptionCipher creates the AES-GCM cipher from the base64 encoded 32 byte ENCRYPTION_KEY
+func encryptionCipher() (cipher.AEAD, error) {
+	if viper.GetString("ENCRYPTION_KEY") == "" {
+		return nil, errors.New("ENCRYPTION_KEY is not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(viper.GetString("ENCRYPTION_KEY"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != 32 {
+		return nil, errors.New("ENCRYPTION_KEY must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts a secret so that it can be stored at rest
+func Encrypt(plaintext string) (string, error) {
+	gcm, err := encryptionCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// Decrypt decrypts a secret encrypted with Encrypt
+func Decrypt(ciphertext string) (string, error) {
+	gcm, err := encryptionCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("Ciphertext is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// HashSecret returns the SHA-256 of a random secret encoded as hex. Secrets with 256 bits of entropy do not need a
+// salt or a slow hash, so they can be stored and looked up by this hash
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,264 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// EventBus publishes the lifecycle events of channels to a message broker
+type EventBus interface {
+	// Publish delivers the JSON payload of an event, keyed by the channel it happened in so that the events of a
+	// channel can be kept in order
+	Publish(event string, key string, payload []byte) error
+}
+
+// NewEventBus creates the publisher selected by EVENT_BUS, which is either kafka or nats. It returns nil when
+// EVENT_BUS is not set, in which case events are only delivered to webhooks
+func NewEventBus() (EventBus, error) {
+	switch viper.GetString("EVENT_BUS") {
+	case "":
+		return nil, nil
+	case "kafka":
+		return &KafkaEventBus{
+			URL:      strings.TrimSuffix(viper.GetString("KAFKA_REST_URL"), "/"),
+			Topic:    viper.GetString("EVENT_BUS_TOPIC"),
+			Username: viper.GetString("KAFKA_REST_USERNAME"),
+			Password: viper.GetString("KAFKA_REST_PASSWORD"),
+		}, nil
+	case "nats":
+		return &NATSEventBus{
+			URL:     viper.GetString("NATS_URL"),
+			Subject: viper.GetString("EVENT_BUS_TOPIC"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown event bus %s", viper.GetString("EVENT_BUS"))
+	}
+}
+
+var eventBusClient = &http.Client{Timeout: 10 * time.Second}
+
+// KafkaEventBus publishes events to a Kafka topic through the Confluent REST Proxy
+type KafkaEventBus struct {
+	URL      string
+	Topic    string
+	Username string
+	Password string
+}
+
+// Publish produces an event to the topic with the channel as the record key
+func (k *KafkaEventBus) Publish(event string, key string, payload []byte) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{{"key": key, "value": json.RawMessage(payload)}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", k.URL+"/topics/"+url.PathEscape(k.Topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if k.Username != "" {
+		req.SetBasicAuth(k.Username, k.Password)
+	}
+
+	response, err := eventBusClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	contents, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("Kafka REST Proxy responded with %d: %s", response.StatusCode, string(contents))
+	}
+
+	// The proxy reports records that could not be produced per record rather than with the status
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if json.Unmarshal(contents, &result) == nil {
+		for _, offset := range result.Offsets {
+			if offset.ErrorCode != nil {
+				return fmt.Errorf("Kafka could not produce the event: %s", offset.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+// natsTimeout bounds connecting to NATS and waiting for it to acknowledge a publish
+const natsTimeout = 10 * time.Second
+
+// NATSEventBus publishes events to the subject <Subject>.<event> of a NATS server. URL is a nats:// or tls:// URL,
+// which can hold a user and password or a token as its user. The connection is kept open between events and
+// reopened after errors
+type NATSEventBus struct {
+	URL     string
+	Subject string
+
+	mutex  sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Publish sends an event and waits for the server to answer a ping sent after it, so that the event is known to
+// have reached the server when no error is returned
+func (n *NATSEventBus) Publish(event string, key string, payload []byte) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.conn == nil {
+		err := n.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := n.publish(n.Subject+"."+event, payload)
+	if err != nil {
+		n.conn.Close()
+		n.conn = nil
+	}
+
+	return err
+}
+
+// connect opens a connection to the server and authenticates with the credentials in the URL
+func (n *NATSEventBus) connect() error {
+	server, err := url.Parse(n.URL)
+	if err != nil {
+		return err
+	}
+
+	host := server.Host
+	if server.Port() == "" {
+		host = net.JoinHostPort(server.Hostname(), "4222")
+	}
+
+	conn, err := net.DialTimeout("tcp", host, natsTimeout)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	reader := bufio.NewReader(conn)
+
+	// The server introduces itself before anything is sent
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("Unexpected greeting from NATS: %q", line)
+	}
+
+	if server.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: server.Hostname()})
+		err = tlsConn.Handshake()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "name": "app-builder-backend", "lang": "go", "version": "1.0.0"}
+	if password, ok := server.User.Password(); ok {
+		options["user"] = server.User.Username()
+		options["pass"] = password
+	} else if server.User != nil {
+		options["auth_token"] = server.User.Username()
+	}
+
+	connect, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	n.conn = conn
+	n.reader = reader
+
+	_, err = conn.Write([]byte("CONNECT " + string(connect) + "\r\nPING\r\n"))
+	if err == nil {
+		err = n.awaitPong()
+	}
+	if err != nil {
+		conn.Close()
+		n.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// publish sends a message followed by a ping and waits for the pong
+func (n *NATSEventBus) publish(subject string, payload []byte) error {
+	n.conn.SetDeadline(time.Now().Add(natsTimeout))
+
+	var message bytes.Buffer
+	message.WriteString("PUB " + subject + " " + strconv.Itoa(len(payload)) + "\r\n")
+	message.Write(payload)
+	message.WriteString("\r\nPING\r\n")
+
+	_, err := n.conn.Write(message.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return n.awaitPong()
+}
+
+// awaitPong reads from the server until it answers a ping. Pings from the server are answered along the way
+func (n *NATSEventBus) awaitPong() error {
+	for {
+		line, err := n.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			_, err = n.conn.Write([]byte("PONG\r\n"))
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS error: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
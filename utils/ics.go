@@ -0,0 +1,85 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// MeetingEvent is a scheduled meeting exported as a calendar event
+type MeetingEvent struct {
+	UID         string
+	Title       string
+	Description string
+	URL         string
+	StartsAt    time.Time
+	EndsAt      time.Time
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// icsEscape escapes text values as described in RFC 5545 section 3.3.11
+func icsEscape(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+	return replacer.Replace(value)
+}
+
+// icsFold folds a content line into lines of at most 75 octets as described in RFC 5545 section 3.1,
+// without splitting multi-byte characters
+func icsFold(line string) string {
+	var folded strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > 75 {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+	folded.WriteString("\r\n")
+	return folded.String()
+}
+
+// ICS renders the meeting as an RFC 5545 calendar with a single event
+func (event *MeetingEvent) ICS(now time.Time) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Agora//App Builder//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + event.UID,
+		"DTSTAMP:" + now.UTC().Format(icsTimeFormat),
+		"DTSTART:" + event.StartsAt.UTC().Format(icsTimeFormat),
+		"DTEND:" + event.EndsAt.UTC().Format(icsTimeFormat),
+		"SUMMARY:" + icsEscape(event.Title),
+	}
+
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsEscape(event.Description))
+	}
+
+	if event.URL != "" {
+		lines = append(lines, "URL:"+event.URL, "LOCATION:"+icsEscape(event.URL))
+	}
+
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(icsFold(line))
+	}
+	return calendar.String()
+}
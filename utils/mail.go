@@ -0,0 +1,325 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// MailAttachment is a file attached to an email
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// MailMessage is an email to a single recipient. HTML is optional and sent as an alternative to Text
+type MailMessage struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []MailAttachment
+}
+
+// Mailer sends emails to users
+type Mailer interface {
+	// Send delivers an email
+	Send(message *MailMessage) error
+}
+
+// NewMailer creates the mailer selected by MAIL_PROVIDER, which is smtp, sendgrid or ses. MAIL_PROVIDER defaults to
+// smtp when SMTP_HOST is set. It returns nil when no provider is configured, in which case the features that send
+// emails are unavailable
+func NewMailer() (Mailer, error) {
+	provider := viper.GetString("MAIL_PROVIDER")
+	if provider == "" && viper.GetString("SMTP_HOST") != "" {
+		provider = "smtp"
+	}
+
+	switch provider {
+	case "":
+		return nil, nil
+	case "smtp":
+		return &SMTPMailer{
+			Host:     viper.GetString("SMTP_HOST"),
+			Port:     viper.GetInt("SMTP_PORT"),
+			Username: viper.GetString("SMTP_USERNAME"),
+			Password: viper.GetString("SMTP_PASSWORD"),
+			From:     viper.GetString("MAIL_FROM"),
+		}, nil
+	case "sendgrid":
+		return &SendGridMailer{
+			APIKey: viper.GetString("SENDGRID_API_KEY"),
+			From:   viper.GetString("MAIL_FROM"),
+		}, nil
+	case "ses":
+		return &SESMailer{
+			Region:    viper.GetString("SES_REGION"),
+			AccessKey: viper.GetString("SES_ACCESS_KEY"),
+			SecretKey: viper.GetString("SES_SECRET_KEY"),
+			From:      viper.GetString("MAIL_FROM"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown mail provider %s", provider)
+	}
+}
+
+// checkHeaders rejects recipients and subjects that would inject headers into an email
+func (message *MailMessage) checkHeaders() error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return fmt.Errorf("Invalid email header")
+	}
+
+	return nil
+}
+
+// mimeBoundary returns a random boundary for a multipart body
+func mimeBoundary() (string, error) {
+	random := make([]byte, 16)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(random), nil
+}
+
+// wrapBase64 encodes content in base64 split into lines of 76 characters, as MIME requires
+func wrapBase64(content []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+	return wrapped.String()
+}
+
+// MIME renders the message as a MIME email from an address. Plain text only messages are sent as is, while HTML
+// bodies and attachments are sent as multipart bodies
+func (message *MailMessage) MIME(from string) ([]byte, error) {
+	err := message.checkHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	var email bytes.Buffer
+	for _, header := range []string{
+		"From: " + from,
+		"To: " + message.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+	} {
+		email.WriteString(header + "\r\n")
+	}
+
+	if message.HTML == "" && len(message.Attachments) == 0 {
+		email.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n" + message.Text)
+		return email.Bytes(), nil
+	}
+
+	mixed, err := mimeBoundary()
+	if err != nil {
+		return nil, err
+	}
+	alternative, err := mimeBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	email.WriteString("Content-Type: multipart/mixed; boundary=\"" + mixed + "\"\r\n\r\n")
+	email.WriteString("--" + mixed + "\r\nContent-Type: multipart/alternative; boundary=\"" + alternative + "\"\r\n\r\n")
+	email.WriteString("--" + alternative + "\r\nContent-Type: text/plain; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	email.WriteString(wrapBase64([]byte(message.Text)) + "\r\n")
+	if message.HTML != "" {
+		email.WriteString("--" + alternative + "\r\nContent-Type: text/html; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+		email.WriteString(wrapBase64([]byte(message.HTML)) + "\r\n")
+	}
+	email.WriteString("--" + alternative + "--\r\n")
+
+	for _, attachment := range message.Attachments {
+		if strings.ContainsAny(attachment.Filename+attachment.ContentType, "\r\n\"") {
+			return nil, fmt.Errorf("Invalid attachment %q", attachment.Filename)
+		}
+
+		email.WriteString("--" + mixed + "\r\nContent-Type: " + attachment.ContentType + "; name=\"" + attachment.Filename + "\"\r\n")
+		email.WriteString("Content-Disposition: attachment; filename=\"" + attachment.Filename + "\"\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+		email.WriteString(wrapBase64(attachment.Content) + "\r\n")
+	}
+	email.WriteString("--" + mixed + "--\r\n")
+
+	return email.Bytes(), nil
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers an email. The connection is upgraded with STARTTLS when the server supports it
+func (m *SMTPMailer) Send(message *MailMessage) error {
+	email, err := message.MIME(m.From)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	return smtp.SendMail(net.JoinHostPort(m.Host, strconv.Itoa(m.Port)), auth, m.From, []string{message.To}, email)
+}
+
+var mailClient = &http.Client{Timeout: 10 * time.Second}
+
+// SendGridMailer sends emails with the SendGrid v3 Mail Send API
+type SendGridMailer struct {
+	APIKey string
+	From   string
+}
+
+// Send delivers an email
+func (m *SendGridMailer) Send(message *MailMessage) error {
+	err := message.checkHeaders()
+	if err != nil {
+		return err
+	}
+
+	content := []map[string]string{{"type": "text/plain", "value": message.Text}}
+	if message.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": message.HTML})
+	}
+
+	request := map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": []map[string]string{{"email": message.To}}}},
+		"from":             map[string]string{"email": m.From},
+		"subject":          message.Subject,
+		"content":          content,
+	}
+
+	if len(message.Attachments) > 0 {
+		attachments := []map[string]string{}
+		for _, attachment := range message.Attachments {
+			attachments = append(attachments, map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(attachment.Content),
+				"filename":    attachment.Filename,
+				"type":        attachment.ContentType,
+				"disposition": "attachment",
+			})
+		}
+		request["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return sendMailRequest(req)
+}
+
+// SESMailer sends emails with the AWS SES v2 API
+type SESMailer struct {
+	Region    string
+	AccessKey string
+	SecretKey string
+	From      string
+}
+
+var sesSigner = v4Signer{
+	algorithm:    "AWS4-HMAC-SHA256",
+	keyPrefix:    "AWS4",
+	service:      "ses",
+	terminator:   "aws4_request",
+	headerPrefix: "X-Amz-",
+}
+
+// Send delivers an email as a raw MIME message, which SES needs for attachments
+func (m *SESMailer) Send(message *MailMessage) error {
+	email, err := message.MIME(m.From)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": m.From,
+		"Destination":      map[string][]string{"ToAddresses": {message.To}},
+		"Content":          map[string]interface{}{"Raw": map[string][]byte{"Data": email}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The request is signed in its query, which covers the payload through its hash
+	hash := sha256.Sum256(body)
+	signer := sesSigner
+	signer.payloadHash = hex.EncodeToString(hash[:])
+
+	path := "/v2/email/outbound-emails"
+	signedURL := signer.presign("POST", "email."+m.Region+".amazonaws.com", path, m.Region, m.AccessKey, m.SecretKey, nil, time.Minute, time.Now())
+	req, err := http.NewRequest("POST", signedURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return sendMailRequest(req)
+}
+
+// sendMailRequest makes a request to an email API and turns unsuccessful responses into errors
+func sendMailRequest(req *http.Request) error {
+	response, err := mailClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		contents, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("Mail request failed with %d: %s", response.StatusCode, string(contents))
+	}
+
+	return nil
+}
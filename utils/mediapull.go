@@ -0,0 +1,118 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/spf13/viper"
+)
+
+// ErrPlayerNotFound is returned when Media Pull no longer knows about a player, for example because its stream ended
+var ErrPlayerNotFound = errors.New("Player not found")
+
+// Player plays an online media stream into a channel as a user
+type Player struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	StreamURL   string `json:"streamUrl,omitempty"`
+	ChannelName string `json:"channelName,omitempty"`
+	Token       string `json:"token,omitempty"`
+	UID         int    `json:"uid,omitempty"`
+	IdleTimeout int    `json:"idleTimeout,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+// PlayerRequest is the body of requests and responses of the Media Pull RESTful API
+type PlayerRequest struct {
+	Player Player `json:"player"`
+}
+
+// cloudPlayerURL returns the URL of the players of the project in MEDIA_PUSH_REGION
+func cloudPlayerURL(project *AgoraProject) string {
+	return "https://api.agora.io/" + viper.GetString("MEDIA_PUSH_REGION") + "/v1/projects/" + project.appID() + "/cloud-player/players"
+}
+
+// StartMediaPull creates a player that joins a channel with the given credentials and plays an RTMP or HLS stream
+func (c *AgoraClient) StartMediaPull(project *AgoraProject, channel string, name string, streamURL string, user *models.UserCredentials) (*Player, error) {
+	requestBody, err := json.Marshal(&PlayerRequest{
+		Player: Player{
+			Name:        name,
+			StreamURL:   streamURL,
+			ChannelName: channel,
+			Token:       user.Rtc,
+			UID:         user.UID,
+			IdleTimeout: 300,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", cloudPlayerURL(project), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Creating player failed with status %d", resp.StatusCode)
+	}
+
+	var result PlayerRequest
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Player, nil
+}
+
+// StopMediaPull deletes a player, which removes it from its channel
+func (c *AgoraClient) StopMediaPull(project *AgoraProject, playerID string) error {
+	req, err := http.NewRequest("DELETE", cloudPlayerURL(project)+"/"+playerID, nil)
+	if err != nil {
+		return err
+	}
+
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return ErrPlayerNotFound
+	}
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Deleting player failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
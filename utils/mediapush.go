@@ -0,0 +1,172 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// ErrConverterNotFound is returned when Media Push no longer knows about a converter, for example because it
+// stopped after the channel was idle
+var ErrConverterNotFound = errors.New("Converter not found")
+
+// ConverterCanvas is the size of the video pushed by a converter
+type ConverterCanvas struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ConverterVerticalLayout arranges the video of every user in the channel automatically
+type ConverterVerticalLayout struct {
+	FillMode string `json:"fillMode"`
+}
+
+// ConverterVideoOptions configures the video a converter pushes
+type ConverterVideoOptions struct {
+	Canvas     ConverterCanvas         `json:"canvas"`
+	LayoutType int                     `json:"layoutType"`
+	Vertical   ConverterVerticalLayout `json:"vertical"`
+	Codec      string                  `json:"codec"`
+	FrameRate  int                     `json:"frameRate"`
+	Bitrate    int                     `json:"bitrate"`
+}
+
+// ConverterAudioOptions configures the audio a converter pushes
+type ConverterAudioOptions struct {
+	CodecProfile  string `json:"codecProfile"`
+	SampleRate    int    `json:"sampleRate"`
+	Bitrate       int    `json:"bitrate"`
+	AudioChannels int    `json:"audioChannels"`
+}
+
+// TranscodeOptions configures how a converter mixes the streams of a channel
+type TranscodeOptions struct {
+	RtcChannel   string                `json:"rtcChannel"`
+	AudioOptions ConverterAudioOptions `json:"audioOptions"`
+	VideoOptions ConverterVideoOptions `json:"videoOptions"`
+}
+
+// Converter pushes a channel to a CDN over RTMP
+type Converter struct {
+	ID               string            `json:"id,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	TranscodeOptions *TranscodeOptions `json:"transcodeOptions,omitempty"`
+	RtmpURL          string            `json:"rtmpUrl,omitempty"`
+	IdleTimeout      int               `json:"idleTimeout,omitempty"`
+	State            string            `json:"state,omitempty"`
+}
+
+// ConverterRequest is the body of requests and responses of the Media Push RESTful API
+type ConverterRequest struct {
+	Converter Converter `json:"converter"`
+}
+
+// mediaPushURL returns the URL of the converters of the project in MEDIA_PUSH_REGION
+func mediaPushURL(project *AgoraProject) string {
+	return "https://api.agora.io/" + viper.GetString("MEDIA_PUSH_REGION") + "/v1/projects/" + project.appID() + "/rtmp-converters"
+}
+
+// StartMediaPush creates a converter that mixes every user in a channel and pushes the result to an RTMP URL.
+// The video uses the same size, frame rate and bitrate as recordings
+func (c *AgoraClient) StartMediaPush(project *AgoraProject, channel string, name string, rtmpURL string) (*Converter, error) {
+	requestBody, err := json.Marshal(&ConverterRequest{
+		Converter: Converter{
+			Name: name,
+			TranscodeOptions: &TranscodeOptions{
+				RtcChannel: channel,
+				AudioOptions: ConverterAudioOptions{
+					CodecProfile:  "LC-AAC",
+					SampleRate:    48000,
+					Bitrate:       128,
+					AudioChannels: 2,
+				},
+				VideoOptions: ConverterVideoOptions{
+					Canvas: ConverterCanvas{
+						Width:  viper.GetInt("RECORDING_WIDTH"),
+						Height: viper.GetInt("RECORDING_HEIGHT"),
+					},
+					LayoutType: 1,
+					Vertical: ConverterVerticalLayout{
+						FillMode: "fill",
+					},
+					Codec:     "H.264",
+					FrameRate: viper.GetInt("RECORDING_FPS"),
+					Bitrate:   viper.GetInt("RECORDING_BITRATE"),
+				},
+			},
+			RtmpURL:     rtmpURL,
+			IdleTimeout: 300,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", mediaPushURL(project), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Creating converter failed with status %d", resp.StatusCode)
+	}
+
+	var result ConverterRequest
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.Converter, nil
+}
+
+// StopMediaPush deletes a converter, which stops pushing its channel
+func (c *AgoraClient) StopMediaPush(project *AgoraProject, converterID string) error {
+	req, err := http.NewRequest("DELETE", mediaPushURL(project)+"/"+converterID, nil)
+	if err != nil {
+		return err
+	}
+
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return ErrConverterNotFound
+	}
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Deleting converter failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
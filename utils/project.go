@@ -0,0 +1,57 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// AgoraProject holds the credentials of an Agora project. Tokens for a channel have to be signed by the project the
+// channel's RESTful API requests are made for, so both always come from the same project. Functions that take a project
+// use the project configured for the deployment when it is nil
+type AgoraProject struct {
+	AppID               string
+	AppCertificate      string
+	CustomerID          string
+	CustomerCertificate string
+}
+
+// DefaultProject returns the Agora project configured for the deployment with APP_ID, APP_CERTIFICATE,
+// CUSTOMER_ID and CUSTOMER_CERTIFICATE
+func DefaultProject() *AgoraProject {
+	return &AgoraProject{
+		AppID:               viper.GetString("APP_ID"),
+		AppCertificate:      viper.GetString("APP_CERTIFICATE"),
+		CustomerID:          viper.GetString("CUSTOMER_ID"),
+		CustomerCertificate: viper.GetString("CUSTOMER_CERTIFICATE"),
+	}
+}
+
+// orDefault returns the project, or the project configured for the deployment when it is nil
+func (project *AgoraProject) orDefault() *AgoraProject {
+	if project == nil {
+		return DefaultProject()
+	}
+
+	return project
+}
+
+func (project *AgoraProject) appID() string {
+	return project.orDefault().AppID
+}
+
+// authorize sets the customer credentials of the project on a request to the Agora RESTful API
+func (project *AgoraProject) authorize(req *http.Request) {
+	credentials := project.orDefault()
+	req.SetBasicAuth(credentials.CustomerID, credentials.CustomerCertificate)
+}
@@ -0,0 +1,79 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// PubSub delivers messages published on a topic to the subscribers of that topic
+type PubSub interface {
+	// Subscribe returns a channel that receives the messages published on topic until ctx is done,
+	// after which the channel is closed
+	Subscribe(ctx context.Context, topic string) <-chan []byte
+	// Publish sends a message to the current subscribers of topic
+	Publish(topic string, message []byte)
+}
+
+// MemoryPubSub delivers messages published on a topic to every subscriber of that topic within this process
+type MemoryPubSub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+// NewPubSub creates an empty MemoryPubSub
+func NewPubSub() *MemoryPubSub {
+	return &MemoryPubSub{
+		subscribers: map[string]map[chan []byte]struct{}{},
+	}
+}
+
+// Subscribe returns a channel that receives the messages published on topic until ctx is done,
+// after which the channel is closed
+func (ps *MemoryPubSub) Subscribe(ctx context.Context, topic string) <-chan []byte {
+	messages := make(chan []byte, 16)
+
+	ps.mu.Lock()
+	if ps.subscribers[topic] == nil {
+		ps.subscribers[topic] = map[chan []byte]struct{}{}
+	}
+	ps.subscribers[topic][messages] = struct{}{}
+	ps.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		ps.mu.Lock()
+		delete(ps.subscribers[topic], messages)
+		if len(ps.subscribers[topic]) == 0 {
+			delete(ps.subscribers, topic)
+		}
+		close(messages)
+		ps.mu.Unlock()
+	}()
+
+	return messages
+}
+
+// Publish sends a message to the current subscribers of topic. Subscribers that are not keeping up
+// miss the message instead of blocking the publisher
+func (ps *MemoryPubSub) Publish(topic string, message []byte) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for subscriber := range ps.subscribers[topic] {
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+}
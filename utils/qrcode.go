@@ -0,0 +1,527 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QRCode is a QR code encoding bytes with error correction level M, which recovers from 15% of the code being
+// damaged. Versions 1 to 20 are supported, which hold up to 666 bytes
+type QRCode struct {
+	// Size is the number of modules on each side of the code, without its quiet zone
+	Size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// qrQuietZone is the number of light modules around a code that scanners need to find it
+const qrQuietZone = 4
+
+// qrBlocks describes the error correction blocks of a version at level M
+type qrBlocks struct {
+	ecPerBlock  int
+	group1      int
+	group1Data  int
+	group2      int
+	group2Data  int
+	remainder   int
+	alignment   []int
+	countLength int
+}
+
+func (b qrBlocks) dataCodewords() int {
+	return b.group1*b.group1Data + b.group2*b.group2Data
+}
+
+// qrVersions are the versions the encoder supports, indexed by version - 1
+var qrVersions = []qrBlocks{
+	{10, 1, 16, 0, 0, 0, nil, 8},
+	{16, 1, 28, 0, 0, 7, []int{6, 18}, 8},
+	{26, 1, 44, 0, 0, 7, []int{6, 22}, 8},
+	{18, 2, 32, 0, 0, 7, []int{6, 26}, 8},
+	{24, 2, 43, 0, 0, 7, []int{6, 30}, 8},
+	{16, 4, 27, 0, 0, 7, []int{6, 34}, 8},
+	{18, 4, 31, 0, 0, 0, []int{6, 22, 38}, 8},
+	{22, 2, 38, 2, 39, 0, []int{6, 24, 42}, 8},
+	{22, 3, 36, 2, 37, 0, []int{6, 26, 46}, 8},
+	{26, 4, 43, 1, 44, 0, []int{6, 28, 50}, 16},
+	{30, 1, 50, 4, 51, 0, []int{6, 30, 54}, 16},
+	{22, 6, 36, 2, 37, 0, []int{6, 32, 58}, 16},
+	{22, 8, 37, 1, 38, 0, []int{6, 34, 62}, 16},
+	{24, 4, 40, 5, 41, 3, []int{6, 26, 46, 66}, 16},
+	{24, 5, 41, 5, 42, 3, []int{6, 26, 48, 70}, 16},
+	{28, 7, 45, 3, 46, 3, []int{6, 26, 50, 74}, 16},
+	{28, 10, 46, 1, 47, 3, []int{6, 30, 54, 78}, 16},
+	{26, 9, 43, 4, 44, 3, []int{6, 30, 56, 82}, 16},
+	{26, 3, 44, 11, 45, 3, []int{6, 30, 58, 86}, 16},
+	{26, 3, 41, 13, 42, 3, []int{6, 34, 62, 90}, 16},
+}
+
+// ErrQRCodeTooLong is returned when content does not fit in the largest supported version
+var ErrQRCodeTooLong = errors.New("Content is too long for a QR code")
+
+// NewQRCode encodes content in byte mode in the smallest version it fits in
+func NewQRCode(content []byte) (*QRCode, error) {
+	for index, blocks := range qrVersions {
+		if 4+blocks.countLength+8*len(content) <= 8*blocks.dataCodewords() {
+			code := newQRCode(index + 1)
+			code.draw(blocks, qrCodewords(content, blocks))
+			return code, nil
+		}
+	}
+
+	return nil, ErrQRCodeTooLong
+}
+
+func newQRCode(version int) *QRCode {
+	size := 17 + 4*version
+	code := &QRCode{
+		Size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+
+	for y := range code.modules {
+		code.modules[y] = make([]bool, size)
+		code.function[y] = make([]bool, size)
+	}
+
+	return code
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (code *QRCode) Dark(x int, y int) bool {
+	return code.modules[y][x]
+}
+
+// qrBits appends bits to a bit string
+type qrBits []bool
+
+func (bits *qrBits) append(value int, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bits = append(*bits, (value>>uint(i))&1 == 1)
+	}
+}
+
+// qrCodewords encodes content into data codewords and interleaves them with their error correction codewords
+func qrCodewords(content []byte, blocks qrBlocks) []byte {
+	capacity := 8 * blocks.dataCodewords()
+
+	bits := qrBits{}
+	bits.append(0x4, 4)
+	bits.append(len(content), blocks.countLength)
+	for _, b := range content {
+		bits.append(int(b), 8)
+	}
+
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	data := make([]byte, 0, blocks.dataCodewords())
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for _, bit := range bits[i : i+8] {
+			b <<= 1
+			if bit {
+				b |= 1
+			}
+		}
+		data = append(data, b)
+	}
+
+	for pad := byte(0xEC); len(data) < blocks.dataCodewords(); pad ^= 0xEC ^ 0x11 {
+		data = append(data, pad)
+	}
+
+	divisor := qrGenerator(blocks.ecPerBlock)
+	dataBlocks := [][]byte{}
+	ecBlocks := [][]byte{}
+	offset := 0
+	for i := 0; i < blocks.group1+blocks.group2; i++ {
+		length := blocks.group1Data
+		if i >= blocks.group1 {
+			length = blocks.group2Data
+		}
+
+		block := data[offset : offset+length]
+		offset += length
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, qrRemainder(block, divisor))
+	}
+
+	result := []byte{}
+	for i := 0; i < blocks.group2Data || i < blocks.group1Data; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	for i := 0; i < blocks.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+// qrMultiply multiplies two elements of GF(256) modulo the polynomial the QR specification uses
+func qrMultiply(x byte, y byte) byte {
+	var result byte
+	for i := 7; i >= 0; i-- {
+		carry := result >> 7
+		result = result<<1 ^ carry*0x1D
+		result ^= (y >> uint(i) & 1) * x
+	}
+
+	return result
+}
+
+// qrGenerator returns the coefficients of the Reed-Solomon generator polynomial of a degree, highest first without
+// the leading 1
+func qrGenerator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// qrRemainder computes the error correction codewords of a block
+func qrRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= qrMultiply(coefficient, factor)
+		}
+	}
+
+	return result
+}
+
+// setFunction draws a module that is part of a function pattern, which data is not placed on or masked
+func (code *QRCode) setFunction(x int, y int, dark bool) {
+	code.modules[y][x] = dark
+	code.function[y][x] = true
+}
+
+// draw lays out the function patterns and codewords and applies the mask that makes the code easiest to read
+func (code *QRCode) draw(blocks qrBlocks, codewords []byte) {
+	size := code.Size
+	version := (size - 17) / 4
+
+	for i := 0; i < size; i++ {
+		code.setFunction(6, i, i%2 == 0)
+		code.setFunction(i, 6, i%2 == 0)
+	}
+
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || x >= size || y < 0 || y >= size {
+					continue
+				}
+
+				distance := qrMax(qrAbs(dx), qrAbs(dy))
+				code.setFunction(x, y, distance != 2 && distance != 4)
+			}
+		}
+	}
+
+	for i, cx := range blocks.alignment {
+		for j, cy := range blocks.alignment {
+			// Alignment patterns are left out where they would overlap the finder patterns
+			last := len(blocks.alignment) - 1
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					code.setFunction(cx+dx, cy+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format information, which is drawn once the mask is chosen
+	code.drawFormat(0)
+
+	if version >= 7 {
+		remainder := version
+		for i := 0; i < 12; i++ {
+			remainder = remainder<<1 ^ (remainder>>11)*0x1F25
+		}
+
+		bits := version<<12 | remainder
+		for i := 0; i < 18; i++ {
+			dark := (bits>>uint(i))&1 == 1
+			a, b := size-11+i%3, i/3
+			code.setFunction(a, b, dark)
+			code.setFunction(b, a, dark)
+		}
+	}
+
+	index := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vertical := 0; vertical < size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = size - 1 - vertical
+				}
+
+				if !code.function[y][x] && index < len(codewords)*8 {
+					code.modules[y][x] = (codewords[index>>3]>>uint(7-index&7))&1 == 1
+					index++
+				}
+			}
+		}
+	}
+
+	best, lowest := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormat(mask)
+		penalty := code.penalty()
+		if lowest < 0 || penalty < lowest {
+			best, lowest = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+
+	code.applyMask(best)
+	code.drawFormat(best)
+}
+
+// drawFormat draws both copies of the format information, which holds the error correction level and the mask
+func (code *QRCode) drawFormat(mask int) {
+	// Level M is encoded as 00
+	data := mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+
+	bit := func(i int) bool {
+		return (bits>>uint(i))&1 == 1
+	}
+
+	size := code.Size
+	for i := 0; i <= 5; i++ {
+		code.setFunction(8, i, bit(i))
+	}
+	code.setFunction(8, 7, bit(6))
+	code.setFunction(8, 8, bit(7))
+	code.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		code.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		code.setFunction(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		code.setFunction(8, size-15+i, bit(i))
+	}
+	code.setFunction(8, size-8, true)
+}
+
+// applyMask inverts the data modules the mask selects. Applying a mask twice undoes it
+func (code *QRCode) applyMask(mask int) {
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			if invert && !code.function[y][x] {
+				code.modules[y][x] = !code.modules[y][x]
+			}
+		}
+	}
+}
+
+// qrFinderLike are the module sequences that look like a finder pattern to scanners
+var qrFinderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores how hard the code is to scan as described in section 8.8.2 of ISO/IEC 18004
+func (code *QRCode) penalty() int {
+	size := code.Size
+	result := 0
+	dark := 0
+
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i <= size; i++ {
+			if i < size && get(i) == get(i-1) {
+				run++
+				continue
+			}
+
+			if run >= 5 {
+				result += 3 + run - 5
+			}
+			run = 1
+		}
+
+		for start := 0; start+11 <= size; start++ {
+			for _, pattern := range qrFinderLike {
+				matches := true
+				for k, value := range pattern {
+					if get(start+k) != value {
+						matches = false
+						break
+					}
+				}
+
+				if matches {
+					result += 40
+				}
+			}
+		}
+	}
+
+	for i := 0; i < size; i++ {
+		row, column := i, i
+		line(func(x int) bool { return code.modules[row][x] })
+		line(func(y int) bool { return code.modules[y][column] })
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if code.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < size && y+1 < size {
+				value := code.modules[y][x]
+				if code.modules[y][x+1] == value && code.modules[y+1][x] == value && code.modules[y+1][x+1] == value {
+					result += 3
+				}
+			}
+		}
+	}
+
+	deviation := qrAbs(dark*100/(size*size) - 50)
+	return result + deviation/5*10
+}
+
+// PNG renders the code with its quiet zone, drawing each module as a square of scale pixels
+func (code *QRCode) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		return nil, fmt.Errorf("Invalid QR code scale %d", scale)
+	}
+
+	width := (code.Size + 2*qrQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{color.White, color.Black})
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.modules[y][x] {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+qrQuietZone)*scale+dx, (y+qrQuietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+
+	var result bytes.Buffer
+	err := png.Encode(&result, img)
+	return result.Bytes(), err
+}
+
+// SVG renders the code with its quiet zone as a scalable image with one unit per module
+func (code *QRCode) SVG() string {
+	width := code.Size + 2*qrQuietZone
+
+	var path strings.Builder
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#ffffff"/><path d="%s" fill="#000000"/></svg>`, width, width, path.String())
+}
+
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func qrMax(x int, y int) int {
+	if x > y {
+		return x
+	}
+
+	return y
+}
@@ -12,29 +12,65 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"io"
+	"math/big"
 	mrand "math/rand"
 
 	"github.com/gofrs/uuid"
+	"github.com/spf13/viper"
 )
 
-// GenerateDTMF generates a random string of 8 digits
+// GenerateDTMF generates a random string of DTMF_LENGTH digits, which is kept between 4 and 16
 func GenerateDTMF() (*string, error) {
-	const size = 8
+	size := viper.GetInt("DTMF_LENGTH")
+	if size < 4 {
+		size = 4
+	} else if size > 16 {
+		size = 16
+	}
+
+	digits, err := GenerateDigits(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &digits, nil
+}
+
+// GenerateDigits generates a random string of size digits
+func GenerateDigits(size int) (string, error) {
 	table := [...]byte{'1', '2', '3', '4', '5', '6', '7', '8', '9', '0'}
 
 	b := make([]byte, size)
 	n, err := io.ReadAtLeast(rand.Reader, b, size)
 	if n != size {
-		return nil, err
+		return "", err
 	}
 
 	for i := 0; i < len(b); i++ {
 		b[i] = table[int(b[i])%len(table)]
 	}
 
-	result := string(b)
-	return &result, nil
+	return string(b), nil
+}
+
+// slugAlphabet leaves out characters that are easily confused with each other, like 0 and O or 1 and l
+const slugAlphabet = "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// GenerateSlug generates a random string of size characters that is short enough to type from a link
+func GenerateSlug(size int) (string, error) {
+	b := make([]byte, size)
+	for i := range b {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(slugAlphabet))))
+		if err != nil {
+			return "", err
+		}
+
+		b[i] = slugAlphabet[index.Int64()]
+	}
+
+	return string(b), nil
 }
 
 // RandomRange generates a random range in a particular range
@@ -52,3 +88,33 @@ func GenerateUUID() (string, error) {
 
 	return uuid.String(), nil
 }
+
+// GenerateSecret generates a random 256 bit secret encoded as hex after a prefix that tells what the secret is for
+func GenerateSecret(prefix string) (string, error) {
+	b := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// IDGenerator generates the random identifiers of channels, like their names, passphrases and DTMFs
+type IDGenerator interface {
+	GenerateUUID() (string, error)
+	GenerateDTMF() (*string, error)
+}
+
+// RandomIDGenerator generates identifiers with GenerateUUID and GenerateDTMF
+type RandomIDGenerator struct{}
+
+// GenerateUUID generates a uuid string
+func (RandomIDGenerator) GenerateUUID() (string, error) {
+	return GenerateUUID()
+}
+
+// GenerateDTMF generates a random string of DTMF_LENGTH digits
+func (RandomIDGenerator) GenerateDTMF() (*string, error) {
+	return GenerateDTMF()
+}
@@ -0,0 +1,73 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RateLimit is the number of requests allowed per minute. Up to a minute's worth of requests can be made at once
+type RateLimit int
+
+// rateLimitScript implements a token bucket kept in a Redis hash. It refills the bucket for the time since it was
+// last updated, takes a token if there is one and returns whether a token was taken and how long to wait otherwise
+var rateLimitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1]) or burst
+local updated = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = (1 - tokens) / rate
+end
+redis.call("HSET", KEYS[1], "tokens", tokens, "updated", now)
+redis.call("EXPIRE", KEYS[1], math.ceil(burst / rate) + 1)
+return {allowed, tostring(wait)}
+`)
+
+// RateLimiter limits the rate of requests per key with token buckets stored in Redis, so that the limits hold
+// across every instance
+type RateLimiter struct {
+	Client *redis.Client
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it returns false and how long to wait
+// until a token is available. A limit of 0 or less allows every request
+func (limiter *RateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	if limit <= 0 {
+		return true, 0, nil
+	}
+
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+	result, err := rateLimitScript.Run(ctx, limiter.Client, []string{"ratelimit:" + key}, float64(limit)/60, int(limit), now).Result()
+	if err != nil {
+		return false, 0, err
+	}
+
+	values := result.([]interface{})
+	wait, err := strconv.ParseFloat(values[1].(string), 64)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return values[0].(int64) == 1, time.Duration(wait * float64(time.Second)), nil
+}
@@ -12,27 +12,73 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/samyak-jain/agora_backend/pkg/models"
+	"github.com/samyak-jain/agora_backend/utils/rtctoken"
 	"github.com/spf13/viper"
 )
 
+// ErrRecordingNotFound is returned when cloud recording no longer knows about a recording session
+var ErrRecordingNotFound = errors.New("Recording not found")
+
 // Recorder manages cloud recording
 type Recorder struct {
-	http.Client
 	Channel string
 	Token   string
 	UID     int32
 	RID     string
 	SID     string
-	Logger  *Logger
+	// Mode is the cloud recording mode, either "mix" or "web". Defaults to "mix" when empty
+	Mode string
+	// Storage is where the recording is uploaded to. Defaults to the storage configured for the deployment when nil
+	Storage StorageProvider
+	// Project is the Agora project the channel belongs to. Defaults to the project configured for the deployment
+	// when nil
+	Project *AgoraProject
+	// Area is the area the recording is made in. Defaults to AGORA_AREA when empty
+	Area   models.AgoraArea
+	Logger *Logger
+	// Client sends the requests of the recording to Agora
+	Client *AgoraClient
+	// Context is used for the requests to cloud recording so that they are traced as part of the request that made
+	// them. Defaults to context.Background() when nil
+	Context context.Context
+}
+
+func (rec *Recorder) context() context.Context {
+	if rec.Context == nil {
+		return context.Background()
+	}
+
+	return rec.Context
+}
+
+func (rec *Recorder) area() models.AgoraArea {
+	if rec.Area == "" {
+		return DefaultArea()
+	}
+
+	return rec.Area
+}
+
+func (rec *Recorder) mode() string {
+	if rec.Mode == "" {
+		return "mix"
+	}
+
+	return rec.Mode
 }
 
 type AcquireClientRequest struct {
-	ResourceExpiredHour int `json:"resourceExpiredHour,omitempty"`
+	ResourceExpiredHour int    `json:"resourceExpiredHour,omitempty"`
+	Scene               int    `json:"scene,omitempty"`
+	Region              string `json:"region,omitempty"`
 }
 
 type AcquireRequest struct {
@@ -86,9 +132,58 @@ type StartRecordRequest struct {
 	ClientRequest ClientRequest `json:"clientRequest"`
 }
 
+// WebRecordingConfig contains the parameters of the page that is recorded in web recording mode
+type WebRecordingConfig struct {
+	URL              string `json:"url"`
+	AudioProfile     int    `json:"audioProfile"`
+	VideoWidth       int    `json:"videoWidth"`
+	VideoHeight      int    `json:"videoHeight"`
+	MaxRecordingHour int    `json:"maxRecordingHour"`
+}
+
+type ExtensionService struct {
+	ServiceName       string             `json:"serviceName"`
+	ErrorHandlePolicy string             `json:"errorHandlePolicy"`
+	ServiceParam      WebRecordingConfig `json:"serviceParam"`
+}
+
+type ExtensionServiceConfig struct {
+	ErrorHandlePolicy string             `json:"errorHandlePolicy"`
+	ExtensionServices []ExtensionService `json:"extensionServices"`
+}
+
+type WebClientRequest struct {
+	ExtensionServiceConfig ExtensionServiceConfig `json:"extensionServiceConfig"`
+	RecordingFileConfig    RecordingFileConfig    `json:"recordingFileConfig"`
+	StorageConfig          StorageConfig          `json:"storageConfig"`
+}
+
+type StartWebRecordRequest struct {
+	Cname         string           `json:"cname"`
+	UID           string           `json:"uid"`
+	ClientRequest WebClientRequest `json:"clientRequest"`
+}
+
+// AcquireResponse is the response of the acquire endpoint
+type AcquireResponse struct {
+	ResourceID string `json:"resourceId"`
+}
+
+// RecordingResponse is the response of the endpoints that start and update a recording
+type RecordingResponse struct {
+	ResourceID string `json:"resourceId"`
+	SID        string `json:"sid"`
+}
+
+// StopResponse is the response of the stop endpoint, with the files that were recorded
+type StopResponse struct {
+	RecordingResponse
+	ServerResponse QueryServerResponse `json:"serverResponse"`
+}
+
 // Acquire runs the acquire endpoint for Cloud Recording
 func (rec *Recorder) Acquire() error {
-	creds, err := GenerateUserCredentials(rec.Channel, false, false)
+	creds, err := GenerateUserCredentials(rec.Project, rec.Channel, rtctoken.RolePublisher, TokenExpiry(nil), false, false, rec.area())
 	if err != nil {
 		return err
 	}
@@ -96,59 +191,97 @@ func (rec *Recorder) Acquire() error {
 	rec.UID = int32(creds.UID)
 	rec.Token = creds.Rtc
 
+	clientRequest := AcquireClientRequest{
+		ResourceExpiredHour: 24,
+		Region:              recordingRegions[rec.area()],
+	}
+
+	if rec.mode() == "web" {
+		clientRequest.Scene = 1
+	}
+
 	requestBody, err := json.Marshal(&AcquireRequest{
-		Cname: rec.Channel,
-		UID:   strconv.Itoa(int(rec.UID)),
-		ClientRequest: AcquireClientRequest{
-			ResourceExpiredHour: 24,
-		},
+		Cname:         rec.Channel,
+		UID:           strconv.Itoa(int(rec.UID)),
+		ClientRequest: clientRequest,
 	})
 
-	req, err := http.NewRequest("POST", "https://api.agora.io/v1/apps/"+viper.GetString("APP_ID")+"/cloud_recording/acquire",
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/acquire",
 		bytes.NewBuffer(requestBody))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(viper.GetString("CUSTOMER_ID"), viper.GetString("CUSTOMER_CERTIFICATE"))
+	rec.Project.authorize(req)
 
-	resp, err := rec.Do(req)
+	resp, err := rec.Client.Do(req)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	var result map[string]string
-	json.NewDecoder(resp.Body).Decode(&result)
-
-	rec.RID = result["resourceId"]
+	var result AcquireResponse
+	err = decodeAgoraResponse(resp, "acquire", &result)
+	if err != nil {
+		return err
+	}
 
 	rec.Logger.Debug().Interface("Result", result).Msg("Recording Result")
 
+	if result.ResourceID == "" {
+		return errors.New("Agora acquire returned no resource ID")
+	}
+
+	rec.RID = result.ResourceID
 	return nil
 }
 
-// Start starts the recording
-func (rec *Recorder) Start(channelTitle string, secret *string) error {
+// storageConfig builds the storage config for a recording, with files prefixed by the title and the current date and time
+func (rec *Recorder) storageConfig(channelTitle string) (*StorageConfig, error) {
 	// currentTime := strconv.FormatInt(time.Now().Unix(), 10)
 	location, err := time.LoadLocation("America/Los_Angeles")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	currentTimeStamp := time.Now().In(location)
 	currentDate := currentTimeStamp.Format("20060102")
 	currentTime := currentTimeStamp.Format("150405")
 
-	transcodingConfig := TranscodingConfig{
-		Height:           720,
-		Width:            1280,
-		Bitrate:          2260,
-		Fps:              15,
-		MixedVideoLayout: 1,
-		BackgroundColor:  "#000000",
+	storage := rec.Storage
+	if storage == nil {
+		storage, err = GlobalStorageProvider()
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	storageConfig := storage.StorageConfig([]string{
+		channelTitle, currentDate, currentTime,
+	})
+	return &storageConfig, nil
+}
+
+// DefaultTranscodingConfig returns the transcoding settings configured for this deployment
+func DefaultTranscodingConfig() TranscodingConfig {
+	return TranscodingConfig{
+		Height:           viper.GetInt("RECORDING_HEIGHT"),
+		Width:            viper.GetInt("RECORDING_WIDTH"),
+		Bitrate:          viper.GetInt("RECORDING_BITRATE"),
+		Fps:              viper.GetInt("RECORDING_FPS"),
+		MixedVideoLayout: viper.GetInt("RECORDING_LAYOUT"),
+		BackgroundColor:  viper.GetString("RECORDING_BACKGROUND_COLOR"),
+	}
+}
+
+// Start starts the recording
+func (rec *Recorder) Start(channelTitle string, secret *string, transcodingConfig TranscodingConfig) error {
+	storageConfig, err := rec.storageConfig(channelTitle)
+	if err != nil {
+		return err
+	}
+
 	var recordingConfig RecordingConfig
 	if secret != nil && *secret != "" {
 
@@ -173,17 +306,8 @@ func (rec *Recorder) Start(channelTitle string, secret *string) error {
 		Cname: rec.Channel,
 		UID:   strconv.Itoa(int(rec.UID)),
 		ClientRequest: ClientRequest{
-			Token: rec.Token,
-			StorageConfig: StorageConfig{
-				Vendor:    viper.GetInt("RECORDING_VENDOR"),
-				Region:    viper.GetInt("RECORDING_REGION"),
-				Bucket:    viper.GetString("BUCKET_NAME"),
-				AccessKey: viper.GetString("BUCKET_ACCESS_KEY"),
-				SecretKey: viper.GetString("BUCKET_ACCESS_SECRET"),
-				FileNamePrefix: []string{
-					channelTitle, currentDate, currentTime,
-				},
-			},
+			Token:         rec.Token,
+			StorageConfig: *storageConfig,
 			RecordingFileConfig: RecordingFileConfig{
 				AVFileType: []string{"hls", "mp4"},
 			},
@@ -198,28 +322,108 @@ func (rec *Recorder) Start(channelTitle string, secret *string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.agora.io/v1/apps/"+viper.GetString("APP_ID")+"/cloud_recording/resourceid/"+rec.RID+"/mode/mix/start",
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/mode/mix/start",
 		bytes.NewBuffer(requestBody))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(viper.GetString("CUSTOMER_ID"), viper.GetString("CUSTOMER_CERTIFICATE"))
+	rec.Project.authorize(req)
 
-	resp, err := rec.Do(req)
+	resp, err := rec.Client.Do(req)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	var result map[string]string
-	json.NewDecoder(resp.Body).Decode(&result)
-	rec.SID = result["sid"]
+	var result RecordingResponse
+	err = decodeAgoraResponse(resp, "start", &result)
+	if err != nil {
+		return err
+	}
 
 	rec.Logger.Debug().Interface("Result", result).Msg("Recording Result")
 
+	if result.SID == "" {
+		return errors.New("Agora start returned no SID")
+	}
+
+	rec.SID = result.SID
+	return nil
+}
+
+// StartWeb starts recording the web page at the given URL instead of the streams in the channel
+func (rec *Recorder) StartWeb(pageURL string, channelTitle string) error {
+	storageConfig, err := rec.storageConfig(channelTitle)
+	if err != nil {
+		return err
+	}
+
+	recordingRequest := StartWebRecordRequest{
+		Cname: rec.Channel,
+		UID:   strconv.Itoa(int(rec.UID)),
+		ClientRequest: WebClientRequest{
+			ExtensionServiceConfig: ExtensionServiceConfig{
+				ErrorHandlePolicy: "error_abort",
+				ExtensionServices: []ExtensionService{
+					{
+						ServiceName:       "web_recorder_service",
+						ErrorHandlePolicy: "error_abort",
+						ServiceParam: WebRecordingConfig{
+							URL:              pageURL,
+							AudioProfile:     0,
+							VideoWidth:       1280,
+							VideoHeight:      720,
+							MaxRecordingHour: 72,
+						},
+					},
+				},
+			},
+			RecordingFileConfig: RecordingFileConfig{
+				AVFileType: []string{"hls", "mp4"},
+			},
+			StorageConfig: *storageConfig,
+		},
+	}
+
+	rec.Logger.Info().Interface("Start Request", recordingRequest).Msg("Web recording request")
+
+	requestBody, err := json.Marshal(&recordingRequest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/mode/web/start",
+		bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	rec.Project.authorize(req)
+
+	resp, err := rec.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	var result RecordingResponse
+	err = decodeAgoraResponse(resp, "start", &result)
+	if err != nil {
+		return err
+	}
+
+	rec.Logger.Debug().Interface("Result", result).Msg("Web Recording Result")
+
+	if result.SID == "" {
+		return errors.New("Agora start returned no SID")
+	}
+
+	rec.SID = result.SID
 	return nil
 }
 
@@ -229,17 +433,18 @@ type UpdateRecordRequest struct {
 	ClientRequest TranscodingConfig `json:"clientRequest"`
 }
 
-func ChangeRecordingMode(channel string, uid int, rid string, sid string, mode int, maxUID string, logger *Logger) error {
+// ChangeMode switches a mix mode recording to layout, with the video of maxUID in the largest region
+func (rec *Recorder) ChangeMode(layout int, maxUID string) error {
 	recordingRequest := UpdateRecordRequest{
-		Cname: channel,
-		UID:   strconv.Itoa(uid),
+		Cname: rec.Channel,
+		UID:   strconv.Itoa(int(rec.UID)),
 		ClientRequest: TranscodingConfig{
-			MixedVideoLayout: mode,
+			MixedVideoLayout: layout,
 			MaxResolutionUID: maxUID,
 		},
 	}
 
-	logger.Info().Interface("Change Recording", recordingRequest).Msg("Change Recording Mode")
+	rec.Logger.Info().Interface("Change Recording", recordingRequest).Msg("Change Recording Mode")
 
 	requestBody, err := json.Marshal(&recordingRequest)
 
@@ -247,69 +452,350 @@ func ChangeRecordingMode(channel string, uid int, rid string, sid string, mode i
 		return err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.agora.io/v1/apps/"+viper.GetString("APP_ID")+"/cloud_recording/resourceid/"+rid+"/sid/"+sid+"/mode/mix/update",
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/sid/"+rec.SID+"/mode/mix/update",
 		bytes.NewBuffer(requestBody))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(viper.GetString("CUSTOMER_ID"), viper.GetString("CUSTOMER_CERTIFICATE"))
+	rec.Project.authorize(req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := rec.Client.Do(req)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	var result map[string]string
-	json.NewDecoder(resp.Body).Decode(&result)
+	var result RecordingResponse
+	err = decodeAgoraResponse(resp, "update", &result)
+	if err != nil {
+		return err
+	}
 
-	logger.Info().Interface("response", result).Msg("Update Cloud Recording Response")
+	rec.Logger.Info().Interface("response", result).Msg("Update Cloud Recording Response")
 
 	return nil
-
 }
 
-// Stop stops the cloud recording
-func Stop(channel string, uid int, rid string, sid string, logger *Logger) error {
+// Stop stops the cloud recording in the mode and area it was started in
+func (rec *Recorder) Stop() error {
 	recordingRequest := AcquireRequest{
-		Cname:         channel,
-		UID:           strconv.Itoa(uid),
+		Cname:         rec.Channel,
+		UID:           strconv.Itoa(int(rec.UID)),
 		ClientRequest: AcquireClientRequest{},
 	}
 
-	logger.Info().Interface("Stop Request", recordingRequest).Msg("Stop Recording Request")
+	rec.Logger.Info().Interface("Stop Request", recordingRequest).Msg("Stop Recording Request")
+
+	requestBody, err := json.Marshal(&recordingRequest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/sid/"+rec.SID+"/mode/"+rec.mode()+"/stop",
+		bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	rec.Project.authorize(req)
+
+	resp, err := rec.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrRecordingNotFound
+	}
+
+	var result StopResponse
+	err = decodeAgoraResponse(resp, "stop", &result)
+	if err != nil {
+		return err
+	}
+
+	rec.Logger.Info().Interface("response", result).Msg("Stop Cloud Recording Response")
+
+	return nil
+}
+
+type AudioUIDList struct {
+	SubscribeAudioUIDs []string `json:"subscribeAudioUids"`
+}
+
+type VideoUIDList struct {
+	SubscribeVideoUIDs []string `json:"subscribeVideoUids"`
+}
+
+type StreamSubscribe struct {
+	AudioUIDList AudioUIDList `json:"audioUidList"`
+	VideoUIDList VideoUIDList `json:"videoUidList"`
+}
+
+type WebRecordingUpdate struct {
+	Onhold bool `json:"onhold"`
+}
+
+type UpdateClientRequest struct {
+	StreamSubscribe    *StreamSubscribe    `json:"streamSubscribe,omitempty"`
+	WebRecordingConfig *WebRecordingUpdate `json:"webRecordingConfig,omitempty"`
+}
+
+type UpdateSubscriptionRequest struct {
+	Cname         string              `json:"cname"`
+	UID           string              `json:"uid"`
+	ClientRequest UpdateClientRequest `json:"clientRequest"`
+}
+
+// update calls the update endpoint of the running recording
+func (rec *Recorder) update(clientRequest UpdateClientRequest) error {
+	recordingRequest := UpdateSubscriptionRequest{
+		Cname:         rec.Channel,
+		UID:           strconv.Itoa(int(rec.UID)),
+		ClientRequest: clientRequest,
+	}
+
+	rec.Logger.Info().Interface("Update Request", recordingRequest).Msg("Update Recording")
+
+	requestBody, err := json.Marshal(&recordingRequest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/sid/"+rec.SID+"/mode/"+rec.mode()+"/update",
+		bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	rec.Project.authorize(req)
+
+	resp, err := rec.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	var result RecordingResponse
+	err = decodeAgoraResponse(resp, "update", &result)
+	if err != nil {
+		rec.Logger.Error().Err(err).Msg("Error response in update recording")
+		return err
+	}
+
+	rec.Logger.Info().Interface("response", result).Msg("Update Cloud Recording Response")
+
+	return nil
+}
+
+// subscribe changes the audio and video streams the recorder is subscribed to
+func (rec *Recorder) subscribe(audioUIDs []string, videoUIDs []string) error {
+	return rec.update(UpdateClientRequest{
+		StreamSubscribe: &StreamSubscribe{
+			AudioUIDList: AudioUIDList{SubscribeAudioUIDs: audioUIDs},
+			VideoUIDList: VideoUIDList{SubscribeVideoUIDs: videoUIDs},
+		},
+	})
+}
+
+// Pause stops recording until Resume is called. In mix mode the recorder unsubscribes from every
+// stream while in web mode the page recording is put on hold
+func (rec *Recorder) Pause() error {
+	if rec.mode() == "web" {
+		return rec.update(UpdateClientRequest{WebRecordingConfig: &WebRecordingUpdate{Onhold: true}})
+	}
+
+	return rec.subscribe([]string{}, []string{})
+}
+
+// Resume continues a recording that was paused
+func (rec *Recorder) Resume() error {
+	if rec.mode() == "web" {
+		return rec.update(UpdateClientRequest{WebRecordingConfig: &WebRecordingUpdate{Onhold: false}})
+	}
+
+	return rec.subscribe([]string{"#allstream#"}, []string{"#allstream#"})
+}
+
+// Layouts supported by the mixedVideoLayout field of a mix mode recording
+const (
+	LayoutFloating = 0
+	LayoutBestFit  = 1
+	LayoutVertical = 2
+)
+
+type UpdateLayoutClientRequest struct {
+	MixedVideoLayout int    `json:"mixedVideoLayout"`
+	MaxResolutionUID string `json:"maxResolutionUid,omitempty"`
+	BackgroundColor  string `json:"backgroundColor,omitempty"`
+}
+
+type UpdateLayoutRequest struct {
+	Cname         string                    `json:"cname"`
+	UID           string                    `json:"uid"`
+	ClientRequest UpdateLayoutClientRequest `json:"clientRequest"`
+}
+
+// UpdateLayout changes the video layout of a mix mode recording while it is in progress
+func (rec *Recorder) UpdateLayout(layout int, maxResolutionUID string, backgroundColor string) error {
+	recordingRequest := UpdateLayoutRequest{
+		Cname: rec.Channel,
+		UID:   strconv.Itoa(int(rec.UID)),
+		ClientRequest: UpdateLayoutClientRequest{
+			MixedVideoLayout: layout,
+			MaxResolutionUID: maxResolutionUID,
+			BackgroundColor:  backgroundColor,
+		},
+	}
+
+	rec.Logger.Info().Interface("Update Layout Request", recordingRequest).Msg("Update Recording Layout")
 
 	requestBody, err := json.Marshal(&recordingRequest)
+	if err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("POST", "https://api.agora.io/v1/apps/"+viper.GetString("APP_ID")+"/cloud_recording/resourceid/"+rid+"/sid/"+sid+"/mode/mix/stop",
-		bytes.NewBuffer([]byte(requestBody)))
+	req, err := http.NewRequestWithContext(rec.context(), "POST", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/sid/"+rec.SID+"/mode/mix/updateLayout",
+		bytes.NewBuffer(requestBody))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(viper.GetString("CUSTOMER_ID"), viper.GetString("CUSTOMER_CERTIFICATE"))
+	rec.Project.authorize(req)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := rec.Client.Do(req)
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
 
-	var result map[string]string
-	json.NewDecoder(resp.Body).Decode(&result)
+	var result RecordingResponse
+	err = decodeAgoraResponse(resp, "updateLayout", &result)
+	if err != nil {
+		rec.Logger.Error().Err(err).Msg("Error response in update layout")
+		return err
+	}
 
-	logger.Info().Interface("response", result).Msg("Stop Cloud Recording Response")
+	rec.Logger.Info().Interface("response", result).Msg("Update Layout Response")
 
 	return nil
 }
 
+type RecordingFile struct {
+	Filename       string `json:"filename"`
+	TrackType      string `json:"trackType"`
+	UID            string `json:"uid"`
+	MixedAllUser   bool   `json:"mixedAllUser"`
+	IsPlayable     bool   `json:"isPlayable"`
+	SliceStartTime int64  `json:"sliceStartTime"`
+}
+
+type QueryServerResponse struct {
+	FileListMode    string          `json:"fileListMode"`
+	FileList        json.RawMessage `json:"fileList"`
+	Status          int             `json:"status"`
+	SliceStartTime  int64           `json:"sliceStartTime"`
+	UploadingStatus string          `json:"uploadingStatus"`
+}
+
+type QueryResponse struct {
+	ResourceID     string              `json:"resourceId"`
+	SID            string              `json:"sid"`
+	ServerResponse QueryServerResponse `json:"serverResponse"`
+	Code           int                 `json:"code"`
+	Reason         string              `json:"reason"`
+}
+
+// Files returns the recorded files irrespective of whether Agora sent the file list as a
+// single m3u8 file name ("string" mode) or as a list of file objects ("json" mode)
+func (q *QueryResponse) Files() []RecordingFile {
+	var files []RecordingFile
+	if err := json.Unmarshal(q.ServerResponse.FileList, &files); err == nil {
+		return files
+	}
+
+	var filename string
+	if err := json.Unmarshal(q.ServerResponse.FileList, &filename); err == nil && filename != "" {
+		return []RecordingFile{{
+			Filename:       filename,
+			MixedAllUser:   true,
+			IsPlayable:     true,
+			SliceStartTime: q.ServerResponse.SliceStartTime,
+		}}
+	}
+
+	return []RecordingFile{}
+}
+
+// RecordingState converts the status code returned by the query endpoint into a readable state
+func RecordingState(status int) string {
+	switch status {
+	case 0:
+		return "IDLE"
+	case 1:
+		return "INITIALIZED"
+	case 2, 3:
+		return "STARTING"
+	case 4:
+		return "STARTED"
+	case 5:
+		return "RECORDING"
+	case 6:
+		return "STOPPING"
+	case 7:
+		return "STOPPED"
+	case 8:
+		return "EXITING"
+	case 20:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Query fetches the status of an ongoing cloud recording
+func (rec *Recorder) Query() (*QueryResponse, error) {
+	req, err := http.NewRequestWithContext(rec.context(), "GET", agoraAPIURL(rec.area())+"/v1/apps/"+rec.Project.appID()+"/cloud_recording/resourceid/"+rec.RID+"/sid/"+rec.SID+"/mode/"+rec.mode()+"/query", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	rec.Project.authorize(req)
+
+	resp, err := rec.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, ErrRecordingNotFound
+	}
+
+	var result QueryResponse
+	err = decodeAgoraResponse(resp, "query", &result)
+	if err != nil {
+		rec.Logger.Error().Err(err).Msg("Error response in query recording")
+		return nil, err
+	}
+
+	rec.Logger.Debug().Interface("Result", result).Msg("Query Recording Result")
+
+	return &result, nil
+}
+
 // FirstN is to return the first N characters of a string
 func FirstN(s string, n int) string {
 	i := 0
@@ -0,0 +1,98 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisPubSub delivers messages through Redis so that subscribers connected to any instance of the backend receive
+// the messages published by every other instance. A single Redis connection is shared by all the subscribers of
+// this instance, which are fanned out to locally
+type RedisPubSub struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	local  *MemoryPubSub
+	logger *Logger
+
+	mu     sync.Mutex
+	topics map[string]int
+}
+
+// NewRedisPubSub creates a RedisPubSub and starts forwarding the messages received from Redis to local subscribers
+func NewRedisPubSub(client *redis.Client, logger *Logger) *RedisPubSub {
+	ps := &RedisPubSub{
+		client: client,
+		pubsub: client.Subscribe(context.Background()),
+		local:  NewPubSub(),
+		logger: logger,
+		topics: map[string]int{},
+	}
+
+	go func() {
+		for message := range ps.pubsub.Channel() {
+			ps.local.Publish(message.Channel, []byte(message.Payload))
+		}
+	}()
+
+	return ps
+}
+
+// Subscribe returns a channel that receives the messages published on topic by any instance until ctx is done,
+// after which the channel is closed. The Redis channel of the topic is only subscribed to while this instance has
+// subscribers for it
+func (ps *RedisPubSub) Subscribe(ctx context.Context, topic string) <-chan []byte {
+	ps.mu.Lock()
+	ps.topics[topic]++
+	if ps.topics[topic] == 1 {
+		if err := ps.pubsub.Subscribe(context.Background(), topic); err != nil {
+			ps.logger.Error().Err(err).Str("topic", topic).Msg("Could not subscribe to Redis channel")
+		}
+	}
+	ps.mu.Unlock()
+
+	messages := ps.local.Subscribe(ctx, topic)
+
+	go func() {
+		<-ctx.Done()
+
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+
+		ps.topics[topic]--
+		if ps.topics[topic] > 0 {
+			return
+		}
+
+		delete(ps.topics, topic)
+		if err := ps.pubsub.Unsubscribe(context.Background(), topic); err != nil {
+			ps.logger.Error().Err(err).Str("topic", topic).Msg("Could not unsubscribe from Redis channel")
+		}
+	}()
+
+	return messages
+}
+
+// Publish sends a message to the subscribers of topic on every instance. Messages that cannot be published are
+// logged and dropped, as with subscribers that are not keeping up
+func (ps *RedisPubSub) Publish(topic string, message []byte) {
+	if err := ps.client.Publish(context.Background(), topic, message).Err(); err != nil {
+		ps.logger.Error().Err(err).Str("topic", topic).Msg("Could not publish to Redis channel")
+	}
+}
+
+// Close stops receiving messages from Redis
+func (ps *RedisPubSub) Close() error {
+	return ps.pubsub.Close()
+}
@@ -0,0 +1,152 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slackSignatureTolerance is how old the timestamp of a signed Slack request can be
+const slackSignatureTolerance = 5 * time.Minute
+
+var slackClient = &http.Client{Timeout: 10 * time.Second}
+
+// SlackMessage is a message posted in reply to a slash command or an interaction.
+// Messages are only shown to the user that sent the command unless ResponseType is in_channel
+type SlackMessage struct {
+	ResponseType    string       `json:"response_type,omitempty"`
+	ReplaceOriginal bool         `json:"replace_original,omitempty"`
+	Text            string       `json:"text"`
+	Blocks          []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block. Only the fields of section, actions and context blocks are supported
+type SlackBlock struct {
+	Type string     `json:"type"`
+	Text *SlackText `json:"text,omitempty"`
+	// Elements are the buttons of actions blocks or the texts of context blocks
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// SlackText is a plain_text or mrkdwn text object
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackElement is an interactive element of an actions block, like a button
+type SlackElement struct {
+	Type     string     `json:"type"`
+	Text     *SlackText `json:"text,omitempty"`
+	ActionID string     `json:"action_id,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	Value    string     `json:"value,omitempty"`
+	Style    string     `json:"style,omitempty"`
+}
+
+// SlackSection is a section block with mrkdwn text
+func SlackSection(text string) SlackBlock {
+	return SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: text}}
+}
+
+// SlackContext is a context block with mrkdwn text, shown smaller than the text of sections
+func SlackContext(text string) SlackBlock {
+	return SlackBlock{Type: "context", Elements: []interface{}{SlackText{Type: "mrkdwn", Text: text}}}
+}
+
+// SlackActions is an actions block with buttons
+func SlackActions(buttons ...SlackElement) SlackBlock {
+	block := SlackBlock{Type: "actions"}
+	for _, button := range buttons {
+		block.Elements = append(block.Elements, button)
+	}
+
+	return block
+}
+
+// SlackButton is a button, that opens url when it is not empty
+func SlackButton(actionID string, text string, url string, value string) SlackElement {
+	return SlackElement{Type: "button", Text: &SlackText{Type: "plain_text", Text: text}, ActionID: actionID, URL: url, Value: value}
+}
+
+// SlackEscape escapes the characters Slack treats as control characters in message text
+func SlackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// VerifySlackSignature checks that a request was sent by Slack by verifying the X-Slack-Signature of its body,
+// which is signed with the signing secret of the Slack app along with X-Slack-Request-Timestamp
+func VerifySlackSignature(body []byte, timestamp string, signature string, secret string, now time.Time) error {
+	if secret == "" {
+		return errors.New("Slack signing secret is not set")
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("Slack request has no timestamp")
+	}
+
+	age := now.Sub(time.Unix(signedAt, 0))
+	if age > slackSignatureTolerance || age < -slackSignatureTolerance {
+		return errors.New("Slack signature has expired")
+	}
+
+	provided, err := hex.DecodeString(strings.TrimPrefix(signature, "v0="))
+	if err != nil || !strings.HasPrefix(signature, "v0=") {
+		return errors.New("Slack signature is malformed")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+
+	if !hmac.Equal(provided, mac.Sum(nil)) {
+		return errors.New("Slack signature does not match")
+	}
+
+	return nil
+}
+
+// PostSlackMessage sends a message to the response URL of a slash command or an interaction, which Slack accepts
+// for 30 minutes after the user acted
+func PostSlackMessage(responseURL string, message *SlackMessage) error {
+	if !strings.HasPrefix(responseURL, "https://hooks.slack.com/") {
+		return fmt.Errorf("Invalid Slack response URL %q", responseURL)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	response, err := slackClient.Post(responseURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		contents, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("Slack returned %d: %s", response.StatusCode, string(contents))
+	}
+
+	return nil
+}
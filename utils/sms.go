@@ -0,0 +1,160 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// SMSSender sends text messages to phone numbers
+type SMSSender interface {
+	// SendSMS delivers a text message to a phone number in E.164 format
+	SendSMS(to string, body string) error
+}
+
+// NewSMSSender creates the sender selected by SMS_PROVIDER, which is either twilio or sns. It returns nil when
+// SMS_PROVIDER is not set, in which case the features that send text messages are unavailable
+func NewSMSSender() (SMSSender, error) {
+	switch viper.GetString("SMS_PROVIDER") {
+	case "":
+		return nil, nil
+	case "twilio":
+		return &TwilioSMS{
+			AccountSID: viper.GetString("TWILIO_ACCOUNT_SID"),
+			AuthToken:  viper.GetString("TWILIO_AUTH_TOKEN"),
+			From:       viper.GetString("TWILIO_FROM_NUMBER"),
+		}, nil
+	case "sns":
+		return &SNSSMS{
+			Region:    viper.GetString("SNS_REGION"),
+			AccessKey: viper.GetString("SNS_ACCESS_KEY"),
+			SecretKey: viper.GetString("SNS_SECRET_KEY"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown SMS provider %s", viper.GetString("SMS_PROVIDER"))
+	}
+}
+
+// SMSTracker is implemented by senders that can report the delivery status of text messages
+type SMSTracker interface {
+	// SendTrackedSMS delivers a text message and has the provider post changes to its delivery status to statusURL.
+	// It returns the ID the provider gave the message
+	SendTrackedSMS(to string, body string, statusURL string) (string, error)
+}
+
+var smsClient = &http.Client{Timeout: 10 * time.Second}
+
+// TwilioSMS sends text messages with the Twilio Messages API
+type TwilioSMS struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+}
+
+// SendSMS delivers a text message to a phone number in E.164 format
+func (t *TwilioSMS) SendSMS(to string, body string) error {
+	_, err := t.SendTrackedSMS(to, body, "")
+	return err
+}
+
+// SendTrackedSMS delivers a text message and has Twilio post its MessageStatus to statusURL
+func (t *TwilioSMS) SendTrackedSMS(to string, body string, statusURL string) (string, error) {
+	form := url.Values{"To": {to}, "From": {t.From}, "Body": {body}}
+	if statusURL != "" {
+		form.Set("StatusCallback", statusURL)
+	}
+
+	req, err := http.NewRequest("POST", "https://api.twilio.com/2010-04-01/Accounts/"+t.AccountSID+"/Messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := smsClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	contents, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode >= 300 {
+		return "", fmt.Errorf("SMS request failed with %d: %s", response.StatusCode, string(contents))
+	}
+
+	var message struct {
+		SID string `json:"sid"`
+	}
+	err = json.Unmarshal(contents, &message)
+	return message.SID, err
+}
+
+// SNSSMS sends text messages with AWS SNS
+type SNSSMS struct {
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+var snsSigner = v4Signer{
+	algorithm:    "AWS4-HMAC-SHA256",
+	keyPrefix:    "AWS4",
+	service:      "sns",
+	terminator:   "aws4_request",
+	headerPrefix: "X-Amz-",
+	payloadHash:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+}
+
+// SendSMS delivers a transactional text message to a phone number in E.164 format
+func (s *SNSSMS) SendSMS(to string, body string) error {
+	query := map[string]string{
+		"Action":                         "Publish",
+		"Version":                        "2010-03-31",
+		"PhoneNumber":                    to,
+		"Message":                        body,
+		"MessageAttributes.entry.1.Name": "AWS.SNS.SMS.SMSType",
+		"MessageAttributes.entry.1.Value.DataType":    "String",
+		"MessageAttributes.entry.1.Value.StringValue": "Transactional",
+	}
+
+	signedURL := snsSigner.presign("GET", "sns."+s.Region+".amazonaws.com", "/", s.Region, s.AccessKey, s.SecretKey, query, time.Minute, time.Now())
+	req, err := http.NewRequest("GET", signedURL, nil)
+	if err != nil {
+		return err
+	}
+
+	return sendSMSRequest(req)
+}
+
+// sendSMSRequest makes a request to an SMS API and turns unsuccessful responses into errors
+func sendSMSRequest(req *http.Request) error {
+	response, err := smsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		contents, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("SMS request failed with %d: %s", response.StatusCode, string(contents))
+	}
+
+	return nil
+}
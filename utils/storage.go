@@ -0,0 +1,316 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Storage vendors supported by cloud recording
+const (
+	VendorAWS     = 1
+	VendorAlibaba = 2
+	VendorAzure   = 5
+	VendorGoogle  = 6
+)
+
+// StorageProvider is a bucket that cloud recording uploads recordings to
+type StorageProvider interface {
+	// StorageConfig returns the storage config sent to cloud recording when a recording is started
+	StorageConfig(fileNamePrefix []string) StorageConfig
+	// PresignURL creates a download URL for an object that is valid for the given duration
+	PresignURL(key string, expiry time.Duration) (string, error)
+	// PresignUpload creates a URL an object can be uploaded to with a PUT request, valid for the given duration
+	PresignUpload(key string, expiry time.Duration) (*PresignedUpload, error)
+	// ListObjects returns the keys of every object that starts with prefix
+	ListObjects(prefix string) ([]string, error)
+	// DeleteObjects deletes every object that starts with prefix and returns the number of deleted objects
+	DeleteObjects(prefix string) (int, error)
+}
+
+// PresignedUpload is a URL that clients upload an object to with a PUT request, sending the headers along with it
+type PresignedUpload struct {
+	URL     string
+	Headers map[string]string
+}
+
+// StorageSettings identifies a bucket and the credentials used to access it
+type StorageSettings struct {
+	Provider  string
+	Region    int
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// providerVendors maps the names accepted in STORAGE_PROVIDER onto cloud recording vendors
+var providerVendors = map[string]int{
+	"aws":     VendorAWS,
+	"alibaba": VendorAlibaba,
+	"azure":   VendorAzure,
+	"gcs":     VendorGoogle,
+}
+
+// GlobalStorageSettings returns the storage settings configured for this deployment. STORAGE_PROVIDER takes
+// precedence over RECORDING_VENDOR when it is set
+func GlobalStorageSettings() StorageSettings {
+	provider := viper.GetString("STORAGE_PROVIDER")
+	if provider == "" {
+		for name, vendor := range providerVendors {
+			if vendor == viper.GetInt("RECORDING_VENDOR") {
+				provider = name
+			}
+		}
+	}
+
+	return StorageSettings{
+		Provider:  provider,
+		Region:    viper.GetInt("RECORDING_REGION"),
+		Bucket:    viper.GetString("BUCKET_NAME"),
+		AccessKey: viper.GetString("BUCKET_ACCESS_KEY"),
+		SecretKey: viper.GetString("BUCKET_ACCESS_SECRET"),
+	}
+}
+
+// NewStorageProvider creates the storage provider for the given settings
+func NewStorageProvider(settings StorageSettings) (StorageProvider, error) {
+	switch settings.Provider {
+	case "aws":
+		if settings.Region < 0 || settings.Region >= len(awsRegions) {
+			return nil, fmt.Errorf("Unknown AWS region %d", settings.Region)
+		}
+		return &S3Storage{settings}, nil
+	case "alibaba":
+		if settings.Region < 0 || settings.Region >= len(alibabaRegions) {
+			return nil, fmt.Errorf("Unknown Alibaba Cloud region %d", settings.Region)
+		}
+		return &OSSStorage{settings}, nil
+	case "gcs":
+		return &GCSStorage{settings}, nil
+	case "azure":
+		return &AzureStorage{settings}, nil
+	default:
+		return nil, fmt.Errorf("Unknown storage provider %q", settings.Provider)
+	}
+}
+
+// GlobalStorageProvider creates the storage provider configured for this deployment
+func GlobalStorageProvider() (StorageProvider, error) {
+	return NewStorageProvider(GlobalStorageSettings())
+}
+
+// PresignRecordingURL creates a time limited download URL for a recorded file in the recording bucket
+func PresignRecordingURL(storage StorageProvider, key string) (string, time.Time, error) {
+	expiry := time.Duration(viper.GetInt("RECORDING_URL_EXPIRY_SECONDS")) * time.Second
+	expiresAt := time.Now().UTC().Add(expiry)
+
+	signedURL, err := storage.PresignURL(key, expiry)
+	return signedURL, expiresAt, err
+}
+
+// storageConfig builds the storage config for cloud recording from the settings
+func (settings StorageSettings) storageConfig(fileNamePrefix []string) StorageConfig {
+	return StorageConfig{
+		Vendor:         providerVendors[settings.Provider],
+		Region:         settings.Region,
+		Bucket:         settings.Bucket,
+		AccessKey:      settings.AccessKey,
+		SecretKey:      settings.SecretKey,
+		FileNamePrefix: fileNamePrefix,
+	}
+}
+
+// uriEncode percent encodes a string as required by AWS Signature Version 4
+func uriEncode(value string, encodeSlash bool) string {
+	var result strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' || (b == '/' && !encodeSlash) {
+			result.WriteByte(b)
+		} else {
+			fmt.Fprintf(&result, "%%%02X", b)
+		}
+	}
+	return result.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// v4Signer presigns requests with AWS Signature Version 4 or the equivalent Google Cloud Storage V4 signing
+type v4Signer struct {
+	algorithm    string
+	keyPrefix    string
+	service      string
+	terminator   string
+	headerPrefix string
+	// payloadHash is signed as the hash of the payload, UNSIGNED-PAYLOAD when empty which only storage services accept
+	payloadHash string
+}
+
+var awsSigner = v4Signer{
+	algorithm:    "AWS4-HMAC-SHA256",
+	keyPrefix:    "AWS4",
+	service:      "s3",
+	terminator:   "aws4_request",
+	headerPrefix: "X-Amz-",
+}
+
+var googleSigner = v4Signer{
+	algorithm:    "GOOG4-HMAC-SHA256",
+	keyPrefix:    "GOOG4",
+	service:      "storage",
+	terminator:   "goog4_request",
+	headerPrefix: "X-Goog-",
+}
+
+// presign creates a presigned URL for a request against host and the already unescaped path
+func (signer v4Signer) presign(method, host, path, region, accessKey, secretKey string, extraQuery map[string]string, expiry time.Duration, now time.Time) string {
+	now = now.UTC()
+	requestDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/" + signer.service + "/" + signer.terminator
+	canonicalURI := uriEncode(path, false)
+
+	query := map[string]string{
+		signer.headerPrefix + "Algorithm":     signer.algorithm,
+		signer.headerPrefix + "Credential":    accessKey + "/" + scope,
+		signer.headerPrefix + "Date":          requestDate,
+		signer.headerPrefix + "Expires":       strconv.Itoa(int(expiry.Seconds())),
+		signer.headerPrefix + "SignedHeaders": "host",
+	}
+
+	for k, v := range extraQuery {
+		query[k] = v
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, uriEncode(k, true)+"="+uriEncode(query[k], true))
+	}
+	canonicalQuery := strings.Join(params, "&")
+
+	payloadHash := signer.payloadHash
+	if payloadHash == "" {
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+
+	canonicalRequest := method + "\n" + canonicalURI + "\n" + canonicalQuery + "\nhost:" + host + "\n\nhost\n" + payloadHash
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := signer.algorithm + "\n" + requestDate + "\n" + scope + "\n" + hex.EncodeToString(hashedRequest[:])
+
+	signingKey := hmacSHA256([]byte(signer.keyPrefix+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, signer.service)
+	signingKey = hmacSHA256(signingKey, signer.terminator)
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return "https://" + host + canonicalURI + "?" + canonicalQuery + "&" + signer.headerPrefix + "Signature=" + signature
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	NextMarker            string `xml:"NextMarker"`
+}
+
+// listObjects pages through a ListObjects style XML API. listURL returns the signed URL for a page given the
+// continuation token of the previous page
+func listObjects(listURL func(continuation string) (string, error)) ([]string, error) {
+	keys := []string{}
+	var continuation string
+
+	for {
+		signedURL, err := listURL(continuation)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.Get(signedURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("Listing objects failed with status %d", resp.StatusCode)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated {
+			return keys, nil
+		}
+
+		if result.NextContinuationToken != "" {
+			continuation = result.NextContinuationToken
+		} else {
+			continuation = result.NextMarker
+		}
+	}
+}
+
+// deleteObjects deletes every key using the signed URL returned by deleteURL
+func deleteObjects(keys []string, deleteURL func(key string) (string, error)) (int, error) {
+	for index, key := range keys {
+		signedURL, err := deleteURL(key)
+		if err != nil {
+			return index, err
+		}
+
+		req, err := http.NewRequest("DELETE", signedURL, nil)
+		if err != nil {
+			return index, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return index, err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != 200 && resp.StatusCode != 202 && resp.StatusCode != 204 {
+			return index, fmt.Errorf("Deleting %s failed with status %d", key, resp.StatusCode)
+		}
+	}
+
+	return len(keys), nil
+}
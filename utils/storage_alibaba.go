@@ -0,0 +1,93 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// alibabaRegions maps the region codes used by cloud recording onto Alibaba Cloud region names
+var alibabaRegions = []string{
+	"cn-hangzhou", "cn-shanghai", "cn-qingdao", "cn-beijing", "cn-zhangjiakou", "cn-huhehaote", "cn-shenzhen",
+	"cn-hongkong", "us-west-1", "us-east-1", "ap-southeast-1", "ap-southeast-2", "ap-southeast-3",
+	"ap-southeast-5", "ap-northeast-1", "ap-south-1", "eu-central-1", "eu-west-1", "me-east-1",
+}
+
+// OSSStorage stores recordings in an Alibaba Cloud OSS bucket
+type OSSStorage struct {
+	StorageSettings
+}
+
+// presign creates a presigned URL for an OSS request
+func (s *OSSStorage) presign(method string, key string, extraQuery url.Values, expiry time.Duration) string {
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	stringToSign := method + "\n\n\n" + expires + "\n/" + s.Bucket + "/" + key
+
+	mac := hmac.New(sha1.New, []byte(s.SecretKey))
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	query := url.Values{}
+	for k := range extraQuery {
+		query.Set(k, extraQuery.Get(k))
+	}
+	query.Set("OSSAccessKeyId", s.AccessKey)
+	query.Set("Expires", expires)
+	query.Set("Signature", signature)
+
+	return "https://" + s.Bucket + ".oss-" + alibabaRegions[s.Region] + ".aliyuncs.com/" + uriEncode(key, false) + "?" + query.Encode()
+}
+
+// StorageConfig returns the storage config sent to cloud recording when a recording is started
+func (s *OSSStorage) StorageConfig(fileNamePrefix []string) StorageConfig {
+	return s.storageConfig(fileNamePrefix)
+}
+
+// PresignURL creates a download URL for an object that is valid for the given duration
+func (s *OSSStorage) PresignURL(key string, expiry time.Duration) (string, error) {
+	return s.presign("GET", key, nil, expiry), nil
+}
+
+// PresignUpload creates a URL an object can be uploaded to with a PUT request, valid for the given duration. The
+// signature covers the content type, so the upload must not set one
+func (s *OSSStorage) PresignUpload(key string, expiry time.Duration) (*PresignedUpload, error) {
+	return &PresignedUpload{URL: s.presign("PUT", key, nil, expiry)}, nil
+}
+
+// ListObjects returns the keys of every object that starts with prefix
+func (s *OSSStorage) ListObjects(prefix string) ([]string, error) {
+	return listObjects(func(continuation string) (string, error) {
+		query := url.Values{}
+		query.Set("prefix", prefix)
+		if continuation != "" {
+			query.Set("marker", continuation)
+		}
+
+		return s.presign("GET", "", query, time.Minute), nil
+	})
+}
+
+// DeleteObjects deletes every object that starts with prefix and returns the number of deleted objects
+func (s *OSSStorage) DeleteObjects(prefix string) (int, error) {
+	keys, err := s.ListObjects(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleteObjects(keys, func(key string) (string, error) {
+		return s.presign("DELETE", key, nil, time.Minute), nil
+	})
+}
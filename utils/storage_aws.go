@@ -0,0 +1,72 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"time"
+)
+
+// awsRegions maps the region codes used by cloud recording onto AWS region names
+var awsRegions = []string{
+	"us-east-1", "us-east-2", "us-west-1", "us-west-2", "eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1",
+	"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2", "sa-east-1", "ca-central-1",
+	"ap-south-1", "cn-north-1", "cn-northwest-1", "us-gov-west-1",
+}
+
+// S3Storage stores recordings in an AWS S3 bucket
+type S3Storage struct {
+	StorageSettings
+}
+
+func (s *S3Storage) host() string {
+	return s.Bucket + ".s3." + awsRegions[s.Region] + ".amazonaws.com"
+}
+
+// StorageConfig returns the storage config sent to cloud recording when a recording is started
+func (s *S3Storage) StorageConfig(fileNamePrefix []string) StorageConfig {
+	return s.storageConfig(fileNamePrefix)
+}
+
+// PresignURL creates a download URL for an object that is valid for the given duration
+func (s *S3Storage) PresignURL(key string, expiry time.Duration) (string, error) {
+	return awsSigner.presign("GET", s.host(), "/"+key, awsRegions[s.Region], s.AccessKey, s.SecretKey, nil, expiry, time.Now()), nil
+}
+
+// PresignUpload creates a URL an object can be uploaded to with a PUT request, valid for the given duration
+func (s *S3Storage) PresignUpload(key string, expiry time.Duration) (*PresignedUpload, error) {
+	return &PresignedUpload{
+		URL: awsSigner.presign("PUT", s.host(), "/"+key, awsRegions[s.Region], s.AccessKey, s.SecretKey, nil, expiry, time.Now()),
+	}, nil
+}
+
+// ListObjects returns the keys of every object that starts with prefix
+func (s *S3Storage) ListObjects(prefix string) ([]string, error) {
+	return listObjects(func(continuation string) (string, error) {
+		query := map[string]string{"list-type": "2", "prefix": prefix}
+		if continuation != "" {
+			query["continuation-token"] = continuation
+		}
+
+		return awsSigner.presign("GET", s.host(), "/", awsRegions[s.Region], s.AccessKey, s.SecretKey, query, time.Minute, time.Now()), nil
+	})
+}
+
+// DeleteObjects deletes every object that starts with prefix and returns the number of deleted objects
+func (s *S3Storage) DeleteObjects(prefix string) (int, error) {
+	keys, err := s.ListObjects(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleteObjects(keys, func(key string) (string, error) {
+		return awsSigner.presign("DELETE", s.host(), "/"+key, awsRegions[s.Region], s.AccessKey, s.SecretKey, nil, time.Minute, time.Now()), nil
+	})
+}
@@ -0,0 +1,165 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureSASVersion is the storage service version used to sign shared access signatures
+const azureSASVersion = "2018-11-09"
+
+// AzureStorage stores recordings in an Azure Blob Storage container. The bucket is the container name, the
+// access key is the storage account name and the secret is the storage account key
+type AzureStorage struct {
+	StorageSettings
+}
+
+type azureBlobList struct {
+	Blobs []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// sas creates a service shared access signature for a blob, or for the container when blob is empty
+func (s *AzureStorage) sas(permissions, blob string, expiry time.Duration) (url.Values, error) {
+	key, err := base64.StdEncoding.DecodeString(s.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Azure storage account key: %w", err)
+	}
+
+	resource := "c"
+	canonicalResource := "/blob/" + s.AccessKey + "/" + s.Bucket
+	if blob != "" {
+		resource = "b"
+		canonicalResource += "/" + blob
+	}
+
+	signedExpiry := time.Now().UTC().Add(expiry).Format("2006-01-02T15:04:05Z")
+	stringToSign := strings.Join([]string{
+		permissions, "", signedExpiry, canonicalResource, "", "", "https", azureSASVersion, resource, "", "", "", "", "", "",
+	}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+
+	query := url.Values{}
+	query.Set("sv", azureSASVersion)
+	query.Set("sr", resource)
+	query.Set("sp", permissions)
+	query.Set("se", signedExpiry)
+	query.Set("spr", "https")
+	query.Set("sig", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return query, nil
+}
+
+func (s *AzureStorage) containerURL() string {
+	return "https://" + s.AccessKey + ".blob.core.windows.net/" + s.Bucket
+}
+
+// StorageConfig returns the storage config sent to cloud recording when a recording is started
+func (s *AzureStorage) StorageConfig(fileNamePrefix []string) StorageConfig {
+	return s.storageConfig(fileNamePrefix)
+}
+
+// PresignURL creates a download URL for an object that is valid for the given duration
+func (s *AzureStorage) PresignURL(key string, expiry time.Duration) (string, error) {
+	query, err := s.sas("r", key, expiry)
+	if err != nil {
+		return "", err
+	}
+
+	return s.containerURL() + "/" + uriEncode(key, false) + "?" + query.Encode(), nil
+}
+
+// PresignUpload creates a URL an object can be uploaded to with a PUT request, valid for the given duration. Azure
+// requires the type of blob to be sent with the upload
+func (s *AzureStorage) PresignUpload(key string, expiry time.Duration) (*PresignedUpload, error) {
+	query, err := s.sas("cw", key, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignedUpload{
+		URL:     s.containerURL() + "/" + uriEncode(key, false) + "?" + query.Encode(),
+		Headers: map[string]string{"x-ms-blob-type": "BlockBlob"},
+	}, nil
+}
+
+// ListObjects returns the keys of every object that starts with prefix
+func (s *AzureStorage) ListObjects(prefix string) ([]string, error) {
+	keys := []string{}
+	var marker string
+
+	for {
+		query, err := s.sas("l", "", time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("restype", "container")
+		query.Set("comp", "list")
+		query.Set("prefix", prefix)
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := http.Get(s.containerURL() + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var result azureBlobList
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("Listing blobs failed with status %d", resp.StatusCode)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blob := range result.Blobs {
+			keys = append(keys, blob.Name)
+		}
+
+		if result.NextMarker == "" {
+			return keys, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// DeleteObjects deletes every object that starts with prefix and returns the number of deleted objects
+func (s *AzureStorage) DeleteObjects(prefix string) (int, error) {
+	keys, err := s.ListObjects(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleteObjects(keys, func(key string) (string, error) {
+		query, err := s.sas("d", key, time.Minute)
+		if err != nil {
+			return "", err
+		}
+
+		return s.containerURL() + "/" + uriEncode(key, false) + "?" + query.Encode(), nil
+	})
+}
@@ -0,0 +1,64 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"time"
+)
+
+// GCSStorage stores recordings in a Google Cloud Storage bucket. Cloud recording only supports HMAC keys
+// for Google Cloud Storage, so the access key and secret are the HMAC key of a service account
+type GCSStorage struct {
+	StorageSettings
+}
+
+const gcsHost = "storage.googleapis.com"
+
+// StorageConfig returns the storage config sent to cloud recording when a recording is started
+func (s *GCSStorage) StorageConfig(fileNamePrefix []string) StorageConfig {
+	return s.storageConfig(fileNamePrefix)
+}
+
+// PresignURL creates a download URL for an object that is valid for the given duration
+func (s *GCSStorage) PresignURL(key string, expiry time.Duration) (string, error) {
+	return googleSigner.presign("GET", gcsHost, "/"+s.Bucket+"/"+key, "auto", s.AccessKey, s.SecretKey, nil, expiry, time.Now()), nil
+}
+
+// PresignUpload creates a URL an object can be uploaded to with a PUT request, valid for the given duration
+func (s *GCSStorage) PresignUpload(key string, expiry time.Duration) (*PresignedUpload, error) {
+	return &PresignedUpload{
+		URL: googleSigner.presign("PUT", gcsHost, "/"+s.Bucket+"/"+key, "auto", s.AccessKey, s.SecretKey, nil, expiry, time.Now()),
+	}, nil
+}
+
+// ListObjects returns the keys of every object that starts with prefix
+func (s *GCSStorage) ListObjects(prefix string) ([]string, error) {
+	return listObjects(func(continuation string) (string, error) {
+		query := map[string]string{"list-type": "2", "prefix": prefix}
+		if continuation != "" {
+			query["continuation-token"] = continuation
+		}
+
+		return googleSigner.presign("GET", gcsHost, "/"+s.Bucket, "auto", s.AccessKey, s.SecretKey, query, time.Minute, time.Now()), nil
+	})
+}
+
+// DeleteObjects deletes every object that starts with prefix and returns the number of deleted objects
+func (s *GCSStorage) DeleteObjects(prefix string) (int, error) {
+	keys, err := s.ListObjects(prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleteObjects(keys, func(key string) (string, error) {
+		return googleSigner.presign("DELETE", gcsHost, "/"+s.Bucket+"/"+key, "auto", s.AccessKey, s.SecretKey, nil, time.Minute, time.Now()), nil
+	})
+}
@@ -0,0 +1,164 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// stripeSignatureTolerance is how old the timestamp of a signed webhook request can be
+const stripeSignatureTolerance = 5 * time.Minute
+
+var stripeClient = &http.Client{Timeout: 10 * time.Second}
+
+// StripeCheckout describes a Stripe Checkout session that subscribes a customer to a price
+type StripeCheckout struct {
+	PriceID string
+	// Customer is the existing Stripe customer to subscribe. A new customer is created when it is empty
+	Customer      string
+	CustomerEmail string
+	// Metadata is copied to the subscription, so that webhooks can tell who it belongs to
+	Metadata   map[string]string
+	SuccessURL string
+	CancelURL  string
+}
+
+// stripeSession is the part of a Stripe Checkout or billing portal session the backend uses
+type stripeSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreateStripeCheckout creates a Stripe Checkout session for a subscription and returns its URL
+func CreateStripeCheckout(checkout StripeCheckout) (string, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"line_items[0][price]":    {checkout.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"success_url":             {checkout.SuccessURL},
+		"cancel_url":              {checkout.CancelURL},
+	}
+
+	if checkout.Customer != "" {
+		form.Set("customer", checkout.Customer)
+	} else if checkout.CustomerEmail != "" {
+		form.Set("customer_email", checkout.CustomerEmail)
+	}
+
+	for key, value := range checkout.Metadata {
+		form.Set("subscription_data[metadata]["+key+"]", value)
+	}
+
+	var session stripeSession
+	err := stripeRequest("checkout/sessions", form, &session)
+	return session.URL, err
+}
+
+// CreateStripePortal creates a Stripe billing portal session, where a customer can change or cancel their
+// subscription, and returns its URL
+func CreateStripePortal(customer string, returnURL string) (string, error) {
+	form := url.Values{"customer": {customer}, "return_url": {returnURL}}
+
+	var session stripeSession
+	err := stripeRequest("billing_portal/sessions", form, &session)
+	return session.URL, err
+}
+
+// stripeRequest makes a request to the Stripe API with STRIPE_SECRET_KEY and decodes the response into result
+func stripeRequest(path string, form url.Values, result interface{}) error {
+	req, err := http.NewRequest("POST", "https://api.stripe.com/v1/"+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+viper.GetString("STRIPE_SECRET_KEY"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := stripeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("Stripe request failed with %d: %s", response.StatusCode, string(contents))
+	}
+
+	return json.Unmarshal(contents, result)
+}
+
+// VerifyStripeSignature checks the Stripe-Signature header of a webhook request against its payload. The header
+// holds the time the request was signed and one or more signatures of the time and payload with the webhook secret
+func VerifyStripeSignature(payload []byte, header string, secret string, now time.Time) error {
+	if secret == "" {
+		return errors.New("Stripe webhook secret is not set")
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+
+		switch pair[0] {
+		case "t":
+			timestamp = pair[1]
+		case "v1":
+			signature, err := hex.DecodeString(pair[1])
+			if err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("Stripe signature has no timestamp")
+	}
+
+	age := now.Sub(time.Unix(signedAt, 0))
+	if age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return errors.New("Stripe signature has expired")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return errors.New("Stripe signature does not match")
+}
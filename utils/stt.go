@@ -0,0 +1,127 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// TranscriptSegment is a piece of speech along with when it was said, relative to the start of the audio
+type TranscriptSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// SpeechToText transcribes recorded audio
+type SpeechToText interface {
+	// Transcribe transcribes an audio or video file. The file name is used to detect the format of the file
+	Transcribe(audio io.Reader, fileName string) ([]TranscriptSegment, error)
+}
+
+// NewSpeechToText creates the speech to text provider configured with STT_PROVIDER, or returns nil when
+// transcribing recordings is disabled
+func NewSpeechToText() (SpeechToText, error) {
+	switch strings.ToLower(viper.GetString("STT_PROVIDER")) {
+	case "":
+		return nil, nil
+	case "whisper":
+		return &WhisperSTT{
+			URL:    viper.GetString("STT_API_URL"),
+			APIKey: viper.GetString("STT_API_KEY"),
+			Model:  viper.GetString("STT_MODEL"),
+		}, nil
+	}
+
+	return nil, errors.New("Unknown STT_PROVIDER " + viper.GetString("STT_PROVIDER"))
+}
+
+// WhisperSTT transcribes audio with the OpenAI Whisper API or any API compatible with it
+type WhisperSTT struct {
+	URL    string
+	APIKey string
+	Model  string
+}
+
+// Transcribe uploads the file to the transcriptions endpoint, which accepts files of up to 25 MB
+func (stt *WhisperSTT) Transcribe(audio io.Reader, fileName string) ([]TranscriptSegment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("model", stt.Model)
+	form.WriteField("response_format", "verbose_json")
+
+	file, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(file, audio)
+	if err != nil {
+		return nil, err
+	}
+
+	err = form.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", stt.URL, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+stt.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("Transcribing %s failed with status %d", fileName, resp.StatusCode)
+	}
+
+	var result struct {
+		Segments []struct {
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+			Text  string  `json:"text"`
+		} `json:"segments"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []TranscriptSegment{}
+	for _, segment := range result.Segments {
+		segments = append(segments, TranscriptSegment{
+			Start: time.Duration(segment.Start * float64(time.Second)),
+			End:   time.Duration(segment.End * float64(time.Second)),
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+
+	return segments, nil
+}
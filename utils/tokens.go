@@ -14,61 +14,182 @@ import (
 	"fmt"
 	"time"
 
+	accesstoken "github.com/AgoraIO/Tools/DynamicKey/AgoraDynamicKey/go/src/AccessToken"
 	"github.com/samyak-jain/agora_backend/pkg/models"
 	"github.com/samyak-jain/agora_backend/utils/rtctoken"
 	"github.com/samyak-jain/agora_backend/utils/rtmtoken"
 	"github.com/spf13/viper"
 )
 
-// GetRtcToken generates token for Agora RTC SDK
-func GetRtcToken(channel string, uid int) (string, error) {
-	var RtcRole rtctoken.Role = rtctoken.RolePublisher
+// maxTokenExpiry is the longest privilege expiry in seconds, as tokens themselves are only valid for 24 hours
+const maxTokenExpiry = 86400
+
+// TokenExpiry returns the privilege expiry in seconds for tokens of a channel. The expiry set on the channel
+// takes precedence over TOKEN_EXPIRY_SECONDS
+func TokenExpiry(channel *models.Channel) uint32 {
+	if channel != nil && channel.TokenExpirySeconds.Valid && channel.TokenExpirySeconds.Int32 > 0 {
+		return uint32(channel.TokenExpirySeconds.Int32)
+	}
+
+	return uint32(viper.GetInt("TOKEN_EXPIRY_SECONDS"))
+}
+
+// ValidTokenExpiry checks whether a privilege expiry in seconds can be used for tokens
+func ValidTokenExpiry(expiry int) bool {
+	return expiry > 0 && expiry <= maxTokenExpiry
+}
 
+// ChannelRole returns the role of a user joining the channel. Hosts can always publish, while viewers only
+// get subscriber privileges unless the channel allows viewers to publish
+func ChannelRole(channel *models.Channel, host bool) rtctoken.Role {
+	if host || channel.AllowViewersToPublish {
+		return rtctoken.RolePublisher
+	}
+
+	return rtctoken.RoleSubscriber
+}
+
+// GetRtcToken generates token for Agora RTC SDK
+func GetRtcToken(project *AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32) (string, error) {
 	currentTimestamp := uint32(time.Now().UTC().Unix())
-	expireTimestamp := currentTimestamp + 86400
+	expireTimestamp := currentTimestamp + expiry
 
-	return rtctoken.BuildTokenWithUID(viper.GetString("APP_ID"), viper.GetString("APP_CERTIFICATE"), channel, uint32(uid), RtcRole, expireTimestamp)
+	credentials := project.orDefault()
+	return rtctoken.BuildTokenWithUID(credentials.AppID, credentials.AppCertificate, channel, uint32(uid), role, expireTimestamp)
 }
 
 // GetRtmToken generates a token for Agora RTM SDK
-func GetRtmToken(user string) (string, error) {
+func GetRtmToken(project *AgoraProject, user string, expiry uint32) (string, error) {
 
 	currentTimestamp := uint32(time.Now().UTC().Unix())
-	expireTimestamp := currentTimestamp + 86400
+	expireTimestamp := currentTimestamp + expiry
 
-	return rtmtoken.BuildToken(viper.GetString("APP_ID"), viper.GetString("APP_CERTIFICATE"), user, rtmtoken.RoleRtmUser, expireTimestamp)
+	credentials := project.orDefault()
+	return rtmtoken.BuildToken(credentials.AppID, credentials.AppCertificate, user, rtmtoken.RoleRtmUser, expireTimestamp)
 }
 
-// GenerateUserCredentials generates uid, rtc and rtc token
-func GenerateUserCredentials(channel string, rtm bool, pstn bool) (*models.UserCredentials, error) {
+// GetAudioRtcToken generates a token for Agora RTC SDK that can only publish audio and data streams. Users with
+// the subscriber role can only join the channel
+func GetAudioRtcToken(project *AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32) (string, error) {
+	currentTimestamp := uint32(time.Now().UTC().Unix())
+	expireTimestamp := currentTimestamp + expiry
+
+	credentials := project.orDefault()
+	token := accesstoken.CreateAccessToken2(credentials.AppID, credentials.AppCertificate, channel, fmt.Sprint(uid))
+	token.AddPrivilege(accesstoken.KJoinChannel, expireTimestamp)
+
+	if role == rtctoken.RolePublisher {
+		token.AddPrivilege(accesstoken.KPublishAudioStream, expireTimestamp)
+		token.AddPrivilege(accesstoken.KPublishDataStream, expireTimestamp)
+	}
+
+	return token.Build()
+}
+
+// The range of uids newUID returns for PSTN users, which tells PSTN users apart in attendance
+const (
+	MinPSTNUID = 100000000 + 10000000
+	MaxPSTNUID = 100000000 + 99999999
+)
+
+// newUID returns a random uid, in the range reserved for PSTN users when pstn is set
+func newUID(pstn bool) int {
 	initialUID := RandomRange(10000000, 99999999)
-	var uid int
 	if pstn {
-		uid = initialUID + 100000000
-	} else {
-		uid = initialUID + 200000000
+		return initialUID + 100000000
 	}
 
-	rtcToken, err := GetRtcToken(channel, uid)
-	if err != nil {
-		return nil, err
-	}
+	return initialUID + 200000000
+}
 
+// userCredentials bundles an rtc token with the uid it was generated for and, when rtm is set, an rtm token for the same uid
+func userCredentials(project *AgoraProject, uid int, rtcToken string, rtm bool, expiry uint32, area models.AgoraArea) (*models.UserCredentials, error) {
 	if !rtm {
 		return &models.UserCredentials{
-			Rtc: rtcToken,
-			UID: uid,
+			Rtc:  rtcToken,
+			UID:  uid,
+			Area: area,
 		}, nil
 	}
 
-	rtmToken, err := GetRtmToken(fmt.Sprint(uid))
+	rtmToken, err := GetRtmToken(project, fmt.Sprint(uid), expiry)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.UserCredentials{
-		Rtc: rtcToken,
-		Rtm: &rtmToken,
-		UID: uid,
+		Rtc:  rtcToken,
+		Rtm:  &rtmToken,
+		UID:  uid,
+		Area: area,
 	}, nil
 }
+
+// GenerateUserCredentials generates a uid with an rtc token for role valid for expiry seconds and, when rtm is set, an rtm token for the same uid.
+// The credentials tell the SDK to stay within area
+func GenerateUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, rtm bool, pstn bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	uid := newUID(pstn)
+
+	rtcToken, err := GetRtcToken(project, channel, uid, role, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return userCredentials(project, uid, rtcToken, rtm, expiry, area)
+}
+
+// GenerateAudioUserCredentials generates a uid with rtc and rtm tokens like GenerateUserCredentials, except that
+// the rtc token cannot publish video
+func GenerateAudioUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, area models.AgoraArea) (*models.UserCredentials, error) {
+	uid := newUID(false)
+
+	rtcToken, err := GetAudioRtcToken(project, channel, uid, role, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return userCredentials(project, uid, rtcToken, true, expiry, area)
+}
+
+// RenewUserCredentials generates fresh rtc and rtm tokens for a uid that has already joined the channel. Audio
+// only users get a token that cannot publish video, like the one they joined with
+func RenewUserCredentials(project *AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32, audioOnly bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	var rtcToken string
+	var err error
+	if audioOnly {
+		rtcToken, err = GetAudioRtcToken(project, channel, uid, role, expiry)
+	} else {
+		rtcToken, err = GetRtcToken(project, channel, uid, role, expiry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userCredentials(project, uid, rtcToken, true, expiry, area)
+}
+
+// TokenGenerator generates the credentials users join channels with, so that tokens can come from somewhere other
+// than the app certificates of the projects
+type TokenGenerator interface {
+	GenerateUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, rtm bool, pstn bool, area models.AgoraArea) (*models.UserCredentials, error)
+	GenerateAudioUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, area models.AgoraArea) (*models.UserCredentials, error)
+	RenewUserCredentials(project *AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32, audioOnly bool, area models.AgoraArea) (*models.UserCredentials, error)
+}
+
+// CertificateTokenGenerator builds tokens with the app certificates of the projects
+type CertificateTokenGenerator struct{}
+
+// GenerateUserCredentials generates a uid with an rtc token and, when rtm is set, an rtm token
+func (CertificateTokenGenerator) GenerateUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, rtm bool, pstn bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	return GenerateUserCredentials(project, channel, role, expiry, rtm, pstn, area)
+}
+
+// GenerateAudioUserCredentials generates a uid with rtc and rtm tokens that cannot publish video
+func (CertificateTokenGenerator) GenerateAudioUserCredentials(project *AgoraProject, channel string, role rtctoken.Role, expiry uint32, area models.AgoraArea) (*models.UserCredentials, error) {
+	return GenerateAudioUserCredentials(project, channel, role, expiry, area)
+}
+
+// RenewUserCredentials generates fresh rtc and rtm tokens for a uid that has already joined the channel
+func (CertificateTokenGenerator) RenewUserCredentials(project *AgoraProject, channel string, uid int, role rtctoken.Role, expiry uint32, audioOnly bool, area models.AgoraArea) (*models.UserCredentials, error) {
+	return RenewUserCredentials(project, channel, uid, role, expiry, audioOnly, area)
+}
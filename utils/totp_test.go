@@ -0,0 +1,72 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the test vectors of RFC 6238, "12345678901234567890" encoded as base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc6238Vectors are the SHA-1 test vectors of RFC 6238, cut down to the 6 digits authenticator apps use
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCode(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vector := range rfc6238Vectors {
+		if code := totpCode(key, vector.unix/totpPeriod); code != vector.code {
+			t.Errorf("code at %d is %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		now := time.Unix(vector.unix, 0)
+		step := vector.unix / totpPeriod
+
+		if got, ok := ValidateTOTP(rfc6238Secret, vector.code, now, 0); !ok || got != step {
+			t.Errorf("code at %d validated as step %d, %v, want step %d", vector.unix, got, ok, step)
+		}
+
+		if _, ok := ValidateTOTP(rfc6238Secret, vector.code, now.Add(-totpPeriod*time.Second), 0); !ok {
+			t.Errorf("code at %d was refused a step early", vector.unix)
+		}
+
+		if _, ok := ValidateTOTP(rfc6238Secret, vector.code, now.Add(2*totpPeriod*time.Second), 0); ok {
+			t.Errorf("code at %d was accepted two steps late", vector.unix)
+		}
+
+		if _, ok := ValidateTOTP(rfc6238Secret, vector.code, now, step); ok {
+			t.Errorf("code at %d was accepted twice", vector.unix)
+		}
+	}
+
+	if _, ok := ValidateTOTP("not base32!", "287082", time.Unix(59, 0), 0); ok {
+		t.Error("code of an invalid secret was accepted")
+	}
+}
@@ -0,0 +1,95 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/trace/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+	exporttrace "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by the backend itself
+const tracerName = "github.com/samyak-jain/agora_backend"
+
+// SetupTracing exports spans with TRACING_EXPORTER, either otlp or jaeger, and traces outgoing HTTP requests made
+// with the default transport, which includes the calls to the Agora RESTful APIs. Tracing is disabled when
+// TRACING_EXPORTER is not set. The returned function flushes the spans that have not been exported yet
+func SetupTracing(ctx context.Context) (func(context.Context) error, error) {
+	var exporter exporttrace.SpanExporter
+	var err error
+
+	switch viper.GetString("TRACING_EXPORTER") {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		options := []otlpgrpc.Option{}
+		if viper.GetString("TRACING_ENDPOINT") != "" {
+			options = append(options, otlpgrpc.WithEndpoint(viper.GetString("TRACING_ENDPOINT")))
+		}
+
+		if viper.GetBool("TRACING_INSECURE") {
+			options = append(options, otlpgrpc.WithInsecure())
+		}
+
+		exporter, err = otlp.NewExporter(ctx, otlpgrpc.NewDriver(options...))
+	case "jaeger":
+		exporter, err = jaeger.NewRawExporter(jaeger.WithCollectorEndpoint(viper.GetString("TRACING_ENDPOINT")),
+			jaeger.WithProcess(jaeger.Process{ServiceName: viper.GetString("TRACING_SERVICE_NAME")}))
+	default:
+		return nil, fmt.Errorf("Unknown tracing exporter %s", viper.GetString("TRACING_EXPORTER"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(viper.GetString("TRACING_SERVICE_NAME")))),
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(viper.GetFloat64("TRACING_SAMPLE_RATIO"))),
+		}),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	http.DefaultTransport = otelhttp.NewTransport(http.DefaultTransport)
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span that is a child of the span in ctx, if any
+func StartSpan(ctx context.Context, name string, options ...trace.SpanOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, options...)
+}
+
+// EndSpan marks a span as failed when err is set and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,190 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/samyak-jain/agora_backend/pkg/models"
+)
+
+// ErrTranscriptionNotFound is returned when Real-Time Transcription no longer knows about a task, for example
+// because it stopped after the channel was idle
+var ErrTranscriptionNotFound = errors.New("Transcription not found")
+
+// TranscriptionTask is a Real-Time Transcription task along with the builder token used to manage it
+type TranscriptionTask struct {
+	TaskID       string `json:"taskId"`
+	BuilderToken string `json:"-"`
+	Status       string `json:"status"`
+}
+
+// TranscriptionRtcConfig is the user a transcription task joins a channel as
+type TranscriptionRtcConfig struct {
+	ChannelName     string                 `json:"channelName"`
+	UID             string                 `json:"uid"`
+	Token           string                 `json:"token"`
+	ChannelType     string                 `json:"channelType,omitempty"`
+	SubscribeConfig map[string]interface{} `json:"subscribeConfig,omitempty"`
+	MaxIdleTime     int                    `json:"maxIdleTime,omitempty"`
+}
+
+// TranscriptionAudio configures the audio a transcription task subscribes to
+type TranscriptionAudio struct {
+	SubscribeSource string                 `json:"subscribeSource"`
+	AgoraRtcConfig  TranscriptionRtcConfig `json:"agoraRtcConfig"`
+}
+
+// TranscriptionCloudStorage configures where transcripts are stored
+type TranscriptionCloudStorage struct {
+	Format        string        `json:"format"`
+	StorageConfig StorageConfig `json:"storageConfig"`
+}
+
+// TranscriptionOutput configures where captions are sent
+type TranscriptionOutput struct {
+	Destinations       []string                    `json:"destinations"`
+	AgoraRTCDataStream TranscriptionRtcConfig      `json:"agoraRTCDataStream"`
+	CloudStorage       []TranscriptionCloudStorage `json:"cloudStorage"`
+}
+
+// TranscriptionRecognizeConfig configures speech recognition
+type TranscriptionRecognizeConfig struct {
+	Language string              `json:"language"`
+	Model    string              `json:"model"`
+	Output   TranscriptionOutput `json:"output"`
+}
+
+// TranscriptionConfig lists the features of a transcription task
+type TranscriptionConfig struct {
+	Features        []string                     `json:"features"`
+	RecognizeConfig TranscriptionRecognizeConfig `json:"recognizeConfig"`
+}
+
+// TranscriptionRequest is the body sent to start a transcription task
+type TranscriptionRequest struct {
+	Audio  TranscriptionAudio  `json:"audio"`
+	Config TranscriptionConfig `json:"config"`
+}
+
+// speechToTextURL returns the URL of the Real-Time Transcription API of the project
+func speechToTextURL(project *AgoraProject) string {
+	return "https://api.agora.io/v1/projects/" + project.appID() + "/rtsc/speech-to-text"
+}
+
+// sendTranscriptionRequest sends a request to the Real-Time Transcription API and decodes the response into result
+func (c *AgoraClient) sendTranscriptionRequest(project *AgoraProject, method string, requestURL string, body interface{}, result interface{}) error {
+	var requestBody bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&requestBody).Encode(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, requestURL, &requestBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	project.authorize(req)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return ErrTranscriptionNotFound
+	}
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("Transcription request failed with status %d", resp.StatusCode)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// StartTranscription transcribes every user in a channel. Captions are sent to the channel as data stream messages by
+// the publisher and the transcript is uploaded to storage as WebVTT files in an HLS playlist under fileNamePrefix
+func (c *AgoraClient) StartTranscription(project *AgoraProject, channel string, language string, subscriber *models.UserCredentials, publisher *models.UserCredentials, storage StorageConfig) (*TranscriptionTask, error) {
+	var builderToken struct {
+		TokenName string `json:"tokenName"`
+	}
+	err := c.sendTranscriptionRequest(project, "POST", speechToTextURL(project)+"/builderTokens", map[string]string{"instanceId": channel}, &builderToken)
+	if err != nil {
+		return nil, err
+	}
+
+	request := TranscriptionRequest{
+		Audio: TranscriptionAudio{
+			SubscribeSource: "AGORARTC",
+			AgoraRtcConfig: TranscriptionRtcConfig{
+				ChannelName: channel,
+				UID:         strconv.Itoa(subscriber.UID),
+				Token:       subscriber.Rtc,
+				ChannelType: "LIVE_TYPE",
+				SubscribeConfig: map[string]interface{}{
+					"subscribeMode": "CHANNEL_MODE",
+				},
+				MaxIdleTime: 60,
+			},
+		},
+		Config: TranscriptionConfig{
+			Features: []string{"RECOGNIZE"},
+			RecognizeConfig: TranscriptionRecognizeConfig{
+				Language: language,
+				Model:    "Model",
+				Output: TranscriptionOutput{
+					Destinations: []string{"AgoraRTCDataStream", "Storage"},
+					AgoraRTCDataStream: TranscriptionRtcConfig{
+						ChannelName: channel,
+						UID:         strconv.Itoa(publisher.UID),
+						Token:       publisher.Rtc,
+					},
+					CloudStorage: []TranscriptionCloudStorage{
+						{
+							Format:        "HLS",
+							StorageConfig: storage,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var task TranscriptionTask
+	err = c.sendTranscriptionRequest(project, "POST", speechToTextURL(project)+"/tasks?builderToken="+url.QueryEscape(builderToken.TokenName), &request, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	task.BuilderToken = builderToken.TokenName
+	return &task, nil
+}
+
+// StopTranscription stops a transcription task
+func (c *AgoraClient) StopTranscription(project *AgoraProject, taskID string, builderToken string) error {
+	return c.sendTranscriptionRequest(project, "DELETE", speechToTextURL(project)+"/tasks/"+url.PathEscape(taskID)+"?builderToken="+url.QueryEscape(builderToken), nil, nil)
+}
@@ -0,0 +1,144 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrWebhookAddress is returned for webhook URLs that resolve to an address inside the network of the backend
+var ErrWebhookAddress = errors.New("Webhook URL must point at a public address")
+
+// privateBlocks are the ranges, besides loopback, link-local and unspecified addresses, webhooks are never sent to
+var privateBlocks = parseBlocks("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "198.18.0.0/15", "fc00::/7")
+
+// webhookClient refuses redirects and checks every address it dials, so a public hostname that later resolves to a
+// private address or a redirect to one cannot reach the network of the backend
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network string, address string, conn syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+
+				if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+					return ErrWebhookAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func parseBlocks(cidrs ...string) []*net.IPNet {
+	blocks := make([]*net.IPNet, len(cidrs))
+	for index, cidr := range cidrs {
+		_, block, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		blocks[index] = block
+	}
+
+	return blocks
+}
+
+// publicAddress reports whether an address is outside of the loopback, private, link-local and unspecified ranges
+func publicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, block := range privateBlocks {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckWebhookHost resolves the host of a webhook URL and fails with ErrWebhookAddress when any of its addresses is
+// not public
+func CheckWebhookHost(ctx context.Context, host string) error {
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return ErrWebhookAddress
+	}
+
+	for _, address := range addresses {
+		if !publicAddress(address.IP) {
+			return ErrWebhookAddress
+		}
+	}
+
+	return nil
+}
+
+// SignWebhook signs the payload of a webhook request sent at a time with the secret of the webhook. Receivers check
+// the Webhook-Signature header, formatted as t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">
+func SignWebhook(secret string, payload []byte, sentAt time.Time) string {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostWebhook delivers a signed event to a webhook URL and returns the status it responded with. Responses outside
+// of 2xx, including redirects, are errors. The body of the response is never read, since the error is shown to the
+// owner of the webhook
+func PostWebhook(url string, secret string, eventID string, event string, payload []byte) (int, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Webhook-Id", eventID)
+	req.Header.Set("Webhook-Event", event)
+	req.Header.Set("Webhook-Signature", SignWebhook(secret, payload, time.Now()))
+
+	response, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("Webhook responded with %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,118 @@
+// ********************************************
+// Copyright © 2021 Agora Lab, Inc., all rights reserved.
+// AppBuilder and all associated components, source code, APIs, services, and documentation
+// (the “Materials”) are owned by Agora Lab, Inc. and its licensors.  The Materials may not be
+// accessed, used, modified, or distributed for any purpose without a license from Agora Lab, Inc.
+// Use without a license or in violation of any license terms and conditions (including use for
+// any purpose competitive to Agora Lab, Inc.’s business) is strictly prohibited.  For more
+// information visit https://appbuilder.agora.io.
+// *********************************************
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Whiteboard room token roles, from most to least privileged
+const (
+	WhiteboardRoleAdmin  = "admin"
+	WhiteboardRoleWriter = "writer"
+	WhiteboardRoleReader = "reader"
+)
+
+// ErrWhiteboardNotConfigured is returned when a whiteboard is requested without WHITEBOARD_SDK_TOKEN being set
+var ErrWhiteboardNotConfigured = errors.New("Whiteboard is not configured")
+
+// whiteboardURL is the base URL of the Interactive Whiteboard RESTful API
+const whiteboardURL = "https://api.netless.link/v5"
+
+// whiteboardRoom is the part of a whiteboard room returned by the RESTful API that is used
+type whiteboardRoom struct {
+	UUID string `json:"uuid"`
+}
+
+// whiteboardRequest sends an authenticated request to the Interactive Whiteboard RESTful API
+func whiteboardRequest(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	if viper.GetString("WHITEBOARD_SDK_TOKEN") == "" {
+		return nil, ErrWhiteboardNotConfigured
+	}
+
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", whiteboardURL+path, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("token", viper.GetString("WHITEBOARD_SDK_TOKEN"))
+	req.Header.Set("region", viper.GetString("WHITEBOARD_REGION"))
+
+	return http.DefaultClient.Do(req)
+}
+
+// CreateWhiteboardRoom creates a whiteboard room in WHITEBOARD_REGION and returns its UUID
+func CreateWhiteboardRoom(ctx context.Context) (string, error) {
+	resp, err := whiteboardRequest(ctx, "/rooms", map[string]interface{}{
+		"isRecord": false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		return "", fmt.Errorf("Creating whiteboard room failed with status %d", resp.StatusCode)
+	}
+
+	var room whiteboardRoom
+	err = json.NewDecoder(resp.Body).Decode(&room)
+	if err != nil {
+		return "", err
+	}
+
+	if room.UUID == "" {
+		return "", errors.New("Whiteboard room has no UUID")
+	}
+
+	return room.UUID, nil
+}
+
+// WhiteboardRoomToken generates a token that joins a whiteboard room with the given role until it expires
+func WhiteboardRoomToken(ctx context.Context, roomUUID string, role string, lifespan time.Duration) (string, error) {
+	resp, err := whiteboardRequest(ctx, "/tokens/rooms/"+roomUUID, map[string]interface{}{
+		"lifespan": lifespan.Milliseconds(),
+		"role":     role,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		return "", fmt.Errorf("Generating whiteboard room token failed with status %d", resp.StatusCode)
+	}
+
+	// The token is returned as a JSON string
+	var token string
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}